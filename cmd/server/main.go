@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"wallet-point/config"
+	"wallet-point/internal/metrics"
+	"wallet-point/internal/tracing"
 	"wallet-point/routes"
 	"wallet-point/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
 )
 
 // @title Wallet Point API
@@ -36,26 +46,122 @@ func main() {
 	// Set Gin mode
 	gin.SetMode(cfg.GinMode)
 
+	// Register custom request validators (nim, points, enums)
+	if err := utils.RegisterValidators(); err != nil {
+		log.Fatal("❌ Failed to register validators:", err)
+	}
+
+	// Initialize structured logging
+	utils.InitLogger(cfg.LogFormat, cfg.LogLevel)
+
 	// Initialize JWT
 	utils.InitJWT(cfg.JWTSecret)
 
+	// Encrypt PII columns at rest
+	if err := utils.InitPII(cfg.PIIEncryptionKeys); err != nil {
+		log.Fatal("❌ Invalid PII encryption keys:", err)
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: cfg.OTelServiceName,
+		Endpoint:    cfg.OTelEndpoint,
+		Insecure:    cfg.OTelInsecure,
+		SampleRatio: cfg.OTelSampleRatio,
+	})
+	if err != nil {
+		log.Fatal("❌ Failed to initialize tracing:", err)
+	}
+
 	// Connect to database
 	db := config.ConnectDB(cfg)
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatal("❌ Failed to instrument database:", err)
+	}
+	for host, pool := range config.ReplicaPools() {
+		metrics.RegisterDBPool("wallet_point_replica:"+host, pool)
+	}
+	if err := db.Use(otelgorm.NewPlugin(otelgorm.WithDBName(cfg.DBName))); err != nil {
+		log.Fatal("❌ Failed to instrument database:", err)
+	}
+	// Queries run with a campus admin's request context only see that campus
+	if err := db.Use(utils.TenantPlugin{}); err != nil {
+		log.Fatal("❌ Failed to scope database queries by campus:", err)
+	}
 
-	// Initialize Gin
-	r := gin.Default()
+	// Initialize Gin; requests are logged by middleware.Logger
+	r := gin.New()
+	r.Use(gin.Recovery())
 
 	// Setup routes
-	routes.SetupRoutes(r, db, cfg.AllowedOrigins, cfg.JWTExpiryHours)
+	campusServer := routes.SetupRoutes(r, db, cfg)
 
 	// Start server
-	serverAddress := ":" + cfg.ServerPort
+	server := &http.Server{
+		Addr:              ":" + cfg.ServerPort,
+		Handler:           routes.VersionFallback(r),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
 	log.Printf("🚀 Server starting on http://%s", cfg.ServerAddress)
-	log.Printf("📚 API Documentation (if Swagger enabled): http://%s/swagger/index.html", cfg.ServerAddress)
-	log.Printf("🏥 Health Check: http://%s/api/v1/health", cfg.ServerAddress)
+	log.Printf("📚 API Documentation: http://%s/docs/index.html", cfg.ServerAddress)
+	log.Printf("🏥 Health Check: http://%s/healthz (readiness: /readyz)", cfg.ServerAddress)
+	log.Printf("📈 Metrics: http://%s/metrics", cfg.ServerAddress)
+	if campusServer != nil {
+		log.Printf("🔌 gRPC (mTLS): %s:%s", cfg.ServerHost, cfg.GRPCPort)
+	}
 	log.Println("✨ Press Ctrl+C to stop the server")
 
-	if err := r.Run(serverAddress); err != nil {
-		log.Fatal("❌ Failed to start server:", err)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("❌ Failed to start server:", err)
+		}
+	}()
+	if campusServer != nil {
+		go func() {
+			if err := campusServer.ListenAndServe(); err != nil {
+				log.Fatal("❌ Failed to start gRPC server:", err)
+			}
+		}()
 	}
+
+	// Wait for Ctrl+C or the SIGTERM of a deploy
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-signals.Done()
+	stop()
+
+	log.Println("🛑 Shutting down, draining in-flight requests...")
+	utils.BeginShutdown()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// 1. Stop accepting connections and wait for in-flight requests (e.g. a checkout)
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("⚠️ HTTP server did not drain in time: %v", err)
+	}
+	if campusServer != nil {
+		if err := campusServer.Shutdown(ctx); err != nil {
+			log.Printf("⚠️ gRPC server did not drain in time: %v", err)
+		}
+	}
+
+	// 2. Let background loops finish their round and flush the notification outbox
+	if err := utils.StopBackground(ctx); err != nil {
+		log.Printf("⚠️ Background work did not finish in time: %v", err)
+	}
+
+	// 3. Flush buffered spans and close the connection pools
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("⚠️ Flushing traces failed: %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	for _, pool := range config.ReplicaPools() {
+		pool.Close()
+	}
+
+	log.Println("👋 Server stopped")
 }
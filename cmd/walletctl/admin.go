@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/auth"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/spf13/cobra"
+)
+
+func newAdminCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Manage admin accounts",
+	}
+	cmd.AddCommand(newAdminCreateCommand())
+	return cmd
+}
+
+func newAdminCreateCommand() *cobra.Command {
+	var req auth.RegisterRequest
+	var passwordStdin, superadmin bool
+	var tenantCode string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an admin account",
+		Long: "Create an admin account, e.g. the first one of a new installation.\n" +
+			"Admins manage one campus (--tenant, the main campus by default); super admins manage every campus.\n" +
+			"Pass the password on stdin (--password-stdin) to keep it out of the shell history.",
+		Example: `  echo "$ADMIN_PASSWORD" | walletctl admin create --email ops@campus.ac.id --name "Ops Team" --nip 198001012000 --password-stdin`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if passwordStdin {
+				line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil && line == "" {
+					return fmt.Errorf("reading password from stdin: %w", err)
+				}
+				req.Password = strings.TrimRight(line, "\r\n")
+			}
+			if req.Password == "" {
+				return errors.New("a password is required: use --password-stdin or --password")
+			}
+			req.Role = "admin"
+			if superadmin {
+				req.Role = "superadmin"
+			}
+
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			if err := a.tenants.EnsureDefault(); err != nil {
+				return err
+			}
+			if tenantCode != "" {
+				campus, err := a.tenants.ActiveTenantByCode(tenantCode)
+				if err != nil {
+					return fmt.Errorf("campus %s: %w", tenantCode, err)
+				}
+				req.TenantID = campus.ID
+			}
+			if err := binding.Validator.ValidateStruct(&req); err != nil {
+				return err
+			}
+
+			user, err := a.auth.Register(&req)
+			if err != nil {
+				return err
+			}
+
+			// The first admin of an installation has nobody else to attribute it to
+			createdBy := user.ID
+			if email, _ := cmd.Flags().GetString("admin"); email != "" {
+				admin, err := a.actingAdmin(cmd)
+				if err != nil {
+					return err
+				}
+				createdBy = admin.ID
+			}
+			a.logActivity(audit.CreateAuditParams{
+				UserID:   createdBy,
+				Action:   "REGISTER",
+				Entity:   "USER",
+				EntityID: user.ID,
+				Details:  "walletctl registered new " + user.Role + ": " + user.Email,
+			})
+
+			printf(cmd, "Created %s #%d %s", user.Role, user.ID, user.Email)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&req.Email, "email", "", "login email (required)")
+	cmd.Flags().StringVar(&req.FullName, "name", "", "full name (required)")
+	cmd.Flags().StringVar(&req.NimNip, "nip", "", "NIP (required)")
+	cmd.Flags().StringVar(&req.Password, "password", "", "password (visible in the process list; prefer --password-stdin)")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "read the password from the first line of stdin")
+	cmd.Flags().BoolVar(&superadmin, "superadmin", false, "create a super admin, who manages every campus")
+	cmd.Flags().StringVar(&tenantCode, "tenant", "", "code of the campus the admin manages (default: the main campus)")
+	cmd.MarkFlagRequired("email")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("nip")
+	return cmd
+}
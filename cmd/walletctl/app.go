@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"wallet-point/config"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/auth"
+	"wallet-point/internal/events"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/report"
+	"wallet-point/internal/storage"
+	"wallet-point/internal/tenant"
+	"wallet-point/internal/wallet"
+	"wallet-point/internal/webhook"
+	"wallet-point/utils"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// app wires the services walletctl uses. Job handlers are registered as in the
+// server so jobs can be enqueued, but no workers run here: the server's
+// workers pick the jobs up.
+type app struct {
+	cfg      *config.Config
+	db       *gorm.DB
+	auth     *auth.AuthService
+	tenants  *tenant.TenantService
+	wallets  *wallet.WalletService
+	audit    audit.Logger
+	jobs     *jobs.Queue
+	webhooks *webhook.Service
+}
+
+func newApp() (*app, error) {
+	cfg := config.LoadConfig()
+	utils.InitLogger(cfg.LogFormat, cfg.LogLevel)
+	if err := utils.RegisterValidators(); err != nil {
+		return nil, err
+	}
+	if err := utils.InitPII(cfg.PIIEncryptionKeys); err != nil {
+		return nil, err
+	}
+
+	db := config.ConnectDB(cfg)
+
+	jobQueue := jobs.NewQueue(jobs.NewRepository(db), cfg.JobPollInterval, cfg.JobTimeout)
+	tenantService := tenant.NewTenantService(tenant.NewTenantRepository(db))
+	authService := auth.NewAuthService(auth.NewAuthRepository(db), cfg.JWTExpiryHours)
+	authService.SetTenants(tenantService)
+	walletService := wallet.NewWalletService(wallet.NewWalletRepository(db), db)
+	walletService.SetAuthService(authService)
+	walletService.SetJobQueue(jobQueue)
+	webhookService := webhook.NewService(webhook.NewRepository(db), db, jobQueue, cfg.WebhookTimeout, cfg.WebhookMaxAttempts)
+	walletService.SetOutbox(events.NewOutbox(db, jobQueue))
+	reportService := report.NewReportService(report.NewReportRepository(db))
+	files, err := storage.New(storage.Config{
+		Driver:    cfg.StorageDriver,
+		LocalPath: cfg.UploadPath,
+		Secret:    cfg.JWTSecret,
+		Bucket:    cfg.StorageBucket,
+		Region:    cfg.StorageRegion,
+		Endpoint:  cfg.StorageEndpoint,
+		AccessKey: cfg.StorageAccessKey,
+		SecretKey: cfg.StorageSecretKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	jobQueue.SetStorage(files)
+	reportService.SetJobQueue(jobQueue, files)
+
+	return &app{
+		cfg:      cfg,
+		db:       db,
+		auth:     authService,
+		tenants:  tenantService,
+		wallets:  walletService,
+		audit:    audit.NewAuditService(audit.NewAuditRepository(db)),
+		jobs:     jobQueue,
+		webhooks: webhookService,
+	}, nil
+}
+
+// actingAdmin resolves the --admin flag to an active admin account
+func (a *app) actingAdmin(cmd *cobra.Command) (*auth.User, error) {
+	email, _ := cmd.Flags().GetString("admin")
+	if email == "" {
+		return nil, fmt.Errorf("--admin (or WALLETCTL_ADMIN) is required: the change is recorded for that admin")
+	}
+
+	admin, err := a.auth.GetUserByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("admin %s: %w", email, err)
+	}
+	if (admin.Role != "admin" && admin.Role != "superadmin") || admin.Status != "active" {
+		return nil, fmt.Errorf("%s is not an active admin", email)
+	}
+	return admin, nil
+}
+
+// logActivity records a walletctl change in the audit log like the API handlers do
+func (a *app) logActivity(params audit.CreateAuditParams) {
+	hostname, _ := os.Hostname()
+	params.UserAgent = "walletctl@" + hostname
+	if err := a.audit.LogActivity(params); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: audit log not written: %v\n", err)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/jobs"
+
+	"github.com/spf13/cobra"
+)
+
+func newJobsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Trigger and retry background jobs",
+		Long:  "Jobs are queued in the database and run by the server's workers.",
+	}
+	cmd.AddCommand(
+		newJobsTypesCommand(),
+		newJobsEnqueueCommand(),
+		newJobsRetryCommand(),
+	)
+	return cmd
+}
+
+func newJobsTypesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "types",
+		Short: "List the job types that can be enqueued",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			for _, jobType := range a.jobs.Types() {
+				printf(cmd, "%s", jobType)
+			}
+			return nil
+		},
+	}
+}
+
+func newJobsEnqueueCommand() *cobra.Command {
+	var payload string
+	var delay time.Duration
+
+	cmd := &cobra.Command{
+		Use:     "enqueue <type>",
+		Short:   "Queue a job",
+		Example: "  walletctl jobs enqueue wallet.reconcile_balances --admin ops@campus.ac.id",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !json.Valid([]byte(payload)) {
+				return errors.New("--payload must be valid JSON")
+			}
+
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			admin, err := a.actingAdmin(cmd)
+			if err != nil {
+				return err
+			}
+
+			job, err := a.jobs.Enqueue(args[0], json.RawMessage(payload), jobs.EnqueueOptions{Delay: delay, CreatedBy: &admin.ID})
+			if err != nil {
+				return err
+			}
+			a.logActivity(audit.CreateAuditParams{
+				UserID:   admin.ID,
+				Action:   "ENQUEUE_JOB",
+				Entity:   "JOB",
+				EntityID: job.ID,
+				Details:  "walletctl queued job " + job.Type,
+			})
+
+			printf(cmd, "Queued job #%d (%s) on the %s queue", job.ID, job.Type, job.Queue)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&payload, "payload", "{}", "job payload as JSON")
+	cmd.Flags().DurationVar(&delay, "delay", 0, "run the job after this delay")
+	return cmd
+}
+
+func newJobsRetryCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "retry <job-id>",
+		Short: "Put a dead job back into its queue",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID, err := strconv.ParseUint(args[0], 10, 32)
+			if err != nil {
+				return errors.New("invalid job ID")
+			}
+
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			admin, err := a.actingAdmin(cmd)
+			if err != nil {
+				return err
+			}
+
+			job, err := a.jobs.Retry(uint(jobID))
+			if err != nil {
+				return err
+			}
+			a.logActivity(audit.CreateAuditParams{
+				UserID:   admin.ID,
+				Action:   "RETRY_JOB",
+				Entity:   "JOB",
+				EntityID: job.ID,
+				Details:  "walletctl retried job " + job.Type,
+			})
+
+			printf(cmd, "Job #%d (%s) is queued again", job.ID, job.Type)
+			return nil
+		},
+	}
+}
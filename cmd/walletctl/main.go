@@ -0,0 +1,44 @@
+// Command walletctl runs operational tasks (migrations, admin accounts, wallet
+// adjustments, webhook replays, background jobs, PII key rotation, table
+// partitioning) through the same services as the API, reading the same
+// environment/.env configuration.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "walletctl",
+		Short:        "Wallet Point operations tool",
+		SilenceUsage: true,
+	}
+	root.PersistentFlags().String("admin", os.Getenv("WALLETCTL_ADMIN"), "email of the admin the change is recorded for in the audit log (env WALLETCTL_ADMIN)")
+
+	root.AddCommand(
+		newMigrateCommand(),
+		newSeedCommand(),
+		newAdminCommand(),
+		newWalletCommand(),
+		newWebhooksCommand(),
+		newJobsCommand(),
+		newPIICommand(),
+		newPartitionsCommand(),
+	)
+	return root
+}
+
+// printf writes a line of command output
+func printf(cmd *cobra.Command, format string, args ...interface{}) {
+	fmt.Fprintf(cmd.OutOrStdout(), format+"\n", args...)
+}
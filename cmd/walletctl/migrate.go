@@ -0,0 +1,23 @@
+package main
+
+import (
+	"wallet-point/internal/database"
+
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Create and update the database tables",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			database.Migrate(a.db)
+			return nil
+		},
+	}
+}
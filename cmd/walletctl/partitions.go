@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"wallet-point/internal/partition"
+
+	"github.com/spf13/cobra"
+)
+
+func newPartitionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "partitions",
+		Short: "Manage the monthly partitions of the transaction and audit tables",
+		Long: "Once a table is partitioned the server's partition_maintenance job creates the upcoming\n" +
+			"months and drops those past PARTITION_RETENTION.",
+	}
+	cmd.AddCommand(
+		newPartitionsInitCommand(),
+		newPartitionsStatusCommand(),
+	)
+	return cmd
+}
+
+func newPartitionsInitCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init [table...]",
+		Short: "Partition existing tables by month",
+		Long: "Partition tables (all of " + strings.Join(partition.Tables, ", ") + " by default) by month from their\n" +
+			"oldest row. MySQL copies the whole table, blocking writes to it meanwhile: run it in a quiet\n" +
+			"period, e.g. with maintenance mode on. Tables already partitioned are skipped.",
+		Example: "  walletctl partitions init audit_logs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			tables := args
+			if len(tables) == 0 {
+				tables = partition.Tables
+			}
+
+			manager := partition.NewManager(a.db, a.cfg.PartitionMonthsAhead, a.cfg.PartitionRetention)
+			for _, table := range tables {
+				converted, err := manager.Convert(cmd.Context(), table)
+				if err != nil {
+					return err
+				}
+				if converted {
+					printf(cmd, "%s: partitioned by month", table)
+				} else {
+					printf(cmd, "%s: already partitioned", table)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newPartitionsStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List the partitions of each table",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+
+			manager := partition.NewManager(a.db, a.cfg.PartitionMonthsAhead, a.cfg.PartitionRetention)
+			statuses, err := manager.Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, status := range statuses {
+				if !status.Partitioned {
+					printf(cmd, "%s: not partitioned (walletctl partitions init %s)", status.Table, status.Table)
+					continue
+				}
+				printf(cmd, "%s:", status.Table)
+				for _, p := range status.Partitions {
+					printf(cmd, "  %-8s ~%d rows", p.Name, p.Rows)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"wallet-point/internal/database"
+
+	"github.com/spf13/cobra"
+)
+
+func newPIICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pii",
+		Short: "Manage the encryption of personal data",
+	}
+	cmd.AddCommand(newPIIRotateCommand())
+	return cmd
+}
+
+func newPIIRotateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate",
+		Short: "Re-encrypt personal data with the current key",
+		Long: "Re-encrypt phone numbers and PIN hashes with the first key of PII_ENCRYPTION_KEYS:\n" +
+			"values of older keys are rewrapped and values stored in plain text are encrypted.\n" +
+			"Run it after adding a new key in front of the list; the old key can be removed afterwards.",
+		Example: "  PII_ENCRYPTION_KEYS=k2:<new>,k1:<old> walletctl pii rotate",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+
+			changed, err := database.RotatePII(a.db)
+			for column, count := range changed {
+				printf(cmd, "%s: re-encrypted %d values", column, count)
+			}
+			if err == nil {
+				printf(cmd, "All personal data is encrypted with the current key")
+			}
+			return err
+		},
+	}
+}
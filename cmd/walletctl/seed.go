@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"wallet-point/internal/database"
+
+	"github.com/spf13/cobra"
+)
+
+func newSeedCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Load demo data (users, products, wallets, transactions)",
+		Long: "Load demo users, categories, products, wallets with balances and sample transactions\n" +
+			"for local development and demo environments. Running it again only adds what is missing.\n" +
+			"Every seeded account uses the password \"" + database.SeedPassword + "\" and PIN \"" + database.SeedPIN + "\".",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			if a.cfg.AppEnv == "production" && !force {
+				return errors.New("refusing to seed demo accounts with known passwords in production (APP_ENV=production); pass --force to do it anyway")
+			}
+
+			result, err := database.Seed(a.db)
+			if err != nil {
+				return err
+			}
+			printf(cmd, "Seeded %d users, %d categories, %d products, %d wallets and %d transactions",
+				result.Users, result.Categories, result.Products, result.Wallets, result.Transactions)
+			printf(cmd, "Log in as admin@walletpoint.test, dosen1@walletpoint.test or mahasiswa1@walletpoint.test with password %q", database.SeedPassword)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "seed even when APP_ENV=production")
+	return cmd
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/wallet"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/spf13/cobra"
+)
+
+func newWalletCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wallet",
+		Short: "Adjust wallet balances",
+	}
+	cmd.AddCommand(
+		newWalletAdjustCommand("credit", "Add points to a wallet"),
+		newWalletAdjustCommand("debit", "Remove points from a wallet"),
+	)
+	return cmd
+}
+
+// newWalletAdjustCommand is the admin "adjust points" action for one direction
+func newWalletAdjustCommand(direction, short string) *cobra.Command {
+	var nimNip, reason string
+	var amount int
+
+	cmd := &cobra.Command{
+		Use:     direction,
+		Short:   short,
+		Example: fmt.Sprintf("  walletctl wallet %s --nim 2021001 --amount 50 --reason \"Event compensation\" --admin ops@campus.ac.id", direction),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			admin, err := a.actingAdmin(cmd)
+			if err != nil {
+				return err
+			}
+			target, err := a.wallets.GetWalletByNimNip(nimNip)
+			if err != nil {
+				return fmt.Errorf("wallet of %s: %w", nimNip, err)
+			}
+
+			req := &wallet.AdjustmentRequest{
+				WalletID:    target.WalletID,
+				Amount:      amount,
+				Direction:   direction,
+				Description: reason,
+			}
+			if err := binding.Validator.ValidateStruct(req); err != nil {
+				return err
+			}
+			if err := a.wallets.AdjustPoints(req, admin.ID); err != nil {
+				return err
+			}
+
+			a.logActivity(audit.CreateAuditParams{
+				UserID:   admin.ID,
+				Action:   "ADJUST_POINTS",
+				Entity:   "WALLET",
+				EntityID: target.WalletID,
+				Details:  fmt.Sprintf("walletctl adjusted points: %s %d | Reason: %s", direction, amount, reason),
+			})
+
+			updated, err := a.wallets.GetWalletByID(target.WalletID)
+			if err != nil {
+				return err
+			}
+			printf(cmd, "%s %d points for %s (%s); balance is now %d", direction, amount, target.FullName, target.NimNip, updated.Balance)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&nimNip, "nim", "", "NIM/NIP of the wallet owner (required)")
+	cmd.Flags().IntVar(&amount, "amount", 0, "points (required)")
+	cmd.Flags().StringVar(&reason, "reason", "", "reason, shown in the transaction history (required)")
+	cmd.MarkFlagRequired("nim")
+	cmd.MarkFlagRequired("amount")
+	cmd.MarkFlagRequired("reason")
+	return cmd
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newWebhooksCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhooks",
+		Short: "Operate outbound webhooks",
+	}
+	cmd.AddCommand(newWebhooksReplayCommand())
+	return cmd
+}
+
+func newWebhooksReplayCommand() *cobra.Command {
+	var endpointID uint
+	var since time.Duration
+
+	cmd := &cobra.Command{
+		Use:     "replay",
+		Short:   "Redeliver failed webhook deliveries",
+		Long:    "Redeliver the deliveries that ran out of attempts, e.g. after a receiver's outage.\nThe server's webhook workers send them.",
+		Example: "  walletctl webhooks replay --endpoint 3 --since 6h --admin ops@campus.ac.id",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newApp()
+			if err != nil {
+				return err
+			}
+			admin, err := a.actingAdmin(cmd)
+			if err != nil {
+				return err
+			}
+
+			count, err := a.webhooks.RedeliverFailed(endpointID, time.Now().Add(-since), admin.ID)
+			printf(cmd, "Queued %d failed deliveries", count)
+			return err
+		},
+	}
+
+	cmd.Flags().UintVar(&endpointID, "endpoint", 0, "only this endpoint ID (default: all endpoints)")
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "only deliveries created within this period")
+	return cmd
+}
@@ -1,18 +1,42 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
 )
 
 type Config struct {
-	ServerHost     string
-	ServerPort     string
-	ServerAddress  string
-	GinMode        string
+	ServerHost    string
+	ServerPort    string
+	ServerAddress string
+	GinMode       string
+	AppEnv        string // deployment environment, used to target feature flags
+	LogFormat     string
+	LogLevel      string
+
+	// Request log: one JSON line per request, with redacted bodies of mutating
+	// requests; AccessLog is "stdout", a file path, or empty to disable it
+	AccessLog             string
+	AccessLogMaxBodyBytes int
+
+	// Request deadlines: RequestTimeout applies to every route, ReportTimeout to the
+	// report and analytics endpoints; 0 disables the deadline
+	RequestTimeout time.Duration
+	ReportTimeout  time.Duration
+
+	// Graceful shutdown: how long to keep serving while the load balancer
+	// deregisters the instance, then how long to wait for in-flight work
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+
 	DBHost         string
 	DBPort         string
 	DBUser         string
@@ -20,54 +44,809 @@ type Config struct {
 	DBName         string
 	JWTSecret      string
 	JWTExpiryHours int
-	AllowedOrigins string
-	MaxUploadSize  int64
-	UploadPath     string
+
+	// Master keys ("id:base64 key") encrypting PII columns (phone numbers, PIN
+	// hashes); the first encrypts, the rest only decrypt values not yet rotated
+	PIIEncryptionKeys []string
+
+	MaxUploadSize int64
+	UploadPath    string
+
+	// File storage for uploads, submission receipts and exports: "local" keeps them
+	// under UploadPath, "s3" and "gcs" in StorageBucket (GCS through its
+	// S3-compatible XML API with an HMAC key). Signed links expire after StorageURLTTL.
+	StorageDriver       string
+	StorageBucket       string
+	StorageRegion       string
+	StorageEndpoint     string // S3-compatible server (e.g. MinIO); empty uses the provider's
+	StorageAccessKey    string
+	StorageSecretKey    string
+	StorageURLTTL       time.Duration
+	ExportRetentionDays int
+
+	// Read replicas (host or host:port) for reports and listings; credentials
+	// default to the primary's
+	DBReplicaHosts    []string
+	DBReplicaUser     string
+	DBReplicaPassword string
+
+	// Connection pools: the primary serves every write and most reads, so it gets
+	// the larger pool; each replica gets its own DBReplicaMaxOpenConns
+	DBMaxOpenConns        int
+	DBMaxIdleConns        int
+	DBReplicaMaxOpenConns int
+	DBReplicaMaxIdleConns int
+	DBConnMaxLifetime     time.Duration
+	DBConnMaxIdleTime     time.Duration
+
+	// CORS and security headers
+	AllowedOrigins        string
+	CORSAllowedHeaders    string
+	CORSExposedHeaders    string
+	CORSMaxAge            time.Duration
+	ContentSecurityPolicy string
+	FrameOptions          string
+	HSTSMaxAge            int
+
+	// Messaging (SMS/WhatsApp) gateway
+	MessagingProvider         string
+	MessagingAPIURL           string
+	MessagingAPIKey           string
+	MessagingSender           string
+	MessagingCostPerMessage   int
+	MessagingDailyBudget      int
+	MessagingUserDailyLimit   int
+	MessagingTimeout          time.Duration
+	MessagingMaxAttempts      int
+	LargeTransactionThreshold int
+
+	// Email (SMTP) notifications
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	SMTPTimeout  time.Duration
+
+	// Top-ups: points bought with money at TopUpPointPrice rupiah each through the
+	// PaymentProviders (midtrans, manual bank transfer; the first is the default,
+	// none disables top-ups). Unpaid top-ups expire after TopUpExpiry.
+	PaymentProviders    []string
+	TopUpPointPrice     int64
+	TopUpMinPoints      int
+	TopUpMaxPoints      int
+	TopUpExpiry         time.Duration
+	PaymentTimeout      time.Duration
+	MidtransServerKey   string
+	MidtransProduction  bool
+	BankTransferBank    string
+	BankTransferAccount string
+	BankTransferHolder  string
+
+	// LMS integration: course and quiz completions earn points per course mapping.
+	// The LMS pushes events signed with LMSWebhookSecret, and/or lms_sync polls
+	// LMSFeedURL with LMSFeedToken; either is disabled while empty.
+	LMSWebhookSecret string
+	LMSFeedURL       string
+	LMSFeedToken     string
+	LMSTimeout       time.Duration
+
+	// Attendance integration: the campus attendance API pushes events signed with
+	// AttendanceWebhookSecret (disabled while empty); AttendancePoints are the
+	// points per event type
+	AttendanceWebhookSecret string
+	AttendancePoints        map[string]int
+
+	// Library fines: the library system raises fines signed with LibrarySecret
+	// (disabled while empty) and is told of paid fines at LibraryCallbackURL
+	LibrarySecret      string
+	LibraryCallbackURL string
+	LibraryTimeout     time.Duration
+
+	// Canteen POS: tills authenticated by API key charge student pay codes of up
+	// to POSMaxCharge each and POSDailyLimit per student per day (0 disables it),
+	// and may void a charge within POSVoidWindow
+	POSMaxCharge  int
+	POSDailyLimit int
+	POSVoidWindow time.Duration
+	POSPayCodeTTL time.Duration
+
+	// Kiosks: self-service store screens registered at /admin/kiosks, bound to
+	// their device ID. A kiosk order must be confirmed by the student within
+	// KioskOrderTTL.
+	KioskOrderTTL time.Duration
+
+	// Ticket waitlists: a seat freed in a full session is held for the next
+	// waitlisted student for TicketClaimWindow
+	TicketClaimWindow time.Duration
+
+	// Graduate anonymization: students on an uploaded graduation list are
+	// anonymized once they graduated more than AnonymizeAfterYears ago, unless
+	// the upload gives its own number of years
+	AnonymizeAfterYears int
+
+	// Print quota products: purchases add pages on the campus print server at
+	// PrintServerURL (empty disables them); a top-up the server has not accepted
+	// after PrintQuotaMaxAttempts tries is refunded
+	PrintServerURL        string
+	PrintServerToken      string
+	PrintServerTimeout    time.Duration
+	PrintQuotaMaxAttempts int
+
+	// Accounting journal exports: the account code of the points liability and
+	// of each wallet transaction type, and the currency value of one point
+	AccountingAccounts   map[string]string
+	AccountingPointValue int
+
+	// LDAP login for staff accounts of the university directory, off while
+	// LDAPURL is empty. Members of the groups in LDAPGroupRoles (group CN to
+	// role) log in with their directory password; other logins stay local.
+	LDAPURL           string
+	LDAPStartTLS      bool
+	LDAPBindDN        string
+	LDAPBindPassword  string
+	LDAPBaseDN        string
+	LDAPUserFilter    string
+	LDAPNIPAttribute  string
+	LDAPGroupRoles    map[string]string
+	LDAPFallbackLocal bool
+	LDAPTimeout       time.Duration
+
+	// Telegram bot: balance and history commands plus purchase notifications
+	// for users who linked their chat. Off while TelegramBotToken is empty;
+	// updates arrive at /api/v1/integrations/telegram/webhook, set up with
+	// setWebhook and TelegramWebhookSecret as its secret_token.
+	TelegramBotToken      string
+	TelegramBotUsername   string
+	TelegramWebhookSecret string
+	TelegramLinkCodeTTL   time.Duration
+	TelegramTimeout       time.Duration
+
+	// Public storefront API: read-only catalog for the campus marketing site,
+	// authenticated by API keys from /admin/storefront/keys. StorefrontRateLimit
+	// is the requests per minute of a key without its own limit.
+	StorefrontRateLimit  int
+	StorefrontShowPrices bool
+
+	// Google Sheets catalog sync: two-way sync of product names, prices and
+	// status with the CatalogSheetTab tab of spreadsheet CatalogSheetID (empty
+	// disables it), as the service account of GoogleServiceAccountFile
+	CatalogSheetID           string
+	CatalogSheetTab          string
+	GoogleServiceAccountFile string
+	CatalogSheetTimeout      time.Duration
+
+	// Operational alerts (reconciliation mismatches, flagged transactions, dead
+	// jobs, failed payment webhooks) posted to a Slack or Discord webhook, empty
+	// disables them. Each kind posts at most once per AlertCooldown and all
+	// together at most AlertMaxPerHour times an hour (0 = unlimited).
+	AlertWebhookURL string
+	AlertCooldown   time.Duration
+	AlertMaxPerHour int
+
+	// Circuit breakers of external services (SMTP, messaging gateway, payment
+	// providers, each webhook endpoint): consecutive failures before calls are
+	// suspended, and for how long
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+
+	// gRPC API for campus systems (canteen POS, attendance), served only when
+	// GRPCPort is set. Clients authenticate with a certificate signed by GRPCClientCAFile.
+	GRPCPort           string
+	GRPCCertFile       string
+	GRPCKeyFile        string
+	GRPCClientCAFile   string
+	GRPCAllowedClients []string // certificate common names; empty allows any certificate of the CA
+
+	// Background jobs: worker goroutines per queue, e.g. "default=2,exports=1"
+	JobWorkers      map[string]int
+	JobPollInterval time.Duration
+	JobTimeout      time.Duration
+	// Domain events older than this are deleted by domain_event_cleanup
+	EventRetentionDays int
+
+	// Outbound webhooks: per-request timeout and attempts before a delivery is marked failed
+	WebhookTimeout     time.Duration
+	WebhookMaxAttempts int
+
+	// Cron scheduler: jobs listed in SchedulerDisabledJobs are never run
+	SchedulerDisabledJobs []string
+	CartItemTTLDays       int
+	PointExpiryMonths     int
+
+	// Monthly partitions of the transaction and audit tables: partition_maintenance
+	// keeps PartitionMonthsAhead months ready and drops the months older than the
+	// retention of a table, e.g. "audit_logs=24" (tables not listed are kept forever)
+	PartitionMonthsAhead int
+	PartitionRetention   map[string]int
+
+	// Idempotency-Key retention
+	IdempotencyTTL time.Duration
+
+	// Maintenance mode refuses writes with 503: MaintenanceMode forces it on,
+	// otherwise the maintenance_mode feature flag decides. Route prefixes in
+	// MaintenanceAllowedPaths and callers with a MaintenanceAllowedRoles role may still write.
+	MaintenanceMode         bool
+	MaintenanceAllowedPaths []string
+	MaintenanceAllowedRoles []string
+
+	// Inventory
+	LowStockThreshold int
+
+	// Redis cache for product catalog reads; empty RedisURL disables caching
+	RedisURL        string
+	ProductCacheTTL time.Duration
+
+	// How long the totals of the admin transaction and audit listings are reused
+	// before being recounted in the background; 0 counts on every page load
+	ListCountTTL time.Duration
+
+	// Live product updates (SSE) - maximum open streams; 0 disables the stream
+	LiveUpdatesMaxClients int
+
+	// Responses of at least this many bytes are gzip/brotli compressed; 0 disables compression
+	CompressionMinBytes int
+
+	// Observability - when set, /metrics requires "Authorization: Bearer <token>"
+	MetricsToken string
+
+	// Tracing - spans are exported over OTLP/HTTP when an endpoint is set
+	OTelServiceName string
+	OTelEndpoint    string
+	OTelInsecure    bool
+	OTelSampleRatio float64
+}
+
+const defaultJWTSecret = "change-this-secret-key-in-production"
+
+// defaults holds every setting with its fallback value. Keys are the environment
+// variable names in lower case, so config.yaml uses e.g. "db_host: mysql".
+var defaults = map[string]interface{}{
+	"server_host":              "localhost",
+	"server_port":              "8102",
+	"gin_mode":                 "debug",
+	"app_env":                  "development",
+	"log_format":               "",
+	"log_level":                "info",
+	"shutdown_delay_seconds":   0,
+	"shutdown_timeout_seconds": 30,
+
+	"db_host":          "localhost",
+	"db_port":          "3306",
+	"db_user":          "root",
+	"db_password":      "",
+	"db_name":          "wallet_point",
+	"jwt_secret":       defaultJWTSecret,
+	"jwt_expiry_hours": 24,
+
+	"pii_encryption_keys": "",
+
+	"max_upload_size": 10485760, // 10MB
+	"upload_path":     "./public/uploads",
+
+	"storage_driver":          "local",
+	"storage_bucket":          "",
+	"storage_region":          "us-east-1",
+	"storage_endpoint":        "",
+	"storage_access_key":      "",
+	"storage_secret_key":      "",
+	"storage_url_ttl_seconds": 900,
+	"export_retention_days":   7,
+
+	"db_replica_hosts":    "",
+	"db_replica_user":     "",
+	"db_replica_password": "",
+
+	"db_max_open_conns":             100,
+	"db_max_idle_conns":             25,
+	"db_replica_max_open_conns":     50,
+	"db_replica_max_idle_conns":     10,
+	"db_conn_max_lifetime_seconds":  3600,
+	"db_conn_max_idle_time_seconds": 300,
+
+	"allowed_origins":         "https://walletpoint.xeroon.my.id",
+	"cors_allowed_headers":    "Content-Type, Content-Length, Accept, Accept-Encoding, Accept-Language, Authorization, Cache-Control, Origin, X-CSRF-Token, X-Requested-With, Idempotency-Key, If-None-Match, X-Store, X-Tenant-ID, traceparent, tracestate",
+	"cors_exposed_headers":    "Content-Disposition, Content-Language, ETag, Idempotent-Replayed, X-API-Version, X-Request-ID",
+	"cors_max_age_seconds":    600,
+	"content_security_policy": "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; font-src 'self';",
+	"frame_options":           "DENY",
+	"hsts_max_age_seconds":    31536000,
+
+	"messaging_provider":          "log",
+	"messaging_api_url":           "",
+	"messaging_api_key":           "",
+	"messaging_sender":            "WalletPoint",
+	"messaging_cost_per_message":  350,
+	"messaging_daily_budget":      100000,
+	"messaging_user_daily_limit":  5,
+	"messaging_timeout_seconds":   10,
+	"messaging_max_attempts":      3,
+	"large_transaction_threshold": 1000,
+
+	"payment_providers":       "",
+	"topup_point_price":       100,
+	"topup_min_points":        10,
+	"topup_max_points":        10000,
+	"topup_expiry_hours":      24,
+	"payment_timeout_seconds": 15,
+	"midtrans_server_key":     "",
+	"midtrans_production":     false,
+	"bank_transfer_bank":      "",
+	"bank_transfer_account":   "",
+	"bank_transfer_holder":    "",
+
+	"lms_webhook_secret":  "",
+	"lms_feed_url":        "",
+	"lms_feed_token":      "",
+	"lms_timeout_seconds": 15,
+
+	"attendance_webhook_secret": "",
+	"attendance_points":         "lecture=2,seminar=5",
+
+	"library_secret":          "",
+	"library_callback_url":    "",
+	"library_timeout_seconds": 10,
+
+	"pos_max_charge":       1000,
+	"pos_daily_limit":      0,
+	"pos_void_minutes":     10,
+	"pos_pay_code_seconds": 120,
+
+	"kiosk_order_ttl_seconds": 300,
+
+	"ticket_claim_window_minutes": 30,
+
+	"anonymize_after_years": 5,
+
+	"print_server_url":             "",
+	"print_server_token":           "",
+	"print_server_timeout_seconds": 10,
+	"print_quota_max_attempts":     6,
+
+	"accounting_accounts":    "liability=2-1100,mission=6-1100,topup=1-1100,marketplace=4-1100,adjustment=6-1900",
+	"accounting_point_value": 1,
+
+	"ldap_url":             "",
+	"ldap_start_tls":       false,
+	"ldap_bind_dn":         "",
+	"ldap_bind_password":   "",
+	"ldap_base_dn":         "",
+	"ldap_user_filter":     "(&(objectClass=person)(mail=%s))",
+	"ldap_nip_attribute":   "employeeNumber",
+	"ldap_group_roles":     "",
+	"ldap_fallback_local":  true,
+	"ldap_timeout_seconds": 5,
+
+	"telegram_bot_token":         "",
+	"telegram_bot_username":      "",
+	"telegram_webhook_secret":    "",
+	"telegram_link_code_seconds": 600,
+	"telegram_timeout_seconds":   10,
+
+	"storefront_rate_limit":  60,
+	"storefront_show_prices": true,
+
+	"catalog_sheet_id":              "",
+	"catalog_sheet_tab":             "Catalog",
+	"google_service_account_file":   "",
+	"catalog_sheet_timeout_seconds": 15,
+
+	"alert_webhook_url":      "",
+	"alert_cooldown_seconds": 600,
+	"alert_max_per_hour":     30,
+
+	"smtp_host":     "",
+	"smtp_port":     "587",
+	"smtp_username": "",
+	"smtp_password": "",
+	"smtp_from":     "no-reply@walletpoint.xeroon.my.id",
+
+	"smtp_timeout_seconds": 30,
+
+	"circuit_breaker_threshold":        5,
+	"circuit_breaker_cooldown_seconds": 60,
+
+	"grpc_port":            "",
+	"grpc_tls_cert_file":   "",
+	"grpc_tls_key_file":    "",
+	"grpc_client_ca_file":  "",
+	"grpc_allowed_clients": "",
+
+	"job_workers":               "default=2,emails=2,exports=1,webhooks=4,reconciliation=1,events=2",
+	"job_poll_interval_seconds": 5,
+	"job_timeout_seconds":       300,
+	"event_retention_days":      30,
+
+	"webhook_timeout_seconds": 10,
+	"webhook_max_attempts":    8,
+
+	"scheduler_disabled_jobs": "point_expiry",
+	"cart_item_ttl_days":      30,
+	"point_expiry_months":     12,
+
+	"partition_months_ahead": 3,
+	"partition_retention":    "",
+
+	"idempotency_ttl_hours": 24,
+
+	"maintenance_mode":          false,
+	"maintenance_allowed_paths": "/api/v1/auth/login",
+	"maintenance_allowed_roles": "superadmin",
+
+	"low_stock_threshold": 5,
+
+	"redis_url":                 "",
+	"product_cache_ttl_seconds": 60,
+	"list_count_ttl_seconds":    60,
+
+	"live_updates_max_clients": 500,
+
+	"compression_min_bytes": 1024,
+
+	"access_log":                "",
+	"access_log_max_body_bytes": 4096,
+
+	"request_timeout_seconds": 30,
+	"report_timeout_seconds":  120,
+
+	"metrics_token": "",
+
+	"otel_service_name":           "wallet-point",
+	"otel_exporter_otlp_endpoint": "",
+	"otel_exporter_otlp_insecure": false,
+	"otel_traces_sample_ratio":    1.0,
 }
 
+// LoadConfig loads and validates the configuration, exiting with every problem
+// listed when it is invalid so a misconfigured deploy never starts serving
 func LoadConfig() *Config {
+	cfg, err := Load()
+	if err != nil {
+		log.Fatalf("❌ Invalid configuration:\n%v", err)
+	}
+	return cfg
+}
+
+// Load reads the settings from (in order of precedence) environment variables,
+// a .env file and an optional config file, then validates them. The config file
+// is CONFIG_FILE when set, otherwise config.yaml (or .json/.toml) in . or ./config.
+func Load() (*Config, error) {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: .env file not found, using environment variables")
 	}
 
-	// Parse JWT expiry hours
-	jwtExpiry, err := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
-	if err != nil {
-		jwtExpiry = 24
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	if file := os.Getenv("CONFIG_FILE"); file != "" {
+		v.SetConfigFile(file)
+	} else {
+		v.SetConfigName("config")
+		v.AddConfigPath(".")
+		v.AddConfigPath("./config")
+	}
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+	}
+	v.AutomaticEnv()
+
+	r := &reader{v: v}
+
+	serverHost := r.string("server_host")
+	serverPort := r.string("server_port")
+	ginMode := r.string("gin_mode")
+
+	// JSON logs for the log shipper in production, readable text while developing
+	logFormat := r.string("log_format")
+	if logFormat == "" {
+		logFormat = "text"
+		if ginMode == "release" {
+			logFormat = "json"
+		}
+	}
+
+	cfg := &Config{
+		ServerHost:    serverHost,
+		ServerPort:    serverPort,
+		ServerAddress: serverHost + ":" + serverPort,
+		GinMode:       ginMode,
+		AppEnv:        r.string("app_env"),
+		LogFormat:     logFormat,
+		LogLevel:      r.string("log_level"),
+
+		AccessLog:             r.string("access_log"),
+		AccessLogMaxBodyBytes: r.int("access_log_max_body_bytes"),
+
+		RequestTimeout: r.seconds("request_timeout_seconds"),
+		ReportTimeout:  r.seconds("report_timeout_seconds"),
+
+		ShutdownDelay:   r.seconds("shutdown_delay_seconds"),
+		ShutdownTimeout: r.seconds("shutdown_timeout_seconds"),
+
+		DBHost:         r.string("db_host"),
+		DBPort:         r.string("db_port"),
+		DBUser:         r.string("db_user"),
+		DBPassword:     r.string("db_password"),
+		DBName:         r.string("db_name"),
+		JWTSecret:      r.string("jwt_secret"),
+		JWTExpiryHours: r.int("jwt_expiry_hours"),
+
+		PIIEncryptionKeys: r.list("pii_encryption_keys"),
+
+		MaxUploadSize: r.int64("max_upload_size"),
+		UploadPath:    r.string("upload_path"),
+
+		StorageDriver:       r.string("storage_driver"),
+		StorageBucket:       r.string("storage_bucket"),
+		StorageRegion:       r.string("storage_region"),
+		StorageEndpoint:     r.string("storage_endpoint"),
+		StorageAccessKey:    r.string("storage_access_key"),
+		StorageSecretKey:    r.string("storage_secret_key"),
+		StorageURLTTL:       r.seconds("storage_url_ttl_seconds"),
+		ExportRetentionDays: r.int("export_retention_days"),
+
+		DBReplicaHosts:    r.list("db_replica_hosts"),
+		DBReplicaUser:     r.string("db_replica_user"),
+		DBReplicaPassword: r.string("db_replica_password"),
+
+		DBMaxOpenConns:        r.int("db_max_open_conns"),
+		DBMaxIdleConns:        r.int("db_max_idle_conns"),
+		DBReplicaMaxOpenConns: r.int("db_replica_max_open_conns"),
+		DBReplicaMaxIdleConns: r.int("db_replica_max_idle_conns"),
+		DBConnMaxLifetime:     r.seconds("db_conn_max_lifetime_seconds"),
+		DBConnMaxIdleTime:     r.seconds("db_conn_max_idle_time_seconds"),
+
+		AllowedOrigins:        r.string("allowed_origins"),
+		CORSAllowedHeaders:    r.string("cors_allowed_headers"),
+		CORSExposedHeaders:    r.string("cors_exposed_headers"),
+		CORSMaxAge:            r.seconds("cors_max_age_seconds"),
+		ContentSecurityPolicy: r.string("content_security_policy"),
+		FrameOptions:          r.string("frame_options"),
+		HSTSMaxAge:            r.int("hsts_max_age_seconds"),
+
+		MessagingProvider:         r.string("messaging_provider"),
+		MessagingAPIURL:           r.string("messaging_api_url"),
+		MessagingAPIKey:           r.string("messaging_api_key"),
+		MessagingSender:           r.string("messaging_sender"),
+		MessagingCostPerMessage:   r.int("messaging_cost_per_message"),
+		MessagingDailyBudget:      r.int("messaging_daily_budget"),
+		MessagingUserDailyLimit:   r.int("messaging_user_daily_limit"),
+		MessagingTimeout:          r.seconds("messaging_timeout_seconds"),
+		MessagingMaxAttempts:      r.int("messaging_max_attempts"),
+		LargeTransactionThreshold: r.int("large_transaction_threshold"),
+
+		PaymentProviders:    r.list("payment_providers"),
+		TopUpPointPrice:     r.int64("topup_point_price"),
+		TopUpMinPoints:      r.int("topup_min_points"),
+		TopUpMaxPoints:      r.int("topup_max_points"),
+		TopUpExpiry:         time.Duration(r.int64("topup_expiry_hours")) * time.Hour,
+		PaymentTimeout:      r.seconds("payment_timeout_seconds"),
+		MidtransServerKey:   r.string("midtrans_server_key"),
+		MidtransProduction:  r.bool("midtrans_production"),
+		BankTransferBank:    r.string("bank_transfer_bank"),
+		BankTransferAccount: r.string("bank_transfer_account"),
+		BankTransferHolder:  r.string("bank_transfer_holder"),
+
+		LMSWebhookSecret: r.string("lms_webhook_secret"),
+		LMSFeedURL:       r.string("lms_feed_url"),
+		LMSFeedToken:     r.string("lms_feed_token"),
+		LMSTimeout:       r.seconds("lms_timeout_seconds"),
+
+		AttendanceWebhookSecret: r.string("attendance_webhook_secret"),
+		AttendancePoints:        r.pairs("attendance_points", "type=points"),
+
+		LibrarySecret:      r.string("library_secret"),
+		LibraryCallbackURL: r.string("library_callback_url"),
+		LibraryTimeout:     r.seconds("library_timeout_seconds"),
+
+		POSMaxCharge:  r.int("pos_max_charge"),
+		POSDailyLimit: r.int("pos_daily_limit"),
+		POSVoidWindow: time.Duration(r.int64("pos_void_minutes")) * time.Minute,
+		POSPayCodeTTL: r.seconds("pos_pay_code_seconds"),
+
+		KioskOrderTTL: r.seconds("kiosk_order_ttl_seconds"),
+
+		TicketClaimWindow: time.Duration(r.int64("ticket_claim_window_minutes")) * time.Minute,
+
+		AnonymizeAfterYears: r.int("anonymize_after_years"),
+
+		PrintServerURL:        r.string("print_server_url"),
+		PrintServerToken:      r.string("print_server_token"),
+		PrintServerTimeout:    r.seconds("print_server_timeout_seconds"),
+		PrintQuotaMaxAttempts: r.int("print_quota_max_attempts"),
+
+		AccountingAccounts:   r.mapping("accounting_accounts", "name=account"),
+		AccountingPointValue: r.int("accounting_point_value"),
+
+		LDAPURL:           r.string("ldap_url"),
+		LDAPStartTLS:      r.bool("ldap_start_tls"),
+		LDAPBindDN:        r.string("ldap_bind_dn"),
+		LDAPBindPassword:  r.string("ldap_bind_password"),
+		LDAPBaseDN:        r.string("ldap_base_dn"),
+		LDAPUserFilter:    r.string("ldap_user_filter"),
+		LDAPNIPAttribute:  r.string("ldap_nip_attribute"),
+		LDAPGroupRoles:    r.mapping("ldap_group_roles", "group=role"),
+		LDAPFallbackLocal: r.bool("ldap_fallback_local"),
+		LDAPTimeout:       r.seconds("ldap_timeout_seconds"),
+
+		TelegramBotToken:      r.string("telegram_bot_token"),
+		TelegramBotUsername:   strings.TrimPrefix(r.string("telegram_bot_username"), "@"),
+		TelegramWebhookSecret: r.string("telegram_webhook_secret"),
+		TelegramLinkCodeTTL:   r.seconds("telegram_link_code_seconds"),
+		TelegramTimeout:       r.seconds("telegram_timeout_seconds"),
+
+		StorefrontRateLimit:  r.int("storefront_rate_limit"),
+		StorefrontShowPrices: r.bool("storefront_show_prices"),
+
+		CatalogSheetID:           r.string("catalog_sheet_id"),
+		CatalogSheetTab:          r.string("catalog_sheet_tab"),
+		GoogleServiceAccountFile: r.string("google_service_account_file"),
+		CatalogSheetTimeout:      r.seconds("catalog_sheet_timeout_seconds"),
+
+		AlertWebhookURL: r.string("alert_webhook_url"),
+		AlertCooldown:   r.seconds("alert_cooldown_seconds"),
+		AlertMaxPerHour: r.int("alert_max_per_hour"),
+
+		SMTPHost:     r.string("smtp_host"),
+		SMTPPort:     r.string("smtp_port"),
+		SMTPUsername: r.string("smtp_username"),
+		SMTPPassword: r.string("smtp_password"),
+		SMTPFrom:     r.string("smtp_from"),
+		SMTPTimeout:  r.seconds("smtp_timeout_seconds"),
+
+		CircuitBreakerThreshold: r.int("circuit_breaker_threshold"),
+		CircuitBreakerCooldown:  r.seconds("circuit_breaker_cooldown_seconds"),
+
+		GRPCPort:           r.string("grpc_port"),
+		GRPCCertFile:       r.string("grpc_tls_cert_file"),
+		GRPCKeyFile:        r.string("grpc_tls_key_file"),
+		GRPCClientCAFile:   r.string("grpc_client_ca_file"),
+		GRPCAllowedClients: r.list("grpc_allowed_clients"),
+
+		JobWorkers:         r.workers("job_workers"),
+		JobPollInterval:    r.seconds("job_poll_interval_seconds"),
+		JobTimeout:         r.seconds("job_timeout_seconds"),
+		EventRetentionDays: r.int("event_retention_days"),
+
+		WebhookTimeout:     r.seconds("webhook_timeout_seconds"),
+		WebhookMaxAttempts: r.int("webhook_max_attempts"),
+
+		SchedulerDisabledJobs: r.list("scheduler_disabled_jobs"),
+		CartItemTTLDays:       r.int("cart_item_ttl_days"),
+		PointExpiryMonths:     r.int("point_expiry_months"),
+
+		PartitionMonthsAhead: r.int("partition_months_ahead"),
+		PartitionRetention:   r.pairs("partition_retention", "table=months"),
+
+		IdempotencyTTL: time.Duration(r.int64("idempotency_ttl_hours")) * time.Hour,
+
+		MaintenanceMode:         r.bool("maintenance_mode"),
+		MaintenanceAllowedPaths: r.list("maintenance_allowed_paths"),
+		MaintenanceAllowedRoles: r.list("maintenance_allowed_roles"),
+
+		LowStockThreshold: r.int("low_stock_threshold"),
+
+		RedisURL:        r.string("redis_url"),
+		ProductCacheTTL: r.seconds("product_cache_ttl_seconds"),
+		ListCountTTL:    r.seconds("list_count_ttl_seconds"),
+
+		LiveUpdatesMaxClients: r.int("live_updates_max_clients"),
+
+		CompressionMinBytes: r.int("compression_min_bytes"),
+
+		MetricsToken: r.string("metrics_token"),
+
+		OTelServiceName: r.string("otel_service_name"),
+		OTelEndpoint:    r.string("otel_exporter_otlp_endpoint"),
+		OTelInsecure:    r.bool("otel_exporter_otlp_insecure"),
+		OTelSampleRatio: r.float("otel_traces_sample_ratio"),
+	}
+
+	if err := errors.Join(append(r.errs, cfg.Validate())...); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
+
+// reader converts settings strictly, collecting a descriptive error for each
+// malformed value instead of silently falling back to the default
+type reader struct {
+	v    *viper.Viper
+	errs []error
+}
+
+func (r *reader) string(key string) string {
+	return strings.TrimSpace(r.v.GetString(key))
+}
 
-	// Parse max upload size
-	maxUploadSize, err := strconv.ParseInt(getEnv("MAX_UPLOAD_SIZE", "10485760"), 10, 64)
+func (r *reader) int64(key string) int64 {
+	value, err := strconv.ParseInt(r.string(key), 10, 64)
 	if err != nil {
-		maxUploadSize = 10485760 // 10MB default
+		r.errs = append(r.errs, fmt.Errorf("%s: %q is not a whole number", strings.ToUpper(key), r.string(key)))
 	}
+	return value
+}
 
-	serverHost := getEnv("SERVER_HOST", "localhost")
-	serverPort := getEnv("SERVER_PORT", "8102")
-
-	return &Config{
-		ServerHost:     serverHost,
-		ServerPort:     serverPort,
-		ServerAddress:  serverHost + ":" + serverPort,
-		GinMode:        getEnv("GIN_MODE", "debug"),
-		DBHost:         getEnv("DB_HOST", "localhost"),
-		DBPort:         getEnv("DB_PORT", "3306"),
-		DBUser:         getEnv("DB_USER", "root"),
-		DBPassword:     getEnv("DB_PASSWORD", ""),
-		DBName:         getEnv("DB_NAME", "wallet_point"),
-		JWTSecret:      getEnv("JWT_SECRET", "change-this-secret-key-in-production"),
-		JWTExpiryHours: jwtExpiry,
-		AllowedOrigins: getEnv("ALLOWED_ORIGINS", "https://walletpoint.xeroon.my.id"),
-		MaxUploadSize:  maxUploadSize,
-		UploadPath:     getEnv("UPLOAD_PATH", "./uploads"),
+func (r *reader) int(key string) int {
+	return int(r.int64(key))
+}
+
+func (r *reader) seconds(key string) time.Duration {
+	return time.Duration(r.int64(key)) * time.Second
+}
+
+func (r *reader) float(key string) float64 {
+	value, err := strconv.ParseFloat(r.string(key), 64)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: %q is not a number", strings.ToUpper(key), r.string(key)))
 	}
+	return value
 }
 
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+func (r *reader) bool(key string) bool {
+	value, err := strconv.ParseBool(r.string(key))
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: %q is not true or false", strings.ToUpper(key), r.string(key)))
 	}
 	return value
 }
+
+// list parses a comma separated value, skipping empty items
+func (r *reader) list(key string) []string {
+	var items []string
+	for _, item := range strings.Split(r.string(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// workers parses "queue=count,..." into a worker count per queue
+func (r *reader) workers(key string) map[string]int {
+	return r.pairs(key, "queue=count")
+}
+
+// pairs parses "name=number,..." into a number per name; format names the
+// pair for the error message
+func (r *reader) pairs(key, format string) map[string]int {
+	pairs := make(map[string]int)
+	for _, item := range strings.Split(r.string(key), ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, number, ok := strings.Cut(item, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(number))
+		if !ok || strings.TrimSpace(name) == "" || err != nil || n < 0 {
+			r.errs = append(r.errs, fmt.Errorf("%s: %q is not %s", strings.ToUpper(key), item, format))
+			continue
+		}
+		pairs[strings.TrimSpace(name)] = n
+	}
+	return pairs
+}
+
+// mapping parses "name=value,..." into a value per name; format names the
+// pair for the error message
+func (r *reader) mapping(key, format string) map[string]string {
+	mapping := make(map[string]string)
+	for _, item := range strings.Split(r.string(key), ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			r.errs = append(r.errs, fmt.Errorf("%s: %q is not %s", strings.ToUpper(key), item, format))
+			continue
+		}
+		mapping[name] = value
+	}
+	return mapping
+}
@@ -1,28 +1,35 @@
 package config
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
+	"strings"
 	"time"
+	"wallet-point/utils"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
 func ConnectDB(cfg *Config) *gorm.DB {
 	// Build DSN (Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.DBUser,
-		cfg.DBPassword,
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBName,
-	)
-
-	// Configure GORM
+	dsn := buildDSN(cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
+
+	// Configure GORM; SQL statements are only logged at LOG_LEVEL=debug, slow ones always
+	gormLogLevel := logger.Warn
+	if cfg.LogLevel == "debug" {
+		gormLogLevel = logger.Info
+	}
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: logger.New(slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn), logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  gormLogLevel,
+			IgnoreRecordNotFoundError: true,
+		}),
 		NowFunc: func() time.Time {
 			return time.Now().Local()
 		},
@@ -40,16 +47,75 @@ func ConnectDB(cfg *Config) *gorm.DB {
 		log.Fatal("Failed to get database instance:", err)
 	}
 
-	// Connection pool settings
-	sqlDB.SetMaxIdleConns(10)           // Maximum idle connections
-	sqlDB.SetMaxOpenConns(100)          // Maximum open connections
-	sqlDB.SetConnMaxLifetime(time.Hour) // Connection lifetime
+	configurePool(sqlDB, cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg)
 
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
 		log.Fatal("Failed to ping database:", err)
 	}
 
+	if len(cfg.DBReplicaHosts) > 0 {
+		resolver, err := replicaResolver(cfg)
+		if err != nil {
+			log.Fatal("Failed to connect to read replica:", err)
+		}
+		if err := db.Use(resolver); err != nil {
+			log.Fatal("Failed to connect to read replica:", err)
+		}
+		log.Printf("✅ Read replicas configured: %s", strings.Join(cfg.DBReplicaHosts, ", "))
+	}
+
 	log.Println("✅ Database connected successfully")
 	return db
 }
+
+// ReplicaPools returns the connection pools ConnectDB opened for the read replicas,
+// by host, so their usage can be exported next to the primary's
+func ReplicaPools() map[string]*sql.DB {
+	return replicaPools
+}
+
+var replicaPools = map[string]*sql.DB{}
+
+// configurePool applies the pool size and connection lifetimes from the config
+func configurePool(sqlDB *sql.DB, maxOpen, maxIdle int, cfg *Config) {
+	sqlDB.SetMaxOpenConns(maxOpen)
+	sqlDB.SetMaxIdleConns(maxIdle)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+}
+
+// replicaResolver registers the read replicas under utils.ReplicaResolver. Only queries
+// opted in with utils.ReadReplica use them, so wallet reads keep seeing their own writes.
+// The replica pools are opened and sized here rather than through the resolver's
+// SetMaxOpenConns, which would also resize the primary pool.
+func replicaResolver(cfg *Config) (*dbresolver.DBResolver, error) {
+	user, password := cfg.DBUser, cfg.DBPassword
+	if cfg.DBReplicaUser != "" {
+		user, password = cfg.DBReplicaUser, cfg.DBReplicaPassword
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(cfg.DBReplicaHosts))
+	for _, replica := range cfg.DBReplicaHosts {
+		host, port, found := strings.Cut(replica, ":")
+		if !found {
+			port = cfg.DBPort
+		}
+		pool, err := sql.Open("mysql", buildDSN(user, password, host, port, cfg.DBName))
+		if err != nil {
+			return nil, err
+		}
+		configurePool(pool, cfg.DBReplicaMaxOpenConns, cfg.DBReplicaMaxIdleConns, cfg)
+		replicaPools[replica] = pool
+		replicas = append(replicas, mysql.New(mysql.Config{Conn: pool}))
+	}
+
+	return dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}, utils.ReplicaResolver), nil
+}
+
+func buildDSN(user, password, host, port, name string) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", user, password, host, port, name)
+}
@@ -0,0 +1,491 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-point/utils"
+)
+
+// Validate checks the settings the server cannot run correctly without and
+// returns one error per problem, named after the environment variable to fix
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	// Server
+	if !isPort(c.ServerPort) {
+		fail("SERVER_PORT: %q is not a valid port", c.ServerPort)
+	}
+	if !oneOf(c.GinMode, "debug", "release", "test") {
+		fail("GIN_MODE: must be debug, release or test, got %q", c.GinMode)
+	}
+	if c.AppEnv == "" {
+		fail("APP_ENV: is required")
+	}
+	if !oneOf(c.LogFormat, "text", "json") {
+		fail("LOG_FORMAT: must be text or json, got %q", c.LogFormat)
+	}
+	if !oneOf(c.LogLevel, "debug", "info", "warn", "error") {
+		fail("LOG_LEVEL: must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+	if c.ShutdownDelay < 0 {
+		fail("SHUTDOWN_DELAY_SECONDS: must not be negative")
+	}
+	if c.ShutdownTimeout <= 0 {
+		fail("SHUTDOWN_TIMEOUT_SECONDS: must be greater than 0")
+	}
+
+	// Database
+	if c.DBHost == "" {
+		fail("DB_HOST: is required")
+	}
+	if !isPort(c.DBPort) {
+		fail("DB_PORT: %q is not a valid port", c.DBPort)
+	}
+	if c.DBUser == "" {
+		fail("DB_USER: is required")
+	}
+	if c.DBName == "" {
+		fail("DB_NAME: is required")
+	}
+	for _, replica := range c.DBReplicaHosts {
+		if host, port, found := strings.Cut(replica, ":"); host == "" || (found && !isPort(port)) {
+			fail("DB_REPLICA_HOSTS: %q is not host or host:port", replica)
+		}
+	}
+	if c.DBMaxOpenConns < 1 {
+		fail("DB_MAX_OPEN_CONNS: must be at least 1")
+	}
+	if c.DBMaxIdleConns < 0 || c.DBMaxIdleConns > c.DBMaxOpenConns {
+		fail("DB_MAX_IDLE_CONNS: must be between 0 and DB_MAX_OPEN_CONNS (%d)", c.DBMaxOpenConns)
+	}
+	if c.DBReplicaMaxOpenConns < 1 {
+		fail("DB_REPLICA_MAX_OPEN_CONNS: must be at least 1")
+	}
+	if c.DBReplicaMaxIdleConns < 0 || c.DBReplicaMaxIdleConns > c.DBReplicaMaxOpenConns {
+		fail("DB_REPLICA_MAX_IDLE_CONNS: must be between 0 and DB_REPLICA_MAX_OPEN_CONNS (%d)", c.DBReplicaMaxOpenConns)
+	}
+	if c.DBConnMaxLifetime < 0 {
+		fail("DB_CONN_MAX_LIFETIME_SECONDS: must not be negative")
+	}
+	if c.DBConnMaxIdleTime < 0 {
+		fail("DB_CONN_MAX_IDLE_TIME_SECONDS: must not be negative")
+	}
+
+	// JWT - the built-in secret is public, so it is only tolerated outside release mode
+	if c.GinMode == "release" {
+		if c.JWTSecret == defaultJWTSecret {
+			fail("JWT_SECRET: must be changed from the default in release mode")
+		} else if len(c.JWTSecret) < 32 {
+			fail("JWT_SECRET: must be at least 32 characters in release mode")
+		}
+	} else if c.JWTSecret == "" {
+		fail("JWT_SECRET: is required")
+	}
+	if c.JWTExpiryHours <= 0 {
+		fail("JWT_EXPIRY_HOURS: must be greater than 0")
+	}
+	if _, err := utils.ParsePIIKeys(c.PIIEncryptionKeys); err != nil {
+		fail("PII_ENCRYPTION_KEYS: %v", err)
+	} else if c.GinMode == "release" && len(c.PIIEncryptionKeys) == 0 {
+		fail("PII_ENCRYPTION_KEYS: is required in release mode")
+	}
+
+	// Storage
+	if c.UploadPath == "" {
+		fail("UPLOAD_PATH: is required")
+	}
+	if c.MaxUploadSize <= 0 {
+		fail("MAX_UPLOAD_SIZE: must be greater than 0")
+	}
+	switch c.StorageDriver {
+	case "local":
+	case "s3", "gcs":
+		if c.StorageBucket == "" {
+			fail("STORAGE_BUCKET: is required when STORAGE_DRIVER=%s", c.StorageDriver)
+		}
+		if c.StorageAccessKey == "" || c.StorageSecretKey == "" {
+			fail("STORAGE_ACCESS_KEY, STORAGE_SECRET_KEY: are required when STORAGE_DRIVER=%s", c.StorageDriver)
+		}
+		if c.StorageEndpoint != "" && !isURL(c.StorageEndpoint) {
+			fail("STORAGE_ENDPOINT: must be a valid http(s) URL")
+		}
+	default:
+		fail("STORAGE_DRIVER: must be local, s3 or gcs, got %q", c.StorageDriver)
+	}
+	if c.StorageURLTTL <= 0 {
+		fail("STORAGE_URL_TTL_SECONDS: must be greater than 0")
+	}
+	if c.ExportRetentionDays <= 0 {
+		fail("EXPORT_RETENTION_DAYS: must be greater than 0")
+	}
+	if c.EventRetentionDays <= 0 {
+		fail("EVENT_RETENTION_DAYS: must be greater than 0")
+	}
+
+	// Payment notifications (messaging gateway) and email
+	switch c.MessagingProvider {
+	case "log":
+	case "http":
+		if !isURL(c.MessagingAPIURL) {
+			fail("MESSAGING_API_URL: a valid http(s) URL is required when MESSAGING_PROVIDER=http")
+		}
+		if c.MessagingAPIKey == "" {
+			fail("MESSAGING_API_KEY: is required when MESSAGING_PROVIDER=http")
+		}
+	default:
+		fail("MESSAGING_PROVIDER: must be log or http, got %q", c.MessagingProvider)
+	}
+	if c.MessagingTimeout <= 0 {
+		fail("MESSAGING_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	if c.MessagingMaxAttempts <= 0 {
+		fail("MESSAGING_MAX_ATTEMPTS: must be greater than 0")
+	}
+	// Top-ups
+	for _, provider := range c.PaymentProviders {
+		switch provider {
+		case "midtrans":
+			if c.MidtransServerKey == "" {
+				fail("MIDTRANS_SERVER_KEY: is required when PAYMENT_PROVIDERS includes midtrans")
+			}
+		case "manual":
+			if c.BankTransferBank == "" || c.BankTransferAccount == "" || c.BankTransferHolder == "" {
+				fail("BANK_TRANSFER_BANK, BANK_TRANSFER_ACCOUNT, BANK_TRANSFER_HOLDER: are required when PAYMENT_PROVIDERS includes manual")
+			}
+		default:
+			fail("PAYMENT_PROVIDERS: unknown provider %q (midtrans, manual)", provider)
+		}
+	}
+	if c.TopUpPointPrice <= 0 {
+		fail("TOPUP_POINT_PRICE: must be greater than 0")
+	}
+	if c.TopUpMinPoints <= 0 || c.TopUpMaxPoints < c.TopUpMinPoints {
+		fail("TOPUP_MIN_POINTS, TOPUP_MAX_POINTS: need 0 < min <= max")
+	}
+	if c.TopUpExpiry <= 0 {
+		fail("TOPUP_EXPIRY_HOURS: must be greater than 0")
+	}
+	if c.PaymentTimeout <= 0 {
+		fail("PAYMENT_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	// LMS integration
+	if c.LMSWebhookSecret != "" && len(c.LMSWebhookSecret) < 16 {
+		fail("LMS_WEBHOOK_SECRET: must be at least 16 characters")
+	}
+	if c.LMSFeedURL != "" {
+		if !isURL(c.LMSFeedURL) {
+			fail("LMS_FEED_URL: %q is not a valid http(s) URL", c.LMSFeedURL)
+		}
+		if c.LMSFeedToken == "" {
+			fail("LMS_FEED_TOKEN: is required when LMS_FEED_URL is set")
+		}
+	}
+	if c.LMSTimeout <= 0 {
+		fail("LMS_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	// Attendance integration
+	if c.AttendanceWebhookSecret != "" && len(c.AttendanceWebhookSecret) < 16 {
+		fail("ATTENDANCE_WEBHOOK_SECRET: must be at least 16 characters")
+	}
+	for eventType := range c.AttendancePoints {
+		if len(eventType) > 30 {
+			fail("ATTENDANCE_POINTS: event type %q is longer than 30 characters", eventType)
+		}
+	}
+	// Library fines
+	if c.LibrarySecret != "" && len(c.LibrarySecret) < 16 {
+		fail("LIBRARY_SECRET: must be at least 16 characters")
+	}
+	if c.LibraryCallbackURL != "" {
+		if !isURL(c.LibraryCallbackURL) {
+			fail("LIBRARY_CALLBACK_URL: %q is not a valid http(s) URL", c.LibraryCallbackURL)
+		}
+		if c.LibrarySecret == "" {
+			fail("LIBRARY_CALLBACK_URL: needs LIBRARY_SECRET to sign the callbacks")
+		}
+	}
+	if c.LibraryTimeout <= 0 {
+		fail("LIBRARY_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	// Canteen POS
+	if c.POSMaxCharge < 1 {
+		fail("POS_MAX_CHARGE: must be at least 1")
+	}
+	if c.POSDailyLimit < 0 {
+		fail("POS_DAILY_LIMIT: must not be negative")
+	}
+	if c.POSVoidWindow < 0 {
+		fail("POS_VOID_MINUTES: must not be negative")
+	}
+	if c.POSPayCodeTTL < 30*time.Second || c.POSPayCodeTTL > 15*time.Minute {
+		fail("POS_PAY_CODE_SECONDS: must be between 30 and 900")
+	}
+	// Kiosks
+	if c.KioskOrderTTL < 30*time.Second || c.KioskOrderTTL > 30*time.Minute {
+		fail("KIOSK_ORDER_TTL_SECONDS: must be between 30 and 1800")
+	}
+	// Ticket waitlists
+	if c.TicketClaimWindow < 5*time.Minute || c.TicketClaimWindow > 24*time.Hour {
+		fail("TICKET_CLAIM_WINDOW_MINUTES: must be between 5 and 1440")
+	}
+	// Graduate anonymization
+	if c.AnonymizeAfterYears < 1 || c.AnonymizeAfterYears > 50 {
+		fail("ANONYMIZE_AFTER_YEARS: must be between 1 and 50")
+	}
+	// Print quota products
+	if c.PrintServerURL != "" {
+		if !isURL(c.PrintServerURL) {
+			fail("PRINT_SERVER_URL: %q is not a valid http(s) URL", c.PrintServerURL)
+		}
+		if c.PrintServerToken == "" {
+			fail("PRINT_SERVER_TOKEN: is required when PRINT_SERVER_URL is set")
+		}
+	}
+	if c.PrintServerTimeout <= 0 {
+		fail("PRINT_SERVER_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	if c.PrintQuotaMaxAttempts < 1 || c.PrintQuotaMaxAttempts > 20 {
+		fail("PRINT_QUOTA_MAX_ATTEMPTS: must be between 1 and 20")
+	}
+	// Accounting journal: one account per journal line type (see report.JournalTypes)
+	for _, name := range []string{"liability", "mission", "topup", "marketplace", "adjustment"} {
+		if c.AccountingAccounts[name] == "" {
+			fail("ACCOUNTING_ACCOUNTS: missing the %s account", name)
+		}
+	}
+	if c.AccountingPointValue < 1 {
+		fail("ACCOUNTING_POINT_VALUE: must be at least 1")
+	}
+	// LDAP login
+	if c.LDAPURL != "" {
+		if !strings.HasPrefix(c.LDAPURL, "ldap://") && !strings.HasPrefix(c.LDAPURL, "ldaps://") {
+			fail("LDAP_URL: %q must start with ldap:// or ldaps://", c.LDAPURL)
+		}
+		if c.LDAPStartTLS && !strings.HasPrefix(c.LDAPURL, "ldap://") {
+			fail("LDAP_START_TLS: only applies to ldap:// URLs")
+		}
+		if c.LDAPBaseDN == "" {
+			fail("LDAP_BASE_DN: is required when LDAP_URL is set")
+		}
+		if strings.Count(c.LDAPUserFilter, "%s") != 1 || strings.Count(c.LDAPUserFilter, "%") != 1 {
+			fail("LDAP_USER_FILTER: must contain %%s exactly once, for the login email")
+		}
+		if c.LDAPNIPAttribute == "" {
+			fail("LDAP_NIP_ATTRIBUTE: must not be empty")
+		}
+		if len(c.LDAPGroupRoles) == 0 {
+			fail("LDAP_GROUP_ROLES: at least one group=role is required when LDAP_URL is set")
+		}
+		for group, role := range c.LDAPGroupRoles {
+			if role != "admin" && role != "dosen" {
+				fail("LDAP_GROUP_ROLES: group %q maps to %q, expected admin or dosen", group, role)
+			}
+		}
+	}
+	if c.LDAPTimeout <= 0 {
+		fail("LDAP_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	// Telegram bot
+	if c.TelegramBotToken != "" {
+		if !isSecretToken(c.TelegramWebhookSecret) {
+			fail("TELEGRAM_WEBHOOK_SECRET: is required with TELEGRAM_BOT_TOKEN, 1 to 256 characters of A-Z, a-z, 0-9, _ and -")
+		}
+	}
+	if c.TelegramLinkCodeTTL <= 0 {
+		fail("TELEGRAM_LINK_CODE_SECONDS: must be greater than 0")
+	}
+	if c.TelegramTimeout <= 0 {
+		fail("TELEGRAM_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	// Public storefront API
+	if c.StorefrontRateLimit <= 0 {
+		fail("STOREFRONT_RATE_LIMIT: must be greater than 0")
+	}
+	// Google Sheets catalog sync
+	if c.CatalogSheetID != "" {
+		if c.GoogleServiceAccountFile == "" {
+			fail("GOOGLE_SERVICE_ACCOUNT_FILE: is required when CATALOG_SHEET_ID is set")
+		}
+		if c.CatalogSheetTab == "" {
+			fail("CATALOG_SHEET_TAB: must not be empty")
+		}
+	}
+	if c.CatalogSheetTimeout <= 0 {
+		fail("CATALOG_SHEET_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	// Operational alerts
+	if c.AlertWebhookURL != "" && !isURL(c.AlertWebhookURL) {
+		fail("ALERT_WEBHOOK_URL: %q is not a valid http(s) URL", c.AlertWebhookURL)
+	}
+	if c.AlertCooldown < 0 {
+		fail("ALERT_COOLDOWN_SECONDS: must not be negative")
+	}
+	if c.AlertMaxPerHour < 0 {
+		fail("ALERT_MAX_PER_HOUR: must not be negative")
+	}
+	if c.SMTPTimeout <= 0 {
+		fail("SMTP_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	if c.CircuitBreakerThreshold < 0 {
+		fail("CIRCUIT_BREAKER_THRESHOLD: must not be negative")
+	}
+	if c.CircuitBreakerThreshold > 0 && c.CircuitBreakerCooldown <= 0 {
+		fail("CIRCUIT_BREAKER_COOLDOWN_SECONDS: must be greater than 0")
+	}
+	if c.SMTPHost != "" {
+		if !isPort(c.SMTPPort) {
+			fail("SMTP_PORT: %q is not a valid port", c.SMTPPort)
+		}
+		if c.SMTPFrom == "" {
+			fail("SMTP_FROM: is required when SMTP_HOST is set")
+		}
+	}
+
+	// gRPC - mTLS is mandatory, so the certificates are required once a port is set
+	if c.GRPCPort != "" {
+		if !isPort(c.GRPCPort) || c.GRPCPort == c.ServerPort {
+			fail("GRPC_PORT: %q is not a valid port distinct from SERVER_PORT", c.GRPCPort)
+		}
+		if c.GRPCCertFile == "" || c.GRPCKeyFile == "" {
+			fail("GRPC_TLS_CERT_FILE, GRPC_TLS_KEY_FILE: are required when GRPC_PORT is set")
+		}
+		if c.GRPCClientCAFile == "" {
+			fail("GRPC_CLIENT_CA_FILE: is required when GRPC_PORT is set")
+		}
+	}
+
+	// Limits
+	limits := []struct {
+		key   string
+		value int
+	}{
+		{"MESSAGING_COST_PER_MESSAGE", c.MessagingCostPerMessage},
+		{"MESSAGING_DAILY_BUDGET", c.MessagingDailyBudget},
+		{"MESSAGING_USER_DAILY_LIMIT", c.MessagingUserDailyLimit},
+		{"LARGE_TRANSACTION_THRESHOLD", c.LargeTransactionThreshold},
+		{"LOW_STOCK_THRESHOLD", c.LowStockThreshold},
+		{"LIVE_UPDATES_MAX_CLIENTS", c.LiveUpdatesMaxClients},
+		{"COMPRESSION_MIN_BYTES", c.CompressionMinBytes},
+		{"ACCESS_LOG_MAX_BODY_BYTES", c.AccessLogMaxBodyBytes},
+		{"HSTS_MAX_AGE_SECONDS", c.HSTSMaxAge},
+		{"CORS_MAX_AGE_SECONDS", int(c.CORSMaxAge.Seconds())},
+		{"REQUEST_TIMEOUT_SECONDS", int(c.RequestTimeout.Seconds())},
+		{"REPORT_TIMEOUT_SECONDS", int(c.ReportTimeout.Seconds())},
+		{"LIST_COUNT_TTL_SECONDS", int(c.ListCountTTL.Seconds())},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			fail("%s: must not be negative", limit.key)
+		}
+	}
+
+	// Background jobs
+	if c.JobPollInterval <= 0 {
+		fail("JOB_POLL_INTERVAL_SECONDS: must be greater than 0")
+	}
+	if c.JobTimeout <= 0 {
+		fail("JOB_TIMEOUT_SECONDS: must be greater than 0")
+	}
+
+	// Webhooks
+	if c.WebhookTimeout <= 0 {
+		fail("WEBHOOK_TIMEOUT_SECONDS: must be greater than 0")
+	}
+	if c.WebhookMaxAttempts <= 0 {
+		fail("WEBHOOK_MAX_ATTEMPTS: must be greater than 0")
+	}
+	if c.CartItemTTLDays <= 0 {
+		fail("CART_ITEM_TTL_DAYS: must be greater than 0")
+	}
+	if c.PointExpiryMonths <= 0 {
+		fail("POINT_EXPIRY_MONTHS: must be greater than 0")
+	}
+	if c.PartitionMonthsAhead < 1 || c.PartitionMonthsAhead > 24 {
+		fail("PARTITION_MONTHS_AHEAD: must be between 1 and 24")
+	}
+	for table, months := range c.PartitionRetention {
+		switch {
+		case table == "wallet_transactions":
+			fail("PARTITION_RETENTION: wallet_transactions can not be pruned, balances are reconciled against the full ledger")
+		case table != "marketplace_transactions" && table != "audit_logs":
+			fail("PARTITION_RETENTION: unknown table %q (marketplace_transactions, audit_logs)", table)
+		case months < 1:
+			fail("PARTITION_RETENTION: %s must keep at least 1 month", table)
+		}
+	}
+	if c.IdempotencyTTL <= 0 {
+		fail("IDEMPOTENCY_TTL_HOURS: must be greater than 0")
+	}
+
+	// Maintenance mode
+	for _, path := range c.MaintenanceAllowedPaths {
+		if !strings.HasPrefix(path, "/") {
+			fail("MAINTENANCE_ALLOWED_PATHS: %q must be a route path starting with /", path)
+		}
+	}
+	for _, role := range c.MaintenanceAllowedRoles {
+		if !oneOf(role, "superadmin", "admin", "dosen", "mahasiswa") {
+			fail("MAINTENANCE_ALLOWED_ROLES: unknown role %q", role)
+		}
+	}
+
+	// Cache
+	if c.RedisURL != "" && !strings.HasPrefix(c.RedisURL, "redis://") && !strings.HasPrefix(c.RedisURL, "rediss://") {
+		fail("REDIS_URL: must start with redis:// or rediss://")
+	}
+	if c.ProductCacheTTL <= 0 {
+		fail("PRODUCT_CACHE_TTL_SECONDS: must be greater than 0")
+	}
+
+	// CORS and tracing
+	if strings.TrimSpace(c.AllowedOrigins) == "" {
+		fail("ALLOWED_ORIGINS: is required")
+	}
+	if strings.Contains(c.OTelEndpoint, "://") {
+		fail("OTEL_EXPORTER_OTLP_ENDPOINT: must be host:port without a scheme, got %q", c.OTelEndpoint)
+	}
+	if c.OTelSampleRatio < 0 || c.OTelSampleRatio > 1 {
+		fail("OTEL_TRACES_SAMPLE_RATIO: must be between 0 and 1")
+	}
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+func isPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port > 0 && port <= 65535
+}
+
+func isURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// isSecretToken reports whether value is a valid Telegram webhook secret_token
+func isSecretToken(value string) bool {
+	if value == "" || len(value) > 256 {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
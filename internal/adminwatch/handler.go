@@ -0,0 +1,57 @@
+package adminwatch
+
+import (
+	"math"
+	"net/http"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminWatchHandler struct {
+	service *Service
+}
+
+func NewAdminWatchHandler(service *Service) *AdminWatchHandler {
+	return &AdminWatchHandler{service: service}
+}
+
+// GetAll handles listing admin activity alerts (Super admin)
+// @Summary Get admin activity alerts
+// @Description Admins who took an unusual number of sensitive actions (price changes, manual point credits, wallet resets) within the admin_watch window, newest first. Each alert was also sent to the super admins as a notification.
+// @Tags Admin - Activity Alerts
+// @Security BearerAuth
+// @Produce json
+// @Param admin_id query int false "Filter by admin"
+// @Param rule query string false "Filter by watched action" Enums(price_changes, manual_credits, wallet_resets)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=AlertListResponse}
+// @Router /admin/admin-activity-alerts [get]
+func (h *AdminWatchHandler) GetAll(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	adminID, ok := utils.QueryInt(c, "admin_id", 0, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+	rule, ok := utils.QueryEnum(c, "rule", "admin_watch_rule")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetAlerts(c.Request.Context(), AlertListParams{
+		AdminID: uint(adminID),
+		Rule:    rule,
+		Page:    page,
+		Limit:   limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve admin activity alerts", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Admin activity alerts retrieved successfully", "alerts", response.Alerts, response.Pagination, nil)
+}
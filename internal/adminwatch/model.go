@@ -0,0 +1,61 @@
+package adminwatch
+
+import (
+	"time"
+	"wallet-point/internal/settings"
+	"wallet-point/utils"
+)
+
+// Rule is a kind of sensitive admin action the watcher counts in the audit log
+type Rule struct {
+	Name    string // e.g. "price_changes"
+	Label   string // how notifications name the actions, e.g. "perubahan harga produk"
+	Action  string // audit action
+	Details string // LIKE pattern the audit details must match; empty matches any
+	Setting string // threshold setting; 0 turns the rule off
+}
+
+// Rules are the watched actions. The details patterns follow the audit entries
+// of the handlers: a product update names a price only when the price was set.
+var Rules = []Rule{
+	{Name: "price_changes", Label: "perubahan harga produk", Action: "UPDATE_PRODUCT", Details: "%| Price: %", Setting: settings.AdminWatchPriceChanges},
+	{Name: "manual_credits", Label: "penambahan poin manual", Action: "ADJUST_POINTS", Details: "Admin adjusted points: credit %", Setting: settings.AdminWatchManualCredits},
+	{Name: "wallet_resets", Label: "reset dompet", Action: "RESET_WALLET", Setting: settings.AdminWatchWalletResets},
+}
+
+// ActivityAlert records that an admin took Count actions of Rule within
+// [WindowStart, WindowEnd) and the super admins were told. An admin is alerted
+// on once per rule and window.
+type ActivityAlert struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TenantID    uint      `json:"tenant_id" gorm:"not null;default:1;index"`
+	AdminID     uint      `json:"admin_id" gorm:"not null;index"`
+	Rule        string    `json:"rule" gorm:"size:50;not null"`
+	Count       int       `json:"count" gorm:"not null"`
+	Threshold   int       `json:"threshold" gorm:"not null"`
+	WindowStart time.Time `json:"window_start" gorm:"not null"`
+	WindowEnd   time.Time `json:"window_end" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+func (ActivityAlert) TableName() string {
+	return "admin_activity_alerts"
+}
+
+type ActivityAlertWithAdmin struct {
+	ActivityAlert
+	AdminName  string `json:"admin_name"`
+	AdminEmail string `json:"admin_email"`
+}
+
+type AlertListParams struct {
+	AdminID uint
+	Rule    string
+	Page    int
+	Limit   int
+}
+
+type AlertListResponse struct {
+	Alerts []ActivityAlertWithAdmin `json:"alerts"`
+	utils.Pagination
+}
@@ -0,0 +1,90 @@
+package adminwatch
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, alert *ActivityAlert) error {
+	return r.db.WithContext(ctx).Create(alert).Error
+}
+
+// FindAll lists the raised alerts, newest first
+func (r *Repository) FindAll(ctx context.Context, params AlertListParams) ([]ActivityAlertWithAdmin, int64, error) {
+	var alerts []ActivityAlertWithAdmin
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&ActivityAlert{})
+	if params.AdminID != 0 {
+		query = query.Where("admin_activity_alerts.admin_id = ?", params.AdminID)
+	}
+	if params.Rule != "" {
+		query = query.Where("admin_activity_alerts.rule = ?", params.Rule)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.
+		Select("admin_activity_alerts.*, users.full_name AS admin_name, users.email AS admin_email").
+		Joins("LEFT JOIN users ON users.id = admin_activity_alerts.admin_id").
+		Order("admin_activity_alerts.created_at DESC").Order("admin_activity_alerts.id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&alerts).Error
+	return alerts, total, err
+}
+
+// actorCount is the number of actions of a rule one admin took
+type actorCount struct {
+	UserID   uint
+	TenantID uint
+	FullName string
+	Count    int
+}
+
+// countActions returns the admins who took at least threshold actions of rule in [since, until)
+func (r *Repository) countActions(ctx context.Context, rule Rule, since, until time.Time, threshold int) ([]actorCount, error) {
+	var counts []actorCount
+	query := r.db.WithContext(ctx).Table("audit_logs al").
+		Select("al.user_id, u.tenant_id, u.full_name, COUNT(*) AS count").
+		Joins("JOIN users u ON u.id = al.user_id").
+		Where("al.created_at >= ? AND al.created_at < ?", since, until).
+		Where("al.action = ? AND u.role IN ?", rule.Action, []string{"admin", "superadmin"})
+	if rule.Details != "" {
+		query = query.Where("al.details LIKE ?", rule.Details)
+	}
+	err := query.Group("al.user_id, u.tenant_id, u.full_name").
+		Having("COUNT(*) >= ?", threshold).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// alertedSince reports whether adminID was alerted on for rule after since
+func (r *Repository) alertedSince(ctx context.Context, adminID uint, rule string, since time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&ActivityAlert{}).
+		Where("admin_id = ? AND rule = ? AND created_at > ?", adminID, rule, since).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// findSuperAdminIDs returns the IDs of all active super admins
+func (r *Repository) findSuperAdminIDs(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Table("users").
+		Where("role = ? AND status = ? AND deleted_at IS NULL", "superadmin", "active").
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,110 @@
+package adminwatch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/settings"
+	"wallet-point/utils"
+)
+
+// Service watches the audit log for admins taking an unusual volume of
+// sensitive actions and tells the super admins
+type Service struct {
+	repo          *Repository
+	settings      *settings.Service
+	notifications *notification.NotificationService
+}
+
+func NewService(repo *Repository, settingsService *settings.Service, notifications *notification.NotificationService) *Service {
+	return &Service{repo: repo, settings: settingsService, notifications: notifications}
+}
+
+// Watch is the cron job of the watcher: it counts each admin's actions of every
+// rule over the last window and alerts the super admins of those reaching the
+// rule's threshold, once per admin, rule and window
+func (s *Service) Watch(ctx context.Context) (string, error) {
+	window := time.Duration(s.settings.Int(settings.AdminWatchWindowMinutes)) * time.Minute
+	now := time.Now()
+	since := now.Add(-window)
+
+	var superAdmins []uint
+	raised := 0
+	for _, rule := range Rules {
+		threshold := s.settings.Int(rule.Setting)
+		if threshold <= 0 {
+			continue
+		}
+		counts, err := s.repo.countActions(ctx, rule, since, now, threshold)
+		if err != nil {
+			return "", err
+		}
+
+		for _, actor := range counts {
+			alerted, err := s.repo.alertedSince(ctx, actor.UserID, rule.Name, since)
+			if err != nil {
+				return "", err
+			}
+			if alerted {
+				continue
+			}
+			if err := s.repo.Create(ctx, &ActivityAlert{
+				TenantID:    actor.TenantID,
+				AdminID:     actor.UserID,
+				Rule:        rule.Name,
+				Count:       actor.Count,
+				Threshold:   threshold,
+				WindowStart: since,
+				WindowEnd:   now,
+			}); err != nil {
+				return "", err
+			}
+			raised++
+
+			if superAdmins == nil {
+				if superAdmins, err = s.repo.findSuperAdminIDs(ctx); err != nil {
+					return "", err
+				}
+			}
+			s.notify(ctx, superAdmins, actor, rule, threshold, window)
+		}
+	}
+	return fmt.Sprintf("raised %d admin activity alerts", raised), nil
+}
+
+// notify tells the super admins other than the admin in question
+func (s *Service) notify(ctx context.Context, superAdmins []uint, actor actorCount, rule Rule, threshold int, window time.Duration) {
+	var recipients []uint
+	for _, id := range superAdmins {
+		if id != actor.UserID {
+			recipients = append(recipients, id)
+		}
+	}
+	if len(recipients) == 0 {
+		slog.WarnContext(ctx, "admin watch: no super admin to notify", "admin_id", actor.UserID, "rule", rule.Name)
+		return
+	}
+
+	s.notifications.NotifyMany(recipients, notification.NotifyParams{
+		Type:  "admin_activity_alert",
+		Title: fmt.Sprintf("Aktivitas admin tidak biasa: %s", rule.Label),
+		Message: fmt.Sprintf("%s melakukan %d %s dalam %d menit terakhir (batas %d). Periksa log audit admin tersebut.",
+			actor.FullName, actor.Count, rule.Label, int(window.Minutes()), threshold),
+		Link:      fmt.Sprintf("/admin/audit-logs?user_id=%d", actor.UserID),
+		SendEmail: true,
+	})
+}
+
+// GetAlerts returns a page of the raised alerts
+func (s *Service) GetAlerts(ctx context.Context, params AlertListParams) (*AlertListResponse, error) {
+	alerts, total, err := s.repo.FindAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &AlertListResponse{
+		Alerts:     alerts,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
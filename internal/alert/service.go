@@ -0,0 +1,222 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"wallet-point/internal/resilience"
+	"wallet-point/utils"
+)
+
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+
+	deliveryTimeout = 30 * time.Second
+	maxTextLength   = 1500 // Discord rejects messages over 2000 characters
+)
+
+// Alert is one operational event for the on-call channel. Alerts sharing a Key
+// (e.g. "jobs.dead.report.export") are one kind of problem: repeats within the
+// cooldown are counted instead of posted.
+type Alert struct {
+	Key      string
+	Severity string
+	Title    string
+	Text     string
+	Fields   map[string]string
+}
+
+// keyState tracks the last post of one alert key and the repeats held back since
+type keyState struct {
+	lastSent   time.Time
+	suppressed int
+}
+
+// Service posts alerts to a Slack or Discord incoming webhook. Posting happens
+// in the background and never fails the caller. Storms are damped twice: each
+// key posts at most once per cooldown, and at most maxPerHour alerts are posted
+// per hour overall; held back alerts are reported with the next post of their key.
+type Service struct {
+	webhookURL string
+	discord    bool
+	env        string
+	client     *http.Client
+	policy     resilience.Policy
+
+	cooldown   time.Duration
+	maxPerHour int
+
+	mu          sync.Mutex
+	keys        map[string]*keyState
+	hourStart   time.Time
+	sentInHour  int
+	droppedHour int
+}
+
+// NewService returns an alert service posting to webhookURL; Discord webhooks
+// are recognised by their host, anything else gets a Slack payload
+func NewService(webhookURL, env string, cooldown time.Duration, maxPerHour int, policy resilience.Policy) *Service {
+	if policy.Retryable == nil {
+		policy.Retryable = retryableAlertError
+	}
+	discord := false
+	if parsed, err := url.Parse(webhookURL); err == nil {
+		host := strings.ToLower(parsed.Hostname())
+		discord = host == "discord.com" || host == "discordapp.com" || strings.HasSuffix(host, ".discord.com")
+	}
+	return &Service{
+		webhookURL: webhookURL,
+		discord:    discord,
+		env:        env,
+		client:     &http.Client{},
+		policy:     policy,
+		cooldown:   cooldown,
+		maxPerHour: maxPerHour,
+		keys:       make(map[string]*keyState),
+	}
+}
+
+// Send posts the alert unless its key is cooling down or the hourly budget is spent
+func (s *Service) Send(a Alert) {
+	suppressed, ok := s.admit(a.Key, time.Now())
+	if !ok {
+		slog.Debug("alert: suppressed", "key", a.Key)
+		return
+	}
+
+	utils.Go(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		defer cancel()
+		if err := s.post(ctx, a, suppressed); err != nil {
+			slog.Error("alert: post failed", "key", a.Key, "error", err)
+		}
+	})
+}
+
+// admit applies the rate limits, returning how many alerts of the key were held back before this one
+func (s *Service) admit(key string, now time.Time) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.hourStart) >= time.Hour {
+		if s.droppedHour > 0 {
+			slog.Warn("alert: hourly limit dropped alerts", "dropped", s.droppedHour)
+		}
+		s.hourStart, s.sentInHour, s.droppedHour = now, 0, 0
+	}
+
+	state, ok := s.keys[key]
+	if !ok {
+		state = &keyState{}
+		s.keys[key] = state
+	}
+	if !state.lastSent.IsZero() && now.Sub(state.lastSent) < s.cooldown {
+		state.suppressed++
+		return 0, false
+	}
+	if s.maxPerHour > 0 && s.sentInHour >= s.maxPerHour {
+		state.suppressed++
+		s.droppedHour++
+		return 0, false
+	}
+
+	suppressed := state.suppressed
+	state.lastSent, state.suppressed = now, 0
+	s.sentInHour++
+	return suppressed, true
+}
+
+// message renders the alert in the markdown of the chat service
+func (s *Service) message(a Alert, suppressed int) string {
+	icon := ":warning:"
+	if a.Severity == SeverityCritical {
+		icon = ":rotating_light:"
+	}
+	bold := "*"
+	if s.discord {
+		bold = "**"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s[%s] %s%s", icon, bold, s.env, a.Title, bold)
+	if text := a.Text; text != "" {
+		if len(text) > maxTextLength {
+			text = text[:maxTextLength] + "…"
+		}
+		b.WriteString("\n" + text)
+	}
+
+	names := make([]string, 0, len(a.Fields))
+	for name := range a.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n• %s: %s", name, a.Fields[name])
+	}
+
+	if suppressed > 0 {
+		fmt.Fprintf(&b, "\n_%d similar alerts suppressed since the last one_", suppressed)
+	}
+	return b.String()
+}
+
+// alertStatusError is a non-2xx reply of the chat webhook
+type alertStatusError struct {
+	status int
+	body   string
+}
+
+func (e *alertStatusError) Error() string {
+	return fmt.Sprintf("alert webhook returned status %d: %s", e.status, e.body)
+}
+
+// retryableAlertError retries everything except a 4xx other than 429
+func retryableAlertError(err error) bool {
+	statusErr, ok := err.(*alertStatusError)
+	if !ok {
+		return true
+	}
+	return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+}
+
+func (s *Service) post(ctx context.Context, a Alert, suppressed int) error {
+	payload := map[string]string{"text": s.message(a, suppressed)}
+	if s.discord {
+		payload = map[string]string{"content": s.message(a, suppressed)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return s.policy.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return &alertStatusError{status: resp.StatusCode, body: string(body)}
+		}
+		return nil
+	})
+}
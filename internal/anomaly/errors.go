@@ -0,0 +1,11 @@
+package anomaly
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrFlagNotFound = utils.NewAppError("SUSPICIOUS_ACTIVITY_NOT_FOUND", http.StatusNotFound, "suspicious activity not found")
+	ErrFlagReviewed = utils.NewAppError("SUSPICIOUS_ACTIVITY_REVIEWED", http.StatusConflict, "suspicious activity was already reviewed")
+)
@@ -0,0 +1,113 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnomalyHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewAnomalyHandler(service *Service, auditService audit.Logger) *AnomalyHandler {
+	return &AnomalyHandler{service: service, auditService: auditService}
+}
+
+// GetAll handles listing the suspicious activity review queue (Admin)
+// @Summary List suspicious wallet activity
+// @Description Activity flagged by the hourly anomaly detection, newest first, each with the reason it was flagged
+// @Tags Admin - Suspicious Activity
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(open, dismissed, confirmed)
+// @Param rule query string false "Filter by detection rule" Enums(spend_spike, night_new_ip)
+// @Param user_id query int false "Filter by user"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=FlagListResponse}
+// @Router /admin/suspicious-activity [get]
+func (h *AnomalyHandler) GetAll(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "anomaly_status")
+	if !ok {
+		return
+	}
+	rule, ok := utils.QueryEnum(c, "rule", "anomaly_rule")
+	if !ok {
+		return
+	}
+	userID, ok := utils.QueryInt(c, "user_id", 0, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetFlags(c.Request.Context(), FlagListParams{
+		Status: status,
+		Rule:   rule,
+		UserID: uint(userID),
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve suspicious activity", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Suspicious activity retrieved successfully", "flags", response.Flags, response.Pagination, nil)
+}
+
+// Review handles closing a flagged activity (Admin)
+// @Summary Review suspicious wallet activity
+// @Description Dismisses an open flag as legitimate or confirms it as abuse
+// @Tags Admin - Suspicious Activity
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Flag ID"
+// @Param request body ReviewRequest true "Review decision"
+// @Success 200 {object} utils.Response{data=Flag}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/suspicious-activity/{id}/review [post]
+func (h *AnomalyHandler) Review(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid flag ID", nil)
+		return
+	}
+
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	reviewerID := c.GetUint("user_id")
+	flag, err := h.service.Review(c.Request.Context(), uint(id), reviewerID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Suspicious activity reviewed", flag)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    reviewerID,
+		Action:    "REVIEW_SUSPICIOUS_ACTIVITY",
+		Entity:    "SUSPICIOUS_ACTIVITY",
+		EntityID:  flag.ID,
+		Details:   fmt.Sprintf("Marked %s of user %d as %s", flag.Rule, flag.UserID, flag.Status),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,70 @@
+package anomaly
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+// Rules flagging wallet activity
+const (
+	RuleSpendSpike = "spend_spike"  // a day's spending far above the student's average
+	RuleNightNewIP = "night_new_ip" // wallet activity at night from an IP address not used before
+)
+
+const (
+	StatusOpen      = "open"
+	StatusDismissed = "dismissed"
+	StatusConfirmed = "confirmed"
+)
+
+// Flag is an entry of the suspicious activity review queue. Explanation says
+// in words why the activity was flagged; Fingerprint keeps the detection job
+// from flagging the same activity twice.
+type Flag struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TenantID    uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Rule        string     `json:"rule" gorm:"type:enum('spend_spike','night_new_ip');not null"`
+	Explanation string     `json:"explanation" gorm:"size:500;not null"`
+	Amount      int        `json:"amount,omitempty"`   // spend_spike: points spent that day
+	Baseline    int        `json:"baseline,omitempty"` // spend_spike: average daily points of the 30 days before
+	IPAddress   string     `json:"ip_address,omitempty" gorm:"size:45"`
+	OccurredAt  time.Time  `json:"occurred_at" gorm:"not null"`
+	Fingerprint string     `json:"-" gorm:"size:150;uniqueIndex;not null"`
+	Status      string     `json:"status" gorm:"type:enum('open','dismissed','confirmed');default:'open';not null;index"`
+	ReviewedBy  *uint      `json:"reviewed_by"`
+	ReviewNote  string     `json:"review_note,omitempty" gorm:"size:500"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Flag) TableName() string {
+	return "suspicious_activities"
+}
+
+// FlagWithUser is a flag as listed in the review queue
+type FlagWithUser struct {
+	Flag
+	UserName string `json:"user_name"`
+	NimNip   string `json:"nim_nip"`
+}
+
+type FlagListParams struct {
+	Status string
+	Rule   string
+	UserID uint
+	Page   int
+	Limit  int
+}
+
+type FlagListResponse struct {
+	Flags []FlagWithUser `json:"flags"`
+	utils.Pagination
+}
+
+// ReviewRequest closes a flag: dismissed as legitimate or confirmed as abuse
+type ReviewRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=dismissed confirmed" enums:"dismissed,confirmed"`
+	Note     string `json:"note" binding:"max=500"`
+}
@@ -0,0 +1,139 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// walletActions are the audit actions moving points out of or into a wallet,
+// checked by the night activity rule
+var walletActions = []string{
+	"TRANSFER_POINTS", "PURCHASE_PRODUCT", "CART_CHECKOUT",
+	"SEND_POINT_GIFT", "CLAIM_POINT_GIFT", "PAY_LIBRARY_FINE",
+}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create stores a flag unless one with its fingerprint exists; false when it did
+func (r *Repository) Create(ctx context.Context, flag *Flag) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(flag)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Lock loads a flag for update within tx, so it is reviewed once
+func (r *Repository) Lock(tx *gorm.DB, id uint) (*Flag, error) {
+	var flag Flag
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFlagNotFound
+		}
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (r *Repository) Update(tx *gorm.DB, flag *Flag, updates map[string]interface{}) error {
+	return tx.Model(flag).Updates(updates).Error
+}
+
+// FindAll lists the flags of the review queue, newest first
+func (r *Repository) FindAll(ctx context.Context, params FlagListParams) ([]FlagWithUser, int64, error) {
+	var flags []FlagWithUser
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Flag{})
+	if params.Status != "" {
+		query = query.Where("suspicious_activities.status = ?", params.Status)
+	}
+	if params.Rule != "" {
+		query = query.Where("suspicious_activities.rule = ?", params.Rule)
+	}
+	if params.UserID != 0 {
+		query = query.Where("suspicious_activities.user_id = ?", params.UserID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.
+		Select("suspicious_activities.*, users.full_name AS user_name, users.nim_nip").
+		Joins("LEFT JOIN users ON users.id = suspicious_activities.user_id").
+		Order("suspicious_activities.occurred_at DESC").Order("suspicious_activities.id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&flags).Error
+	return flags, total, err
+}
+
+// userSpend is the points a user spent in a period
+type userSpend struct {
+	UserID   uint
+	TenantID uint
+	Total    int
+}
+
+// spendByUser sums the successful debits of [since, until) per user, leaving
+// out admin adjustments. Only users spending minTotal or more are returned;
+// userIDs, when not nil, limits the sum to those users.
+func (r *Repository) spendByUser(ctx context.Context, since, until time.Time, minTotal int, userIDs []uint) ([]userSpend, error) {
+	var spends []userSpend
+	query := r.db.WithContext(ctx).Table("wallet_transactions wt").
+		Select("w.user_id, u.tenant_id, SUM(wt.amount) AS total").
+		Joins("JOIN wallets w ON w.id = wt.wallet_id").
+		Joins("JOIN users u ON u.id = w.user_id").
+		Where("wt.direction = ? AND wt.status = ? AND wt.type <> ?", "debit", "success", "adjustment").
+		Where("wt.created_at >= ? AND wt.created_at < ?", since, until).
+		Group("w.user_id, u.tenant_id")
+	if userIDs != nil {
+		query = query.Where("w.user_id IN ?", userIDs)
+	}
+	if minTotal > 0 {
+		query = query.Having("SUM(wt.amount) >= ?", minTotal)
+	}
+	err := query.Scan(&spends).Error
+	return spends, err
+}
+
+// walletActivity is a wallet action taken by a user, as audited
+type walletActivity struct {
+	UserID    uint
+	TenantID  uint
+	Action    string
+	IPAddress string
+	CreatedAt time.Time
+}
+
+// findWalletActivity returns the audited wallet actions of [since, until) with a known IP address
+func (r *Repository) findWalletActivity(ctx context.Context, since, until time.Time) ([]walletActivity, error) {
+	var activity []walletActivity
+	err := r.db.WithContext(ctx).Table("audit_logs al").
+		Select("al.user_id, u.tenant_id, al.action, al.ip_address, al.created_at").
+		Joins("JOIN users u ON u.id = al.user_id").
+		Where("al.created_at >= ? AND al.created_at < ?", since, until).
+		Where("al.action IN ? AND al.ip_address <> ''", walletActions).
+		Order("al.created_at ASC").
+		Scan(&activity).Error
+	return activity, err
+}
+
+// usedIP reports whether userID took any audited action, logins included, from ip in [since, until)
+func (r *Repository) usedIP(ctx context.Context, userID uint, ip string, since, until time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("audit_logs").
+		Where("user_id = ? AND ip_address = ?", userID, ip).
+		Where("created_at >= ? AND created_at < ?", since, until).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
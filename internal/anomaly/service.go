@@ -0,0 +1,235 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"wallet-point/internal/alert"
+	"wallet-point/internal/settings"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DetectionInterval is the schedule of the detection job; each run covers the interval before it
+	DetectionInterval = time.Hour
+	// baselineDays is the history a user's normal spending and IP addresses are taken from
+	baselineDays      = 30
+	alertFlagExamples = 5
+)
+
+// Service runs the wallet anomaly detection and keeps the review queue of what it flags
+type Service struct {
+	repo     *Repository
+	db       *gorm.DB
+	settings *settings.Service
+	// New flags are posted to the operations channel, enabled with SetAlerts
+	alerts *alert.Service
+}
+
+func NewService(repo *Repository, db *gorm.DB, settingsService *settings.Service) *Service {
+	return &Service{repo: repo, db: db, settings: settingsService}
+}
+
+// SetAlerts posts a summary of the newly flagged activity to the operations channel
+func (s *Service) SetAlerts(alerts *alert.Service) {
+	s.alerts = alerts
+}
+
+// Detect is the cron job of the detection: it compares the wallet activity of
+// the last DetectionInterval with each user's own history and queues what
+// stands out for review. Activity already flagged is not flagged again.
+func (s *Service) Detect(ctx context.Context) (string, error) {
+	end := time.Now().Truncate(DetectionInterval)
+	start := end.Add(-DetectionInterval)
+
+	spikes, err := s.detectSpendSpikes(ctx, start, end)
+	if err != nil {
+		return "", err
+	}
+	night, err := s.detectNightNewIPs(ctx, start, end)
+	if err != nil {
+		return "", err
+	}
+
+	flags := append(spikes, night...)
+	s.alert(flags, start, end)
+	return fmt.Sprintf("flagged %d spend spikes, %d night activities from new IP addresses", len(spikes), len(night)), nil
+}
+
+// detectSpendSpikes flags users whose spending of the day so far is the
+// configured multiple of their average daily spending. Users who spent nothing
+// in the baseline period have no norm yet and are left out.
+func (s *Service) detectSpendSpikes(ctx context.Context, start, end time.Time) ([]Flag, error) {
+	multiplier := s.settings.Int(settings.AnomalySpendMultiplier)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	spends, err := s.repo.spendByUser(ctx, day, end, s.settings.Int(settings.AnomalyMinSpend), nil)
+	if err != nil || len(spends) == 0 {
+		return nil, err
+	}
+	userIDs := make([]uint, len(spends))
+	for i, spend := range spends {
+		userIDs[i] = spend.UserID
+	}
+	history, err := s.repo.spendByUser(ctx, day.AddDate(0, 0, -baselineDays), day, 0, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	baselines := make(map[uint]int, len(history))
+	for _, spend := range history {
+		baselines[spend.UserID] = spend.Total
+	}
+
+	var flags []Flag
+	for _, spend := range spends {
+		past := baselines[spend.UserID]
+		if past == 0 || spend.Total*baselineDays < past*multiplier {
+			continue
+		}
+		average := (past + baselineDays - 1) / baselineDays
+		flag := Flag{
+			TenantID: spend.TenantID,
+			UserID:   spend.UserID,
+			Rule:     RuleSpendSpike,
+			Explanation: fmt.Sprintf("Spent %d points on %s, %.1fx the average of %d points a day over the previous %d days (flagged from %dx)",
+				spend.Total, day.Format("2006-01-02"), float64(spend.Total*baselineDays)/float64(past), average, baselineDays, multiplier),
+			Amount:      spend.Total,
+			Baseline:    average,
+			OccurredAt:  end,
+			Fingerprint: fmt.Sprintf("%s:%d:%s", RuleSpendSpike, spend.UserID, day.Format("20060102")),
+		}
+		created, err := s.repo.Create(ctx, &flag)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			flags = append(flags, flag)
+		}
+	}
+	return flags, nil
+}
+
+// detectNightNewIPs flags wallet actions taken in the configured night hours
+// from an IP address the user did not use in the baseline period
+func (s *Service) detectNightNewIPs(ctx context.Context, start, end time.Time) ([]Flag, error) {
+	from, to := s.settings.Int(settings.AnomalyNightStartHour), s.settings.Int(settings.AnomalyNightEndHour)
+	if from == to {
+		return nil, nil
+	}
+
+	activity, err := s.repo.findWalletActivity(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags []Flag
+	seen := make(map[string]bool)
+	for _, a := range activity {
+		if !atNight(a.CreatedAt.Hour(), from, to) {
+			continue
+		}
+		key := fmt.Sprintf("%d:%s", a.UserID, a.IPAddress)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		used, err := s.repo.usedIP(ctx, a.UserID, a.IPAddress, start.AddDate(0, 0, -baselineDays), start)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			continue
+		}
+		flag := Flag{
+			TenantID: a.TenantID,
+			UserID:   a.UserID,
+			Rule:     RuleNightNewIP,
+			Explanation: fmt.Sprintf("%s at %s from IP address %s, not used by this account in the previous %d days, in the night hours %02d:00-%02d:00",
+				a.Action, a.CreatedAt.Format("2006-01-02 15:04"), a.IPAddress, baselineDays, from, to),
+			IPAddress:   a.IPAddress,
+			OccurredAt:  a.CreatedAt,
+			Fingerprint: fmt.Sprintf("%s:%d:%s:%s", RuleNightNewIP, a.UserID, a.IPAddress, a.CreatedAt.Format("20060102")),
+		}
+		created, err := s.repo.Create(ctx, &flag)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			flags = append(flags, flag)
+		}
+	}
+	return flags, nil
+}
+
+// atNight reports whether hour falls in [from, to), which may wrap past midnight
+func atNight(hour, from, to int) bool {
+	if from < to {
+		return hour >= from && hour < to
+	}
+	return hour >= from || hour < to
+}
+
+func (s *Service) alert(flags []Flag, start, end time.Time) {
+	if s.alerts == nil || len(flags) == 0 {
+		return
+	}
+	var lines []string
+	for i, flag := range flags {
+		if i == alertFlagExamples {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("user %d: %s", flag.UserID, flag.Explanation))
+	}
+	s.alerts.Send(alert.Alert{
+		Key:      "wallet.suspicious_activity",
+		Severity: alert.SeverityWarning,
+		Title:    fmt.Sprintf("%d suspicious wallet activities to review", len(flags)),
+		Text:     strings.Join(lines, "\n"),
+		Fields: map[string]string{
+			"period": fmt.Sprintf("%s to %s", start.Format("15:04"), end.Format("15:04")),
+		},
+	})
+}
+
+// GetFlags returns a page of the review queue
+func (s *Service) GetFlags(ctx context.Context, params FlagListParams) (*FlagListResponse, error) {
+	flags, total, err := s.repo.FindAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &FlagListResponse{
+		Flags:      flags,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// Review closes an open flag with the decision of reviewerID
+func (s *Service) Review(ctx context.Context, id, reviewerID uint, req ReviewRequest) (*Flag, error) {
+	var reviewed *Flag
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		flag, err := s.repo.Lock(tx, id)
+		if err != nil {
+			return err
+		}
+		if flag.Status != StatusOpen {
+			return ErrFlagReviewed
+		}
+		now := time.Now()
+		flag.Status, flag.ReviewedBy, flag.ReviewNote, flag.ReviewedAt = req.Decision, &reviewerID, req.Note, &now
+		if err := s.repo.Update(tx, flag, map[string]interface{}{
+			"status":      flag.Status,
+			"reviewed_by": reviewerID,
+			"review_note": flag.ReviewNote,
+			"reviewed_at": now,
+		}); err != nil {
+			return err
+		}
+		reviewed = flag
+		return nil
+	})
+	return reviewed, err
+}
@@ -0,0 +1,13 @@
+package attendance
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrNotConfigured    = utils.NewAppError("ATTENDANCE_NOT_CONFIGURED", http.StatusNotFound, "attendance integration is not configured")
+	ErrInvalidSignature = utils.NewAppError("ATTENDANCE_INVALID_SIGNATURE", http.StatusUnauthorized, "attendance event signature is missing, invalid or too old")
+	ErrInvalidEvent     = utils.NewAppError("ATTENDANCE_INVALID_EVENT", http.StatusBadRequest, "attendance event is incomplete")
+	ErrInvalidRange     = utils.NewAppError("ATTENDANCE_INVALID_RANGE", http.StatusBadRequest, "from and to must be YYYY-MM-DD dates with from not after to")
+)
@@ -0,0 +1,116 @@
+package attendance
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignatureHeader carries the signature of an attendance event
+const SignatureHeader = "X-Attendance-Signature"
+
+// maxEventBody caps the size of an attendance event
+const maxEventBody = 16 << 10
+
+type AttendanceHandler struct {
+	service *Service
+}
+
+func NewAttendanceHandler(service *Service) *AttendanceHandler {
+	return &AttendanceHandler{service: service}
+}
+
+// ReceiveEvent handles an attendance event pushed by the campus attendance API
+// @Summary Receive attendance event
+// @Description Called by the campus attendance API when a student attends a lecture, seminar or other session. The body is signed in the X-Attendance-Signature header as "t=<unix>,v1=<hex HMAC-SHA256 of "<t>.<body>">" with ATTENDANCE_WEBHOOK_SECRET (the scheme of our outbound webhooks). The event type earns the points set in ATTENDANCE_POINTS. Redelivering an event ID returns the recorded outcome.
+// @Tags Integrations
+// @Accept json
+// @Produce json
+// @Param X-Attendance-Signature header string true "Signature of the body"
+// @Param request body EventPayload true "Attendance event"
+// @Success 200 {object} utils.Response{data=Event}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /integrations/attendance/events [post]
+func (h *AttendanceHandler) ReceiveEvent(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEventBody))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read request body", nil)
+		return
+	}
+	if err := h.service.VerifySignature(c.GetHeader(SignatureHeader), body); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	var payload EventPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, ErrInvalidEvent)
+		return
+	}
+
+	event, err := h.service.Process(c.Request.Context(), payload)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attendance event processed", event)
+}
+
+// GetReport handles the per-student attendance earnings report (Admin)
+// @Summary Attendance earnings report
+// @Description Points each student earned from attendance over a period, highest earners first
+// @Tags Admin - Attendance
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date inclusive (YYYY-MM-DD)"
+// @Param type query string false "Only events of this type (e.g. lecture, seminar)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ReportResponse}
+// @Failure 400 {object} utils.Response
+// @Router /admin/attendance/report [get]
+func (h *AttendanceHandler) GetReport(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetReport(c.Request.Context(), ReportParams{
+		From:  c.Query("from"),
+		To:    c.Query("to"),
+		Type:  c.Query("type"),
+		Page:  page,
+		Limit: limit,
+	})
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.ListResponse(c, "Attendance report retrieved successfully", "students", response.Students, response.Pagination, nil)
+}
+
+// GetMySummary handles a student's own attendance earnings
+// @Summary My attendance earnings
+// @Description Points earned from attendance per event type, with the latest attendance events and why any earned nothing
+// @Tags Attendance
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=Summary}
+// @Router /mahasiswa/attendance [get]
+func (h *AttendanceHandler) GetMySummary(c *gin.Context) {
+	summary, err := h.service.GetSummary(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve attendance summary", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attendance summary retrieved successfully", summary)
+}
@@ -0,0 +1,82 @@
+package attendance
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	StatusCredited = "credited"
+	StatusSkipped  = "skipped"
+)
+
+// Event is an attendance event received from the campus attendance API and
+// what it earned. EventID makes redelivered events no-ops.
+type Event struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	EventID     string    `json:"event_id" gorm:"size:100;uniqueIndex;not null"`
+	Type        string    `json:"type" gorm:"size:30;not null"`
+	SessionID   string    `json:"session_id" gorm:"size:100;not null"`
+	SessionName string    `json:"session_name,omitempty" gorm:"size:255"`
+	StudentNIM  string    `json:"student_nim" gorm:"size:50;not null"`
+	UserID      *uint     `json:"user_id,omitempty" gorm:"index"`
+	Points      int       `json:"points" gorm:"default:0;not null"`
+	Status      string    `json:"status" gorm:"type:enum('credited','skipped');not null"`
+	Reason      string    `json:"reason,omitempty" gorm:"size:255"`
+	AttendedAt  time.Time `json:"attended_at" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (Event) TableName() string {
+	return "attendance_events"
+}
+
+// EventPayload is an attendance event as the attendance API sends it
+type EventPayload struct {
+	ID          string    `json:"id" example:"att-20240912-4411"`
+	Type        string    `json:"type" example:"lecture"`
+	SessionID   string    `json:"session_id" example:"IF-101-W03"`
+	SessionName string    `json:"session_name,omitempty" example:"Algoritma dan Pemrograman - Minggu 3"`
+	StudentNIM  string    `json:"student_nim" example:"2207411001"`
+	AttendedAt  time.Time `json:"attended_at"`
+}
+
+// ReportParams filters the attendance report; From and To are YYYY-MM-DD,
+// both inclusive
+type ReportParams struct {
+	From  string
+	To    string
+	Type  string
+	Page  int
+	Limit int
+}
+
+// StudentTotal is what one student earned from attendance
+type StudentTotal struct {
+	UserID         uint      `json:"user_id"`
+	NimNip         string    `json:"nim_nip"`
+	FullName       string    `json:"full_name"`
+	Attended       int64     `json:"attended"`
+	Points         int64     `json:"points"`
+	LastAttendedAt time.Time `json:"last_attended_at"`
+}
+
+type ReportResponse struct {
+	Students []StudentTotal `json:"students"`
+	utils.Pagination
+}
+
+// TypeTotal is what one event type earned
+type TypeTotal struct {
+	Type     string `json:"type"`
+	Attended int64  `json:"attended"`
+	Points   int64  `json:"points"`
+}
+
+// Summary is a student's own attendance earnings
+type Summary struct {
+	Attended int64       `json:"attended"`
+	Points   int64       `json:"points"`
+	ByType   []TypeTotal `json:"by_type"`
+	Recent   []Event     `json:"recent"`
+}
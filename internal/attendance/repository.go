@@ -0,0 +1,81 @@
+package attendance
+
+import (
+	"context"
+	"time"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db      *gorm.DB
+	replica *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db, replica: utils.ReadReplica(db)}
+}
+
+// credited restricts a query to the credited events attended in [from, to)
+func credited(from, to time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("attendance_events.status = ?", StatusCredited)
+		if !from.IsZero() {
+			db = db.Where("attendance_events.attended_at >= ?", from)
+		}
+		if !to.IsZero() {
+			db = db.Where("attendance_events.attended_at < ?", to)
+		}
+		return db
+	}
+}
+
+// StudentTotals sums the credited events of [from, to) per student, highest
+// earners first; campus admins see their own students
+func (r *Repository) StudentTotals(ctx context.Context, from, to time.Time, eventType string, page, limit int) ([]StudentTotal, int64, error) {
+	var totals []StudentTotal
+	var count int64
+
+	query := r.replica.WithContext(ctx).Table("attendance_events").
+		Joins("INNER JOIN users ON users.id = attendance_events.user_id").
+		Scopes(credited(from, to), utils.ScopeTenant(ctx, "users.tenant_id"))
+	if eventType != "" {
+		query = query.Where("attendance_events.type = ?", eventType)
+	}
+
+	if err := query.Session(&gorm.Session{}).Distinct("attendance_events.user_id").Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.
+		Select("attendance_events.user_id, users.nim_nip, users.full_name, COUNT(*) AS attended, " +
+			"SUM(attendance_events.points) AS points, MAX(attendance_events.attended_at) AS last_attended_at").
+		Group("attendance_events.user_id, users.nim_nip, users.full_name").
+		Order("points DESC").Order("attendance_events.user_id").
+		Limit(limit).Offset(offset).
+		Scan(&totals).Error
+	return totals, count, err
+}
+
+// TypeTotals sums the credited events of a student per event type
+func (r *Repository) TypeTotals(ctx context.Context, userID uint) ([]TypeTotal, error) {
+	var totals []TypeTotal
+	err := r.replica.WithContext(ctx).Table("attendance_events").
+		Select("type, COUNT(*) AS attended, SUM(points) AS points").
+		Scopes(credited(time.Time{}, time.Time{})).
+		Where("user_id = ?", userID).
+		Group("type").Order("type").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// RecentEvents returns the newest events of a student, credited or not
+func (r *Repository) RecentEvents(ctx context.Context, userID uint, limit int) ([]Event, error) {
+	var events []Event
+	err := r.replica.WithContext(ctx).Where("user_id = ?", userID).
+		Order("attended_at DESC").Order("id DESC").
+		Limit(limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,190 @@
+package attendance
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wallet-point/internal/wallet"
+	"wallet-point/internal/webhook"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recentEvents is how many events a student's summary lists
+const recentEvents = 20
+
+const dateLayout = "2006-01-02"
+
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	walletService *wallet.WalletService
+	signatures    webhook.InboundVerifier
+	points        map[string]int
+}
+
+// NewService accepts events signed with secret (without one the integration is
+// disabled) and credits the points of their type; types missing from points
+// earn nothing
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService, secret string, points map[string]int) *Service {
+	return &Service{
+		repo:          repo,
+		db:            db,
+		walletService: walletService,
+		signatures:    webhook.InboundVerifier{Secret: secret, NotConfigured: ErrNotConfigured, Invalid: ErrInvalidSignature},
+		points:        points,
+	}
+}
+
+// VerifySignature checks the signature header of an event body from the attendance API
+func (s *Service) VerifySignature(header string, body []byte) error {
+	return s.signatures.Verify(header, body)
+}
+
+// Process credits the student of an event the points of its type. Events
+// without a known student or for a type without points are kept as skipped.
+// An event ID seen before returns the recorded outcome.
+func (s *Service) Process(ctx context.Context, payload EventPayload) (*Event, error) {
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	event := &Event{
+		EventID:     payload.ID,
+		Type:        payload.Type,
+		SessionID:   payload.SessionID,
+		SessionName: payload.SessionName,
+		StudentNIM:  payload.StudentNIM,
+		Status:      StatusSkipped,
+		AttendedAt:  payload.AttendedAt,
+	}
+	if event.AttendedAt.IsZero() {
+		event.AttendedAt = time.Now()
+	}
+
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return tx.Where("event_id = ?", payload.ID).First(event).Error
+		}
+
+		student, err := wallet.FindStudent(tx, event.StudentNIM)
+		if err != nil {
+			return err
+		}
+		if student == nil {
+			return s.skip(tx, event, "unknown student")
+		}
+		event.UserID = &student.UserID
+
+		points := s.points[event.Type]
+		if points == 0 {
+			return s.skip(tx, event, "event type earns no points")
+		}
+
+		// Attendance rewards are earned like mission rewards
+		if err := s.walletService.CreditWithTransaction(tx, student.WalletID, points, "mission", event.description()); err != nil {
+			return err
+		}
+		event.Status = StatusCredited
+		event.Points = points
+		return tx.Model(event).Updates(map[string]interface{}{
+			"status":  event.Status,
+			"points":  event.Points,
+			"user_id": event.UserID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *Service) skip(tx *gorm.DB, event *Event, reason string) error {
+	event.Reason = reason
+	return tx.Model(event).Updates(map[string]interface{}{"reason": reason, "user_id": event.UserID}).Error
+}
+
+// GetReport lists what each student earned from attendance over a period
+func (s *Service) GetReport(ctx context.Context, params ReportParams) (*ReportResponse, error) {
+	from, to, err := resolveRange(params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	students, total, err := s.repo.StudentTotals(ctx, from, to, params.Type, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ReportResponse{
+		Students:   students,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// GetSummary returns what a student earned from attendance and their latest events
+func (s *Service) GetSummary(ctx context.Context, userID uint) (*Summary, error) {
+	byType, err := s.repo.TypeTotals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.repo.RecentEvents(ctx, userID, recentEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{ByType: byType, Recent: recent}
+	for _, total := range byType {
+		summary.Attended += total.Attended
+		summary.Points += total.Points
+	}
+	return summary, nil
+}
+
+// resolveRange parses the inclusive YYYY-MM-DD bounds into [from, to); an
+// empty bound is open
+func resolveRange(fromDate, toDate string) (from, to time.Time, err error) {
+	if fromDate != "" {
+		if from, err = time.ParseInLocation(dateLayout, fromDate, time.Local); err != nil {
+			return from, to, ErrInvalidRange
+		}
+	}
+	if toDate != "" {
+		if to, err = time.ParseInLocation(dateLayout, toDate, time.Local); err != nil {
+			return from, to, ErrInvalidRange
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return from, to, ErrInvalidRange
+	}
+	return from, to, nil
+}
+
+func (e *Event) description() string {
+	session := e.SessionName
+	if session == "" {
+		session = e.SessionID
+	}
+	return fmt.Sprintf("Attendance: %s %s", e.Type, session)
+}
+
+func (p *EventPayload) validate() error {
+	if p.ID == "" || len(p.ID) > 100 || p.Type == "" || len(p.Type) > 30 ||
+		p.SessionID == "" || len(p.SessionID) > 100 || len(p.SessionName) > 255 ||
+		p.StudentNIM == "" || len(p.StudentNIM) > 50 {
+		return ErrInvalidEvent
+	}
+	return nil
+}
@@ -24,30 +24,41 @@ func NewAuditHandler(service *AuditService) *AuditHandler {
 // @Produce json
 // @Param user_id query int false "Filter by User ID"
 // @Param action query string false "Filter by Action"
+// @Param device_id query string false "Filter by kiosk device ID"
 // @Param date query string false "Filter by Date (YYYY-MM-DD)"
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Opaque cursor from next_cursor; takes precedence over page"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
 // @Success 200 {object} utils.Response{data=AuditListResponse}
 // @Failure 401 {object} utils.Response
 // @Router /admin/audit-logs [get]
 func (h *AuditHandler) GetAll(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
 	userID, _ := strconv.Atoi(c.Query("user_id"))
+	cursor, err := utils.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cursor", nil)
+		return
+	}
 
 	params := AuditListParams{
-		UserID: userID,
-		Action: c.Query("action"),
-		Date:   c.Query("date"),
-		Page:   page,
-		Limit:  limit,
+		UserID:   userID,
+		Action:   c.Query("action"),
+		DeviceID: c.Query("device_id"),
+		Date:     c.Query("date"),
+		Cursor:   cursor,
+		Page:     page,
+		Limit:    limit,
 	}
 
-	response, err := h.service.GetLogs(params)
+	response, err := h.service.GetLogs(c.Request.Context(), params)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve audit logs", err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Audit logs retrieved successfully", response)
+	utils.ListResponse(c, "Audit logs retrieved successfully", "logs", response.Logs, response.Pagination, nil)
 }
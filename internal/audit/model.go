@@ -2,6 +2,7 @@ package audit
 
 import (
 	"time"
+	"wallet-point/utils"
 )
 
 type AuditLog struct {
@@ -13,7 +14,8 @@ type AuditLog struct {
 	Details   string    `json:"details" gorm:"type:text"`
 	IPAddress string    `json:"ip_address" gorm:"size:45"`
 	UserAgent string    `json:"user_agent" gorm:"size:255"`
-	CreatedAt time.Time `json:"created_at"`
+	DeviceID  string    `json:"device_id,omitempty" gorm:"size:100;index"` // kiosk the action was taken at, if any
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`                // partitioning column, see internal/partition
 }
 
 func (AuditLog) TableName() string {
@@ -28,22 +30,22 @@ type CreateAuditParams struct {
 	Details   string
 	IPAddress string
 	UserAgent string
+	DeviceID  string
 }
 
 type AuditListParams struct {
-	UserID int
-	Action string
-	Date   string
-	Page   int
-	Limit  int
+	UserID   int
+	Action   string
+	DeviceID string
+	Date     string
+	Cursor   *utils.Cursor
+	Page     int
+	Limit    int
 }
 
 type AuditListResponse struct {
-	Logs       []AuditLogWithUser `json:"logs"`
-	Total      int64              `json:"total"`
-	Page       int                `json:"page"`
-	Limit      int                `json:"limit"`
-	TotalPages int                `json:"total_pages"`
+	Logs []AuditLogWithUser `json:"logs"`
+	utils.Pagination
 }
 
 type AuditLogWithUser struct {
@@ -1,50 +1,62 @@
 package audit
 
 import (
+	"context"
+	"time"
+	"wallet-point/utils"
+
 	"gorm.io/gorm"
 )
 
 type AuditRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	replica *gorm.DB // admin audit log listing
+	counts  *utils.CountCache
 }
 
 func NewAuditRepository(db *gorm.DB) *AuditRepository {
-	return &AuditRepository{db: db}
+	return &AuditRepository{db: db, replica: utils.ReadReplica(db)}
+}
+
+// SetCountCache makes the log listing reuse recent totals; without it totals are exact
+func (r *AuditRepository) SetCountCache(counts *utils.CountCache) {
+	r.counts = counts
 }
 
 func (r *AuditRepository) Create(log *AuditLog) error {
 	return r.db.Create(log).Error
 }
 
-func (r *AuditRepository) FindAll(params AuditListParams) ([]AuditLogWithUser, int64, error) {
+func (r *AuditRepository) FindAll(ctx context.Context, params AuditListParams) ([]AuditLogWithUser, int64, error) {
 	var logs []AuditLogWithUser
-	var total int64
 
-	query := r.db.Model(&AuditLog{})
+	query := r.replica.WithContext(ctx).Model(&AuditLog{})
 
 	if params.UserID > 0 {
-		query = query.Where("user_id = ?", params.UserID)
+		query = query.Where("audit_logs.user_id = ?", params.UserID)
 	}
 	if params.Action != "" {
-		query = query.Where("action = ?", params.Action)
+		query = query.Where("audit_logs.action = ?", params.Action)
+	}
+	if params.DeviceID != "" {
+		query = query.Where("audit_logs.device_id = ?", params.DeviceID)
 	}
 	if params.Date != "" {
-		query = query.Where("DATE(created_at) = ?", params.Date)
+		// A range on the bare column lets MySQL read only the partition of that month
+		query = query.Where("audit_logs.created_at >= ? AND audit_logs.created_at < ? + INTERVAL 1 DAY", params.Date, params.Date)
 	}
+	// Campus admins see the activity of their own campus's users
+	query = query.Scopes(utils.ScopeTenantOwner(ctx, "audit_logs.user_id", "users"))
 
-	if err := query.Count(&total).Error; err != nil {
+	total, err := r.counts.Count(ctx, query)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	offset := (params.Page - 1) * params.Limit
-
 	// Join with users to get name/role
-	err := r.db.Table("audit_logs").
-		Select("audit_logs.*, users.full_name as user_name, users.role as user_role").
-		Joins("LEFT JOIN users ON users.id = audit_logs.user_id").
-		Order("audit_logs.created_at DESC").
-		Limit(params.Limit).
-		Offset(offset).
+	query = query.Select("audit_logs.*, users.full_name as user_name, users.role as user_role").
+		Joins("LEFT JOIN users ON users.id = audit_logs.user_id")
+	err = utils.Paginate(query, params.Cursor, "audit_logs.created_at", "audit_logs.id", params.Page, params.Limit).
 		Scan(&logs).Error
 
 	if err != nil {
@@ -53,3 +65,14 @@ func (r *AuditRepository) FindAll(params AuditListParams) ([]AuditLogWithUser, i
 
 	return logs, total, nil
 }
+
+// FindBetween hands the logs created in [from, to) to fn in batches, oldest first
+func (r *AuditRepository) FindBetween(ctx context.Context, from, to time.Time, fn func([]AuditLog) error) error {
+	var batch []AuditLog
+	return r.db.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at, id").
+		FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
@@ -1,10 +1,25 @@
 package audit
 
 import (
-	"math"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
 	"time"
+	"wallet-point/internal/storage"
+	"wallet-point/utils"
 )
 
+// Logger records audit entries. Everything outside this package depends on it
+// rather than on AuditService, so tests can pass a recorder.
+type Logger interface {
+	LogActivity(params CreateAuditParams) error
+}
+
+var _ Logger = (*AuditService)(nil)
+
 type AuditService struct {
 	repo *AuditRepository
 }
@@ -23,6 +38,7 @@ func (s *AuditService) LogActivity(params CreateAuditParams) error {
 		Details:   params.Details,
 		IPAddress: params.IPAddress,
 		UserAgent: params.UserAgent,
+		DeviceID:  params.DeviceID,
 		CreatedAt: time.Now(),
 	}
 
@@ -30,7 +46,7 @@ func (s *AuditService) LogActivity(params CreateAuditParams) error {
 }
 
 // GetLogs retrieves logs for admin
-func (s *AuditService) GetLogs(params AuditListParams) (*AuditListResponse, error) {
+func (s *AuditService) GetLogs(ctx context.Context, params AuditListParams) (*AuditListResponse, error) {
 	if params.Page < 1 {
 		params.Page = 1
 	}
@@ -38,18 +54,52 @@ func (s *AuditService) GetLogs(params AuditListParams) (*AuditListResponse, erro
 		params.Limit = 20
 	}
 
-	logs, total, err := s.repo.FindAll(params)
+	logs, total, err := s.repo.FindAll(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+	nextCursor := utils.NextCursor(logs, params.Limit, func(l AuditLogWithUser) (time.Time, uint) {
+		return l.CreatedAt, l.ID
+	})
 
 	return &AuditListResponse{
 		Logs:       logs,
-		Total:      total,
-		Page:       params.Page,
-		Limit:      params.Limit,
-		TotalPages: totalPages,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, nextCursor),
 	}, nil
 }
+
+// Archive writes the logs created in [from, to) to files as gzipped JSON lines,
+// before their partition is dropped, and returns the key of the archive
+func (s *AuditService) Archive(ctx context.Context, files storage.Storage, from, to time.Time) (string, error) {
+	tmp, err := os.CreateTemp("", "audit-archive-*.jsonl.gz")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := gzip.NewWriter(tmp)
+	encoder := json.NewEncoder(zw)
+	err = s.repo.FindBetween(ctx, from, to, func(logs []AuditLog) error {
+		for _, log := range logs {
+			if err := encoder.Encode(log); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s/audit_logs/%s.jsonl.gz", storage.PrefixArchives, from.Format("2006-01"))
+	return key, files.Put(ctx, key, tmp, size, "application/gzip")
+}
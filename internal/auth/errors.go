@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrUserNotFound        = utils.NewAppError("USER_NOT_FOUND", http.StatusNotFound, "user not found")
+	ErrInvalidCredentials  = utils.NewAppError("AUTH_INVALID_CREDENTIALS", http.StatusUnauthorized, "invalid email or password")
+	ErrAccountInactive     = utils.NewAppError("AUTH_ACCOUNT_INACTIVE", http.StatusForbidden, "account is inactive or suspended")
+	ErrEmailTaken          = utils.NewAppError("USER_EMAIL_TAKEN", http.StatusConflict, "email already registered")
+	ErrNimNipTaken         = utils.NewAppError("USER_NIM_NIP_TAKEN", http.StatusConflict, "NIM/NIP already registered")
+	ErrPasswordIncorrect   = utils.NewAppError("AUTH_PASSWORD_INCORRECT", http.StatusBadRequest, "current password incorrect")
+	ErrPinIncorrect        = utils.NewAppError("PIN_INCORRECT", http.StatusBadRequest, "current PIN incorrect")
+	ErrPinRequired         = utils.NewAppError("PIN_REQUIRED", http.StatusBadRequest, "current PIN is required to change to a new one")
+	ErrPinNotSet           = utils.NewAppError("PIN_NOT_SET", http.StatusBadRequest, "transaction PIN has not been set. Please set your PIN in Security settings first.")
+	ErrPinInvalid          = utils.NewAppError("PIN_INVALID", http.StatusBadRequest, "invalid transaction PIN code")
+	ErrTokenGenerateFailed = utils.NewAppError("AUTH_TOKEN_FAILED", http.StatusInternalServerError, "failed to generate token")
+	ErrHashFailed          = utils.NewAppError("AUTH_HASH_FAILED", http.StatusInternalServerError, "failed to secure credentials")
+	ErrCreateUserFailed    = utils.NewAppError("USER_CREATE_FAILED", http.StatusInternalServerError, "failed to create user")
+	ErrRoleNotAllowed      = utils.NewAppError("USER_ROLE_NOT_ALLOWED", http.StatusForbidden, "only super admins can grant this role")
+
+	ErrDirectoryUnavailable  = utils.NewAppError("AUTH_DIRECTORY_UNAVAILABLE", http.StatusServiceUnavailable, "the university directory is unavailable, try again later")
+	ErrDirectoryRoleMissing  = utils.NewAppError("AUTH_DIRECTORY_ROLE_MISSING", http.StatusForbidden, "your directory account is not in a group with access to Wallet Point")
+	ErrDirectoryNIPMissing   = utils.NewAppError("AUTH_DIRECTORY_NIP_MISSING", http.StatusForbidden, "your directory account has no NIP")
+	ErrPasswordManagedByLDAP = utils.NewAppError("AUTH_PASSWORD_MANAGED_BY_DIRECTORY", http.StatusBadRequest, "this account's password is managed in the university directory")
+)
@@ -10,16 +10,16 @@ import (
 
 type AuthHandler struct {
 	service      *AuthService
-	auditService *audit.AuditService
+	auditService audit.Logger
 }
 
-func NewAuthHandler(service *AuthService, auditService *audit.AuditService) *AuthHandler {
+func NewAuthHandler(service *AuthService, auditService audit.Logger) *AuthHandler {
 	return &AuthHandler{service: service, auditService: auditService}
 }
 
 // Login handles user login
 // @Summary User login
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. When LDAP is configured, staff found in the university directory log in with their directory password (the account is created on first login, role from the directory groups); other accounts use their local password
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -27,17 +27,19 @@ func NewAuthHandler(service *AuthService, auditService *audit.AuditService) *Aut
 // @Success 200 {object} utils.Response{data=LoginResponse}
 // @Failure 400 {object} utils.Response
 // @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 503 {object} utils.Response
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	response, err := h.service.Login(req.Email, req.Password)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusUnauthorized, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusUnauthorized, err)
 		return
 	}
 
@@ -57,7 +59,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // Register handles user registration (admin only)
 // @Summary Admin register new user
-// @Description Create a new user account (Admin only)
+// @Description Create a new user account in the admin's campus (Admin only). Super admins choose the campus with tenant_id and may create other super admins.
 // @Tags Admin
 // @Accept json
 // @Produce json
@@ -65,22 +67,28 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Param request body RegisterRequest true "User details"
 // @Success 201 {object} utils.Response{data=User}
 // @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 409 {object} utils.Response
 // @Router /admin/users [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
+	// Campus admins add users to their own campus; only super admins pick one or create super admins
+	if c.GetString("role") != "superadmin" {
+		if req.Role == "superadmin" {
+			utils.ServiceErrorResponse(c, http.StatusForbidden, ErrRoleNotAllowed)
+			return
+		}
+		req.TenantID = c.GetUint("tenant_id")
+	}
+
 	user, err := h.service.Register(&req)
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "email already registered" || err.Error() == "NIM/NIP already registered" {
-			statusCode = http.StatusConflict
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -113,21 +121,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) PublicRegister(c *gin.Context) {
 	var req PublicRegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	user, err := h.service.PublicRegister(&req)
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "email already registered" || err.Error() == "NIM/NIP already registered" {
-			statusCode = http.StatusConflict
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, "Rekening berhasil dibuat. Silakan login.", user)
+	utils.SuccessResponse(c, http.StatusCreated, "Account created successfully. Please log in.", user)
 
 	// Log activity (without UserID as they are not logged in yet)
 	h.auditService.LogActivity(audit.CreateAuditParams{
@@ -163,11 +167,21 @@ func (h *AuthHandler) Me(c *gin.Context) {
 }
 
 // UpdateProfile handles user profile update
+// @Summary Update profile
+// @Description Update the authenticated user's profile
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateProfileRequest true "Profile details"
+// @Success 200 {object} utils.Response{data=User}
+// @Failure 400 {object} utils.Response
+// @Router /auth/profile [put]
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	var req UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
@@ -192,16 +206,25 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 }
 
 // UpdatePassword handles user password change
+// @Summary Change password
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdatePasswordRequest true "Old and new password"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /auth/password [put]
 func (h *AuthHandler) UpdatePassword(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	var req UpdatePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	if err := h.service.UpdatePassword(userID, &req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -219,17 +242,27 @@ func (h *AuthHandler) UpdatePassword(c *gin.Context) {
 	})
 }
 
+// UpdatePin handles setting or changing the wallet PIN
+// @Summary Update PIN
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdatePinRequest true "PIN details"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /auth/pin [put]
 func (h *AuthHandler) UpdatePin(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	var req UpdatePinRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	if err := h.service.UpdatePIN(userID, &req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+)
+
+// LDAPConfig describes the university directory. UserFilter is an LDAP filter
+// with one %s for the (escaped) login email; GroupRoles maps the CN of a group
+// the entry is a memberOf to the role its members get.
+type LDAPConfig struct {
+	URL           string // ldap://host:389 or ldaps://host:636
+	StartTLS      bool
+	BindDN        string // service account used to look up the login
+	BindPassword  string
+	BaseDN        string
+	UserFilter    string
+	NIPAttribute  string
+	GroupRoles    map[string]string
+	Timeout       time.Duration
+	FallbackLocal bool // log local accounts in with their own password while the directory is down
+}
+
+// directoryUser is the directory entry of a successful bind
+type directoryUser struct {
+	Email    string
+	FullName string
+	NIP      string
+	Role     string
+}
+
+// LDAPDirectory authenticates staff by binding to the directory as them
+type LDAPDirectory struct {
+	cfg LDAPConfig
+}
+
+func NewLDAPDirectory(cfg LDAPConfig) *LDAPDirectory {
+	return &LDAPDirectory{cfg: cfg}
+}
+
+// errDirectoryUnavailable wraps connection and service account failures, which
+// say nothing about the user's credentials
+type errDirectoryUnavailable struct {
+	err error
+}
+
+func (e *errDirectoryUnavailable) Error() string {
+	return "directory unavailable: " + e.err.Error()
+}
+
+func (e *errDirectoryUnavailable) Unwrap() error {
+	return e.err
+}
+
+// errNotInDirectory means no entry matched the login, so it is a local account
+var errNotInDirectory = errors.New("login not found in directory")
+
+// Authenticate looks the login up with the service account, then binds as the
+// entry with password. A wrong password is ErrInvalidCredentials; an entry in
+// none of the mapped groups is ErrDirectoryRoleMissing.
+func (d *LDAPDirectory) Authenticate(email, password string) (*directoryUser, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return nil, &errDirectoryUnavailable{err: err}
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(d.cfg.BindDN, d.cfg.BindPassword); err != nil {
+		return nil, &errDirectoryUnavailable{err: fmt.Errorf("service account bind: %w", err)}
+	}
+
+	search := ldap.NewSearchRequest(
+		d.cfg.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(d.cfg.Timeout.Seconds()), false,
+		fmt.Sprintf(d.cfg.UserFilter, ldap.EscapeFilter(email)),
+		[]string{"mail", "cn", "displayName", d.cfg.NIPAttribute, "memberOf"},
+		nil,
+	)
+	result, err := conn.Search(search)
+	if err != nil {
+		return nil, &errDirectoryUnavailable{err: fmt.Errorf("search: %w", err)}
+	}
+	if len(result.Entries) == 0 {
+		return nil, errNotInDirectory
+	}
+	if len(result.Entries) > 1 {
+		return nil, &errDirectoryUnavailable{err: fmt.Errorf("filter matches %d entries for %s", len(result.Entries), email)}
+	}
+	entry := result.Entries[0]
+
+	// An empty password would be an unauthenticated bind, which succeeds
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, &errDirectoryUnavailable{err: fmt.Errorf("user bind: %w", err)}
+	}
+
+	user := &directoryUser{
+		Email:    entry.GetAttributeValue("mail"),
+		FullName: entry.GetAttributeValue("displayName"),
+		NIP:      entry.GetAttributeValue(d.cfg.NIPAttribute),
+		Role:     d.role(entry.GetAttributeValues("memberOf")),
+	}
+	if user.Email == "" {
+		user.Email = email
+	}
+	if user.FullName == "" {
+		user.FullName = entry.GetAttributeValue("cn")
+	}
+	if user.Role == "" {
+		return nil, ErrDirectoryRoleMissing
+	}
+	return user, nil
+}
+
+func (d *LDAPDirectory) connect() (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: d.cfg.Timeout}
+	conn, err := ldap.DialURL(d.cfg.URL, ldap.DialWithDialer(dialer))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(d.cfg.Timeout)
+
+	if d.cfg.StartTLS {
+		serverName := ""
+		if parsed, err := url.Parse(d.cfg.URL); err == nil {
+			serverName = parsed.Hostname()
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: serverName}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// role picks the strongest role of the mapped groups in memberOf (admin over dosen)
+func (d *LDAPDirectory) role(groups []string) string {
+	best := ""
+	for _, group := range groups {
+		dn, err := ldap.ParseDN(group)
+		if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+			continue
+		}
+		rdn := dn.RDNs[0].Attributes[0]
+		if !strings.EqualFold(rdn.Type, "cn") {
+			continue
+		}
+		for cn, role := range d.cfg.GroupRoles {
+			if strings.EqualFold(cn, rdn.Value) && (best == "" || role == "admin") {
+				best = role
+			}
+		}
+	}
+	return best
+}
@@ -2,19 +2,30 @@ package auth
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	Email        string    `json:"email" gorm:"uniqueIndex;not null"`
-	PasswordHash string    `json:"-" gorm:"column:password_hash;not null"`
-	FullName     string    `json:"full_name" gorm:"not null"`
-	NimNip       string    `json:"nim_nip" gorm:"uniqueIndex;not null"`
-	Role         string    `json:"role" gorm:"type:enum('admin','dosen','mahasiswa');not null"`
-	Status       string    `json:"status" gorm:"type:enum('active','inactive','suspended');default:'active'"`
-	PinHash      string    `json:"-" gorm:"column:pin_hash"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	Email        string `json:"email" gorm:"uniqueIndex;not null"`
+	PasswordHash string `json:"-" gorm:"column:password_hash;not null"`
+	FullName     string `json:"full_name" gorm:"not null"`
+	NimNip       string `json:"nim_nip" gorm:"uniqueIndex;not null"`
+	Role         string `json:"role" gorm:"type:enum('superadmin','admin','dosen','mahasiswa');not null"`
+	Status       string `json:"status" gorm:"type:enum('active','inactive','suspended');default:'active'"`
+	TenantID     uint   `json:"tenant_id" gorm:"not null;default:1;index"`
+	PinHash      string `json:"-" gorm:"column:pin_hash;size:255;serializer:pii"`
+	AuthSource   string `json:"auth_source" gorm:"type:enum('local','ldap');default:'local';not null"` // ldap: password checked by the directory
+	// Set by the campus graduation list; anonymized graduates keep their
+	// wallet and orders but no personal data (see user.JobAnonymizeGraduates)
+	GraduatedOn  *time.Time `json:"graduated_on,omitempty" gorm:"type:date;index"`
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Deleted accounts are hidden from every query, so they can no longer log in
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 func (User) TableName() string {
@@ -30,15 +41,21 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
-	NimNip   string `json:"nim_nip" binding:"required"`
-	Role     string `json:"role" binding:"required,oneof=admin dosen mahasiswa"`
+	NimNip   string `json:"nim_nip" binding:"required,nim"`
+	Role     string `json:"role" binding:"required,user_role" enums:"superadmin,admin,dosen,mahasiswa"`
+
+	// Campus of the new user; only super admins choose it, admins always add users to their own campus
+	TenantID uint `json:"tenant_id,omitempty"`
 }
 
 type PublicRegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
-	NimNip   string `json:"nim_nip" binding:"required"`
+	NimNip   string `json:"nim_nip" binding:"required,nim"`
+
+	// Campus code; the main campus when empty
+	Tenant string `json:"tenant,omitempty" binding:"omitempty,max=50"`
 }
 
 type LoginResponse struct {
@@ -53,6 +70,7 @@ type UserSummary struct {
 	NimNip   string `json:"nim_nip"`
 	Role     string `json:"role"`
 	Status   string `json:"status"`
+	TenantID uint   `json:"tenant_id"`
 	Balance  int    `json:"balance,omitempty"`
 }
 type UpdateProfileRequest struct {
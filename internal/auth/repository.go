@@ -20,7 +20,7 @@ func (r *AuthRepository) FindByEmail(email string) (*User, error) {
 	err := r.db.Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -33,7 +33,7 @@ func (r *AuthRepository) FindByID(userID uint) (*User, error) {
 	err := r.db.First(&user, userID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -45,17 +45,17 @@ func (r *AuthRepository) Create(user *User) error {
 	return r.db.Create(user).Error
 }
 
-// CheckEmailExists checks if email already exists
+// CheckEmailExists checks if email already exists, including on deleted accounts
 func (r *AuthRepository) CheckEmailExists(email string) (bool, error) {
 	var count int64
-	err := r.db.Model(&User{}).Where("email = ?", email).Count(&count).Error
+	err := r.db.Unscoped().Model(&User{}).Where("email = ?", email).Count(&count).Error
 	return count > 0, err
 }
 
-// CheckNimNipExists checks if NIM/NIP already exists
+// CheckNimNipExists checks if NIM/NIP already exists, including on deleted accounts
 func (r *AuthRepository) CheckNimNipExists(nimNip string) (bool, error) {
 	var count int64
-	err := r.db.Model(&User{}).Where("nim_nip = ?", nimNip).Count(&count).Error
+	err := r.db.Unscoped().Model(&User{}).Where("nim_nip = ?", nimNip).Count(&count).Error
 	return count > 0, err
 }
 func (r *AuthRepository) Update(userID uint, updates map[string]interface{}) error {
@@ -1,14 +1,19 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"fmt"
+	"log/slog"
+	"wallet-point/internal/tenant"
 	"wallet-point/utils"
 )
 
 type AuthService struct {
 	repo      *AuthRepository
 	jwtExpiry int
+	tenants   *tenant.TenantService
+	directory *LDAPDirectory
 }
 
 func NewAuthService(repo *AuthRepository, jwtExpiry int) *AuthService {
@@ -18,17 +23,55 @@ func NewAuthService(repo *AuthRepository, jwtExpiry int) *AuthService {
 	}
 }
 
+// SetTenants enables campus checks: users of an inactive campus cannot log in, and
+// new users must join an active campus
+func (s *AuthService) SetTenants(tenants *tenant.TenantService) {
+	s.tenants = tenants
+}
+
+// SetDirectory makes staff accounts of the university directory log in with
+// their directory password; logins not in the directory stay local
+func (s *AuthService) SetDirectory(directory *LDAPDirectory) {
+	s.directory = directory
+}
+
 // Login authenticates user and returns JWT token
 func (s *AuthService) Login(email, password string) (*LoginResponse, error) {
+	directoryDown := false
+	if s.directory != nil {
+		user, err := s.loginDirectory(email, password)
+		var unavailable *errDirectoryUnavailable
+		switch {
+		case err == nil:
+			return s.issueToken(user)
+		case errors.As(err, &unavailable):
+			slog.Error("ldap: directory unavailable", "error", err)
+			if !s.directory.cfg.FallbackLocal {
+				return nil, ErrDirectoryUnavailable
+			}
+			directoryDown = true
+		case !errors.Is(err, errNotInDirectory):
+			return nil, err
+		}
+	}
+
 	// Find user by email
 	user, err := s.repo.FindByEmail(email)
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, ErrInvalidCredentials
+	}
+
+	// Directory accounts have no local password to fall back to
+	if user.AuthSource == AuthSourceLDAP {
+		if directoryDown {
+			return nil, ErrDirectoryUnavailable
+		}
+		return nil, ErrInvalidCredentials
 	}
 
 	// Check if user is active
 	if user.Status != "active" {
-		return nil, errors.New("account is inactive or suspended")
+		return nil, ErrAccountInactive
 	}
 
 	// Verify password
@@ -36,16 +79,112 @@ func (s *AuthService) Login(email, password string) (*LoginResponse, error) {
 	if err != nil {
 		// Fallback for legacy plain text passwords during migration/testing
 		if user.PasswordHash == password {
-			fmt.Printf("WARNING: User %s still using plain text password. Please update for security.\n", email)
+			slog.Warn("user still has a plain text password, update it", "email", email)
 		} else {
-			return nil, errors.New("invalid email or password")
+			return nil, ErrInvalidCredentials
+		}
+	}
+
+	return s.issueToken(user)
+}
+
+// loginDirectory binds to the directory as the user and brings their local
+// account in line with the entry, creating it on the first login. The role
+// follows the directory groups, except that super admins keep theirs.
+func (s *AuthService) loginDirectory(email, password string) (*User, error) {
+	entry, err := s.directory.Authenticate(email, password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.FindByEmail(entry.Email)
+	if errors.Is(err, ErrUserNotFound) {
+		return s.createDirectoryUser(entry)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"auth_source": AuthSourceLDAP}
+	if entry.FullName != "" && entry.FullName != user.FullName {
+		updates["full_name"] = entry.FullName
+	}
+	if user.Role != "superadmin" && entry.Role != user.Role {
+		updates["role"] = entry.Role
+	}
+	if err := s.repo.Update(user.ID, updates); err != nil {
+		return nil, err
+	}
+	if user.Role != "superadmin" {
+		user.Role = entry.Role
+	}
+	if entry.FullName != "" {
+		user.FullName = entry.FullName
+	}
+	user.AuthSource = AuthSourceLDAP
+
+	if user.Status != "active" {
+		return nil, ErrAccountInactive
+	}
+	return user, nil
+}
+
+// createDirectoryUser adds a directory account to the main campus. Its local
+// password is random: the directory is asked every time.
+func (s *AuthService) createDirectoryUser(entry *directoryUser) (*User, error) {
+	if entry.NIP == "" {
+		return nil, ErrDirectoryNIPMissing
+	}
+	exists, err := s.repo.CheckNimNipExists(entry.NIP)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrNimNipTaken
+	}
+
+	unusable := make([]byte, 32)
+	if _, err := rand.Read(unusable); err != nil {
+		return nil, ErrHashFailed
+	}
+	hashedPassword, err := utils.HashPassword(hex.EncodeToString(unusable))
+	if err != nil {
+		return nil, ErrHashFailed
+	}
+
+	user := &User{
+		Email:        entry.Email,
+		PasswordHash: hashedPassword,
+		FullName:     entry.FullName,
+		NimNip:       entry.NIP,
+		Role:         entry.Role,
+		Status:       "active",
+		TenantID:     utils.DefaultTenantID,
+		AuthSource:   AuthSourceLDAP,
+	}
+	if err := s.repo.Create(user); err != nil {
+		return nil, ErrCreateUserFailed
+	}
+	slog.Info("ldap: created account of directory user", "user_id", user.ID, "role", user.Role)
+	return user, nil
+}
+
+// issueToken checks the campus of an authenticated user and signs their JWT
+func (s *AuthService) issueToken(user *User) (*LoginResponse, error) {
+	// Super admins are not bound to their campus
+	if user.Role != "superadmin" {
+		if err := s.checkTenant(user.TenantID); err != nil {
+			if errors.Is(err, tenant.ErrTenantInactive) {
+				return nil, ErrAccountInactive
+			}
+			return nil, err
 		}
 	}
 
 	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, s.jwtExpiry)
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, user.TenantID, s.jwtExpiry)
 	if err != nil {
-		return nil, errors.New("failed to generate token")
+		return nil, ErrTokenGenerateFailed
 	}
 
 	return &LoginResponse{
@@ -57,29 +196,56 @@ func (s *AuthService) Login(email, password string) (*LoginResponse, error) {
 			NimNip:   user.NimNip,
 			Role:     user.Role,
 			Status:   user.Status,
+			TenantID: user.TenantID,
 		},
 	}, nil
 }
 
-// Register creates a new user (admin only)
+// Register creates a new user (admin only) in the campus req.TenantID, the main campus when 0
 func (s *AuthService) Register(req *RegisterRequest) (*User, error) {
-	return s.createUser(req.Email, req.Password, req.FullName, req.NimNip, req.Role)
+	tenantID := req.TenantID
+	if tenantID == 0 {
+		tenantID = utils.DefaultTenantID
+	}
+	if err := s.checkTenant(tenantID); err != nil {
+		return nil, err
+	}
+	return s.createUser(req.Email, req.Password, req.FullName, req.NimNip, req.Role, tenantID)
 }
 
 // PublicRegister creates a new mahasiswa user (public)
 func (s *AuthService) PublicRegister(req *PublicRegisterRequest) (*User, error) {
-	return s.createUser(req.Email, req.Password, req.FullName, req.NimNip, "mahasiswa")
+	tenantID := utils.DefaultTenantID
+	if req.Tenant != "" && s.tenants != nil {
+		campus, err := s.tenants.ActiveTenantByCode(req.Tenant)
+		if err != nil {
+			return nil, err
+		}
+		tenantID = campus.ID
+	} else if err := s.checkTenant(tenantID); err != nil {
+		return nil, err
+	}
+	return s.createUser(req.Email, req.Password, req.FullName, req.NimNip, "mahasiswa", tenantID)
+}
+
+// checkTenant fails unless the campus exists and is active
+func (s *AuthService) checkTenant(tenantID uint) error {
+	if s.tenants == nil {
+		return nil
+	}
+	_, err := s.tenants.ActiveTenant(tenantID)
+	return err
 }
 
 // Internal helper to create user
-func (s *AuthService) createUser(email, password, fullName, nimNip, role string) (*User, error) {
+func (s *AuthService) createUser(email, password, fullName, nimNip, role string, tenantID uint) (*User, error) {
 	// Check if email already exists
 	exists, err := s.repo.CheckEmailExists(email)
 	if err != nil {
 		return nil, err
 	}
 	if exists {
-		return nil, errors.New("email already registered")
+		return nil, ErrEmailTaken
 	}
 
 	// Check if NIM/NIP already exists
@@ -88,13 +254,13 @@ func (s *AuthService) createUser(email, password, fullName, nimNip, role string)
 		return nil, err
 	}
 	if exists {
-		return nil, errors.New("NIM/NIP already registered")
+		return nil, ErrNimNipTaken
 	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
-		return nil, errors.New("failed to secure password")
+		return nil, ErrHashFailed
 	}
 
 	// Create user
@@ -105,10 +271,11 @@ func (s *AuthService) createUser(email, password, fullName, nimNip, role string)
 		NimNip:       nimNip,
 		Role:         role,
 		Status:       "active",
+		TenantID:     tenantID,
 	}
 
 	if err := s.repo.Create(user); err != nil {
-		return nil, errors.New("failed to create user")
+		return nil, ErrCreateUserFailed
 	}
 
 	return user, nil
@@ -119,6 +286,11 @@ func (s *AuthService) GetUserByID(userID uint) (*User, error) {
 	return s.repo.FindByID(userID)
 }
 
+// GetUserByEmail gets user by email
+func (s *AuthService) GetUserByEmail(email string) (*User, error) {
+	return s.repo.FindByEmail(email)
+}
+
 // UpdateProfile updates basic user information
 func (s *AuthService) UpdateProfile(userID uint, req *UpdateProfileRequest) (*User, error) {
 	updates := map[string]interface{}{
@@ -137,19 +309,23 @@ func (s *AuthService) UpdatePassword(userID uint, req *UpdatePasswordRequest) er
 		return err
 	}
 
+	if user.AuthSource == AuthSourceLDAP {
+		return ErrPasswordManagedByLDAP
+	}
+
 	// Verify old password
 	err = utils.VerifyPassword(user.PasswordHash, req.OldPassword)
 	if err != nil {
 		// Fallback for legacy plain text
 		if user.PasswordHash != req.OldPassword {
-			return errors.New("current password incorrect")
+			return ErrPasswordIncorrect
 		}
 	}
 
 	// Hash new password
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
-		return errors.New("failed to secure new password")
+		return ErrHashFailed
 	}
 
 	return s.repo.UpdatePassword(userID, hashedPassword)
@@ -166,19 +342,19 @@ func (s *AuthService) UpdatePIN(userID uint, req *UpdatePinRequest) error {
 	if user.PinHash != "" && req.OldPin != "" {
 		err = utils.VerifyPassword(user.PinHash, req.OldPin)
 		if err != nil && user.PinHash != req.OldPin {
-			return errors.New("current PIN incorrect")
+			return ErrPinIncorrect
 		}
 	} else if user.PinHash != "" && req.OldPin == "" {
-		return errors.New("current PIN is required to change to a new one")
+		return ErrPinRequired
 	}
 
 	// Hash new PIN
 	hashedPin, err := utils.HashPassword(req.NewPin)
 	if err != nil {
-		return errors.New("failed to secure new PIN")
+		return ErrHashFailed
 	}
 
-	return s.repo.Update(userID, map[string]interface{}{"pin_hash": hashedPin})
+	return s.repo.Update(userID, map[string]interface{}{"pin_hash": utils.PII(hashedPin)})
 }
 
 // VerifyPIN checks if the provided PIN is correct
@@ -189,13 +365,27 @@ func (s *AuthService) VerifyPIN(userID uint, pin string) error {
 	}
 
 	if user.PinHash == "" {
-		return errors.New("transaction PIN has not been set. Please set your PIN in Security settings first.")
+		return ErrPinNotSet
 	}
 
 	err = utils.VerifyPassword(user.PinHash, pin)
 	if err != nil && user.PinHash != pin {
-		return errors.New("invalid transaction PIN code")
+		return ErrPinInvalid
 	}
 
 	return nil
 }
+
+// ResetPIN sets a new PIN without the old one (caller must have verified the user out of band)
+func (s *AuthService) ResetPIN(userID uint, newPin string) error {
+	if _, err := s.repo.FindByID(userID); err != nil {
+		return err
+	}
+
+	hashedPin, err := utils.HashPassword(newPin)
+	if err != nil {
+		return ErrHashFailed
+	}
+
+	return s.repo.Update(userID, map[string]interface{}{"pin_hash": utils.PII(hashedPin)})
+}
@@ -0,0 +1,11 @@
+package batch
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrInvalidPath   = utils.NewAppError("BATCH_INVALID_PATH", http.StatusBadRequest, "sub-request path must be an API path other than /batch")
+	ErrNotConfigured = utils.NewAppError("BATCH_NOT_CONFIGURED", http.StatusServiceUnavailable, "batch requests are not available")
+)
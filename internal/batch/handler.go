@@ -0,0 +1,43 @@
+package batch
+
+import (
+	"net/http"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BatchHandler struct {
+	service *Service
+}
+
+func NewBatchHandler(service *Service) *BatchHandler {
+	return &BatchHandler{service: service}
+}
+
+// Execute handles a batch of read requests
+// @Summary Batch read requests
+// @Description Run up to 10 GET requests in one round trip, e.g. a home screen's balance, featured products, cart and notifications. Sub-requests run concurrently with the caller's headers (Authorization, Accept-Language) and are authorized and rate limited like direct calls. Each response carries the sub-request's id, HTTP status and JSON body, in request order; the batch itself succeeds even when some sub-requests fail.
+// @Tags Batch
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body BatchRequest true "Sub-requests"
+// @Success 200 {object} utils.Response{data=BatchResponse}
+// @Failure 400 {object} utils.Response
+// @Router /batch [post]
+func (h *BatchHandler) Execute(c *gin.Context) {
+	var req BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	responses, err := h.service.Execute(c.Request, req.Requests)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Batch completed", BatchResponse{Responses: responses})
+}
@@ -0,0 +1,23 @@
+package batch
+
+import "encoding/json"
+
+type SubRequest struct {
+	ID     string `json:"id" binding:"max=64" example:"wallet"`                       // echoed back to match responses
+	Method string `json:"method" binding:"omitempty,oneof=GET" example:"GET"`         // only reads can be batched
+	Path   string `json:"path" binding:"required" example:"/api/v1/mahasiswa/wallet"` // path and query string
+}
+
+type BatchRequest struct {
+	Requests []SubRequest `json:"requests" binding:"required,min=1,max=10,dive"`
+}
+
+type SubResponse struct {
+	ID     string          `json:"id,omitempty"`
+	Status int             `json:"status" example:"200"`
+	Body   json.RawMessage `json:"body" swaggertype:"object"`
+}
+
+type BatchResponse struct {
+	Responses []SubResponse `json:"responses"`
+}
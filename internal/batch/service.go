@@ -0,0 +1,129 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subRequestTimeout stops a slow or streaming endpoint from holding the whole batch
+const subRequestTimeout = 10 * time.Second
+
+// Service replays sub-requests through the router in-process, so every one of
+// them passes the same authentication, role checks and rate limits as a direct call
+type Service struct {
+	target http.Handler
+}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+// SetTarget sets the handler sub-requests are served by. It is set once every
+// route is registered.
+func (s *Service) SetTarget(target http.Handler) {
+	s.target = target
+}
+
+// Execute runs the sub-requests concurrently on behalf of parent, whose headers
+// (Authorization, Accept-Language, ...) they inherit; responses keep the request order
+func (s *Service) Execute(parent *http.Request, requests []SubRequest) ([]SubResponse, error) {
+	if s.target == nil {
+		return nil, ErrNotConfigured
+	}
+
+	targets := make([]*url.URL, len(requests))
+	for i, req := range requests {
+		target, err := parsePath(req.Path)
+		if err != nil {
+			return nil, err
+		}
+		targets[i] = target
+	}
+
+	responses := make([]SubResponse, len(requests))
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = s.serve(parent, requests[i].ID, targets[i])
+		}()
+	}
+	wg.Wait()
+
+	return responses, nil
+}
+
+func (s *Service) serve(parent *http.Request, id string, target *url.URL) SubResponse {
+	ctx, cancel := context.WithTimeout(parent.Context(), subRequestTimeout)
+	defer cancel()
+
+	req := parent.Clone(ctx)
+	req.Method = http.MethodGet
+	req.URL = target
+	req.RequestURI = target.RequestURI()
+	req.Body = http.NoBody
+	req.ContentLength = 0
+	for _, header := range []string{"Content-Type", "Content-Length", "Accept-Encoding", "If-None-Match", "Idempotency-Key"} {
+		req.Header.Del(header)
+	}
+
+	recorder := newRecorder()
+	s.target.ServeHTTP(recorder, req)
+
+	body := recorder.body.Bytes()
+	if !json.Valid(body) {
+		body, _ = json.Marshal(string(body))
+	}
+	return SubResponse{ID: id, Status: recorder.status, Body: body}
+}
+
+// parsePath accepts API paths only, and never /batch itself
+func parsePath(path string) (*url.URL, error) {
+	target, err := url.Parse(path)
+	if err != nil || target.IsAbs() || target.Host != "" || !strings.HasPrefix(target.Path, "/api/") {
+		return nil, ErrInvalidPath
+	}
+	if strings.Contains(target.Path, "..") || strings.HasSuffix(strings.TrimSuffix(target.Path, "/"), "/batch") {
+		return nil, ErrInvalidPath
+	}
+	return target, nil
+}
+
+// recorder buffers a sub-response. Flush is a no-op so handlers that stream
+// still work; they are cut off by subRequestTimeout.
+type recorder struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(p)
+}
+
+func (r *recorder) Flush() {}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+	"wallet-point/internal/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache is a JSON read-through cache on Redis. A nil client (no REDIS_URL)
+// disables it: every lookup misses and writes are no-ops, so callers never
+// need to check whether caching is configured.
+type Cache struct {
+	client *redis.Client
+	prefix string
+}
+
+// New connects to url (redis://[:password@]host:port/db); an empty url returns a disabled cache
+func New(url, prefix string) (*Cache, error) {
+	if url == "" {
+		return &Cache{prefix: prefix}, nil
+	}
+
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{client: redis.NewClient(options), prefix: prefix}, nil
+}
+
+func (c *Cache) Enabled() bool {
+	return c.client != nil
+}
+
+// GetJSON decodes the value stored at key into dest and reports whether it was found.
+// Redis errors count as a miss so reads fall back to the database.
+func (c *Cache) GetJSON(ctx context.Context, name, key string, dest interface{}) bool {
+	if c.client == nil {
+		return false
+	}
+
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			metrics.CacheRequests.WithLabelValues(name, "miss").Inc()
+		} else {
+			metrics.CacheRequests.WithLabelValues(name, "error").Inc()
+			slog.WarnContext(ctx, "cache: get failed", "key", key, "error", err)
+		}
+		return false
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		metrics.CacheRequests.WithLabelValues(name, "error").Inc()
+		return false
+	}
+
+	metrics.CacheRequests.WithLabelValues(name, "hit").Inc()
+	return true
+}
+
+// SetJSON stores value at key for ttl; failures are logged and otherwise ignored
+func (c *Cache) SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if c.client == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := c.client.Set(ctx, c.prefix+key, data, ttl).Err(); err != nil {
+		slog.WarnContext(ctx, "cache: set failed", "key", key, "error", err)
+	}
+}
+
+// Delete removes keys
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if c.client == nil || len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+// Generation returns the counter stored at key (0 when missing). Embedding it in
+// the keys of derived entries (e.g. list pages) lets Bump invalidate all of them at once.
+func (c *Cache) Generation(ctx context.Context, key string) (int64, error) {
+	if c.client == nil {
+		return 0, nil
+	}
+
+	generation, err := c.client.Get(ctx, c.prefix+key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return generation, err
+}
+
+// Bump increments the generation counter at key
+func (c *Cache) Bump(ctx context.Context, key string) error {
+	if c.client == nil {
+		return nil
+	}
+	return c.client.Incr(ctx, c.prefix+key).Err()
+}
+
+// Check pings Redis, for the readiness probe
+func (c *Cache) Check(ctx context.Context) error {
+	if c.client == nil {
+		return nil
+	}
+	return c.client.Ping(ctx).Err()
+}
+
+// Response groups of the response cache middleware. Each group has a
+// generation counter in the keys of its responses, so a change to the data
+// behind a group drops all of them with InvalidateResponses.
+const (
+	ResponseCatalog = "catalog" // public product and category listings
+)
+
+// ResponseGenerationKey is the generation counter of a response group
+func ResponseGenerationKey(group string) string {
+	return "responses:" + group + ":generation"
+}
+
+// InvalidateResponses drops every cached response of groups
+func (c *Cache) InvalidateResponses(ctx context.Context, groups ...string) error {
+	for _, group := range groups {
+		if err := c.Bump(ctx, ResponseGenerationKey(group)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
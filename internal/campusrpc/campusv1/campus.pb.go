@@ -0,0 +1,516 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: campus/v1/campus.proto
+
+// Internal API for campus systems such as the canteen POS and attendance.
+// Served over mTLS only; regenerate the Go code with:
+//   protoc -I proto --go_out=. --go_opt=module=wallet-point \
+//     --go-grpc_out=. --go-grpc_opt=module=wallet-point campus/v1/campus.proto
+
+package campusv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WalletOperationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// NIM/NIP of the wallet owner
+	NimNip string `protobuf:"bytes,1,opt,name=nim_nip,json=nimNip,proto3" json:"nim_nip,omitempty"`
+	Amount int64  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Unique per calling system, at most 100 characters. Retrying with the same
+	// reference returns the original result instead of moving points twice.
+	Reference     string `protobuf:"bytes,3,opt,name=reference,proto3" json:"reference,omitempty"`
+	Description   string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletOperationRequest) Reset() {
+	*x = WalletOperationRequest{}
+	mi := &file_campus_v1_campus_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletOperationRequest) ProtoMessage() {}
+
+func (x *WalletOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_campus_v1_campus_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletOperationRequest.ProtoReflect.Descriptor instead.
+func (*WalletOperationRequest) Descriptor() ([]byte, []int) {
+	return file_campus_v1_campus_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WalletOperationRequest) GetNimNip() string {
+	if x != nil {
+		return x.NimNip
+	}
+	return ""
+}
+
+func (x *WalletOperationRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *WalletOperationRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *WalletOperationRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type WalletOperationResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	OperationId uint64                 `protobuf:"varint,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	WalletId    uint64                 `protobuf:"varint,2,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	// Balance right after the operation
+	Balance int64 `protobuf:"varint,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	// True when the reference was already used and nothing was changed
+	Replayed      bool `protobuf:"varint,4,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletOperationResponse) Reset() {
+	*x = WalletOperationResponse{}
+	mi := &file_campus_v1_campus_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletOperationResponse) ProtoMessage() {}
+
+func (x *WalletOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_campus_v1_campus_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletOperationResponse.ProtoReflect.Descriptor instead.
+func (*WalletOperationResponse) Descriptor() ([]byte, []int) {
+	return file_campus_v1_campus_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WalletOperationResponse) GetOperationId() uint64 {
+	if x != nil {
+		return x.OperationId
+	}
+	return 0
+}
+
+func (x *WalletOperationResponse) GetWalletId() uint64 {
+	if x != nil {
+		return x.WalletId
+	}
+	return 0
+}
+
+func (x *WalletOperationResponse) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *WalletOperationResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+type GetWalletRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NimNip        string                 `protobuf:"bytes,1,opt,name=nim_nip,json=nimNip,proto3" json:"nim_nip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWalletRequest) Reset() {
+	*x = GetWalletRequest{}
+	mi := &file_campus_v1_campus_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWalletRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWalletRequest) ProtoMessage() {}
+
+func (x *GetWalletRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_campus_v1_campus_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWalletRequest.ProtoReflect.Descriptor instead.
+func (*GetWalletRequest) Descriptor() ([]byte, []int) {
+	return file_campus_v1_campus_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetWalletRequest) GetNimNip() string {
+	if x != nil {
+		return x.NimNip
+	}
+	return ""
+}
+
+type Wallet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WalletId      uint64                 `protobuf:"varint,1,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	NimNip        string                 `protobuf:"bytes,2,opt,name=nim_nip,json=nimNip,proto3" json:"nim_nip,omitempty"`
+	FullName      string                 `protobuf:"bytes,3,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Balance       int64                  `protobuf:"varint,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Wallet) Reset() {
+	*x = Wallet{}
+	mi := &file_campus_v1_campus_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Wallet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wallet) ProtoMessage() {}
+
+func (x *Wallet) ProtoReflect() protoreflect.Message {
+	mi := &file_campus_v1_campus_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wallet.ProtoReflect.Descriptor instead.
+func (*Wallet) Descriptor() ([]byte, []int) {
+	return file_campus_v1_campus_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Wallet) GetWalletId() uint64 {
+	if x != nil {
+		return x.WalletId
+	}
+	return 0
+}
+
+func (x *Wallet) GetNimNip() string {
+	if x != nil {
+		return x.NimNip
+	}
+	return ""
+}
+
+func (x *Wallet) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *Wallet) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+type GetProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductRequest) Reset() {
+	*x = GetProductRequest{}
+	mi := &file_campus_v1_campus_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductRequest) ProtoMessage() {}
+
+func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_campus_v1_campus_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
+func (*GetProductRequest) Descriptor() ([]byte, []int) {
+	return file_campus_v1_campus_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetProductRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type Product struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Price         int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Stock         int64                  `protobuf:"varint,5,opt,name=stock,proto3" json:"stock,omitempty"`
+	ImageUrl      string                 `protobuf:"bytes,6,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Active        bool                   `protobuf:"varint,7,opt,name=active,proto3" json:"active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_campus_v1_campus_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_campus_v1_campus_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_campus_v1_campus_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Product) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Product) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Product) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Product) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Product) GetStock() int64 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+func (x *Product) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *Product) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+var File_campus_v1_campus_proto protoreflect.FileDescriptor
+
+const file_campus_v1_campus_proto_rawDesc = "" +
+	"\n" +
+	"\x16campus/v1/campus.proto\x12\tcampus.v1\"\x89\x01\n" +
+	"\x16WalletOperationRequest\x12\x17\n" +
+	"\anim_nip\x18\x01 \x01(\tR\x06nimNip\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1c\n" +
+	"\treference\x18\x03 \x01(\tR\treference\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\"\x8f\x01\n" +
+	"\x17WalletOperationResponse\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\x04R\voperationId\x12\x1b\n" +
+	"\twallet_id\x18\x02 \x01(\x04R\bwalletId\x12\x18\n" +
+	"\abalance\x18\x03 \x01(\x03R\abalance\x12\x1a\n" +
+	"\breplayed\x18\x04 \x01(\bR\breplayed\"+\n" +
+	"\x10GetWalletRequest\x12\x17\n" +
+	"\anim_nip\x18\x01 \x01(\tR\x06nimNip\"u\n" +
+	"\x06Wallet\x12\x1b\n" +
+	"\twallet_id\x18\x01 \x01(\x04R\bwalletId\x12\x17\n" +
+	"\anim_nip\x18\x02 \x01(\tR\x06nimNip\x12\x1b\n" +
+	"\tfull_name\x18\x03 \x01(\tR\bfullName\x12\x18\n" +
+	"\abalance\x18\x04 \x01(\x03R\abalance\"#\n" +
+	"\x11GetProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\xb0\x01\n" +
+	"\aProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x03R\x05price\x12\x14\n" +
+	"\x05stock\x18\x05 \x01(\x03R\x05stock\x12\x1b\n" +
+	"\timage_url\x18\x06 \x01(\tR\bimageUrl\x12\x16\n" +
+	"\x06active\x18\a \x01(\bR\x06active2\xb9\x02\n" +
+	"\rCampusService\x12U\n" +
+	"\fCreditWallet\x12!.campus.v1.WalletOperationRequest\x1a\".campus.v1.WalletOperationResponse\x12T\n" +
+	"\vDebitWallet\x12!.campus.v1.WalletOperationRequest\x1a\".campus.v1.WalletOperationResponse\x12;\n" +
+	"\tGetWallet\x12\x1b.campus.v1.GetWalletRequest\x1a\x11.campus.v1.Wallet\x12>\n" +
+	"\n" +
+	"GetProduct\x12\x1c.campus.v1.GetProductRequest\x1a\x12.campus.v1.ProductB3Z1wallet-point/internal/campusrpc/campusv1;campusv1b\x06proto3"
+
+var (
+	file_campus_v1_campus_proto_rawDescOnce sync.Once
+	file_campus_v1_campus_proto_rawDescData []byte
+)
+
+func file_campus_v1_campus_proto_rawDescGZIP() []byte {
+	file_campus_v1_campus_proto_rawDescOnce.Do(func() {
+		file_campus_v1_campus_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_campus_v1_campus_proto_rawDesc), len(file_campus_v1_campus_proto_rawDesc)))
+	})
+	return file_campus_v1_campus_proto_rawDescData
+}
+
+var file_campus_v1_campus_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_campus_v1_campus_proto_goTypes = []any{
+	(*WalletOperationRequest)(nil),  // 0: campus.v1.WalletOperationRequest
+	(*WalletOperationResponse)(nil), // 1: campus.v1.WalletOperationResponse
+	(*GetWalletRequest)(nil),        // 2: campus.v1.GetWalletRequest
+	(*Wallet)(nil),                  // 3: campus.v1.Wallet
+	(*GetProductRequest)(nil),       // 4: campus.v1.GetProductRequest
+	(*Product)(nil),                 // 5: campus.v1.Product
+}
+var file_campus_v1_campus_proto_depIdxs = []int32{
+	0, // 0: campus.v1.CampusService.CreditWallet:input_type -> campus.v1.WalletOperationRequest
+	0, // 1: campus.v1.CampusService.DebitWallet:input_type -> campus.v1.WalletOperationRequest
+	2, // 2: campus.v1.CampusService.GetWallet:input_type -> campus.v1.GetWalletRequest
+	4, // 3: campus.v1.CampusService.GetProduct:input_type -> campus.v1.GetProductRequest
+	1, // 4: campus.v1.CampusService.CreditWallet:output_type -> campus.v1.WalletOperationResponse
+	1, // 5: campus.v1.CampusService.DebitWallet:output_type -> campus.v1.WalletOperationResponse
+	3, // 6: campus.v1.CampusService.GetWallet:output_type -> campus.v1.Wallet
+	5, // 7: campus.v1.CampusService.GetProduct:output_type -> campus.v1.Product
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_campus_v1_campus_proto_init() }
+func file_campus_v1_campus_proto_init() {
+	if File_campus_v1_campus_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_campus_v1_campus_proto_rawDesc), len(file_campus_v1_campus_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_campus_v1_campus_proto_goTypes,
+		DependencyIndexes: file_campus_v1_campus_proto_depIdxs,
+		MessageInfos:      file_campus_v1_campus_proto_msgTypes,
+	}.Build()
+	File_campus_v1_campus_proto = out.File
+	file_campus_v1_campus_proto_goTypes = nil
+	file_campus_v1_campus_proto_depIdxs = nil
+}
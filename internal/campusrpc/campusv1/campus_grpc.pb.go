@@ -0,0 +1,248 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: campus/v1/campus.proto
+
+// Internal API for campus systems such as the canteen POS and attendance.
+// Served over mTLS only; regenerate the Go code with:
+//   protoc -I proto --go_out=. --go_opt=module=wallet-point \
+//     --go-grpc_out=. --go-grpc_opt=module=wallet-point campus/v1/campus.proto
+
+package campusv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CampusService_CreditWallet_FullMethodName = "/campus.v1.CampusService/CreditWallet"
+	CampusService_DebitWallet_FullMethodName  = "/campus.v1.CampusService/DebitWallet"
+	CampusService_GetWallet_FullMethodName    = "/campus.v1.CampusService/GetWallet"
+	CampusService_GetProduct_FullMethodName   = "/campus.v1.CampusService/GetProduct"
+)
+
+// CampusServiceClient is the client API for CampusService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CampusServiceClient interface {
+	// CreditWallet adds points to a student's wallet (e.g. an attendance reward)
+	CreditWallet(ctx context.Context, in *WalletOperationRequest, opts ...grpc.CallOption) (*WalletOperationResponse, error)
+	// DebitWallet takes points from a student's wallet (e.g. a canteen purchase)
+	DebitWallet(ctx context.Context, in *WalletOperationRequest, opts ...grpc.CallOption) (*WalletOperationResponse, error)
+	// GetWallet returns the balance of an active student
+	GetWallet(ctx context.Context, in *GetWalletRequest, opts ...grpc.CallOption) (*Wallet, error)
+	// GetProduct looks up a marketplace product
+	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error)
+}
+
+type campusServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCampusServiceClient(cc grpc.ClientConnInterface) CampusServiceClient {
+	return &campusServiceClient{cc}
+}
+
+func (c *campusServiceClient) CreditWallet(ctx context.Context, in *WalletOperationRequest, opts ...grpc.CallOption) (*WalletOperationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WalletOperationResponse)
+	err := c.cc.Invoke(ctx, CampusService_CreditWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *campusServiceClient) DebitWallet(ctx context.Context, in *WalletOperationRequest, opts ...grpc.CallOption) (*WalletOperationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WalletOperationResponse)
+	err := c.cc.Invoke(ctx, CampusService_DebitWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *campusServiceClient) GetWallet(ctx context.Context, in *GetWalletRequest, opts ...grpc.CallOption) (*Wallet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wallet)
+	err := c.cc.Invoke(ctx, CampusService_GetWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *campusServiceClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Product)
+	err := c.cc.Invoke(ctx, CampusService_GetProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CampusServiceServer is the server API for CampusService service.
+// All implementations must embed UnimplementedCampusServiceServer
+// for forward compatibility.
+type CampusServiceServer interface {
+	// CreditWallet adds points to a student's wallet (e.g. an attendance reward)
+	CreditWallet(context.Context, *WalletOperationRequest) (*WalletOperationResponse, error)
+	// DebitWallet takes points from a student's wallet (e.g. a canteen purchase)
+	DebitWallet(context.Context, *WalletOperationRequest) (*WalletOperationResponse, error)
+	// GetWallet returns the balance of an active student
+	GetWallet(context.Context, *GetWalletRequest) (*Wallet, error)
+	// GetProduct looks up a marketplace product
+	GetProduct(context.Context, *GetProductRequest) (*Product, error)
+	mustEmbedUnimplementedCampusServiceServer()
+}
+
+// UnimplementedCampusServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCampusServiceServer struct{}
+
+func (UnimplementedCampusServiceServer) CreditWallet(context.Context, *WalletOperationRequest) (*WalletOperationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreditWallet not implemented")
+}
+func (UnimplementedCampusServiceServer) DebitWallet(context.Context, *WalletOperationRequest) (*WalletOperationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DebitWallet not implemented")
+}
+func (UnimplementedCampusServiceServer) GetWallet(context.Context, *GetWalletRequest) (*Wallet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWallet not implemented")
+}
+func (UnimplementedCampusServiceServer) GetProduct(context.Context, *GetProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
+}
+func (UnimplementedCampusServiceServer) mustEmbedUnimplementedCampusServiceServer() {}
+func (UnimplementedCampusServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeCampusServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CampusServiceServer will
+// result in compilation errors.
+type UnsafeCampusServiceServer interface {
+	mustEmbedUnimplementedCampusServiceServer()
+}
+
+func RegisterCampusServiceServer(s grpc.ServiceRegistrar, srv CampusServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCampusServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CampusService_ServiceDesc, srv)
+}
+
+func _CampusService_CreditWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampusServiceServer).CreditWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampusService_CreditWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampusServiceServer).CreditWallet(ctx, req.(*WalletOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CampusService_DebitWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampusServiceServer).DebitWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampusService_DebitWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampusServiceServer).DebitWallet(ctx, req.(*WalletOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CampusService_GetWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampusServiceServer).GetWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampusService_GetWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampusServiceServer).GetWallet(ctx, req.(*GetWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CampusService_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampusServiceServer).GetProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampusService_GetProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampusServiceServer).GetProduct(ctx, req.(*GetProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CampusService_ServiceDesc is the grpc.ServiceDesc for CampusService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CampusService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "campus.v1.CampusService",
+	HandlerType: (*CampusServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreditWallet",
+			Handler:    _CampusService_CreditWallet_Handler,
+		},
+		{
+			MethodName: "DebitWallet",
+			Handler:    _CampusService_DebitWallet_Handler,
+		},
+		{
+			MethodName: "GetWallet",
+			Handler:    _CampusService_GetWallet_Handler,
+		},
+		{
+			MethodName: "GetProduct",
+			Handler:    _CampusService_GetProduct_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "campus/v1/campus.proto",
+}
@@ -0,0 +1,135 @@
+package campusrpc
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+	"wallet-point/utils"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type clientKey struct{}
+
+// clientName returns the common name of the calling system's certificate
+func clientName(ctx context.Context) string {
+	name, _ := ctx.Value(clientKey{}).(string)
+	return name
+}
+
+// clientAddress returns the IP address of the caller, for audit logs
+func clientAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func recoverPanics(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			slog.ErrorContext(ctx, "grpc: handler panic", "method", info.FullMethod, "panic", recovered)
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// authorizeClient identifies the caller by the common name of its verified client
+// certificate and rejects it when allowed is set and does not list it
+func authorizeClient(allowed []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "client certificate required")
+		}
+		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "client certificate required")
+		}
+
+		name := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+		if name == "" || (len(allowed) > 0 && !slices.Contains(allowed, name)) {
+			slog.WarnContext(ctx, "grpc: client not allowed", "client", name, "method", info.FullMethod)
+			return nil, status.Errorf(codes.PermissionDenied, "client %q is not allowed", name)
+		}
+		return handler(context.WithValue(ctx, clientKey{}, name), req)
+	}
+}
+
+func logCalls(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	attrs := []any{"method", info.FullMethod, "client", clientName(ctx), "duration", time.Since(start)}
+	if err != nil {
+		slog.WarnContext(ctx, "grpc: call failed", append(attrs, "error", err)...)
+	} else {
+		slog.InfoContext(ctx, "grpc: call", attrs...)
+	}
+	return resp, err
+}
+
+// translateErrors turns service errors into gRPC statuses: an AppError keeps its
+// code as the ErrorInfo reason, anything else becomes a generic Internal error
+func translateErrors(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+
+	appErr, ok := utils.AsAppError(err)
+	if !ok {
+		slog.ErrorContext(ctx, "grpc: internal error", "method", info.FullMethod, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	st := status.New(grpcCode(appErr.Status), err.Error())
+	if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: appErr.Code, Domain: "wallet-point"}); detailErr == nil {
+		st = detailed
+	}
+	return nil, st.Err()
+}
+
+// grpcCode maps the HTTP status of an AppError to the closest gRPC code
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		// Requests are validated before reaching the services, so their 400s are
+		// business rules such as an insufficient balance
+		return codes.FailedPrecondition
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		if httpStatus >= 500 {
+			return codes.Internal
+		}
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,112 @@
+// Package campusrpc serves the gRPC API used by campus systems (canteen POS,
+// attendance) to move wallet points and look up products. It listens on its own
+// port and only accepts clients presenting a certificate signed by the configured CA.
+package campusrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/campusrpc/campusv1"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/wallet"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+type Config struct {
+	Port           string
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string
+	AllowedClients []string // certificate common names; empty allows any certificate of the CA
+}
+
+type Server struct {
+	grpc *grpc.Server
+	addr string
+}
+
+func NewServer(cfg Config, walletService *wallet.WalletService, marketplaceService *marketplace.MarketplaceService, auditService audit.Logger) (*Server, error) {
+	tlsConfig, err := loadTLSConfig(cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.ChainUnaryInterceptor(
+			recoverPanics,
+			authorizeClient(cfg.AllowedClients),
+			logCalls,
+			translateErrors,
+		),
+	)
+	campusv1.RegisterCampusServiceServer(server, &campusService{
+		walletService:      walletService,
+		marketplaceService: marketplaceService,
+		auditService:       auditService,
+	})
+
+	return &Server{grpc: server, addr: ":" + cfg.Port}, nil
+}
+
+// ListenAndServe serves until Shutdown is called
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	if err := s.grpc.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting calls and waits for in-flight ones, cutting them off
+// once ctx expires
+func (s *Server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return ctx.Err()
+	}
+}
+
+// loadTLSConfig requires and verifies a client certificate signed by the CA in clientCAFile
+func loadTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load gRPC server certificate: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read gRPC client CA: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("read gRPC client CA: no certificates found in %s", clientCAFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package campusrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/campusrpc/campusv1"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxReferenceLength matches the reference column of wallet_external_operations
+const maxReferenceLength = 100
+
+type campusService struct {
+	campusv1.UnimplementedCampusServiceServer
+	walletService      *wallet.WalletService
+	marketplaceService *marketplace.MarketplaceService
+	auditService       audit.Logger
+}
+
+func (s *campusService) CreditWallet(ctx context.Context, req *campusv1.WalletOperationRequest) (*campusv1.WalletOperationResponse, error) {
+	return s.applyOperation(ctx, "credit", req)
+}
+
+func (s *campusService) DebitWallet(ctx context.Context, req *campusv1.WalletOperationRequest) (*campusv1.WalletOperationResponse, error) {
+	return s.applyOperation(ctx, "debit", req)
+}
+
+func (s *campusService) applyOperation(ctx context.Context, direction string, req *campusv1.WalletOperationRequest) (*campusv1.WalletOperationResponse, error) {
+	if req.GetNimNip() == "" {
+		return nil, status.Error(codes.InvalidArgument, "nim_nip is required")
+	}
+	if req.GetAmount() < 1 || req.GetAmount() > utils.MaxPointsPerOperation {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must be between 1 and %d", utils.MaxPointsPerOperation)
+	}
+	if req.GetReference() == "" || len(req.GetReference()) > maxReferenceLength {
+		return nil, status.Errorf(codes.InvalidArgument, "reference is required and must be at most %d characters", maxReferenceLength)
+	}
+
+	client := clientName(ctx)
+	op, replayed, err := s.walletService.ApplyExternalOperation(ctx, wallet.ExternalOperationRequest{
+		Client:      client,
+		NimNip:      req.GetNimNip(),
+		Reference:   req.GetReference(),
+		Direction:   direction,
+		Amount:      int(req.GetAmount()),
+		Description: req.GetDescription(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !replayed {
+		s.auditService.LogActivity(audit.CreateAuditParams{
+			Action:    "EXTERNAL_" + strings.ToUpper(direction),
+			Entity:    "WALLET",
+			EntityID:  op.WalletID,
+			Details:   fmt.Sprintf("%s %s %d points for %s (reference %s)", client, direction, op.Amount, req.GetNimNip(), op.Reference),
+			IPAddress: clientAddress(ctx),
+			UserAgent: "grpc/" + client,
+		})
+	}
+
+	return &campusv1.WalletOperationResponse{
+		OperationId: uint64(op.ID),
+		WalletId:    uint64(op.WalletID),
+		Balance:     int64(op.BalanceAfter),
+		Replayed:    replayed,
+	}, nil
+}
+
+func (s *campusService) GetWallet(ctx context.Context, req *campusv1.GetWalletRequest) (*campusv1.Wallet, error) {
+	if req.GetNimNip() == "" {
+		return nil, status.Error(codes.InvalidArgument, "nim_nip is required")
+	}
+
+	w, err := s.walletService.GetWalletByNimNip(req.GetNimNip())
+	if err != nil {
+		return nil, err
+	}
+	return &campusv1.Wallet{
+		WalletId: uint64(w.WalletID),
+		NimNip:   w.NimNip,
+		FullName: w.FullName,
+		Balance:  int64(w.Balance),
+	}, nil
+}
+
+func (s *campusService) GetProduct(ctx context.Context, req *campusv1.GetProductRequest) (*campusv1.Product, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	product, err := s.marketplaceService.GetProductByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return &campusv1.Product{
+		Id:          uint64(product.ID),
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       int64(product.Price),
+		Stock:       int64(product.Stock),
+		ImageUrl:    product.ImageURL,
+		Active:      product.Status == "active",
+	}, nil
+}
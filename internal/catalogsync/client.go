@@ -0,0 +1,219 @@
+package catalogsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wallet-point/internal/resilience"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	sheetsBaseURL = "https://sheets.googleapis.com/v4/spreadsheets/"
+	sheetsScope   = "https://www.googleapis.com/auth/spreadsheets"
+	tokenLifetime = time.Hour
+)
+
+// serviceAccount is the part of a Google service account key file the client needs
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// Client reads and writes cell values of one spreadsheet with the Sheets API v4,
+// authenticated as a service account the sheet is shared with
+type Client struct {
+	spreadsheetID string
+	account       serviceAccount
+	client        *http.Client
+	policy        resilience.Policy
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewClient loads the service account key file (JSON, as downloaded from the
+// Google Cloud console)
+func NewClient(keyFile, spreadsheetID string, policy resilience.Policy) (*Client, error) {
+	raw, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, fmt.Errorf("service account key: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("service account key: client_email and private_key are required")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = retryableSheetsError
+	}
+	return &Client{spreadsheetID: spreadsheetID, account: account, client: &http.Client{}, policy: policy}, nil
+}
+
+// sheetsStatusError is a non-2xx reply of Google
+type sheetsStatusError struct {
+	status int
+	body   string
+}
+
+func (e *sheetsStatusError) Error() string {
+	return fmt.Sprintf("google sheets returned status %d: %s", e.status, e.body)
+}
+
+// retryableSheetsError retries everything except a 4xx other than 429
+func retryableSheetsError(err error) bool {
+	statusErr, ok := err.(*sheetsStatusError)
+	if !ok {
+		return true
+	}
+	return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+}
+
+// token returns a cached access token, exchanging a signed JWT for a new one when it is about to expire
+func (c *Client) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken != "" && time.Until(c.expiresAt) > time.Minute {
+		return c.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(c.account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("service account key: %w", err)
+	}
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   c.account.ClientEmail,
+		"scope": sheetsScope,
+		"aud":   c.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(tokenLifetime).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := c.send(ctx, http.MethodPost, c.account.TokenURI, "application/x-www-form-urlencoded", []byte(form.Encode()), "", &result); err != nil {
+		return "", err
+	}
+
+	c.accessToken = result.AccessToken
+	c.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return c.accessToken, nil
+}
+
+// ReadRows returns the rows of range (e.g. "'Catalog'!A1:E") as strings.
+// Numbers are read unformatted, so a price shown as "1.000" comes back as
+// "1000"; trailing empty cells are left out by Google.
+func (c *Client) ReadRows(ctx context.Context, cellRange string) ([][]string, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := sheetsBaseURL + url.PathEscape(c.spreadsheetID) + "/values/" + url.PathEscape(cellRange) + "?valueRenderOption=UNFORMATTED_VALUE"
+	var result struct {
+		Values [][]interface{} `json:"values"`
+	}
+	if err := c.send(ctx, http.MethodGet, endpoint, "", nil, token, &result); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, len(result.Values))
+	for i, cells := range result.Values {
+		rows[i] = make([]string, len(cells))
+		for j, cell := range cells {
+			switch value := cell.(type) {
+			case float64:
+				rows[i][j] = strconv.FormatFloat(value, 'f', -1, 64)
+			case nil:
+			default:
+				rows[i][j] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+	}
+	return rows, nil
+}
+
+// RangeValues is the content written to one range
+type RangeValues struct {
+	Range  string          `json:"range"`
+	Values [][]interface{} `json:"values"`
+}
+
+// WriteRanges writes several ranges in one request. Values are stored raw: Go
+// numbers become numbers, and text starting with "=" is not run as a formula.
+func (c *Client) WriteRanges(ctx context.Context, data []RangeValues) error {
+	if len(data) == 0 {
+		return nil
+	}
+	token, err := c.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"valueInputOption": "RAW",
+		"data":             data,
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := sheetsBaseURL + url.PathEscape(c.spreadsheetID) + "/values:batchUpdate"
+	return c.send(ctx, http.MethodPost, endpoint, "application/json", body, token, nil)
+}
+
+func (c *Client) send(ctx context.Context, method, endpoint, contentType string, body []byte, token string, result interface{}) error {
+	return c.policy.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return &sheetsStatusError{status: resp.StatusCode, body: strings.TrimSpace(string(body))}
+		}
+		if result == nil {
+			return nil
+		}
+		return json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(result)
+	})
+}
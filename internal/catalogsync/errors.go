@@ -0,0 +1,13 @@
+package catalogsync
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrNotConfigured    = utils.NewAppError("CATALOG_SYNC_NOT_CONFIGURED", http.StatusNotFound, "catalog sheet sync is not configured")
+	ErrSyncRunning      = utils.NewAppError("CATALOG_SYNC_RUNNING", http.StatusConflict, "a catalog sheet sync is already running")
+	ErrConflictNotFound = utils.NewAppError("CATALOG_SYNC_CONFLICT_NOT_FOUND", http.StatusNotFound, "catalog sync conflict not found")
+	ErrConflictResolved = utils.NewAppError("CATALOG_SYNC_CONFLICT_RESOLVED", http.StatusConflict, "catalog sync conflict is already resolved")
+)
@@ -0,0 +1,128 @@
+package catalogsync
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogSyncHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewCatalogSyncHandler(service *Service, auditService audit.Logger) *CatalogSyncHandler {
+	return &CatalogSyncHandler{service: service, auditService: auditService}
+}
+
+// Run handles an on-demand catalog sheet sync (Super admin)
+// @Summary Sync catalog with Google Sheet
+// @Description Runs the two-way sync now instead of waiting for the schedule: name, price and status edits in the sheet are applied to the catalog, catalog changes and stock are written to the sheet, and fields changed differently on both sides are recorded as conflicts
+// @Tags Admin - Catalog Sync
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=SyncResult}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/catalog-sync/run [post]
+func (h *CatalogSyncHandler) Run(c *gin.Context) {
+	result, err := h.service.Sync(c.Request.Context())
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadGateway, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Catalog sheet synced", result)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "SYNC_CATALOG_SHEET",
+		Entity:    "PRODUCT",
+		Details:   fmt.Sprintf("Synced the catalog sheet: %d fields applied, %d rows written, %d appended, %d conflicts", result.AppliedToCatalog, result.RowsWritten, result.RowsAppended, result.Conflicts),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetConflicts handles listing catalog sync conflicts (Super admin)
+// @Summary List catalog sync conflicts
+// @Description Fields edited differently in the sheet and the catalog since the last sync
+// @Tags Admin - Catalog Sync
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(open, resolved)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ConflictListResponse}
+// @Router /admin/catalog-sync/conflicts [get]
+func (h *CatalogSyncHandler) GetConflicts(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "catalog_conflict_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetConflicts(c.Request.Context(), ConflictListParams{
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve catalog sync conflicts", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Catalog sync conflicts retrieved successfully", "conflicts", response.Conflicts, response.Pagination, nil)
+}
+
+// ResolveConflict handles settling a catalog sync conflict (Super admin)
+// @Summary Resolve catalog sync conflict
+// @Description Keep the sheet's or the catalog's value; the next sync copies it to the other side
+// @Tags Admin - Catalog Sync
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Conflict ID"
+// @Param request body ResolveConflictRequest true "Side to keep"
+// @Success 200 {object} utils.Response{data=Conflict}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/catalog-sync/conflicts/{id}/resolve [post]
+func (h *CatalogSyncHandler) ResolveConflict(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid conflict ID", nil)
+		return
+	}
+	var req ResolveConflictRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	conflict, err := h.service.ResolveConflict(c.Request.Context(), uint(id), req.Keep, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Catalog sync conflict resolved", conflict)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "RESOLVE_CATALOG_CONFLICT",
+		Entity:    "PRODUCT",
+		EntityID:  conflict.ProductID,
+		Details:   fmt.Sprintf("Kept the %s value of %s for product %d (sheet %q, catalog %q)", req.Keep, conflict.Field, conflict.ProductID, conflict.SheetValue, conflict.AppValue),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
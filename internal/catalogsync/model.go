@@ -0,0 +1,104 @@
+package catalogsync
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	FieldName   = "name"
+	FieldPrice  = "price"
+	FieldStatus = "status"
+
+	ConflictOpen     = "open"
+	ConflictResolved = "resolved"
+
+	KeepSheet = "sheet"
+	KeepApp   = "app"
+)
+
+// syncedFields are compared in both directions; stock is only ever written to the sheet
+var syncedFields = []string{FieldName, FieldPrice, FieldStatus}
+
+// State is the last agreed value of a product's synced fields, the common base
+// of the three-way comparison: a field that differs from it in only the sheet
+// or only the catalog changed on that side and is copied over; one that
+// changed on both sides to different values is a conflict.
+type State struct {
+	ProductID uint      `json:"product_id" gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `json:"name" gorm:"not null"`
+	Price     string    `json:"price" gorm:"size:20;not null"`
+	Status    string    `json:"status" gorm:"size:20;not null"`
+	SyncedAt  time.Time `json:"synced_at"`
+}
+
+func (State) TableName() string {
+	return "catalog_sync_states"
+}
+
+func (s *State) value(field string) string {
+	switch field {
+	case FieldName:
+		return s.Name
+	case FieldPrice:
+		return s.Price
+	}
+	return s.Status
+}
+
+func (s *State) set(field, value string) {
+	switch field {
+	case FieldName:
+		s.Name = value
+	case FieldPrice:
+		s.Price = value
+	default:
+		s.Status = value
+	}
+}
+
+// Conflict is a field edited differently in the sheet and in the catalog since
+// the last sync. Both sides keep their value until an admin picks one.
+type Conflict struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ProductID  uint       `json:"product_id" gorm:"not null;index:idx_catalog_sync_conflict_field"`
+	Field      string     `json:"field" gorm:"size:20;not null;index:idx_catalog_sync_conflict_field"`
+	BaseValue  string     `json:"base_value"`
+	SheetValue string     `json:"sheet_value"`
+	AppValue   string     `json:"app_value"`
+	Status     string     `json:"status" gorm:"type:enum('open','resolved');default:'open';not null;index"`
+	Resolution string     `json:"resolution,omitempty" gorm:"size:10"`
+	ResolvedBy *uint      `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (Conflict) TableName() string {
+	return "catalog_sync_conflicts"
+}
+
+// SyncResult summarizes one sync run
+type SyncResult struct {
+	RowsRead         int      `json:"rows_read"`
+	AppliedToCatalog int      `json:"applied_to_catalog"` // product fields updated from the sheet
+	RowsWritten      int      `json:"rows_written"`       // sheet rows updated from the catalog
+	RowsAppended     int      `json:"rows_appended"`      // products added to the sheet
+	Conflicts        int      `json:"conflicts"`
+	Errors           []string `json:"errors"` // rows that could not be read, e.g. an invalid price
+}
+
+type ConflictListParams struct {
+	Status string
+	Page   int
+	Limit  int
+}
+
+type ConflictListResponse struct {
+	Conflicts []Conflict `json:"conflicts"`
+	utils.Pagination
+}
+
+type ResolveConflictRequest struct {
+	Keep string `json:"keep" binding:"required,oneof=sheet app" enums:"sheet,app"`
+}
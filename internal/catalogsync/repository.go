@@ -0,0 +1,119 @@
+package catalogsync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// product is the catalog side of a sheet row
+type product struct {
+	ID     uint
+	Name   string
+	Price  int
+	Status string
+	Stock  int
+}
+
+// products lists every product not deleted, of all campuses
+func (r *Repository) products(ctx context.Context) ([]product, error) {
+	var products []product
+	err := r.db.WithContext(ctx).Table("products").
+		Select("id, name, price, status, stock").
+		Where("deleted_at IS NULL").
+		Order("id ASC").
+		Scan(&products).Error
+	return products, err
+}
+
+func (r *Repository) updateProduct(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Table("products").Where("id = ? AND deleted_at IS NULL", id).Updates(updates).Error
+}
+
+// states returns the last agreed values per product ID
+func (r *Repository) states(ctx context.Context) (map[uint]*State, error) {
+	var states []State
+	if err := r.db.WithContext(ctx).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	byProduct := make(map[uint]*State, len(states))
+	for i := range states {
+		byProduct[states[i].ProductID] = &states[i]
+	}
+	return byProduct, nil
+}
+
+func (r *Repository) saveState(ctx context.Context, state *State) error {
+	return r.db.WithContext(ctx).Save(state).Error
+}
+
+// recordConflict keeps one open conflict per product field, refreshing its values
+func (r *Repository) recordConflict(ctx context.Context, conflict *Conflict) error {
+	var existing Conflict
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND field = ? AND status = ?", conflict.ProductID, conflict.Field, ConflictOpen).
+		Take(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.db.WithContext(ctx).Create(conflict).Error
+	}
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(&existing).Updates(map[string]interface{}{
+		"base_value":  conflict.BaseValue,
+		"sheet_value": conflict.SheetValue,
+		"app_value":   conflict.AppValue,
+	}).Error
+}
+
+// closeConflicts resolves the open conflicts of a field whose sides agree again
+func (r *Repository) closeConflicts(ctx context.Context, productID uint, field string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&Conflict{}).
+		Where("product_id = ? AND field = ? AND status = ?", productID, field, ConflictOpen).
+		Updates(map[string]interface{}{"status": ConflictResolved, "resolved_at": &now}).Error
+}
+
+// lockConflict loads a conflict for update within tx, so two admins cannot resolve it both ways
+func (r *Repository) lockConflict(tx *gorm.DB, id uint) (*Conflict, error) {
+	var conflict Conflict
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&conflict, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConflictNotFound
+		}
+		return nil, err
+	}
+	return &conflict, nil
+}
+
+// FindConflicts lists conflicts newest first
+func (r *Repository) FindConflicts(ctx context.Context, params ConflictListParams) ([]Conflict, int64, error) {
+	var conflicts []Conflict
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Conflict{})
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("updated_at DESC").Order("id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&conflicts).Error
+	return conflicts, total, err
+}
@@ -0,0 +1,327 @@
+package catalogsync
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wallet-point/internal/marketplace"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+const maxNameLength = 255
+
+// sheetHeader is row 1 of the sheet; products follow from row 2, one per row.
+// Stock is informational: the sheet shows it, edits to it are ignored.
+var sheetHeader = []interface{}{"product_id", "name", "price", "status", "stock"}
+
+// Service keeps the product catalog and a Google Sheet maintained by the store
+// staff in step, in both directions
+type Service struct {
+	repo        *Repository
+	db          *gorm.DB
+	marketplace *marketplace.MarketplaceService
+	client      *Client
+	tab         string
+	running     sync.Mutex
+}
+
+func NewService(repo *Repository, db *gorm.DB, marketplaceService *marketplace.MarketplaceService) *Service {
+	return &Service{repo: repo, db: db, marketplace: marketplaceService}
+}
+
+// SetClient enables syncing with the tab of the client's spreadsheet
+func (s *Service) SetClient(client *Client, tab string) {
+	s.client = client
+	s.tab = tab
+}
+
+// sheetRow is a product row read from the sheet
+type sheetRow struct {
+	number int // 1-based row number in the sheet
+	cells  []string
+}
+
+func (r sheetRow) cell(i int) string {
+	if i < len(r.cells) {
+		return strings.TrimSpace(r.cells[i])
+	}
+	return ""
+}
+
+// value returns the normalized value of a synced field, or an error for a value the catalog cannot take
+func (r sheetRow) value(field string) (string, error) {
+	switch field {
+	case FieldName:
+		name := r.cell(1)
+		if name == "" || len(name) > maxNameLength {
+			return "", fmt.Errorf("name must be 1 to %d characters", maxNameLength)
+		}
+		return name, nil
+	case FieldPrice:
+		price, err := strconv.Atoi(r.cell(2))
+		if err != nil || price <= 0 || price > utils.MaxPointsPerOperation {
+			return "", fmt.Errorf("price %q is not a whole number of points from 1 to %d", r.cell(2), utils.MaxPointsPerOperation)
+		}
+		return strconv.Itoa(price), nil
+	}
+	status := strings.ToLower(r.cell(3))
+	if status != "active" && status != "inactive" {
+		return "", fmt.Errorf("status %q is not active or inactive", r.cell(3))
+	}
+	return status, nil
+}
+
+func (p product) value(field string) string {
+	switch field {
+	case FieldName:
+		return p.Name
+	case FieldPrice:
+		return strconv.Itoa(p.Price)
+	}
+	return p.Status
+}
+
+// Sync runs one two-way pass, for the cron job and the admin endpoint. Per
+// product and field it compares the sheet and the catalog with the last agreed
+// value: a change on one side is copied to the other, different changes on
+// both sides become a Conflict and stay as they are. On the first sync of a row
+// the catalog is taken as the agreed value, so the prices already kept in the
+// sheet win. Products missing from the sheet are appended to it.
+func (s *Service) Sync(ctx context.Context) (*SyncResult, error) {
+	if s.client == nil {
+		return nil, ErrNotConfigured
+	}
+	if !s.running.TryLock() {
+		return nil, ErrSyncRunning
+	}
+	defer s.running.Unlock()
+
+	rows, err := s.client.ReadRows(ctx, s.cellRange(1, 0))
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.products(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.repo.states(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{Errors: []string{}}
+	var writes []RangeValues
+	if len(rows) == 0 {
+		writes = append(writes, RangeValues{Range: s.cellRange(1, 1), Values: [][]interface{}{sheetHeader}})
+		rows = [][]string{nil}
+	}
+
+	byProduct := make(map[uint]sheetRow)
+	for i, cells := range rows[1:] {
+		row := sheetRow{number: i + 2, cells: cells}
+		if row.cell(0) == "" {
+			continue // blank or note rows
+		}
+		result.RowsRead++
+		id, err := strconv.ParseUint(row.cell(0), 10, 64)
+		if err != nil || id == 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: product_id %q is not a number", row.number, row.cell(0)))
+			continue
+		}
+		if first, ok := byProduct[uint(id)]; ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: product %d is already on row %d", row.number, id, first.number))
+			continue
+		}
+		byProduct[uint(id)] = row
+	}
+
+	nextRow := len(rows) + 1
+	now := time.Now()
+	for _, p := range products {
+		row, inSheet := byProduct[p.ID]
+		delete(byProduct, p.ID)
+
+		state, known := states[p.ID]
+		if !known {
+			state = &State{ProductID: p.ID, Name: p.Name, Price: strconv.Itoa(p.Price), Status: p.Status}
+		}
+
+		if !inSheet {
+			writes = append(writes, RangeValues{Range: s.cellRange(nextRow, nextRow), Values: [][]interface{}{{p.ID, p.Name, p.Price, p.Status, p.Stock}}})
+			nextRow++
+			result.RowsAppended++
+		} else {
+			desired, updates := s.merge(ctx, p, row, state, result)
+			if len(updates) > 0 {
+				if err := s.repo.updateProduct(ctx, p.ID, updates); err != nil {
+					return nil, err
+				}
+				s.marketplace.ProductChanged(p.ID)
+				result.AppliedToCatalog += len(updates)
+			}
+			if !row.matches(desired) {
+				writes = append(writes, RangeValues{Range: s.cellRange(row.number, row.number), Values: [][]interface{}{desired}})
+				result.RowsWritten++
+			}
+		}
+
+		state.SyncedAt = now
+		if err := s.repo.saveState(ctx, state); err != nil {
+			return nil, err
+		}
+	}
+
+	for id, row := range byProduct {
+		result.Errors = append(result.Errors, fmt.Sprintf("row %d: product %d does not exist or was deleted", row.number, id))
+	}
+
+	// The catalog is already updated; a failed write is redone by the next run,
+	// which sees the same catalog changes against the same agreed values
+	if err := s.client.WriteRanges(ctx, writes); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// merge compares one product with its sheet row field by field, updating state
+// to the agreed values. It returns the row the sheet should show and the
+// catalog updates taken from the sheet.
+func (s *Service) merge(ctx context.Context, p product, row sheetRow, state *State, result *SyncResult) ([]interface{}, map[string]interface{}) {
+	updates := make(map[string]interface{})
+	final := map[string]string{}
+
+	for _, field := range syncedFields {
+		base, app := state.value(field), p.value(field)
+		sheet, err := row.value(field)
+		if err != nil {
+			// An unusable cell counts as unchanged, so it is overwritten with the catalog value
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", row.number, err))
+			sheet = base
+		}
+
+		switch {
+		case sheet == app:
+			final[field] = app
+			state.set(field, app)
+			if err := s.repo.closeConflicts(ctx, p.ID, field); err != nil {
+				slog.ErrorContext(ctx, "catalog sync: closing conflicts failed", "product_id", p.ID, "field", field, "error", err)
+			}
+		case sheet == base:
+			final[field] = app
+			state.set(field, app)
+		case app == base:
+			final[field] = sheet
+			state.set(field, sheet)
+			if field == FieldPrice {
+				price, _ := strconv.Atoi(sheet)
+				updates[field] = price
+			} else {
+				updates[field] = sheet
+			}
+		default:
+			final[field] = sheet // both sides keep their value until resolved
+			result.Conflicts++
+			conflict := &Conflict{ProductID: p.ID, Field: field, BaseValue: base, SheetValue: sheet, AppValue: app, Status: ConflictOpen}
+			if err := s.repo.recordConflict(ctx, conflict); err != nil {
+				slog.ErrorContext(ctx, "catalog sync: recording conflict failed", "product_id", p.ID, "field", field, "error", err)
+			}
+		}
+	}
+
+	price, _ := strconv.Atoi(final[FieldPrice])
+	return []interface{}{p.ID, final[FieldName], price, final[FieldStatus], p.Stock}, updates
+}
+
+// matches reports whether the row already shows values
+func (r sheetRow) matches(values []interface{}) bool {
+	for i, value := range values {
+		if r.cell(i) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// cellRange addresses columns A to E of rows from to to of the tab; to 0 means to the end
+func (s *Service) cellRange(from, to int) string {
+	tab := "'" + strings.ReplaceAll(s.tab, "'", "''") + "'"
+	if to == 0 {
+		return fmt.Sprintf("%s!A%d:E", tab, from)
+	}
+	return fmt.Sprintf("%s!A%d:E%d", tab, from, to)
+}
+
+// RunScheduled is the cron job form of Sync
+func (s *Service) RunScheduled(ctx context.Context) (string, error) {
+	if s.client == nil {
+		return "catalog sheet sync is not configured", nil
+	}
+	result, err := s.Sync(ctx)
+	if err != nil {
+		return "", err
+	}
+	summary := fmt.Sprintf("read %d rows: %d fields applied, %d rows written, %d appended, %d conflicts, %d errors",
+		result.RowsRead, result.AppliedToCatalog, result.RowsWritten, result.RowsAppended, result.Conflicts, len(result.Errors))
+	return summary, nil
+}
+
+func (s *Service) GetConflicts(ctx context.Context, params ConflictListParams) (*ConflictListResponse, error) {
+	conflicts, total, err := s.repo.FindConflicts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if conflicts == nil {
+		conflicts = []Conflict{}
+	}
+	return &ConflictListResponse{
+		Conflicts:  conflicts,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// ResolveConflict settles a conflict in favour of the sheet or the catalog. The
+// agreed value is set to the losing side's value, so the next sync sees only
+// the winning side as changed and copies it over.
+func (s *Service) ResolveConflict(ctx context.Context, id uint, keep string, adminID uint) (*Conflict, error) {
+	var resolved *Conflict
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		conflict, err := s.repo.lockConflict(tx, id)
+		if err != nil {
+			return err
+		}
+		if conflict.Status != ConflictOpen {
+			return ErrConflictResolved
+		}
+
+		var state State
+		if err := tx.First(&state, conflict.ProductID).Error; err != nil {
+			return err
+		}
+		if keep == KeepSheet {
+			state.set(conflict.Field, conflict.AppValue)
+		} else {
+			state.set(conflict.Field, conflict.SheetValue)
+		}
+		if err := tx.Save(&state).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		conflict.Status = ConflictResolved
+		conflict.Resolution = keep
+		conflict.ResolvedBy = &adminID
+		conflict.ResolvedAt = &now
+		if err := tx.Save(conflict).Error; err != nil {
+			return err
+		}
+		resolved = conflict
+		return nil
+	})
+	return resolved, err
+}
@@ -2,11 +2,42 @@ package database
 
 import (
 	"log"
+	"wallet-point/internal/adminwatch"
+	"wallet-point/internal/anomaly"
+	"wallet-point/internal/attendance"
 	"wallet-point/internal/audit"
 	"wallet-point/internal/auth"
+	"wallet-point/internal/catalogsync"
+	"wallet-point/internal/events"
+	"wallet-point/internal/faculty"
+	"wallet-point/internal/feature"
+	"wallet-point/internal/fulfillment"
+	"wallet-point/internal/idempotency"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/kiosk"
+	"wallet-point/internal/library"
+	"wallet-point/internal/lms"
 	"wallet-point/internal/marketplace"
+	"wallet-point/internal/messaging"
 	"wallet-point/internal/mission"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/orders"
+	"wallet-point/internal/payment"
+	"wallet-point/internal/pointgift"
+	"wallet-point/internal/pos"
+	"wallet-point/internal/printquota"
+	"wallet-point/internal/promotion"
+	"wallet-point/internal/report"
+	"wallet-point/internal/saga"
+	"wallet-point/internal/scheduler"
+	"wallet-point/internal/settings"
+	"wallet-point/internal/storefront"
+	"wallet-point/internal/telegram"
+	"wallet-point/internal/tenant"
+	"wallet-point/internal/ticketing"
+	"wallet-point/internal/topuprequest"
 	"wallet-point/internal/wallet"
+	"wallet-point/internal/webhook"
 
 	"gorm.io/gorm"
 )
@@ -19,22 +50,90 @@ func Migrate(db *gorm.DB) {
 	// but the heavy lifting and enums are handled in 01_tables.sql.
 
 	err := db.AutoMigrate(
+		&tenant.Tenant{},
 		&auth.User{},
 		&wallet.Wallet{},
 		&wallet.WalletTransaction{},
 		&wallet.PaymentToken{},
+		&marketplace.Category{},
 		&marketplace.Product{},
 		&marketplace.MarketplaceTransaction{},
 		&marketplace.CartItem{},
+		&marketplace.StockAlert{},
 		&audit.AuditLog{},
 		&mission.Mission{},
 		&mission.MissionQuestion{},
 		&mission.MissionSubmission{},
+		&messaging.UserPhone{},
+		&messaging.OTPCode{},
+		&messaging.MessageLog{},
+		&notification.Notification{},
+		&notification.OutboundMessage{},
+		&notification.DeadLetter{},
+		&report.DailyProductSales{},
+		&report.DailyUserSpend{},
+		&report.AggregationRun{},
+		&report.ReportSubscription{},
+		&feature.FeatureFlag{},
+		&idempotency.Record{},
+		&jobs.Job{},
+		&scheduler.JobRun{},
+		&wallet.BalanceSnapshot{},
+		&wallet.ExternalOperation{},
+		&webhook.Endpoint{},
+		&webhook.Delivery{},
+		&payment.TopUp{},
+		&lms.CourseRule{},
+		&lms.Event{},
+		&attendance.Event{},
+		&library.Fine{},
+		&pos.Terminal{},
+		&pos.PayCode{},
+		&pos.Charge{},
+		&printquota.TopUp{},
+		&catalogsync.State{},
+		&catalogsync.Conflict{},
+		&telegram.Link{},
+		&telegram.LinkCode{},
+		&storefront.APIKey{},
+		&kiosk.Kiosk{},
+		&kiosk.Order{},
+		&events.DomainEvent{},
+		&saga.Saga{},
+		&settings.Setting{},
+		&marketplace.InventoryEntry{},
+		&promotion.Promotion{},
+		&promotion.Redemption{},
+		&pointgift.Gift{},
+		&fulfillment.Fulfillment{},
+		&anomaly.Flag{},
+		&adminwatch.ActivityAlert{},
+		&marketplace.ProductQuestion{},
+		&marketplace.ProductQuestionVote{},
+		&marketplace.StoreHours{},
+		&orders.Order{},
+		&orders.OrderItem{},
+		&ticketing.Session{},
+		&ticketing.Ticket{},
+		&ticketing.WaitlistEntry{},
+		&faculty.Store{},
+		&faculty.Banner{},
+		&faculty.StoreProduct{},
+		&faculty.StoreAdmin{},
+		&topuprequest.TopUpRequest{},
 	)
 
 	if err != nil {
 		log.Fatal("❌ Migration failed:", err)
 	}
 
+	// Category slugs are unique per campus now; the old global index would still
+	// refuse a slug another campus uses
+	if db.Migrator().HasIndex(&marketplace.Category{}, "idx_categories_slug") {
+		if err := db.Migrator().DropIndex(&marketplace.Category{}, "idx_categories_slug"); err != nil {
+			log.Fatal("❌ Migration failed:", err)
+		}
+	}
+
 	log.Println("✅ Database migration/sync completed")
 }
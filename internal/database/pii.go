@@ -0,0 +1,61 @@
+package database
+
+import (
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// piiColumns are the columns of the models tagged `serializer:pii`
+var piiColumns = []struct{ table, column string }{
+	{"users", "pin_hash"},
+	{"user_phones", "phone_number"},
+	{"message_logs", "phone_number"},
+}
+
+const piiRotateBatch = 500
+
+// RotatePII re-encrypts every PII column for the current key of
+// PII_ENCRYPTION_KEYS: values of older keys get their data key rewrapped and
+// values stored before encryption was enabled are encrypted. It returns the
+// number of values changed per table.column and can be rerun safely.
+func RotatePII(db *gorm.DB) (map[string]int, error) {
+	changed := make(map[string]int, len(piiColumns))
+	for _, pii := range piiColumns {
+		name := pii.table + "." + pii.column
+		lastID := uint(0)
+		for {
+			var rows []struct {
+				ID    uint
+				Value string
+			}
+			err := db.Table(pii.table).
+				Select("id, "+pii.column+" AS value").
+				Where("id > ? AND "+pii.column+" <> ''", lastID).
+				Order("id").
+				Limit(piiRotateBatch).
+				Scan(&rows).Error
+			if err != nil {
+				return changed, err
+			}
+
+			for _, row := range rows {
+				value, ok, err := utils.RewrapPII(row.Value)
+				if err != nil {
+					return changed, err
+				}
+				if ok {
+					if err := db.Table(pii.table).Where("id = ?", row.ID).UpdateColumn(pii.column, value).Error; err != nil {
+						return changed, err
+					}
+					changed[name]++
+				}
+				lastID = row.ID
+			}
+			if len(rows) < piiRotateBatch {
+				break
+			}
+		}
+	}
+	return changed, nil
+}
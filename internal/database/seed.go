@@ -0,0 +1,271 @@
+package database
+
+import (
+	"fmt"
+	"time"
+	"wallet-point/internal/auth"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// Demo credentials of every seeded account
+const (
+	SeedPassword = "password123"
+	SeedPIN      = "123456"
+)
+
+type seedUser struct {
+	Email    string
+	FullName string
+	NimNip   string
+	Role     string
+	// Ledger of a new wallet, oldest first; the balance is its sum
+	Ledger []seedEntry
+}
+
+type seedEntry struct {
+	Type        string
+	Direction   string
+	Amount      int
+	Description string
+	DaysAgo     int
+	// Product bought by a marketplace debit, matched by name
+	Product  string
+	Quantity int
+}
+
+type seedProduct struct {
+	Name        string
+	Description string
+	Price       int
+	Stock       int
+	Categories  []string // slugs
+}
+
+type seedCategory struct {
+	Name   string
+	Slug   string
+	Parent string // slug
+}
+
+var seedCategories = []seedCategory{
+	{Name: "Stationery", Slug: "stationery"},
+	{Name: "Notebooks", Slug: "notebooks", Parent: "stationery"},
+	{Name: "Merchandise", Slug: "merchandise"},
+	{Name: "Food & Drinks", Slug: "food-drinks"},
+	{Name: "Vouchers", Slug: "vouchers"},
+}
+
+var seedProducts = []seedProduct{
+	{Name: "Campus Notebook A5", Description: "80-page dotted notebook with the campus logo", Price: 25, Stock: 120, Categories: []string{"stationery", "notebooks"}},
+	{Name: "Gel Pen Set", Description: "Set of 5 gel pens, 0.5mm", Price: 15, Stock: 200, Categories: []string{"stationery"}},
+	{Name: "Campus Hoodie", Description: "Navy hoodie, sizes S-XL", Price: 250, Stock: 30, Categories: []string{"merchandise"}},
+	{Name: "Tumbler 500ml", Description: "Stainless steel tumbler", Price: 120, Stock: 45, Categories: []string{"merchandise"}},
+	{Name: "Canteen Lunch Voucher", Description: "One lunch set at the main canteen", Price: 30, Stock: 500, Categories: []string{"vouchers", "food-drinks"}},
+	{Name: "Coffee Voucher", Description: "Any coffee at the library cafe", Price: 20, Stock: 300, Categories: []string{"vouchers", "food-drinks"}},
+	{Name: "Printing Credit 50 Pages", Description: "Black and white printing at the faculty print room", Price: 10, Stock: 1000, Categories: []string{"vouchers"}},
+	{Name: "Seminar Kit", Description: "Lanyard, notebook and pen for campus events", Price: 60, Stock: 4, Categories: []string{"merchandise", "stationery"}},
+}
+
+var seedUsers = []seedUser{
+	{Email: "admin@walletpoint.test", FullName: "Admin Wallet Point", NimNip: "198001012005011001", Role: "admin"},
+	{Email: "superadmin@walletpoint.test", FullName: "Super Admin Wallet Point", NimNip: "197501012000011000", Role: "superadmin"},
+	{Email: "dosen1@walletpoint.test", FullName: "Dr. Siti Rahmawati", NimNip: "197805152008012002", Role: "dosen"},
+	{Email: "dosen2@walletpoint.test", FullName: "Budi Santoso, M.Kom.", NimNip: "198511202012011003", Role: "dosen"},
+	{Email: "mahasiswa1@walletpoint.test", FullName: "Andi Pratama", NimNip: "2021110001", Role: "mahasiswa", Ledger: []seedEntry{
+		{Type: "mission", Direction: "credit", Amount: 150, Description: "Mission reward: Campus clean-up day", DaysAgo: 30},
+		{Type: "mission", Direction: "credit", Amount: 100, Description: "Mission reward: Library survey", DaysAgo: 21},
+		{Type: "marketplace", Direction: "debit", Amount: 50, Description: "Purchase: 2x Campus Notebook A5", DaysAgo: 14, Product: "Campus Notebook A5", Quantity: 2},
+		{Type: "adjustment", Direction: "credit", Amount: 25, Description: "Event compensation", DaysAgo: 3},
+	}},
+	{Email: "mahasiswa2@walletpoint.test", FullName: "Dewi Lestari", NimNip: "2021110002", Role: "mahasiswa", Ledger: []seedEntry{
+		{Type: "topup", Direction: "credit", Amount: 300, Description: "Welcome points", DaysAgo: 45},
+		{Type: "marketplace", Direction: "debit", Amount: 250, Description: "Purchase: 1x Campus Hoodie", DaysAgo: 10, Product: "Campus Hoodie", Quantity: 1},
+		{Type: "mission", Direction: "credit", Amount: 80, Description: "Mission reward: Peer tutoring", DaysAgo: 2},
+	}},
+	{Email: "mahasiswa3@walletpoint.test", FullName: "Rizky Maulana", NimNip: "2022110003", Role: "mahasiswa", Ledger: []seedEntry{
+		{Type: "mission", Direction: "credit", Amount: 200, Description: "Mission reward: Hackathon participation", DaysAgo: 20},
+		{Type: "transfer_out", Direction: "debit", Amount: 40, Description: "Transfer to Putri Ayu: lunch", DaysAgo: 7},
+		{Type: "marketplace", Direction: "debit", Amount: 60, Description: "Purchase: 2x Canteen Lunch Voucher", DaysAgo: 5, Product: "Canteen Lunch Voucher", Quantity: 2},
+	}},
+	{Email: "mahasiswa4@walletpoint.test", FullName: "Putri Ayu", NimNip: "2022110004", Role: "mahasiswa", Ledger: []seedEntry{
+		{Type: "transfer_in", Direction: "credit", Amount: 40, Description: "Transfer from Rizky Maulana: lunch", DaysAgo: 7},
+		{Type: "mission", Direction: "credit", Amount: 120, Description: "Mission reward: Open house guide", DaysAgo: 4},
+		{Type: "marketplace", Direction: "debit", Amount: 20, Description: "Purchase: 1x Coffee Voucher", DaysAgo: 1, Product: "Coffee Voucher", Quantity: 1},
+	}},
+	{Email: "mahasiswa5@walletpoint.test", FullName: "Fajar Nugroho", NimNip: "2023110005", Role: "mahasiswa"},
+}
+
+// SeedResult counts what Seed created; rows that already existed are left untouched
+type SeedResult struct {
+	Users        int
+	Categories   int
+	Products     int
+	Wallets      int
+	Transactions int
+}
+
+// Seed loads demo data for local development and demo environments. It is
+// idempotent: users are matched by email, categories by slug and products by
+// name, and a wallet's sample ledger is only written while the wallet has none.
+func Seed(db *gorm.DB) (*SeedResult, error) {
+	result := &SeedResult{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		passwordHash, err := utils.HashPassword(SeedPassword)
+		if err != nil {
+			return err
+		}
+		pinHash, err := utils.HashPassword(SeedPIN)
+		if err != nil {
+			return err
+		}
+
+		users := make(map[string]*auth.User, len(seedUsers))
+		for _, seed := range seedUsers {
+			user := &auth.User{}
+			res := tx.Where(auth.User{Email: seed.Email}).Attrs(auth.User{
+				PasswordHash: passwordHash,
+				PinHash:      pinHash,
+				FullName:     seed.FullName,
+				NimNip:       seed.NimNip,
+				Role:         seed.Role,
+				Status:       "active",
+			}).FirstOrCreate(user)
+			if res.Error != nil {
+				return fmt.Errorf("user %s: %w", seed.Email, res.Error)
+			}
+			result.Users += int(res.RowsAffected)
+			users[seed.Email] = user
+		}
+		admin := users[seedUsers[0].Email]
+
+		categories := make(map[string]*marketplace.Category, len(seedCategories))
+		for _, seed := range seedCategories {
+			category := &marketplace.Category{}
+			attrs := marketplace.Category{Name: seed.Name}
+			if seed.Parent != "" {
+				attrs.ParentID = &categories[seed.Parent].ID
+			}
+			res := tx.Where(marketplace.Category{TenantID: utils.DefaultTenantID, Slug: seed.Slug}).Attrs(attrs).FirstOrCreate(category)
+			if res.Error != nil {
+				return fmt.Errorf("category %s: %w", seed.Slug, res.Error)
+			}
+			result.Categories += int(res.RowsAffected)
+			categories[seed.Slug] = category
+		}
+
+		products := make(map[string]*marketplace.Product, len(seedProducts))
+		for _, seed := range seedProducts {
+			product := &marketplace.Product{}
+			res := tx.Where(marketplace.Product{Name: seed.Name}).Attrs(marketplace.Product{
+				Description: seed.Description,
+				Price:       seed.Price,
+				Stock:       seed.Stock,
+				Status:      "active",
+				CreatedBy:   admin.ID,
+			}).FirstOrCreate(product)
+			if res.Error != nil {
+				return fmt.Errorf("product %s: %w", seed.Name, res.Error)
+			}
+			if res.RowsAffected > 0 {
+				result.Products++
+				productCategories := make([]marketplace.Category, 0, len(seed.Categories))
+				for _, slug := range seed.Categories {
+					productCategories = append(productCategories, *categories[slug])
+				}
+				if err := tx.Model(product).Association("Categories").Append(productCategories); err != nil {
+					return fmt.Errorf("categories of %s: %w", seed.Name, err)
+				}
+			}
+			products[seed.Name] = product
+		}
+
+		for _, seed := range seedUsers {
+			user := users[seed.Email]
+			userWallet := &wallet.Wallet{}
+			res := tx.Where(wallet.Wallet{UserID: user.ID}).FirstOrCreate(userWallet)
+			if res.Error != nil {
+				return fmt.Errorf("wallet of %s: %w", seed.Email, res.Error)
+			}
+			result.Wallets += int(res.RowsAffected)
+
+			created, err := seedLedger(tx, userWallet, user, seed.Ledger, products)
+			if err != nil {
+				return fmt.Errorf("ledger of %s: %w", seed.Email, err)
+			}
+			result.Transactions += created
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// seedLedger writes the sample transactions of a wallet that has none yet and
+// sets its balance to their sum, so balance reconciliation finds nothing to report
+func seedLedger(tx *gorm.DB, userWallet *wallet.Wallet, user *auth.User, ledger []seedEntry, products map[string]*marketplace.Product) (int, error) {
+	if len(ledger) == 0 {
+		return 0, nil
+	}
+	var existing int64
+	if err := tx.Model(&wallet.WalletTransaction{}).Where("wallet_id = ?", userWallet.ID).Count(&existing).Error; err != nil {
+		return 0, err
+	}
+	if existing > 0 {
+		return 0, nil
+	}
+
+	balance := 0
+	for _, entry := range ledger {
+		at := time.Now().AddDate(0, 0, -entry.DaysAgo)
+		txn := &wallet.WalletTransaction{
+			WalletID:    userWallet.ID,
+			Type:        entry.Type,
+			Amount:      entry.Amount,
+			Direction:   entry.Direction,
+			Status:      "success",
+			Description: entry.Description,
+			CreatedBy:   "system",
+			CreatedAt:   at,
+		}
+		if entry.Type == "adjustment" {
+			txn.CreatedBy = "admin"
+		}
+		if err := tx.Create(txn).Error; err != nil {
+			return 0, err
+		}
+
+		if entry.Direction == "credit" {
+			balance += entry.Amount
+		} else {
+			balance -= entry.Amount
+		}
+
+		if product := products[entry.Product]; product != nil {
+			if err := tx.Create(&marketplace.MarketplaceTransaction{
+				WalletID:      userWallet.ID,
+				ProductID:     product.ID,
+				Amount:        product.Price,
+				TotalAmount:   entry.Amount,
+				Quantity:      entry.Quantity,
+				StudentName:   user.FullName,
+				StudentNPM:    user.NimNip,
+				PaymentMethod: "wallet",
+				Status:        "success",
+				CreatedAt:     at,
+			}).Error; err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tx.Model(userWallet).Update("balance", balance).Error; err != nil {
+		return 0, err
+	}
+	return len(ledger), nil
+}
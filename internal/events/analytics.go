@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"wallet-point/internal/metrics"
+)
+
+// SubscribeAnalytics feeds the business counters of the metrics package. They
+// live here rather than in metrics, which the job queue itself depends on.
+func SubscribeAnalytics(outbox *Outbox) {
+	outbox.Subscribe(EventOrderPlaced, "analytics", func(ctx context.Context, event *DomainEvent) error {
+		var placed OrderPlaced
+		if err := event.Decode(&placed); err != nil {
+			return err
+		}
+		metrics.OrdersPlaced.WithLabelValues(placed.Source).Add(float64(len(placed.Orders)))
+		metrics.OrderPoints.WithLabelValues(placed.Source).Add(float64(placed.Total))
+		return nil
+	})
+
+	countPoints := func(ctx context.Context, event *DomainEvent) error {
+		var entry LedgerEntry
+		if err := event.Decode(&entry); err != nil {
+			return err
+		}
+		metrics.PointsMoved.WithLabelValues(entry.Direction, entry.Type).Add(float64(entry.Amount))
+		return nil
+	}
+	outbox.Subscribe(EventPointsDebited, "analytics", countPoints)
+	outbox.Subscribe(EventPointsCredited, "analytics", countPoints)
+
+	outbox.Subscribe(EventStockLow, "analytics", func(ctx context.Context, event *DomainEvent) error {
+		metrics.StockLowAlerts.Inc()
+		return nil
+	})
+}
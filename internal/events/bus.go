@@ -0,0 +1,107 @@
+package events
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Topics published on the bus
+const (
+	// TopicProductChanged carries a marketplace.ProductChange after a product's stock, price
+	// or status changed (purchase, checkout, QR sale, admin edit)
+	TopicProductChanged = "product.changed"
+)
+
+type Event struct {
+	Topic   string
+	Payload interface{}
+	At      time.Time
+}
+
+// Bus is an in-process publish/subscribe hub. Publishing never blocks: a
+// subscriber whose buffer is full misses the event, so subscribers should only
+// ever need the latest state (e.g. a live stock display), not every event.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+type Subscription struct {
+	bus    *Bus
+	topics []string
+	ch     chan Event
+	once   sync.Once
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe returns a subscription receiving events of topics (all topics when
+// none are given) with room for buffer pending events. Call Close when done.
+func (b *Bus) Subscribe(buffer int, topics ...string) *Subscription {
+	sub := &Subscription{bus: b, topics: topics, ch: make(chan Event, buffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Publish hands the event to every matching subscriber
+func (b *Bus) Publish(topic string, payload interface{}) {
+	event := Event{Topic: topic, Payload: payload, At: time.Now()}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if len(sub.topics) > 0 && !slices.Contains(sub.topics, topic) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			slog.Debug("event bus: subscriber buffer full, event dropped", "topic", topic)
+		}
+	}
+}
+
+// Subscribers returns the number of open subscriptions
+func (b *Bus) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription; their channels are closed once drained
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		sub.once.Do(func() { close(sub.ch) })
+	}
+}
+
+// C delivers the subscription's events; it is closed by Close or Bus.Close
+func (s *Subscription) C() <-chan Event {
+	return s.ch
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	delete(s.bus.subs, s)
+	s.once.Do(func() { close(s.ch) })
+}
@@ -0,0 +1,108 @@
+package events
+
+import "time"
+
+// Domain events, published to the Outbox by the service that made the change.
+// Their names and payloads are read back from the domain_events table, so add
+// fields rather than renaming them.
+const (
+	// EventOrderPlaced carries an OrderPlaced after a purchase or cart checkout
+	EventOrderPlaced = "order.placed"
+	// EventPointsDebited and EventPointsCredited carry the LedgerEntry of every wallet movement
+	EventPointsDebited  = "points.debited"
+	EventPointsCredited = "points.credited"
+	// EventStockLow carries a StockLow when a product's low-stock alert fires (again)
+	EventStockLow = "stock.low"
+	// EventBackInStock carries a BackInStock when a restock takes a product from no stock to some
+	EventBackInStock = "stock.back_in_stock"
+	// EventQuestionAnswered carries a QuestionAnswered when an admin first answers a product question
+	EventQuestionAnswered = "product.question_answered"
+)
+
+const (
+	OrderSourcePurchase = "purchase"
+	OrderSourceCheckout = "checkout"
+)
+
+type OrderPlaced struct {
+	UserID   uint    `json:"user_id"`
+	WalletID uint    `json:"wallet_id"`
+	Source   string  `json:"source"` // purchase or checkout
+	Total    int     `json:"total"`
+	Orders   []Order `json:"orders"`
+	Gift     *Gift   `json:"gift,omitempty"` // set when the buyer had the orders issued to another student
+}
+
+// Gift names the buyer and the recipient of a gifted order
+type Gift struct {
+	RecipientID   uint   `json:"recipient_id"`
+	RecipientName string `json:"recipient_name"`
+	SenderName    string `json:"sender_name"`
+}
+
+// Order is one product of an OrderPlaced: the marketplace transaction as
+// recorded, plus the product's name
+type Order struct {
+	ID            uint      `json:"id"`
+	WalletID      uint      `json:"wallet_id"`
+	ProductID     uint      `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	Amount        int       `json:"amount"`
+	TotalAmount   int       `json:"total_amount"`
+	Quantity      int       `json:"quantity"`
+	StudentName   string    `json:"student_name"`
+	StudentNPM    string    `json:"student_npm"`
+	StudentMajor  string    `json:"student_major"`
+	StudentBatch  string    `json:"student_batch"`
+	PaymentMethod string    `json:"payment_method"`
+	Status        string    `json:"status"`
+	RecipientID   *uint     `json:"recipient_user_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LedgerEntry is a wallet transaction as recorded
+type LedgerEntry struct {
+	ID          uint      `json:"id"`
+	WalletID    uint      `json:"wallet_id"`
+	Type        string    `json:"type"`
+	Amount      int       `json:"amount"`
+	Direction   string    `json:"direction"`
+	ReferenceID *uint     `json:"reference_id"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StockLow reports a product at or below its low-stock threshold.
+// CategoryOwnerIDs are the admins who own the product's categories; OwnerID is
+// the admin who created the product.
+type StockLow struct {
+	TenantID    uint   `json:"tenant_id"`
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Stock       int    `json:"stock"`
+	Threshold   int    `json:"threshold"`
+	OwnerID     uint   `json:"owner_id"`
+
+	CategoryOwnerIDs []uint `json:"category_owner_ids,omitempty"`
+}
+
+// BackInStock reports a sold-out product that can be bought again. UserIDs are
+// the students who have it in their cart.
+type BackInStock struct {
+	TenantID    uint   `json:"tenant_id"`
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Stock       int    `json:"stock"`
+	UserIDs     []uint `json:"user_ids"`
+}
+
+// QuestionAnswered reports the answer to a student's question on a product page
+type QuestionAnswered struct {
+	TenantID    uint   `json:"tenant_id"`
+	QuestionID  uint   `json:"question_id"`
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	AskerID     uint   `json:"asker_id"`
+}
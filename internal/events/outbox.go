@@ -0,0 +1,189 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-point/internal/jobs"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// Job types of the outbox, run on the events queue
+const (
+	JobDispatch = "event.dispatch"
+	JobDeliver  = "event.deliver"
+)
+
+// DomainEvent is a published domain event as stored in the outbox. The origin
+// fields describe the API request that caused it, if any.
+type DomainEvent struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	Type         string          `json:"type" gorm:"size:100;not null;index"`
+	TenantID     uint            `json:"tenant_id" gorm:"index"`
+	Payload      json.RawMessage `json:"payload" gorm:"type:text" swaggertype:"object"`
+	RequestID    string          `json:"request_id,omitempty" gorm:"size:100"`
+	IPAddress    string          `json:"ip_address,omitempty" gorm:"size:45"`
+	UserAgent    string          `json:"user_agent,omitempty" gorm:"size:255"`
+	DispatchedAt *time.Time      `json:"dispatched_at"`
+	CreatedAt    time.Time       `json:"created_at" gorm:"index"`
+}
+
+func (DomainEvent) TableName() string {
+	return "domain_events"
+}
+
+// Decode unmarshals the payload into v, e.g. an *OrderPlaced. A payload that
+// does not fit will not fit on a retry either, so the error is permanent.
+func (e *DomainEvent) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return jobs.Permanent(fmt.Errorf("decode %s event %d: %w", e.Type, e.ID, err))
+	}
+	return nil
+}
+
+// Handler reacts to one domain event. Delivery is at least once: a handler that
+// fails is retried with backoff, so it may see an event again after a partial run.
+type Handler func(ctx context.Context, event *DomainEvent) error
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+type dispatchJob struct {
+	EventID uint `json:"event_id"`
+}
+
+type deliverJob struct {
+	EventID    uint   `json:"event_id"`
+	Subscriber string `json:"subscriber"`
+}
+
+// Outbox is the durable counterpart of Bus. Publish stores an event in the
+// transaction of the change it reports, so it exists exactly when the change
+// does; the job queue then hands it to every subscriber of its type, each
+// retried and dead-lettered on its own like any other job.
+type Outbox struct {
+	db          *gorm.DB
+	queue       *jobs.Queue
+	subscribers map[string][]subscriber
+}
+
+func NewOutbox(db *gorm.DB, queue *jobs.Queue) *Outbox {
+	o := &Outbox{db: db, queue: queue, subscribers: make(map[string][]subscriber)}
+	queue.Register(JobDispatch, jobs.QueueEvents, o.dispatch)
+	queue.Register(JobDeliver, jobs.QueueEvents, o.deliver)
+	return o
+}
+
+// Subscribe has handler receive every event of eventType. name identifies the
+// subscriber in its delivery jobs, so keep it stable; call Subscribe before
+// the job workers start.
+func (o *Outbox) Subscribe(eventType, name string, handler Handler) {
+	o.subscribers[eventType] = append(o.subscribers[eventType], subscriber{name: name, handler: handler})
+}
+
+// Publish stores an event of eventType with payload. Pass the transaction that
+// made the change; the campus and request origin are taken from its context.
+// With a nil tx the event is written in a transaction of its own.
+func (o *Outbox) Publish(tx *gorm.DB, eventType string, payload interface{}) error {
+	if tx == nil {
+		return o.db.Transaction(func(tx *gorm.DB) error {
+			return o.Publish(tx, eventType, payload)
+		})
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx := tx.Statement.Context
+	tenantID, _ := utils.TenantFromContext(ctx)
+	origin := utils.OriginFromContext(ctx)
+	event := &DomainEvent{
+		Type:      eventType,
+		TenantID:  tenantID,
+		Payload:   data,
+		RequestID: origin.RequestID,
+		IPAddress: origin.IPAddress,
+		UserAgent: origin.UserAgent,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return err
+	}
+	_, err = o.queue.EnqueueTx(tx, JobDispatch, dispatchJob{EventID: event.ID}, jobs.EnqueueOptions{})
+	return err
+}
+
+// dispatch fans an event out into one delivery job per subscriber. Publishers
+// such as walletctl need not know the subscribers; only the server does.
+func (o *Outbox) dispatch(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	var payload dispatchJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	event, err := o.find(ctx, payload.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribers := o.subscribers[event.Type]
+	err = o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, sub := range subscribers {
+			if _, err := o.queue.EnqueueTx(tx, JobDeliver, deliverJob{EventID: event.ID, Subscriber: sub.name}, jobs.EnqueueOptions{}); err != nil {
+				return err
+			}
+		}
+		return tx.Model(event).UpdateColumn("dispatched_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"event_type": event.Type, "subscribers": len(subscribers)}, nil
+}
+
+func (o *Outbox) deliver(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	var payload deliverJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	event, err := o.find(ctx, payload.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sub := range o.subscribers[event.Type] {
+		if sub.name != payload.Subscriber {
+			continue
+		}
+		if event.TenantID != 0 {
+			ctx = utils.WithTenant(ctx, event.TenantID)
+		}
+		return nil, sub.handler(ctx, event)
+	}
+	return nil, jobs.Permanent(fmt.Errorf("no subscriber %s for event type %s", payload.Subscriber, event.Type))
+}
+
+func (o *Outbox) find(ctx context.Context, id uint) (*DomainEvent, error) {
+	var event DomainEvent
+	err := o.db.WithContext(ctx).First(&event, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Removed by Cleanup while its jobs waited
+		return nil, jobs.Permanent(fmt.Errorf("domain event %d no longer exists", id))
+	}
+	return &event, err
+}
+
+// Cleanup deletes events older than retentionDays, for the scheduler. Their jobs
+// have long finished: the last retry of a failing one is hours after the event.
+func (o *Outbox) Cleanup(ctx context.Context, retentionDays int) (string, error) {
+	result := o.db.WithContext(ctx).Where("created_at < ?", time.Now().AddDate(0, 0, -retentionDays)).Delete(&DomainEvent{})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return fmt.Sprintf("deleted %d domain events", result.RowsAffected), nil
+}
@@ -0,0 +1,17 @@
+package faculty
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrStoreNotFound     = utils.NewAppError("FACULTY_STORE_NOT_FOUND", http.StatusNotFound, "faculty store not found")
+	ErrStoreCodeInvalid  = utils.NewAppError("FACULTY_STORE_CODE_INVALID", http.StatusBadRequest, "store code must be 2 to 50 lowercase letters, digits or dashes")
+	ErrStoreCodeTaken    = utils.NewAppError("FACULTY_STORE_CODE_TAKEN", http.StatusConflict, "store code is already used")
+	ErrStoreForbidden    = utils.NewAppError("FACULTY_STORE_FORBIDDEN", http.StatusForbidden, "you are not an admin of this store")
+	ErrStoreAdminInvalid = utils.NewAppError("FACULTY_STORE_ADMIN_INVALID", http.StatusBadRequest, "store admins must be active admin or lecturer accounts of the campus")
+	ErrCatalogProduct    = utils.NewAppError("FACULTY_STORE_PRODUCT_NOT_FOUND", http.StatusBadRequest, "catalog products must be products of the campus")
+	ErrBannerNotFound    = utils.NewAppError("FACULTY_STORE_BANNER_NOT_FOUND", http.StatusNotFound, "banner not found")
+	ErrBannerInvalid     = utils.NewAppError("FACULTY_STORE_BANNER_INVALID", http.StatusBadRequest, "a banner must end after it starts")
+)
@@ -0,0 +1,420 @@
+package faculty
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StoreHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewStoreHandler(service *Service, auditService audit.Logger) *StoreHandler {
+	return &StoreHandler{service: service, auditService: auditService}
+}
+
+// GetStores handles listing the open faculty stores
+// @Summary Get faculty stores
+// @Description The open faculty stores of the campus with their branding and catalog size. A store's products are listed with GET /stores/{store}/products or the X-Store header on the product list.
+// @Tags Faculty Stores
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Store}
+// @Router /stores [get]
+func (h *StoreHandler) GetStores(c *gin.Context) {
+	stores, err := h.service.GetStores(c.Request.Context(), true)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve faculty stores", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Faculty stores retrieved successfully", stores)
+}
+
+// GetStore handles getting the front page of a faculty store
+// @Summary Get faculty store
+// @Description An open faculty store with its branding and the banners showing now
+// @Tags Faculty Stores
+// @Security BearerAuth
+// @Produce json
+// @Param store path string true "Store code"
+// @Success 200 {object} utils.Response{data=Store}
+// @Failure 404 {object} utils.Response
+// @Router /stores/{store} [get]
+func (h *StoreHandler) GetStore(c *gin.Context) {
+	store, err := h.service.GetStore(c.Request.Context(), c.Param("store"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Faculty store retrieved successfully", store)
+}
+
+// GetAll handles listing every faculty store of the campus (Admin)
+// @Summary List faculty stores
+// @Description All faculty stores of the campus, closed ones included, with their admins and catalog size
+// @Tags Admin - Faculty Stores
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Store}
+// @Router /admin/faculty-stores [get]
+func (h *StoreHandler) GetAll(c *gin.Context) {
+	stores, err := h.service.GetStores(c.Request.Context(), false)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve faculty stores", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Faculty stores retrieved successfully", stores)
+}
+
+// Create handles opening a faculty store (Admin)
+// @Summary Create faculty store
+// @Description Open a branded sub-store for a faculty. code picks it in /stores/{store} paths and the X-Store header. Assign its admins with PUT /admin/faculty-stores/{id}/admins; they fill its catalog and banners.
+// @Tags Admin - Faculty Stores
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateStoreRequest true "Store"
+// @Success 201 {object} utils.Response{data=Store}
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/faculty-stores [post]
+func (h *StoreHandler) Create(c *gin.Context) {
+	var req CreateStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	store, err := h.service.CreateStore(c.Request.Context(), &req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Faculty store created successfully", store)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_FACULTY_STORE",
+		Entity:    "FACULTY_STORE",
+		EntityID:  store.ID,
+		Details:   fmt.Sprintf("Admin opened faculty store '%s' (%s)", store.Name, store.Code),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Update handles changing a faculty store (Admin)
+// @Summary Update faculty store
+// @Description Rename, open or close a store or change its branding; omitted fields are left unchanged. A closed store and its catalog are hidden from students.
+// @Tags Admin - Faculty Stores
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Store ID"
+// @Param request body UpdateStoreRequest true "Store changes"
+// @Success 200 {object} utils.Response{data=Store}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/faculty-stores/{id} [put]
+func (h *StoreHandler) Update(c *gin.Context) {
+	storeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid store ID", nil)
+		return
+	}
+	var req UpdateStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	store, err := h.service.UpdateStore(c.Request.Context(), uint(storeID), &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Faculty store updated successfully", store)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_FACULTY_STORE",
+		Entity:    "FACULTY_STORE",
+		EntityID:  store.ID,
+		Details:   fmt.Sprintf("Admin updated faculty store '%s' (active: %t)", store.Code, store.Active),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// SetAdmins handles replacing the admins of a faculty store (Admin)
+// @Summary Set faculty store admins
+// @Description Replace the admins of a store with active admin or lecturer accounts of the campus; an empty list removes them all. Store admins manage its branding, banners and catalog.
+// @Tags Admin - Faculty Stores
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Store ID"
+// @Param request body SetAdminsRequest true "Admin user IDs"
+// @Success 200 {object} utils.Response{data=Store}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/faculty-stores/{id}/admins [put]
+func (h *StoreHandler) SetAdmins(c *gin.Context) {
+	storeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid store ID", nil)
+		return
+	}
+	var req SetAdminsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	store, err := h.service.SetAdmins(c.Request.Context(), uint(storeID), req.UserIDs)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Faculty store admins updated successfully", store)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "SET_FACULTY_STORE_ADMINS",
+		Entity:    "FACULTY_STORE",
+		EntityID:  store.ID,
+		Details:   fmt.Sprintf("Admin set the admins of faculty store '%s' to %v", store.Code, store.AdminIDs),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateBranding handles changing the look of a managed store
+// @Summary Update store branding
+// @Description Change the tagline, logo and colors of a store you manage; omitted fields are left unchanged. Upload the logo with POST /upload first.
+// @Tags Faculty Stores - Manage
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param store path string true "Store code"
+// @Param request body BrandingRequest true "Branding changes"
+// @Success 200 {object} utils.Response{data=Store}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /stores/{store}/manage/branding [put]
+func (h *StoreHandler) UpdateBranding(c *gin.Context) {
+	store, ok := h.managed(c)
+	if !ok {
+		return
+	}
+	var req BrandingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	store, err := h.service.UpdateBranding(c.Request.Context(), store, &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Faculty store updated successfully", store)
+}
+
+// SetCatalog handles replacing the catalog of a managed store
+// @Summary Set store catalog
+// @Description Replace the products a store you manage sells with campus products, listed in the given order; an empty list empties the catalog. Stock, prices and purchases stay those of the campus marketplace.
+// @Tags Faculty Stores - Manage
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param store path string true "Store code"
+// @Param request body SetCatalogRequest true "Product IDs"
+// @Success 200 {object} utils.Response{data=SetCatalogRequest}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /stores/{store}/manage/products [put]
+func (h *StoreHandler) SetCatalog(c *gin.Context) {
+	store, ok := h.managed(c)
+	if !ok {
+		return
+	}
+	var req SetCatalogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	productIDs, err := h.service.SetCatalog(c.Request.Context(), store, req.ProductIDs)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Faculty store catalog updated successfully", SetCatalogRequest{ProductIDs: productIDs})
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "SET_FACULTY_STORE_CATALOG",
+		Entity:    "FACULTY_STORE",
+		EntityID:  store.ID,
+		Details:   fmt.Sprintf("Catalog of faculty store '%s' set to %d products", store.Code, len(productIDs)),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetBanners handles listing the banners of a managed store
+// @Summary Get store banners
+// @Description Every banner of a store you manage, inactive and scheduled ones included, by position
+// @Tags Faculty Stores - Manage
+// @Security BearerAuth
+// @Produce json
+// @Param store path string true "Store code"
+// @Success 200 {object} utils.Response{data=[]Banner}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /stores/{store}/manage/banners [get]
+func (h *StoreHandler) GetBanners(c *gin.Context) {
+	store, ok := h.managed(c)
+	if !ok {
+		return
+	}
+
+	banners, err := h.service.GetBanners(c.Request.Context(), store)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve banners", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Banners retrieved successfully", banners)
+}
+
+// CreateBanner handles adding a banner to a managed store
+// @Summary Create store banner
+// @Description Add a banner to a store you manage. It shows on the store page by position while active and between starts_at and ends_at when set. Upload the image with POST /upload first.
+// @Tags Faculty Stores - Manage
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param store path string true "Store code"
+// @Param request body BannerRequest true "Banner"
+// @Success 201 {object} utils.Response{data=Banner}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /stores/{store}/manage/banners [post]
+func (h *StoreHandler) CreateBanner(c *gin.Context) {
+	store, ok := h.managed(c)
+	if !ok {
+		return
+	}
+	var req BannerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	banner, err := h.service.CreateBanner(c.Request.Context(), store, &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Banner created successfully", banner)
+}
+
+// UpdateBanner handles replacing a banner of a managed store
+// @Summary Update store banner
+// @Description Replace every field of a banner of a store you manage
+// @Tags Faculty Stores - Manage
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param store path string true "Store code"
+// @Param id path int true "Banner ID"
+// @Param request body BannerRequest true "Banner"
+// @Success 200 {object} utils.Response{data=Banner}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /stores/{store}/manage/banners/{id} [put]
+func (h *StoreHandler) UpdateBanner(c *gin.Context) {
+	store, ok := h.managed(c)
+	if !ok {
+		return
+	}
+	bannerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid banner ID", nil)
+		return
+	}
+	var req BannerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	banner, err := h.service.UpdateBanner(c.Request.Context(), store, uint(bannerID), &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Banner updated successfully", banner)
+}
+
+// DeleteBanner handles deleting a banner of a managed store
+// @Summary Delete store banner
+// @Tags Faculty Stores - Manage
+// @Security BearerAuth
+// @Produce json
+// @Param store path string true "Store code"
+// @Param id path int true "Banner ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /stores/{store}/manage/banners/{id} [delete]
+func (h *StoreHandler) DeleteBanner(c *gin.Context) {
+	store, ok := h.managed(c)
+	if !ok {
+		return
+	}
+	bannerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid banner ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteBanner(c.Request.Context(), store, uint(bannerID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Banner deleted successfully", nil)
+}
+
+// managed loads the store of the path when the caller may manage it, or
+// writes the error response
+func (h *StoreHandler) managed(c *gin.Context) (*Store, bool) {
+	store, err := h.service.ManagedStore(c.Request.Context(), c.Param("store"), c.GetUint("user_id"), c.GetString("role"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return store, true
+}
@@ -0,0 +1,115 @@
+package faculty
+
+import "time"
+
+// Store is a faculty's branded sub-store of the campus marketplace. It sells a
+// hand-picked subset of the campus catalog with the campus wallets and orders,
+// and is run by its own admins next to the campus admins.
+type Store struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"tenant_id" gorm:"not null;default:1;uniqueIndex:idx_faculty_store_tenant_code"`
+	Code         string    `json:"code" gorm:"size:50;not null;uniqueIndex:idx_faculty_store_tenant_code"` // picks the store in paths and X-Store
+	Name         string    `json:"name" gorm:"size:150;not null"`
+	Tagline      string    `json:"tagline,omitempty" gorm:"size:255"`
+	LogoURL      string    `json:"logo_url,omitempty" gorm:"size:500"`
+	PrimaryColor string    `json:"primary_color,omitempty" gorm:"size:7"`
+	AccentColor  string    `json:"accent_color,omitempty" gorm:"size:7"`
+	Active       bool      `json:"active" gorm:"default:true;not null"`
+	CreatedBy    uint      `json:"created_by"`
+	ProductCount int       `json:"product_count" gorm:"-"`
+	AdminIDs     []uint    `json:"admin_ids,omitempty" gorm:"-"`
+	Banners      []Banner  `json:"banners,omitempty" gorm:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (Store) TableName() string {
+	return "faculty_stores"
+}
+
+// Banner is a promotional image on a store's front page, shown by Position
+// while active and within its optional StartsAt-EndsAt window
+type Banner struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TenantID  uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	StoreID   uint       `json:"store_id" gorm:"not null;index"`
+	Title     string     `json:"title" gorm:"size:150;not null"`
+	ImageURL  string     `json:"image_url" gorm:"size:500;not null"`
+	LinkURL   string     `json:"link_url,omitempty" gorm:"size:500"`
+	Position  int        `json:"position" gorm:"default:0;not null"`
+	Active    bool       `json:"active" gorm:"not null"`
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (Banner) TableName() string {
+	return "faculty_store_banners"
+}
+
+// StoreProduct puts a campus product in the catalog of a store
+type StoreProduct struct {
+	StoreID   uint `gorm:"primaryKey"`
+	ProductID uint `gorm:"primaryKey;index"`
+	Position  int  `gorm:"default:0;not null"`
+	CreatedAt time.Time
+}
+
+func (StoreProduct) TableName() string {
+	return "faculty_store_products"
+}
+
+// StoreAdmin lets a campus staff member manage a store's branding, banners and catalog
+type StoreAdmin struct {
+	StoreID   uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+func (StoreAdmin) TableName() string {
+	return "faculty_store_admins"
+}
+
+type CreateStoreRequest struct {
+	Code         string `json:"code" binding:"required,max=50" example:"fasilkom"`
+	Name         string `json:"name" binding:"required,max=150" example:"Toko Fasilkom"`
+	Tagline      string `json:"tagline" binding:"max=255"`
+	LogoURL      string `json:"logo_url" binding:"omitempty,max=500"`
+	PrimaryColor string `json:"primary_color" binding:"omitempty,hexcolor" example:"#1E3A8A"`
+	AccentColor  string `json:"accent_color" binding:"omitempty,hexcolor" example:"#F59E0B"`
+}
+
+// BrandingRequest changes the look of a store; omitted fields are left unchanged
+type BrandingRequest struct {
+	Tagline      *string `json:"tagline" binding:"omitempty,max=255"`
+	LogoURL      *string `json:"logo_url" binding:"omitempty,max=500"`
+	PrimaryColor *string `json:"primary_color" binding:"omitempty,hexcolor"`
+	AccentColor  *string `json:"accent_color" binding:"omitempty,hexcolor"`
+}
+
+// UpdateStoreRequest is what campus admins may change; omitted fields are left unchanged
+type UpdateStoreRequest struct {
+	Name   string `json:"name" binding:"max=150"`
+	Active *bool  `json:"active"`
+	BrandingRequest
+}
+
+type SetAdminsRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"max=50"`
+}
+
+// SetCatalogRequest replaces the catalog of a store, shown in this order
+type SetCatalogRequest struct {
+	ProductIDs []uint `json:"product_ids" binding:"max=1000"`
+}
+
+type BannerRequest struct {
+	Title    string     `json:"title" binding:"required,max=150"`
+	ImageURL string     `json:"image_url" binding:"required,max=500"`
+	LinkURL  string     `json:"link_url" binding:"max=500"`
+	Position int        `json:"position"`
+	Active   *bool      `json:"active"` // defaults to true
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
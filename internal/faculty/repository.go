@@ -0,0 +1,191 @@
+package faculty
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-point/internal/marketplace"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) FindAll(ctx context.Context, activeOnly bool) ([]Store, error) {
+	var stores []Store
+	query := r.db.WithContext(ctx)
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	err := query.Order("name ASC").Find(&stores).Error
+	return stores, err
+}
+
+func (r *Repository) FindByID(ctx context.Context, id uint) (*Store, error) {
+	var store Store
+	if err := r.db.WithContext(ctx).First(&store, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStoreNotFound
+		}
+		return nil, err
+	}
+	return &store, nil
+}
+
+func (r *Repository) FindByCode(ctx context.Context, code string) (*Store, error) {
+	var store Store
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&store).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStoreNotFound
+		}
+		return nil, err
+	}
+	return &store, nil
+}
+
+func (r *Repository) CodeTaken(ctx context.Context, code string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Store{}).Where("code = ?", code).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *Repository) Create(ctx context.Context, store *Store) error {
+	return r.db.WithContext(ctx).Create(store).Error
+}
+
+func (r *Repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Store{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// CountProducts returns the catalog size of each of storeIDs
+func (r *Repository) CountProducts(ctx context.Context, storeIDs []uint) (map[uint]int, error) {
+	var rows []struct {
+		StoreID uint
+		Count   int
+	}
+	err := r.db.WithContext(ctx).Model(&StoreProduct{}).
+		Select("store_id, COUNT(*) AS count").
+		Where("store_id IN ?", storeIDs).
+		Group("store_id").
+		Scan(&rows).Error
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.StoreID] = row.Count
+	}
+	return counts, err
+}
+
+// FindProductIDs returns the catalog of a store in its order
+func (r *Repository) FindProductIDs(ctx context.Context, storeID uint) ([]uint, error) {
+	var productIDs []uint
+	err := r.db.WithContext(ctx).Model(&StoreProduct{}).
+		Where("store_id = ?", storeID).
+		Order("position ASC").
+		Pluck("product_id", &productIDs).Error
+	return productIDs, err
+}
+
+// CountCampusProducts counts how many of productIDs are products of the campus of ctx
+func (r *Repository) CountCampusProducts(ctx context.Context, productIDs []uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&marketplace.Product{}).Where("id IN ?", productIDs).Count(&count).Error
+	return count, err
+}
+
+// ReplaceProducts sets the catalog of a store to productIDs, in that order
+func (r *Repository) ReplaceProducts(ctx context.Context, storeID uint, productIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("store_id = ?", storeID).Delete(&StoreProduct{}).Error; err != nil {
+			return err
+		}
+		if len(productIDs) == 0 {
+			return nil
+		}
+		rows := make([]StoreProduct, len(productIDs))
+		for i, productID := range productIDs {
+			rows[i] = StoreProduct{StoreID: storeID, ProductID: productID, Position: i}
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (r *Repository) FindAdminIDs(ctx context.Context, storeID uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.WithContext(ctx).Model(&StoreAdmin{}).Where("store_id = ?", storeID).Order("user_id").Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+func (r *Repository) IsAdmin(ctx context.Context, storeID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&StoreAdmin{}).Where("store_id = ? AND user_id = ?", storeID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// CountStaff counts how many of userIDs are active admin or lecturer accounts of the campus of ctx
+func (r *Repository) CountStaff(ctx context.Context, userIDs []uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("users").
+		Scopes(utils.ScopeTenant(ctx, "tenant_id")).
+		Where("id IN ? AND role IN ? AND status = ? AND deleted_at IS NULL", userIDs, []string{"admin", "dosen"}, "active").
+		Count(&count).Error
+	return count, err
+}
+
+// ReplaceAdmins sets the admins of a store to userIDs
+func (r *Repository) ReplaceAdmins(ctx context.Context, storeID uint, userIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("store_id = ?", storeID).Delete(&StoreAdmin{}).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+		rows := make([]StoreAdmin, len(userIDs))
+		for i, userID := range userIDs {
+			rows[i] = StoreAdmin{StoreID: storeID, UserID: userID}
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+// FindBanners lists the banners of a store by position; live limits them to
+// active ones within their window at now
+func (r *Repository) FindBanners(ctx context.Context, storeID uint, live bool, now time.Time) ([]Banner, error) {
+	var banners []Banner
+	query := r.db.WithContext(ctx).Where("store_id = ?", storeID)
+	if live {
+		query = query.Where("active = ? AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", true, now, now)
+	}
+	err := query.Order("position ASC").Order("id ASC").Find(&banners).Error
+	return banners, err
+}
+
+func (r *Repository) FindBanner(ctx context.Context, storeID, bannerID uint) (*Banner, error) {
+	var banner Banner
+	if err := r.db.WithContext(ctx).Where("store_id = ?", storeID).First(&banner, bannerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBannerNotFound
+		}
+		return nil, err
+	}
+	return &banner, nil
+}
+
+func (r *Repository) CreateBanner(ctx context.Context, banner *Banner) error {
+	return r.db.WithContext(ctx).Create(banner).Error
+}
+
+func (r *Repository) SaveBanner(ctx context.Context, banner *Banner) error {
+	return r.db.WithContext(ctx).Save(banner).Error
+}
+
+func (r *Repository) DeleteBanner(ctx context.Context, bannerID uint) error {
+	return r.db.WithContext(ctx).Delete(&Banner{}, bannerID).Error
+}
@@ -0,0 +1,307 @@
+package faculty
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"wallet-point/internal/marketplace"
+)
+
+var storeCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,49}$`)
+
+type Service struct {
+	repo        *Repository
+	marketplace *marketplace.MarketplaceService
+}
+
+// NewService lets product lists be narrowed to a store's catalog
+func NewService(repo *Repository, marketplaceService *marketplace.MarketplaceService) *Service {
+	s := &Service{repo: repo, marketplace: marketplaceService}
+	marketplaceService.SetStoreCatalogs(s)
+	return s
+}
+
+// CatalogProductIDs returns the catalog of the active store with code
+func (s *Service) CatalogProductIDs(ctx context.Context, code string) ([]uint, error) {
+	store, err := s.activeStore(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindProductIDs(ctx, store.ID)
+}
+
+// GetStores lists the stores of the campus with their catalog size; activeOnly
+// leaves out the closed ones
+func (s *Service) GetStores(ctx context.Context, activeOnly bool) ([]Store, error) {
+	stores, err := s.repo.FindAll(ctx, activeOnly)
+	if err != nil || len(stores) == 0 {
+		return stores, err
+	}
+	storeIDs := make([]uint, len(stores))
+	for i, store := range stores {
+		storeIDs[i] = store.ID
+	}
+	counts, err := s.repo.CountProducts(ctx, storeIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stores {
+		stores[i].ProductCount = counts[stores[i].ID]
+		if !activeOnly {
+			if stores[i].AdminIDs, err = s.repo.FindAdminIDs(ctx, stores[i].ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stores, nil
+}
+
+// GetStore returns the front page of an active store: its branding, the banners
+// showing now and its catalog size
+func (s *Service) GetStore(ctx context.Context, code string) (*Store, error) {
+	store, err := s.activeStore(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if store.Banners, err = s.repo.FindBanners(ctx, store.ID, true, time.Now()); err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountProducts(ctx, []uint{store.ID})
+	if err != nil {
+		return nil, err
+	}
+	store.ProductCount = counts[store.ID]
+	return store, nil
+}
+
+// CreateStore opens a store in the campus of ctx
+func (s *Service) CreateStore(ctx context.Context, req *CreateStoreRequest, adminID uint) (*Store, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if !storeCodePattern.MatchString(code) {
+		return nil, ErrStoreCodeInvalid
+	}
+	taken, err := s.repo.CodeTaken(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrStoreCodeTaken
+	}
+
+	store := &Store{
+		Code:         code,
+		Name:         strings.TrimSpace(req.Name),
+		Tagline:      strings.TrimSpace(req.Tagline),
+		LogoURL:      req.LogoURL,
+		PrimaryColor: strings.ToUpper(req.PrimaryColor),
+		AccentColor:  strings.ToUpper(req.AccentColor),
+		Active:       true,
+		CreatedBy:    adminID,
+	}
+	if err := s.repo.Create(ctx, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// UpdateStore renames, opens or closes a store or changes its branding
+func (s *Service) UpdateStore(ctx context.Context, storeID uint, req *UpdateStoreRequest) (*Store, error) {
+	store, err := s.repo.FindByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := brandingUpdates(store, &req.BrandingRequest)
+	if name := strings.TrimSpace(req.Name); name != "" {
+		store.Name = name
+		updates["name"] = name
+	}
+	if req.Active != nil {
+		store.Active = *req.Active
+		updates["active"] = store.Active
+	}
+	if len(updates) == 0 {
+		return store, nil
+	}
+	if err := s.repo.Update(ctx, store.ID, updates); err != nil {
+		return nil, err
+	}
+	if req.Active != nil {
+		// Cached pages of the store's catalog must start or stop answering
+		s.marketplace.InvalidateLists()
+	}
+	return store, nil
+}
+
+// UpdateBranding changes the look of a store, for its admins
+func (s *Service) UpdateBranding(ctx context.Context, store *Store, req *BrandingRequest) (*Store, error) {
+	updates := brandingUpdates(store, req)
+	if len(updates) == 0 {
+		return store, nil
+	}
+	if err := s.repo.Update(ctx, store.ID, updates); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// SetAdmins replaces the admins of a store with staff of the campus
+func (s *Service) SetAdmins(ctx context.Context, storeID uint, userIDs []uint) (*Store, error) {
+	store, err := s.repo.FindByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs = uniqueIDs(userIDs)
+	if len(userIDs) > 0 {
+		staff, err := s.repo.CountStaff(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		if int(staff) != len(userIDs) {
+			return nil, ErrStoreAdminInvalid
+		}
+	}
+	if err := s.repo.ReplaceAdmins(ctx, store.ID, userIDs); err != nil {
+		return nil, err
+	}
+	store.AdminIDs = userIDs
+	return store, nil
+}
+
+// ManagedStore returns the store with code when userID may manage it: campus
+// admins manage every store of their campus, store admins their own
+func (s *Service) ManagedStore(ctx context.Context, code string, userID uint, role string) (*Store, error) {
+	store, err := s.repo.FindByCode(ctx, strings.ToLower(code))
+	if err != nil {
+		return nil, err
+	}
+	if role == "admin" || role == "superadmin" {
+		return store, nil
+	}
+	isAdmin, err := s.repo.IsAdmin(ctx, store.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrStoreForbidden
+	}
+	return store, nil
+}
+
+// SetCatalog replaces the products a store sells with campus products, in order
+func (s *Service) SetCatalog(ctx context.Context, store *Store, productIDs []uint) ([]uint, error) {
+	productIDs = uniqueIDs(productIDs)
+	if len(productIDs) > 0 {
+		found, err := s.repo.CountCampusProducts(ctx, productIDs)
+		if err != nil {
+			return nil, err
+		}
+		if int(found) != len(productIDs) {
+			return nil, ErrCatalogProduct
+		}
+	}
+	if err := s.repo.ReplaceProducts(ctx, store.ID, productIDs); err != nil {
+		return nil, err
+	}
+	s.marketplace.InvalidateLists()
+	return productIDs, nil
+}
+
+// GetBanners lists every banner of a store, inactive ones included
+func (s *Service) GetBanners(ctx context.Context, store *Store) ([]Banner, error) {
+	return s.repo.FindBanners(ctx, store.ID, false, time.Time{})
+}
+
+func (s *Service) CreateBanner(ctx context.Context, store *Store, req *BannerRequest) (*Banner, error) {
+	banner := &Banner{TenantID: store.TenantID, StoreID: store.ID}
+	if err := applyBanner(banner, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateBanner(ctx, banner); err != nil {
+		return nil, err
+	}
+	return banner, nil
+}
+
+// UpdateBanner replaces the fields of a banner
+func (s *Service) UpdateBanner(ctx context.Context, store *Store, bannerID uint, req *BannerRequest) (*Banner, error) {
+	banner, err := s.repo.FindBanner(ctx, store.ID, bannerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyBanner(banner, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveBanner(ctx, banner); err != nil {
+		return nil, err
+	}
+	return banner, nil
+}
+
+func (s *Service) DeleteBanner(ctx context.Context, store *Store, bannerID uint) error {
+	if _, err := s.repo.FindBanner(ctx, store.ID, bannerID); err != nil {
+		return err
+	}
+	return s.repo.DeleteBanner(ctx, bannerID)
+}
+
+func (s *Service) activeStore(ctx context.Context, code string) (*Store, error) {
+	store, err := s.repo.FindByCode(ctx, strings.ToLower(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if !store.Active {
+		return nil, fmt.Errorf("%w: '%s' is closed", ErrStoreNotFound, store.Code)
+	}
+	return store, nil
+}
+
+// brandingUpdates applies req to store and returns the columns it changed
+func brandingUpdates(store *Store, req *BrandingRequest) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if req.Tagline != nil {
+		store.Tagline = strings.TrimSpace(*req.Tagline)
+		updates["tagline"] = store.Tagline
+	}
+	if req.LogoURL != nil {
+		store.LogoURL = *req.LogoURL
+		updates["logo_url"] = store.LogoURL
+	}
+	if req.PrimaryColor != nil {
+		store.PrimaryColor = strings.ToUpper(*req.PrimaryColor)
+		updates["primary_color"] = store.PrimaryColor
+	}
+	if req.AccentColor != nil {
+		store.AccentColor = strings.ToUpper(*req.AccentColor)
+		updates["accent_color"] = store.AccentColor
+	}
+	return updates
+}
+
+func applyBanner(banner *Banner, req *BannerRequest) error {
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return ErrBannerInvalid
+	}
+	banner.Title = strings.TrimSpace(req.Title)
+	banner.ImageURL = req.ImageURL
+	banner.LinkURL = req.LinkURL
+	banner.Position = req.Position
+	banner.Active = req.Active == nil || *req.Active
+	banner.StartsAt = req.StartsAt
+	banner.EndsAt = req.EndsAt
+	return nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,12 @@
+package feature
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrFlagNotFound    = utils.NewAppError("FEATURE_FLAG_NOT_FOUND", http.StatusNotFound, "feature flag not found")
+	ErrFeatureDisabled = utils.NewAppError("FEATURE_DISABLED", http.StatusForbidden, "this feature is currently disabled")
+	ErrMaintenance     = utils.NewAppError("MAINTENANCE", http.StatusServiceUnavailable, "Wallet Point is undergoing maintenance; changes are paused for a few minutes, please try again shortly")
+)
@@ -0,0 +1,87 @@
+package feature
+
+import (
+	"fmt"
+	"net/http"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FlagHandler struct {
+	service      *FlagService
+	auditService audit.Logger
+}
+
+func NewFlagHandler(service *FlagService, auditService audit.Logger) *FlagHandler {
+	return &FlagHandler{service: service, auditService: auditService}
+}
+
+// GetMyFeatures handles listing which features are on for the current user
+// @Summary Get enabled features
+// @Description Get the feature flags evaluated for the authenticated user's role and this environment
+// @Tags Features
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=map[string]bool}
+// @Router /features [get]
+func (h *FlagHandler) GetMyFeatures(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Features retrieved successfully", h.service.EnabledFor(c.GetString("role")))
+}
+
+// GetAll handles listing all feature flags (Admin)
+// @Summary Get feature flags
+// @Tags Admin - Feature Flags
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]FeatureFlag}
+// @Router /admin/feature-flags [get]
+func (h *FlagHandler) GetAll(c *gin.Context) {
+	flags, err := h.service.GetFlags()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve feature flags", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Feature flags retrieved successfully", flags)
+}
+
+// Update handles toggling or retargeting a feature flag (Admin)
+// @Summary Update feature flag
+// @Description Enable or disable a feature, optionally only for some environments (APP_ENV) or roles. Other instances pick up the change within a minute.
+// @Tags Admin - Feature Flags
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param key path string true "Flag key"
+// @Param request body UpdateFlagRequest true "Flag settings"
+// @Success 200 {object} utils.Response{data=FeatureFlag}
+// @Failure 404 {object} utils.Response
+// @Router /admin/feature-flags/{key} [put]
+func (h *FlagHandler) Update(c *gin.Context) {
+	var req UpdateFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	flag, err := h.service.UpdateFlag(c.Param("key"), req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Feature flag updated", flag)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "UPDATE_FEATURE_FLAG",
+		Entity:    "FEATURE_FLAG",
+		EntityID:  flag.ID,
+		Details:   fmt.Sprintf("Set feature %s enabled=%t (environments: %q, roles: %q)", flag.Key, flag.Enabled, flag.Environments, flag.Roles),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,46 @@
+package feature
+
+import (
+	"time"
+)
+
+// Flags known to the code; a flag missing from the database falls back to its default here
+const (
+	Transfers    = "transfers"
+	PreOrders    = "pre_orders"
+	NewOrderFlow = "new_order_flow"
+
+	MaintenanceMode = "maintenance_mode"
+)
+
+var defaultFlags = []FeatureFlag{
+	{Key: Transfers, Description: "Student-to-student point transfers and points gifts", Enabled: true},
+	{Key: PreOrders, Description: "Pre-ordering out-of-stock products", Enabled: false},
+	{Key: NewOrderFlow, Description: "Order-based checkout flow", Enabled: false},
+	{Key: MaintenanceMode, Description: "Maintenance: writes are refused with 503 while reads keep working", Enabled: false},
+}
+
+// FeatureFlag switches a feature on or off. Environments and Roles are comma-separated
+// allow-lists (e.g. "staging,production", "admin,dosen"); empty means all.
+type FeatureFlag struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Key          string    `json:"key" gorm:"size:100;uniqueIndex;not null"`
+	Description  string    `json:"description" gorm:"size:255"`
+	Enabled      bool      `json:"enabled" gorm:"default:false;not null"`
+	Environments string    `json:"environments" gorm:"size:255"`
+	Roles        string    `json:"roles" gorm:"size:255"`
+	UpdatedBy    *uint     `json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+type UpdateFlagRequest struct {
+	Enabled      *bool   `json:"enabled" binding:"required"`
+	Description  *string `json:"description" binding:"omitempty,max=255"`
+	Environments *string `json:"environments" binding:"omitempty,max=255"`
+	Roles        *string `json:"roles" binding:"omitempty,max=255"`
+}
@@ -0,0 +1,47 @@
+package feature
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type FlagRepository struct {
+	db *gorm.DB
+}
+
+func NewFlagRepository(db *gorm.DB) *FlagRepository {
+	return &FlagRepository{db: db}
+}
+
+func (r *FlagRepository) FindAll() ([]FeatureFlag, error) {
+	var flags []FeatureFlag
+	err := r.db.Order("`key` ASC").Find(&flags).Error
+	return flags, err
+}
+
+func (r *FlagRepository) FindByKey(key string) (*FeatureFlag, error) {
+	var flag FeatureFlag
+	err := r.db.Where("`key` = ?", key).First(&flag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFlagNotFound
+		}
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// CreateMissing inserts the flags whose key does not exist yet, leaving existing ones untouched
+func (r *FlagRepository) CreateMissing(flags []FeatureFlag) error {
+	for i := range flags {
+		if err := r.db.Where(FeatureFlag{Key: flags[i].Key}).FirstOrCreate(&flags[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *FlagRepository) Update(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&FeatureFlag{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,184 @@
+package feature
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+type FlagService struct {
+	repo        *FlagRepository
+	environment string
+	ttl         time.Duration
+
+	mu       sync.RWMutex
+	flags    map[string]FeatureFlag
+	loadedAt time.Time
+}
+
+// NewFlagService evaluates flags for environment (APP_ENV); the flag table is
+// cached for ttl so every instance picks up admin changes within that window
+func NewFlagService(repo *FlagRepository, environment string, ttl time.Duration) *FlagService {
+	return &FlagService{
+		repo:        repo,
+		environment: environment,
+		ttl:         ttl,
+	}
+}
+
+// EnsureDefaults creates the rows of the flags known to the code so admins can toggle them
+func (s *FlagService) EnsureDefaults() error {
+	flags := make([]FeatureFlag, len(defaultFlags))
+	copy(flags, defaultFlags)
+	if err := s.repo.CreateMissing(flags); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// IsEnabled reports whether key is on for the current environment and the given role
+func (s *FlagService) IsEnabled(key, role string) bool {
+	flag, ok := s.lookup(key)
+	if !ok || !flag.Enabled {
+		return false
+	}
+	return matches(flag.Environments, s.environment) && matches(flag.Roles, role)
+}
+
+// Enabled reports whether key is on for the current environment, regardless of role.
+// Service hooks use it; per-role targeting is enforced by the FeatureFlag middleware.
+func (s *FlagService) Enabled(key string) bool {
+	flag, ok := s.lookup(key)
+	if !ok || !flag.Enabled {
+		return false
+	}
+	return matches(flag.Environments, s.environment)
+}
+
+// EnabledFor lists the flags that are on for role, for clients to adapt their UI
+func (s *FlagService) EnabledFor(role string) map[string]bool {
+	s.refresh()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make(map[string]bool, len(s.flags))
+	for key := range s.flags {
+		flag := s.flags[key]
+		result[key] = flag.Enabled && matches(flag.Environments, s.environment) && matches(flag.Roles, role)
+	}
+	return result
+}
+
+// GetFlags lists all flags (Admin)
+func (s *FlagService) GetFlags() ([]FeatureFlag, error) {
+	return s.repo.FindAll()
+}
+
+// UpdateFlag changes a flag (Admin); the change applies to this instance immediately
+func (s *FlagService) UpdateFlag(key string, req UpdateFlagRequest, adminID uint) (*FeatureFlag, error) {
+	flag, err := s.repo.FindByKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"enabled":    *req.Enabled,
+		"updated_by": adminID,
+	}
+	if req.Description != nil {
+		updates["description"] = strings.TrimSpace(*req.Description)
+	}
+	if req.Environments != nil {
+		updates["environments"] = normalizeList(*req.Environments)
+	}
+	if req.Roles != nil {
+		updates["roles"] = normalizeList(*req.Roles)
+	}
+
+	if err := s.repo.Update(flag.ID, updates); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return s.repo.FindByKey(key)
+}
+
+func (s *FlagService) lookup(key string) (FeatureFlag, bool) {
+	s.refresh()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	flag, ok := s.flags[key]
+	return flag, ok
+}
+
+// refresh reloads the flag table once the cache is older than ttl. When the
+// database is unreachable the last known flags (or the code defaults) stay in use.
+func (s *FlagService) refresh() {
+	s.mu.RLock()
+	fresh := s.flags != nil && time.Since(s.loadedAt) < s.ttl
+	s.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.flags != nil && time.Since(s.loadedAt) < s.ttl {
+		return
+	}
+
+	flags, err := s.repo.FindAll()
+	if err != nil {
+		slog.Error("feature flags: reload failed", "error", err)
+		if s.flags == nil {
+			s.flags = indexFlags(defaultFlags)
+		}
+		// Retry on the next round instead of on every request
+		s.loadedAt = time.Now()
+		return
+	}
+
+	s.flags = indexFlags(append(append([]FeatureFlag{}, defaultFlags...), flags...))
+	s.loadedAt = time.Now()
+}
+
+func (s *FlagService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// indexFlags maps flags by key; later entries win, so stored flags override the defaults
+func indexFlags(flags []FeatureFlag) map[string]FeatureFlag {
+	index := make(map[string]FeatureFlag, len(flags))
+	for _, flag := range flags {
+		index[flag.Key] = flag
+	}
+	return index
+}
+
+// matches reports whether value is in the comma-separated allow-list (empty allows all)
+func matches(list, value string) bool {
+	if strings.TrimSpace(list) == "" {
+		return true
+	}
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeList(list string) string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,13 @@
+package fulfillment
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrFulfillmentNotFound = utils.NewAppError("FULFILLMENT_NOT_FOUND", http.StatusNotFound, "order fulfillment not found")
+	ErrAlreadyClaimed      = utils.NewAppError("FULFILLMENT_ALREADY_CLAIMED", http.StatusConflict, "order is already claimed by another staff member")
+	ErrNotClaimed          = utils.NewAppError("FULFILLMENT_NOT_CLAIMED", http.StatusConflict, "claim the order before completing or releasing it")
+	ErrAlreadyCompleted    = utils.NewAppError("FULFILLMENT_COMPLETED", http.StatusConflict, "order was already handed over")
+)
@@ -0,0 +1,122 @@
+package fulfillment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FulfillmentHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewFulfillmentHandler(service *Service, auditService audit.Logger) *FulfillmentHandler {
+	return &FulfillmentHandler{service: service, auditService: auditService}
+}
+
+// GetQueue handles listing the fulfillment work queue (Admin)
+// @Summary Get fulfillment queue
+// @Description Paid physical orders not yet handed over, grouped by pickup location with the oldest order first. Products without a pickup location are collected at the main store.
+// @Tags Admin - Fulfillment
+// @Security BearerAuth
+// @Produce json
+// @Param pickup_location query string false "Only this pickup location"
+// @Param status query string false "Only pending or claimed orders" Enums(pending, claimed)
+// @Param mine query bool false "Only the orders you claimed"
+// @Success 200 {object} utils.Response{data=[]QueueGroup}
+// @Router /admin/fulfillment/queue [get]
+func (h *FulfillmentHandler) GetQueue(c *gin.Context) {
+	status, ok := utils.QueryEnum(c, "status", "fulfillment_status")
+	if !ok {
+		return
+	}
+	params := QueueParams{PickupLocation: c.Query("pickup_location"), Status: status}
+	if c.Query("mine") == "true" {
+		params.ClaimedBy = c.GetUint("user_id")
+	}
+
+	queue, err := h.service.GetQueue(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve fulfillment queue", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Fulfillment queue retrieved successfully", queue)
+}
+
+// Claim handles taking an order from the queue (Admin)
+// @Summary Claim order
+// @Description Takes a pending order to prepare; other staff see it as claimed by you
+// @Tags Admin - Fulfillment
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Fulfillment ID"
+// @Success 200 {object} utils.Response{data=Fulfillment}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/fulfillment/{id}/claim [post]
+func (h *FulfillmentHandler) Claim(c *gin.Context) {
+	h.transition(c, h.service.Claim, "Order claimed", "CLAIM_ORDER_FULFILLMENT", "Claimed")
+}
+
+// Release handles putting a claimed order back on the queue (Admin)
+// @Summary Release order
+// @Tags Admin - Fulfillment
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Fulfillment ID"
+// @Success 200 {object} utils.Response{data=Fulfillment}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/fulfillment/{id}/release [post]
+func (h *FulfillmentHandler) Release(c *gin.Context) {
+	h.transition(c, h.service.Release, "Order released", "RELEASE_ORDER_FULFILLMENT", "Released")
+}
+
+// Complete handles recording that the student collected an order (Admin)
+// @Summary Complete order
+// @Description Marks an order you claimed as handed over, taking it off the queue
+// @Tags Admin - Fulfillment
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Fulfillment ID"
+// @Success 200 {object} utils.Response{data=Fulfillment}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/fulfillment/{id}/complete [post]
+func (h *FulfillmentHandler) Complete(c *gin.Context) {
+	h.transition(c, h.service.Complete, "Order handed over", "COMPLETE_ORDER_FULFILLMENT", "Handed over")
+}
+
+func (h *FulfillmentHandler) transition(c *gin.Context, apply func(ctx context.Context, id, staffID uint) (*Fulfillment, error), message, action, verb string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid fulfillment ID", nil)
+		return
+	}
+
+	staffID := c.GetUint("user_id")
+	fulfillment, err := apply(c.Request.Context(), uint(id), staffID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, message, fulfillment)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    staffID,
+		Action:    action,
+		Entity:    "ORDER_FULFILLMENT",
+		EntityID:  fulfillment.ID,
+		Details:   fmt.Sprintf("%s order %d: %dx %s at %s", verb, fulfillment.OrderID, fulfillment.Quantity, fulfillment.ProductName, fulfillment.PickupLocation),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,60 @@
+package fulfillment
+
+import "time"
+
+const (
+	StatusPending   = "pending"
+	StatusClaimed   = "claimed" // a staff member is preparing it
+	StatusCompleted = "completed"
+)
+
+// MainStore is the pickup location of products without one
+const MainStore = "Toko utama"
+
+// Fulfillment is the hand-over of a paid physical order. It is created when the
+// order is placed, at the product's pickup location of that moment, and worked
+// off by the store staff: claimed by one of them, then completed when the
+// student collected it.
+type Fulfillment struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	TenantID       uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	OrderID        uint       `json:"order_id" gorm:"not null;uniqueIndex"`
+	ProductID      uint       `json:"product_id" gorm:"not null"`
+	ProductName    string     `json:"product_name" gorm:"size:255;not null"`
+	Quantity       int        `json:"quantity" gorm:"not null"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"` // collects the order: the buyer or a gift's recipient
+	PickupLocation string     `json:"pickup_location" gorm:"size:100;not null;index"`
+	Status         string     `json:"status" gorm:"type:enum('pending','claimed','completed');default:'pending';not null;index"`
+	ClaimedBy      *uint      `json:"claimed_by"`
+	ClaimedAt      *time.Time `json:"claimed_at"`
+	CompletedBy    *uint      `json:"completed_by"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Fulfillment) TableName() string {
+	return "order_fulfillments"
+}
+
+// QueueItem is an open fulfillment with the student who collects it
+type QueueItem struct {
+	Fulfillment
+	StudentName string `json:"student_name"`
+	StudentNIM  string `json:"student_nim"`
+	ClaimerName string `json:"claimer_name,omitempty"`
+}
+
+// QueueGroup is the work queue of one pickup location, oldest order first
+type QueueGroup struct {
+	PickupLocation string      `json:"pickup_location"`
+	Pending        int         `json:"pending"`
+	Claimed        int         `json:"claimed"`
+	Orders         []QueueItem `json:"orders"`
+}
+
+type QueueParams struct {
+	PickupLocation string
+	Status         string // pending or claimed; both when empty
+	ClaimedBy      uint   // only the orders claimed by this staff member
+}
@@ -0,0 +1,63 @@
+package fulfillment
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(tx *gorm.DB, fulfillment *Fulfillment) error {
+	return tx.Create(fulfillment).Error
+}
+
+// Lock loads a fulfillment for update within tx, so two staff members cannot claim it both
+func (r *Repository) Lock(tx *gorm.DB, id uint) (*Fulfillment, error) {
+	var fulfillment Fulfillment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&fulfillment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFulfillmentNotFound
+		}
+		return nil, err
+	}
+	return &fulfillment, nil
+}
+
+func (r *Repository) Update(tx *gorm.DB, fulfillment *Fulfillment, updates map[string]interface{}) error {
+	return tx.Model(fulfillment).Updates(updates).Error
+}
+
+// FindOpen lists the pending and claimed fulfillments by pickup location, oldest first
+func (r *Repository) FindOpen(ctx context.Context, params QueueParams) ([]QueueItem, error) {
+	var items []QueueItem
+
+	query := r.db.WithContext(ctx).Model(&Fulfillment{}).
+		Select("order_fulfillments.*, student.full_name AS student_name, student.nim_nip AS student_nim, claimer.full_name AS claimer_name").
+		Joins("LEFT JOIN users student ON student.id = order_fulfillments.user_id").
+		Joins("LEFT JOIN users claimer ON claimer.id = order_fulfillments.claimed_by")
+	if params.Status != "" {
+		query = query.Where("order_fulfillments.status = ?", params.Status)
+	} else {
+		query = query.Where("order_fulfillments.status IN ?", []string{StatusPending, StatusClaimed})
+	}
+	if params.PickupLocation != "" {
+		query = query.Where("order_fulfillments.pickup_location = ?", params.PickupLocation)
+	}
+	if params.ClaimedBy != 0 {
+		query = query.Where("order_fulfillments.claimed_by = ?", params.ClaimedBy)
+	}
+
+	err := query.Order("order_fulfillments.pickup_location ASC").
+		Order("order_fulfillments.created_at ASC").Order("order_fulfillments.id ASC").
+		Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,141 @@
+package fulfillment
+
+import (
+	"context"
+	"time"
+	"wallet-point/internal/marketplace"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// Service is the work queue of the store staff: the physical orders paid but
+// not yet handed over
+type Service struct {
+	repo        *Repository
+	db          *gorm.DB
+	marketplace *marketplace.MarketplaceService
+}
+
+func NewService(repo *Repository, db *gorm.DB, marketplaceService *marketplace.MarketplaceService) *Service {
+	s := &Service{repo: repo, db: db, marketplace: marketplaceService}
+	marketplaceService.RegisterFulfiller(marketplace.ProductPhysical, s)
+	return s
+}
+
+// Fulfill puts a physical order on the queue of its product's pickup location,
+// in the purchase transaction
+func (s *Service) Fulfill(tx *gorm.DB, userID uint, product *marketplace.Product, order *marketplace.MarketplaceTransaction) error {
+	location := product.PickupLocation
+	if location == "" {
+		location = MainStore
+	}
+	return s.repo.Create(tx, &Fulfillment{
+		TenantID:       product.TenantID,
+		OrderID:        order.ID,
+		ProductID:      product.ID,
+		ProductName:    product.Name,
+		Quantity:       order.Quantity,
+		UserID:         userID,
+		PickupLocation: location,
+		Status:         StatusPending,
+	})
+}
+
+// GetQueue returns the open orders grouped by pickup location
+func (s *Service) GetQueue(ctx context.Context, params QueueParams) ([]QueueGroup, error) {
+	items, err := s.repo.FindOpen(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []QueueGroup{}
+	for _, item := range items {
+		if len(groups) == 0 || groups[len(groups)-1].PickupLocation != item.PickupLocation {
+			groups = append(groups, QueueGroup{PickupLocation: item.PickupLocation, Orders: []QueueItem{}})
+		}
+		group := &groups[len(groups)-1]
+		group.Orders = append(group.Orders, item)
+		if item.Status == StatusClaimed {
+			group.Claimed++
+		} else {
+			group.Pending++
+		}
+	}
+	return groups, nil
+}
+
+// Claim takes a pending order for staffID to prepare. Claiming an order one
+// already holds is a no-op, so a retried request succeeds.
+func (s *Service) Claim(ctx context.Context, id, staffID uint) (*Fulfillment, error) {
+	return s.transition(ctx, id, func(tx *gorm.DB, f *Fulfillment) error {
+		switch f.Status {
+		case StatusCompleted:
+			return ErrAlreadyCompleted
+		case StatusClaimed:
+			if f.ClaimedBy == nil || *f.ClaimedBy != staffID {
+				return ErrAlreadyClaimed
+			}
+			return nil
+		}
+		now := time.Now()
+		f.Status, f.ClaimedBy, f.ClaimedAt = StatusClaimed, &staffID, &now
+		return s.repo.Update(tx, f, map[string]interface{}{"status": f.Status, "claimed_by": staffID, "claimed_at": now})
+	})
+}
+
+// Release puts an order staffID claimed back on the queue
+func (s *Service) Release(ctx context.Context, id, staffID uint) (*Fulfillment, error) {
+	return s.transition(ctx, id, func(tx *gorm.DB, f *Fulfillment) error {
+		if err := checkClaimer(f, staffID); err != nil {
+			return err
+		}
+		f.Status, f.ClaimedBy, f.ClaimedAt = StatusPending, nil, nil
+		return s.repo.Update(tx, f, map[string]interface{}{"status": f.Status, "claimed_by": nil, "claimed_at": nil})
+	})
+}
+
+// Complete records that the student collected an order staffID claimed
+func (s *Service) Complete(ctx context.Context, id, staffID uint) (*Fulfillment, error) {
+	return s.transition(ctx, id, func(tx *gorm.DB, f *Fulfillment) error {
+		if err := checkClaimer(f, staffID); err != nil {
+			return err
+		}
+		now := time.Now()
+		f.Status, f.CompletedBy, f.CompletedAt = StatusCompleted, &staffID, &now
+		if err := s.repo.Update(tx, f, map[string]interface{}{"status": f.Status, "completed_by": staffID, "completed_at": now}); err != nil {
+			return err
+		}
+		return s.marketplace.ItemDelivered(tx, f.OrderID)
+	})
+}
+
+// checkClaimer allows only the staff member holding a claimed order to release or complete it
+func checkClaimer(f *Fulfillment, staffID uint) error {
+	switch {
+	case f.Status == StatusCompleted:
+		return ErrAlreadyCompleted
+	case f.Status != StatusClaimed:
+		return ErrNotClaimed
+	case f.ClaimedBy == nil || *f.ClaimedBy != staffID:
+		return ErrAlreadyClaimed
+	}
+	return nil
+}
+
+// transition applies change to a locked fulfillment of the campus of ctx
+func (s *Service) transition(ctx context.Context, id uint, change func(tx *gorm.DB, f *Fulfillment) error) (*Fulfillment, error) {
+	var fulfillment *Fulfillment
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		f, err := s.repo.Lock(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := change(tx, f); err != nil {
+			return err
+		}
+		fulfillment = f
+		return nil
+	})
+	return fulfillment, err
+}
@@ -0,0 +1,10 @@
+package graph
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrInvalidQuantity = utils.NewAppError("GRAPHQL_INVALID_QUANTITY", http.StatusBadRequest, "quantity must be greater than 0")
+)
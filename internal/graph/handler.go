@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// MaxQueryComplexity caps the fields a single query may resolve, so one request
+// cannot walk every product and wallet transaction at once
+const MaxQueryComplexity = 500
+
+type ginContextKey struct{}
+
+type GraphQLHandler struct {
+	server             *handler.Server
+	marketplaceService *marketplace.MarketplaceService
+}
+
+func NewGraphQLHandler(marketplaceService *marketplace.MarketplaceService, walletService *wallet.WalletService) *GraphQLHandler {
+	resolver := &Resolver{
+		marketplaceService: marketplaceService,
+		walletService:      walletService,
+	}
+
+	server := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.Use(extension.FixedComplexityLimit(MaxQueryComplexity))
+	if gin.IsDebugging() {
+		server.Use(extension.Introspection{})
+	}
+	server.SetErrorPresenter(presentError)
+	server.SetRecoverFunc(func(ctx context.Context, recovered any) error {
+		return fmt.Errorf("panic: %v", recovered)
+	})
+
+	return &GraphQLHandler{server: server, marketplaceService: marketplaceService}
+}
+
+// Serve handles GraphQL queries and mutations of the authenticated student
+// @Summary GraphQL endpoint
+// @Description Query products, cart, orders and wallet, or change the cart, in one round trip. See internal/graph/schema.graphqls for the schema.
+// @Tags Mahasiswa - GraphQL
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body object true "GraphQL request: query, operationName and variables"
+// @Success 200 {object} object "GraphQL response: data and errors"
+// @Failure 422 {object} object "Query failed to parse or validate"
+// @Router /mahasiswa/graphql [post]
+func (h *GraphQLHandler) Serve(c *gin.Context) {
+	ctx := context.WithValue(c.Request.Context(), ginContextKey{}, c)
+	ctx = withLoaders(ctx, newLoaders(h.marketplaceService))
+	h.server.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
+
+func ginContext(ctx context.Context) *gin.Context {
+	return ctx.Value(ginContextKey{}).(*gin.Context)
+}
+
+func currentUserID(ctx context.Context) uint {
+	return ginContext(ctx).GetUint("user_id")
+}
+
+// presentError gives service errors the same code and translated message as the
+// REST API (under extensions.code) and hides unexpected errors behind a generic one
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	c := ginContext(ctx)
+
+	if appErr, ok := utils.AsAppError(err); ok {
+		gqlErr.Message = utils.TranslateError(c, err)
+		gqlErr.Extensions = map[string]interface{}{"code": appErr.Code}
+		return gqlErr
+	}
+	if gqlErr.Err == nil {
+		// Parse and validation errors raised by gqlgen itself
+		return gqlErr
+	}
+
+	slog.ErrorContext(ctx, "graphql: resolver failed", "path", gqlErr.Path.String(), "error", err)
+	gqlErr.Message = utils.Translate(c, "Internal server error")
+	gqlErr.Extensions = map[string]interface{}{"code": utils.CodeInternal}
+	return gqlErr
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"time"
+	"wallet-point/internal/marketplace"
+
+	"github.com/vikstrous/dataloadgen"
+)
+
+type loadersKey struct{}
+
+// loaderWait is how long a loader collects keys before it runs the batch query
+const loaderWait = 2 * time.Millisecond
+
+// Loaders batch the per-object lookups of one request (the product of each order, the
+// categories of each product) into a single query per field.
+type Loaders struct {
+	products   *dataloadgen.Loader[uint, *marketplace.Product]
+	categories *dataloadgen.Loader[uint, []marketplace.Category]
+}
+
+// newLoaders creates the loaders of one request; they cache results, so they must
+// never be shared across requests
+func newLoaders(marketplaceService *marketplace.MarketplaceService) *Loaders {
+	return &Loaders{
+		products: dataloadgen.NewMappedLoader(func(ctx context.Context, ids []uint) (map[uint]*marketplace.Product, error) {
+			products, err := marketplaceService.GetProductsByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*marketplace.Product, len(products))
+			for i := range products {
+				byID[products[i].ID] = &products[i]
+			}
+			return byID, nil
+		}, dataloadgen.WithWait(loaderWait)),
+		categories: dataloadgen.NewLoader(func(ctx context.Context, ids []uint) ([][]marketplace.Category, []error) {
+			byProduct, err := marketplaceService.GetProductCategories(ctx, ids)
+			categories := make([][]marketplace.Category, len(ids))
+			if err != nil {
+				errs := make([]error, len(ids))
+				for i := range errs {
+					errs[i] = err
+				}
+				return categories, errs
+			}
+			for i, id := range ids {
+				categories[i] = byProduct[id]
+			}
+			return categories, nil
+		}, dataloadgen.WithWait(loaderWait)),
+	}
+}
+
+func withLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+func loadersFor(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
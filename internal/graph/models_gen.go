@@ -0,0 +1,24 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"wallet-point/internal/marketplace"
+	"wallet-point/utils"
+)
+
+type Mutation struct {
+}
+
+type OrderPage struct {
+	Orders   []marketplace.MarketplaceTransaction `json:"orders"`
+	PageInfo *utils.Pagination                    `json:"pageInfo"`
+}
+
+type ProductPage struct {
+	Products []marketplace.Product `json:"products"`
+	PageInfo *utils.Pagination     `json:"pageInfo"`
+}
+
+type Query struct {
+}
@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/wallet"
+)
+
+// Resolver holds the services the GraphQL resolvers delegate to; business
+// rules stay in the services so REST and GraphQL behave the same.
+type Resolver struct {
+	marketplaceService *marketplace.MarketplaceService
+	walletService      *wallet.WalletService
+}
+
+// maxPageSize caps the limit argument of list fields
+const maxPageSize = 100
+
+func clampLimit(limit int) int {
+	if limit < 1 {
+		return 20
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,168 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"github.com/vikstrous/dataloadgen"
+)
+
+// Subtotal is the resolver for the subtotal field.
+func (r *cartItemResolver) Subtotal(ctx context.Context, obj *marketplace.CartItem) (int, error) {
+	return obj.Product.Price * obj.Quantity, nil
+}
+
+// AddToCart is the resolver for the addToCart field.
+func (r *mutationResolver) AddToCart(ctx context.Context, productID uint, quantity int) (*marketplace.CartResponse, error) {
+	if quantity < 1 {
+		return nil, ErrInvalidQuantity
+	}
+	userID := currentUserID(ctx)
+	if err := r.marketplaceService.AddToCart(ctx, userID, marketplace.AddToCartRequest{ProductID: productID, Quantity: quantity}); err != nil {
+		return nil, err
+	}
+	return r.marketplaceService.GetCart(ctx, userID)
+}
+
+// UpdateCartItem is the resolver for the updateCartItem field.
+func (r *mutationResolver) UpdateCartItem(ctx context.Context, itemID uint, quantity int) (*marketplace.CartResponse, error) {
+	if quantity < 1 {
+		return nil, ErrInvalidQuantity
+	}
+	userID := currentUserID(ctx)
+	if err := r.marketplaceService.UpdateCartItem(userID, itemID, quantity); err != nil {
+		return nil, err
+	}
+	return r.marketplaceService.GetCart(ctx, userID)
+}
+
+// RemoveFromCart is the resolver for the removeFromCart field.
+func (r *mutationResolver) RemoveFromCart(ctx context.Context, itemID uint) (*marketplace.CartResponse, error) {
+	userID := currentUserID(ctx)
+	if err := r.marketplaceService.RemoveFromCart(userID, itemID); err != nil {
+		return nil, err
+	}
+	return r.marketplaceService.GetCart(ctx, userID)
+}
+
+// Checkout is the resolver for the checkout field.
+func (r *mutationResolver) Checkout(ctx context.Context, pin string) (*wallet.Wallet, error) {
+	userID := currentUserID(ctx)
+	req := marketplace.CartCheckoutRequest{PIN: pin, PaymentMethod: "wallet"}
+	if err := r.marketplaceService.Checkout(ctx, userID, req); err != nil {
+		return nil, err
+	}
+	return r.walletService.GetWalletByUserID(userID)
+}
+
+// Product is the resolver for the product field.
+func (r *orderResolver) Product(ctx context.Context, obj *marketplace.MarketplaceTransaction) (*marketplace.Product, error) {
+	product, err := loadersFor(ctx).products.Load(ctx, obj.ProductID)
+	if errors.Is(err, dataloadgen.ErrNotFound) {
+		return nil, nil
+	}
+	return product, err
+}
+
+// Categories is the resolver for the categories field.
+func (r *productResolver) Categories(ctx context.Context, obj *marketplace.Product) ([]marketplace.Category, error) {
+	if obj.Categories != nil {
+		return obj.Categories, nil
+	}
+	return loadersFor(ctx).categories.Load(ctx, obj.ID)
+}
+
+// Products is the resolver for the products field.
+func (r *queryResolver) Products(ctx context.Context, page int, limit int) (*ProductPage, error) {
+	result, err := r.marketplaceService.GetAllProducts(ctx, marketplace.ProductListParams{
+		Status: "active",
+		Page:   page,
+		Limit:  clampLimit(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ProductPage{Products: result.Products, PageInfo: &result.Pagination}, nil
+}
+
+// Product is the resolver for the product field.
+func (r *queryResolver) Product(ctx context.Context, id uint) (*marketplace.Product, error) {
+	product, err := r.marketplaceService.GetProductByID(ctx, id)
+	if errors.Is(err, marketplace.ErrProductNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if product.Status != "active" {
+		return nil, nil
+	}
+	return product, nil
+}
+
+// Cart is the resolver for the cart field.
+func (r *queryResolver) Cart(ctx context.Context) (*marketplace.CartResponse, error) {
+	return r.marketplaceService.GetCart(ctx, currentUserID(ctx))
+}
+
+// Orders is the resolver for the orders field.
+func (r *queryResolver) Orders(ctx context.Context, page int, limit int) (*OrderPage, error) {
+	w, err := r.walletService.GetWalletByUserID(currentUserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	limit = clampLimit(limit)
+	orders, total, err := r.marketplaceService.GetWalletTransactions(w.ID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	pageInfo := utils.NewPagination(page, limit, total, "")
+	return &OrderPage{Orders: orders, PageInfo: &pageInfo}, nil
+}
+
+// Wallet is the resolver for the wallet field.
+func (r *queryResolver) Wallet(ctx context.Context) (*wallet.Wallet, error) {
+	return r.walletService.GetWalletByUserID(currentUserID(ctx))
+}
+
+// Transactions is the resolver for the transactions field.
+func (r *walletResolver) Transactions(ctx context.Context, obj *wallet.Wallet, limit int) ([]wallet.WalletTransaction, error) {
+	return r.walletService.GetWalletTransactions(obj.ID, clampLimit(limit), nil)
+}
+
+// CartItem returns CartItemResolver implementation.
+func (r *Resolver) CartItem() CartItemResolver { return &cartItemResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Order returns OrderResolver implementation.
+func (r *Resolver) Order() OrderResolver { return &orderResolver{r} }
+
+// Product returns ProductResolver implementation.
+func (r *Resolver) Product() ProductResolver { return &productResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Wallet returns WalletResolver implementation.
+func (r *Resolver) Wallet() WalletResolver { return &walletResolver{r} }
+
+type cartItemResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type orderResolver struct{ *Resolver }
+type productResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type walletResolver struct{ *Resolver }
@@ -0,0 +1,32 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	service *HealthService
+}
+
+func NewHealthHandler(service *HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+// Healthz handles the liveness probe (GET /healthz)
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Liveness())
+}
+
+// Readyz handles the readiness probe (GET /readyz), answering 503 when any
+// registered dependency is down
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.service.Readiness(c.Request.Context())
+
+	statusCode := http.StatusOK
+	if report.Status != StatusUp {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.JSON(statusCode, report)
+}
@@ -0,0 +1,20 @@
+package health
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// ComponentStatus is the result of one dependency check
+type ComponentStatus struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the body of /healthz and /readyz
+type Report struct {
+	Status     string                     `json:"status"`
+	Uptime     string                     `json:"uptime"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
@@ -0,0 +1,143 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// CheckFunc reports whether a dependency is usable; it must honour ctx's deadline
+type CheckFunc func(ctx context.Context) error
+
+type registeredCheck struct {
+	check    CheckFunc
+	optional bool
+}
+
+type HealthService struct {
+	mu        sync.RWMutex
+	checks    map[string]registeredCheck
+	timeout   time.Duration
+	startedAt time.Time
+}
+
+func NewHealthService(timeout time.Duration) *HealthService {
+	return &HealthService{
+		checks:    make(map[string]registeredCheck),
+		timeout:   timeout,
+		startedAt: time.Now(),
+	}
+}
+
+// Register adds a readiness check; components register themselves as they are wired up
+func (s *HealthService) Register(name string, check CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[name] = registeredCheck{check: check}
+}
+
+// RegisterOptional adds a check that is reported but does not make the instance
+// not-ready, for dependencies the service degrades gracefully without (e.g. the cache)
+func (s *HealthService) RegisterOptional(name string, check CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[name] = registeredCheck{check: check, optional: true}
+}
+
+// Liveness only reports that the process is serving requests. It deliberately
+// skips dependencies so an outage of the database does not get pods restarted.
+func (s *HealthService) Liveness() Report {
+	return Report{Status: StatusUp, Uptime: s.uptime()}
+}
+
+// Readiness runs every registered check concurrently and is down if any non-optional one fails
+func (s *HealthService) Readiness(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	s.mu.RLock()
+	checks := make(map[string]registeredCheck, len(s.checks))
+	for name, check := range s.checks {
+		checks[name] = check
+	}
+	s.mu.RUnlock()
+
+	report := Report{
+		Status:     StatusUp,
+		Uptime:     s.uptime(),
+		Components: make(map[string]ComponentStatus, len(checks)),
+	}
+
+	// A draining instance reports not-ready so it is taken out of rotation before it stops listening
+	if utils.ShuttingDown() {
+		report.Status = StatusDown
+		report.Components["server"] = ComponentStatus{Status: StatusDown, Error: "shutting down"}
+		return report
+	}
+
+	var wg sync.WaitGroup
+	var resultMu sync.Mutex
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check registeredCheck) {
+			defer wg.Done()
+			status := runCheck(ctx, check.check)
+
+			resultMu.Lock()
+			defer resultMu.Unlock()
+			report.Components[name] = status
+			if status.Status == StatusDown && !check.optional {
+				report.Status = StatusDown
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (s *HealthService) uptime() string {
+	return time.Since(s.startedAt).Round(time.Second).String()
+}
+
+func runCheck(ctx context.Context, check CheckFunc) ComponentStatus {
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() { errCh <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	status := ComponentStatus{Status: StatusUp, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// DatabaseCheck pings the database connection pool
+func DatabaseCheck(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// ReplicaCheck runs a query on the read replica; replication problems only degrade reports
+func ReplicaCheck(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		var one int
+		return utils.ReadReplica(db).WithContext(ctx).Raw("SELECT 1").Scan(&one).Error
+	}
+}
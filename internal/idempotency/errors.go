@@ -0,0 +1,12 @@
+package idempotency
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrInvalidKey = utils.NewAppError("IDEMPOTENCY_KEY_INVALID", http.StatusBadRequest, "Idempotency-Key must be 1 to 255 characters")
+	ErrKeyReused  = utils.NewAppError("IDEMPOTENCY_KEY_REUSED", http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+	ErrInProgress = utils.NewAppError("IDEMPOTENCY_REQUEST_IN_PROGRESS", http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+)
@@ -0,0 +1,32 @@
+package idempotency
+
+import (
+	"time"
+)
+
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+)
+
+// Record is one Idempotency-Key used by a user. The first request stores its hash and,
+// once handled, its response; retries with the same key get that response replayed.
+type Record struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	UserID         uint      `json:"user_id" gorm:"uniqueIndex:idx_idempotency_user_key;not null"`
+	Key            string    `json:"key" gorm:"size:255;uniqueIndex:idx_idempotency_user_key;not null"`
+	Method         string    `json:"method" gorm:"size:10;not null"`
+	Path           string    `json:"path" gorm:"size:255;not null"`
+	RequestHash    string    `json:"request_hash" gorm:"size:64;not null"`
+	Status         string    `json:"status" gorm:"type:enum('processing','completed');default:'processing';not null"`
+	ResponseStatus int       `json:"response_status"`
+	ResponseBody   []byte    `json:"-" gorm:"type:mediumblob"`
+	ContentType    string    `json:"content_type" gorm:"size:100"`
+	ExpiresAt      time.Time `json:"expires_at" gorm:"index;not null"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
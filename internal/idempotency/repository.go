@@ -0,0 +1,57 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Claim inserts record unless the user already used its key; it reports whether this call inserted it
+func (r *Repository) Claim(record *Record) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *Repository) Find(userID uint, key string) (*Record, error) {
+	var record Record
+	err := r.db.Where("user_id = ? AND `key` = ?", userID, key).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *Repository) Complete(id uint, status int, body []byte, contentType string) error {
+	return r.db.Model(&Record{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          StatusCompleted,
+		"response_status": status,
+		"response_body":   body,
+		"content_type":    contentType,
+	}).Error
+}
+
+func (r *Repository) Delete(id uint) error {
+	return r.db.Delete(&Record{}, id).Error
+}
+
+// DeleteExpired removes keys past their retention and returns how many were removed
+func (r *Repository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", now).Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,111 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"time"
+	"wallet-point/utils"
+)
+
+type Service struct {
+	repo *Repository
+	ttl  time.Duration
+}
+
+// NewService keeps each key for ttl; a retry after that is treated as a new request
+func NewService(repo *Repository, ttl time.Duration) *Service {
+	return &Service{repo: repo, ttl: ttl}
+}
+
+// Hash fingerprints a request so a key reused for a different request is rejected
+func Hash(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Begin claims key for the request. It returns the stored record with replay set
+// when the same request already completed, ErrInProgress while it is still running
+// and ErrKeyReused when the key belongs to a different request.
+func (s *Service) Begin(userID uint, key, method, path, requestHash string) (record *Record, replay bool, err error) {
+	if key == "" || len(key) > 255 {
+		return nil, false, ErrInvalidKey
+	}
+
+	now := time.Now()
+	record = &Record{
+		UserID:      userID,
+		Key:         key,
+		Method:      method,
+		Path:        path,
+		RequestHash: requestHash,
+		Status:      StatusProcessing,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := s.repo.Claim(record)
+		if err != nil {
+			return nil, false, err
+		}
+		if claimed {
+			return record, false, nil
+		}
+
+		existing, err := s.repo.Find(userID, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing == nil {
+			continue // Released between the insert and the lookup
+		}
+		if existing.ExpiresAt.Before(now) {
+			if err := s.repo.Delete(existing.ID); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, false, ErrKeyReused
+		}
+		if existing.Status != StatusCompleted {
+			return nil, false, ErrInProgress
+		}
+		return existing, true, nil
+	}
+
+	return nil, false, ErrInProgress
+}
+
+// Complete stores the response so retries replay it
+func (s *Service) Complete(record *Record, status int, body []byte, contentType string) {
+	if err := s.repo.Complete(record.ID, status, body, contentType); err != nil {
+		slog.Error("idempotency: store response failed", "key", record.Key, "error", err)
+	}
+}
+
+// Release forgets the key so the request can be retried (server errors are not replayed)
+func (s *Service) Release(record *Record) {
+	if err := s.repo.Delete(record.ID); err != nil {
+		slog.Error("idempotency: release key failed", "key", record.Key, "error", err)
+	}
+}
+
+// StartCleanup periodically removes expired keys in the background
+func (s *Service) StartCleanup(interval time.Duration) {
+	utils.Go(func() {
+		for {
+			if removed, err := s.repo.DeleteExpired(time.Now()); err != nil {
+				slog.Error("idempotency: cleanup failed", "error", err)
+			} else if removed > 0 {
+				slog.Info("idempotency: removed expired keys", "count", removed)
+			}
+			if !utils.Sleep(interval) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"errors"
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrJobNotFound    = utils.NewAppError("JOB_NOT_FOUND", http.StatusNotFound, "job not found")
+	ErrJobNotDead     = utils.NewAppError("JOB_NOT_DEAD", http.StatusConflict, "only dead jobs can be retried")
+	ErrJobNoFile      = utils.NewAppError("JOB_NO_FILE", http.StatusNotFound, "job has no file to download")
+	ErrUnknownJobType = utils.NewAppError("JOB_UNKNOWN_TYPE", http.StatusBadRequest, "unknown job type")
+)
+
+// PermanentError marks a job failure that retrying will not fix (e.g. invalid payload)
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so the job is dead-lettered instead of retried
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err was marked as a permanent job failure
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
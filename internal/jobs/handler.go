@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/storage"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	queue        *Queue
+	files        *storage.Handler
+	auditService audit.Logger
+}
+
+func NewJobHandler(queue *Queue, files *storage.Handler, auditService audit.Logger) *JobHandler {
+	return &JobHandler{queue: queue, files: files, auditService: auditService}
+}
+
+// GetAll handles listing background jobs (Admin)
+// @Summary Get background jobs
+// @Tags Admin - Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param queue query string false "Filter by queue" Enums(default, emails, exports, webhooks, reconciliation, events)
+// @Param status query string false "Filter by status" Enums(pending, running, succeeded, dead)
+// @Param type query string false "Filter by job type"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=JobListResponse}
+// @Router /admin/jobs [get]
+func (h *JobHandler) GetAll(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "job_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.queue.GetJobs(JobListParams{
+		Queue:  c.Query("queue"),
+		Status: status,
+		Type:   c.Query("type"),
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve jobs", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Jobs retrieved successfully", "jobs", response.Jobs, response.Pagination, nil)
+}
+
+// GetByID handles getting the status of a background job
+// @Summary Get job status
+// @Description Get the status, attempts and result of a background job. Users only see the jobs they started; super admins see every job.
+// @Tags Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} utils.Response{data=Job}
+// @Failure 404 {object} utils.Response
+// @Router /jobs/{id} [get]
+// @Router /admin/jobs/{id} [get]
+func (h *JobHandler) GetByID(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid job ID", nil)
+		return
+	}
+
+	job, err := h.queue.GetJob(uint(jobID), c.GetUint("user_id"), c.GetString("role") == "superadmin")
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Job retrieved successfully", job)
+}
+
+// Download handles downloading the file produced by a finished job (e.g. an export)
+// @Summary Download job result
+// @Description With S3 or GCS storage the reply redirects to a short-lived signed link to the file.
+// @Tags Jobs
+// @Security BearerAuth
+// @Produce application/octet-stream
+// @Param id path int true "Job ID"
+// @Success 200 {file} file
+// @Success 307 "Redirect to a signed link"
+// @Failure 404 {object} utils.Response
+// @Router /jobs/{id}/download [get]
+func (h *JobHandler) Download(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid job ID", nil)
+		return
+	}
+
+	job, err := h.queue.GetJobFile(c.Request.Context(), uint(jobID), c.GetUint("user_id"), c.GetString("role") == "superadmin")
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.files.Download(c, job.ResultFile, path.Base(job.ResultFile))
+}
+
+// Retry handles requeueing a dead job (Admin)
+// @Summary Retry dead job
+// @Tags Admin - Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} utils.Response{data=Job}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/jobs/{id}/retry [post]
+func (h *JobHandler) Retry(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid job ID", nil)
+		return
+	}
+
+	job, err := h.queue.Retry(uint(jobID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Job requeued", job)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "RETRY_JOB",
+		Entity:    "JOB",
+		EntityID:  job.ID,
+		Details:   fmt.Sprintf("Admin requeued dead %s job #%d", job.Type, job.ID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// Queues have their own worker pool so a burst of exports cannot delay emails
+const (
+	QueueDefault        = "default"
+	QueueEmails         = "emails"
+	QueueExports        = "exports"
+	QueueWebhooks       = "webhooks"
+	QueueReconciliation = "reconciliation"
+	QueueEvents         = "events"
+)
+
+// Job is one unit of background work. Handlers are looked up by Type; Result holds
+// what the handler returned (JSON) and ResultFile the storage key of a generated
+// file, if any.
+type Job struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	Queue       string          `json:"queue" gorm:"size:50;not null;index:idx_jobs_claim,priority:1"`
+	Type        string          `json:"type" gorm:"size:100;not null;index"`
+	Payload     json.RawMessage `json:"payload" gorm:"type:text" swaggertype:"object"`
+	Status      string          `json:"status" gorm:"type:enum('pending','running','succeeded','dead');default:'pending';not null;index:idx_jobs_claim,priority:2"`
+	Attempts    int             `json:"attempts" gorm:"default:0;not null"`
+	MaxAttempts int             `json:"max_attempts" gorm:"default:5;not null"`
+	RunAt       time.Time       `json:"run_at" gorm:"not null;index:idx_jobs_claim,priority:3"`
+	LockedAt    *time.Time      `json:"locked_at"`
+	LastError   string          `json:"last_error,omitempty" gorm:"type:text"`
+	Result      json.RawMessage `json:"result,omitempty" gorm:"type:mediumtext" swaggertype:"object"`
+	ResultFile  string          `json:"-" gorm:"size:500"`
+	CreatedBy   *uint           `json:"created_by" gorm:"index"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// HasFile reports whether the job produced a downloadable file
+func (j *Job) HasFile() bool {
+	return j.ResultFile != ""
+}
+
+type JobListParams struct {
+	Queue  string
+	Status string
+	Type   string
+	Page   int
+	Limit  int
+}
+
+type JobListResponse struct {
+	Jobs []Job `json:"jobs"`
+	utils.Pagination
+}
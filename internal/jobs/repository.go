@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(tx *gorm.DB, job *Job) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(job).Error
+}
+
+func (r *Repository) FindByID(id uint) (*Job, error) {
+	var job Job
+	err := r.db.First(&job, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindAll lists jobs newest first with filters and pagination
+func (r *Repository) FindAll(params JobListParams) ([]Job, int64, error) {
+	var jobs []Job
+	var total int64
+
+	query := r.db.Model(&Job{})
+	if params.Queue != "" {
+		query = query.Where("queue = ?", params.Queue)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.Type != "" {
+		query = query.Where("type = ?", params.Type)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("id DESC").Limit(params.Limit).Offset(offset).Find(&jobs).Error
+	return jobs, total, err
+}
+
+// Claim locks the next due job of queue and marks it running. SKIP LOCKED lets
+// several workers and instances poll the same queue without blocking each other.
+func (r *Repository) Claim(queue string, now time.Time) (*Job, error) {
+	var job Job
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("queue = ? AND status = ? AND run_at <= ?", queue, StatusPending, now).
+			Order("run_at ASC, id ASC").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		job.Status = StatusRunning
+		job.Attempts++
+		job.LockedAt = &now
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":    StatusRunning,
+			"attempts":  job.Attempts,
+			"locked_at": now,
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *Repository) Update(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&Job{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ResetStuck returns running jobs locked before cutoff (their worker died) to the queue
+func (r *Repository) ResetStuck(cutoff time.Time) (int64, error) {
+	result := r.db.Model(&Job{}).
+		Where("status = ? AND locked_at < ?", StatusRunning, cutoff).
+		Updates(map[string]interface{}{"status": StatusPending, "locked_at": nil, "run_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
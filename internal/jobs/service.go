@@ -0,0 +1,350 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+	"time"
+	"wallet-point/internal/alert"
+	"wallet-point/internal/resilience"
+	"wallet-point/internal/storage"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxAttempts = 5
+	retryBaseDelay     = 30 * time.Second
+	retryMaxDelay      = time.Hour
+)
+
+// HandlerFunc runs a job; the returned result is stored as JSON on the job
+type HandlerFunc func(ctx context.Context, job *Job) (interface{}, error)
+
+type registration struct {
+	queue   string
+	handler HandlerFunc
+}
+
+type Queue struct {
+	repo         *Repository
+	handlers     map[string]registration
+	pollInterval time.Duration
+	jobTimeout   time.Duration
+	files        storage.Storage // where ResultFile lives
+	alerts       *alert.Service  // told of dead-lettered jobs, set with SetAlerts
+
+	// Worker heartbeat, read by the readiness probe
+	lastPollAt atomic.Int64
+}
+
+// NewQueue polls for due jobs every pollInterval and gives each run jobTimeout to finish
+func NewQueue(repo *Repository, pollInterval, jobTimeout time.Duration) *Queue {
+	return &Queue{
+		repo:         repo,
+		handlers:     make(map[string]registration),
+		pollInterval: pollInterval,
+		jobTimeout:   jobTimeout,
+	}
+}
+
+// SetAlerts reports dead-lettered jobs to the operations channel
+func (q *Queue) SetAlerts(alerts *alert.Service) {
+	q.alerts = alerts
+}
+
+// SetStorage sets the storage the files of finished jobs are kept in
+func (q *Queue) SetStorage(files storage.Storage) {
+	q.files = files
+}
+
+// Register routes jobs of jobType to handler on queue; call it before Start
+func (q *Queue) Register(jobType, queue string, handler HandlerFunc) {
+	q.handlers[jobType] = registration{queue: queue, handler: handler}
+}
+
+// Types lists the registered job types, sorted
+func (q *Queue) Types() []string {
+	types := make([]string, 0, len(q.handlers))
+	for jobType := range q.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// EnqueueOptions tune a single job
+type EnqueueOptions struct {
+	Delay       time.Duration
+	MaxAttempts int
+	CreatedBy   *uint
+}
+
+// Enqueue persists a job of a registered type; payload is stored as JSON
+func (q *Queue) Enqueue(jobType string, payload interface{}, options EnqueueOptions) (*Job, error) {
+	return q.EnqueueTx(nil, jobType, payload, options)
+}
+
+// EnqueueTx is Enqueue inside tx, so the job only exists if tx commits
+func (q *Queue) EnqueueTx(tx *gorm.DB, jobType string, payload interface{}, options EnqueueOptions) (*Job, error) {
+	registration, ok := q.handlers[jobType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJobType, jobType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAttempts := options.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	job := &Job{
+		Queue:       registration.queue,
+		Type:        jobType,
+		Payload:     data,
+		Status:      StatusPending,
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now().Add(options.Delay),
+		CreatedBy:   options.CreatedBy,
+	}
+	if err := q.repo.Create(tx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Start runs workers[queue] goroutines per queue plus a janitor that requeues jobs
+// whose worker died. Workers finish their current job on shutdown.
+func (q *Queue) Start(workers map[string]int) {
+	q.lastPollAt.Store(time.Now().UnixNano())
+
+	for queue, count := range workers {
+		for i := 0; i < count; i++ {
+			queue := queue
+			utils.Go(func() {
+				for {
+					for !utils.ShuttingDown() && q.work(queue) {
+					}
+					if !utils.Sleep(q.pollInterval) {
+						return
+					}
+				}
+			})
+		}
+		slog.Info("jobs: workers started", "queue", queue, "workers", count)
+	}
+
+	utils.Go(func() {
+		for {
+			q.lastPollAt.Store(time.Now().UnixNano())
+			if reset, err := q.repo.ResetStuck(time.Now().Add(-2 * q.jobTimeout)); err != nil {
+				slog.Error("jobs: reset stuck jobs failed", "error", err)
+			} else if reset > 0 {
+				slog.Warn("jobs: requeued stuck jobs", "count", reset)
+			}
+			if !utils.Sleep(q.pollInterval) {
+				return
+			}
+		}
+	})
+}
+
+// Check fails when the workers were not started or the janitor stopped running
+func (q *Queue) Check(ctx context.Context) error {
+	lastPoll := q.lastPollAt.Load()
+	if lastPoll == 0 {
+		return errors.New("job workers not started")
+	}
+	if since := time.Since(time.Unix(0, lastPoll)); since > 5*q.pollInterval+time.Minute {
+		return fmt.Errorf("job queue stalled, last poll %s ago", since.Round(time.Second))
+	}
+	return nil
+}
+
+// work claims and runs one job of queue; it reports whether there was one
+func (q *Queue) work(queue string) bool {
+	job, err := q.repo.Claim(queue, time.Now())
+	if err != nil {
+		slog.Error("jobs: claim failed", "queue", queue, "error", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	q.run(job)
+	return true
+}
+
+func (q *Queue) run(job *Job) {
+	log := slog.With("job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts)
+
+	registration, ok := q.handlers[job.Type]
+	if !ok {
+		q.fail(job, Permanent(fmt.Errorf("no handler registered for job type %s", job.Type)))
+		return
+	}
+	if job.Attempts > job.MaxAttempts {
+		q.fail(job, fmt.Errorf("gave up after %d attempts (worker stopped mid-run)", job.MaxAttempts))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), q.jobTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := q.runHandler(ctx, registration.handler, job)
+	if err != nil {
+		log.Warn("jobs: job failed", "duration", time.Since(start), "error", err)
+		q.fail(job, err)
+		return
+	}
+
+	updates := map[string]interface{}{
+		"status":      StatusSucceeded,
+		"locked_at":   nil,
+		"last_error":  "",
+		"finished_at": time.Now(),
+		"result_file": job.ResultFile,
+	}
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err == nil {
+			updates["result"] = data
+		}
+	}
+	if err := q.repo.Update(job.ID, updates); err != nil {
+		log.Error("jobs: store result failed", "error", err)
+	}
+	log.Info("jobs: job succeeded", "duration", time.Since(start))
+}
+
+// runHandler turns a handler panic into a job failure instead of crashing the worker
+func (q *Queue) runHandler(ctx context.Context, handler HandlerFunc, job *Job) (result interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// fail reschedules job with exponential backoff, or dead-letters it when the
+// error is permanent or its attempts are used up
+func (q *Queue) fail(job *Job, err error) {
+	updates := map[string]interface{}{
+		"locked_at":  nil,
+		"last_error": err.Error(),
+	}
+	if IsPermanent(err) || job.Attempts >= job.MaxAttempts {
+		updates["status"] = StatusDead
+		updates["finished_at"] = time.Now()
+		slog.Error("jobs: job dead-lettered", "job_id", job.ID, "job_type", job.Type, "attempts", job.Attempts, "error", err)
+		if q.alerts != nil {
+			q.alerts.Send(alert.Alert{
+				Key:      "jobs.dead." + job.Type,
+				Severity: alert.SeverityCritical,
+				Title:    "Background job failed",
+				Text:     err.Error(),
+				Fields: map[string]string{
+					"job":      fmt.Sprintf("#%d %s", job.ID, job.Type),
+					"queue":    job.Queue,
+					"attempts": fmt.Sprintf("%d/%d", job.Attempts, job.MaxAttempts),
+				},
+			})
+		}
+	} else {
+		updates["status"] = StatusPending
+		updates["run_at"] = time.Now().Add(retryDelay(job.Attempts))
+	}
+
+	if err := q.repo.Update(job.ID, updates); err != nil {
+		slog.Error("jobs: update failed job", "job_id", job.ID, "error", err)
+	}
+}
+
+// retryDelay grows exponentially from retryBaseDelay, is capped at retryMaxDelay and jittered
+func retryDelay(attempts int) time.Duration {
+	return resilience.Backoff(attempts, retryBaseDelay, retryMaxDelay)
+}
+
+// GetJobs lists jobs (Admin)
+func (q *Queue) GetJobs(params JobListParams) (*JobListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	jobs, total, err := q.repo.FindAll(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JobListResponse{
+		Jobs:       jobs,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// GetJob returns a job; non-admins only see the jobs they started
+func (q *Queue) GetJob(jobID, userID uint, isAdmin bool) (*Job, error) {
+	job, err := q.repo.FindByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && (job.CreatedBy == nil || *job.CreatedBy != userID) {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// GetJobFile returns a finished job whose file is still in storage
+func (q *Queue) GetJobFile(ctx context.Context, jobID, userID uint, isAdmin bool) (*Job, error) {
+	job, err := q.GetJob(jobID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != StatusSucceeded || !job.HasFile() {
+		return nil, ErrJobNoFile
+	}
+	if _, err := q.files.Stat(ctx, job.ResultFile); err != nil {
+		// Export files are deleted after EXPORT_RETENTION_DAYS
+		if errors.Is(err, storage.ErrFileNotFound) {
+			return nil, ErrJobNoFile
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// Retry puts a dead job back into its queue with a fresh set of attempts (Admin)
+func (q *Queue) Retry(jobID uint) (*Job, error) {
+	job, err := q.repo.FindByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != StatusDead {
+		return nil, ErrJobNotDead
+	}
+
+	if err := q.repo.Update(job.ID, map[string]interface{}{
+		"status":      StatusPending,
+		"attempts":    0,
+		"run_at":      time.Now(),
+		"finished_at": nil,
+	}); err != nil {
+		return nil, err
+	}
+	return q.repo.FindByID(job.ID)
+}
@@ -0,0 +1,19 @@
+package kiosk
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrInvalidAPIKey      = utils.NewAppError("KIOSK_INVALID_API_KEY", http.StatusUnauthorized, "kiosk API key is missing or invalid")
+	ErrDeviceMismatch     = utils.NewAppError("KIOSK_DEVICE_MISMATCH", http.StatusUnauthorized, "kiosk API key is not bound to this device")
+	ErrKioskDisabled      = utils.NewAppError("KIOSK_DISABLED", http.StatusForbidden, "kiosk has been disabled")
+	ErrKioskNotFound      = utils.NewAppError("KIOSK_NOT_FOUND", http.StatusNotFound, "kiosk not found")
+	ErrDeviceTaken        = utils.NewAppError("KIOSK_DEVICE_TAKEN", http.StatusConflict, "device is already registered as another kiosk")
+	ErrProductUnavailable = utils.NewAppError("KIOSK_PRODUCT_UNAVAILABLE", http.StatusBadRequest, "product is not active or not enough in stock")
+	ErrOrderNotFound      = utils.NewAppError("KIOSK_ORDER_NOT_FOUND", http.StatusNotFound, "kiosk order not found")
+	ErrOrderInvalid       = utils.NewAppError("KIOSK_ORDER_INVALID", http.StatusBadRequest, "kiosk order code is invalid, expired or already used")
+	ErrOrderClosed        = utils.NewAppError("KIOSK_ORDER_CLOSED", http.StatusConflict, "kiosk order is no longer pending")
+	ErrPriceChanged       = utils.NewAppError("KIOSK_PRICE_CHANGED", http.StatusConflict, "product price changed since the order was made, start a new order at the kiosk")
+)
@@ -0,0 +1,457 @@
+package kiosk
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader carries the API key of a kiosk
+	APIKeyHeader = "X-API-Key"
+	// DeviceIDHeader carries the ID of the device the kiosk runs on
+	DeviceIDHeader = "X-Device-ID"
+)
+
+// kioskKey is where RequireKiosk stores the authenticated kiosk
+const kioskKey = "kiosk"
+
+type KioskHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewKioskHandler(service *Service, auditService audit.Logger) *KioskHandler {
+	return &KioskHandler{service: service, auditService: auditService}
+}
+
+// RequireKiosk authenticates a kiosk by its X-API-Key and X-Device-ID and
+// scopes the request to the kiosk's campus
+func (h *KioskHandler) RequireKiosk() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kiosk, err := h.service.Authenticate(c.Request.Context(), c.GetHeader(APIKeyHeader), c.GetHeader(DeviceIDHeader))
+		if err != nil {
+			utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(kioskKey, kiosk)
+		c.Set("tenant_id", kiosk.TenantID)
+		c.Request = c.Request.WithContext(utils.WithTenant(c.Request.Context(), kiosk.TenantID))
+		c.Next()
+	}
+}
+
+func kioskOf(c *gin.Context) *Kiosk {
+	return c.MustGet(kioskKey).(*Kiosk)
+}
+
+// logActivity records an audit entry attributed to the kiosk's device
+func (h *KioskHandler) logActivity(c *gin.Context, kiosk *Kiosk, userID uint, action, entity string, entityID uint, details string) {
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		DeviceID:  kiosk.DeviceID,
+	})
+}
+
+// GetSelf handles a kiosk checking its registration
+// @Summary Get kiosk
+// @Description The calling kiosk's registration; a disabled kiosk gets 403 KIOSK_DISABLED here and everywhere else
+// @Tags Kiosk
+// @Produce json
+// @Param X-API-Key header string true "Kiosk API key"
+// @Param X-Device-ID header string true "Device ID the kiosk is registered for"
+// @Success 200 {object} utils.Response{data=Kiosk}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /kiosk/me [get]
+func (h *KioskHandler) GetSelf(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk retrieved successfully", kioskOf(c))
+}
+
+// GetProducts handles a kiosk browsing the catalog
+// @Summary List kiosk products
+// @Description Active products of the kiosk's campus
+// @Tags Kiosk
+// @Produce json
+// @Param X-API-Key header string true "Kiosk API key"
+// @Param X-Device-ID header string true "Device ID the kiosk is registered for"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=marketplace.ProductListResponse}
+// @Router /kiosk/products [get]
+func (h *KioskHandler) GetProducts(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetProducts(c.Request.Context(), page, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve products", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Products retrieved successfully", "products", response.Products, response.Pagination, response.Meta())
+}
+
+// GetProduct handles a kiosk showing one product
+// @Summary Get kiosk product
+// @Tags Kiosk
+// @Produce json
+// @Param X-API-Key header string true "Kiosk API key"
+// @Param X-Device-ID header string true "Device ID the kiosk is registered for"
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.Response{data=marketplace.Product}
+// @Failure 404 {object} utils.Response
+// @Router /kiosk/products/{id} [get]
+func (h *KioskHandler) GetProduct(c *gin.Context) {
+	productID, ok := idParam(c, "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	product, err := h.service.GetProduct(c.Request.Context(), productID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product retrieved successfully", product)
+}
+
+// CreateOrder handles a kiosk starting a purchase
+// @Summary Create kiosk order
+// @Description Prices the product and returns a code and QR image for the kiosk to show. The student scans it in the app and confirms with their PIN within KIOSK_ORDER_TTL_SECONDS; poll GET /kiosk/orders/{id} for the outcome.
+// @Tags Kiosk
+// @Accept json
+// @Produce json
+// @Param X-API-Key header string true "Kiosk API key"
+// @Param X-Device-ID header string true "Device ID the kiosk is registered for"
+// @Param request body CreateOrderRequest true "Product and quantity"
+// @Success 201 {object} utils.Response{data=OrderResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /kiosk/orders [post]
+func (h *KioskHandler) CreateOrder(c *gin.Context) {
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	kiosk := kioskOf(c)
+	order, err := h.service.CreateOrder(c.Request.Context(), kiosk, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Kiosk order created", order)
+
+	h.logActivity(c, kiosk, 0, "CREATE_KIOSK_ORDER", "KIOSK_ORDER", order.ID,
+		fmt.Sprintf("Kiosk %s started an order of %dx %s for %d points", kiosk.Name, order.Quantity, order.ProductName, order.Total))
+}
+
+// GetOrder handles a kiosk polling an order
+// @Summary Get kiosk order
+// @Tags Kiosk
+// @Produce json
+// @Param X-API-Key header string true "Kiosk API key"
+// @Param X-Device-ID header string true "Device ID the kiosk is registered for"
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.Response{data=Order}
+// @Failure 404 {object} utils.Response
+// @Router /kiosk/orders/{id} [get]
+func (h *KioskHandler) GetOrder(c *gin.Context) {
+	orderID, ok := idParam(c, "Invalid order ID")
+	if !ok {
+		return
+	}
+
+	order, err := h.service.GetOrder(c.Request.Context(), kioskOf(c), orderID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk order retrieved successfully", order)
+}
+
+// CancelOrder handles a kiosk abandoning an order nobody confirmed
+// @Summary Cancel kiosk order
+// @Tags Kiosk
+// @Produce json
+// @Param X-API-Key header string true "Kiosk API key"
+// @Param X-Device-ID header string true "Device ID the kiosk is registered for"
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.Response{data=Order}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /kiosk/orders/{id}/cancel [post]
+func (h *KioskHandler) CancelOrder(c *gin.Context) {
+	orderID, ok := idParam(c, "Invalid order ID")
+	if !ok {
+		return
+	}
+
+	kiosk := kioskOf(c)
+	order, err := h.service.CancelOrder(c.Request.Context(), kiosk, orderID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk order cancelled", order)
+
+	h.logActivity(c, kiosk, 0, "CANCEL_KIOSK_ORDER", "KIOSK_ORDER", order.ID,
+		fmt.Sprintf("Kiosk %s cancelled the order of %dx %s", kiosk.Name, order.Quantity, order.ProductName))
+}
+
+// PreviewOrder handles a student scanning a kiosk QR code
+// @Summary Preview kiosk order
+// @Description What a scanned kiosk code buys and where, to show before the student confirms it
+// @Tags Mahasiswa - Kiosk
+// @Security BearerAuth
+// @Produce json
+// @Param code path string true "Code from the kiosk QR code"
+// @Success 200 {object} utils.Response{data=OrderPreview}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/kiosk/orders/{code} [get]
+func (h *KioskHandler) PreviewOrder(c *gin.Context) {
+	preview, err := h.service.PreviewOrder(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk order retrieved successfully", preview)
+}
+
+// ConfirmOrder handles a student paying a kiosk order
+// @Summary Confirm kiosk order
+// @Description Buys the scanned kiosk order from the student's wallet after checking their PIN. A failed attempt leaves the order open until it expires.
+// @Tags Mahasiswa - Kiosk
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ConfirmOrderRequest true "Code and PIN"
+// @Success 200 {object} utils.Response{data=Order}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /mahasiswa/kiosk/orders/confirm [post]
+func (h *KioskHandler) ConfirmOrder(c *gin.Context) {
+	var req ConfirmOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	order, kiosk, err := h.service.ConfirmOrder(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk order paid", order)
+
+	h.logActivity(c, kiosk, userID, "CONFIRM_KIOSK_ORDER", "KIOSK_ORDER", order.ID,
+		fmt.Sprintf("Paid %d points for %dx %s at kiosk %s (%s)", order.Total, order.Quantity, order.ProductName, kiosk.Name, kiosk.Location))
+}
+
+// GetKiosks handles listing the kiosks (Admin)
+// @Summary List kiosks
+// @Tags Admin - Kiosk
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Kiosk}
+// @Router /admin/kiosks [get]
+func (h *KioskHandler) GetKiosks(c *gin.Context) {
+	kiosks, err := h.service.GetKiosks(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve kiosks", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosks retrieved successfully", kiosks)
+}
+
+// RegisterKiosk handles registering a kiosk device (Admin)
+// @Summary Register kiosk
+// @Description Binds a kiosk to its device ID. The response holds the API key; it is not shown again. The kiosk sends it as X-API-Key together with its X-Device-ID.
+// @Tags Admin - Kiosk
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RegisterKioskRequest true "Kiosk"
+// @Success 201 {object} utils.Response{data=KioskWithKey}
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/kiosks [post]
+func (h *KioskHandler) RegisterKiosk(c *gin.Context) {
+	var req RegisterKioskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	kiosk, err := h.service.RegisterKiosk(c.Request.Context(), req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Kiosk registered", kiosk)
+
+	h.logActivity(c, &kiosk.Kiosk, adminID, "REGISTER_KIOSK", "KIOSK", kiosk.ID,
+		fmt.Sprintf("Registered kiosk %s at %s for device %s (key %s...)", kiosk.Name, kiosk.Location, kiosk.DeviceID, kiosk.KeyPrefix))
+}
+
+// UpdateKiosk handles changing a kiosk (Admin)
+// @Summary Update kiosk
+// @Description Rename or move a kiosk; a new device_id rebinds its key to replacement hardware
+// @Tags Admin - Kiosk
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Kiosk ID"
+// @Param request body UpdateKioskRequest true "Fields to change"
+// @Success 200 {object} utils.Response{data=Kiosk}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/kiosks/{id} [put]
+func (h *KioskHandler) UpdateKiosk(c *gin.Context) {
+	kioskID, ok := idParam(c, "Invalid kiosk ID")
+	if !ok {
+		return
+	}
+	var req UpdateKioskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	kiosk, err := h.service.UpdateKiosk(c.Request.Context(), kioskID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk updated", kiosk)
+
+	h.logActivity(c, kiosk, c.GetUint("user_id"), "UPDATE_KIOSK", "KIOSK", kiosk.ID,
+		fmt.Sprintf("Updated kiosk %s: location=%s, device=%s", kiosk.Name, kiosk.Location, kiosk.DeviceID))
+}
+
+// RotateKey handles replacing a kiosk's API key (Admin)
+// @Summary Rotate kiosk API key
+// @Description The old key stops working immediately; the response holds the new one
+// @Tags Admin - Kiosk
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Kiosk ID"
+// @Success 200 {object} utils.Response{data=KioskWithKey}
+// @Failure 404 {object} utils.Response
+// @Router /admin/kiosks/{id}/rotate-key [post]
+func (h *KioskHandler) RotateKey(c *gin.Context) {
+	kioskID, ok := idParam(c, "Invalid kiosk ID")
+	if !ok {
+		return
+	}
+
+	kiosk, err := h.service.RotateKey(c.Request.Context(), kioskID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk API key rotated", kiosk)
+
+	h.logActivity(c, &kiosk.Kiosk, c.GetUint("user_id"), "ROTATE_KIOSK_KEY", "KIOSK", kiosk.ID,
+		fmt.Sprintf("Rotated the API key of kiosk %s (now %s...)", kiosk.Name, kiosk.KeyPrefix))
+}
+
+// DisableKiosk handles shutting a kiosk out remotely (Admin)
+// @Summary Disable kiosk
+// @Description The kiosk's requests are refused with 403 KIOSK_DISABLED from now on and its pending orders are cancelled
+// @Tags Admin - Kiosk
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Kiosk ID"
+// @Param request body DisableKioskRequest true "Reason"
+// @Success 200 {object} utils.Response{data=Kiosk}
+// @Failure 404 {object} utils.Response
+// @Router /admin/kiosks/{id}/disable [post]
+func (h *KioskHandler) DisableKiosk(c *gin.Context) {
+	kioskID, ok := idParam(c, "Invalid kiosk ID")
+	if !ok {
+		return
+	}
+	var req DisableKioskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	kiosk, err := h.service.DisableKiosk(c.Request.Context(), kioskID, req.Reason)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk disabled", kiosk)
+
+	h.logActivity(c, kiosk, c.GetUint("user_id"), "DISABLE_KIOSK", "KIOSK", kiosk.ID,
+		fmt.Sprintf("Disabled kiosk %s: %s", kiosk.Name, req.Reason))
+}
+
+// EnableKiosk handles letting a disabled kiosk back in (Admin)
+// @Summary Enable kiosk
+// @Tags Admin - Kiosk
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Kiosk ID"
+// @Success 200 {object} utils.Response{data=Kiosk}
+// @Failure 404 {object} utils.Response
+// @Router /admin/kiosks/{id}/enable [post]
+func (h *KioskHandler) EnableKiosk(c *gin.Context) {
+	kioskID, ok := idParam(c, "Invalid kiosk ID")
+	if !ok {
+		return
+	}
+
+	kiosk, err := h.service.EnableKiosk(c.Request.Context(), kioskID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Kiosk enabled", kiosk)
+
+	h.logActivity(c, kiosk, c.GetUint("user_id"), "ENABLE_KIOSK", "KIOSK", kiosk.ID,
+		fmt.Sprintf("Enabled kiosk %s", kiosk.Name))
+}
+
+func idParam(c *gin.Context, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, message, nil)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,111 @@
+package kiosk
+
+import "time"
+
+const (
+	OrderPending    = "pending"
+	OrderProcessing = "processing"
+	OrderCompleted  = "completed"
+	OrderCancelled  = "cancelled"
+	OrderExpired    = "expired"
+)
+
+// Kiosk is a self-service store screen. It authenticates with an API key of
+// which only the SHA-256 hash is stored, sent together with the ID of the
+// device it was registered for, so a key copied off the device is useless alone.
+type Kiosk struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	TenantID       uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	Name           string     `json:"name" gorm:"size:100;not null"`
+	DeviceID       string     `json:"device_id" gorm:"size:100;uniqueIndex;not null"`
+	Location       string     `json:"location" gorm:"size:255;not null"`
+	KeyPrefix      string     `json:"key_prefix" gorm:"size:20;not null"` // shown to tell keys apart
+	KeyHash        string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Active         bool       `json:"active" gorm:"default:true;not null"`
+	DisabledReason string     `json:"disabled_reason,omitempty" gorm:"size:255"`
+	LastSeenAt     *time.Time `json:"last_seen_at"`
+	CreatedBy      uint       `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Kiosk) TableName() string {
+	return "kiosks"
+}
+
+// Order is a purchase started at a kiosk. The kiosk shows Code as a QR code;
+// the student scans it in the app and confirms with their PIN, which buys the
+// product from their wallet.
+type Order struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TenantID    uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	KioskID     uint       `json:"kiosk_id" gorm:"not null;index"`
+	Code        string     `json:"code" gorm:"size:64;uniqueIndex;not null"`
+	ProductID   uint       `json:"product_id" gorm:"not null"`
+	ProductName string     `json:"product_name" gorm:"size:255;not null"`
+	Quantity    int        `json:"quantity" gorm:"not null"`
+	Total       int        `json:"total" gorm:"not null"`
+	Status      string     `json:"status" gorm:"type:enum('pending','processing','completed','cancelled','expired');default:'pending';not null;index"`
+	UserID      *uint      `json:"user_id"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Order) TableName() string {
+	return "kiosk_orders"
+}
+
+type RegisterKioskRequest struct {
+	Name     string `json:"name" binding:"required,max=100" example:"Kiosk Perpustakaan Pusat"`
+	DeviceID string `json:"device_id" binding:"required,max=100" example:"KSK-7F3A-22B1"`
+	Location string `json:"location" binding:"required,max=255" example:"Gedung Perpustakaan, Lantai 1"`
+}
+
+// UpdateKioskRequest renames or moves a kiosk; a new device_id rebinds its key
+// to replacement hardware
+type UpdateKioskRequest struct {
+	Name     *string `json:"name" binding:"omitempty,max=100"`
+	DeviceID *string `json:"device_id" binding:"omitempty,min=1,max=100"`
+	Location *string `json:"location" binding:"omitempty,max=255"`
+}
+
+type DisableKioskRequest struct {
+	Reason string `json:"reason" binding:"required,max=255" example:"Layar rusak"`
+}
+
+// KioskWithKey is returned when a kiosk is registered or its key rotated, the
+// only times the API key is shown
+type KioskWithKey struct {
+	Kiosk
+	APIKey string `json:"api_key"`
+}
+
+type CreateOrderRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"omitempty,gt=0,max=100"`
+}
+
+// OrderResponse is the order a kiosk shows, with its code as a PNG QR code
+type OrderResponse struct {
+	Order
+	QRCodeBase64 string `json:"qr_code_base64"`
+}
+
+// OrderPreview is what the student's app shows after scanning a kiosk QR code
+type OrderPreview struct {
+	Code        string    `json:"code"`
+	ProductID   uint      `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Quantity    int       `json:"quantity"`
+	Total       int       `json:"total"`
+	KioskName   string    `json:"kiosk_name"`
+	Location    string    `json:"location"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type ConfirmOrderRequest struct {
+	Code string `json:"code" binding:"required,max=64"`
+	PIN  string `json:"pin" binding:"required"`
+}
@@ -0,0 +1,115 @@
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindKioskByKey returns the kiosk whose key hashes to keyHash, active or not,
+// across campuses (the request has no campus until the kiosk is known)
+func (r *Repository) FindKioskByKey(ctx context.Context, keyHash string) (*Kiosk, error) {
+	var kiosk Kiosk
+	err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).Take(&kiosk).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	return &kiosk, err
+}
+
+func (r *Repository) TouchKiosk(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&Kiosk{}).Where("id = ?", id).UpdateColumn("last_seen_at", at).Error
+}
+
+// deviceTaken reports whether another kiosk, of any campus, is bound to deviceID
+func (r *Repository) deviceTaken(deviceID string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&Kiosk{}).Where("device_id = ? AND id <> ?", deviceID, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *Repository) CreateKiosk(ctx context.Context, kiosk *Kiosk) error {
+	return r.db.WithContext(ctx).Create(kiosk).Error
+}
+
+func (r *Repository) FindKiosks(ctx context.Context) ([]Kiosk, error) {
+	var kiosks []Kiosk
+	err := r.db.WithContext(ctx).Order("name").Find(&kiosks).Error
+	return kiosks, err
+}
+
+func (r *Repository) FindKioskByID(ctx context.Context, id uint) (*Kiosk, error) {
+	var kiosk Kiosk
+	if err := r.db.WithContext(ctx).First(&kiosk, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKioskNotFound
+		}
+		return nil, err
+	}
+	return &kiosk, nil
+}
+
+func (r *Repository) UpdateKiosk(ctx context.Context, kiosk *Kiosk, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(kiosk).Updates(updates).Error
+}
+
+func (r *Repository) CreateOrder(ctx context.Context, order *Order) error {
+	return r.db.WithContext(ctx).Create(order).Error
+}
+
+// FindOrder returns an order the kiosk made
+func (r *Repository) FindOrder(ctx context.Context, kioskID, id uint) (*Order, error) {
+	var order Order
+	err := r.db.WithContext(ctx).Where("kiosk_id = ?", kioskID).First(&order, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	return &order, err
+}
+
+// FindOpenOrderByCode returns the unexpired pending order of a code
+func (r *Repository) FindOpenOrderByCode(ctx context.Context, code string, now time.Time) (*Order, error) {
+	var order Order
+	err := r.db.WithContext(ctx).Where("code = ? AND status = ? AND expires_at > ?", code, OrderPending, now).Take(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderInvalid
+	}
+	return &order, err
+}
+
+// transition moves an order from one status to another, returning false when
+// it was not in from (anymore)
+func (r *Repository) transition(ctx context.Context, id uint, from, to string, updates map[string]interface{}) (bool, error) {
+	values := map[string]interface{}{"status": to}
+	for column, value := range updates {
+		values[column] = value
+	}
+	result := r.db.WithContext(ctx).Model(&Order{}).Where("id = ? AND status = ?", id, from).Updates(values)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CancelPendingOrders cancels the pending orders of a kiosk
+func (r *Repository) CancelPendingOrders(ctx context.Context, kioskID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&Order{}).
+		Where("kiosk_id = ? AND status = ?", kioskID, OrderPending).
+		Update("status", OrderCancelled)
+	return result.RowsAffected, result.Error
+}
+
+// ExpireOrders marks pending orders that expired before now as expired
+func (r *Repository) ExpireOrders(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&Order{}).
+		Where("status = ? AND expires_at <= ?", OrderPending, now).
+		Update("status", OrderExpired)
+	return result.RowsAffected, result.Error
+}
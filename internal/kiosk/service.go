@@ -0,0 +1,351 @@
+package kiosk
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet-point/internal/marketplace"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// touchInterval limits how often a kiosk's last_seen_at is written
+const touchInterval = time.Minute
+
+type Service struct {
+	repo        *Repository
+	marketplace *marketplace.MarketplaceService
+	orderTTL    time.Duration
+}
+
+func NewService(repo *Repository, marketplaceService *marketplace.MarketplaceService, orderTTL time.Duration) *Service {
+	return &Service{repo: repo, marketplace: marketplaceService, orderTTL: orderTTL}
+}
+
+// Authenticate returns the kiosk of an API key sent from the device it is bound
+// to. A disabled kiosk gets ErrKioskDisabled, so it can show why it is closed.
+func (s *Service) Authenticate(ctx context.Context, apiKey, deviceID string) (*Kiosk, error) {
+	if apiKey == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	kiosk, err := s.repo.FindKioskByKey(ctx, hashKey(apiKey))
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(kiosk.DeviceID), []byte(deviceID)) != 1 {
+		slog.WarnContext(ctx, "kiosk: key used from another device", "kiosk_id", kiosk.ID, "device_id", deviceID)
+		return nil, ErrDeviceMismatch
+	}
+	if !kiosk.Active {
+		return nil, ErrKioskDisabled
+	}
+	if now := time.Now(); kiosk.LastSeenAt == nil || now.Sub(*kiosk.LastSeenAt) > touchInterval {
+		if err := s.repo.TouchKiosk(ctx, kiosk.ID, now); err != nil {
+			slog.WarnContext(ctx, "kiosk: record kiosk use failed", "kiosk_id", kiosk.ID, "error", err)
+		}
+	}
+	return kiosk, nil
+}
+
+func (s *Service) GetKiosks(ctx context.Context) ([]Kiosk, error) {
+	return s.repo.FindKiosks(ctx)
+}
+
+// RegisterKiosk binds a new kiosk to its device; the API key is only returned
+// here and by RotateKey
+func (s *Service) RegisterKiosk(ctx context.Context, req RegisterKioskRequest, adminID uint) (*KioskWithKey, error) {
+	deviceID := strings.TrimSpace(req.DeviceID)
+	taken, err := s.repo.deviceTaken(deviceID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrDeviceTaken
+	}
+
+	apiKey := newAPIKey()
+	kiosk := &Kiosk{
+		Name:      req.Name,
+		DeviceID:  deviceID,
+		Location:  req.Location,
+		KeyPrefix: apiKey[:12],
+		KeyHash:   hashKey(apiKey),
+		Active:    true,
+		CreatedBy: adminID,
+	}
+	if err := s.repo.CreateKiosk(ctx, kiosk); err != nil {
+		return nil, err
+	}
+	return &KioskWithKey{Kiosk: *kiosk, APIKey: apiKey}, nil
+}
+
+func (s *Service) UpdateKiosk(ctx context.Context, id uint, req UpdateKioskRequest) (*Kiosk, error) {
+	kiosk, err := s.repo.FindKioskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Location != nil {
+		updates["location"] = *req.Location
+	}
+	if req.DeviceID != nil {
+		deviceID := strings.TrimSpace(*req.DeviceID)
+		taken, err := s.repo.deviceTaken(deviceID, kiosk.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrDeviceTaken
+		}
+		updates["device_id"] = deviceID
+	}
+	if len(updates) > 0 {
+		if err := s.repo.UpdateKiosk(ctx, kiosk, updates); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindKioskByID(ctx, id)
+}
+
+// RotateKey replaces a kiosk's API key; the old key stops working at once
+func (s *Service) RotateKey(ctx context.Context, id uint) (*KioskWithKey, error) {
+	kiosk, err := s.repo.FindKioskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := newAPIKey()
+	kiosk.KeyPrefix = apiKey[:12]
+	kiosk.KeyHash = hashKey(apiKey)
+	if err := s.repo.UpdateKiosk(ctx, kiosk, map[string]interface{}{"key_prefix": kiosk.KeyPrefix, "key_hash": kiosk.KeyHash}); err != nil {
+		return nil, err
+	}
+	return &KioskWithKey{Kiosk: *kiosk, APIKey: apiKey}, nil
+}
+
+// DisableKiosk shuts a kiosk out remotely: its requests are refused from now on
+// and its pending orders can no longer be confirmed
+func (s *Service) DisableKiosk(ctx context.Context, id uint, reason string) (*Kiosk, error) {
+	kiosk, err := s.repo.FindKioskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateKiosk(ctx, kiosk, map[string]interface{}{"active": false, "disabled_reason": reason}); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.CancelPendingOrders(ctx, kiosk.ID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindKioskByID(ctx, id)
+}
+
+func (s *Service) EnableKiosk(ctx context.Context, id uint) (*Kiosk, error) {
+	kiosk, err := s.repo.FindKioskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateKiosk(ctx, kiosk, map[string]interface{}{"active": true, "disabled_reason": ""}); err != nil {
+		return nil, err
+	}
+	return s.repo.FindKioskByID(ctx, id)
+}
+
+// GetProducts lists the active products of the kiosk's campus (ctx)
+func (s *Service) GetProducts(ctx context.Context, page, limit int) (*marketplace.ProductListResponse, error) {
+	return s.marketplace.GetAllProducts(ctx, marketplace.ProductListParams{Status: "active", Page: page, Limit: limit})
+}
+
+func (s *Service) GetProduct(ctx context.Context, id uint) (*marketplace.Product, error) {
+	product, err := s.marketplace.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.Status != "active" {
+		return nil, marketplace.ErrProductNotFound
+	}
+	return product, nil
+}
+
+// CreateOrder starts a purchase at a kiosk, priced now, for a student to
+// confirm within the order TTL
+func (s *Service) CreateOrder(ctx context.Context, kiosk *Kiosk, req CreateOrderRequest) (*OrderResponse, error) {
+	quantity := req.Quantity
+	if quantity <= 0 {
+		quantity = 1
+	}
+	product, err := s.GetProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Stock < quantity {
+		return nil, ErrProductUnavailable
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	order := &Order{
+		TenantID:    kiosk.TenantID,
+		KioskID:     kiosk.ID,
+		Code:        "WPK:" + hex.EncodeToString(b),
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Quantity:    quantity,
+		Total:       product.Price * quantity,
+		Status:      OrderPending,
+		ExpiresAt:   time.Now().Add(s.orderTTL),
+	}
+
+	qrCode, err := qrcode.Encode(order.Code, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	return &OrderResponse{Order: *order, QRCodeBase64: base64.StdEncoding.EncodeToString(qrCode)}, nil
+}
+
+// GetOrder returns an order of the kiosk, for it to poll until the student confirms
+func (s *Service) GetOrder(ctx context.Context, kiosk *Kiosk, id uint) (*Order, error) {
+	order, err := s.repo.FindOrder(ctx, kiosk.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	// The expiry job may not have run yet
+	if order.Status == OrderPending && !time.Now().Before(order.ExpiresAt) {
+		order.Status = OrderExpired
+	}
+	return order, nil
+}
+
+func (s *Service) CancelOrder(ctx context.Context, kiosk *Kiosk, id uint) (*Order, error) {
+	order, err := s.repo.FindOrder(ctx, kiosk.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	cancelled, err := s.repo.transition(ctx, order.ID, OrderPending, OrderCancelled, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, ErrOrderClosed
+	}
+	order.Status = OrderCancelled
+	return order, nil
+}
+
+// PreviewOrder shows a student what a scanned kiosk code buys before they confirm it
+func (s *Service) PreviewOrder(ctx context.Context, code string) (*OrderPreview, error) {
+	order, err := s.repo.FindOpenOrderByCode(ctx, strings.TrimSpace(code), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	kiosk, err := s.repo.FindKioskByID(ctx, order.KioskID)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderPreview{
+		Code:        order.Code,
+		ProductID:   order.ProductID,
+		ProductName: order.ProductName,
+		Quantity:    order.Quantity,
+		Total:       order.Total,
+		KioskName:   kiosk.Name,
+		Location:    kiosk.Location,
+		ExpiresAt:   order.ExpiresAt,
+	}, nil
+}
+
+// ConfirmOrder buys a scanned kiosk order from the student's wallet. The order
+// is claimed first, so a code confirmed twice is bought once; a failed purchase
+// (wrong PIN, too few points) returns it to pending for another try.
+func (s *Service) ConfirmOrder(ctx context.Context, userID uint, req ConfirmOrderRequest) (*Order, *Kiosk, error) {
+	order, err := s.repo.FindOpenOrderByCode(ctx, strings.TrimSpace(req.Code), time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	kiosk, err := s.repo.FindKioskByID(ctx, order.KioskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !kiosk.Active {
+		return nil, nil, ErrKioskDisabled
+	}
+
+	claimed, err := s.repo.transition(ctx, order.ID, OrderPending, OrderProcessing, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !claimed {
+		return nil, nil, ErrOrderInvalid
+	}
+
+	err = s.purchase(ctx, userID, order, req.PIN)
+	if err != nil {
+		if _, releaseErr := s.repo.transition(ctx, order.ID, OrderProcessing, OrderPending, nil); releaseErr != nil {
+			slog.ErrorContext(ctx, "kiosk: release order failed", "order_id", order.ID, "error", releaseErr)
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if _, err := s.repo.transition(ctx, order.ID, OrderProcessing, OrderCompleted, map[string]interface{}{"user_id": userID, "completed_at": now}); err != nil {
+		// The student has paid; only the kiosk's view of the order is behind
+		slog.ErrorContext(ctx, "kiosk: complete order failed", "order_id", order.ID, "user_id", userID, "error", err)
+	}
+	order.Status = OrderCompleted
+	order.UserID = &userID
+	order.CompletedAt = &now
+	return order, kiosk, nil
+}
+
+// purchase buys the order's product at the price the kiosk showed
+func (s *Service) purchase(ctx context.Context, userID uint, order *Order, pin string) error {
+	product, err := s.marketplace.GetProductByID(ctx, order.ProductID)
+	if err != nil {
+		return err
+	}
+	if product.Price*order.Quantity != order.Total {
+		return ErrPriceChanged
+	}
+	return s.marketplace.PurchaseProduct(ctx, userID, &marketplace.PurchaseRequest{
+		ProductID:     order.ProductID,
+		Quantity:      order.Quantity,
+		PaymentMethod: "wallet",
+		PIN:           pin,
+	})
+}
+
+// ExpireOrders marks orders nobody confirmed in time as expired, for the scheduler
+func (s *Service) ExpireOrders(ctx context.Context) (string, error) {
+	expired, err := s.repo.ExpireOrders(ctx, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("expired %d kiosk orders", expired), nil
+}
+
+// newAPIKey returns a random kiosk key, e.g. ksk_3f9a...
+func newAPIKey() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return "ksk_" + hex.EncodeToString(b)
+}
+
+func hashKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
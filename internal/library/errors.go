@@ -0,0 +1,15 @@
+package library
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrNotConfigured    = utils.NewAppError("LIBRARY_NOT_CONFIGURED", http.StatusNotFound, "library integration is not configured")
+	ErrInvalidSignature = utils.NewAppError("LIBRARY_INVALID_SIGNATURE", http.StatusUnauthorized, "library request signature is missing, invalid or too old")
+	ErrInvalidFine      = utils.NewAppError("LIBRARY_INVALID_FINE", http.StatusBadRequest, "library fine is incomplete")
+	ErrStudentNotFound  = utils.NewAppError("LIBRARY_STUDENT_NOT_FOUND", http.StatusNotFound, "no active student with this NIM")
+	ErrFineNotFound     = utils.NewAppError("LIBRARY_FINE_NOT_FOUND", http.StatusNotFound, "library fine not found")
+	ErrFineAlreadyPaid  = utils.NewAppError("LIBRARY_FINE_ALREADY_PAID", http.StatusConflict, "library fine is already paid")
+)
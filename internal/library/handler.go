@@ -0,0 +1,171 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignatureHeader carries the signature of requests from and callbacks to the library
+const SignatureHeader = "X-Library-Signature"
+
+// maxFineBody caps the size of a fine request
+const maxFineBody = 16 << 10
+
+type LibraryHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewLibraryHandler(service *Service, auditService audit.Logger) *LibraryHandler {
+	return &LibraryHandler{service: service, auditService: auditService}
+}
+
+// CreateFine handles a fine raised by the library system
+// @Summary Raise library fine
+// @Description Called by the library system to invoice a student a fine in points. The body is signed in the X-Library-Signature header as "t=<unix>,v1=<hex HMAC-SHA256 of "<t>.<body>">" with LIBRARY_SECRET. Raising a fine_ref again returns the existing fine with 200. Once the student pays, LIBRARY_CALLBACK_URL receives {"event":"fine.paid",...} signed the same way.
+// @Tags Integrations
+// @Accept json
+// @Produce json
+// @Param X-Library-Signature header string true "Signature of the body"
+// @Param request body CreateFineRequest true "Fine"
+// @Success 201 {object} utils.Response{data=Fine}
+// @Success 200 {object} utils.Response{data=Fine}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /integrations/library/fines [post]
+func (h *LibraryHandler) CreateFine(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxFineBody))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read request body", nil)
+		return
+	}
+	if err := h.service.VerifySignature(c.GetHeader(SignatureHeader), body); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	var req CreateFineRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, ErrInvalidFine)
+		return
+	}
+
+	fine, created, err := h.service.CreateFine(c.Request.Context(), req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	utils.SuccessResponse(c, status, "Library fine recorded", fine)
+}
+
+// GetMyFines handles listing the student's library fines
+// @Summary My library fines
+// @Tags Library
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(unpaid, paid)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=FineListResponse}
+// @Router /mahasiswa/library/fines [get]
+func (h *LibraryHandler) GetMyFines(c *gin.Context) {
+	h.list(c, c.GetUint("user_id"))
+}
+
+// PayFine handles paying a library fine with points
+// @Summary Pay library fine
+// @Description Debit the fine from the student's wallet (PIN required); the library is told through its callback
+// @Tags Library
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Param request body PayFineRequest true "PIN"
+// @Success 200 {object} utils.Response{data=Fine}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /mahasiswa/library/fines/{id}/pay [post]
+func (h *LibraryHandler) PayFine(c *gin.Context) {
+	fineID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid library fine ID", nil)
+		return
+	}
+	var req PayFineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	fine, err := h.service.Pay(c.Request.Context(), uint(fineID), userID, req.PIN)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Library fine paid", fine)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "PAY_LIBRARY_FINE",
+		Entity:    "LIBRARY_FINE",
+		EntityID:  fine.ID,
+		Details:   fmt.Sprintf("Paid library fine %s (%d points)", fine.FineRef, fine.Amount),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetAll handles listing all library fines (Admin)
+// @Summary List library fines
+// @Description Paid fines show whether the library has been told (callback_status sent, pending or failed)
+// @Tags Admin - Library
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(unpaid, paid)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=FineListResponse}
+// @Router /admin/library/fines [get]
+func (h *LibraryHandler) GetAll(c *gin.Context) {
+	h.list(c, 0)
+}
+
+func (h *LibraryHandler) list(c *gin.Context, userID uint) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "library_fine_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetFines(c.Request.Context(), FineListParams{
+		UserID: userID,
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve library fines", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Library fines retrieved successfully", "fines", response.Fines, response.Pagination, nil)
+}
@@ -0,0 +1,74 @@
+package library
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	StatusUnpaid = "unpaid"
+	StatusPaid   = "paid"
+)
+
+// Delivery states of the callback telling the library a fine was paid
+const (
+	CallbackPending = "pending"
+	CallbackSent    = "sent"
+	CallbackFailed  = "failed"
+)
+
+// Fine is an invoice the library system raised against a student, payable with
+// points. FineRef is the library's own reference; raising it again returns the
+// existing fine.
+type Fine struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	TenantID       uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	FineRef        string     `json:"fine_ref" gorm:"size:100;uniqueIndex;not null"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	StudentNIM     string     `json:"student_nim" gorm:"size:50;not null"`
+	Amount         int        `json:"amount" gorm:"not null"` // points
+	Description    string     `json:"description" gorm:"size:255;not null"`
+	Status         string     `json:"status" gorm:"type:enum('unpaid','paid');default:'unpaid';not null;index"`
+	PaidAt         *time.Time `json:"paid_at"`
+	CallbackStatus string     `json:"callback_status,omitempty" gorm:"size:20"`
+	CallbackError  string     `json:"callback_error,omitempty" gorm:"size:255"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Fine) TableName() string {
+	return "library_fines"
+}
+
+// CreateFineRequest is a fine as the library system raises it
+type CreateFineRequest struct {
+	FineRef     string `json:"fine_ref" example:"LIB-2024-00913"`
+	StudentNIM  string `json:"student_nim" example:"2207411001"`
+	Amount      int    `json:"amount" example:"50"`
+	Description string `json:"description" example:"Keterlambatan pengembalian: Clean Code (7 hari)"`
+}
+
+type PayFineRequest struct {
+	PIN string `json:"pin" binding:"required"`
+}
+
+// Callback is the body posted to the library once a fine is paid
+type Callback struct {
+	Event      string    `json:"event"` // "fine.paid"
+	FineRef    string    `json:"fine_ref"`
+	StudentNIM string    `json:"student_nim"`
+	Amount     int       `json:"amount"`
+	PaidAt     time.Time `json:"paid_at"`
+}
+
+type FineListParams struct {
+	UserID uint
+	Status string
+	Page   int
+	Limit  int
+}
+
+type FineListResponse struct {
+	Fines []Fine `json:"fines"`
+	utils.Pagination
+}
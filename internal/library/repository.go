@@ -0,0 +1,83 @@
+package library
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, fine *Fine) error {
+	return r.db.WithContext(ctx).Create(fine).Error
+}
+
+// FindByRef returns the fine the library raised as ref, or nil
+func (r *Repository) FindByRef(ctx context.Context, ref string) (*Fine, error) {
+	var fine Fine
+	err := r.db.WithContext(ctx).Where("fine_ref = ?", ref).Take(&fine).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &fine, err
+}
+
+func (r *Repository) FindByID(ctx context.Context, id uint) (*Fine, error) {
+	var fine Fine
+	if err := r.db.WithContext(ctx).First(&fine, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFineNotFound
+		}
+		return nil, err
+	}
+	return &fine, nil
+}
+
+// Lock loads a student's fine for update within tx, so a double click pays once
+func (r *Repository) Lock(tx *gorm.DB, id, userID uint) (*Fine, error) {
+	var fine Fine
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND user_id = ?", id, userID).First(&fine).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFineNotFound
+		}
+		return nil, err
+	}
+	return &fine, nil
+}
+
+func (r *Repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Fine{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindAll lists fines newest first
+func (r *Repository) FindAll(ctx context.Context, params FineListParams) ([]Fine, int64, error) {
+	var fines []Fine
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Fine{})
+	if params.UserID != 0 {
+		query = query.Where("user_id = ?", params.UserID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("created_at DESC").Order("id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&fines).Error
+	return fines, total, err
+}
@@ -0,0 +1,297 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"wallet-point/internal/auth"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/resilience"
+	"wallet-point/internal/wallet"
+	"wallet-point/internal/webhook"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// JobCallback is the job type telling the library a fine was paid; it runs on
+// the webhooks queue so the job queue's backoff paces the retries
+const JobCallback = "library.callback"
+
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	walletService *wallet.WalletService
+	authService   *auth.AuthService
+	queue         *jobs.Queue
+	notifications *notification.NotificationService
+	secret        string
+	signatures    webhook.InboundVerifier
+	callbackURL   string
+	client        *http.Client
+	policy        resilience.Policy
+}
+
+type callbackJob struct {
+	FineID uint `json:"fine_id"`
+}
+
+// NewService accepts fines signed with secret (without one the integration is
+// disabled) and posts paid fines to callbackURL, signed the same way, through
+// policy; an empty callbackURL sends no callbacks
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService, authService *auth.AuthService, queue *jobs.Queue, secret, callbackURL string, policy resilience.Policy) *Service {
+	s := &Service{
+		repo:          repo,
+		db:            db,
+		walletService: walletService,
+		authService:   authService,
+		queue:         queue,
+		secret:        secret,
+		signatures:    webhook.InboundVerifier{Secret: secret, NotConfigured: ErrNotConfigured, Invalid: ErrInvalidSignature},
+		callbackURL:   callbackURL,
+		client: &http.Client{
+			// A redirect could point the signed payload at another host; report it instead
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		policy: policy,
+	}
+	queue.Register(JobCallback, jobs.QueueWebhooks, s.sendCallback)
+	return s
+}
+
+// SetNotifications tells students when the library raises a fine
+func (s *Service) SetNotifications(notifications *notification.NotificationService) {
+	s.notifications = notifications
+}
+
+// VerifySignature checks the signature header of a request body from the library
+func (s *Service) VerifySignature(header string, body []byte) error {
+	return s.signatures.Verify(header, body)
+}
+
+// CreateFine raises a fine against a student. A fine_ref raised before returns
+// the existing fine and false.
+func (s *Service) CreateFine(ctx context.Context, req CreateFineRequest) (*Fine, bool, error) {
+	req.FineRef = strings.TrimSpace(req.FineRef)
+	req.StudentNIM = strings.TrimSpace(req.StudentNIM)
+	if req.FineRef == "" || len(req.FineRef) > 100 || req.StudentNIM == "" || len(req.StudentNIM) > 50 ||
+		req.Amount <= 0 || req.Description == "" || len(req.Description) > 255 {
+		return nil, false, ErrInvalidFine
+	}
+
+	existing, err := s.repo.FindByRef(ctx, req.FineRef)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	student, err := wallet.FindStudent(s.db.WithContext(ctx), req.StudentNIM)
+	if err != nil {
+		return nil, false, err
+	}
+	if student == nil {
+		return nil, false, ErrStudentNotFound
+	}
+
+	fine := &Fine{
+		TenantID:    student.TenantID,
+		FineRef:     req.FineRef,
+		UserID:      student.UserID,
+		StudentNIM:  req.StudentNIM,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Status:      StatusUnpaid,
+	}
+	if err := s.repo.Create(ctx, fine); err != nil {
+		return nil, false, err
+	}
+
+	s.notify(fine)
+	return fine, true, nil
+}
+
+// GetFines lists fines; a UserID limits them to one student
+func (s *Service) GetFines(ctx context.Context, params FineListParams) (*FineListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	fines, total, err := s.repo.FindAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &FineListResponse{
+		Fines:      fines,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// Pay settles a student's unpaid fine from their wallet after checking their
+// PIN, and queues the callback to the library in the same transaction
+func (s *Service) Pay(ctx context.Context, fineID, userID uint, pin string) (*Fine, error) {
+	if err := s.authService.VerifyPIN(userID, pin); err != nil {
+		return nil, err
+	}
+	userWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fine *Fine
+	err = utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		var err error
+		fine, err = s.repo.Lock(tx, fineID, userID)
+		if err != nil {
+			return err
+		}
+		if fine.Status != StatusUnpaid {
+			return ErrFineAlreadyPaid
+		}
+
+		// Fines are spending like marketplace purchases
+		description := fmt.Sprintf("Denda perpustakaan %s: %s", fine.FineRef, fine.Description)
+		if err := s.walletService.DebitWithTransaction(tx, userWallet.ID, fine.Amount, "marketplace", description); err != nil {
+			return err
+		}
+
+		paidAt := time.Now()
+		fine.Status = StatusPaid
+		fine.PaidAt = &paidAt
+		updates := map[string]interface{}{"status": fine.Status, "paid_at": paidAt}
+		if s.callbackURL != "" {
+			fine.CallbackStatus = CallbackPending
+			updates["callback_status"] = fine.CallbackStatus
+			if _, err := s.queue.EnqueueTx(tx, JobCallback, callbackJob{FineID: fine.ID}, jobs.EnqueueOptions{}); err != nil {
+				return err
+			}
+		}
+		return tx.Model(fine).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fine, nil
+}
+
+// callbackStatusError is a non-2xx reply of the library
+type callbackStatusError struct {
+	status int
+}
+
+func (e *callbackStatusError) Error() string {
+	return fmt.Sprintf("library responded %d", e.status)
+}
+
+// sendCallback is the JobCallback handler: it posts the paid fine to the library
+// once and records the outcome; a returned error makes the job queue retry it
+func (s *Service) sendCallback(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	var payload callbackJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	fine, err := s.repo.FindByID(ctx, payload.FineID)
+	if err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	if fine.CallbackStatus == CallbackSent {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(Callback{
+		Event:      "fine.paid",
+		FineRef:    fine.FineRef,
+		StudentNIM: fine.StudentNIM,
+		Amount:     fine.Amount,
+		PaidAt:     *fine.PaidAt,
+	})
+	if err != nil {
+		return nil, jobs.Permanent(err)
+	}
+
+	// Attempts are paced by the job queue, so the policy makes a single one
+	policy := s.policy
+	policy.MaxAttempts = 1
+	err = policy.Do(ctx, func(ctx context.Context) error {
+		return s.post(ctx, body)
+	})
+
+	// A 4xx other than 429 means the library rejected the callback; retrying will not help
+	statusErr, rejected := err.(*callbackStatusError)
+	permanent := rejected && statusErr.status < 500 && statusErr.status != http.StatusTooManyRequests
+	updates := map[string]interface{}{"callback_status": CallbackSent, "callback_error": ""}
+	if err != nil {
+		message := err.Error()
+		if len(message) > 255 {
+			message = message[:255]
+		}
+		updates["callback_error"] = message
+		if permanent || job.Attempts >= job.MaxAttempts {
+			updates["callback_status"] = CallbackFailed
+		} else {
+			updates["callback_status"] = CallbackPending
+		}
+	}
+	if updateErr := s.repo.Update(ctx, fine.ID, updates); updateErr != nil {
+		slog.Error("library: record callback failed", "fine_id", fine.ID, "job_id", job.ID, "error", updateErr)
+	}
+
+	if err != nil {
+		if permanent {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	return map[string]interface{}{"fine_ref": fine.FineRef}, nil
+}
+
+func (s *Service) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WalletPoint-Webhooks/1.0")
+	req.Header.Set(SignatureHeader, webhook.Sign(s.secret, time.Now().Unix(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &callbackStatusError{status: resp.StatusCode}
+	}
+	return nil
+}
+
+func (s *Service) notify(fine *Fine) {
+	if s.notifications == nil {
+		return
+	}
+
+	err := s.notifications.Notify(notification.NotifyParams{
+		UserID:    fine.UserID,
+		Type:      "library_fine",
+		Title:     "Denda perpustakaan",
+		Message:   fmt.Sprintf("Perpustakaan menagih denda %d poin: %s. Bayar dengan poin dari menu Denda Perpustakaan.", fine.Amount, fine.Description),
+		Link:      "/library/fines",
+		SendEmail: true,
+	})
+	if err != nil {
+		slog.Error("library: notify student failed", "fine_ref", fine.FineRef, "error", err)
+	}
+}
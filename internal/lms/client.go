@@ -0,0 +1,104 @@
+package lms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"wallet-point/internal/resilience"
+)
+
+// Client reads completion events from the LMS feed for polling: a GET of the
+// feed URL with since (RFC 3339) answers {"events": [...], "next_cursor": "..."},
+// and the next page is fetched with cursor until next_cursor is empty. Calls go
+// through policy: network errors, timeouts, 5xx and 429 replies are retried.
+type Client struct {
+	feedURL string
+	token   string
+	client  *http.Client
+	policy  resilience.Policy
+}
+
+func NewClient(feedURL, token string, policy resilience.Policy) *Client {
+	if policy.Retryable == nil {
+		policy.Retryable = retryableFeedError
+	}
+	return &Client{feedURL: feedURL, token: token, client: &http.Client{}, policy: policy}
+}
+
+// feedStatusError is a non-2xx reply of the feed
+type feedStatusError struct {
+	status int
+	body   string
+}
+
+func (e *feedStatusError) Error() string {
+	return fmt.Sprintf("LMS feed returned status %d: %s", e.status, e.body)
+}
+
+// retryableFeedError retries everything except a 4xx other than 429, which
+// means the request itself was rejected
+func retryableFeedError(err error) bool {
+	statusErr, ok := err.(*feedStatusError)
+	if !ok {
+		return true
+	}
+	return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+}
+
+type feedPage struct {
+	Events     []EventPayload `json:"events"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+// Events returns the events that happened at or after since
+func (c *Client) Events(ctx context.Context, since time.Time) ([]EventPayload, error) {
+	var events []EventPayload
+	cursor := ""
+	for {
+		query := url.Values{"since": {since.UTC().Format(time.RFC3339)}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+
+		var page feedPage
+		err := c.policy.Do(ctx, func(ctx context.Context) error {
+			separator := "?"
+			if strings.Contains(c.feedURL, "?") {
+				separator = "&"
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.feedURL+separator+query.Encode(), nil)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Accept", "application/json")
+			req.Header.Set("Authorization", "Bearer "+c.token)
+
+			resp, err := c.client.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode >= 300 {
+				body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+				return &feedStatusError{status: resp.StatusCode, body: string(body)}
+			}
+			page = feedPage{}
+			return json.NewDecoder(resp.Body).Decode(&page)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, page.Events...)
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return events, nil
+		}
+		cursor = page.NextCursor
+	}
+}
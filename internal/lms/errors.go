@@ -0,0 +1,14 @@
+package lms
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrNotConfigured      = utils.NewAppError("LMS_NOT_CONFIGURED", http.StatusNotFound, "LMS integration is not configured")
+	ErrInvalidSignature   = utils.NewAppError("LMS_INVALID_SIGNATURE", http.StatusUnauthorized, "LMS event signature is missing, invalid or too old")
+	ErrInvalidEvent       = utils.NewAppError("LMS_INVALID_EVENT", http.StatusBadRequest, "LMS event is incomplete")
+	ErrCourseRuleNotFound = utils.NewAppError("LMS_COURSE_RULE_NOT_FOUND", http.StatusNotFound, "LMS course mapping not found")
+	ErrCourseRuleExists   = utils.NewAppError("LMS_COURSE_RULE_EXISTS", http.StatusConflict, "LMS course is already mapped")
+)
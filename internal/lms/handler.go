@@ -0,0 +1,244 @@
+package lms
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignatureHeader carries the signature of a webhook event
+const SignatureHeader = "X-LMS-Signature"
+
+// maxEventBody caps the size of a webhook event
+const maxEventBody = 64 << 10
+
+type LMSHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewLMSHandler(service *Service, auditService audit.Logger) *LMSHandler {
+	return &LMSHandler{service: service, auditService: auditService}
+}
+
+// ReceiveEvent handles a completion event pushed by the LMS
+// @Summary Receive LMS completion event
+// @Description Called by the campus LMS when a student completes a course or quiz. The body is signed in the X-LMS-Signature header as "t=<unix>,v1=<hex HMAC-SHA256 of "<t>.<body>">" with LMS_WEBHOOK_SECRET (the scheme of our outbound webhooks). Redelivering an event ID returns the recorded outcome.
+// @Tags Integrations
+// @Accept json
+// @Produce json
+// @Param X-LMS-Signature header string true "Signature of the body"
+// @Param request body EventPayload true "Completion event"
+// @Success 200 {object} utils.Response{data=Event}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /integrations/lms/events [post]
+func (h *LMSHandler) ReceiveEvent(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEventBody))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read request body", nil)
+		return
+	}
+	if err := h.service.VerifySignature(c.GetHeader(SignatureHeader), body); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	var payload EventPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, ErrInvalidEvent)
+		return
+	}
+
+	event, err := h.service.Process(c.Request.Context(), SourceWebhook, payload)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "LMS event processed", event)
+}
+
+// GetRules handles listing the LMS course mappings (Admin)
+// @Summary List LMS course mappings
+// @Tags Admin - LMS
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]CourseRule}
+// @Router /admin/lms/courses [get]
+func (h *LMSHandler) GetRules(c *gin.Context) {
+	rules, err := h.service.GetRules(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve LMS courses", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "LMS courses retrieved successfully", rules)
+}
+
+// CreateRule handles mapping an LMS course to points (Admin)
+// @Summary Map LMS course
+// @Description Set the points earned by completing an LMS course and by passing its quizzes (with at least quiz_min_score percent)
+// @Tags Admin - LMS
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateCourseRuleRequest true "Course mapping"
+// @Success 201 {object} utils.Response{data=CourseRule}
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/lms/courses [post]
+func (h *LMSHandler) CreateRule(c *gin.Context) {
+	var req CreateCourseRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	rule, err := h.service.CreateRule(c.Request.Context(), req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "LMS course mapped", rule)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_LMS_COURSE",
+		Entity:    "LMS_COURSE",
+		EntityID:  rule.ID,
+		Details:   fmt.Sprintf("Mapped LMS course %s: %d points on completion, %d per quiz from %.0f%%", rule.CourseID, rule.CompletionPoints, rule.QuizPoints, rule.QuizMinScore),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateRule handles changing an LMS course mapping (Admin)
+// @Summary Update LMS course mapping
+// @Tags Admin - LMS
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Course mapping ID"
+// @Param request body UpdateCourseRuleRequest true "Fields to change"
+// @Success 200 {object} utils.Response{data=CourseRule}
+// @Failure 404 {object} utils.Response
+// @Router /admin/lms/courses/{id} [put]
+func (h *LMSHandler) UpdateRule(c *gin.Context) {
+	ruleID, ok := ruleIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateCourseRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	rule, err := h.service.UpdateRule(c.Request.Context(), ruleID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "LMS course mapping updated", rule)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_LMS_COURSE",
+		Entity:    "LMS_COURSE",
+		EntityID:  rule.ID,
+		Details:   fmt.Sprintf("Updated LMS course %s: %d points on completion, %d per quiz from %.0f%%, active=%t", rule.CourseID, rule.CompletionPoints, rule.QuizPoints, rule.QuizMinScore, rule.Active),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteRule handles removing an LMS course mapping (Admin)
+// @Summary Delete LMS course mapping
+// @Description The course stops earning points; points already credited stay
+// @Tags Admin - LMS
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Course mapping ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/lms/courses/{id} [delete]
+func (h *LMSHandler) DeleteRule(c *gin.Context) {
+	ruleID, ok := ruleIDParam(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.service.DeleteRule(c.Request.Context(), ruleID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "LMS course mapping deleted", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "DELETE_LMS_COURSE",
+		Entity:    "LMS_COURSE",
+		EntityID:  rule.ID,
+		Details:   "Removed the mapping of LMS course " + rule.CourseID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetEvents handles listing received LMS events and their outcome (Admin)
+// @Summary List LMS events
+// @Description Skipped events carry the reason (unknown student, course not mapped, score too low, already rewarded)
+// @Tags Admin - LMS
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by outcome" Enums(credited, skipped)
+// @Param course_id query string false "Filter by LMS course"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=EventListResponse}
+// @Router /admin/lms/events [get]
+func (h *LMSHandler) GetEvents(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "lms_event_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetEvents(c.Request.Context(), EventListParams{
+		Status:   status,
+		CourseID: c.Query("course_id"),
+		Page:     page,
+		Limit:    limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve LMS events", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "LMS events retrieved successfully", "events", response.Events, response.Pagination, nil)
+}
+
+func ruleIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid LMS course mapping ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
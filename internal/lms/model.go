@@ -0,0 +1,107 @@
+package lms
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+// Event types sent by the LMS
+const (
+	EventCourseCompleted = "course_completed"
+	EventQuizCompleted   = "quiz_completed"
+)
+
+const (
+	StatusCredited = "credited"
+	StatusSkipped  = "skipped"
+
+	SourceWebhook = "webhook"
+	SourcePoll    = "poll"
+)
+
+// CourseRule maps an LMS course of a campus to the points its completion and its
+// quizzes earn. Courses without an active rule earn nothing.
+type CourseRule struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	TenantID         uint      `json:"tenant_id" gorm:"not null;default:1;uniqueIndex:idx_lms_course_rules_course,priority:1"`
+	CourseID         string    `json:"course_id" gorm:"size:100;not null;uniqueIndex:idx_lms_course_rules_course,priority:2"`
+	Name             string    `json:"name" gorm:"size:255"`
+	CompletionPoints int       `json:"completion_points" gorm:"default:0;not null"`
+	QuizPoints       int       `json:"quiz_points" gorm:"default:0;not null"`    // per passed quiz
+	QuizMinScore     float64   `json:"quiz_min_score" gorm:"default:0;not null"` // percent needed to pass
+	Active           bool      `json:"active" gorm:"default:true;not null"`
+	CreatedBy        uint      `json:"created_by"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (CourseRule) TableName() string {
+	return "lms_course_rules"
+}
+
+// Event is a completion event received from the LMS and what it earned. EventID
+// makes redelivered events no-ops; RewardKey allows one reward per student,
+// course and quiz however often the LMS reports the completion.
+type Event struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	EventID    string    `json:"event_id" gorm:"size:100;uniqueIndex;not null"`
+	Source     string    `json:"source" gorm:"size:20;not null"`
+	Type       string    `json:"type" gorm:"size:30;not null"`
+	CourseID   string    `json:"course_id" gorm:"size:100;not null;index"`
+	QuizID     string    `json:"quiz_id,omitempty" gorm:"size:100"`
+	StudentNIM string    `json:"student_nim" gorm:"size:50;not null"`
+	Score      *float64  `json:"score,omitempty"` // percent
+	UserID     *uint     `json:"user_id,omitempty" gorm:"index"`
+	Points     int       `json:"points" gorm:"default:0;not null"`
+	Status     string    `json:"status" gorm:"type:enum('credited','skipped');not null"`
+	Reason     string    `json:"reason,omitempty" gorm:"size:255"`
+	RewardKey  *string   `json:"-" gorm:"size:255;uniqueIndex"`
+	OccurredAt time.Time `json:"occurred_at" gorm:"not null;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (Event) TableName() string {
+	return "lms_events"
+}
+
+// EventPayload is a completion event as the LMS sends it, in a webhook body or
+// the polling feed. Score is out of MaxScore, or a percentage without one.
+type EventPayload struct {
+	ID         string    `json:"id" example:"moodle-88123"`
+	Type       string    `json:"type" enums:"course_completed,quiz_completed"`
+	CourseID   string    `json:"course_id" example:"IF-2024-101"`
+	QuizID     string    `json:"quiz_id,omitempty"`
+	StudentNIM string    `json:"student_nim" example:"2207411001"`
+	Score      *float64  `json:"score,omitempty"`
+	MaxScore   *float64  `json:"max_score,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type CreateCourseRuleRequest struct {
+	CourseID         string  `json:"course_id" binding:"required,max=100"`
+	Name             string  `json:"name" binding:"max=255"`
+	CompletionPoints int     `json:"completion_points" binding:"min=0"`
+	QuizPoints       int     `json:"quiz_points" binding:"min=0"`
+	QuizMinScore     float64 `json:"quiz_min_score" binding:"min=0,max=100"`
+	Active           *bool   `json:"active"`
+}
+
+type UpdateCourseRuleRequest struct {
+	Name             *string  `json:"name" binding:"omitempty,max=255"`
+	CompletionPoints *int     `json:"completion_points" binding:"omitempty,min=0"`
+	QuizPoints       *int     `json:"quiz_points" binding:"omitempty,min=0"`
+	QuizMinScore     *float64 `json:"quiz_min_score" binding:"omitempty,min=0,max=100"`
+	Active           *bool    `json:"active"`
+}
+
+type EventListParams struct {
+	Status   string
+	CourseID string
+	Page     int
+	Limit    int
+}
+
+type EventListResponse struct {
+	Events []Event `json:"events"`
+	utils.Pagination
+}
@@ -0,0 +1,105 @@
+package lms
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// findActiveRule returns the active rule of a campus's course, or nil
+func (r *Repository) findActiveRule(tx *gorm.DB, tenantID uint, courseID string) (*CourseRule, error) {
+	var rule CourseRule
+	err := tx.Where("tenant_id = ? AND course_id = ? AND active = ?", tenantID, courseID, true).Take(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rule, err
+}
+
+func (r *Repository) rewarded(tx *gorm.DB, rewardKey string) (bool, error) {
+	var count int64
+	err := tx.Model(&Event{}).Where("reward_key = ?", rewardKey).Count(&count).Error
+	return count > 0, err
+}
+
+// LastPolled returns when the newest polled event happened (zero before the first poll)
+func (r *Repository) LastPolled(ctx context.Context) (time.Time, error) {
+	var last *time.Time
+	err := r.db.WithContext(ctx).Model(&Event{}).Where("source = ?", SourcePoll).Select("MAX(occurred_at)").Row().Scan(&last)
+	if err != nil || last == nil {
+		return time.Time{}, err
+	}
+	return *last, nil
+}
+
+func (r *Repository) CreateRule(ctx context.Context, rule *CourseRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// RuleExists reports whether the campus of ctx already maps courseID
+func (r *Repository) RuleExists(ctx context.Context, courseID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&CourseRule{}).Where("course_id = ?", courseID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *Repository) FindRules(ctx context.Context) ([]CourseRule, error) {
+	var rules []CourseRule
+	err := r.db.WithContext(ctx).Order("course_id").Find(&rules).Error
+	return rules, err
+}
+
+func (r *Repository) FindRuleByID(ctx context.Context, id uint) (*CourseRule, error) {
+	var rule CourseRule
+	if err := r.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCourseRuleNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *Repository) UpdateRule(ctx context.Context, rule *CourseRule, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(rule).Updates(updates).Error
+}
+
+func (r *Repository) DeleteRule(ctx context.Context, rule *CourseRule) error {
+	return r.db.WithContext(ctx).Delete(rule).Error
+}
+
+// FindEvents lists events newest first; campus admins see the events of their students
+func (r *Repository) FindEvents(ctx context.Context, params EventListParams) ([]Event, int64, error) {
+	var events []Event
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Event{}).
+		Scopes(utils.ScopeTenantOwner(ctx, "lms_events.user_id", "users"))
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.CourseID != "" {
+		query = query.Where("course_id = ?", params.CourseID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("occurred_at DESC").Order("id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,326 @@
+package lms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet-point/internal/wallet"
+	"wallet-point/internal/webhook"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pollOverlap re-reads a margin of the feed before the newest polled event, for
+// events the LMS records late; events already seen are skipped by their ID
+const pollOverlap = time.Hour
+
+// firstPollWindow is how far back the first poll reads
+const firstPollWindow = 7 * 24 * time.Hour
+
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	walletService *wallet.WalletService
+	signatures    webhook.InboundVerifier
+	client        *Client
+}
+
+// NewService accepts webhook events signed with secret; without one the
+// webhook is disabled
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService, secret string) *Service {
+	return &Service{
+		repo:          repo,
+		db:            db,
+		walletService: walletService,
+		signatures:    webhook.InboundVerifier{Secret: secret, NotConfigured: ErrNotConfigured, Invalid: ErrInvalidSignature},
+	}
+}
+
+// SetClient enables polling of the LMS feed
+func (s *Service) SetClient(client *Client) {
+	s.client = client
+}
+
+// VerifySignature checks the signature header of a webhook body from the LMS
+func (s *Service) VerifySignature(header string, body []byte) error {
+	return s.signatures.Verify(header, body)
+}
+
+// Process applies the course rule of an event: the student gets the points of a
+// completed course, or of a quiz passed with the rule's minimum score, once per
+// course and quiz. Events without a student, rule or points are kept as skipped.
+// An event ID seen before returns the recorded outcome.
+func (s *Service) Process(ctx context.Context, source string, payload EventPayload) (*Event, error) {
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	event := &Event{
+		EventID:    payload.ID,
+		Source:     source,
+		Type:       payload.Type,
+		CourseID:   payload.CourseID,
+		QuizID:     payload.QuizID,
+		StudentNIM: payload.StudentNIM,
+		Score:      payload.percent(),
+		Status:     StatusSkipped,
+		OccurredAt: payload.OccurredAt,
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return tx.Where("event_id = ?", payload.ID).First(event).Error
+		}
+
+		student, err := wallet.FindStudent(tx, event.StudentNIM)
+		if err != nil {
+			return err
+		}
+		if student == nil {
+			return s.skip(tx, event, "unknown student")
+		}
+		event.UserID = &student.UserID
+
+		rule, err := s.repo.findActiveRule(tx, student.TenantID, event.CourseID)
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return s.skip(tx, event, "course is not mapped")
+		}
+
+		points, reason := rule.points(event)
+		if points == 0 {
+			return s.skip(tx, event, reason)
+		}
+
+		rewardKey := fmt.Sprintf("%d:%s:%s:%s", student.UserID, event.CourseID, event.Type, event.QuizID)
+		rewarded, err := s.repo.rewarded(tx, rewardKey)
+		if err != nil {
+			return err
+		}
+		if rewarded {
+			return s.skip(tx, event, "already rewarded")
+		}
+
+		// The wallet has no separate earning rules engine: the course rules of
+		// this package are the earning rules of LMS events, and missions only
+		// reward reviewed submissions. The rule decided the points above, so
+		// they are credited directly, typed like mission rewards since they are
+		// earned the same way.
+		if err := s.walletService.CreditWithTransaction(tx, student.WalletID, points, "mission", event.description(rule)); err != nil {
+			return err
+		}
+		event.Status = StatusCredited
+		event.Points = points
+		event.RewardKey = &rewardKey
+		return tx.Model(event).Updates(map[string]interface{}{
+			"status":     event.Status,
+			"points":     event.Points,
+			"user_id":    event.UserID,
+			"reward_key": rewardKey,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *Service) skip(tx *gorm.DB, event *Event, reason string) error {
+	event.Reason = reason
+	return tx.Model(event).Updates(map[string]interface{}{"reason": reason, "user_id": event.UserID}).Error
+}
+
+// Poll processes the events of the LMS feed since the last poll, for the scheduler
+func (s *Service) Poll(ctx context.Context) (string, error) {
+	if s.client == nil {
+		return "LMS polling is not configured", nil
+	}
+
+	last, err := s.repo.LastPolled(ctx)
+	if err != nil {
+		return "", err
+	}
+	since := last.Add(-pollOverlap)
+	if last.IsZero() {
+		since = time.Now().Add(-firstPollWindow)
+	}
+
+	payloads, err := s.client.Events(ctx, since)
+	if err != nil {
+		return "", err
+	}
+
+	credited, skipped := 0, 0
+	var failed []error
+	for _, payload := range payloads {
+		event, err := s.Process(ctx, SourcePoll, payload)
+		if err != nil {
+			slog.WarnContext(ctx, "lms: event not processed", "event_id", payload.ID, "error", err)
+			failed = append(failed, fmt.Errorf("event %s: %w", payload.ID, err))
+			continue
+		}
+		if event.Status == StatusCredited {
+			credited++
+		} else {
+			skipped++
+		}
+	}
+	return fmt.Sprintf("read %d events: %d credited, %d skipped, %d failed", len(payloads), credited, skipped, len(failed)), errors.Join(failed...)
+}
+
+func (s *Service) GetRules(ctx context.Context) ([]CourseRule, error) {
+	return s.repo.FindRules(ctx)
+}
+
+func (s *Service) CreateRule(ctx context.Context, req CreateCourseRuleRequest, adminID uint) (*CourseRule, error) {
+	exists, err := s.repo.RuleExists(ctx, strings.TrimSpace(req.CourseID))
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrCourseRuleExists
+	}
+
+	rule := &CourseRule{
+		CourseID:         strings.TrimSpace(req.CourseID),
+		Name:             req.Name,
+		CompletionPoints: req.CompletionPoints,
+		QuizPoints:       req.QuizPoints,
+		QuizMinScore:     req.QuizMinScore,
+		Active:           req.Active == nil || *req.Active,
+		CreatedBy:        adminID,
+	}
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *Service) UpdateRule(ctx context.Context, id uint, req UpdateCourseRuleRequest) (*CourseRule, error) {
+	rule, err := s.repo.FindRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.CompletionPoints != nil {
+		updates["completion_points"] = *req.CompletionPoints
+	}
+	if req.QuizPoints != nil {
+		updates["quiz_points"] = *req.QuizPoints
+	}
+	if req.QuizMinScore != nil {
+		updates["quiz_min_score"] = *req.QuizMinScore
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) > 0 {
+		if err := s.repo.UpdateRule(ctx, rule, updates); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindRuleByID(ctx, id)
+}
+
+func (s *Service) DeleteRule(ctx context.Context, id uint) (*CourseRule, error) {
+	rule, err := s.repo.FindRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return rule, s.repo.DeleteRule(ctx, rule)
+}
+
+func (s *Service) GetEvents(ctx context.Context, params EventListParams) (*EventListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	events, total, err := s.repo.FindEvents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &EventListResponse{
+		Events:     events,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// points returns what event earns under the rule, or 0 and why not
+func (r *CourseRule) points(event *Event) (int, string) {
+	switch event.Type {
+	case EventCourseCompleted:
+		if r.CompletionPoints == 0 {
+			return 0, "course completion earns no points"
+		}
+		return r.CompletionPoints, ""
+	case EventQuizCompleted:
+		if r.QuizPoints == 0 {
+			return 0, "quizzes of the course earn no points"
+		}
+		if event.Score == nil || *event.Score < r.QuizMinScore {
+			return 0, fmt.Sprintf("score below %.0f%%", r.QuizMinScore)
+		}
+		return r.QuizPoints, ""
+	}
+	return 0, "unknown event type"
+}
+
+func (e *Event) description(rule *CourseRule) string {
+	course := rule.Name
+	if course == "" {
+		course = rule.CourseID
+	}
+	if e.Type == EventQuizCompleted {
+		return fmt.Sprintf("LMS: quiz %s passed in %s", e.QuizID, course)
+	}
+	return "LMS: completed course " + course
+}
+
+func (p *EventPayload) validate() error {
+	if p.ID == "" || len(p.ID) > 100 || p.CourseID == "" || len(p.CourseID) > 100 || p.StudentNIM == "" || len(p.StudentNIM) > 50 || len(p.QuizID) > 100 {
+		return ErrInvalidEvent
+	}
+	switch p.Type {
+	case EventCourseCompleted:
+	case EventQuizCompleted:
+		if p.QuizID == "" || p.Score == nil {
+			return ErrInvalidEvent
+		}
+	default:
+		return ErrInvalidEvent
+	}
+	return nil
+}
+
+// percent returns the score as a percentage
+func (p *EventPayload) percent() *float64 {
+	if p.Score == nil {
+		return nil
+	}
+	score := *p.Score
+	if p.MaxScore != nil && *p.MaxScore > 0 {
+		score = score / *p.MaxScore * 100
+	}
+	return &score
+}
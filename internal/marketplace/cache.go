@@ -0,0 +1,91 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet-point/internal/cache"
+	"wallet-point/utils"
+)
+
+// Product catalog cache. Items are keyed by ID; list pages embed a generation
+// counter so any product change invalidates every cached page with one INCR.
+const (
+	productCacheName          = "products"
+	productListGenerationKey  = "products:list:generation"
+	productInvalidateDeadline = 2 * time.Second
+)
+
+// SetCache enables caching of product reads for ttl
+func (s *MarketplaceService) SetCache(c *cache.Cache, ttl time.Duration) {
+	s.cache = c
+	s.cacheTTL = ttl
+}
+
+func productCacheKey(productID uint) string {
+	return fmt.Sprintf("products:item:%d", productID)
+}
+
+// productListCacheKey returns "" when the generation is unavailable, so the page is not cached
+func (s *MarketplaceService) productListCacheKey(ctx context.Context, params ProductListParams) string {
+	generation, err := s.cache.Generation(ctx, productListGenerationKey)
+	if err != nil {
+		slog.WarnContext(ctx, "product cache: read generation failed", "error", err)
+		return ""
+	}
+
+	cursor := ""
+	if params.Cursor != nil {
+		cursor = utils.EncodeCursor(params.Cursor.CreatedAt, params.Cursor.ID)
+	}
+	tenantID, _ := utils.TenantFromContext(ctx)
+	return fmt.Sprintf("products:list:%d:%d:%s:%d:%s:%t:%d:%d:%s:%s", generation, tenantID, params.Status, params.CategoryID, params.Store, params.IncludeDeleted, params.Page, params.Limit, cursor, strings.Join(params.Fields, ","))
+}
+
+// InvalidateProduct drops the cached product, all cached list pages and the
+// cached public catalog responses. It runs on its own deadline so a cancelled
+// request cannot leave stale entries behind.
+func (s *MarketplaceService) InvalidateProduct(productID uint) {
+	if s.cache == nil || !s.cache.Enabled() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), productInvalidateDeadline)
+	defer cancel()
+
+	if err := s.cache.Delete(ctx, productCacheKey(productID)); err != nil {
+		slog.Error("product cache: invalidate product failed", "product_id", productID, "error", err)
+	}
+	if err := s.cache.Bump(ctx, productListGenerationKey); err != nil {
+		slog.Error("product cache: invalidate lists failed", "error", err)
+	}
+	if err := s.cache.InvalidateResponses(ctx, cache.ResponseCatalog); err != nil {
+		slog.Error("product cache: invalidate catalog responses failed", "error", err)
+	}
+}
+
+// InvalidateCategories drops the cached list pages, whose category filters may
+// now match other products, and the cached public catalog responses
+func (s *MarketplaceService) InvalidateCategories() {
+	s.InvalidateLists()
+}
+
+// InvalidateLists drops the cached list pages and public catalog responses,
+// e.g. after a faculty store's catalog changed
+func (s *MarketplaceService) InvalidateLists() {
+	if s.cache == nil || !s.cache.Enabled() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), productInvalidateDeadline)
+	defer cancel()
+
+	if err := s.cache.Bump(ctx, productListGenerationKey); err != nil {
+		slog.Error("product cache: invalidate lists failed", "error", err)
+	}
+	if err := s.cache.InvalidateResponses(ctx, cache.ResponseCatalog); err != nil {
+		slog.Error("product cache: invalidate catalog responses failed", "error", err)
+	}
+}
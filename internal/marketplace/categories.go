@@ -0,0 +1,383 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"wallet-point/utils"
+)
+
+// GetCategoryTree returns every category nested under its parent, by name
+func (s *MarketplaceService) GetCategoryTree(ctx context.Context) ([]CategoryNode, error) {
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return categoryTree(categories, nil), nil
+}
+
+// GetCategory returns a category with its subcategories
+func (s *MarketplaceService) GetCategory(ctx context.Context, categoryID uint) (*CategoryNode, error) {
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if node := findCategoryNode(categoryTree(categories, nil), categoryID); node != nil {
+		return node, nil
+	}
+	return nil, ErrCategoryNotFound
+}
+
+// CreateCategory adds a category, under ParentID when given
+func (s *MarketplaceService) CreateCategory(ctx context.Context, req *CreateCategoryRequest) (*Category, error) {
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := indexCategories(categories)
+
+	category := &Category{Name: strings.TrimSpace(req.Name), Description: req.Description, ReturnWindowHours: req.ReturnWindowHours}
+	if req.OwnerID != nil && *req.OwnerID != 0 {
+		if err := s.checkCategoryOwner(ctx, *req.OwnerID); err != nil {
+			return nil, err
+		}
+		category.OwnerID = req.OwnerID
+	}
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if _, ok := index[*req.ParentID]; !ok {
+			return nil, fmt.Errorf("%w: parent %d", ErrCategoryNotFound, *req.ParentID)
+		}
+		category.ParentID = req.ParentID
+	}
+	if category.Slug, err = s.categorySlug(ctx, req.Slug, category.Name, 0); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	s.InvalidateCategories()
+	return category, nil
+}
+
+// UpdateCategory renames or moves a category; it cannot be moved under itself
+// or one of its subcategories
+func (s *MarketplaceService) UpdateCategory(ctx context.Context, categoryID uint, req *UpdateCategoryRequest) (*Category, error) {
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := indexCategories(categories)
+	category, ok := index[categoryID]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+
+	updates := make(map[string]interface{})
+	if name := strings.TrimSpace(req.Name); name != "" {
+		category.Name = name
+		updates["name"] = name
+	}
+	if req.Slug != "" {
+		if category.Slug, err = s.categorySlug(ctx, req.Slug, "", categoryID); err != nil {
+			return nil, err
+		}
+		updates["slug"] = category.Slug
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+		updates["description"] = category.Description
+	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			if _, ok := index[*req.ParentID]; !ok {
+				return nil, fmt.Errorf("%w: parent %d", ErrCategoryNotFound, *req.ParentID)
+			}
+			// Walking up from the new parent must not reach the category itself
+			for id := req.ParentID; id != nil; id = index[*id].ParentID {
+				if *id == categoryID {
+					return nil, ErrCategoryCycle
+				}
+			}
+			category.ParentID = req.ParentID
+		}
+		updates["parent_id"] = category.ParentID
+	}
+	if req.ReturnWindowHours != nil {
+		category.ReturnWindowHours = req.ReturnWindowHours
+		if *req.ReturnWindowHours < 0 {
+			category.ReturnWindowHours = nil
+		}
+		updates["return_window_hours"] = category.ReturnWindowHours
+	}
+	if req.OwnerID != nil {
+		category.OwnerID = nil
+		if *req.OwnerID != 0 {
+			if err := s.checkCategoryOwner(ctx, *req.OwnerID); err != nil {
+				return nil, err
+			}
+			category.OwnerID = req.OwnerID
+		}
+		updates["owner_id"] = category.OwnerID
+	}
+
+	if len(updates) > 0 {
+		if err := s.repo.UpdateCategory(ctx, categoryID, updates); err != nil {
+			return nil, err
+		}
+		s.InvalidateCategories()
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category without subcategories; its products stay, without it
+func (s *MarketplaceService) DeleteCategory(ctx context.Context, categoryID uint) error {
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := indexCategories(categories)[categoryID]; !ok {
+		return ErrCategoryNotFound
+	}
+	for _, category := range categories {
+		if category.ParentID != nil && *category.ParentID == categoryID {
+			return ErrCategoryHasChildren
+		}
+	}
+	if err := s.repo.DeleteCategory(ctx, categoryID); err != nil {
+		return err
+	}
+	s.InvalidateCategories()
+	return nil
+}
+
+// setProductCategories replaces the categories of a product, after checking they exist
+func (s *MarketplaceService) setProductCategories(ctx context.Context, productID uint, categoryIDs []uint) error {
+	if err := s.checkCategories(ctx, categoryIDs); err != nil {
+		return err
+	}
+	return s.repo.ReplaceProductCategories(ctx, productID, uniqueIDs(categoryIDs))
+}
+
+func (s *MarketplaceService) checkCategories(ctx context.Context, categoryIDs []uint) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return err
+	}
+	index := indexCategories(categories)
+	for _, id := range categoryIDs {
+		if _, ok := index[id]; !ok {
+			return fmt.Errorf("%w: %d", ErrCategoryNotFound, id)
+		}
+	}
+	return nil
+}
+
+// categoryFilter resolves params.CategoryID to it and its subcategories
+func (s *MarketplaceService) categoryFilter(ctx context.Context, params *ProductListParams) error {
+	if params.CategoryID == 0 {
+		return nil
+	}
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return err
+	}
+	node := findCategoryNode(categoryTree(categories, nil), params.CategoryID)
+	if node == nil {
+		return ErrCategoryNotFound
+	}
+	params.CategoryIDs = nil
+	var collect func(n *CategoryNode)
+	collect = func(n *CategoryNode) {
+		params.CategoryIDs = append(params.CategoryIDs, n.ID)
+		for i := range n.Children {
+			collect(&n.Children[i])
+		}
+	}
+	collect(node)
+	return nil
+}
+
+// decorateProducts attaches the category trees and availability of products and
+// returns the status of the store, nil when no store hours are set
+func (s *MarketplaceService) decorateProducts(ctx context.Context, products []Product) (*StoreStatus, error) {
+	var assigned map[uint][]Category
+	if len(products) > 0 {
+		productIDs := make([]uint, len(products))
+		for i, product := range products {
+			productIDs[i] = product.ID
+		}
+		var err error
+		if assigned, err = s.repo.FindCategoriesByProductIDs(ctx, productIDs); err != nil {
+			return nil, err
+		}
+		if err := s.attachCategoryTrees(ctx, products, assigned); err != nil {
+			return nil, err
+		}
+	}
+	return s.attachAvailability(ctx, products, assigned)
+}
+
+// attachCategoryTrees sets the CategoryTree of products: their categories, as
+// assigned, and the parents of those, nested from the top level
+func (s *MarketplaceService) attachCategoryTrees(ctx context.Context, products []Product, assigned map[uint][]Category) error {
+	if len(assigned) == 0 {
+		return nil
+	}
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return err
+	}
+	index := indexCategories(categories)
+
+	for i := range products {
+		include := make(map[uint]bool)
+		for _, category := range assigned[products[i].ID] {
+			// The category and every parent up to the top level
+			for id := &category.ID; id != nil && !include[*id]; {
+				include[*id] = true
+				parent, ok := index[*id]
+				if !ok {
+					break
+				}
+				id = parent.ParentID
+			}
+		}
+		if len(include) > 0 {
+			products[i].CategoryTree = categoryTree(categories, include)
+		}
+	}
+	return nil
+}
+
+// categorySlug normalizes slug, or derives it from name when empty, and checks
+// no category but exceptID uses it
+func (s *MarketplaceService) categorySlug(ctx context.Context, slug, name string, exceptID uint) (string, error) {
+	if slug == "" {
+		slug = name
+	}
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(slug) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug = strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "", ErrCategorySlugInvalid
+	}
+
+	taken, err := s.repo.SlugTaken(ctx, slug, exceptID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", ErrCategorySlugTaken
+	}
+	return slug, nil
+}
+
+// checkCategoryOwner accepts an active admin of the campus of ctx
+func (s *MarketplaceService) checkCategoryOwner(ctx context.Context, userID uint) error {
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil || user.Role != "admin" || user.Status != "active" || !utils.InTenant(ctx, user.TenantID) {
+		return ErrCategoryOwner
+	}
+	return nil
+}
+
+// categoryOwners returns the admins who own the assigned categories: each
+// category's own owner or that of its nearest parent with one
+func categoryOwners(assigned []Category, index map[uint]*Category) []uint {
+	var owners []uint
+	for _, category := range assigned {
+		for id := &category.ID; id != nil && index[*id] != nil; id = index[*id].ParentID {
+			if owner := index[*id].OwnerID; owner != nil {
+				owners = append(owners, *owner)
+				break
+			}
+		}
+	}
+	return uniqueIDs(owners)
+}
+
+func indexCategories(categories []Category) map[uint]*Category {
+	index := make(map[uint]*Category, len(categories))
+	for i := range categories {
+		index[categories[i].ID] = &categories[i]
+	}
+	return index
+}
+
+// categoryTree nests categories under their parents, keeping their order.
+// include, when not nil, limits the tree to those categories; a category
+// whose parent is left out becomes a top-level node.
+func categoryTree(categories []Category, include map[uint]bool) []CategoryNode {
+	children := make(map[uint][]Category)
+	var roots []Category
+	index := indexCategories(categories)
+	for _, category := range categories {
+		if include != nil && !include[category.ID] {
+			continue
+		}
+		if category.ParentID == nil || index[*category.ParentID] == nil || (include != nil && !include[*category.ParentID]) {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var build func(list []Category) []CategoryNode
+	build = func(list []Category) []CategoryNode {
+		nodes := make([]CategoryNode, 0, len(list))
+		for _, category := range list {
+			nodes = append(nodes, CategoryNode{
+				ID:                category.ID,
+				Name:              category.Name,
+				Slug:              category.Slug,
+				Description:       category.Description,
+				ParentID:          category.ParentID,
+				ReturnWindowHours: category.ReturnWindowHours,
+				OwnerID:           category.OwnerID,
+				Children:          build(children[category.ID]),
+			})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+func findCategoryNode(nodes []CategoryNode, categoryID uint) *CategoryNode {
+	for i := range nodes {
+		if nodes[i].ID == categoryID {
+			return &nodes[i]
+		}
+		if node := findCategoryNode(nodes[i].Children, categoryID); node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,41 @@
+package marketplace
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrProductNotFound     = utils.NewAppError("PRODUCT_NOT_FOUND", http.StatusNotFound, "product not found")
+	ErrProductNotDeleted   = utils.NewAppError("PRODUCT_NOT_DELETED", http.StatusConflict, "product is not deleted")
+	ErrProductInactive     = utils.NewAppError("PRODUCT_INACTIVE", http.StatusBadRequest, "product is not active")
+	ErrProductOutOfStock   = utils.NewAppError("PRODUCT_OUT_OF_STOCK", http.StatusBadRequest, "product out of stock")
+	ErrInsufficientStock   = utils.NewAppError("PRODUCT_INSUFFICIENT_STOCK", http.StatusBadRequest, "insufficient stock")
+	ErrPrintPagesRequired  = utils.NewAppError("PRODUCT_PRINT_PAGES_REQUIRED", http.StatusBadRequest, "print quota products need print_pages")
+	ErrProductUnavailable  = utils.NewAppError("PRODUCT_UNAVAILABLE", http.StatusServiceUnavailable, "this kind of product cannot be delivered right now")
+	ErrSpendLimitReached   = utils.NewAppError("MARKETPLACE_DAILY_SPEND_LIMIT_REACHED", http.StatusBadRequest, "order would exceed your daily marketplace spending limit")
+	ErrPurchaseLimit       = utils.NewAppError("PRODUCT_PURCHASE_LIMIT_REACHED", http.StatusBadRequest, "order would exceed the product's purchase limit")
+	ErrTicketNotInCart     = utils.NewAppError("TICKET_NOT_IN_CART", http.StatusBadRequest, "tickets are bought for a session with a direct purchase, not through the cart")
+	ErrCartEmpty           = utils.NewAppError("CART_EMPTY", http.StatusBadRequest, "cart is empty")
+	ErrGiftToSelf          = utils.NewAppError("GIFT_TO_SELF", http.StatusBadRequest, "you cannot gift an order to yourself")
+	ErrGiftRecipient       = utils.NewAppError("GIFT_RECIPIENT_NOT_FOUND", http.StatusNotFound, "gift recipient is not an active student of this campus")
+	ErrStockAlertNotFound  = utils.NewAppError("STOCK_ALERT_NOT_FOUND", http.StatusNotFound, "stock alert not found")
+	ErrStockAlertResolved  = utils.NewAppError("STOCK_ALERT_RESOLVED", http.StatusConflict, "stock alert already resolved")
+	ErrCategoryNotFound    = utils.NewAppError("CATEGORY_NOT_FOUND", http.StatusNotFound, "category not found")
+	ErrCategorySlugTaken   = utils.NewAppError("CATEGORY_SLUG_TAKEN", http.StatusConflict, "another category of the campus already uses this slug")
+	ErrCategorySlugInvalid = utils.NewAppError("CATEGORY_SLUG_INVALID", http.StatusBadRequest, "category slug must contain letters or digits")
+	ErrCategoryCycle       = utils.NewAppError("CATEGORY_PARENT_CYCLE", http.StatusBadRequest, "a category cannot be placed under itself or one of its subcategories")
+	ErrCategoryHasChildren = utils.NewAppError("CATEGORY_HAS_CHILDREN", http.StatusConflict, "move or delete the subcategories first")
+	ErrCategoryOwner       = utils.NewAppError("CATEGORY_OWNER_INVALID", http.StatusBadRequest, "the category owner must be an active admin of the campus")
+	ErrStoreClosed         = utils.NewAppError("STORE_CLOSED", http.StatusConflict, "the store is closed")
+	ErrStoreHoursInvalid   = utils.NewAppError("STORE_HOURS_INVALID", http.StatusBadRequest, "opening hours must be HH:MM with closes after opens, one per weekday")
+	ErrReturnWindowClosed  = utils.NewAppError("RETURN_WINDOW_CLOSED", http.StatusConflict, "the order can no longer be cancelled or returned")
+	ErrQuestionNotFound    = utils.NewAppError("PRODUCT_QUESTION_NOT_FOUND", http.StatusNotFound, "product question not found")
+	ErrQuestionRejected    = utils.NewAppError("PRODUCT_QUESTION_REJECTED", http.StatusConflict, "product question was rejected")
+	ErrDuplicateRestock    = utils.NewAppError("RESTOCK_DUPLICATE_PRODUCT", http.StatusBadRequest, "a product may appear only once per restock")
+	ErrCreateProductFailed = utils.NewAppError("PRODUCT_CREATE_FAILED", http.StatusInternalServerError, "failed to create product")
+	ErrUpdateProductFailed = utils.NewAppError("PRODUCT_UPDATE_FAILED", http.StatusInternalServerError, "failed to update product")
+
+	ErrLiveUpdatesDisabled = utils.NewAppError("LIVE_UPDATES_DISABLED", http.StatusServiceUnavailable, "live updates are not enabled")
+	ErrLiveUpdatesFull     = utils.NewAppError("LIVE_UPDATES_FULL", http.StatusServiceUnavailable, "too many live update connections, try again later")
+)
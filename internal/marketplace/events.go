@@ -0,0 +1,132 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/events"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// SetOutbox publishes order.placed and stock.low domain events; their
+// subscribers send the order.created webhooks, audit entries and notifications
+func (s *MarketplaceService) SetOutbox(outbox *events.Outbox) {
+	s.outbox = outbox
+}
+
+// placedOrder describes a recorded order in an OrderPlaced event
+func placedOrder(txn *MarketplaceTransaction, productName string) events.Order {
+	return events.Order{
+		ID:            txn.ID,
+		WalletID:      txn.WalletID,
+		ProductID:     txn.ProductID,
+		ProductName:   productName,
+		Amount:        txn.Amount,
+		TotalAmount:   txn.TotalAmount,
+		Quantity:      txn.Quantity,
+		StudentName:   txn.StudentName,
+		StudentNPM:    txn.StudentNPM,
+		StudentMajor:  txn.StudentMajor,
+		StudentBatch:  txn.StudentBatch,
+		PaymentMethod: txn.PaymentMethod,
+		Status:        txn.Status,
+		RecipientID:   txn.RecipientID,
+		CreatedAt:     txn.CreatedAt,
+	}
+}
+
+// publishOrderPlaced publishes the orders of one purchase or checkout within its transaction
+func (s *MarketplaceService) publishOrderPlaced(tx *gorm.DB, order *orderState, orders []events.Order) error {
+	if s.outbox == nil {
+		return nil
+	}
+	total := 0
+	for _, placed := range orders {
+		total += placed.TotalAmount
+	}
+	return s.outbox.Publish(tx, events.EventOrderPlaced, events.OrderPlaced{
+		UserID:   order.UserID,
+		WalletID: order.WalletID,
+		Source:   order.Source,
+		Total:    total,
+		Orders:   orders,
+		Gift:     order.Gift,
+	})
+}
+
+// AuditOrderPlaced is the audit subscriber of order.placed: a PURCHASE_PRODUCT
+// entry for a single purchase, a CART_CHECKOUT entry for a checkout, both with
+// the origin of the request that placed them
+func AuditOrderPlaced(auditService audit.Logger) events.Handler {
+	return func(ctx context.Context, event *events.DomainEvent) error {
+		var placed events.OrderPlaced
+		if err := event.Decode(&placed); err != nil {
+			return err
+		}
+
+		params := audit.CreateAuditParams{
+			UserID:    placed.UserID,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+		}
+		if placed.Source == events.OrderSourceCheckout || len(placed.Orders) != 1 {
+			params.Action = "CART_CHECKOUT"
+			params.Entity = "WALLET"
+			params.EntityID = placed.UserID
+			params.Details = fmt.Sprintf("User completed checkout from cart: %d products for %d points", len(placed.Orders), placed.Total)
+		} else {
+			order := placed.Orders[0]
+			params.Action = "PURCHASE_PRODUCT"
+			params.Entity = "PRODUCT"
+			params.EntityID = order.ProductID
+			params.Details = fmt.Sprintf("User purchased %dx %s (product ID %d) for %d points", order.Quantity, order.ProductName, order.ProductID, order.TotalAmount)
+		}
+		if placed.Gift != nil {
+			params.Details += fmt.Sprintf(" as a gift to user %d (%s)", placed.Gift.RecipientID, placed.Gift.RecipientName)
+		}
+		return auditService.LogActivity(params)
+	}
+}
+
+// publishStockLow runs from CheckLowStock, after the stock change committed.
+// The event belongs to the product's campus and names the owners of the
+// product's categories, who get the alert.
+func (s *MarketplaceService) publishStockLow(product *Product, threshold int) error {
+	ctx := utils.WithTenant(context.Background(), product.TenantID)
+	owners, err := s.stockAlertOwners(ctx, product.ID)
+	if err != nil {
+		slog.Error("stock alert: loading category owners failed", "product_id", product.ID, "error", err)
+		return err
+	}
+	err = utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		return s.outbox.Publish(tx, events.EventStockLow, events.StockLow{
+			TenantID:    product.TenantID,
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Stock:       product.Stock,
+			Threshold:   threshold,
+			OwnerID:     product.CreatedBy,
+
+			CategoryOwnerIDs: owners,
+		})
+	})
+	if err != nil {
+		slog.Error("stock alert: publishing stock.low failed", "product_id", product.ID, "error", err)
+	}
+	return err
+}
+
+func (s *MarketplaceService) stockAlertOwners(ctx context.Context, productID uint) ([]uint, error) {
+	assigned, err := s.repo.FindCategoriesByProductIDs(ctx, []uint{productID})
+	if err != nil || len(assigned[productID]) == 0 {
+		return nil, err
+	}
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return categoryOwners(assigned[productID], indexCategories(categories)), nil
+}
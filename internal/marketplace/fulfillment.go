@@ -0,0 +1,69 @@
+package marketplace
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Product types. Physical products are queued for the store staff to hand over
+// (see internal/fulfillment); the others are delivered by the Fulfiller
+// registered for the type.
+const (
+	ProductPhysical   = "physical"
+	ProductPrintQuota = "print_quota"
+	ProductTicket     = "ticket" // bought for one session, see internal/ticketing
+)
+
+// Fulfiller delivers purchases of a product type outside the wallet, e.g. by
+// calling a campus system. Fulfill runs in the transaction of the order saga's
+// last step: an error there rolls back the stock, debit and order, but nothing
+// Fulfill did outside tx, so it should only record or queue the delivery. A
+// delivery that ultimately fails is the fulfiller's to compensate.
+type Fulfiller interface {
+	Fulfill(tx *gorm.DB, userID uint, product *Product, order *MarketplaceTransaction) error
+}
+
+// OrderChecker is implemented by fulfillers that can refuse an order before
+// it is paid, e.g. for a sold out session. Fulfill must still check, as the
+// order saga runs later.
+type OrderChecker interface {
+	CheckOrder(ctx context.Context, userID uint, product *Product, quantity int, sessionID uint) error
+}
+
+// RegisterFulfiller delivers purchases of productType; products of a type
+// without a fulfiller cannot be bought
+func (s *MarketplaceService) RegisterFulfiller(productType string, fulfiller Fulfiller) {
+	if s.fulfillers == nil {
+		s.fulfillers = make(map[string]Fulfiller)
+	}
+	s.fulfillers[productType] = fulfiller
+}
+
+// checkDeliverable refuses products whose type has no fulfiller
+func (s *MarketplaceService) checkDeliverable(product *Product) error {
+	if product.Type == "" || product.Type == ProductPhysical {
+		return nil
+	}
+	if _, ok := s.fulfillers[product.Type]; !ok {
+		return ErrProductUnavailable
+	}
+	return nil
+}
+
+// checkOrder asks the fulfiller of product whether userID can receive quantity of it
+func (s *MarketplaceService) checkOrder(ctx context.Context, userID uint, product *Product, quantity int, sessionID uint) error {
+	if checker, ok := s.fulfillers[product.Type].(OrderChecker); ok {
+		return checker.CheckOrder(ctx, userID, product, quantity, sessionID)
+	}
+	return nil
+}
+
+// fulfill hands a recorded order to the fulfiller of its product type
+func (s *MarketplaceService) fulfill(tx *gorm.DB, userID uint, product *Product, order *MarketplaceTransaction) error {
+	fulfiller, ok := s.fulfillers[product.Type]
+	if !ok {
+		return nil
+	}
+	return fulfiller.Fulfill(tx, userID, product, order)
+}
@@ -0,0 +1,52 @@
+package marketplace
+
+import (
+	"context"
+	"wallet-point/internal/events"
+	"wallet-point/utils"
+)
+
+// giftFor resolves the recipient of a purchase or checkout. No recipient means
+// no gift; otherwise it must be an active student of the buyer's campus other
+// than the buyer.
+func (s *MarketplaceService) giftFor(ctx context.Context, buyerID, recipientID uint) (*events.Gift, error) {
+	if recipientID == 0 {
+		return nil, nil
+	}
+	if recipientID == buyerID {
+		return nil, ErrGiftToSelf
+	}
+
+	recipient, err := s.repo.FindActiveUser(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil || recipient.Role != "mahasiswa" {
+		return nil, ErrGiftRecipient
+	}
+	gift := &events.Gift{RecipientID: recipient.ID, RecipientName: recipient.FullName}
+
+	buyer, err := s.repo.FindActiveUser(ctx, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if buyer != nil {
+		gift.SenderName = buyer.FullName
+	}
+	return gift, nil
+}
+
+// GetReceivedGifts lists the orders other students gifted to userID, newest first
+func (s *MarketplaceService) GetReceivedGifts(ctx context.Context, userID uint, page, limit int) (*GiftListResponse, error) {
+	gifts, total, err := s.repo.FindReceivedGifts(ctx, userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if gifts == nil {
+		gifts = []MarketplaceTransactionWithDetails{}
+	}
+	return &GiftListResponse{
+		Gifts:      gifts,
+		Pagination: utils.NewPagination(page, limit, total, ""),
+	}, nil
+}
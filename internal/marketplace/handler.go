@@ -1,9 +1,14 @@
 package marketplace
 
 import (
+	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 	"wallet-point/internal/audit"
+	"wallet-point/internal/storage"
 	"wallet-point/utils"
 
 	"fmt"
@@ -13,40 +18,126 @@ import (
 
 type MarketplaceHandler struct {
 	service      *MarketplaceService
-	auditService *audit.AuditService
+	files        storage.Storage
+	auditService audit.Logger
 }
 
-func NewMarketplaceHandler(service *MarketplaceService, auditService *audit.AuditService) *MarketplaceHandler {
-	return &MarketplaceHandler{service: service, auditService: auditService}
+func NewMarketplaceHandler(service *MarketplaceService, files storage.Storage, auditService audit.Logger) *MarketplaceHandler {
+	return &MarketplaceHandler{service: service, files: files, auditService: auditService}
 }
 
 // GetAll handles getting all products
+// @Summary Get products
+// @Description Get products with pagination. Mahasiswa only see active products. When store hours are set, store tells whether the store is open and each product's availability whether it can be bought now.
+// @Tags Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status (admin only)" Enums(active, inactive)
+// @Param category_id query int false "Only products of this category or one of its subcategories"
+// @Param X-Store header string false "Code of a faculty store: only products of its catalog"
+// @Param include_deleted query bool false "Also list soft-deleted products (admin only)"
+// @Param fields query string false "Comma-separated product fields to return, e.g. id,name,price,stock (default all)"
+// @Param cursor query string false "Opaque cursor from next_cursor; takes precedence over page"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Param If-None-Match header string false "ETag of a previous response; 304 is returned while it is unchanged"
+// @Success 200 {object} utils.Response{data=ProductListResponse}
+// @Header 200 {string} ETag "Weak validator of the response body"
+// @Success 304 "Not modified"
+// @Failure 404 {object} utils.Response "Unknown or inactive faculty store"
+// @Router /admin/products [get]
+// @Router /mahasiswa/marketplace/products [get]
 func (h *MarketplaceHandler) GetAll(c *gin.Context) {
-	status := c.Query("status")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	status, ok := utils.QueryEnum(c, "status", "product_status")
+	if !ok {
+		return
+	}
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	cursor, err := utils.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cursor", nil)
+		return
+	}
+
+	fields, ok := utils.FieldsQuery(c, ProductFields)
+	if !ok {
+		return
+	}
+	categoryID, ok := utils.QueryInt(c, "category_id", 0, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
 
 	role, _ := c.Get("role")
 	if role == "mahasiswa" {
 		status = "active"
+		includeDeleted = false
+	}
+
+	// A faculty store is picked by the /stores/{store}/products path or the X-Store header
+	store := c.Param("store")
+	if store == "" {
+		store = c.GetHeader("X-Store")
 	}
 
 	params := ProductListParams{
-		Status: status,
-		Page:   page,
-		Limit:  limit,
+		Status:         status,
+		CategoryID:     uint(categoryID),
+		Store:          store,
+		IncludeDeleted: includeDeleted,
+		Fields:         fields,
+		Cursor:         cursor,
+		Page:           page,
+		Limit:          limit,
 	}
 
-	response, err := h.service.GetAllProducts(params)
+	response, err := h.service.GetAllProducts(c.Request.Context(), params)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve products", err.Error())
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Products retrieved successfully", response)
+	utils.ListResponse(c, "Products retrieved successfully", "products", fields.Filter(response.Products), response.Pagination, response.Meta())
+}
+
+// GetStoreProducts handles listing the catalog of a faculty store
+// @Summary Get faculty store products
+// @Description The products of a faculty store's catalog, as Get products with the X-Store header. Students see active products only; they are bought with the usual purchase and checkout endpoints.
+// @Tags Faculty Stores
+// @Security BearerAuth
+// @Produce json
+// @Param store path string true "Store code"
+// @Param category_id query int false "Only products of this category or one of its subcategories"
+// @Param fields query string false "Comma-separated product fields to return, e.g. id,name,price,stock (default all)"
+// @Param cursor query string false "Opaque cursor from next_cursor; takes precedence over page"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=ProductListResponse}
+// @Failure 404 {object} utils.Response
+// @Router /stores/{store}/products [get]
+func (h *MarketplaceHandler) GetStoreProducts(c *gin.Context) {
+	h.GetAll(c)
 }
 
 // GetByID handles getting product by ID
+// @Summary Get product by ID
+// @Description The product with its most helpful answered questions in top_questions
+// @Tags Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param If-None-Match header string false "ETag of a previous response; 304 is returned while it is unchanged"
+// @Success 200 {object} utils.Response{data=ProductDetail}
+// @Header 200 {string} ETag "Weak validator of the response body"
+// @Success 304 "Not modified"
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id} [get]
+// @Router /mahasiswa/marketplace/products/{id} [get]
 func (h *MarketplaceHandler) GetByID(c *gin.Context) {
 	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -54,9 +145,9 @@ func (h *MarketplaceHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	product, err := h.service.GetProductByID(uint(productID))
+	product, err := h.service.GetProductDetail(c.Request.Context(), uint(productID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusNotFound, err)
 		return
 	}
 
@@ -64,7 +155,25 @@ func (h *MarketplaceHandler) GetByID(c *gin.Context) {
 }
 
 // Create handles creating new product
-// Create handles creating new product
+// @Summary Create product
+// @Description Create a product; the image can be uploaded as a file or given as a URL (Admin only)
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param name formData string true "Product name"
+// @Param description formData string false "Description"
+// @Param price formData int true "Price in points"
+// @Param stock formData int true "Initial stock"
+// @Param low_stock_threshold formData int false "Low-stock alert threshold"
+// @Param purchase_limit formData int false "Units one student may buy in total; 0 means no limit"
+// @Param pickup_location formData string false "Where orders are collected, e.g. Koperasi Gedung A; empty is the main store"
+// @Param category_ids formData string false "Comma-separated category IDs, e.g. 3,7"
+// @Param image formData file false "Product image"
+// @Param image_url formData string false "Image URL, used when no file is uploaded"
+// @Success 201 {object} utils.Response{data=Product}
+// @Failure 400 {object} utils.Response
+// @Router /admin/products [post]
 func (h *MarketplaceHandler) Create(c *gin.Context) {
 	adminID := c.GetUint("user_id")
 
@@ -81,41 +190,52 @@ func (h *MarketplaceHandler) Create(c *gin.Context) {
 
 	price, _ := strconv.Atoi(priceStr)
 	stock, _ := strconv.Atoi(stockStr)
+	lowStockThreshold, _ := strconv.Atoi(c.PostForm("low_stock_threshold"))
+	purchaseLimit, _ := strconv.Atoi(c.PostForm("purchase_limit"))
 
 	// Handle Image Upload
 	var imageURL string
 	file, err := c.FormFile("image")
 	if err == nil {
-		// Validate file type and size if needed
-		filename := fmt.Sprintf("%d_%s", adminID, file.Filename)
-		savePath := "./public/uploads/" + filename
-		if err := c.SaveUploadedFile(file, savePath); err != nil {
+		// URL accessible from frontend
+		imageURL, err = storage.SaveUpload(c.Request.Context(), h.files, storage.PrefixProducts, file)
+		if err != nil {
 			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save image", err.Error())
 			return
 		}
-		// URL accessible from frontend
-		imageURL = "/uploads/" + filename
 	} else if c.PostForm("image_url") != "" {
 		// Fallback to URL if string is provided
 		imageURL = c.PostForm("image_url")
 	}
 
 	req := CreateProductRequest{
-		Name:        name,
-		Description: description,
-		Price:       price,
-		Stock:       stock,
-		ImageURL:    imageURL,
+		Name:              name,
+		Description:       description,
+		Price:             price,
+		Stock:             stock,
+		ImageURL:          imageURL,
+		LowStockThreshold: lowStockThreshold,
+		PurchaseLimit:     purchaseLimit,
+		PickupLocation:    c.PostForm("pickup_location"),
+	}
+	categoryIDs, ok := parseCategoryIDs(c, c.PostForm("category_ids"))
+	if !ok {
+		return
 	}
+	req.CategoryIDs = categoryIDs
 
 	if req.Name == "" || req.Price <= 0 {
 		utils.ValidationErrorResponse(c, "Name and valid Price are required")
 		return
 	}
+	if len(req.PickupLocation) > 100 {
+		utils.ValidationErrorResponse(c, "pickup_location must be at most 100 characters")
+		return
+	}
 
-	product, err := h.service.CreateProduct(&req, adminID)
+	product, err := h.service.CreateProduct(c.Request.Context(), &req, adminID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -133,6 +253,28 @@ func (h *MarketplaceHandler) Create(c *gin.Context) {
 }
 
 // Update handles updating product
+// @Summary Update product
+// @Description Update product fields; omitted fields are left unchanged (Admin only)
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param name formData string false "Product name"
+// @Param description formData string false "Description"
+// @Param price formData int false "Price in points"
+// @Param stock formData int false "Stock"
+// @Param status formData string false "Status" Enums(active, inactive)
+// @Param low_stock_threshold formData int false "Low-stock alert threshold"
+// @Param purchase_limit formData int false "Units one student may buy in total; 0 means no limit"
+// @Param pickup_location formData string false "Where orders are collected; sent empty to reset to the main store"
+// @Param category_ids formData string false "Comma-separated category IDs replacing the product's categories; sent empty to remove them all"
+// @Param image formData file false "Product image"
+// @Param image_url formData string false "Image URL, used when no file is uploaded"
+// @Success 200 {object} utils.Response{data=Product}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id} [put]
 func (h *MarketplaceHandler) Update(c *gin.Context) {
 	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -156,13 +298,11 @@ func (h *MarketplaceHandler) Update(c *gin.Context) {
 	var imageURL string
 	file, err := c.FormFile("image")
 	if err == nil {
-		filename := fmt.Sprintf("update_%d_%s", productID, file.Filename)
-		savePath := "./public/uploads/" + filename
-		if err := c.SaveUploadedFile(file, savePath); err != nil {
+		imageURL, err = storage.SaveUpload(c.Request.Context(), h.files, storage.PrefixProducts, file)
+		if err != nil {
 			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save image", err.Error())
 			return
 		}
-		imageURL = "/uploads/" + filename
 	} else {
 		// Check if image_url is provided as string (e.g. keeping existing)
 		imageURL = c.PostForm("image_url")
@@ -176,32 +316,114 @@ func (h *MarketplaceHandler) Update(c *gin.Context) {
 		ImageURL:    imageURL,
 		Status:      status,
 	}
+	if thresholdStr := c.PostForm("low_stock_threshold"); thresholdStr != "" {
+		threshold, err := strconv.Atoi(thresholdStr)
+		if err != nil || threshold < 0 {
+			utils.ValidationErrorResponse(c, "low_stock_threshold must be a non-negative number")
+			return
+		}
+		req.LowStockThreshold = &threshold
+	}
+	if limitStr := c.PostForm("purchase_limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			utils.ValidationErrorResponse(c, "purchase_limit must be a non-negative number")
+			return
+		}
+		req.PurchaseLimit = &limit
+	}
+	if location, ok := c.GetPostForm("pickup_location"); ok {
+		if len(location) > 100 {
+			utils.ValidationErrorResponse(c, "pickup_location must be at most 100 characters")
+			return
+		}
+		req.PickupLocation = &location
+	}
+	if raw, ok := c.GetPostForm("category_ids"); ok {
+		categoryIDs, ok := parseCategoryIDs(c, raw)
+		if !ok {
+			return
+		}
+		req.CategoryIDs = &categoryIDs
+	}
 
-	product, err := h.service.UpdateProduct(uint(productID), &req)
+	product, err := h.service.UpdateProduct(c.Request.Context(), uint(productID), &req)
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "product not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Product updated successfully", product)
 
+	details := "Admin updated product: " + product.Name
+	if req.Price > 0 {
+		// Counted by the admin activity watcher, see internal/adminwatch
+		details += " | Price: " + strconv.Itoa(req.Price)
+	}
 	adminID := c.GetUint("user_id")
 	h.auditService.LogActivity(audit.CreateAuditParams{
 		UserID:    adminID,
 		Action:    "UPDATE_PRODUCT",
 		Entity:    "PRODUCT",
 		EntityID:  product.ID,
-		Details:   "Admin updated product: " + product.Name,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Restock handles adding stock to several products at once
+// @Summary Restock products
+// @Description Add stock to up to 100 products in one atomic operation: every item is applied with an inventory ledger entry, or none is. Students with a sold-out product in their cart are notified that it is back in stock. (Admin only)
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RestockRequest true "Products and quantities to add"
+// @Success 200 {object} utils.Response{data=RestockResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/restock [post]
+func (h *MarketplaceHandler) Restock(c *gin.Context) {
+	var req RestockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	entries, err := h.service.RestockProducts(c.Request.Context(), req.Items, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Products restocked successfully", RestockResponse{Entries: entries})
+
+	units := 0
+	for _, entry := range entries {
+		units += entry.Change
+	}
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "RESTOCK_PRODUCTS",
+		Entity:    "PRODUCT",
+		Details:   fmt.Sprintf("Admin restocked %d products with %d units in total", len(entries), units),
 		IPAddress: c.ClientIP(),
 		UserAgent: c.Request.UserAgent(),
 	})
 }
 
 // Delete handles deleting product
+// @Summary Delete product
+// @Description Soft delete a product; it is hidden everywhere, removed from carts, and can be restored later
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id} [delete]
 func (h *MarketplaceHandler) Delete(c *gin.Context) {
 	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -209,12 +431,8 @@ func (h *MarketplaceHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteProduct(uint(productID)); err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "product not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+	if err := h.service.DeleteProduct(c.Request.Context(), uint(productID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -232,133 +450,938 @@ func (h *MarketplaceHandler) Delete(c *gin.Context) {
 	})
 }
 
+// Restore handles restoring a deleted product
+// @Summary Restore product
+// @Description Restore a soft-deleted product; cart items removed by the delete are not brought back
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.Response{data=Product}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/products/{id}/restore [post]
+func (h *MarketplaceHandler) Restore(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	product, err := h.service.RestoreProduct(c.Request.Context(), uint(productID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product restored successfully", product)
+
+	adminID := c.GetUint("user_id")
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "RESTORE_PRODUCT",
+		Entity:    "PRODUCT",
+		EntityID:  product.ID,
+		Details:   "Admin restored product: " + product.Name,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
 // Purchase handles product purchase
+// @Summary Purchase product
+// @Description Buy a single product directly with wallet points. With recipient_user_id the order is a gift: the points come from your wallet, the order is issued to that student and both of you are notified.
+// @Tags Mahasiswa - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Makes retries safe: a repeated request with the same key and body returns the first response (Idempotent-Replayed: true) instead of charging again"
+// @Param request body PurchaseRequest true "Purchase details"
+// @Success 200 {object} utils.Response
+// @Header 200 {string} Idempotent-Replayed "true when the response is a replay of an earlier request with the same Idempotency-Key"
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response "A request with the same Idempotency-Key is still running"
+// @Failure 422 {object} utils.Response "Idempotency-Key was used for a different request"
+// @Router /mahasiswa/marketplace/purchase [post]
 func (h *MarketplaceHandler) Purchase(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	var req PurchaseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	err := h.service.PurchaseProduct(userID, &req)
+	err := h.service.PurchaseProduct(c.Request.Context(), userID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
+	// Audited by the order.placed subscriber (see AuditOrderPlaced)
 	utils.SuccessResponse(c, http.StatusOK, "Purchase successful", nil)
-
-	h.auditService.LogActivity(audit.CreateAuditParams{
-		UserID:    userID,
-		Action:    "PURCHASE_PRODUCT",
-		Entity:    "PRODUCT",
-		EntityID:  req.ProductID,
-		Details:   fmt.Sprintf("User purchased units of product ID %d", req.ProductID),
-		IPAddress: c.ClientIP(),
-		UserAgent: c.Request.UserAgent(),
-	})
 }
 
 // GetTransactions handles getting all marketplace transactions from consolidated wallet_transactions
+// @Summary Get marketplace transactions
+// @Description Get all marketplace purchases (Admin only)
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param fields query string false "Comma-separated order fields to return, e.g. id,product_name,total_amount,created_at (default all)"
+// @Param cursor query string false "Opaque cursor from next_cursor; takes precedence over page"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=object{transactions=[]MarketplaceTransactionWithDetails,total=int,limit=int,page=int,next_cursor=string}}
+// @Router /admin/marketplace/transactions [get]
 func (h *MarketplaceHandler) GetTransactions(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	cursor, err := utils.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cursor", nil)
+		return
+	}
+
+	fields, ok := utils.FieldsQuery(c, TransactionFields)
+	if !ok {
+		return
+	}
 
-	transactions, total, err := h.service.GetTransactions(limit, page)
+	transactions, total, err := h.service.GetTransactions(c.Request.Context(), cursor, fields, limit, page)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Marketplace transactions retrieved", gin.H{
-		"transactions": transactions,
-		"total":        total,
-		"limit":        limit,
-		"page":         page,
+	nextCursor := utils.NextCursor(transactions, limit, func(t MarketplaceTransactionWithDetails) (time.Time, uint) {
+		return t.CreatedAt, t.ID
 	})
+
+	utils.ListResponse(c, "Marketplace transactions retrieved", "transactions", fields.Filter(transactions),
+		utils.NewPagination(page, limit, total, nextCursor), nil)
 }
 
+// GetCart handles getting the current user's cart
+// @Summary Get cart
+// @Tags Mahasiswa - Cart
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=CartResponse}
+// @Router /mahasiswa/marketplace/cart [get]
 func (h *MarketplaceHandler) GetCart(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	cartResponse, err := h.service.GetCart(userID)
+	cartResponse, err := h.service.GetCart(c.Request.Context(), userID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
-	utils.SuccessResponse(c, http.StatusOK, "Keranjang berhasil diambil", gin.H{
-		"items":       cartResponse.Items,
-		"total_price": cartResponse.TotalPrice,
-	})
+	utils.SuccessResponse(c, http.StatusOK, "Cart retrieved successfully", cartResponse)
 }
 
+// AddToCart handles adding a product to the cart
+// @Summary Add to cart
+// @Tags Mahasiswa - Cart
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body AddToCartRequest true "Cart item"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/marketplace/cart [post]
 func (h *MarketplaceHandler) AddToCart(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	var req AddToCartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	fmt.Printf("DEBUG: Adding to cart - UserID: %d, ProductID: %d, Quantity: %d\n", userID, req.ProductID, req.Quantity)
+	slog.DebugContext(c.Request.Context(), "adding to cart", "user_id", userID, "product_id", req.ProductID, "quantity", req.Quantity)
 
-	if err := h.service.AddToCart(userID, req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	if err := h.service.AddToCart(c.Request.Context(), userID, req); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
-	utils.SuccessResponse(c, http.StatusOK, "Produk berhasil ditambahkan ke keranjang", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Product added to cart", nil)
 }
 
+// UpdateCartItem handles changing the quantity of a cart item
+// @Summary Update cart item
+// @Tags Mahasiswa - Cart
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Cart item ID"
+// @Param request body UpdateCartRequest true "New quantity"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/marketplace/cart/{id} [put]
 func (h *MarketplaceHandler) UpdateCartItem(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	itemID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 	var req UpdateCartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	if err := h.service.UpdateCartItem(userID, uint(itemID), req.Quantity); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
-	utils.SuccessResponse(c, http.StatusOK, "Keranjang berhasil diperbarui", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Cart updated successfully", nil)
 }
 
+// RemoveFromCart handles removing an item from the cart
+// @Summary Remove from cart
+// @Tags Mahasiswa - Cart
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Cart item ID"
+// @Success 200 {object} utils.Response
+// @Router /mahasiswa/marketplace/cart/{id} [delete]
 func (h *MarketplaceHandler) RemoveFromCart(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	itemID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 
 	if err := h.service.RemoveFromCart(userID, uint(itemID)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
-	utils.SuccessResponse(c, http.StatusOK, "Produk berhasil dihapus dari keranjang", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Product removed from cart", nil)
 }
 
+// QuoteCheckout handles a checkout pre-flight
+// @Summary Quote cart checkout
+// @Description What checking out the cart would charge now: total is the exact amount, after the running promotions, and issues lists everything that would make the checkout fail (cart empty, stock, store hours, balance, spending and purchase limits, gift recipient), each with the error code checkout would return. Nothing is changed and no PIN is needed, so confirm screens can call it freely.
+// @Tags Mahasiswa - Cart
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CartQuoteRequest false "Gift recipient, when quoting a gift"
+// @Success 200 {object} utils.Response{data=CartQuote}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/marketplace/cart/quote [post]
+func (h *MarketplaceHandler) QuoteCheckout(c *gin.Context) {
+	var req CartQuoteRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BindingErrorResponse(c, err)
+			return
+		}
+	}
+
+	quote, err := h.service.QuoteCheckout(c.Request.Context(), c.GetUint("user_id"), req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	for i := range quote.Issues {
+		quote.Issues[i].Message = utils.TranslateError(c, quote.Issues[i].err)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Checkout quoted", quote)
+}
+
+// Checkout handles paying for all items in the cart
+// @Summary Checkout cart
+// @Description Pay for every item in the cart with wallet points, less the discount of the running promotions (as shown by Get cart and Quote cart checkout). With recipient_user_id the orders are a gift: the points come from your wallet, the orders are issued to that student and both of you are notified.
+// @Tags Mahasiswa - Cart
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Makes retries safe: a repeated request with the same key and body returns the first response (Idempotent-Replayed: true) instead of charging again"
+// @Param request body CartCheckoutRequest true "Checkout details"
+// @Success 200 {object} utils.Response
+// @Header 200 {string} Idempotent-Replayed "true when the response is a replay of an earlier request with the same Idempotency-Key"
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response "A request with the same Idempotency-Key is still running"
+// @Failure 422 {object} utils.Response "Idempotency-Key was used for a different request"
+// @Router /mahasiswa/marketplace/cart/checkout [post]
 func (h *MarketplaceHandler) Checkout(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	var req CartCheckoutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.service.Checkout(c.Request.Context(), userID, req); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Checkout successful", nil)
+}
+
+// GetGifts handles listing the orders gifted to the student
+// @Summary Get received gifts
+// @Description Orders other students bought for you; user_name is the buyer
+// @Tags Mahasiswa - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=GiftListResponse}
+// @Router /mahasiswa/marketplace/gifts [get]
+func (h *MarketplaceHandler) GetGifts(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetReceivedGifts(c.Request.Context(), c.GetUint("user_id"), page, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve gifts", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Gifts retrieved successfully", "gifts", response.Gifts, response.Pagination, nil)
+}
+
+// GetStockAlerts handles listing low-stock alerts (Admin)
+// @Summary Get stock alerts
+// @Description Get low-stock alerts (Admin only)
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(open, acknowledged, snoozed, resolved)
+// @Success 200 {object} utils.Response{data=[]StockAlertWithProduct}
+// @Router /admin/stock-alerts [get]
+func (h *MarketplaceHandler) GetStockAlerts(c *gin.Context) {
+	status, ok := utils.QueryEnum(c, "status", "stock_alert_status")
+	if !ok {
+		return
+	}
+
+	alerts, err := h.service.GetStockAlerts(status)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve stock alerts", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock alerts retrieved successfully", alerts)
+}
+
+// AcknowledgeStockAlert handles acknowledging a low-stock alert (Admin)
+// @Summary Acknowledge stock alert
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Stock alert ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/stock-alerts/{id}/acknowledge [post]
+func (h *MarketplaceHandler) AcknowledgeStockAlert(c *gin.Context) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid alert ID", nil)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	if err := h.service.AcknowledgeStockAlert(uint(alertID), adminID); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock alert acknowledged", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "ACKNOWLEDGE_STOCK_ALERT",
+		Entity:    "STOCK_ALERT",
+		EntityID:  uint(alertID),
+		Details:   "Admin acknowledged low-stock alert",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// SnoozeStockAlert handles snoozing a low-stock alert (Admin)
+// @Summary Snooze stock alert
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Stock alert ID"
+// @Param request body SnoozeStockAlertRequest true "Snooze duration"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/stock-alerts/{id}/snooze [post]
+func (h *MarketplaceHandler) SnoozeStockAlert(c *gin.Context) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid alert ID", nil)
+		return
+	}
+
+	var req SnoozeStockAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.service.SnoozeStockAlert(uint(alertID), req.Hours); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock alert snoozed", nil)
+
+	adminID := c.GetUint("user_id")
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "SNOOZE_STOCK_ALERT",
+		Entity:    "STOCK_ALERT",
+		EntityID:  uint(alertID),
+		Details:   fmt.Sprintf("Admin snoozed low-stock alert for %d hours", req.Hours),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetProductQuestions handles listing the questions on a product page (Mahasiswa)
+// @Summary Get product questions
+// @Description Answered questions on the product, most helpful first, and your own questions whatever their status
+// @Tags Mahasiswa - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=QuestionListResponse}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/products/{id}/questions [get]
+func (h *MarketplaceHandler) GetProductQuestions(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetQuestions(c.Request.Context(), QuestionListParams{
+		ProductID: uint(productID),
+		ViewerID:  c.GetUint("user_id"),
+		Page:      page,
+		Limit:     limit,
+	})
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.ListResponse(c, "Product questions retrieved successfully", "questions", response.Questions, response.Pagination, nil)
+}
+
+// AskQuestion handles asking a question on a product page (Mahasiswa)
+// @Summary Ask product question
+// @Description The question is shown to other students once an admin answered it; you are notified of the answer
+// @Tags Mahasiswa - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body AskQuestionRequest true "Question"
+// @Success 201 {object} utils.Response{data=ProductQuestion}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/products/{id}/questions [post]
+func (h *MarketplaceHandler) AskQuestion(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	var req AskQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	question, err := h.service.AskQuestion(c.Request.Context(), uint(productID), c.GetUint("user_id"), &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Question submitted", question)
+}
+
+// MarkQuestionHelpful handles voting for an answered question (Mahasiswa)
+// @Summary Mark question helpful
+// @Description Counts once per student; the most helpful questions are shown on the product detail
+// @Tags Mahasiswa - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Question ID"
+// @Success 200 {object} utils.Response{data=ProductQuestion}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/questions/{id}/helpful [post]
+func (h *MarketplaceHandler) MarkQuestionHelpful(c *gin.Context) {
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid question ID", nil)
+		return
+	}
+
+	question, err := h.service.MarkQuestionHelpful(c.Request.Context(), uint(questionID), c.GetUint("user_id"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Question marked helpful", question)
+}
+
+// GetQuestions handles listing product questions to moderate (Admin)
+// @Summary Get product questions
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, published, rejected)
+// @Param product_id query int false "Filter by product"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=QuestionListResponse}
+// @Router /admin/product-questions [get]
+func (h *MarketplaceHandler) GetQuestions(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "product_question_status")
+	if !ok {
+		return
+	}
+	productID, ok := utils.QueryInt(c, "product_id", 0, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetQuestions(c.Request.Context(), QuestionListParams{
+		ProductID: uint(productID),
+		Status:    status,
+		Page:      page,
+		Limit:     limit,
+	})
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.ListResponse(c, "Product questions retrieved successfully", "questions", response.Questions, response.Pagination, nil)
+}
+
+// AnswerQuestion handles answering a product question (Admin)
+// @Summary Answer product question
+// @Description Publishes the question with the answer; the asker is notified of the first answer, answering again corrects it
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param request body AnswerQuestionRequest true "Answer"
+// @Success 200 {object} utils.Response{data=ProductQuestion}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/product-questions/{id}/answer [post]
+func (h *MarketplaceHandler) AnswerQuestion(c *gin.Context) {
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid question ID", nil)
+		return
+	}
+
+	var req AnswerQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	question, err := h.service.AnswerQuestion(c.Request.Context(), uint(questionID), adminID, &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Question answered", question)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "ANSWER_PRODUCT_QUESTION",
+		Entity:    "PRODUCT_QUESTION",
+		EntityID:  question.ID,
+		Details:   fmt.Sprintf("Admin answered a question on product %d", question.ProductID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// RejectQuestion handles hiding a product question (Admin)
+// @Summary Reject product question
+// @Description Hides a question that breaks the rules, also one already answered; the asker still sees it with the reason
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param request body RejectQuestionRequest true "Reason"
+// @Success 200 {object} utils.Response{data=ProductQuestion}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/product-questions/{id}/reject [post]
+func (h *MarketplaceHandler) RejectQuestion(c *gin.Context) {
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid question ID", nil)
+		return
+	}
+
+	var req RejectQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	question, err := h.service.RejectQuestion(c.Request.Context(), uint(questionID), &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Question rejected", question)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "REJECT_PRODUCT_QUESTION",
+		Entity:    "PRODUCT_QUESTION",
+		EntityID:  question.ID,
+		Details:   fmt.Sprintf("Admin rejected a question on product %d | Reason: %s", question.ProductID, req.Reason),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetStoreHours handles listing the opening schedules (Admin)
+// @Summary Get store hours
+// @Description The weekly schedule of the store and of each category with its own, with whether each is open now. Without a store schedule the store is always open; products of a category with a schedule, or under a parent category with one, follow that schedule instead.
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]StoreSchedule}
+// @Router /admin/store-hours [get]
+func (h *MarketplaceHandler) GetStoreHours(c *gin.Context) {
+	schedules, err := h.service.GetStoreHours(c.Request.Context())
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Store hours retrieved successfully", schedules)
+}
+
+// SetStoreHours handles replacing an opening schedule (Admin)
+// @Summary Set store hours
+// @Description Replace the weekly schedule of the store, or of category_id. Weekdays left out are closed; an empty days list removes the schedule. Purchases and checkouts outside the hours are rejected with STORE_CLOSED and the next opening time.
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SetStoreHoursRequest true "Schedule"
+// @Success 200 {object} utils.Response{data=StoreSchedule}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/store-hours [put]
+func (h *MarketplaceHandler) SetStoreHours(c *gin.Context) {
+	var req SetStoreHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	schedule, err := h.service.SetStoreHours(c.Request.Context(), &req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Store hours updated successfully", schedule)
+
+	var entityID uint
+	scope := "store"
+	if req.CategoryID != nil {
+		entityID = *req.CategoryID
+		scope = "category " + schedule.CategoryName
+	}
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "SET_STORE_HOURS",
+		Entity:    "STORE_HOURS",
+		EntityID:  entityID,
+		Details:   fmt.Sprintf("Admin set the opening hours of the %s: %d open days", scope, len(schedule.Days)),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// parseCategoryIDs reads a comma-separated category_ids form field. An invalid
+// ID is answered with a 400 and ok is false; the handler should just return.
+func parseCategoryIDs(c *gin.Context, raw string) (categoryIDs []uint, ok bool) {
+	categoryIDs = []uint{}
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		categoryID, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || categoryID == 0 {
+			utils.ValidationErrorResponse(c, "category_ids must be a comma-separated list of category IDs")
+			return nil, false
+		}
+		categoryIDs = append(categoryIDs, uint(categoryID))
+	}
+	return categoryIDs, true
+}
+
+// GetCategories handles listing the category tree
+// @Summary Get categories
+// @Description Every product category of the campus, nested under its parent category in children
+// @Tags Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]CategoryNode}
+// @Router /marketplace/categories [get]
+func (h *MarketplaceHandler) GetCategories(c *gin.Context) {
+	tree, err := h.service.GetCategoryTree(c.Request.Context())
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", tree)
+}
+
+// GetCategory handles getting a category with its subcategories
+// @Summary Get category
+// @Description A category with its subcategories nested in children
+// @Tags Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} utils.Response{data=CategoryNode}
+// @Failure 404 {object} utils.Response
+// @Router /marketplace/categories/{id} [get]
+func (h *MarketplaceHandler) GetCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", nil)
+		return
+	}
+
+	category, err := h.service.GetCategory(c.Request.Context(), uint(categoryID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
+}
+
+// CreateCategory handles creating a category (Admin)
+// @Summary Create category
+// @Description Create a category of the campus, under parent_id when given. The slug is derived from the name when empty and must be unique within the campus. return_window_hours limits how long after purchase its orders can be cancelled or returned (0 for never), and owner_id names the admin who gets the low-stock alerts of its products; subcategories without either follow it.
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateCategoryRequest true "Category"
+// @Success 201 {object} utils.Response{data=Category}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /marketplace/categories [post]
+func (h *MarketplaceHandler) CreateCategory(c *gin.Context) {
+	var req CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	if err := h.service.Checkout(userID, req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	category, err := h.service.CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Checkout berhasil!", nil)
+	utils.SuccessResponse(c, http.StatusCreated, "Category created successfully", category)
 
 	h.auditService.LogActivity(audit.CreateAuditParams{
-		UserID:    userID,
-		Action:    "CART_CHECKOUT",
-		Entity:    "WALLET",
-		EntityID:  userID,
-		Details:   "User completed checkout from cart",
+		UserID:    c.GetUint("user_id"),
+		Action:    "CREATE_CATEGORY",
+		Entity:    "CATEGORY",
+		EntityID:  category.ID,
+		Details:   "Admin created category: " + category.Name,
 		IPAddress: c.ClientIP(),
 		UserAgent: c.Request.UserAgent(),
 	})
 }
+
+// UpdateCategory handles renaming or moving a category (Admin)
+// @Summary Update category
+// @Description Update the given fields of a category of the campus; parent_id 0 moves the category to the top level, and return_window_hours -1 and owner_id 0 make it follow its parent again. A category cannot be moved under itself or one of its subcategories.
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param request body UpdateCategoryRequest true "Fields to change"
+// @Success 200 {object} utils.Response{data=Category}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /marketplace/categories/{id} [put]
+func (h *MarketplaceHandler) UpdateCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", nil)
+		return
+	}
+
+	var req UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	category, err := h.service.UpdateCategory(c.Request.Context(), uint(categoryID), &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category updated successfully", category)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_CATEGORY",
+		Entity:    "CATEGORY",
+		EntityID:  category.ID,
+		Details:   "Admin updated category: " + category.Name,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteCategory handles deleting a category (Admin)
+// @Summary Delete category
+// @Description Delete a category of the campus without subcategories; its products are kept and only lose the category
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /marketplace/categories/{id} [delete]
+func (h *MarketplaceHandler) DeleteCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteCategory(c.Request.Context(), uint(categoryID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category deleted successfully", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "DELETE_CATEGORY",
+		Entity:    "CATEGORY",
+		EntityID:  uint(categoryID),
+		Details:   fmt.Sprintf("Admin deleted category %d", categoryID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// liveHeartbeat keeps idle streams from being closed by proxies and load balancers
+const liveHeartbeat = 25 * time.Second
+
+// Stream handles the live product feed (Server-Sent Events)
+// @Summary Live product updates
+// @Description Server-Sent Events stream of product stock, price and status changes, for kiosk displays and the web store during flash sales. Each change is an "event: product" whose data is a ProductChange; a comment line is sent every 25 seconds to keep the connection open. Changes made while a client is disconnected are not replayed, so reload the products after reconnecting.
+// @Tags Marketplace
+// @Produce text/event-stream
+// @Param product_ids query string false "Comma separated product IDs to watch (default: all)"
+// @Success 200 {object} ProductChange
+// @Failure 400 {object} utils.Response
+// @Failure 503 {object} utils.Response
+// @Router /marketplace/live [get]
+func (h *MarketplaceHandler) Stream(c *gin.Context) {
+	var watched map[uint]bool
+	if raw := c.Query("product_ids"); raw != "" {
+		watched = make(map[uint]bool)
+		for _, part := range strings.Split(raw, ",") {
+			productID, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+				return
+			}
+			watched[uint(productID)] = true
+		}
+	}
+
+	sub, err := h.service.SubscribeProductChanges()
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx would otherwise hold events back
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 5000\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(liveHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-utils.Draining():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		case event, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			change := event.Payload.(ProductChange)
+			if watched != nil && !watched[change.ProductID] {
+				continue
+			}
+			c.SSEvent("product", change)
+		}
+		c.Writer.Flush()
+	}
+}
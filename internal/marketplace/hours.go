@@ -0,0 +1,276 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-point/utils"
+)
+
+// weekSchedule holds the opening window of each weekday in minutes since
+// midnight; a nil day is closed
+type weekSchedule [7]*[2]int
+
+// status tells whether the schedule is open at now and until when, or else
+// when it opens next
+func (w *weekSchedule) status(now time.Time) StoreStatus {
+	minute := now.Hour()*60 + now.Minute()
+	if day := w[now.Weekday()]; day != nil && minute >= day[0] && minute < day[1] {
+		closes := time.Date(now.Year(), now.Month(), now.Day(), 0, day[1], 0, 0, now.Location())
+		return StoreStatus{Open: true, ClosesAt: &closes}
+	}
+	for offset := 0; offset <= 7; offset++ {
+		date := now.AddDate(0, 0, offset)
+		day := w[date.Weekday()]
+		if day == nil || (offset == 0 && minute >= day[0]) {
+			continue
+		}
+		opens := time.Date(date.Year(), date.Month(), date.Day(), 0, day[0], 0, 0, now.Location())
+		return StoreStatus{NextOpenAt: &opens}
+	}
+	return StoreStatus{}
+}
+
+// storeHours are the opening schedules of a campus
+type storeHours struct {
+	store      *weekSchedule // nil when the store is always open
+	categories map[uint]*weekSchedule
+	parents    map[uint]*uint // loaded only when a category has a schedule
+}
+
+func (s *MarketplaceService) loadStoreHours(ctx context.Context) (*storeHours, error) {
+	rows, err := s.repo.FindStoreHours(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hours := &storeHours{categories: make(map[uint]*weekSchedule)}
+	byCategory := make(map[uint][]StoreHours)
+	var store []StoreHours
+	for _, row := range rows {
+		if row.CategoryID == nil {
+			store = append(store, row)
+		} else {
+			byCategory[*row.CategoryID] = append(byCategory[*row.CategoryID], row)
+		}
+	}
+	if len(store) > 0 {
+		hours.store = scheduleOf(store)
+	}
+	for categoryID, days := range byCategory {
+		hours.categories[categoryID] = scheduleOf(days)
+	}
+
+	if len(hours.categories) > 0 {
+		categories, err := s.repo.FindAllCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hours.parents = make(map[uint]*uint, len(categories))
+		for _, category := range categories {
+			hours.parents[category.ID] = category.ParentID
+		}
+	}
+	return hours, nil
+}
+
+func (h *storeHours) empty() bool {
+	return h.store == nil && len(h.categories) == 0
+}
+
+// storeStatus is the status of the store itself, nil when it is always open
+func (h *storeHours) storeStatus(now time.Time) *StoreStatus {
+	if h.store == nil {
+		return nil
+	}
+	status := h.store.status(now)
+	return &status
+}
+
+// productStatus is the status of a product in categories: each category follows
+// its own schedule or that of its nearest parent with one, and the product can be
+// bought while any of them is open. A product whose categories have no schedule
+// follows the store. Nil means always open.
+func (h *storeHours) productStatus(categories []Category, now time.Time) *StoreStatus {
+	var statuses []StoreStatus
+	for _, category := range categories {
+		for id := &category.ID; id != nil; id = h.parents[*id] {
+			if schedule, ok := h.categories[*id]; ok {
+				statuses = append(statuses, schedule.status(now))
+				break
+			}
+		}
+	}
+	if len(statuses) == 0 {
+		return h.storeStatus(now)
+	}
+
+	combined := StoreStatus{}
+	for _, status := range statuses {
+		switch {
+		case status.Open:
+			if !combined.Open || status.ClosesAt.After(*combined.ClosesAt) {
+				combined = StoreStatus{Open: true, ClosesAt: status.ClosesAt}
+			}
+		case !combined.Open && status.NextOpenAt != nil:
+			if combined.NextOpenAt == nil || status.NextOpenAt.Before(*combined.NextOpenAt) {
+				combined.NextOpenAt = status.NextOpenAt
+			}
+		}
+	}
+	return &combined
+}
+
+// checkStoreOpen fails with ErrStoreClosed, naming the next opening time, when
+// one of products cannot be bought now
+func (s *MarketplaceService) checkStoreOpen(ctx context.Context, products []Product) error {
+	hours, err := s.loadStoreHours(ctx)
+	if err != nil || hours.empty() {
+		return err
+	}
+	var assigned map[uint][]Category
+	if len(hours.categories) > 0 {
+		productIDs := make([]uint, len(products))
+		for i, product := range products {
+			productIDs[i] = product.ID
+		}
+		if assigned, err = s.repo.FindCategoriesByProductIDs(ctx, productIDs); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	for _, product := range products {
+		status := hours.productStatus(assigned[product.ID], now)
+		if status == nil || status.Open {
+			continue
+		}
+		if status.NextOpenAt == nil {
+			return fmt.Errorf("%w: '%s' has no opening hours", ErrStoreClosed, product.Name)
+		}
+		return fmt.Errorf("%w: '%s' can be bought again from %s", ErrStoreClosed, product.Name, status.NextOpenAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// attachAvailability sets the Availability of products, given their categories,
+// and returns the status of the store; both stay nil without store hours
+func (s *MarketplaceService) attachAvailability(ctx context.Context, products []Product, assigned map[uint][]Category) (*StoreStatus, error) {
+	hours, err := s.loadStoreHours(ctx)
+	if err != nil || hours.empty() {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range products {
+		products[i].Availability = hours.productStatus(assigned[products[i].ID], now)
+	}
+	return hours.storeStatus(now), nil
+}
+
+// GetStoreHours returns the schedule of the store, when set, and of every
+// category with its own
+func (s *MarketplaceService) GetStoreHours(ctx context.Context) ([]StoreSchedule, error) {
+	rows, err := s.repo.FindStoreHours(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := indexCategories(categories)
+
+	schedules := []StoreSchedule{}
+	for _, row := range rows {
+		last := len(schedules) - 1
+		if last < 0 || !sameCategory(schedules[last].CategoryID, row.CategoryID) {
+			schedule := StoreSchedule{CategoryID: row.CategoryID}
+			if row.CategoryID != nil && index[*row.CategoryID] != nil {
+				schedule.CategoryName = index[*row.CategoryID].Name
+			}
+			schedules = append(schedules, schedule)
+			last++
+		}
+		schedules[last].Days = append(schedules[last].Days, row)
+	}
+
+	now := time.Now()
+	for i := range schedules {
+		schedules[i].Status = scheduleOf(schedules[i].Days).status(now)
+	}
+	return schedules, nil
+}
+
+// SetStoreHours replaces the schedule of the store or of a category
+func (s *MarketplaceService) SetStoreHours(ctx context.Context, req *SetStoreHoursRequest, adminID uint) (*StoreSchedule, error) {
+	schedule := &StoreSchedule{CategoryID: req.CategoryID, Days: []StoreHours{}}
+	if req.CategoryID != nil {
+		categories, err := s.repo.FindAllCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		category, ok := indexCategories(categories)[*req.CategoryID]
+		if !ok {
+			return nil, ErrCategoryNotFound
+		}
+		schedule.CategoryName = category.Name
+	}
+
+	tenantID, _ := utils.TenantFromContext(ctx)
+	seen := make(map[int]bool, len(req.Days))
+	for _, day := range req.Days {
+		opens, okOpens := parseClock(day.Opens)
+		closes, okCloses := parseClock(day.Closes)
+		if !okOpens || !okCloses || closes <= opens || seen[day.Weekday] {
+			return nil, ErrStoreHoursInvalid
+		}
+		seen[day.Weekday] = true
+		schedule.Days = append(schedule.Days, StoreHours{
+			TenantID:   tenantID,
+			CategoryID: req.CategoryID,
+			Weekday:    day.Weekday,
+			Opens:      formatClock(opens),
+			Closes:     formatClock(closes),
+			UpdatedBy:  adminID,
+		})
+	}
+
+	if err := s.repo.ReplaceStoreHours(ctx, req.CategoryID, schedule.Days); err != nil {
+		return nil, err
+	}
+	schedule.Status = scheduleOf(schedule.Days).status(time.Now())
+	return schedule, nil
+}
+
+func scheduleOf(days []StoreHours) *weekSchedule {
+	schedule := &weekSchedule{}
+	for _, day := range days {
+		opens, _ := parseClock(day.Opens)
+		closes, _ := parseClock(day.Closes)
+		schedule[day.Weekday] = &[2]int{opens, closes}
+	}
+	return schedule
+}
+
+func sameCategory(a, b *uint) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// parseClock reads HH:MM as minutes since midnight; 24:00 is the end of the day
+func parseClock(value string) (int, bool) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok || len(minutes) != 2 {
+		return 0, false
+	}
+	h, errHours := strconv.Atoi(hours)
+	m, errMinutes := strconv.Atoi(minutes)
+	if errHours != nil || errMinutes != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,124 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"wallet-point/internal/settings"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// Purchase limits. The marketplace.daily_spend_limit setting caps the points a
+// student spends on orders per day, a product's PurchaseLimit the units one
+// student buys of it in total; both count successful orders.
+
+// ProductAllowance is how many units of a product with a purchase limit a student may still buy
+type ProductAllowance struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	utils.Allowance
+}
+
+// checkPurchaseLimits runs in the place_order step, which also writes the
+// marketplace transactions the limits count. Locking the wallet first makes a
+// concurrent order of the same student wait until those are committed.
+func (s *MarketplaceService) checkPurchaseLimits(tx *gorm.DB, order *orderState) error {
+	if err := tx.Exec("SELECT id FROM wallets WHERE id = ? FOR UPDATE", order.WalletID).Error; err != nil {
+		return err
+	}
+	return s.checkLimits(tx, order)
+}
+
+// checkLimits checks an order against the limits without locking, which is
+// enough for a quote
+func (s *MarketplaceService) checkLimits(tx *gorm.DB, order *orderState) error {
+	if limit := s.dailySpendLimit(); limit > 0 {
+		start, _ := utils.Today()
+		spent, err := s.repo.SpentSince(tx, order.WalletID, start)
+		if err != nil {
+			return err
+		}
+		total := 0
+		for _, line := range order.Lines {
+			total += line.total()
+		}
+		if spent+total > limit {
+			return fmt.Errorf("%w: %d of %d points left today", ErrSpendLimitReached, max(limit-spent, 0), limit)
+		}
+	}
+
+	productIDs := make([]uint, len(order.Lines))
+	for i, line := range order.Lines {
+		productIDs[i] = line.ProductID
+	}
+	products, err := s.repo.FindLimitedProducts(tx, productIDs)
+	if err != nil || len(products) == 0 {
+		return err
+	}
+	purchased, err := s.repo.PurchasedQuantities(tx, order.WalletID, productIDs)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		for _, line := range order.Lines {
+			if line.ProductID == product.ID && purchased[product.ID]+line.Quantity > product.PurchaseLimit {
+				return fmt.Errorf("%w: %d of %d '%s' left for you", ErrPurchaseLimit, max(product.PurchaseLimit-purchased[product.ID], 0), product.PurchaseLimit, product.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *MarketplaceService) dailySpendLimit() int {
+	if s.settings == nil {
+		return 0
+	}
+	return s.settings.Int(settings.DailySpendLimit)
+}
+
+// DailySpendAllowance returns what the owner of walletID may still spend on
+// orders today, or nil when there is no daily limit
+func (s *MarketplaceService) DailySpendAllowance(ctx context.Context, walletID uint) (*utils.Allowance, error) {
+	limit := s.dailySpendLimit()
+	if limit <= 0 {
+		return nil, nil
+	}
+	start, end := utils.Today()
+	spent, err := s.repo.SpentSince(s.db.WithContext(ctx), walletID, start)
+	if err != nil {
+		return nil, err
+	}
+	return utils.NewAllowance(limit, spent, &end), nil
+}
+
+// ProductAllowances lists the active products of the campus of ctx that have a
+// purchase limit, with what the owner of walletID may still buy of each
+func (s *MarketplaceService) ProductAllowances(ctx context.Context, walletID uint) ([]ProductAllowance, error) {
+	db := s.db.WithContext(ctx)
+	products, err := s.repo.FindLimitedProducts(db, nil)
+	if err != nil {
+		return nil, err
+	}
+	allowances := make([]ProductAllowance, 0, len(products))
+	if len(products) == 0 {
+		return allowances, nil
+	}
+
+	productIDs := make([]uint, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	purchased, err := s.repo.PurchasedQuantities(db, walletID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		allowances = append(allowances, ProductAllowance{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Allowance:   *utils.NewAllowance(product.PurchaseLimit, purchased[product.ID], nil),
+		})
+	}
+	return allowances, nil
+}
@@ -0,0 +1,62 @@
+package marketplace
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+	"wallet-point/internal/events"
+	"wallet-point/utils"
+)
+
+// ProductChange is the live update for one product. Only what a shelf display
+// needs is sent: the stream is public so kiosks can use a plain EventSource.
+type ProductChange struct {
+	ProductID uint      `json:"product_id"`
+	Price     int       `json:"price"`
+	Stock     int       `json:"stock"`
+	Status    string    `json:"status"` // active, inactive or deleted
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// SetEventBus broadcasts product stock, price and status changes on bus to at
+// most maxClients live streams
+func (s *MarketplaceService) SetEventBus(bus *events.Bus, maxClients int) {
+	s.events = bus
+	s.maxLiveClients = maxClients
+}
+
+// ProductChanged runs after a committed change to a product: it drops the cached
+// copies and broadcasts the new stock, price and status to live listeners
+func (s *MarketplaceService) ProductChanged(productID uint) {
+	s.InvalidateProduct(productID)
+	if s.events == nil || s.events.Subscribers() == 0 {
+		return
+	}
+
+	utils.Go(func() {
+		change := ProductChange{ProductID: productID, Status: "deleted", ChangedAt: time.Now()}
+		product, err := s.repo.FindByID(productID)
+		switch {
+		case err == nil:
+			change.Price = product.Price
+			change.Stock = product.Stock
+			change.Status = product.Status
+		case !errors.Is(err, ErrProductNotFound):
+			slog.Error("live updates: load product failed", "product_id", productID, "error", err)
+			return
+		}
+		s.events.Publish(events.TopicProductChanged, change)
+	})
+}
+
+// SubscribeProductChanges opens a subscription to live product changes; the
+// caller closes it when the client goes away
+func (s *MarketplaceService) SubscribeProductChanges() (*events.Subscription, error) {
+	if s.events == nil {
+		return nil, ErrLiveUpdatesDisabled
+	}
+	if s.events.Subscribers() >= s.maxLiveClients {
+		return nil, ErrLiveUpdatesFull
+	}
+	return s.events.Subscribe(64, events.TopicProductChanged), nil
+}
@@ -2,25 +2,152 @@ package marketplace
 
 import (
 	"time"
+	"wallet-point/internal/promotion"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
 )
 
 type Product struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" gorm:"not null"`
-	Description string    `json:"description" gorm:"type:text"`
-	Price       int       `json:"price" gorm:"not null"`
-	Stock       int       `json:"stock" gorm:"default:0;not null"`
-	ImageURL    string    `json:"image_url" gorm:"size:500"`
-	Status      string    `json:"status" gorm:"type:enum('active','inactive');default:'active'"`
-	CreatedBy   uint      `json:"created_by" gorm:"not null"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Name              string         `json:"name" gorm:"not null"`
+	Description       string         `json:"description" gorm:"type:text"`
+	Price             int            `json:"price" gorm:"not null"`
+	Stock             int            `json:"stock" gorm:"default:0;not null"`
+	ImageURL          string         `json:"image_url" gorm:"size:500"`
+	Status            string         `json:"status" gorm:"type:enum('active','inactive');default:'active'"`
+	CreatedBy         uint           `json:"created_by" gorm:"not null"`
+	LowStockThreshold int            `json:"low_stock_threshold" gorm:"default:0;not null"` // Overrides the global threshold when > 0
+	PurchaseLimit     int            `json:"purchase_limit" gorm:"default:0;not null"`      // Units one student may buy in total; 0 means no limit
+	Type              string         `json:"type" gorm:"type:enum('physical','print_quota','ticket');default:'physical';not null"`
+	PrintPages        int            `json:"print_pages,omitempty" gorm:"default:0;not null"` // print_quota: pages added per unit
+	PickupLocation    string         `json:"pickup_location" gorm:"size:100"`                 // physical: where orders are collected; empty is the main store
+	TenantID          uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string"`
+
+	Categories []Category `json:"categories,omitempty" gorm:"many2many:product_categories;"`
+	// The product's categories nested under their parent categories, for browsing
+	CategoryTree []CategoryNode `json:"category_tree,omitempty" gorm:"-"`
+	// Whether the product can be bought now; absent when no store hours are set
+	Availability *StoreStatus `json:"availability,omitempty" gorm:"-"`
 }
 
 func (Product) TableName() string {
 	return "products"
 }
 
+// Category groups the products of a campus; ParentID makes categories
+// hierarchical (e.g. Stationery > Notebooks). Slugs are unique per campus.
+type Category struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	TenantID    uint   `json:"tenant_id" gorm:"not null;default:1;uniqueIndex:idx_categories_tenant_slug,priority:1"`
+	Name        string `json:"name" gorm:"size:100;not null"`
+	Slug        string `json:"slug" gorm:"size:120;not null;uniqueIndex:idx_categories_tenant_slug,priority:2"`
+	Description string `json:"description" gorm:"type:text"`
+	ParentID    *uint  `json:"parent_id" gorm:"index"`
+	// ReturnWindowHours is how long after purchase orders of the category can be
+	// cancelled or returned at its campus; 0 allows none, and nil follows the
+	// parent category
+	ReturnWindowHours *int `json:"return_window_hours"`
+	// OwnerID is the admin who looks after the category's products and gets
+	// their low-stock alerts; nil follows the parent category
+	OwnerID   *uint     `json:"owner_id" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
+
+// StoreHours is one day of an opening schedule: the store, or the products of
+// CategoryID and its subcategories, can be bought on Weekday from Opens until
+// Closes (server time). A day without a row is closed; a store without any row
+// is always open.
+type StoreHours struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TenantID   uint      `json:"tenant_id" gorm:"not null;default:1;index"`
+	CategoryID *uint     `json:"category_id" gorm:"index"` // nil for the whole store
+	Weekday    int       `json:"weekday" gorm:"not null"`  // 0 is Sunday
+	Opens      string    `json:"opens" gorm:"size:5;not null"`
+	Closes     string    `json:"closes" gorm:"size:5;not null"`
+	UpdatedBy  uint      `json:"updated_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (StoreHours) TableName() string {
+	return "store_hours"
+}
+
+// StoreStatus tells whether purchases are accepted now and until or from when
+type StoreStatus struct {
+	Open       bool       `json:"open"`
+	ClosesAt   *time.Time `json:"closes_at,omitempty"`    // while open
+	NextOpenAt *time.Time `json:"next_open_at,omitempty"` // while closed; absent when no day is open
+}
+
+// StoreSchedule is the weekly schedule of the store or of a category
+type StoreSchedule struct {
+	CategoryID   *uint        `json:"category_id"`
+	CategoryName string       `json:"category_name,omitempty"`
+	Days         []StoreHours `json:"days"`
+	Status       StoreStatus  `json:"status"`
+}
+
+type StoreHoursDay struct {
+	Weekday int    `json:"weekday" binding:"gte=0,lte=6"` // 0 is Sunday
+	Opens   string `json:"opens" binding:"required"`      // HH:MM
+	Closes  string `json:"closes" binding:"required"`     // HH:MM after opens; 24:00 is midnight
+}
+
+// SetStoreHoursRequest replaces the schedule of the store, or of CategoryID.
+// Days left out are closed; no days at all removes the schedule, so the store
+// is always open again and the category follows the store.
+type SetStoreHoursRequest struct {
+	CategoryID *uint           `json:"category_id"`
+	Days       []StoreHoursDay `json:"days" binding:"max=7,dive"`
+}
+
+// CategoryNode is a category with its subcategories
+type CategoryNode struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description,omitempty"`
+	ParentID    *uint  `json:"parent_id"`
+	// ReturnWindowHours is set only on categories with their own window
+	ReturnWindowHours *int           `json:"return_window_hours,omitempty"`
+	OwnerID           *uint          `json:"owner_id,omitempty"`
+	Children          []CategoryNode `json:"children,omitempty"`
+}
+
+type CreateCategoryRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Slug        string `json:"slug" binding:"max=120"` // derived from the name when empty
+	Description string `json:"description"`
+	ParentID    *uint  `json:"parent_id"`
+	// Hours after purchase orders can be cancelled or returned, 0 for none;
+	// omitted follows the parent category
+	ReturnWindowHours *int `json:"return_window_hours" binding:"omitempty,gte=0,lte=8760"`
+	// Admin who gets the low-stock alerts of its products; omitted follows the
+	// parent category
+	OwnerID *uint `json:"owner_id"`
+}
+
+// UpdateCategoryRequest changes the given fields; parent_id 0 moves the category
+// to the top level, and return_window_hours -1 and owner_id 0 make it follow its
+// parent again
+type UpdateCategoryRequest struct {
+	Name              string  `json:"name,omitempty" binding:"max=100"`
+	Slug              string  `json:"slug,omitempty" binding:"max=120"`
+	Description       *string `json:"description,omitempty"`
+	ParentID          *uint   `json:"parent_id,omitempty"`
+	ReturnWindowHours *int    `json:"return_window_hours,omitempty" binding:"omitempty,gte=-1,lte=8760"`
+	OwnerID           *uint   `json:"owner_id,omitempty"`
+}
+
 type MarketplaceTransaction struct {
 	ID            uint      `json:"id" gorm:"primaryKey"`
 	WalletID      uint      `json:"wallet_id" gorm:"not null;index"`
@@ -34,7 +161,10 @@ type MarketplaceTransaction struct {
 	StudentBatch  string    `json:"student_batch" gorm:"size:50"`
 	PaymentMethod string    `json:"payment_method" gorm:"size:50;default:'wallet'"`
 	Status        string    `json:"status" gorm:"type:enum('success','failed');default:'success'"`
-	CreatedAt     time.Time `json:"created_at"`
+	RecipientID   *uint     `json:"recipient_user_id,omitempty" gorm:"column:recipient_user_id;index"` // gifts: the student the order was issued to
+	SessionID     *uint     `json:"session_id,omitempty" gorm:"column:ticket_session_id"`              // tickets: the session bought
+	DebitID       uint      `json:"-" gorm:"not null;default:0"`                                       // the wallet debit that paid it, 0 when free or unknown
+	CreatedAt     time.Time `json:"created_at" gorm:"not null"`                                        // partitioning column, see internal/partition
 }
 
 type PurchaseRequest struct {
@@ -47,6 +177,17 @@ type PurchaseRequest struct {
 	StudentNPM    string `json:"student_npm"`
 	StudentMajor  string `json:"student_major"`
 	StudentBatch  string `json:"student_batch"`
+	// RecipientUserID makes the purchase a gift: paid by the buyer, issued to this student
+	RecipientUserID uint `json:"recipient_user_id"`
+	// SessionID is the session a ticket product is bought for; required for tickets
+	SessionID uint `json:"session_id"`
+}
+
+// GiftParty is the buyer or the recipient of a gifted order
+type GiftParty struct {
+	ID       uint
+	FullName string
+	Role     string
 }
 
 func (MarketplaceTransaction) TableName() string {
@@ -67,50 +208,82 @@ type MarketplaceTransactionWithDetails struct {
 	PaymentMethod string    `json:"payment_method"`
 	Status        string    `json:"status"`
 	CreatedAt     time.Time `json:"created_at"`
+	RecipientID   *uint     `json:"recipient_user_id,omitempty"`
 	ProductName   string    `json:"product_name"`
 	UserName      string    `json:"user_name"`
 	UserEmail     string    `json:"user_email"`
 }
 
 type CreateProductRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	Price       int    `json:"price" binding:"required,gt=0"`
-	Stock       int    `json:"stock" binding:"gte=0"`
-	ImageURL    string `json:"image_url"`
+	Name              string `json:"name" binding:"required"`
+	Description       string `json:"description"`
+	Price             int    `json:"price" binding:"required,points"`
+	Stock             int    `json:"stock" binding:"gte=0"`
+	ImageURL          string `json:"image_url"`
+	LowStockThreshold int    `json:"low_stock_threshold" binding:"gte=0"`
+	PurchaseLimit     int    `json:"purchase_limit" binding:"gte=0"`
+	Type              string `json:"type" binding:"omitempty,product_type" enums:"physical,print_quota,ticket"` // defaults to physical
+	PrintPages        int    `json:"print_pages" binding:"gte=0"`                                               // required for print_quota
+	PickupLocation    string `json:"pickup_location" binding:"max=100"`
+	CategoryIDs       []uint `json:"category_ids"`
 }
 
 type UpdateProductRequest struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
-	Price       int    `json:"price,omitempty" binding:"omitempty,gt=0"`
-	Stock       int    `json:"stock,omitempty" binding:"omitempty,gte=0"`
-	ImageURL    string `json:"image_url,omitempty"`
-	Status      string `json:"status,omitempty" binding:"omitempty,oneof=active inactive"`
+	Name              string  `json:"name,omitempty"`
+	Description       string  `json:"description,omitempty"`
+	Price             int     `json:"price,omitempty" binding:"omitempty,points"`
+	Stock             int     `json:"stock,omitempty" binding:"omitempty,gte=0"`
+	ImageURL          string  `json:"image_url,omitempty"`
+	Status            string  `json:"status,omitempty" binding:"omitempty,product_status" enums:"active,inactive"`
+	LowStockThreshold *int    `json:"low_stock_threshold,omitempty" binding:"omitempty,gte=0"`
+	PurchaseLimit     *int    `json:"purchase_limit,omitempty" binding:"omitempty,gte=0"`
+	PrintPages        *int    `json:"print_pages,omitempty" binding:"omitempty,gt=0"`
+	PickupLocation    *string `json:"pickup_location,omitempty" binding:"omitempty,max=100"`
+	CategoryIDs       *[]uint `json:"category_ids,omitempty"` // replaces the categories; empty removes them all
 }
 
 type ProductListParams struct {
-	Status string
-	Page   int
-	Limit  int
+	Status         string
+	CategoryID     uint   // products of this category or one of its subcategories
+	CategoryIDs    []uint // CategoryID and its subcategories, filled in by the service
+	Store          string // faculty store code: only products of its catalog
+	ProductIDs     []uint // the catalog of Store, filled in by the service
+	IncludeDeleted bool   // Admin only: also list soft-deleted products
+	Fields         utils.Fields
+	Cursor         *utils.Cursor
+	Page           int
+	Limit          int
 }
 
 type ProductListResponse struct {
-	Products   []Product `json:"products"`
-	Total      int64     `json:"total"`
-	Page       int       `json:"page"`
-	Limit      int       `json:"limit"`
-	TotalPages int       `json:"total_pages"`
+	Products []Product `json:"products"`
+	utils.Pagination
+	Store *StoreStatus `json:"store,omitempty"` // absent when no store hours are set
+}
+
+// Meta returns the list fields sent next to the products
+func (r *ProductListResponse) Meta() map[string]interface{} {
+	if r.Store == nil {
+		return nil
+	}
+	return map[string]interface{}{"store": r.Store}
+}
+
+// GiftListResponse lists the orders gifted to a student; user_name is the buyer
+type GiftListResponse struct {
+	Gifts []MarketplaceTransactionWithDetails `json:"gifts"`
+	utils.Pagination
 }
 
 type CartItem struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id" gorm:"not null;index"`
-	ProductID uint      `json:"product_id" gorm:"not null;index"`
-	Quantity  int       `json:"quantity" gorm:"not null;default:1"`
-	Product   Product   `json:"product" gorm:"foreignKey:ProductID"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;index"`
+	ProductID uint           `json:"product_id" gorm:"not null;index"`
+	Quantity  int            `json:"quantity" gorm:"not null;default:1"`
+	Product   Product        `json:"product" gorm:"foreignKey:ProductID"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 func (CartItem) TableName() string {
@@ -129,9 +302,200 @@ type UpdateCartRequest struct {
 type CartCheckoutRequest struct {
 	PIN           string `json:"pin" binding:"required"`
 	PaymentMethod string `json:"payment_method" binding:"oneof=wallet"`
+	// RecipientUserID makes the checkout a gift: paid by the buyer, issued to this student
+	RecipientUserID uint `json:"recipient_user_id"`
 }
 
+// CartResponse is a priced cart: TotalPrice is what checkout charges, the
+// subtotal less the discount of the applied promotions
 type CartResponse struct {
-	Items      []CartItem `json:"items"`
-	TotalPrice int        `json:"total_price"`
+	Items      []CartItem          `json:"items"`
+	Subtotal   int                 `json:"subtotal"`
+	Discount   int                 `json:"discount"`
+	Promotions []promotion.Applied `json:"promotions"`
+	TotalPrice int                 `json:"total_price"`
+}
+
+type CartQuoteRequest struct {
+	// RecipientUserID quotes the checkout as a gift to this student
+	RecipientUserID uint `json:"recipient_user_id"`
+}
+
+// CartQuote is what checking out the cart would do now: Total is the exact
+// charge, and the checkout succeeds only when Issues is empty
+type CartQuote struct {
+	Items       []CartItem          `json:"items"`
+	Subtotal    int                 `json:"subtotal"`
+	Discount    int                 `json:"discount"`
+	Promotions  []promotion.Applied `json:"promotions"`
+	Total       int                 `json:"total"`
+	Balance     int                 `json:"balance"`
+	CanCheckout bool                `json:"can_checkout"`
+	Issues      []QuoteIssue        `json:"issues"`
+}
+
+// QuoteIssue is an error the checkout would fail with, e.g. PRODUCT_INSUFFICIENT_STOCK
+type QuoteIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	err     error
+}
+
+// StockAlert tracks a low-stock condition so the same alert is not fired repeatedly
+type StockAlert struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	ProductID      uint       `json:"product_id" gorm:"not null;index"`
+	Stock          int        `json:"stock" gorm:"not null"`
+	Threshold      int        `json:"threshold" gorm:"not null"`
+	Status         string     `json:"status" gorm:"type:enum('open','acknowledged','snoozed','resolved');default:'open';index"`
+	SnoozedUntil   *time.Time `json:"snoozed_until"`
+	AcknowledgedBy *uint      `json:"acknowledged_by"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	LastNotifiedAt *time.Time `json:"last_notified_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (StockAlert) TableName() string {
+	return "stock_alerts"
+}
+
+type StockAlertWithProduct struct {
+	StockAlert
+	ProductName string `json:"product_name"`
+}
+
+// Inventory entry reasons
+const (
+	InventoryRestock = "restock"
+)
+
+// InventoryEntry is a line of a product's inventory ledger: a stock change and
+// the stock it left
+type InventoryEntry struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ProductID  uint      `json:"product_id" gorm:"not null;index"`
+	Change     int       `json:"change" gorm:"not null"`
+	StockAfter int       `json:"stock_after" gorm:"not null"`
+	Reason     string    `json:"reason" gorm:"size:30;not null"`
+	Note       string    `json:"note" gorm:"size:255"`
+	CreatedBy  uint      `json:"created_by" gorm:"not null"`
+	TenantID   uint      `json:"tenant_id" gorm:"not null;default:1;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (InventoryEntry) TableName() string {
+	return "inventory_entries"
+}
+
+type RestockItem struct {
+	ProductID uint   `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,gt=0,lte=1000000"`
+	Note      string `json:"note" binding:"max=255"`
+}
+
+type RestockRequest struct {
+	Items []RestockItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+type RestockResponse struct {
+	Entries []InventoryEntry `json:"entries"`
+}
+
+type SnoozeStockAlertRequest struct {
+	Hours int `json:"hours" binding:"required,gt=0,lte=720"`
+}
+
+// Product question statuses: a question is shown to other students once an
+// admin answered it, and hidden for good when rejected
+const (
+	QuestionPending   = "pending"
+	QuestionPublished = "published"
+	QuestionRejected  = "rejected"
+)
+
+// TopQuestionsLimit is how many answered questions the product detail includes
+const TopQuestionsLimit = 3
+
+// ProductQuestion is a student's question on a product page and the admin's answer
+type ProductQuestion struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	TenantID     uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	ProductID    uint       `json:"product_id" gorm:"not null;index"`
+	AskerID      uint       `json:"asker_id" gorm:"not null;index"`
+	Question     string     `json:"question" gorm:"size:1000;not null"`
+	Answer       string     `json:"answer,omitempty" gorm:"type:text"`
+	Status       string     `json:"status" gorm:"type:enum('pending','published','rejected');default:'pending';not null;index"`
+	RejectReason string     `json:"reject_reason,omitempty" gorm:"size:500"`
+	HelpfulCount int        `json:"helpful_count" gorm:"default:0;not null"`
+	AnsweredBy   *uint      `json:"answered_by"`
+	AnsweredAt   *time.Time `json:"answered_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (ProductQuestion) TableName() string {
+	return "product_questions"
+}
+
+// ProductQuestionVote is a student marking an answered question helpful, once
+type ProductQuestionVote struct {
+	QuestionID uint      `json:"question_id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (ProductQuestionVote) TableName() string {
+	return "product_question_votes"
+}
+
+type ProductQuestionWithNames struct {
+	ProductQuestion
+	AskerName   string `json:"asker_name"`
+	ProductName string `json:"product_name"`
+}
+
+// ProductDetail is a product with its most helpful answered questions
+type ProductDetail struct {
+	Product
+	TopQuestions []ProductQuestionWithNames `json:"top_questions"`
+	// How long orders of the product can be cancelled or returned; absent when
+	// none of its categories sets a window
+	ReturnPolicy *ReturnPolicy `json:"return_policy,omitempty"`
+}
+
+// ReturnPolicy is the return window a product gets from its categories
+type ReturnPolicy struct {
+	Returnable   bool   `json:"returnable"`
+	WindowHours  int    `json:"window_hours"`
+	CategoryID   uint   `json:"category_id"` // the category the window is set on
+	CategoryName string `json:"category_name"`
+}
+
+// QuestionListParams filters product questions. A student (ViewerID set) sees
+// the published questions and their own; admins see every status.
+type QuestionListParams struct {
+	ProductID uint
+	Status    string
+	ViewerID  uint
+	Page      int
+	Limit     int
+}
+
+type QuestionListResponse struct {
+	Questions []ProductQuestionWithNames `json:"questions"`
+	utils.Pagination
+}
+
+type AskQuestionRequest struct {
+	Question string `json:"question" binding:"required,min=5,max=1000"`
+}
+
+type AnswerQuestionRequest struct {
+	Answer string `json:"answer" binding:"required,max=2000"`
+}
+
+type RejectQuestionRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
 }
@@ -0,0 +1,41 @@
+package marketplace
+
+import (
+	"context"
+	"wallet-point/internal/promotion"
+)
+
+// SetPromotions prices carts, checkouts and purchases with the running
+// promotions of the campus; without it everything sells at list price
+func (s *MarketplaceService) SetPromotions(promotionService *promotion.Service) {
+	s.promotions = promotionService
+}
+
+// priceOrder applies the promotions to the lines of an order of walletID,
+// setting each line's discount and the order's applied promotions
+func (s *MarketplaceService) priceOrder(ctx context.Context, walletID uint, order *orderState) (*promotion.Pricing, error) {
+	lines := make([]promotion.Line, len(order.Lines))
+	for i, line := range order.Lines {
+		lines[i] = promotion.Line{ProductID: line.ProductID, Price: line.Price, Quantity: line.Quantity}
+	}
+
+	var pricing *promotion.Pricing
+	if s.promotions != nil {
+		var err error
+		if pricing, err = s.promotions.Price(ctx, walletID, lines); err != nil {
+			return nil, err
+		}
+	} else {
+		pricing = &promotion.Pricing{Applied: []promotion.Applied{}, LineDiscounts: make([]int, len(lines))}
+		for _, line := range lines {
+			pricing.Subtotal += line.Price * line.Quantity
+		}
+		pricing.Total = pricing.Subtotal
+	}
+
+	for i := range order.Lines {
+		order.Lines[i].Discount = pricing.LineDiscounts[i]
+	}
+	order.Promotions = pricing.Applied
+	return pricing, nil
+}
@@ -0,0 +1,171 @@
+package marketplace
+
+import (
+	"context"
+	"strings"
+	"time"
+	"wallet-point/internal/events"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// GetProductDetail returns a product of the campus of ctx with its most
+// helpful answered questions
+func (s *MarketplaceService) GetProductDetail(ctx context.Context, productID uint) (*ProductDetail, error) {
+	product, err := s.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.repo.FindTopQuestions(ctx, productID, TopQuestionsLimit)
+	if err != nil {
+		return nil, err
+	}
+	if questions == nil {
+		questions = []ProductQuestionWithNames{}
+	}
+	decorated := []Product{*product}
+	if _, err := s.decorateProducts(ctx, decorated); err != nil {
+		return nil, err
+	}
+	policy, err := s.GetReturnPolicy(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return &ProductDetail{Product: decorated[0], TopQuestions: questions, ReturnPolicy: policy}, nil
+}
+
+// AskQuestion posts a student's question on a product; it stays pending until an admin answers it
+func (s *MarketplaceService) AskQuestion(ctx context.Context, productID, userID uint, req *AskQuestionRequest) (*ProductQuestion, error) {
+	product, err := s.findProduct(ctx, productID, s.repo.FindByID)
+	if err != nil {
+		return nil, err
+	}
+	question := &ProductQuestion{
+		TenantID:  product.TenantID,
+		ProductID: product.ID,
+		AskerID:   userID,
+		Question:  strings.TrimSpace(req.Question),
+		Status:    QuestionPending,
+	}
+	if err := s.repo.CreateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// GetQuestions returns a page of product questions
+func (s *MarketplaceService) GetQuestions(ctx context.Context, params QuestionListParams) (*QuestionListResponse, error) {
+	if params.ProductID != 0 {
+		if _, err := s.findProduct(ctx, params.ProductID, s.repo.FindByID); err != nil {
+			return nil, err
+		}
+	}
+	questions, total, err := s.repo.FindQuestions(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &QuestionListResponse{
+		Questions:  questions,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// AnswerQuestion answers a question and publishes it. The asker is told of the
+// first answer; answering again only corrects the text.
+func (s *MarketplaceService) AnswerQuestion(ctx context.Context, questionID, adminID uint, req *AnswerQuestionRequest) (*ProductQuestion, error) {
+	var answered *ProductQuestion
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		question, err := s.repo.LockQuestion(tx, questionID)
+		if err != nil {
+			return err
+		}
+		if question.Status == QuestionRejected {
+			return ErrQuestionRejected
+		}
+		first := question.Status == QuestionPending
+
+		now := time.Now()
+		question.Answer, question.Status, question.AnsweredBy, question.AnsweredAt = strings.TrimSpace(req.Answer), QuestionPublished, &adminID, &now
+		if err := s.repo.UpdateQuestion(tx, question, map[string]interface{}{
+			"answer":      question.Answer,
+			"status":      question.Status,
+			"answered_by": adminID,
+			"answered_at": now,
+		}); err != nil {
+			return err
+		}
+		answered = question
+		if !first {
+			return nil
+		}
+		return s.publishQuestionAnswered(tx, question)
+	})
+	return answered, err
+}
+
+// RejectQuestion hides a question that breaks the rules, also after it was published
+func (s *MarketplaceService) RejectQuestion(ctx context.Context, questionID uint, req *RejectQuestionRequest) (*ProductQuestion, error) {
+	var rejected *ProductQuestion
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		question, err := s.repo.LockQuestion(tx, questionID)
+		if err != nil {
+			return err
+		}
+		if question.Status == QuestionRejected {
+			return ErrQuestionRejected
+		}
+		question.Status, question.RejectReason = QuestionRejected, req.Reason
+		if err := s.repo.UpdateQuestion(tx, question, map[string]interface{}{
+			"status":        question.Status,
+			"reject_reason": question.RejectReason,
+		}); err != nil {
+			return err
+		}
+		rejected = question
+		return nil
+	})
+	return rejected, err
+}
+
+// MarkQuestionHelpful counts a student's vote for a published question once
+func (s *MarketplaceService) MarkQuestionHelpful(ctx context.Context, questionID, userID uint) (*ProductQuestion, error) {
+	var voted *ProductQuestion
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		question, err := s.repo.LockQuestion(tx, questionID)
+		if err != nil {
+			return err
+		}
+		if question.Status != QuestionPublished {
+			return ErrQuestionNotFound
+		}
+		added, err := s.repo.AddQuestionVote(tx, question.ID, userID)
+		if err != nil {
+			return err
+		}
+		if added {
+			question.HelpfulCount++
+		}
+		voted = question
+		return nil
+	})
+	return voted, err
+}
+
+// publishQuestionAnswered publishes product.question_answered within the answer's transaction
+func (s *MarketplaceService) publishQuestionAnswered(tx *gorm.DB, question *ProductQuestion) error {
+	if s.outbox == nil {
+		return nil
+	}
+	product, err := s.repo.FindByIDUnscoped(question.ProductID)
+	if err != nil {
+		return err
+	}
+	return s.outbox.Publish(tx, events.EventQuestionAnswered, events.QuestionAnswered{
+		TenantID:    question.TenantID,
+		QuestionID:  question.ID,
+		ProductID:   question.ProductID,
+		ProductName: product.Name,
+		AskerID:     question.AskerID,
+	})
+}
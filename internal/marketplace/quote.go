@@ -0,0 +1,133 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"wallet-point/internal/events"
+	"wallet-point/internal/promotion"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+)
+
+// checkoutPlan is the order a checkout of a cart would run and what blocks it
+type checkoutPlan struct {
+	Items   []CartItem
+	Order   *orderState
+	Pricing *promotion.Pricing
+	Balance int
+	// Issues are the errors that block the checkout, in the order Checkout
+	// meets them; any other error is returned instead
+	Issues []error
+}
+
+// planCheckout prices the cart of userID as a checkout would and collects what
+// blocks it, without changing anything
+func (s *MarketplaceService) planCheckout(ctx context.Context, userID, recipientID uint) (*checkoutPlan, error) {
+	plan := &checkoutPlan{}
+	blocked := func(err error) error {
+		if _, ok := utils.AsAppError(err); ok {
+			plan.Issues = append(plan.Issues, err)
+			return nil
+		}
+		return err
+	}
+
+	items, err := s.repo.GetCart(userID)
+	if err != nil {
+		return nil, err
+	}
+	plan.Items = items
+	if len(items) == 0 {
+		plan.Issues = append(plan.Issues, ErrCartEmpty)
+		return plan, nil
+	}
+	gift, err := s.giftFor(ctx, userID, recipientID)
+	if err := blocked(err); err != nil {
+		return nil, err
+	}
+
+	products := make([]Product, 0, len(items))
+	for _, item := range items {
+		if !utils.InTenant(ctx, item.Product.TenantID) {
+			plan.Issues = append(plan.Issues, ErrProductNotFound)
+			continue
+		}
+		if err := s.checkDeliverable(&item.Product); err != nil {
+			plan.Issues = append(plan.Issues, err)
+		}
+		if item.Product.Type == ProductTicket {
+			plan.Issues = append(plan.Issues, ErrTicketNotInCart)
+		}
+		if item.Product.Stock < item.Quantity {
+			plan.Issues = append(plan.Issues, fmt.Errorf("%w for product '%s'", ErrInsufficientStock, item.Product.Name))
+		}
+		products = append(products, item.Product)
+	}
+	if err := blocked(s.checkStoreOpen(ctx, products)); err != nil {
+		return nil, err
+	}
+
+	studentWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	plan.Balance = studentWallet.Balance
+	plan.Order = &orderState{
+		UserID:   userID,
+		WalletID: studentWallet.ID,
+		Source:   events.OrderSourceCheckout,
+		Gift:     gift,
+		Lines:    make([]orderLine, 0, len(items)),
+	}
+	for _, item := range items {
+		plan.Order.Lines = append(plan.Order.Lines, orderLine{
+			ProductID:   item.ProductID,
+			ProductName: item.Product.Name,
+			Price:       item.Product.Price,
+			Quantity:    item.Quantity,
+		})
+	}
+	if plan.Pricing, err = s.priceOrder(ctx, studentWallet.ID, plan.Order); err != nil {
+		return nil, err
+	}
+	if plan.Balance < plan.Pricing.Total {
+		plan.Issues = append(plan.Issues, fmt.Errorf("%w. Total: %d, Balance: %d", wallet.ErrInsufficientBalance, plan.Pricing.Total, plan.Balance))
+	}
+	return plan, nil
+}
+
+// QuoteCheckout tells what checking out the cart of userID would charge now,
+// with the applied promotions, and every issue that would make it fail. It
+// changes nothing and does not verify the PIN.
+func (s *MarketplaceService) QuoteCheckout(ctx context.Context, userID uint, req CartQuoteRequest) (*CartQuote, error) {
+	plan, err := s.planCheckout(ctx, userID, req.RecipientUserID)
+	if err != nil {
+		return nil, err
+	}
+	// The limits are checked as the order saga does, only without locking the wallet
+	if plan.Order != nil {
+		if err := s.checkLimits(s.db.WithContext(ctx), plan.Order); err != nil {
+			if _, ok := utils.AsAppError(err); !ok {
+				return nil, err
+			}
+			plan.Issues = append(plan.Issues, err)
+		}
+	}
+
+	quote := &CartQuote{
+		Items:      plan.Items,
+		Promotions: []promotion.Applied{},
+		Balance:    plan.Balance,
+		Issues:     make([]QuoteIssue, 0, len(plan.Issues)),
+	}
+	if plan.Pricing != nil {
+		quote.Subtotal, quote.Discount, quote.Total = plan.Pricing.Subtotal, plan.Pricing.Discount, plan.Pricing.Total
+		quote.Promotions = plan.Pricing.Applied
+	}
+	for _, issue := range plan.Issues {
+		appErr, _ := utils.AsAppError(issue)
+		quote.Issues = append(quote.Issues, QuoteIssue{Code: appErr.Code, Message: issue.Error(), err: issue})
+	}
+	quote.CanCheckout = len(quote.Issues) == 0
+	return quote, nil
+}
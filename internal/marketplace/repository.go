@@ -1,39 +1,146 @@
 package marketplace
 
 import (
+	"context"
 	"errors"
+	"time"
+	"wallet-point/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Repository is the product, cart and stock alert storage MarketplaceService depends on.
+// MarketplaceRepository is the GORM implementation; tests and decorators (e.g. a
+// caching repository) can provide their own.
+type Repository interface {
+	GetAll(ctx context.Context, params ProductListParams) ([]Product, int64, error)
+	FindByID(productID uint) (*Product, error)
+	FindByIDUnscoped(productID uint) (*Product, error)
+	FindByIDs(ctx context.Context, productIDs []uint) ([]Product, error)
+	FindCategoriesByProductIDs(ctx context.Context, productIDs []uint) (map[uint][]Category, error)
+	FindAllCategories(ctx context.Context) ([]Category, error)
+	CreateCategory(ctx context.Context, category *Category) error
+	UpdateCategory(ctx context.Context, categoryID uint, updates map[string]interface{}) error
+	DeleteCategory(ctx context.Context, categoryID uint) error
+	SlugTaken(ctx context.Context, slug string, exceptID uint) (bool, error)
+	ReplaceProductCategories(ctx context.Context, productID uint, categoryIDs []uint) error
+	FindStoreHours(ctx context.Context) ([]StoreHours, error)
+	ReplaceStoreHours(ctx context.Context, categoryID *uint, days []StoreHours) error
+	Create(product *Product) error
+	Update(productID uint, updates map[string]interface{}) error
+	Delete(productID uint) error
+	Restore(productID uint) error
+	UpdateStock(tx *gorm.DB, productID uint, delta int) error
+	ReserveStock(tx *gorm.DB, productID uint, quantity int) error
+	LockProducts(tx *gorm.DB, productIDs []uint) ([]Product, error)
+	CreateInventoryEntries(tx *gorm.DB, entries []InventoryEntry) error
+
+	AddToCart(item *CartItem) error
+	GetCart(userID uint) ([]CartItem, error)
+	UpdateCartItem(userID, itemID uint, quantity int) error
+	RemoveFromCart(userID, itemID uint) error
+	ClearCart(tx *gorm.DB, userID uint) error
+	FindCartUserIDs(tx *gorm.DB, productID uint) ([]uint, error)
+	DeleteStaleCartItems(ctx context.Context, before time.Time) (int64, error)
+
+	CreateMarketplaceTransaction(tx *gorm.DB, txn *MarketplaceTransaction) error
+	FindMarketplaceTransactions(tx *gorm.DB, ids []uint) ([]MarketplaceTransaction, error)
+	UpdateMarketplaceTransactionStatus(tx *gorm.DB, id uint, status string) error
+	GetTransactions(ctx context.Context, cursor *utils.Cursor, fields utils.Fields, limit, page int) ([]MarketplaceTransactionWithDetails, int64, error)
+	GetWalletTransactions(walletID uint, page, limit int) ([]MarketplaceTransaction, int64, error)
+	FindReceivedGifts(ctx context.Context, userID uint, page, limit int) ([]MarketplaceTransactionWithDetails, int64, error)
+	FindActiveUser(ctx context.Context, userID uint) (*GiftParty, error)
+	SpentSince(tx *gorm.DB, walletID uint, since time.Time) (int, error)
+	PurchasedQuantities(tx *gorm.DB, walletID uint, productIDs []uint) (map[uint]int, error)
+	FindLimitedProducts(tx *gorm.DB, productIDs []uint) ([]Product, error)
+
+	FindUnresolvedStockAlert(productID uint) (*StockAlert, error)
+	FindStockAlertByID(alertID uint) (*StockAlert, error)
+	CreateStockAlert(alert *StockAlert) error
+	UpdateStockAlert(alertID uint, updates map[string]interface{}) error
+	GetStockAlerts(status string) ([]StockAlertWithProduct, error)
+	FindExpiredSnoozedAlerts(now time.Time) ([]StockAlert, error)
+
+	CreateQuestion(ctx context.Context, question *ProductQuestion) error
+	FindQuestions(ctx context.Context, params QuestionListParams) ([]ProductQuestionWithNames, int64, error)
+	FindTopQuestions(ctx context.Context, productID uint, limit int) ([]ProductQuestionWithNames, error)
+	LockQuestion(tx *gorm.DB, questionID uint) (*ProductQuestion, error)
+	UpdateQuestion(tx *gorm.DB, question *ProductQuestion, updates map[string]interface{}) error
+	AddQuestionVote(tx *gorm.DB, questionID, userID uint) (bool, error)
+}
+
+var _ Repository = (*MarketplaceRepository)(nil)
+
 type MarketplaceRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	replica *gorm.DB // admin sales listing
 }
 
+// Columns read by the joined listings, instead of every column of every joined table
+var (
+	cartProductColumns = []string{"id", "name", "price", "stock", "image_url", "status", "tenant_id"}
+
+	marketplaceTransactionDetailColumns = []string{
+		"t.id", "t.wallet_id", "t.product_id", "t.amount", "t.total_amount", "t.quantity",
+		"t.student_name", "t.student_npm", "t.student_major", "t.student_batch",
+		"t.payment_method", "t.status", "t.recipient_user_id", "t.created_at",
+		"p.name AS product_name", "u.full_name AS user_name", "u.email AS user_email",
+	}
+
+	// ProductFields are the product fields a listing can be narrowed to with fields=
+	ProductFields = utils.FieldColumns{
+		"id": "id", "name": "name", "description": "description", "price": "price", "stock": "stock",
+		"image_url": "image_url", "status": "status", "created_by": "created_by",
+		"low_stock_threshold": "low_stock_threshold", "purchase_limit": "purchase_limit", "type": "type", "print_pages": "print_pages", "pickup_location": "pickup_location",
+		"tenant_id": "tenant_id", "created_at": "created_at", "updated_at": "updated_at", "deleted_at": "deleted_at",
+	}
+
+	// TransactionFields are the order fields a listing can be narrowed to with fields=
+	TransactionFields = utils.FieldColumns{
+		"id": "t.id", "wallet_id": "t.wallet_id", "product_id": "t.product_id", "amount": "t.amount",
+		"total_amount": "t.total_amount", "quantity": "t.quantity", "student_name": "t.student_name",
+		"student_npm": "t.student_npm", "student_major": "t.student_major", "student_batch": "t.student_batch",
+		"payment_method": "t.payment_method", "status": "t.status", "recipient_user_id": "t.recipient_user_id", "created_at": "t.created_at",
+		"product_name": "p.name AS product_name", "user_name": "u.full_name AS user_name", "user_email": "u.email AS user_email",
+	}
+)
+
 func NewMarketplaceRepository(db *gorm.DB) *MarketplaceRepository {
-	return &MarketplaceRepository{db: db}
+	return &MarketplaceRepository{db: db, replica: utils.ReadReplica(db)}
 }
 
 // GetAll gets all products with filters and pagination
-func (r *MarketplaceRepository) GetAll(params ProductListParams) ([]Product, int64, error) {
+func (r *MarketplaceRepository) GetAll(ctx context.Context, params ProductListParams) ([]Product, int64, error) {
 	var products []Product
 	var total int64
 
-	query := r.db.Model(&Product{})
+	query := r.db.WithContext(ctx).Model(&Product{})
 
 	// Apply filters
+	if params.IncludeDeleted {
+		query = query.Unscoped()
+	}
 	if params.Status != "" {
 		query = query.Where("status = ?", params.Status)
 	}
+	if params.Store != "" {
+		query = query.Where("id IN ?", params.ProductIDs)
+	}
+	if len(params.CategoryIDs) > 0 {
+		query = query.Where("id IN (SELECT product_id FROM product_categories WHERE category_id IN ?)", params.CategoryIDs)
+	}
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Apply pagination
-	offset := (params.Page - 1) * params.Limit
-	query = query.Limit(params.Limit).Offset(offset).Order("created_at DESC")
+	// Apply pagination; the cursor keys are selected even when not asked for
+	if columns := params.Fields.Select(ProductFields, "id", "created_at"); columns != nil {
+		query = query.Select(columns)
+	}
+	query = utils.Paginate(query, params.Cursor, "created_at", "id", params.Page, params.Limit)
 
 	if err := query.Find(&products).Error; err != nil {
 		return nil, 0, err
@@ -48,13 +155,126 @@ func (r *MarketplaceRepository) FindByID(productID uint) (*Product, error) {
 	err := r.db.First(&product, productID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("product not found")
+			return nil, ErrProductNotFound
 		}
 		return nil, err
 	}
 	return &product, nil
 }
 
+// FindByIDs loads the products with the given IDs, in no particular order
+func (r *MarketplaceRepository) FindByIDs(ctx context.Context, productIDs []uint) ([]Product, error) {
+	var products []Product
+	err := r.db.WithContext(ctx).Where("id IN ?", productIDs).Find(&products).Error
+	return products, err
+}
+
+// FindCategoriesByProductIDs loads the categories of several products in one
+// query, keyed by product ID. Only categories of the product's own campus count,
+// so a link left from before categories had a campus cannot give a product the
+// return window another campus set.
+func (r *MarketplaceRepository) FindCategoriesByProductIDs(ctx context.Context, productIDs []uint) (map[uint][]Category, error) {
+	var rows []struct {
+		ProductID uint
+		Category
+	}
+	err := r.db.WithContext(ctx).Table("categories c").
+		Select("pc.product_id, c.*").
+		Joins("join product_categories pc on pc.category_id = c.id").
+		Joins("join products p on p.id = pc.product_id and p.tenant_id = c.tenant_id").
+		Where("pc.product_id IN ?", productIDs).
+		Order("c.name").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[uint][]Category, len(productIDs))
+	for _, row := range rows {
+		categories[row.ProductID] = append(categories[row.ProductID], row.Category)
+	}
+	return categories, nil
+}
+
+// FindAllCategories loads every category, by name
+func (r *MarketplaceRepository) FindAllCategories(ctx context.Context) ([]Category, error) {
+	var categories []Category
+	err := r.db.WithContext(ctx).Order("name").Find(&categories).Error
+	return categories, err
+}
+
+func (r *MarketplaceRepository) CreateCategory(ctx context.Context, category *Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *MarketplaceRepository) UpdateCategory(ctx context.Context, categoryID uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Category{}).Where("id = ?", categoryID).Updates(updates).Error
+}
+
+// DeleteCategory deletes a category with its store hours and takes its products
+// out of it; the category must belong to the campus of ctx
+func (r *MarketplaceRepository) DeleteCategory(ctx context.Context, categoryID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&Category{}, categoryID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCategoryNotFound
+		}
+		if err := tx.Exec("DELETE FROM product_categories WHERE category_id = ?", categoryID).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM store_hours WHERE category_id = ?", categoryID).Error
+	})
+}
+
+// SlugTaken reports whether a category of the campus other than exceptID uses slug
+func (r *MarketplaceRepository) SlugTaken(ctx context.Context, slug string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Category{}).Where("slug = ? AND id <> ?", slug, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// ReplaceProductCategories sets the categories of a product to categoryIDs
+func (r *MarketplaceRepository) ReplaceProductCategories(ctx context.Context, productID uint, categoryIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_categories WHERE product_id = ?", productID).Error; err != nil {
+			return err
+		}
+		for _, categoryID := range categoryIDs {
+			if err := tx.Exec("INSERT INTO product_categories (product_id, category_id) VALUES (?, ?)", productID, categoryID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindStoreHours loads the opening schedules of the campus of ctx, the store's first
+func (r *MarketplaceRepository) FindStoreHours(ctx context.Context) ([]StoreHours, error) {
+	var hours []StoreHours
+	err := r.db.WithContext(ctx).Order("category_id, weekday").Find(&hours).Error
+	return hours, err
+}
+
+// ReplaceStoreHours replaces the schedule of the store (nil categoryID) or a category
+func (r *MarketplaceRepository) ReplaceStoreHours(ctx context.Context, categoryID *uint, days []StoreHours) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("category_id IS NULL")
+		if categoryID != nil {
+			query = tx.Where("category_id = ?", *categoryID)
+		}
+		if err := query.Delete(&StoreHours{}).Error; err != nil {
+			return err
+		}
+		if len(days) == 0 {
+			return nil
+		}
+		return tx.Create(&days).Error
+	})
+}
+
 // Create creates a new product
 func (r *MarketplaceRepository) Create(product *Product) error {
 	return r.db.Create(product).Error
@@ -65,9 +285,33 @@ func (r *MarketplaceRepository) Update(productID uint, updates map[string]interf
 	return r.db.Model(&Product{}).Where("id = ?", productID).Updates(updates).Error
 }
 
-// Delete deletes product (soft delete by setting status to inactive)
+// Delete soft deletes product together with the cart items that reference it
 func (r *MarketplaceRepository) Delete(productID uint) error {
-	return r.db.Model(&Product{}).Where("id = ?", productID).Update("status", "inactive").Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&Product{}, productID).Error; err != nil {
+			return err
+		}
+		return tx.Where("product_id = ?", productID).Delete(&CartItem{}).Error
+	})
+}
+
+// FindByIDUnscoped finds product by ID, including soft-deleted products
+func (r *MarketplaceRepository) FindByIDUnscoped(productID uint) (*Product, error) {
+	var product Product
+	err := r.db.Unscoped().First(&product, productID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// Restore clears the deletion mark of a soft-deleted product. Cart items removed
+// by the delete stay removed.
+func (r *MarketplaceRepository) Restore(productID uint) error {
+	return r.db.Unscoped().Model(&Product{}).Where("id = ?", productID).Update("deleted_at", nil).Error
 }
 
 // UpdateStock updates product stock
@@ -81,6 +325,44 @@ func (r *MarketplaceRepository) UpdateStock(tx *gorm.DB, productID uint, delta i
 		Error
 }
 
+// ReserveStock takes quantity units off a product's stock, failing with
+// ErrInsufficientStock rather than going below zero
+func (r *MarketplaceRepository) ReserveStock(tx *gorm.DB, productID uint, quantity int) error {
+	result := tx.Model(&Product{}).
+		Where("id = ? AND stock >= ?", productID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+// LockProducts loads products for update within tx, in ID order so concurrent
+// restocks of overlapping products cannot deadlock
+func (r *MarketplaceRepository) LockProducts(tx *gorm.DB, productIDs []uint) ([]Product, error) {
+	var products []Product
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", productIDs).
+		Order("id ASC").
+		Find(&products).Error
+	return products, err
+}
+
+// CreateInventoryEntries appends entries to the inventory ledger
+func (r *MarketplaceRepository) CreateInventoryEntries(tx *gorm.DB, entries []InventoryEntry) error {
+	return tx.Create(&entries).Error
+}
+
+// FindCartUserIDs returns the users who have productID in their cart
+func (r *MarketplaceRepository) FindCartUserIDs(tx *gorm.DB, productID uint) ([]uint, error) {
+	var userIDs []uint
+	err := tx.Model(&CartItem{}).Where("product_id = ?", productID).Distinct().Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
 func (r *MarketplaceRepository) AddToCart(item *CartItem) error {
 	var existing CartItem
 	err := r.db.Where("user_id = ? AND product_id = ?", item.UserID, item.ProductID).First(&existing).Error
@@ -90,9 +372,14 @@ func (r *MarketplaceRepository) AddToCart(item *CartItem) error {
 	return r.db.Create(item).Error
 }
 
+// GetCart loads the cart items of a user with their products in one joined query.
+// Only the product columns a cart needs are read; the rest of Product is left empty.
 func (r *MarketplaceRepository) GetCart(userID uint) ([]CartItem, error) {
 	var items []CartItem
-	err := r.db.Preload("Product").Where("user_id = ?", userID).Find(&items).Error
+	err := r.db.Joins("Product", r.db.Select(cartProductColumns)).
+		Where("cart_items.user_id = ?", userID).
+		Order("cart_items.id").
+		Find(&items).Error
 	return items, err
 }
 
@@ -119,22 +406,275 @@ func (r *MarketplaceRepository) CreateMarketplaceTransaction(tx *gorm.DB, txn *M
 	return tx.Create(txn).Error
 }
 
+// FindMarketplaceTransactions loads orders by ID in ID order
+func (r *MarketplaceRepository) FindMarketplaceTransactions(tx *gorm.DB, ids []uint) ([]MarketplaceTransaction, error) {
+	var txns []MarketplaceTransaction
+	err := tx.Where("id IN ?", ids).Order("id ASC").Find(&txns).Error
+	return txns, err
+}
+
+func (r *MarketplaceRepository) UpdateMarketplaceTransactionStatus(tx *gorm.DB, id uint, status string) error {
+	return tx.Model(&MarketplaceTransaction{}).Where("id = ?", id).Update("status", status).Error
+}
+
 // GetTransactions with details for admin monitoring
-func (r *MarketplaceRepository) GetTransactions(limit, page int) ([]MarketplaceTransactionWithDetails, int64, error) {
+func (r *MarketplaceRepository) GetTransactions(ctx context.Context, cursor *utils.Cursor, fields utils.Fields, limit, page int) ([]MarketplaceTransactionWithDetails, int64, error) {
 	var txns []MarketplaceTransactionWithDetails
 	var total int64
 
-	query := r.db.Table("marketplace_transactions t").
-		Select("t.*, p.name as product_name, u.full_name as user_name, u.email as user_email").
+	// The joins are all LEFT joins on the row's own keys, so they never change the count
+	tenant := utils.ScopeTenantOwner(ctx, "t.product_id", "products")
+	if err := r.replica.WithContext(ctx).Table("marketplace_transactions t").Scopes(tenant).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	columns := marketplaceTransactionDetailColumns
+	if selected := fields.Select(TransactionFields, "id", "created_at"); selected != nil {
+		columns = selected
+	}
+	query := r.replica.WithContext(ctx).Table("marketplace_transactions t").
+		Select(columns).
 		Joins("left join products p on p.id = t.product_id").
 		Joins("left join wallets w on w.id = t.wallet_id").
-		Joins("left join users u on u.id = w.user_id")
+		Joins("left join users u on u.id = w.user_id").
+		Scopes(tenant)
 
+	err := utils.Paginate(query, cursor, "t.created_at", "t.id", page, limit).Find(&txns).Error
+	return txns, total, err
+}
+
+// GetWalletTransactions lists the marketplace purchases paid from a wallet, newest first
+func (r *MarketplaceRepository) GetWalletTransactions(walletID uint, page, limit int) ([]MarketplaceTransaction, int64, error) {
+	var txns []MarketplaceTransaction
+	var total int64
+
+	query := r.db.Model(&MarketplaceTransaction{}).Where("wallet_id = ?", walletID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := utils.Paginate(query, nil, "created_at", "id", page, limit).Find(&txns).Error
+	return txns, total, err
+}
+
+// FindReceivedGifts lists the successful orders issued to userID by other
+// students, newest first; user_name is the buyer
+func (r *MarketplaceRepository) FindReceivedGifts(ctx context.Context, userID uint, page, limit int) ([]MarketplaceTransactionWithDetails, int64, error) {
+	var txns []MarketplaceTransactionWithDetails
+	var total int64
+
+	query := r.replica.WithContext(ctx).Table("marketplace_transactions t").
+		Where("t.recipient_user_id = ? AND t.status = ?", userID, "success")
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	offset := (page - 1) * limit
-	err := query.Order("t.created_at DESC").Limit(limit).Offset(offset).Find(&txns).Error
+	query = query.Select(marketplaceTransactionDetailColumns).
+		Joins("left join products p on p.id = t.product_id").
+		Joins("left join wallets w on w.id = t.wallet_id").
+		Joins("left join users u on u.id = w.user_id")
+	err := utils.Paginate(query, nil, "t.created_at", "t.id", page, limit).Find(&txns).Error
 	return txns, total, err
 }
+
+// FindActiveUser looks up an active user of the campus of ctx, nil when there is none
+func (r *MarketplaceRepository) FindActiveUser(ctx context.Context, userID uint) (*GiftParty, error) {
+	var party GiftParty
+	err := r.db.WithContext(ctx).Table("users").
+		Select("id, full_name, role").
+		Where("id = ? AND status = ? AND deleted_at IS NULL", userID, "active").
+		Scopes(utils.ScopeTenant(ctx, "tenant_id")).
+		Take(&party).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &party, err
+}
+
+// SpentSince sums the successful orders of a wallet since the given time
+func (r *MarketplaceRepository) SpentSince(tx *gorm.DB, walletID uint, since time.Time) (int, error) {
+	var total int
+	err := tx.Model(&MarketplaceTransaction{}).
+		Where("wallet_id = ? AND status = ? AND created_at >= ?", walletID, "success", since).
+		Select("COALESCE(SUM(total_amount), 0)").Scan(&total).Error
+	return total, err
+}
+
+// PurchasedQuantities returns the units of each product a wallet bought in successful orders
+func (r *MarketplaceRepository) PurchasedQuantities(tx *gorm.DB, walletID uint, productIDs []uint) (map[uint]int, error) {
+	var rows []struct {
+		ProductID uint
+		Quantity  int
+	}
+	err := tx.Model(&MarketplaceTransaction{}).
+		Select("product_id, COALESCE(SUM(quantity), 0) AS quantity").
+		Where("wallet_id = ? AND status = ? AND product_id IN ?", walletID, "success", productIDs).
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.ProductID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// FindLimitedProducts loads the products with a purchase limit among productIDs,
+// or every active one when productIDs is nil
+func (r *MarketplaceRepository) FindLimitedProducts(tx *gorm.DB, productIDs []uint) ([]Product, error) {
+	query := tx.Where("purchase_limit > 0")
+	if productIDs != nil {
+		query = query.Where("id IN ?", productIDs)
+	} else {
+		query = query.Where("status = ?", "active")
+	}
+	var products []Product
+	err := query.Order("name ASC").Find(&products).Error
+	return products, err
+}
+
+// FindUnresolvedStockAlert finds the current (not yet resolved) alert of a product
+func (r *MarketplaceRepository) FindUnresolvedStockAlert(productID uint) (*StockAlert, error) {
+	var alert StockAlert
+	err := r.db.Where("product_id = ? AND status <> ?", productID, "resolved").
+		Order("created_at DESC").
+		First(&alert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *MarketplaceRepository) FindStockAlertByID(alertID uint) (*StockAlert, error) {
+	var alert StockAlert
+	err := r.db.First(&alert, alertID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStockAlertNotFound
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *MarketplaceRepository) CreateStockAlert(alert *StockAlert) error {
+	return r.db.Create(alert).Error
+}
+
+func (r *MarketplaceRepository) UpdateStockAlert(alertID uint, updates map[string]interface{}) error {
+	return r.db.Model(&StockAlert{}).Where("id = ?", alertID).Updates(updates).Error
+}
+
+// GetStockAlerts lists alerts with product names, optionally filtered by status
+func (r *MarketplaceRepository) GetStockAlerts(status string) ([]StockAlertWithProduct, error) {
+	var alerts []StockAlertWithProduct
+	query := r.db.Table("stock_alerts").
+		Select("stock_alerts.*, products.name as product_name").
+		Joins("LEFT JOIN products ON products.id = stock_alerts.product_id")
+	if status != "" {
+		query = query.Where("stock_alerts.status = ?", status)
+	} else {
+		query = query.Where("stock_alerts.status <> ?", "resolved")
+	}
+	err := query.Order("stock_alerts.created_at DESC").Scan(&alerts).Error
+	return alerts, err
+}
+
+// FindExpiredSnoozedAlerts finds snoozed alerts whose snooze window has passed
+func (r *MarketplaceRepository) FindExpiredSnoozedAlerts(now time.Time) ([]StockAlert, error) {
+	var alerts []StockAlert
+	err := r.db.Where("status = ? AND snoozed_until <= ?", "snoozed", now).Find(&alerts).Error
+	return alerts, err
+}
+
+// DeleteStaleCartItems purges cart items untouched since before, items removed
+// from the cart before then and items whose product is no longer active
+func (r *MarketplaceRepository) DeleteStaleCartItems(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("updated_at < ? OR deleted_at < ? OR product_id NOT IN (?)", before, before, r.db.Model(&Product{}).Select("id").Where("status = ?", "active")).
+		Delete(&CartItem{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *MarketplaceRepository) CreateQuestion(ctx context.Context, question *ProductQuestion) error {
+	return r.db.WithContext(ctx).Create(question).Error
+}
+
+// FindQuestions lists product questions, the most helpful and then newest first
+func (r *MarketplaceRepository) FindQuestions(ctx context.Context, params QuestionListParams) ([]ProductQuestionWithNames, int64, error) {
+	var questions []ProductQuestionWithNames
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&ProductQuestion{})
+	if params.ProductID != 0 {
+		query = query.Where("product_questions.product_id = ?", params.ProductID)
+	}
+	if params.Status != "" {
+		query = query.Where("product_questions.status = ?", params.Status)
+	}
+	if params.ViewerID != 0 {
+		query = query.Where("(product_questions.status = ? OR product_questions.asker_id = ?)", QuestionPublished, params.ViewerID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.
+		Select("product_questions.*, users.full_name AS asker_name, products.name AS product_name").
+		Joins("LEFT JOIN users ON users.id = product_questions.asker_id").
+		Joins("LEFT JOIN products ON products.id = product_questions.product_id").
+		Order("product_questions.helpful_count DESC").Order("product_questions.created_at DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&questions).Error
+	return questions, total, err
+}
+
+// FindTopQuestions returns the most helpful answered questions of a product
+func (r *MarketplaceRepository) FindTopQuestions(ctx context.Context, productID uint, limit int) ([]ProductQuestionWithNames, error) {
+	var questions []ProductQuestionWithNames
+	err := r.db.WithContext(ctx).Model(&ProductQuestion{}).
+		Select("product_questions.*, users.full_name AS asker_name, products.name AS product_name").
+		Joins("LEFT JOIN users ON users.id = product_questions.asker_id").
+		Joins("LEFT JOIN products ON products.id = product_questions.product_id").
+		Where("product_questions.product_id = ? AND product_questions.status = ?", productID, QuestionPublished).
+		Order("product_questions.helpful_count DESC").Order("product_questions.answered_at DESC").
+		Limit(limit).
+		Find(&questions).Error
+	return questions, err
+}
+
+// LockQuestion loads a question for update within tx, so two admins cannot moderate it at once
+func (r *MarketplaceRepository) LockQuestion(tx *gorm.DB, questionID uint) (*ProductQuestion, error) {
+	var question ProductQuestion
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&question, questionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return &question, nil
+}
+
+func (r *MarketplaceRepository) UpdateQuestion(tx *gorm.DB, question *ProductQuestion, updates map[string]interface{}) error {
+	return tx.Model(question).Updates(updates).Error
+}
+
+// AddQuestionVote records userID finding a question helpful and counts it;
+// false when they already had
+func (r *MarketplaceRepository) AddQuestionVote(tx *gorm.DB, questionID, userID uint) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ProductQuestionVote{QuestionID: questionID, UserID: userID})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	err := tx.Model(&ProductQuestion{}).Where("id = ?", questionID).
+		UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	return err == nil, err
+}
@@ -0,0 +1,108 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"wallet-point/internal/events"
+	"wallet-point/internal/webhook"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// RestockProducts adds stock to several products of the campus of ctx at once:
+// either every item is applied, each with an inventory ledger entry, or none.
+// Products that were sold out publish stock.back_in_stock, whose subscriber
+// tells the students with the product in their cart.
+func (s *MarketplaceService) RestockProducts(ctx context.Context, items []RestockItem, adminID uint) ([]InventoryEntry, error) {
+	productIDs := make([]uint, 0, len(items))
+	byProduct := make(map[uint]RestockItem, len(items))
+	for _, item := range items {
+		if _, ok := byProduct[item.ProductID]; ok {
+			return nil, fmt.Errorf("%w: product %d", ErrDuplicateRestock, item.ProductID)
+		}
+		byProduct[item.ProductID] = item
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	var entries []InventoryEntry
+	var restocked []Product
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		products, err := s.repo.LockProducts(tx, productIDs)
+		if err != nil {
+			return err
+		}
+		if len(products) != len(productIDs) {
+			found := make(map[uint]bool, len(products))
+			for _, product := range products {
+				found[product.ID] = true
+			}
+			for _, id := range productIDs {
+				if !found[id] {
+					return fmt.Errorf("%w: product %d", ErrProductNotFound, id)
+				}
+			}
+		}
+
+		entries = make([]InventoryEntry, 0, len(products))
+		for i := range products {
+			product := &products[i]
+			item := byProduct[product.ID]
+			if err := s.repo.UpdateStock(tx, product.ID, item.Quantity); err != nil {
+				return err
+			}
+			soldOut := product.Stock <= 0
+			product.Stock += item.Quantity
+
+			entries = append(entries, InventoryEntry{
+				ProductID:  product.ID,
+				Change:     item.Quantity,
+				StockAfter: product.Stock,
+				Reason:     InventoryRestock,
+				Note:       item.Note,
+				CreatedBy:  adminID,
+				TenantID:   product.TenantID,
+			})
+			if soldOut && product.Status == "active" {
+				if err := s.publishBackInStock(tx, product); err != nil {
+					return err
+				}
+			}
+		}
+		if err := s.repo.CreateInventoryEntries(tx, entries); err != nil {
+			return err
+		}
+		restocked = products
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range restocked {
+		product := &restocked[i]
+		s.ProductChanged(product.ID)
+		utils.Go(func() { s.CheckLowStock(product.ID) })
+		s.publishProductEvent(webhook.EventProductUpdated, product)
+	}
+	return entries, nil
+}
+
+// publishBackInStock publishes stock.back_in_stock within the restock's
+// transaction; nobody to tell means no event
+func (s *MarketplaceService) publishBackInStock(tx *gorm.DB, product *Product) error {
+	if s.outbox == nil {
+		return nil
+	}
+	userIDs, err := s.repo.FindCartUserIDs(tx, product.ID)
+	if err != nil || len(userIDs) == 0 {
+		return err
+	}
+	return s.outbox.Publish(tx, events.EventBackInStock, events.BackInStock{
+		TenantID:    product.TenantID,
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Stock:       product.Stock,
+		UserIDs:     userIDs,
+	})
+}
@@ -0,0 +1,65 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wallet-point/utils"
+)
+
+// GetReturnPolicy returns the return window of a product: each of its
+// categories follows its own window or that of its nearest parent with one,
+// and the shortest applies. Windows are set per campus, on the categories of
+// the product's campus. Nil means orders of the product have no window.
+func (s *MarketplaceService) GetReturnPolicy(ctx context.Context, productID uint) (*ReturnPolicy, error) {
+	assigned, err := s.repo.FindCategoriesByProductIDs(ctx, []uint{productID})
+	if err != nil || len(assigned[productID]) == 0 {
+		return nil, err
+	}
+	// An unscoped caller (a super admin, background work) reads the product's campus
+	ctx = utils.WithTenant(ctx, assigned[productID][0].TenantID)
+	categories, err := s.repo.FindAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return returnPolicy(assigned[productID], indexCategories(categories)), nil
+}
+
+func returnPolicy(assigned []Category, index map[uint]*Category) *ReturnPolicy {
+	var policy *ReturnPolicy
+	for _, category := range assigned {
+		for id := &category.ID; id != nil && index[*id] != nil; id = index[*id].ParentID {
+			hours := index[*id].ReturnWindowHours
+			if hours == nil {
+				continue
+			}
+			if policy == nil || *hours < policy.WindowHours {
+				policy = &ReturnPolicy{
+					Returnable:   *hours > 0,
+					WindowHours:  *hours,
+					CategoryID:   *id,
+					CategoryName: index[*id].Name,
+				}
+			}
+			break
+		}
+	}
+	return policy
+}
+
+// CheckReturnWindow fails with ErrReturnWindowClosed when an order of the
+// product bought at purchasedAt can no longer be cancelled or returned
+func (s *MarketplaceService) CheckReturnWindow(ctx context.Context, productID uint, purchasedAt time.Time) error {
+	policy, err := s.GetReturnPolicy(ctx, productID)
+	if err != nil || policy == nil {
+		return err
+	}
+	if !policy.Returnable {
+		return fmt.Errorf("%w: %s orders cannot be cancelled or returned", ErrReturnWindowClosed, policy.CategoryName)
+	}
+	deadline := purchasedAt.Add(time.Duration(policy.WindowHours) * time.Hour)
+	if !time.Now().Before(deadline) {
+		return fmt.Errorf("%w: the window closed at %s", ErrReturnWindowClosed, deadline.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package marketplace
+
+import (
+	"fmt"
+	"wallet-point/internal/events"
+	"wallet-point/internal/metrics"
+	"wallet-point/internal/orders"
+	"wallet-point/internal/promotion"
+	"wallet-point/internal/saga"
+
+	"gorm.io/gorm"
+)
+
+// SagaOrder is the saga type of purchases and cart checkouts
+const SagaOrder = "marketplace.order.v2"
+
+// orderState is the persisted state of an order saga
+type orderState struct {
+	UserID   uint        `json:"user_id"`
+	WalletID uint        `json:"wallet_id"`
+	Source   string      `json:"source"` // events.OrderSourcePurchase or events.OrderSourceCheckout
+	Lines    []orderLine `json:"lines"`
+	// Promotions are the promotions taken off the lines, recorded as redeemed on completion
+	Promotions []promotion.Applied `json:"promotions,omitempty"`
+	// Gift issues the orders to another student; the buyer's wallet still pays
+	Gift *events.Gift `json:"gift,omitempty"`
+	// OrderID is the orders.Order of the create_order step, 0 without an order
+	// service; each line's OrderID is its marketplace transaction, written by
+	// the place_order step
+	OrderID uint `json:"order_id,omitempty"`
+}
+
+// recipientID is the user the orders are issued and delivered to
+func (o *orderState) recipientID() uint {
+	if o.Gift != nil {
+		return o.Gift.RecipientID
+	}
+	return o.UserID
+}
+
+// orderLine is one product of an order; OrderID is set by the place_order step
+type orderLine struct {
+	ProductID    uint   `json:"product_id"`
+	ProductName  string `json:"product_name"`
+	Price        int    `json:"price"`
+	Quantity     int    `json:"quantity"`
+	StudentName  string `json:"student_name,omitempty"`
+	StudentNPM   string `json:"student_npm,omitempty"`
+	StudentMajor string `json:"student_major,omitempty"`
+	StudentBatch string `json:"student_batch,omitempty"`
+	Discount     int    `json:"discount,omitempty"`   // points of promotions taken off the line
+	SessionID    uint   `json:"session_id,omitempty"` // tickets
+	OrderID      uint   `json:"order_id,omitempty"`
+	DebitID      uint   `json:"debit_id,omitempty"` // the wallet debit paying the line, 0 when free
+}
+
+func (l orderLine) total() int {
+	return l.Price*l.Quantity - l.Discount
+}
+
+// SetOrders records every purchase and checkout as an order with its items,
+// which students read as their purchase history
+func (s *MarketplaceService) SetOrders(orderService *orders.Service) {
+	s.orderBook = orderService
+}
+
+// SetSagas runs purchases and checkouts as sagas of coordinator: place the
+// order (stock, wallet and transactions in one database transaction), record
+// it and fulfill it, each step committed on its own and undone when a later
+// one fails
+func (s *MarketplaceService) SetSagas(coordinator *saga.Coordinator) {
+	s.sagas = coordinator
+	coordinator.Register(&saga.Definition{
+		Type:     SagaOrder,
+		NewState: func() interface{} { return &orderState{} },
+		Steps: []saga.Step{
+			{Name: "place_order", Run: s.placeOrder, Compensate: s.cancelPlacement},
+			{Name: "create_order", Run: s.createOrders, Compensate: s.failOrders},
+			// The pivot: delivered orders are never rolled back, a failure
+			// while completing them is retried by the saga recovery instead
+			{Name: "fulfill", Run: s.fulfillOrders, Pivot: true},
+		},
+		OnComplete: s.completeOrder,
+	})
+}
+
+// placeOrder takes the stock, checks the purchase limits, debits the wallet and
+// writes the marketplace transactions in one transaction, so an order is either
+// placed as a whole or not at all
+func (s *MarketplaceService) placeOrder(tx *gorm.DB, state interface{}) error {
+	if err := s.reserveStock(tx, state); err != nil {
+		return err
+	}
+	return s.debitWallet(tx, state)
+}
+
+func (s *MarketplaceService) cancelPlacement(tx *gorm.DB, state interface{}) error {
+	if err := s.refundWallet(tx, state); err != nil {
+		return err
+	}
+	return s.releaseStock(tx, state)
+}
+
+// reserveStock locks the products of the order, in ID order so checkouts of
+// overlapping carts cannot deadlock, and takes the quantities off their stock
+// once every line fits; the locks are held until the step commits
+func (s *MarketplaceService) reserveStock(tx *gorm.DB, state interface{}) error {
+	order := state.(*orderState)
+	needed := make(map[uint]int, len(order.Lines))
+	productIDs := make([]uint, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		if _, ok := needed[line.ProductID]; !ok {
+			productIDs = append(productIDs, line.ProductID)
+		}
+		needed[line.ProductID] += line.Quantity
+	}
+
+	products, err := s.repo.LockProducts(tx, productIDs)
+	if err != nil {
+		return err
+	}
+	stock := make(map[uint]int, len(products))
+	for _, product := range products {
+		stock[product.ID] = product.Stock
+	}
+	for _, line := range order.Lines {
+		if available, ok := stock[line.ProductID]; !ok || available < needed[line.ProductID] {
+			metrics.StockConflicts.WithLabelValues(order.Source).Inc()
+			return fmt.Errorf("%w for product '%s'", ErrInsufficientStock, line.ProductName)
+		}
+	}
+
+	for _, productID := range productIDs {
+		if err := s.repo.ReserveStock(tx, productID, needed[productID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MarketplaceService) releaseStock(tx *gorm.DB, state interface{}) error {
+	for _, line := range state.(*orderState).Lines {
+		if err := s.updateStock(tx, line.ProductID, line.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// debitWallet checks the purchase limits and the first purchase discount, pays
+// the order and writes its marketplace transactions, which both count, in one
+// transaction under the wallet lock
+func (s *MarketplaceService) debitWallet(tx *gorm.DB, state interface{}) error {
+	order := state.(*orderState)
+	if err := s.checkPurchaseLimits(tx, order); err != nil {
+		return err
+	}
+	if s.promotions != nil {
+		if err := s.promotions.CheckFirstPurchase(tx, order.WalletID, order.Promotions); err != nil {
+			return err
+		}
+	}
+	for i, line := range order.Lines {
+		if line.total() == 0 {
+			continue // free through promotions
+		}
+		desc := fmt.Sprintf("Purchase: %dx %s", line.Quantity, line.ProductName)
+		debitID, err := s.walletService.DebitWithTransactionID(tx, order.WalletID, line.total(), "marketplace", desc)
+		if err != nil {
+			return err
+		}
+		order.Lines[i].DebitID = debitID
+	}
+	return s.createTransactions(tx, order)
+}
+
+// refundWallet refunds the order and keeps its transactions, marked failed,
+// for the sales history
+func (s *MarketplaceService) refundWallet(tx *gorm.DB, state interface{}) error {
+	order := state.(*orderState)
+	for _, line := range order.Lines {
+		if line.OrderID != 0 {
+			if err := s.repo.UpdateMarketplaceTransactionStatus(tx, line.OrderID, "failed"); err != nil {
+				return err
+			}
+		}
+		if line.total() == 0 {
+			continue
+		}
+		desc := fmt.Sprintf("Refund: %dx %s, purchase rolled back", line.Quantity, line.ProductName)
+		if err := s.walletService.RefundWithTransaction(tx, order.WalletID, line.DebitID, line.total(), "marketplace", desc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MarketplaceService) createTransactions(tx *gorm.DB, order *orderState) error {
+	for i, line := range order.Lines {
+		txn := &MarketplaceTransaction{
+			WalletID:      order.WalletID,
+			ProductID:     line.ProductID,
+			Amount:        line.Price,
+			TotalAmount:   line.total(),
+			Quantity:      line.Quantity,
+			DebitID:       line.DebitID,
+			StudentName:   line.StudentName,
+			StudentNPM:    line.StudentNPM,
+			StudentMajor:  line.StudentMajor,
+			StudentBatch:  line.StudentBatch,
+			PaymentMethod: "wallet",
+			Status:        "success",
+		}
+		if order.Gift != nil {
+			recipientID := order.Gift.RecipientID
+			txn.RecipientID = &recipientID
+		}
+		if line.SessionID != 0 {
+			sessionID := line.SessionID
+			txn.SessionID = &sessionID
+		}
+		if err := s.repo.CreateMarketplaceTransaction(tx, txn); err != nil {
+			return err
+		}
+		order.Lines[i].OrderID = txn.ID
+	}
+	return nil
+}
+
+func (s *MarketplaceService) createOrders(tx *gorm.DB, state interface{}) error {
+	return s.recordOrder(tx, state.(*orderState))
+}
+
+// recordOrder creates the pending order of the lines
+func (s *MarketplaceService) recordOrder(tx *gorm.DB, order *orderState) error {
+	if s.orderBook == nil {
+		return nil
+	}
+	product, err := s.repo.FindByIDUnscoped(order.Lines[0].ProductID)
+	if err != nil {
+		return err
+	}
+	record := &orders.Order{
+		TenantID: product.TenantID,
+		UserID:   order.UserID,
+		WalletID: order.WalletID,
+		Source:   order.Source,
+		Items:    make([]orders.OrderItem, len(order.Lines)),
+	}
+	if order.Gift != nil {
+		recipientID := order.Gift.RecipientID
+		record.RecipientID = &recipientID
+	}
+	for i, line := range order.Lines {
+		record.Items[i] = orders.OrderItem{
+			ProductID:     line.ProductID,
+			ProductName:   line.ProductName,
+			UnitPrice:     line.Price,
+			Quantity:      line.Quantity,
+			Discount:      line.Discount,
+			Total:         line.total(),
+			TransactionID: line.OrderID,
+		}
+		record.Subtotal += line.Price * line.Quantity
+		record.Discount += line.Discount
+		record.Total += line.total()
+	}
+	if err := s.orderBook.Create(tx, record); err != nil {
+		return err
+	}
+	order.OrderID = record.ID
+	return nil
+}
+
+// failOrders cancels the order; its transactions are marked failed by refundWallet
+func (s *MarketplaceService) failOrders(tx *gorm.DB, state interface{}) error {
+	order := state.(*orderState)
+	if s.orderBook == nil || order.OrderID == 0 {
+		return nil
+	}
+	return s.orderBook.Cancel(tx, order.OrderID)
+}
+
+func (s *MarketplaceService) fulfillOrders(tx *gorm.DB, state interface{}) error {
+	order := state.(*orderState)
+	txns, err := s.orders(tx, order)
+	if err != nil {
+		return err
+	}
+	for i := range txns {
+		var product Product
+		if err := tx.Unscoped().First(&product, txns[i].ProductID).Error; err != nil {
+			return err
+		}
+		if err := s.fulfill(tx, order.recipientID(), &product, &txns[i]); err != nil {
+			return err
+		}
+		// Physical products are delivered when collected, see ItemDelivered
+		if s.orderBook != nil && product.Type != "" && product.Type != ProductPhysical {
+			if err := s.orderBook.ItemDelivered(tx, txns[i].ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ItemDelivered records that the order of a marketplace transaction was handed over
+func (s *MarketplaceService) ItemDelivered(tx *gorm.DB, transactionID uint) error {
+	if s.orderBook == nil {
+		return nil
+	}
+	return s.orderBook.ItemDelivered(tx, transactionID)
+}
+
+// completeOrder marks the order paid, empties the cart of a checkout, records
+// the redeemed promotions and publishes order.placed
+func (s *MarketplaceService) completeOrder(tx *gorm.DB, state interface{}) error {
+	order := state.(*orderState)
+	if s.orderBook != nil && order.OrderID != 0 {
+		if err := s.orderBook.MarkPaid(tx, order.OrderID); err != nil {
+			return err
+		}
+	}
+	if order.Source == events.OrderSourceCheckout {
+		if err := s.repo.ClearCart(tx, order.UserID); err != nil {
+			return err
+		}
+	}
+	if s.promotions != nil {
+		if err := s.promotions.Redeem(tx, order.UserID, order.Promotions); err != nil {
+			return err
+		}
+	}
+
+	txns, err := s.orders(tx, order)
+	if err != nil {
+		return err
+	}
+	placed := make([]events.Order, len(txns))
+	for i := range txns {
+		placed[i] = placedOrder(&txns[i], order.Lines[i].ProductName)
+	}
+	return s.publishOrderPlaced(tx, order, placed)
+}
+
+// orders loads the orders of the create_order step, in line order
+func (s *MarketplaceService) orders(tx *gorm.DB, order *orderState) ([]MarketplaceTransaction, error) {
+	ids := make([]uint, len(order.Lines))
+	for i, line := range order.Lines {
+		ids[i] = line.OrderID
+	}
+	txns, err := s.repo.FindMarketplaceTransactions(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) != len(ids) {
+		return nil, fmt.Errorf("order saga: found %d of %d orders", len(txns), len(ids))
+	}
+	return txns, nil
+}
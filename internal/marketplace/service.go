@@ -1,23 +1,49 @@
 package marketplace
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math"
+	"log/slog"
+	"strings"
+	"time"
 	"wallet-point/internal/auth"
+	"wallet-point/internal/cache"
+	"wallet-point/internal/events"
+	"wallet-point/internal/metrics"
+	"wallet-point/internal/orders"
+	"wallet-point/internal/promotion"
+	"wallet-point/internal/saga"
+	"wallet-point/internal/settings"
+	"wallet-point/internal/tracing"
 	"wallet-point/internal/wallet"
+	"wallet-point/internal/webhook"
+	"wallet-point/utils"
 
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
 type MarketplaceService struct {
-	repo          *MarketplaceRepository
-	walletService *wallet.WalletService
-	authService   *auth.AuthService
-	db            *gorm.DB
+	repo           Repository
+	walletService  *wallet.WalletService
+	authService    *auth.AuthService
+	outbox         *events.Outbox
+	settings       *settings.Service
+	cache          *cache.Cache
+	cacheTTL       time.Duration
+	db             *gorm.DB
+	webhooks       *webhook.Service
+	events         *events.Bus
+	maxLiveClients int
+	fulfillers     map[string]Fulfiller
+	sagas          *saga.Coordinator
+	promotions     *promotion.Service
+	orderBook      *orders.Service
+	storeCatalogs  StoreCatalogs
 }
 
-func NewMarketplaceService(repo *MarketplaceRepository, walletService *wallet.WalletService, authService *auth.AuthService, db *gorm.DB) *MarketplaceService {
+func NewMarketplaceService(repo Repository, walletService *wallet.WalletService, authService *auth.AuthService, db *gorm.DB) *MarketplaceService {
 	return &MarketplaceService{
 		repo:          repo,
 		walletService: walletService,
@@ -26,8 +52,15 @@ func NewMarketplaceService(repo *MarketplaceRepository, walletService *wallet.Wa
 	}
 }
 
+// SetSettings enables low-stock alerts against marketplace.low_stock_threshold
+// (publishing stock.low needs the outbox, see SetOutbox) and the daily spending
+// cap of marketplace.daily_spend_limit
+func (s *MarketplaceService) SetSettings(settingsService *settings.Service) {
+	s.settings = settingsService
+}
+
 // GetAllProducts gets all products with pagination and filters
-func (s *MarketplaceService) GetAllProducts(params ProductListParams) (*ProductListResponse, error) {
+func (s *MarketplaceService) GetAllProducts(ctx context.Context, params ProductListParams) (*ProductListResponse, error) {
 	// Default pagination
 	if params.Page < 1 {
 		params.Page = 1
@@ -35,50 +68,150 @@ func (s *MarketplaceService) GetAllProducts(params ProductListParams) (*ProductL
 	if params.Limit < 1 {
 		params.Limit = 20
 	}
+	if err := s.categoryFilter(ctx, &params); err != nil {
+		return nil, err
+	}
+	if err := s.storeFilter(ctx, &params); err != nil {
+		return nil, err
+	}
 
-	products, total, err := s.repo.GetAll(params)
+	var cacheKey string
+	if s.cache != nil && s.cache.Enabled() {
+		cacheKey = s.productListCacheKey(ctx, params)
+		var cached ProductListResponse
+		if cacheKey != "" && s.cache.GetJSON(ctx, productCacheName, cacheKey, &cached) {
+			return s.decorateList(ctx, params, &cached)
+		}
+	}
+
+	products, total, err := s.repo.GetAll(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+	nextCursor := utils.NextCursor(products, params.Limit, func(p Product) (time.Time, uint) {
+		return p.CreatedAt, p.ID
+	})
 
-	return &ProductListResponse{
+	response := &ProductListResponse{
 		Products:   products,
-		Total:      total,
-		Page:       params.Page,
-		Limit:      params.Limit,
-		TotalPages: totalPages,
-	}, nil
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, nextCursor),
+	}
+	if cacheKey != "" {
+		s.cache.SetJSON(ctx, cacheKey, response, s.cacheTTL)
+	}
+
+	// Category trees and availability are attached after caching so category
+	// edits and opening hours show at once
+	return s.decorateList(ctx, params, response)
+}
+
+// decorateList attaches the store status to a product page and category trees
+// and availability to its products, unless narrowed with fields=
+func (s *MarketplaceService) decorateList(ctx context.Context, params ProductListParams, response *ProductListResponse) (*ProductListResponse, error) {
+	products := response.Products
+	if params.Fields != nil {
+		products = nil
+	}
+	store, err := s.decorateProducts(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	response.Store = store
+	return response, nil
 }
 
 // GetProductByID gets product by ID
-func (s *MarketplaceService) GetProductByID(productID uint) (*Product, error) {
-	return s.repo.FindByID(productID)
+func (s *MarketplaceService) GetProductByID(ctx context.Context, productID uint) (*Product, error) {
+	if s.cache != nil {
+		var cached Product
+		if s.cache.GetJSON(ctx, productCacheName, productCacheKey(productID), &cached) {
+			if !utils.InTenant(ctx, cached.TenantID) {
+				return nil, ErrProductNotFound
+			}
+			return &cached, nil
+		}
+	}
+
+	product, err := s.repo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		s.cache.SetJSON(ctx, productCacheKey(productID), product, s.cacheTTL)
+	}
+	if !utils.InTenant(ctx, product.TenantID) {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
 }
 
-// CreateProduct creates a new product
-func (s *MarketplaceService) CreateProduct(req *CreateProductRequest, adminID uint) (*Product, error) {
+// findProduct loads a product of the campus of ctx; other campuses' products are not found
+func (s *MarketplaceService) findProduct(ctx context.Context, productID uint, find func(uint) (*Product, error)) (*Product, error) {
+	product, err := find(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.InTenant(ctx, product.TenantID) {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
+
+// CreateProduct creates a new product in the campus of ctx
+func (s *MarketplaceService) CreateProduct(ctx context.Context, req *CreateProductRequest, adminID uint) (*Product, error) {
+	if req.Type == "" {
+		req.Type = ProductPhysical
+	}
+	if req.Type == ProductPrintQuota && req.PrintPages < 1 {
+		return nil, ErrPrintPagesRequired
+	}
+	if req.Type == ProductTicket {
+		// The stock of a ticket product is the seats left in its sessions
+		req.Stock = 0
+	}
+	if err := s.checkCategories(ctx, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
 	product := &Product{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
-		ImageURL:    req.ImageURL,
-		Status:      "active",
-		CreatedBy:   adminID,
+		Name:              req.Name,
+		Description:       req.Description,
+		Price:             req.Price,
+		Stock:             req.Stock,
+		ImageURL:          req.ImageURL,
+		Status:            "active",
+		CreatedBy:         adminID,
+		LowStockThreshold: req.LowStockThreshold,
+		PurchaseLimit:     req.PurchaseLimit,
+		Type:              req.Type,
+		PrintPages:        req.PrintPages,
+		PickupLocation:    strings.TrimSpace(req.PickupLocation),
+	}
+	if tenantID, ok := utils.TenantFromContext(ctx); ok {
+		product.TenantID = tenantID
 	}
 
 	if err := s.repo.Create(product); err != nil {
-		return nil, errors.New("failed to create product")
+		return nil, ErrCreateProductFailed
+	}
+	if len(req.CategoryIDs) > 0 {
+		if err := s.repo.ReplaceProductCategories(ctx, product.ID, uniqueIDs(req.CategoryIDs)); err != nil {
+			return nil, err
+		}
 	}
 
+	s.ProductChanged(product.ID)
+	utils.Go(func() { s.CheckLowStock(product.ID) })
+	s.publishProductEvent(webhook.EventProductCreated, product)
+
 	return product, nil
 }
 
 // UpdateProduct updates product
-func (s *MarketplaceService) UpdateProduct(productID uint, req *UpdateProductRequest) (*Product, error) {
-	_, err := s.repo.FindByID(productID)
+func (s *MarketplaceService) UpdateProduct(ctx context.Context, productID uint, req *UpdateProductRequest) (*Product, error) {
+	product, err := s.findProduct(ctx, productID, s.repo.FindByID)
 	if err != nil {
 		return nil, err
 	}
@@ -93,7 +226,7 @@ func (s *MarketplaceService) UpdateProduct(productID uint, req *UpdateProductReq
 	if req.Price > 0 {
 		updates["price"] = req.Price
 	}
-	if req.Stock >= 0 {
+	if req.Stock >= 0 && product.Type != ProductTicket {
 		updates["stock"] = req.Stock
 	}
 	if req.ImageURL != "" {
@@ -102,27 +235,93 @@ func (s *MarketplaceService) UpdateProduct(productID uint, req *UpdateProductReq
 	if req.Status != "" {
 		updates["status"] = req.Status
 	}
+	if req.LowStockThreshold != nil {
+		updates["low_stock_threshold"] = *req.LowStockThreshold
+	}
+	if req.PrintPages != nil {
+		updates["print_pages"] = *req.PrintPages
+	}
+	if req.PurchaseLimit != nil {
+		updates["purchase_limit"] = *req.PurchaseLimit
+	}
+	if req.PickupLocation != nil {
+		updates["pickup_location"] = strings.TrimSpace(*req.PickupLocation)
+	}
 
-	if len(updates) > 0 {
+	changed := len(updates) > 0
+	if changed {
 		if err := s.repo.Update(productID, updates); err != nil {
-			return nil, errors.New("failed to update product")
+			return nil, ErrUpdateProductFailed
+		}
+	}
+	if req.CategoryIDs != nil {
+		if err := s.setProductCategories(ctx, productID, *req.CategoryIDs); err != nil {
+			return nil, err
 		}
+		changed = true
+	}
+	if changed {
+		s.ProductChanged(productID)
 	}
 
-	return s.repo.FindByID(productID)
+	utils.Go(func() { s.CheckLowStock(productID) })
+
+	product, err = s.repo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		s.publishProductEvent(webhook.EventProductUpdated, product)
+	}
+	return product, nil
 }
 
 // DeleteProduct deletes product
-func (s *MarketplaceService) DeleteProduct(productID uint) error {
-	_, err := s.repo.FindByID(productID)
+func (s *MarketplaceService) DeleteProduct(ctx context.Context, productID uint) error {
+	product, err := s.findProduct(ctx, productID, s.repo.FindByID)
 	if err != nil {
 		return err
 	}
-	return s.repo.Delete(productID)
+	if err := s.repo.Delete(productID); err != nil {
+		return err
+	}
+
+	s.ProductChanged(productID)
+	s.publishProductEvent(webhook.EventProductDeleted, product)
+	return nil
+}
+
+// RestoreProduct brings back a soft-deleted product
+func (s *MarketplaceService) RestoreProduct(ctx context.Context, productID uint) (*Product, error) {
+	product, err := s.findProduct(ctx, productID, s.repo.FindByIDUnscoped)
+	if err != nil {
+		return nil, err
+	}
+	if !product.DeletedAt.Valid {
+		return nil, ErrProductNotDeleted
+	}
+
+	if err := s.repo.Restore(productID); err != nil {
+		return nil, err
+	}
+
+	s.ProductChanged(productID)
+	product, err = s.repo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	s.publishProductEvent(webhook.EventProductRestored, product)
+	return product, nil
 }
 
 // PurchaseProduct handles product purchase without a dedicated marketplace_transactions table
-func (s *MarketplaceService) PurchaseProduct(userID uint, req *PurchaseRequest) error {
+func (s *MarketplaceService) PurchaseProduct(ctx context.Context, userID uint, req *PurchaseRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "marketplace.PurchaseProduct", attribute.Int("user.id", int(userID)), attribute.Int("product.id", int(req.ProductID)))
+	defer func() {
+		tracing.End(span, err)
+		metrics.ObserveWalletOperation("purchase", err)
+	}()
+
 	// 1. Verify PIN if using direct wallet
 	if req.PaymentMethod == "wallet" || req.PaymentMethod == "" {
 		if err := s.authService.VerifyPIN(userID, req.PIN); err != nil {
@@ -130,16 +329,23 @@ func (s *MarketplaceService) PurchaseProduct(userID uint, req *PurchaseRequest)
 		}
 	}
 
-	product, err := s.repo.FindByID(req.ProductID)
+	product, err := s.findProduct(ctx, req.ProductID, s.repo.FindByID)
 	if err != nil {
 		return err
 	}
 
 	if product.Status == "inactive" {
-		return errors.New("product is not active")
+		return ErrProductInactive
+	}
+	if err := s.checkDeliverable(product); err != nil {
+		return err
+	}
+	if err := s.checkStoreOpen(ctx, []Product{*product}); err != nil {
+		return err
 	}
 	if product.Stock < 1 {
-		return errors.New("product out of stock")
+		metrics.StockConflicts.WithLabelValues("purchase").Inc()
+		return ErrProductOutOfStock
 	}
 
 	studentWallet, err := s.walletService.GetWalletByUserID(userID)
@@ -147,70 +353,102 @@ func (s *MarketplaceService) PurchaseProduct(userID uint, req *PurchaseRequest)
 		return err
 	}
 
+	gift, err := s.giftFor(ctx, userID, req.RecipientUserID)
+	if err != nil {
+		return err
+	}
+
 	quantity := req.Quantity
 	if quantity <= 0 {
 		quantity = 1
 	}
-	totalPrice := product.Price * quantity
-
-	if studentWallet.Balance < totalPrice {
-		return fmt.Errorf("insufficient balance. Required: %d", totalPrice)
+	recipientID := userID
+	if gift != nil {
+		recipientID = gift.RecipientID
+	}
+	if err := s.checkOrder(ctx, recipientID, product, quantity, req.SessionID); err != nil {
+		return err
+	}
+	order := &orderState{
+		UserID:   userID,
+		WalletID: studentWallet.ID,
+		Source:   events.OrderSourcePurchase,
+		Gift:     gift,
+		Lines: []orderLine{{
+			ProductID:    product.ID,
+			ProductName:  product.Name,
+			Price:        product.Price,
+			Quantity:     quantity,
+			StudentName:  req.StudentName,
+			StudentNPM:   req.StudentNPM,
+			StudentMajor: req.StudentMajor,
+			StudentBatch: req.StudentBatch,
+			SessionID:    req.SessionID,
+		}},
+	}
+	pricing, err := s.priceOrder(ctx, studentWallet.ID, order)
+	if err != nil {
+		return err
 	}
 
-	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// 1. Debit Student Wallet
-		desc := fmt.Sprintf("Purchase: %dx %s", quantity, product.Name)
-		if err := s.walletService.DebitWithTransaction(tx, studentWallet.ID, totalPrice, "marketplace", desc); err != nil {
-			return err
-		}
-
-		// 2. Reduce Stock
-		if err := s.repo.UpdateStock(tx, product.ID, -quantity); err != nil {
-			return err
-		}
+	if studentWallet.Balance < pricing.Total {
+		return fmt.Errorf("%w. Required: %d", wallet.ErrInsufficientBalance, pricing.Total)
+	}
 
-		// 3. Record in Marketplace Transactions
-		txn := &MarketplaceTransaction{
-			WalletID:      studentWallet.ID,
-			ProductID:     product.ID,
-			Amount:        product.Price,
-			TotalAmount:   totalPrice,
-			Quantity:      quantity,
-			StudentName:   req.StudentName,
-			StudentNPM:    req.StudentNPM,
-			StudentMajor:  req.StudentMajor,
-			StudentBatch:  req.StudentBatch,
-			PaymentMethod: "wallet",
-			Status:        "success",
-		}
-		if err := s.repo.CreateMarketplaceTransaction(tx, txn); err != nil {
-			return err
-		}
+	// Stock, wallet, order and fulfillment change in steps (see SetSagas)
+	err = s.sagas.Run(ctx, SagaOrder, order)
 
-		return nil
-	})
+	s.ProductChanged(product.ID)
+	if err == nil {
+		utils.Go(func() { s.CheckLowStock(product.ID) })
+	}
 
 	return err
 }
 
 // GetTransactions retrieves all marketplace transactions from consolidated wallet_transactions (Admin)
-func (s *MarketplaceService) GetTransactions(limit, page int) ([]MarketplaceTransactionWithDetails, int64, error) {
+func (s *MarketplaceService) GetTransactions(ctx context.Context, cursor *utils.Cursor, fields utils.Fields, limit, page int) ([]MarketplaceTransactionWithDetails, int64, error) {
 	if page < 1 {
 		page = 1
 	}
-	return s.repo.GetTransactions(limit, page)
+	return s.repo.GetTransactions(ctx, cursor, fields, limit, page)
+}
+
+// GetProductsByIDs loads several products at once, bypassing the product cache
+func (s *MarketplaceService) GetProductsByIDs(ctx context.Context, productIDs []uint) ([]Product, error) {
+	return s.repo.FindByIDs(ctx, productIDs)
+}
+
+// GetProductCategories loads the categories of several products at once, keyed by product ID
+func (s *MarketplaceService) GetProductCategories(ctx context.Context, productIDs []uint) (map[uint][]Category, error) {
+	return s.repo.FindCategoriesByProductIDs(ctx, productIDs)
+}
+
+// GetWalletTransactions lists the marketplace purchases paid from a wallet, newest first
+func (s *MarketplaceService) GetWalletTransactions(walletID uint, page, limit int) ([]MarketplaceTransaction, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	return s.repo.GetWalletTransactions(walletID, page, limit)
 }
 
 // Cart Methods
 
-func (s *MarketplaceService) AddToCart(userID uint, req AddToCartRequest) error {
+func (s *MarketplaceService) AddToCart(ctx context.Context, userID uint, req AddToCartRequest) error {
 	// Check if product exists and has stock
-	product, err := s.repo.FindByID(req.ProductID)
+	product, err := s.findProduct(ctx, req.ProductID, s.repo.FindByID)
 	if err != nil {
 		return err
 	}
+	if product.Type == ProductTicket {
+		return ErrTicketNotInCart
+	}
 	if product.Stock < req.Quantity {
-		return errors.New("stok tidak mencukupi")
+		metrics.StockConflicts.WithLabelValues("cart").Inc()
+		return ErrInsufficientStock
 	}
 
 	item := &CartItem{
@@ -221,20 +459,32 @@ func (s *MarketplaceService) AddToCart(userID uint, req AddToCartRequest) error
 	return s.repo.AddToCart(item)
 }
 
-func (s *MarketplaceService) GetCart(userID uint) (*CartResponse, error) {
+// GetCart returns the cart priced as checkout would charge it now
+func (s *MarketplaceService) GetCart(ctx context.Context, userID uint) (*CartResponse, error) {
 	items, err := s.repo.GetCart(userID)
 	if err != nil {
 		return nil, err
 	}
+	studentWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
 
-	totalPrice := 0
+	order := &orderState{Lines: make([]orderLine, 0, len(items))}
 	for _, item := range items {
-		totalPrice += item.Product.Price * item.Quantity
+		order.Lines = append(order.Lines, orderLine{ProductID: item.ProductID, Price: item.Product.Price, Quantity: item.Quantity})
+	}
+	pricing, err := s.priceOrder(ctx, studentWallet.ID, order)
+	if err != nil {
+		return nil, err
 	}
 
 	return &CartResponse{
 		Items:      items,
-		TotalPrice: totalPrice,
+		Subtotal:   pricing.Subtotal,
+		Discount:   pricing.Discount,
+		Promotions: pricing.Applied,
+		TotalPrice: pricing.Total,
 	}, nil
 }
 
@@ -246,69 +496,192 @@ func (s *MarketplaceService) RemoveFromCart(userID, itemID uint) error {
 	return s.repo.RemoveFromCart(userID, itemID)
 }
 
-func (s *MarketplaceService) Checkout(userID uint, req CartCheckoutRequest) error {
+// CleanupCarts purges cart items untouched or removed ttlDays ago and items whose product was deactivated or deleted
+func (s *MarketplaceService) CleanupCarts(ctx context.Context, ttlDays int) (string, error) {
+	removed, err := s.repo.DeleteStaleCartItems(ctx, time.Now().AddDate(0, 0, -ttlDays))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("removed %d cart items", removed), nil
+}
+
+func (s *MarketplaceService) Checkout(ctx context.Context, userID uint, req CartCheckoutRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "marketplace.Checkout", attribute.Int("user.id", int(userID)))
+	defer func() {
+		tracing.End(span, err)
+		metrics.ObserveWalletOperation("checkout", err)
+	}()
+
 	// 1. Verify PIN
 	if err := s.authService.VerifyPIN(userID, req.PIN); err != nil {
 		return err
 	}
 
-	// 2. Get Cart Items
-	items, err := s.repo.GetCart(userID)
+	// 2. Check the cart, its stock and opening hours and price it with the
+	// running promotions, as a quote does
+	plan, err := s.planCheckout(ctx, userID, req.RecipientUserID)
 	if err != nil {
 		return err
 	}
-	if len(items) == 0 {
-		return errors.New("keranjang belanja kosong")
+	if len(plan.Issues) > 0 {
+		if errors.Is(plan.Issues[0], ErrInsufficientStock) {
+			metrics.StockConflicts.WithLabelValues("checkout").Inc()
+		}
+		return plan.Issues[0]
 	}
+	items, order, pricing := plan.Items, plan.Order, plan.Pricing
+
+	// 3. Run the order saga (see SetSagas)
+	span.SetAttributes(attribute.Int("cart.items", len(items)), attribute.Int("cart.total", pricing.Total))
+	err = s.sagas.Run(ctx, SagaOrder, order)
 
-	// 3. Calculate total and check stock
-	totalPrice := 0
 	for _, item := range items {
-		if item.Product.Stock < item.Quantity {
-			return fmt.Errorf("stok produk '%s' tidak mencukupi", item.Product.Name)
+		productID := item.ProductID
+		s.ProductChanged(productID)
+		if err == nil {
+			utils.Go(func() { s.CheckLowStock(productID) })
 		}
-		totalPrice += item.Product.Price * item.Quantity
 	}
 
-	// 4. Check balance
-	wallet, err := s.walletService.GetWalletByUserID(userID)
+	return err
+}
+
+// updateStock wraps the stock change of a purchase in its own span
+func (s *MarketplaceService) updateStock(tx *gorm.DB, productID uint, delta int) (err error) {
+	ctx, span := tracing.Start(tx.Statement.Context, "marketplace.UpdateStock", attribute.Int("product.id", int(productID)), attribute.Int("stock.delta", delta))
+	defer func() { tracing.End(span, err) }()
+
+	return s.repo.UpdateStock(tx.WithContext(ctx), productID, delta)
+}
+
+// Low-stock alerts
+
+// CheckLowStock opens, re-fires or resolves the low-stock alert of a product based on its current stock
+func (s *MarketplaceService) CheckLowStock(productID uint) {
+	if s.outbox == nil || s.settings == nil {
+		return
+	}
+
+	product, err := s.repo.FindByID(productID)
 	if err != nil {
-		return err
+		slog.Error("stock alert: product lookup failed", "product_id", productID, "error", err)
+		return
 	}
-	if wallet.Balance < totalPrice {
-		return fmt.Errorf("saldo tidak cukup. Total: %d, Saldo: %d", totalPrice, wallet.Balance)
+
+	threshold := product.LowStockThreshold
+	if threshold <= 0 {
+		threshold = s.settings.Int(settings.LowStockThreshold)
 	}
 
-	// 5. Execute Transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		for _, item := range items {
-			// Debit wallet for each item
-			desc := fmt.Sprintf("Purchase: %dx %s", item.Quantity, item.Product.Name)
-			if err := s.walletService.DebitWithTransaction(tx, wallet.ID, item.Product.Price*item.Quantity, "marketplace", desc); err != nil {
-				return err
-			}
+	alert, err := s.repo.FindUnresolvedStockAlert(productID)
+	if err != nil {
+		slog.Error("stock alert: alert lookup failed", "product_id", productID, "error", err)
+		return
+	}
 
-			// Reduce stock
-			if err := s.repo.UpdateStock(tx, item.ProductID, -item.Quantity); err != nil {
-				return err
-			}
+	now := time.Now()
 
-			// Record in Marketplace Transactions
-			txn := &MarketplaceTransaction{
-				WalletID:      wallet.ID,
-				ProductID:     item.ProductID,
-				Amount:        item.Product.Price,
-				TotalAmount:   item.Product.Price * item.Quantity,
-				Quantity:      item.Quantity,
-				PaymentMethod: "wallet",
-				Status:        "success",
-			}
-			if err := s.repo.CreateMarketplaceTransaction(tx, txn); err != nil {
-				return err
-			}
+	// Restocked (or deactivated): close the current alert so a future drop fires again
+	if product.Stock > threshold || product.Status == "inactive" {
+		if alert != nil {
+			s.repo.UpdateStockAlert(alert.ID, map[string]interface{}{
+				"status":      "resolved",
+				"stock":       product.Stock,
+				"resolved_at": now,
+			})
 		}
+		return
+	}
+
+	if alert == nil {
+		alert = &StockAlert{
+			ProductID: productID,
+			Stock:     product.Stock,
+			Threshold: threshold,
+			Status:    "open",
+		}
+		if err := s.repo.CreateStockAlert(alert); err != nil {
+			slog.Error("stock alert: create alert failed", "product_id", productID, "error", err)
+			return
+		}
+		s.fireStockAlert(alert, product)
+		return
+	}
+
+	// Open or acknowledged alerts already reached their admins; snoozed ones wait for the snooze window
+	s.repo.UpdateStockAlert(alert.ID, map[string]interface{}{"stock": product.Stock})
+	if alert.Status == "snoozed" && alert.SnoozedUntil != nil && now.After(*alert.SnoozedUntil) {
+		s.fireStockAlert(alert, product)
+	}
+}
+
+// ProcessSnoozedStockAlerts re-fires alerts whose snooze window has passed and are still low
+func (s *MarketplaceService) ProcessSnoozedStockAlerts() {
+	alerts, err := s.repo.FindExpiredSnoozedAlerts(time.Now())
+	if err != nil {
+		slog.Error("stock alert: snoozed alert lookup failed", "error", err)
+		return
+	}
+	for _, alert := range alerts {
+		s.CheckLowStock(alert.ProductID)
+	}
+}
 
-		// Clear cart
-		return s.repo.ClearCart(tx, userID)
+// StartStockAlertWatcher periodically re-checks snoozed alerts
+func (s *MarketplaceService) StartStockAlertWatcher(interval time.Duration) {
+	utils.Go(func() {
+		for utils.Sleep(interval) {
+			s.ProcessSnoozedStockAlerts()
+		}
+	})
+}
+
+// fireStockAlert publishes stock.low; the notification subscriber tells the admins
+func (s *MarketplaceService) fireStockAlert(alert *StockAlert, product *Product) {
+	if err := s.publishStockLow(product, alert.Threshold); err != nil {
+		return
+	}
+
+	s.repo.UpdateStockAlert(alert.ID, map[string]interface{}{
+		"status":           "open",
+		"snoozed_until":    nil,
+		"last_notified_at": time.Now(),
+	})
+}
+
+// GetStockAlerts lists low-stock alerts (Admin)
+func (s *MarketplaceService) GetStockAlerts(status string) ([]StockAlertWithProduct, error) {
+	return s.repo.GetStockAlerts(status)
+}
+
+// AcknowledgeStockAlert silences an alert until the product is restocked
+func (s *MarketplaceService) AcknowledgeStockAlert(alertID, adminID uint) error {
+	alert, err := s.repo.FindStockAlertByID(alertID)
+	if err != nil {
+		return err
+	}
+	if alert.Status == "resolved" {
+		return ErrStockAlertResolved
+	}
+	return s.repo.UpdateStockAlert(alertID, map[string]interface{}{
+		"status":          "acknowledged",
+		"acknowledged_by": adminID,
+		"acknowledged_at": time.Now(),
+		"snoozed_until":   nil,
+	})
+}
+
+// SnoozeStockAlert silences an alert for a number of hours, after which it fires again if still low
+func (s *MarketplaceService) SnoozeStockAlert(alertID uint, hours int) error {
+	alert, err := s.repo.FindStockAlertByID(alertID)
+	if err != nil {
+		return err
+	}
+	if alert.Status == "resolved" {
+		return ErrStockAlertResolved
+	}
+	return s.repo.UpdateStockAlert(alertID, map[string]interface{}{
+		"status":        "snoozed",
+		"snoozed_until": time.Now().Add(time.Duration(hours) * time.Hour),
 	})
 }
@@ -0,0 +1,29 @@
+package marketplace
+
+import "context"
+
+// StoreCatalogs resolves faculty stores, branded sub-stores of the campus that
+// sell a subset of its catalog (see internal/faculty)
+type StoreCatalogs interface {
+	// CatalogProductIDs returns the products of the active store with code
+	CatalogProductIDs(ctx context.Context, code string) ([]uint, error)
+}
+
+// SetStoreCatalogs lets product lists be narrowed to a faculty store's catalog
+func (s *MarketplaceService) SetStoreCatalogs(catalogs StoreCatalogs) {
+	s.storeCatalogs = catalogs
+}
+
+// storeFilter resolves params.Store to the products of its catalog
+func (s *MarketplaceService) storeFilter(ctx context.Context, params *ProductListParams) error {
+	if params.Store == "" || s.storeCatalogs == nil {
+		params.Store = ""
+		return nil
+	}
+	productIDs, err := s.storeCatalogs.CatalogProductIDs(ctx, params.Store)
+	if err != nil {
+		return err
+	}
+	params.ProductIDs = productIDs
+	return nil
+}
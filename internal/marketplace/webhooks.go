@@ -0,0 +1,23 @@
+package marketplace
+
+import (
+	"log/slog"
+	"wallet-point/internal/webhook"
+)
+
+// SetWebhooks publishes product.* events; order.created is sent by the
+// webhook subscriber of order.placed (see SetOutbox)
+func (s *MarketplaceService) SetWebhooks(webhooks *webhook.Service) {
+	s.webhooks = webhooks
+}
+
+// publishProductEvent runs after the product change committed; a failure to
+// queue the event is logged rather than undoing the admin's change
+func (s *MarketplaceService) publishProductEvent(eventType string, product *Product) {
+	if s.webhooks == nil {
+		return
+	}
+	if err := s.webhooks.Publish(nil, eventType, product); err != nil {
+		slog.Error("failed to publish product webhook", "event", eventType, "product_id", product.ID, "error", err)
+	}
+}
@@ -0,0 +1,27 @@
+package messaging
+
+import (
+	"context"
+	"wallet-point/internal/events"
+)
+
+// OnPointsDebited is the messaging subscriber of points.debited: every debit of
+// a wallet, whether a transfer, a checkout, a points gift, a POS charge or a
+// campus system's debit, sends its owner the large transaction notice when it
+// reaches the threshold
+func (s *MessagingService) OnPointsDebited(ctx context.Context, event *events.DomainEvent) error {
+	var entry events.LedgerEntry
+	if err := event.Decode(&entry); err != nil {
+		return err
+	}
+	if s.config.LargeTransactionThreshold <= 0 || entry.Amount < s.config.LargeTransactionThreshold {
+		return nil
+	}
+
+	userID, err := s.repo.FindWalletOwner(ctx, entry.WalletID)
+	if err != nil {
+		return err
+	}
+	s.NotifyLargeTransaction(userID, entry.Amount, entry.Description)
+	return nil
+}
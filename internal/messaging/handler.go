@@ -0,0 +1,192 @@
+package messaging
+
+import (
+	"net/http"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MessagingHandler struct {
+	service      *MessagingService
+	auditService audit.Logger
+}
+
+func NewMessagingHandler(service *MessagingService, auditService audit.Logger) *MessagingHandler {
+	return &MessagingHandler{service: service, auditService: auditService}
+}
+
+// GetPhone handles getting the current user's phone number
+// @Summary Get my phone number
+// @Description Get the registered phone number and verification status
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=UserPhone}
+// @Failure 404 {object} utils.Response
+// @Router /auth/phone [get]
+func (h *MessagingHandler) GetPhone(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	phone, err := h.service.GetPhone(userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Phone number retrieved successfully", phone)
+}
+
+// RegisterPhone handles registering a phone number for critical messages
+// @Summary Register phone number
+// @Description Register a phone number (E.164) and send a verification code to it
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RegisterPhoneRequest true "Phone details"
+// @Success 200 {object} utils.Response{data=UserPhone}
+// @Failure 400 {object} utils.Response
+// @Router /auth/phone [post]
+func (h *MessagingHandler) RegisterPhone(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req RegisterPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	phone, err := h.service.RegisterPhone(userID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification code sent", phone)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "REGISTER_PHONE",
+		Entity:    "USER",
+		EntityID:  userID,
+		Details:   "User registered phone number via " + phone.Channel,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// VerifyPhone handles phone number verification
+// @Summary Verify phone number
+// @Description Confirm the registered phone number with the code that was sent to it
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body VerifyPhoneRequest true "Verification code"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /auth/phone/verify [post]
+func (h *MessagingHandler) VerifyPhone(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.service.VerifyPhone(userID, req.Code); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Phone number verified successfully", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "VERIFY_PHONE",
+		Entity:    "USER",
+		EntityID:  userID,
+		Details:   "User verified phone number",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// RequestPINReset handles sending a PIN reset code
+// @Summary Request PIN reset
+// @Description Send a PIN reset code to the user's verified phone number
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /auth/pin/reset [post]
+func (h *MessagingHandler) RequestPINReset(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.service.RequestPINReset(userID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "PIN reset code sent", nil)
+}
+
+// ConfirmPINReset handles setting a new PIN with a reset code
+// @Summary Confirm PIN reset
+// @Description Set a new transaction PIN using the code sent to the verified phone number
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ConfirmPinResetRequest true "Reset code and new PIN"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /auth/pin/reset/confirm [post]
+func (h *MessagingHandler) ConfirmPINReset(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req ConfirmPinResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.service.ConfirmPINReset(userID, &req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "PIN reset successfully", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "RESET_PIN",
+		Entity:    "USER",
+		EntityID:  userID,
+		Details:   "User reset transaction PIN via phone verification",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetUsage handles retrieving today's messaging spend
+// @Summary Get messaging usage
+// @Description Get today's SMS/WhatsApp volume and cost against the configured caps (Admin only)
+// @Tags Admin - Monitoring
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=UsageResponse}
+// @Router /admin/messaging/usage [get]
+func (h *MessagingHandler) GetUsage(c *gin.Context) {
+	usage, err := h.service.GetUsage()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve messaging usage", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Messaging usage retrieved", usage)
+}
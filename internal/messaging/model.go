@@ -0,0 +1,87 @@
+package messaging
+
+import (
+	"time"
+)
+
+type UserPhone struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"uniqueIndex;not null"`
+	PhoneNumber string     `json:"phone_number" gorm:"size:255;not null;serializer:pii"`
+	Channel     string     `json:"channel" gorm:"type:enum('sms','whatsapp');default:'sms'"`
+	VerifiedAt  *time.Time `json:"verified_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (UserPhone) TableName() string {
+	return "user_phones"
+}
+
+// IsVerified reports whether the phone number has completed OTP verification
+func (p *UserPhone) IsVerified() bool {
+	return p.VerifiedAt != nil
+}
+
+type OTPCode struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	Purpose    string     `json:"purpose" gorm:"size:50;not null"` // e.g., "phone_verification", "pin_reset"
+	CodeHash   string     `json:"-" gorm:"not null"`
+	Attempts   int        `json:"attempts" gorm:"default:0;not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	ConsumedAt *time.Time `json:"consumed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (OTPCode) TableName() string {
+	return "otp_codes"
+}
+
+type MessageLog struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"index"`
+	PhoneNumber string    `json:"phone_number" gorm:"size:255;serializer:pii"`
+	Channel     string    `json:"channel" gorm:"size:20"`
+	Purpose     string    `json:"purpose" gorm:"size:50"`
+	Status      string    `json:"status" gorm:"type:enum('sent','failed','blocked');not null"`
+	Cost        int       `json:"cost" gorm:"default:0;not null"`
+	ProviderRef string    `json:"provider_ref" gorm:"size:100"`
+	Error       string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+func (MessageLog) TableName() string {
+	return "message_logs"
+}
+
+type RegisterPhoneRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	Channel     string `json:"channel" binding:"omitempty,oneof=sms whatsapp"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type ConfirmPinResetRequest struct {
+	Code   string `json:"code" binding:"required,len=6,numeric"`
+	NewPin string `json:"new_pin" binding:"required,len=6,numeric"`
+}
+
+type UsageResponse struct {
+	Date            string `json:"date"`
+	MessagesSent    int64  `json:"messages_sent"`
+	MessagesBlocked int64  `json:"messages_blocked"`
+	TotalCost       int64  `json:"total_cost"`
+	DailyBudget     int    `json:"daily_budget"`
+	UserDailyLimit  int    `json:"user_daily_limit"`
+}
+
+// Config holds the cost-capping rules applied to outgoing messages
+type Config struct {
+	CostPerMessage            int
+	DailyBudget               int
+	UserDailyLimit            int
+	LargeTransactionThreshold int
+}
@@ -0,0 +1,130 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	"wallet-point/internal/resilience"
+)
+
+// Provider delivers a text message to a phone number over a single channel
+type Provider interface {
+	Send(to, message string) (providerRef string, err error)
+}
+
+// LogProvider writes messages to the server log instead of sending them (development)
+type LogProvider struct {
+	Channel string
+}
+
+func (p *LogProvider) Send(to, message string) (string, error) {
+	slog.Info("message (log provider)", "channel", p.Channel, "to", to, "message", message)
+	return fmt.Sprintf("log-%d", time.Now().UnixNano()), nil
+}
+
+// HTTPProvider posts messages to a generic SMS/WhatsApp gateway JSON API. Calls go
+// through policy: network errors, timeouts, 5xx and 429 replies are retried.
+type HTTPProvider struct {
+	Channel string
+	URL     string
+	APIKey  string
+	Sender  string
+	client  *http.Client
+	policy  resilience.Policy
+}
+
+func NewHTTPProvider(channel, url, apiKey, sender string, policy resilience.Policy) *HTTPProvider {
+	if policy.Retryable == nil {
+		policy.Retryable = retryableGatewayError
+	}
+	return &HTTPProvider{
+		Channel: channel,
+		URL:     url,
+		APIKey:  apiKey,
+		Sender:  sender,
+		client:  &http.Client{},
+		policy:  policy,
+	}
+}
+
+// gatewayStatusError is a non-2xx reply of the gateway
+type gatewayStatusError struct {
+	status int
+}
+
+func (e *gatewayStatusError) Error() string {
+	return fmt.Sprintf("gateway returned status %d", e.status)
+}
+
+// retryableGatewayError retries everything except a 4xx other than 429, which
+// means the request itself was rejected
+func retryableGatewayError(err error) bool {
+	statusErr, ok := err.(*gatewayStatusError)
+	if !ok {
+		return true
+	}
+	return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+}
+
+func (p *HTTPProvider) Send(to, message string) (string, error) {
+	var ref string
+	err := p.policy.Do(context.Background(), func(ctx context.Context) error {
+		var err error
+		ref, err = p.send(ctx, to, message)
+		return err
+	})
+	return ref, err
+}
+
+func (p *HTTPProvider) send(ctx context.Context, to, message string) (string, error) {
+	payload, err := json.Marshal(map[string]string{
+		"channel": p.Channel,
+		"from":    p.Sender,
+		"to":      to,
+		"message": message,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", &gatewayStatusError{status: resp.StatusCode}
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return result.ID, nil
+}
+
+// NewProviders builds one provider per channel based on the configured provider type.
+// The channels share one gateway, so they share policy and its circuit breaker.
+func NewProviders(providerType, url, apiKey, sender string, policy resilience.Policy) map[string]Provider {
+	providers := make(map[string]Provider)
+	for _, channel := range []string{"sms", "whatsapp"} {
+		if providerType == "http" && url != "" {
+			providers[channel] = NewHTTPProvider(channel, url, apiKey, sender, policy)
+		} else {
+			providers[channel] = &LogProvider{Channel: channel}
+		}
+	}
+	return providers
+}
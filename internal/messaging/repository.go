@@ -0,0 +1,117 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+type MessagingRepository struct {
+	db *gorm.DB
+}
+
+func NewMessagingRepository(db *gorm.DB) *MessagingRepository {
+	return &MessagingRepository{db: db}
+}
+
+// FindPhoneByUserID finds the registered phone number of a user
+func (r *MessagingRepository) FindPhoneByUserID(userID uint) (*UserPhone, error) {
+	var phone UserPhone
+	err := r.db.Where("user_id = ?", userID).First(&phone).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("phone number not registered")
+		}
+		return nil, err
+	}
+	return &phone, nil
+}
+
+// SavePhone creates or replaces the phone number of a user (resets verification)
+func (r *MessagingRepository) SavePhone(phone *UserPhone) error {
+	var existing UserPhone
+	err := r.db.Where("user_id = ?", phone.UserID).First(&existing).Error
+	if err == nil {
+		phone.ID = existing.ID
+		return r.db.Model(&existing).Updates(map[string]interface{}{
+			"phone_number": utils.PII(phone.PhoneNumber),
+			"channel":      phone.Channel,
+			"verified_at":  nil,
+		}).Error
+	}
+	return r.db.Create(phone).Error
+}
+
+// FindWalletOwner returns the user a wallet belongs to
+func (r *MessagingRepository) FindWalletOwner(ctx context.Context, walletID uint) (uint, error) {
+	var userID uint
+	err := r.db.WithContext(ctx).Table("wallets").Select("user_id").Where("id = ?", walletID).Take(&userID).Error
+	return userID, err
+}
+
+func (r *MessagingRepository) MarkPhoneVerified(userID uint, verifiedAt time.Time) error {
+	return r.db.Model(&UserPhone{}).Where("user_id = ?", userID).Update("verified_at", verifiedAt).Error
+}
+
+// CreateOTP invalidates earlier codes for the same purpose and stores a new one
+func (r *MessagingRepository) CreateOTP(otp *OTPCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&OTPCode{}).
+			Where("user_id = ? AND purpose = ? AND consumed_at IS NULL", otp.UserID, otp.Purpose).
+			Update("consumed_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(otp).Error
+	})
+}
+
+// FindActiveOTP finds the latest unconsumed code for a user and purpose
+func (r *MessagingRepository) FindActiveOTP(userID uint, purpose string) (*OTPCode, error) {
+	var otp OTPCode
+	err := r.db.Where("user_id = ? AND purpose = ? AND consumed_at IS NULL", userID, purpose).
+		Order("created_at DESC").
+		First(&otp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no active verification code")
+		}
+		return nil, err
+	}
+	return &otp, nil
+}
+
+func (r *MessagingRepository) IncrementOTPAttempts(otpID uint) error {
+	return r.db.Model(&OTPCode{}).Where("id = ?", otpID).Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+func (r *MessagingRepository) ConsumeOTP(otpID uint) error {
+	return r.db.Model(&OTPCode{}).Where("id = ?", otpID).Update("consumed_at", time.Now()).Error
+}
+
+func (r *MessagingRepository) CreateLog(entry *MessageLog) error {
+	return r.db.Create(entry).Error
+}
+
+// SumCostSince returns the total cost of messages sent since the given time
+func (r *MessagingRepository) SumCostSince(since time.Time) (int64, error) {
+	var total int64
+	err := r.db.Model(&MessageLog{}).
+		Where("created_at >= ? AND status = ?", since, "sent").
+		Select("COALESCE(SUM(cost), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// CountSince counts messages for a user (or all users when userID is 0) with a given status
+func (r *MessagingRepository) CountSince(userID uint, status string, since time.Time) (int64, error) {
+	var count int64
+	query := r.db.Model(&MessageLog{}).Where("created_at >= ? AND status = ?", since, status)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,259 @@
+package messaging
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+	"wallet-point/internal/auth"
+	"wallet-point/utils"
+)
+
+const (
+	PurposePhoneVerification = "phone_verification"
+	PurposePinReset          = "pin_reset"
+	PurposeLargeTransaction  = "large_transaction"
+
+	otpTTL         = 5 * time.Minute
+	otpMaxAttempts = 5
+)
+
+type MessagingService struct {
+	repo        *MessagingRepository
+	providers   map[string]Provider
+	authService *auth.AuthService
+	config      Config
+}
+
+func NewMessagingService(repo *MessagingRepository, providers map[string]Provider, authService *auth.AuthService, config Config) *MessagingService {
+	return &MessagingService{
+		repo:        repo,
+		providers:   providers,
+		authService: authService,
+		config:      config,
+	}
+}
+
+// GetPhone returns the registered phone number of a user
+func (s *MessagingService) GetPhone(userID uint) (*UserPhone, error) {
+	return s.repo.FindPhoneByUserID(userID)
+}
+
+// RegisterPhone stores a new phone number and sends a verification code to it
+func (s *MessagingService) RegisterPhone(userID uint, req *RegisterPhoneRequest) (*UserPhone, error) {
+	channel := req.Channel
+	if channel == "" {
+		channel = "sms"
+	}
+
+	phone := &UserPhone{
+		UserID:      userID,
+		PhoneNumber: req.PhoneNumber,
+		Channel:     channel,
+	}
+	if err := s.repo.SavePhone(phone); err != nil {
+		return nil, errors.New("failed to save phone number")
+	}
+
+	code, err := s.issueOTP(userID, PurposePhoneVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Kode verifikasi Wallet Point Anda: %s. Berlaku %d menit.", code, int(otpTTL.Minutes()))
+	if err := s.deliver(phone, PurposePhoneVerification, message); err != nil {
+		return nil, err
+	}
+
+	return phone, nil
+}
+
+// VerifyPhone confirms ownership of the registered phone number
+func (s *MessagingService) VerifyPhone(userID uint, code string) error {
+	if _, err := s.repo.FindPhoneByUserID(userID); err != nil {
+		return err
+	}
+	if err := s.checkOTP(userID, PurposePhoneVerification, code); err != nil {
+		return err
+	}
+	return s.repo.MarkPhoneVerified(userID, time.Now())
+}
+
+// RequestPINReset sends a PIN reset code to the user's verified phone number
+func (s *MessagingService) RequestPINReset(userID uint) error {
+	code, err := s.issueOTP(userID, PurposePinReset)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf("Kode reset PIN Wallet Point: %s. Jangan berikan kode ini kepada siapa pun.", code)
+	return s.SendCritical(userID, PurposePinReset, message)
+}
+
+// ConfirmPINReset validates the reset code and sets the new transaction PIN
+func (s *MessagingService) ConfirmPINReset(userID uint, req *ConfirmPinResetRequest) error {
+	if err := s.checkOTP(userID, PurposePinReset, req.Code); err != nil {
+		return err
+	}
+	return s.authService.ResetPIN(userID, req.NewPin)
+}
+
+// NotifyLargeTransaction tells the user of a transaction that reached the
+// configured threshold, so they can reset their PIN if it was not them
+func (s *MessagingService) NotifyLargeTransaction(userID uint, amount int, description string) {
+	if s.config.LargeTransactionThreshold <= 0 || amount < s.config.LargeTransactionThreshold {
+		return
+	}
+	message := fmt.Sprintf("Wallet Point: transaksi %d poin berhasil (%s). Jika ini bukan Anda, segera reset PIN.", amount, description)
+	if err := s.SendCritical(userID, PurposeLargeTransaction, message); err != nil {
+		slog.Error("messaging: large transaction notice not sent", "user_id", userID, "error", err)
+	}
+}
+
+// SendCritical sends a message to the user's verified phone, subject to cost caps
+func (s *MessagingService) SendCritical(userID uint, purpose, message string) error {
+	phone, err := s.repo.FindPhoneByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if !phone.IsVerified() {
+		return errors.New("phone number has not been verified")
+	}
+	return s.deliver(phone, purpose, message)
+}
+
+// GetUsage summarizes today's message volume and spend against the budget
+func (s *MessagingService) GetUsage() (*UsageResponse, error) {
+	since := startOfDay()
+
+	sent, err := s.repo.CountSince(0, "sent", since)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.repo.CountSince(0, "blocked", since)
+	if err != nil {
+		return nil, err
+	}
+	cost, err := s.repo.SumCostSince(since)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UsageResponse{
+		Date:            since.Format("2006-01-02"),
+		MessagesSent:    sent,
+		MessagesBlocked: blocked,
+		TotalCost:       cost,
+		DailyBudget:     s.config.DailyBudget,
+		UserDailyLimit:  s.config.UserDailyLimit,
+	}, nil
+}
+
+// deliver applies the cost caps, sends through the channel provider and records the attempt
+func (s *MessagingService) deliver(phone *UserPhone, purpose, message string) error {
+	entry := &MessageLog{
+		UserID:      phone.UserID,
+		PhoneNumber: phone.PhoneNumber,
+		Channel:     phone.Channel,
+		Purpose:     purpose,
+	}
+
+	if err := s.checkCaps(phone.UserID); err != nil {
+		entry.Status = "blocked"
+		entry.Error = err.Error()
+		s.repo.CreateLog(entry)
+		return err
+	}
+
+	provider, ok := s.providers[phone.Channel]
+	if !ok {
+		return fmt.Errorf("no provider configured for channel %s", phone.Channel)
+	}
+
+	ref, err := provider.Send(phone.PhoneNumber, message)
+	if err != nil {
+		entry.Status = "failed"
+		entry.Error = err.Error()
+		s.repo.CreateLog(entry)
+		return errors.New("failed to send message")
+	}
+
+	entry.Status = "sent"
+	entry.Cost = s.config.CostPerMessage
+	entry.ProviderRef = ref
+	return s.repo.CreateLog(entry)
+}
+
+func (s *MessagingService) checkCaps(userID uint) error {
+	since := startOfDay()
+
+	if s.config.UserDailyLimit > 0 {
+		count, err := s.repo.CountSince(userID, "sent", since)
+		if err != nil {
+			return err
+		}
+		if count >= int64(s.config.UserDailyLimit) {
+			return errors.New("daily message limit reached for this user")
+		}
+	}
+
+	if s.config.DailyBudget > 0 {
+		spent, err := s.repo.SumCostSince(since)
+		if err != nil {
+			return err
+		}
+		if spent+int64(s.config.CostPerMessage) > int64(s.config.DailyBudget) {
+			return errors.New("daily messaging budget exhausted")
+		}
+	}
+
+	return nil
+}
+
+func (s *MessagingService) issueOTP(userID uint, purpose string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	hash, err := utils.HashPassword(code)
+	if err != nil {
+		return "", errors.New("failed to secure verification code")
+	}
+
+	otp := &OTPCode{
+		UserID:    userID,
+		Purpose:   purpose,
+		CodeHash:  hash,
+		ExpiresAt: time.Now().Add(otpTTL),
+	}
+	if err := s.repo.CreateOTP(otp); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+func (s *MessagingService) checkOTP(userID uint, purpose, code string) error {
+	otp, err := s.repo.FindActiveOTP(userID, purpose)
+	if err != nil {
+		return err
+	}
+	if time.Now().After(otp.ExpiresAt) {
+		return errors.New("verification code has expired")
+	}
+	if otp.Attempts >= otpMaxAttempts {
+		return errors.New("too many invalid attempts, request a new code")
+	}
+	if err := utils.VerifyPassword(otp.CodeHash, code); err != nil {
+		s.repo.IncrementOTPAttempts(otp.ID)
+		return errors.New("invalid verification code")
+	}
+	return s.repo.ConsumeOTP(otp.ID)
+}
+
+func startOfDay() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// GormPlugin times every statement GORM runs and exports the connection pool stats
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	if sqlDB, err := db.DB(); err == nil {
+		RegisterDBPool("wallet_point", sqlDB)
+	}
+
+	cb := db.Callback()
+	registrations := []error{
+		cb.Create().Before("gorm:create").Register("metrics:before_create", before),
+		cb.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", before),
+		cb.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", before),
+		cb.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", before),
+		cb.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+	}
+	return errors.Join(registrations...)
+}
+
+// RegisterDBPool exports a connection pool's stats under db_name=name: the go_sql_*
+// series (open, in use, idle, waits and wait time) and walletpoint_db_pool_utilization_ratio,
+// the share of MaxOpenConns in use - alert on it staying near 1 together with a rising
+// go_sql_wait_count_total, which means requests are queueing for a connection
+func RegisterDBPool(name string, sqlDB *sql.DB) {
+	Register(collectors.NewDBStatsCollector(sqlDB, name))
+	Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   "walletpoint",
+		Name:        "db_pool_utilization_ratio",
+		Help:        "Connections in use divided by the pool's maximum open connections.",
+		ConstLabels: prometheus.Labels{"db_name": name},
+	}, func() float64 {
+		stats := sqlDB.Stats()
+		if stats.MaxOpenConnections <= 0 {
+			return 0
+		}
+		return float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	}))
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		DBQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			DBQueryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
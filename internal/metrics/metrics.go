@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"wallet-point/utils"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "walletpoint",
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "walletpoint",
+		Name:      "db_query_duration_seconds",
+		Help:      "Database statement latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "db_query_errors_total",
+		Help:      "Failed database statements by operation and table (record-not-found excluded).",
+	}, []string{"operation", "table"})
+
+	WalletOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "wallet_operations_total",
+		Help:      "Point-moving operations by operation and result (success or lower-cased error code).",
+	}, []string{"operation", "result"})
+
+	StockConflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "stock_conflicts_total",
+		Help:      "Purchases rejected because the product did not have enough stock, by flow.",
+	}, []string{"flow"})
+
+	// Fed by the analytics subscriber of the domain events, so they count
+	// committed changes only
+	OrdersPlaced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "orders_placed_total",
+		Help:      "Marketplace orders placed, by source (purchase or checkout).",
+	}, []string{"source"})
+
+	OrderPoints = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "order_points_total",
+		Help:      "Points spent on marketplace orders, by source.",
+	}, []string{"source"})
+
+	PointsMoved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "points_moved_total",
+		Help:      "Points credited to or debited from wallets, by direction and transaction type.",
+	}, []string{"direction", "type"})
+
+	StockLowAlerts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "stock_low_alerts_total",
+		Help:      "Low-stock alerts fired.",
+	})
+
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache name and result (hit, miss or error).",
+	}, []string{"cache", "result"})
+
+	ExternalCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "walletpoint",
+		Name:      "external_calls_total",
+		Help:      "Attempts to call an external service by client and result (success, error, timeout or rejected by an open circuit).",
+	}, []string{"client", "result"})
+
+	CircuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "walletpoint",
+		Name:      "circuit_state",
+		Help:      "Circuit breaker state by client: 0 closed, 1 half-open, 2 open.",
+	}, []string{"client"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		DBQueryDuration,
+		DBQueryErrors,
+		WalletOperations,
+		StockConflicts,
+		OrdersPlaced,
+		OrderPoints,
+		PointsMoved,
+		StockLowAlerts,
+		CacheRequests,
+		ExternalCalls,
+		CircuitState,
+	)
+}
+
+// Register adds an extra collector (e.g. the DB pool stats) to the /metrics registry
+func Register(collector prometheus.Collector) {
+	registry.MustRegister(collector)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveWalletOperation counts a point-moving operation once it has finished
+func ObserveWalletOperation(operation string, err error) {
+	WalletOperations.WithLabelValues(operation, resultLabel(err)).Inc()
+}
+
+// resultLabel keeps the label set small: typed errors by code, everything else as "error"
+func resultLabel(err error) string {
+	if err == nil {
+		return "success"
+	}
+	if appErr, ok := utils.AsAppError(err); ok {
+		return strings.ToLower(appErr.Code)
+	}
+	return "error"
+}
@@ -0,0 +1,14 @@
+package mission
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrMissionNotFound       = utils.NewAppError("MISSION_NOT_FOUND", http.StatusNotFound, "mission not found")
+	ErrMissionDeadlinePassed = utils.NewAppError("MISSION_DEADLINE_PASSED", http.StatusBadRequest, "mission deadline has passed")
+	ErrAlreadySubmitted      = utils.NewAppError("MISSION_ALREADY_SUBMITTED", http.StatusConflict, "you have already submitted this mission")
+	ErrSubmissionNotFound    = utils.NewAppError("SUBMISSION_NOT_FOUND", http.StatusNotFound, "submission not found")
+	ErrSubmissionReviewed    = utils.NewAppError("SUBMISSION_ALREADY_REVIEWED", http.StatusConflict, "submission has already been reviewed")
+)
@@ -1,13 +1,10 @@
 package mission
 
 import (
-	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"time"
 	"wallet-point/internal/audit"
+	"wallet-point/internal/storage"
 	"wallet-point/utils"
 
 	"github.com/gin-gonic/gin"
@@ -15,11 +12,12 @@ import (
 
 type MissionHandler struct {
 	service      *MissionService
-	auditService *audit.AuditService
+	files        storage.Storage
+	auditService audit.Logger
 }
 
-func NewMissionHandler(service *MissionService, auditService *audit.AuditService) *MissionHandler {
-	return &MissionHandler{service: service, auditService: auditService}
+func NewMissionHandler(service *MissionService, files storage.Storage, auditService audit.Logger) *MissionHandler {
+	return &MissionHandler{service: service, files: files, auditService: auditService}
 }
 
 // ========================================
@@ -35,18 +33,24 @@ func NewMissionHandler(service *MissionService, auditService *audit.AuditService
 // @Param type query string false "Filter by type"
 // @Param status query string false "Filter by status"
 // @Param created_by query int false "Filter by creator"
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(20)
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
 // @Success 200 {object} utils.Response{data=MissionListResponse}
 // @Router /missions [get]
 func (h *MissionHandler) GetAllMissions(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "mission_status")
+	if !ok {
+		return
+	}
 	createdBy, _ := strconv.ParseUint(c.Query("created_by"), 10, 32)
 
 	params := MissionListParams{
 		Type:      c.Query("type"),
-		Status:    c.Query("status"),
+		Status:    status,
 		CreatedBy: uint(createdBy),
 		Page:      page,
 		Limit:     limit,
@@ -64,7 +68,7 @@ func (h *MissionHandler) GetAllMissions(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Missions retrieved successfully", response)
+	utils.ListResponse(c, "Missions retrieved successfully", "missions", response.Missions, response.Pagination, nil)
 }
 
 // GetMissionByID handles getting mission by ID
@@ -85,7 +89,7 @@ func (h *MissionHandler) GetMissionByID(c *gin.Context) {
 
 	mission, err := h.service.GetMissionByID(uint(missionID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusNotFound, err)
 		return
 	}
 
@@ -107,13 +111,13 @@ func (h *MissionHandler) CreateMission(c *gin.Context) {
 
 	var req CreateMissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	mission, err := h.service.CreateMission(&req, dosenID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -151,17 +155,13 @@ func (h *MissionHandler) UpdateMission(c *gin.Context) {
 
 	var req UpdateMissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	mission, err := h.service.UpdateMission(uint(missionID), &req)
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "mission not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -196,11 +196,7 @@ func (h *MissionHandler) DeleteMission(c *gin.Context) {
 	}
 
 	if err := h.service.DeleteMission(uint(missionID)); err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "mission not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -244,7 +240,7 @@ func (h *MissionHandler) SubmitMission(c *gin.Context) {
 			// Process JSON request
 			submission, err := h.service.SubmitMission(&req, studentID)
 			if err != nil {
-				utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+				utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 				return
 			}
 			utils.SuccessResponse(c, http.StatusCreated, "Mission submitted successfully", submission)
@@ -263,38 +259,14 @@ func (h *MissionHandler) SubmitMission(c *gin.Context) {
 	content := c.PostForm("submission_content")
 	fileURL := c.PostForm("file_url") // Optional link fallback
 
-	// Handle File Upload
-	file, header, err := c.Request.FormFile("file")
-	if err == nil {
-		defer file.Close()
-
-		// Create upload dir if not exists
-		uploadDir := "./public/uploads/submissions"
-		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create upload directory", err.Error())
-			return
-		}
-
-		// Generate filename: UNIX_USERID_FILENAME
-		filename := fmt.Sprintf("%d_%d_%s", time.Now().Unix(), studentID, filepath.Base(header.Filename))
-		filepath := filepath.Join(uploadDir, filename)
-
-		// Save file
-		out, err := os.Create(filepath)
+	// Handle File Upload (the receipt or proof of the work)
+	if file, err := c.FormFile("file"); err == nil {
+		// Set public URL (Store relative path)
+		fileURL, err = storage.SaveUpload(c.Request.Context(), h.files, storage.PrefixSubmissions, file)
 		if err != nil {
 			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save file", err.Error())
 			return
 		}
-		defer out.Close()
-
-		// buffer copy
-		if _, err := out.ReadFrom(file); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to write file", err.Error())
-			return
-		}
-
-		// Set public URL (Store relative path)
-		fileURL = fmt.Sprintf("/uploads/submissions/%s", filename)
 	}
 
 	req := SubmitMissionRequest{
@@ -305,7 +277,7 @@ func (h *MissionHandler) SubmitMission(c *gin.Context) {
 
 	submission, err := h.service.SubmitMission(&req, studentID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -332,13 +304,19 @@ func (h *MissionHandler) SubmitMission(c *gin.Context) {
 // @Param mission_id query int false "Filter by mission"
 // @Param student_id query int false "Filter by student"
 // @Param status query string false "Filter by status"
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(20)
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
 // @Success 200 {object} utils.Response{data=SubmissionListResponse}
 // @Router /missions/submissions [get]
 func (h *MissionHandler) GetAllSubmissions(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "submission_status")
+	if !ok {
+		return
+	}
 	missionID, _ := strconv.ParseUint(c.Query("mission_id"), 10, 32)
 	studentID, _ := strconv.ParseUint(c.Query("student_id"), 10, 32)
 	creatorID, _ := strconv.ParseUint(c.Query("creator_id"), 10, 32)
@@ -348,7 +326,7 @@ func (h *MissionHandler) GetAllSubmissions(c *gin.Context) {
 		MissionID: uint(missionID),
 		StudentID: uint(studentID),
 		CreatorID: uint(creatorID),
-		Status:    c.Query("status"),
+		Status:    status,
 		Page:      page,
 		Limit:     limit,
 	}
@@ -365,7 +343,7 @@ func (h *MissionHandler) GetAllSubmissions(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Submissions retrieved successfully", response)
+	utils.ListResponse(c, "Submissions retrieved successfully", "submissions", response.Submissions, response.Pagination, nil)
 }
 
 // ReviewSubmission handles reviewing student submission
@@ -389,12 +367,12 @@ func (h *MissionHandler) ReviewSubmission(c *gin.Context) {
 
 	var req ReviewSubmissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	if err := h.service.ReviewSubmission(uint(submissionID), &req, dosenID); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
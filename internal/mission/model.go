@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"time"
+	"wallet-point/utils"
 )
 
 type JSONOptions []string
@@ -73,7 +74,7 @@ type CreateMissionRequest struct {
 	Title       string            `json:"title" binding:"required"`
 	Description string            `json:"description"`
 	Type        string            `json:"type" binding:"required,oneof=quiz task assignment"`
-	Points      int               `json:"points" binding:"required,gt=0"`
+	Points      int               `json:"points" binding:"required,points"`
 	Deadline    *time.Time        `json:"deadline"`
 	Questions   []QuestionRequest `json:"questions"`
 }
@@ -87,9 +88,9 @@ type QuestionRequest struct {
 type UpdateMissionRequest struct {
 	Title       string            `json:"title,omitempty"`
 	Description string            `json:"description,omitempty"`
-	Points      int               `json:"points,omitempty" binding:"omitempty,gt=0"`
+	Points      int               `json:"points,omitempty" binding:"omitempty,points"`
 	Deadline    *time.Time        `json:"deadline,omitempty"`
-	Status      string            `json:"status,omitempty" binding:"omitempty,oneof=active inactive expired"`
+	Status      string            `json:"status,omitempty" binding:"omitempty,mission_status" enums:"active,inactive,expired"`
 	Questions   []QuestionRequest `json:"questions,omitempty"`
 }
 
@@ -106,7 +107,7 @@ type AnswerSubmission struct {
 }
 
 type ReviewSubmissionRequest struct {
-	Status     string `json:"status" binding:"required,oneof=approved rejected"`
+	Status     string `json:"status" binding:"required,review_status" enums:"approved,rejected"`
 	Score      int    `json:"score" binding:"gte=0"`
 	ReviewNote string `json:"review_note"`
 }
@@ -134,11 +135,8 @@ type MissionListParams struct {
 }
 
 type MissionListResponse struct {
-	Missions   []MissionWithCreator `json:"missions"`
-	Total      int64                `json:"total"`
-	Page       int                  `json:"page"`
-	Limit      int                  `json:"limit"`
-	TotalPages int                  `json:"total_pages"`
+	Missions []MissionWithCreator `json:"missions"`
+	utils.Pagination
 }
 
 type SubmissionListParams struct {
@@ -152,10 +150,7 @@ type SubmissionListParams struct {
 
 type SubmissionListResponse struct {
 	Submissions []SubmissionWithDetails `json:"submissions"`
-	Total       int64                   `json:"total"`
-	Page        int                     `json:"page"`
-	Limit       int                     `json:"limit"`
-	TotalPages  int                     `json:"total_pages"`
+	utils.Pagination
 }
 
 type DosenStatsResponse struct {
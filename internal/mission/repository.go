@@ -24,7 +24,7 @@ func (r *MissionRepository) FindByID(id uint) (*Mission, error) {
 	err := r.db.Preload("Questions").First(&mission, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("mission not found")
+			return nil, ErrMissionNotFound
 		}
 		return nil, err
 	}
@@ -80,7 +80,7 @@ func (r *MissionRepository) FindSubmissionByID(id uint) (*MissionSubmission, err
 	err := r.db.First(&submission, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("submission not found")
+			return nil, ErrSubmissionNotFound
 		}
 		return nil, err
 	}
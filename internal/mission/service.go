@@ -2,9 +2,8 @@ package mission
 
 import (
 	"encoding/json"
-	"errors"
-	"math"
 	"wallet-point/internal/wallet"
+	"wallet-point/utils"
 
 	"gorm.io/gorm"
 )
@@ -69,14 +68,9 @@ func (s *MissionService) GetAllMissions(params MissionListParams) (*MissionListR
 		return nil, err
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
-
 	return &MissionListResponse{
 		Missions:   missions,
-		Total:      total,
-		Page:       params.Page,
-		Limit:      params.Limit,
-		TotalPages: totalPages,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
 	}, nil
 }
 
@@ -159,7 +153,7 @@ func (s *MissionService) SubmitMission(req *SubmitMissionRequest, studentID uint
 
 	// Check deadline
 	if mission.Deadline != nil && mission.Deadline.Before(s.db.NowFunc()) {
-		return nil, errors.New("mission deadline has passed")
+		return nil, ErrMissionDeadlinePassed
 	}
 
 	// Check duplicate submission
@@ -168,7 +162,7 @@ func (s *MissionService) SubmitMission(req *SubmitMissionRequest, studentID uint
 		return nil, err
 	}
 	if exists {
-		return nil, errors.New("you have already submitted this mission")
+		return nil, ErrAlreadySubmitted
 	}
 
 	submission := &MissionSubmission{
@@ -198,7 +192,7 @@ func (s *MissionService) ReviewSubmission(submissionID uint, req *ReviewSubmissi
 	}
 
 	if submission.Status != "pending" {
-		return errors.New("submission has already been reviewed")
+		return ErrSubmissionReviewed
 	}
 
 	// Start a transaction for the review and potential wallet reward
@@ -247,14 +241,9 @@ func (s *MissionService) GetAllSubmissions(params SubmissionListParams) (*Submis
 		return nil, err
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
-
 	return &SubmissionListResponse{
 		Submissions: submissions,
-		Total:       total,
-		Page:        params.Page,
-		Limit:       params.Limit,
-		TotalPages:  totalPages,
+		Pagination:  utils.NewPagination(params.Page, params.Limit, total, ""),
 	}, nil
 }
 
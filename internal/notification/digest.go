@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// digestMaxItems keeps the digest email short; the app lists the rest
+const digestMaxItems = 20
+
+// SendUnreadDigests emails every active user a summary of their notifications
+// created after since that are still unread
+func (s *NotificationService) SendUnreadDigests(ctx context.Context, since time.Time) (string, error) {
+	notifications, err := s.repo.FindUnreadSince(ctx, since)
+	if err != nil {
+		return "", err
+	}
+
+	byUser := make(map[uint][]Notification)
+	var userIDs []uint
+	for _, n := range notifications {
+		if _, ok := byUser[n.UserID]; !ok {
+			userIDs = append(userIDs, n.UserID)
+		}
+		byUser[n.UserID] = append(byUser[n.UserID], n)
+	}
+	if len(userIDs) == 0 {
+		return "no unread notifications", nil
+	}
+
+	recipients, err := s.repo.FindRecipients(userIDs)
+	if err != nil {
+		return "", err
+	}
+
+	sent := 0
+	for _, recipient := range recipients {
+		items := byUser[recipient.ID]
+		if len(items) > digestMaxItems {
+			items = items[:digestMaxItems]
+		}
+
+		subject, body, err := RenderEmail("notification_digest", map[string]interface{}{
+			"RecipientName": recipient.FullName,
+			"Notifications": items,
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := s.Enqueue(recipient.ID, ChannelEmail, recipient.Email, subject, body); err != nil {
+			slog.ErrorContext(ctx, "notification: queue digest failed", "user_id", recipient.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	return fmt.Sprintf("queued %d digests", sent), nil
+}
@@ -0,0 +1,180 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+	"wallet-point/internal/resilience"
+)
+
+// Sender delivers a message over one channel (email, ...)
+type Sender interface {
+	Send(to, subject, body string) error
+}
+
+// PermanentError marks a delivery failure that retrying will not fix (e.g. unknown mailbox)
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so the dispatcher dead-letters the message instead of retrying
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err was marked as a permanent delivery failure
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// LogEmailSender writes emails to the server log instead of sending them (development)
+type LogEmailSender struct{}
+
+func (s *LogEmailSender) Send(to, subject, body string) error {
+	slog.Info("email (log sender)", "to", to, "subject", subject)
+	return nil
+}
+
+// SMTPEmailSender sends email through an SMTP relay. Timeout bounds the whole
+// conversation with the relay, from dialing to QUIT.
+type SMTPEmailSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+func (s *SMTPEmailSender) Send(to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") {
+		return Permanent(errors.New("invalid recipient address"))
+	}
+
+	msg := strings.Join([]string{
+		"From: " + s.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	err := s.sendMail(auth, to, []byte(msg))
+
+	// 5xx replies (unknown mailbox, rejected sender, ...) will not succeed on retry
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
+
+// sendMail is smtp.SendMail with a deadline on the connection, so a relay that
+// accepts the connection and then stalls cannot hold the dispatcher
+func (s *SMTPEmailSender) sendMail(auth smtp.Auth, to string, msg []byte) error {
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(s.Host, s.Port))
+	if err != nil {
+		return err
+	}
+	if s.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.Timeout))
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// NewEmailSender returns an SMTP sender when a host is configured, otherwise a log sender
+func NewEmailSender(host, port, username, password, from string, timeout time.Duration) Sender {
+	if host == "" {
+		return &LogEmailSender{}
+	}
+	return &SMTPEmailSender{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+		Timeout:  timeout,
+	}
+}
+
+// policySender sends through a resilience policy
+type policySender struct {
+	sender Sender
+	policy resilience.Policy
+}
+
+// WithPolicy wraps sender in policy. Permanent errors are not retried; while the
+// policy's circuit is open Send returns resilience.ErrOpen without calling sender,
+// and the dispatcher leaves the message queued.
+func WithPolicy(sender Sender, policy resilience.Policy) Sender {
+	if policy.Retryable == nil {
+		policy.Retryable = func(err error) bool { return !IsPermanent(err) }
+	}
+	return &policySender{sender: sender, policy: policy}
+}
+
+func (s *policySender) Send(to, subject, body string) error {
+	return s.policy.Do(context.Background(), func(context.Context) error {
+		return s.sender.Send(to, subject, body)
+	})
+}
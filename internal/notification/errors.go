@@ -0,0 +1,12 @@
+package notification
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrNotificationNotFound = utils.NewAppError("NOTIFICATION_NOT_FOUND", http.StatusNotFound, "notification not found")
+	ErrDeadLetterNotFound   = utils.NewAppError("DEAD_LETTER_NOT_FOUND", http.StatusNotFound, "dead letter not found")
+	ErrDeadLetterRequeued   = utils.NewAppError("DEAD_LETTER_ALREADY_REQUEUED", http.StatusConflict, "dead letter already requeued")
+)
@@ -0,0 +1,127 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"wallet-point/internal/events"
+)
+
+// OnStockLow is the notification subscriber of stock.low: it tells the admins
+// who own the product's categories. A product without an owned category, or
+// whose owners are no longer admins, alerts the admin who created it, and
+// every admin when that one is not an admin either.
+func (s *NotificationService) OnStockLow(ctx context.Context, event *events.DomainEvent) error {
+	var low events.StockLow
+	if err := event.Decode(&low); err != nil {
+		return err
+	}
+	adminIDs, err := s.GetAdminIDs()
+	if err != nil {
+		return err
+	}
+	recipients := stockAlertRecipients(low, adminIDs)
+	if len(recipients) == 0 {
+		slog.WarnContext(ctx, "stock alert: no admin to notify", "product_id", low.ProductID)
+		return nil
+	}
+
+	s.NotifyMany(recipients, NotifyParams{
+		Type:      "low_stock",
+		Title:     fmt.Sprintf("Stok menipis: %s", low.ProductName),
+		Message:   fmt.Sprintf("Stok produk '%s' tersisa %d (batas %d). Segera lakukan restock.", low.ProductName, low.Stock, low.Threshold),
+		Link:      fmt.Sprintf("/admin/products/%d", low.ProductID),
+		SendEmail: true,
+	})
+	return nil
+}
+
+func stockAlertRecipients(low events.StockLow, adminIDs []uint) []uint {
+	admins := make(map[uint]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+	var owners []uint
+	for _, id := range low.CategoryOwnerIDs {
+		if admins[id] {
+			owners = append(owners, id)
+		}
+	}
+	switch {
+	case len(owners) > 0:
+		return owners
+	case admins[low.OwnerID]:
+		return []uint{low.OwnerID}
+	}
+	return adminIDs
+}
+
+// OnBackInStock is the notification subscriber of stock.back_in_stock: it tells
+// the students who have the restocked product in their cart
+func (s *NotificationService) OnBackInStock(ctx context.Context, event *events.DomainEvent) error {
+	var restocked events.BackInStock
+	if err := event.Decode(&restocked); err != nil {
+		return err
+	}
+
+	s.NotifyMany(restocked.UserIDs, NotifyParams{
+		Type:    "back_in_stock",
+		Title:   fmt.Sprintf("Tersedia lagi: %s", restocked.ProductName),
+		Message: fmt.Sprintf("Produk '%s' di keranjang Anda sudah tersedia lagi (stok %d).", restocked.ProductName, restocked.Stock),
+		Link:    fmt.Sprintf("/marketplace/products/%d", restocked.ProductID),
+	})
+	return nil
+}
+
+// OnOrderPlaced is the notification subscriber of order.placed: for a gift it
+// tells the recipient what they received and the buyer that it was delivered
+func (s *NotificationService) OnOrderPlaced(ctx context.Context, event *events.DomainEvent) error {
+	var placed events.OrderPlaced
+	if err := event.Decode(&placed); err != nil {
+		return err
+	}
+	if placed.Gift == nil {
+		return nil
+	}
+
+	products := make([]string, len(placed.Orders))
+	for i, order := range placed.Orders {
+		products[i] = fmt.Sprintf("%dx %s", order.Quantity, order.ProductName)
+	}
+	items := strings.Join(products, ", ")
+
+	sender := placed.Gift.SenderName
+	if sender == "" {
+		sender = "Seorang mahasiswa"
+	}
+	s.NotifyMany([]uint{placed.Gift.RecipientID}, NotifyParams{
+		Type:    "gift_received",
+		Title:   "Anda menerima hadiah",
+		Message: fmt.Sprintf("%s memberi Anda hadiah: %s.", sender, items),
+		Link:    "/marketplace/gifts",
+	})
+	s.NotifyMany([]uint{placed.UserID}, NotifyParams{
+		Type:    "gift_sent",
+		Title:   "Hadiah terkirim",
+		Message: fmt.Sprintf("Hadiah Anda (%s) telah diberikan kepada %s; %d poin dipotong dari dompet Anda.", items, placed.Gift.RecipientName, placed.Total),
+	})
+	return nil
+}
+
+// OnQuestionAnswered is the notification subscriber of product.question_answered:
+// it tells the student who asked
+func (s *NotificationService) OnQuestionAnswered(ctx context.Context, event *events.DomainEvent) error {
+	var answered events.QuestionAnswered
+	if err := event.Decode(&answered); err != nil {
+		return err
+	}
+
+	return s.Notify(NotifyParams{
+		UserID:  answered.AskerID,
+		Type:    "question_answered",
+		Title:   "Pertanyaan Anda dijawab",
+		Message: fmt.Sprintf("Admin telah menjawab pertanyaan Anda tentang produk '%s'.", answered.ProductName),
+		Link:    fmt.Sprintf("/marketplace/products/%d", answered.ProductID),
+	})
+}
@@ -0,0 +1,190 @@
+package notification
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	service      *NotificationService
+	auditService audit.Logger
+}
+
+func NewNotificationHandler(service *NotificationService, auditService audit.Logger) *NotificationHandler {
+	return &NotificationHandler{service: service, auditService: auditService}
+}
+
+// GetAll handles listing the current user's notifications
+// @Summary Get my notifications
+// @Description Get in-app notifications of the authenticated user
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=NotificationListResponse}
+// @Router /notifications [get]
+func (h *NotificationHandler) GetAll(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+
+	params := NotificationListParams{
+		UserID:     c.GetUint("user_id"),
+		UnreadOnly: c.Query("unread") == "true",
+		Page:       page,
+		Limit:      limit,
+	}
+
+	response, err := h.service.GetNotifications(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve notifications", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Notifications retrieved successfully", "notifications", response.Notifications, response.Pagination,
+		gin.H{"unread": response.Unread})
+}
+
+// MarkRead handles marking a notification as read
+// @Summary Mark notification as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /notifications/{id}/read [patch]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid notification ID", nil)
+		return
+	}
+
+	if err := h.service.MarkRead(c.GetUint("user_id"), uint(notificationID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", nil)
+}
+
+// MarkAllRead handles marking all notifications as read
+// @Summary Mark all notifications as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	if err := h.service.MarkAllRead(c.GetUint("user_id")); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update notifications", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "All notifications marked as read", nil)
+}
+
+// GetOutbox handles listing outgoing notification deliveries (Admin)
+// @Summary Get notification outbox
+// @Description Get persisted outgoing notifications with delivery status (Admin only)
+// @Tags Admin - Monitoring
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, sending, sent, dead)
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=OutboxListResponse}
+// @Router /admin/notifications/outbox [get]
+func (h *NotificationHandler) GetOutbox(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "outbox_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetOutbox(OutboxListParams{
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve outbox", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Outbox retrieved successfully", "messages", response.Messages, response.Pagination, nil)
+}
+
+// GetDeadLetters handles listing dead-lettered notifications (Admin)
+// @Summary Get notification dead letters
+// @Description Get notifications that failed permanently or exhausted their retries (Admin only)
+// @Tags Admin - Monitoring
+// @Security BearerAuth
+// @Produce json
+// @Param include_requeued query bool false "Include already requeued entries"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=DeadLetterListResponse}
+// @Router /admin/notifications/dead-letters [get]
+func (h *NotificationHandler) GetDeadLetters(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetDeadLetters(c.Query("include_requeued") == "true", page, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve dead letters", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Dead letters retrieved successfully", "dead_letters", response.DeadLetters, response.Pagination, nil)
+}
+
+// RequeueDeadLetter handles putting a dead-lettered notification back into the outbox (Admin)
+// @Summary Requeue dead letter
+// @Tags Admin - Monitoring
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Dead letter ID"
+// @Success 200 {object} utils.Response{data=OutboundMessage}
+// @Failure 404 {object} utils.Response
+// @Router /admin/notifications/dead-letters/{id}/requeue [post]
+func (h *NotificationHandler) RequeueDeadLetter(c *gin.Context) {
+	deadLetterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid dead letter ID", nil)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	message, err := h.service.RequeueDeadLetter(uint(deadLetterID), adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification requeued", message)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "REQUEUE_NOTIFICATION",
+		Entity:    "NOTIFICATION",
+		EntityID:  message.ID,
+		Details:   fmt.Sprintf("Admin requeued dead letter #%d to %s", deadLetterID, message.Recipient),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,109 @@
+package notification
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	Type      string     `json:"type" gorm:"size:50;not null"` // e.g., "low_stock"
+	Title     string     `json:"title" gorm:"size:255;not null"`
+	Message   string     `json:"message" gorm:"type:text"`
+	Link      string     `json:"link,omitempty" gorm:"size:500"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// OutboundMessage is a persisted outgoing delivery (email, ...) processed by the dispatcher
+type OutboundMessage struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"user_id" gorm:"index"`
+	Channel       string     `json:"channel" gorm:"size:20;not null"`
+	Recipient     string     `json:"recipient" gorm:"size:255;not null"`
+	Subject       string     `json:"subject" gorm:"size:255"`
+	Body          string     `json:"body" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"type:enum('pending','sending','sent','dead');default:'pending';index"`
+	Attempts      int        `json:"attempts" gorm:"default:0;not null"`
+	MaxAttempts   int        `json:"max_attempts" gorm:"default:5;not null"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError     string     `json:"last_error,omitempty" gorm:"type:text"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (OutboundMessage) TableName() string {
+	return "outbound_messages"
+}
+
+// DeadLetter keeps a message that failed permanently or exhausted its retries
+type DeadLetter struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	OutboundMessageID uint       `json:"outbound_message_id" gorm:"index"`
+	UserID            uint       `json:"user_id"`
+	Channel           string     `json:"channel" gorm:"size:20;not null"`
+	Recipient         string     `json:"recipient" gorm:"size:255"`
+	Subject           string     `json:"subject" gorm:"size:255"`
+	Body              string     `json:"body" gorm:"type:text"`
+	Attempts          int        `json:"attempts"`
+	Reason            string     `json:"reason" gorm:"type:text"`
+	RequeuedAt        *time.Time `json:"requeued_at"`
+	RequeuedBy        *uint      `json:"requeued_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+func (DeadLetter) TableName() string {
+	return "notification_dead_letters"
+}
+
+type OutboxListParams struct {
+	Status string
+	Page   int
+	Limit  int
+}
+
+type OutboxListResponse struct {
+	Messages []OutboundMessage `json:"messages"`
+	utils.Pagination
+}
+
+type DeadLetterListResponse struct {
+	DeadLetters []DeadLetter `json:"dead_letters"`
+	utils.Pagination
+}
+
+// NotifyParams describes a notification to deliver to a single user
+type NotifyParams struct {
+	UserID    uint
+	Type      string
+	Title     string
+	Message   string
+	Link      string
+	SendEmail bool
+}
+
+type NotificationListParams struct {
+	UserID     uint
+	UnreadOnly bool
+	Page       int
+	Limit      int
+}
+
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int64          `json:"unread"`
+	utils.Pagination
+}
+
+// Recipient is the minimal user info needed to deliver a notification
+type Recipient struct {
+	ID       uint
+	Email    string
+	FullName string
+}
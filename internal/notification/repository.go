@@ -0,0 +1,234 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(notification *Notification) error {
+	return r.db.Create(notification).Error
+}
+
+// FindAll gets notifications of a user with pagination
+func (r *NotificationRepository) FindAll(params NotificationListParams) ([]Notification, int64, error) {
+	var notifications []Notification
+	var total int64
+
+	query := r.db.Model(&Notification{}).Where("user_id = ?", params.UserID)
+	if params.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("created_at DESC").Limit(params.Limit).Offset(offset).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks a single notification of a user as read
+func (r *NotificationRepository) MarkRead(userID, notificationID uint) error {
+	result := r.db.Model(&Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllRead(userID uint) error {
+	return r.db.Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+// FindRecipients loads delivery info for the given active users
+func (r *NotificationRepository) FindRecipients(userIDs []uint) ([]Recipient, error) {
+	var recipients []Recipient
+	err := r.db.Table("users").
+		Select("id, email, full_name").
+		Where("id IN ? AND status = ? AND deleted_at IS NULL", userIDs, "active").
+		Scan(&recipients).Error
+	return recipients, err
+}
+
+// FindActiveAdminIDs returns the IDs of all active admins
+func (r *NotificationRepository) FindActiveAdminIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Table("users").
+		Where("role = ? AND status = ? AND deleted_at IS NULL", "admin", "active").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Outbox
+
+func (r *NotificationRepository) CreateOutbound(message *OutboundMessage) error {
+	return r.db.Create(message).Error
+}
+
+// ClaimDueOutbound marks up to limit due pending messages as sending and returns them
+func (r *NotificationRepository) ClaimDueOutbound(now time.Time, limit int) ([]OutboundMessage, error) {
+	var candidates []OutboundMessage
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", "pending", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]OutboundMessage, 0, len(candidates))
+	for _, message := range candidates {
+		// Conditional update so concurrent dispatchers never send the same message twice
+		result := r.db.Model(&OutboundMessage{}).
+			Where("id = ? AND status = ?", message.ID, "pending").
+			Update("status", "sending")
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			claimed = append(claimed, message)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *NotificationRepository) UpdateOutbound(messageID uint, updates map[string]interface{}) error {
+	return r.db.Model(&OutboundMessage{}).Where("id = ?", messageID).Updates(updates).Error
+}
+
+// MoveToDeadLetter marks the message dead and stores it in the dead-letter table
+func (r *NotificationRepository) MoveToDeadLetter(message *OutboundMessage, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&OutboundMessage{}).Where("id = ?", message.ID).Updates(map[string]interface{}{
+			"status":     "dead",
+			"attempts":   message.Attempts,
+			"last_error": reason,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&DeadLetter{
+			OutboundMessageID: message.ID,
+			UserID:            message.UserID,
+			Channel:           message.Channel,
+			Recipient:         message.Recipient,
+			Subject:           message.Subject,
+			Body:              message.Body,
+			Attempts:          message.Attempts,
+			Reason:            reason,
+		}).Error
+	})
+}
+
+// ResetStuckOutbound returns messages left in "sending" (e.g. after a crash) to the queue
+func (r *NotificationRepository) ResetStuckOutbound(before time.Time) error {
+	return r.db.Model(&OutboundMessage{}).
+		Where("status = ? AND updated_at < ?", "sending", before).
+		Update("status", "pending").Error
+}
+
+func (r *NotificationRepository) FindOutbound(params OutboxListParams) ([]OutboundMessage, int64, error) {
+	var messages []OutboundMessage
+	var total int64
+
+	query := r.db.Model(&OutboundMessage{})
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("created_at DESC").Limit(params.Limit).Offset(offset).Find(&messages).Error
+	return messages, total, err
+}
+
+func (r *NotificationRepository) FindDeadLetters(includeRequeued bool, page, limit int) ([]DeadLetter, int64, error) {
+	var deadLetters []DeadLetter
+	var total int64
+
+	query := r.db.Model(&DeadLetter{})
+	if !includeRequeued {
+		query = query.Where("requeued_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&deadLetters).Error
+	return deadLetters, total, err
+}
+
+func (r *NotificationRepository) FindDeadLetterByID(id uint) (*DeadLetter, error) {
+	var deadLetter DeadLetter
+	err := r.db.First(&deadLetter, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, err
+	}
+	return &deadLetter, nil
+}
+
+// Requeue creates a fresh outbound message from a dead letter and marks the dead letter as requeued
+func (r *NotificationRepository) Requeue(deadLetter *DeadLetter, adminID uint, now time.Time) (*OutboundMessage, error) {
+	message := &OutboundMessage{
+		UserID:        deadLetter.UserID,
+		Channel:       deadLetter.Channel,
+		Recipient:     deadLetter.Recipient,
+		Subject:       deadLetter.Subject,
+		Body:          deadLetter.Body,
+		Status:        "pending",
+		MaxAttempts:   defaultMaxAttempts,
+		NextAttemptAt: now,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		return tx.Model(&DeadLetter{}).Where("id = ?", deadLetter.ID).Updates(map[string]interface{}{
+			"requeued_at": now,
+			"requeued_by": adminID,
+		}).Error
+	})
+	return message, err
+}
+
+// FindUnreadSince returns the unread notifications created after since, grouped by user
+func (r *NotificationRepository) FindUnreadSince(ctx context.Context, since time.Time) ([]Notification, error) {
+	var notifications []Notification
+	err := r.db.WithContext(ctx).
+		Where("read_at IS NULL AND created_at >= ?", since).
+		Order("user_id, created_at").
+		Find(&notifications).Error
+	return notifications, err
+}
@@ -0,0 +1,310 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+	"wallet-point/internal/resilience"
+	"wallet-point/utils"
+)
+
+const (
+	ChannelEmail = "email"
+
+	defaultMaxAttempts = 5
+	retryBaseDelay     = 30 * time.Second
+	retryMaxDelay      = time.Hour
+	dispatchBatchSize  = 50
+	stuckSendingAfter  = 10 * time.Minute
+)
+
+type NotificationService struct {
+	repo    *NotificationRepository
+	senders map[string]Sender
+
+	// Dispatcher heartbeat, read by the readiness probe
+	dispatchInterval time.Duration
+	lastDispatchAt   atomic.Int64
+}
+
+func NewNotificationService(repo *NotificationRepository, emailSender Sender) *NotificationService {
+	return &NotificationService{
+		repo:    repo,
+		senders: map[string]Sender{ChannelEmail: emailSender},
+	}
+}
+
+// RegisterSender adds a delivery channel to the outbox dispatcher
+func (s *NotificationService) RegisterSender(channel string, sender Sender) {
+	s.senders[channel] = sender
+}
+
+// Notify creates an in-app notification and optionally queues an email to the user
+func (s *NotificationService) Notify(params NotifyParams) error {
+	notification := &Notification{
+		UserID:  params.UserID,
+		Type:    params.Type,
+		Title:   params.Title,
+		Message: params.Message,
+		Link:    params.Link,
+	}
+	if err := s.repo.Create(notification); err != nil {
+		return err
+	}
+
+	if params.SendEmail {
+		recipients, err := s.repo.FindRecipients([]uint{params.UserID})
+		if err != nil {
+			return err
+		}
+		for _, recipient := range recipients {
+			if err := s.Enqueue(recipient.ID, ChannelEmail, recipient.Email, params.Title, params.Message); err != nil {
+				slog.Error("notification: queue email failed", "user_id", recipient.ID, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// EmailUser queues an email to a user's address without creating an in-app notification
+func (s *NotificationService) EmailUser(userID uint, subject, body string) error {
+	recipients, err := s.repo.FindRecipients([]uint{userID})
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return errors.New("recipient not found or inactive")
+	}
+	return s.Enqueue(userID, ChannelEmail, recipients[0].Email, subject, body)
+}
+
+// NotifyMany delivers the same notification to several users
+func (s *NotificationService) NotifyMany(userIDs []uint, params NotifyParams) {
+	for _, userID := range userIDs {
+		params.UserID = userID
+		if err := s.Notify(params); err != nil {
+			slog.Error("notification: notify failed", "user_id", userID, "error", err)
+		}
+	}
+}
+
+// Enqueue persists an outgoing message; the dispatcher delivers it with retries
+func (s *NotificationService) Enqueue(userID uint, channel, recipient, subject, body string) error {
+	if _, ok := s.senders[channel]; !ok {
+		return fmt.Errorf("unknown notification channel %s", channel)
+	}
+	if recipient == "" {
+		return errors.New("recipient address is empty")
+	}
+
+	return s.repo.CreateOutbound(&OutboundMessage{
+		UserID:        userID,
+		Channel:       channel,
+		Recipient:     recipient,
+		Subject:       subject,
+		Body:          body,
+		Status:        "pending",
+		MaxAttempts:   defaultMaxAttempts,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// ProcessOutbox delivers due messages, rescheduling transient failures and dead-lettering permanent ones
+func (s *NotificationService) ProcessOutbox() {
+	now := time.Now()
+
+	if err := s.repo.ResetStuckOutbound(now.Add(-stuckSendingAfter)); err != nil {
+		slog.Error("outbox: reset stuck messages failed", "error", err)
+	}
+
+	messages, err := s.repo.ClaimDueOutbound(now, dispatchBatchSize)
+	if err != nil {
+		slog.Error("outbox: claim messages failed", "error", err)
+	}
+
+	for i := range messages {
+		s.deliver(&messages[i])
+	}
+}
+
+// StartDispatcher runs the outbox dispatcher in the background
+func (s *NotificationService) StartDispatcher(interval time.Duration) {
+	s.dispatchInterval = interval
+	s.lastDispatchAt.Store(time.Now().UnixNano())
+	utils.Go(func() {
+		for {
+			s.ProcessOutbox()
+			s.lastDispatchAt.Store(time.Now().UnixNano())
+			if !utils.Sleep(interval) {
+				// Deliver what the drained requests queued before exiting
+				s.ProcessOutbox()
+				return
+			}
+		}
+	})
+}
+
+// CheckDispatcher fails when the outbox dispatcher is not running or has missed
+// several rounds (e.g. stuck on a hanging SMTP connection)
+func (s *NotificationService) CheckDispatcher(ctx context.Context) error {
+	if s.dispatchInterval == 0 {
+		return errors.New("outbox dispatcher not started")
+	}
+	lastRun := time.Unix(0, s.lastDispatchAt.Load())
+	if since := time.Since(lastRun); since > 3*s.dispatchInterval+stuckSendingAfter {
+		return fmt.Errorf("outbox dispatcher stalled, last round %s ago", since.Round(time.Second))
+	}
+	return nil
+}
+
+func (s *NotificationService) deliver(message *OutboundMessage) {
+	message.Attempts++
+
+	sender, ok := s.senders[message.Channel]
+	if !ok {
+		s.deadLetter(message, "no sender registered for channel "+message.Channel)
+		return
+	}
+
+	err := sender.Send(message.Recipient, message.Subject, message.Body)
+	if err == nil {
+		s.repo.UpdateOutbound(message.ID, map[string]interface{}{
+			"status":     "sent",
+			"attempts":   message.Attempts,
+			"last_error": "",
+			"sent_at":    time.Now(),
+		})
+		return
+	}
+
+	if errors.Is(err, resilience.ErrOpen) {
+		// The provider is known to be down: keep the message queued without using up an attempt
+		s.repo.UpdateOutbound(message.ID, map[string]interface{}{
+			"status":          "pending",
+			"last_error":      err.Error(),
+			"next_attempt_at": time.Now().Add(retryDelay(1)),
+		})
+		return
+	}
+	if IsPermanent(err) {
+		s.deadLetter(message, err.Error())
+		return
+	}
+	if message.Attempts >= message.MaxAttempts {
+		s.deadLetter(message, fmt.Sprintf("gave up after %d attempts: %v", message.Attempts, err))
+		return
+	}
+
+	s.repo.UpdateOutbound(message.ID, map[string]interface{}{
+		"status":          "pending",
+		"attempts":        message.Attempts,
+		"last_error":      err.Error(),
+		"next_attempt_at": time.Now().Add(retryDelay(message.Attempts)),
+	})
+}
+
+func (s *NotificationService) deadLetter(message *OutboundMessage, reason string) {
+	if err := s.repo.MoveToDeadLetter(message, reason); err != nil {
+		slog.Error("outbox: dead-letter failed", "message_id", message.ID, "error", err)
+	}
+}
+
+// retryDelay grows exponentially from retryBaseDelay, is capped at retryMaxDelay and jittered
+func retryDelay(attempts int) time.Duration {
+	return resilience.Backoff(attempts, retryBaseDelay, retryMaxDelay)
+}
+
+// GetOutbox lists outgoing messages (Admin)
+func (s *NotificationService) GetOutbox(params OutboxListParams) (*OutboxListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	messages, total, err := s.repo.FindOutbound(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxListResponse{
+		Messages:   messages,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// GetDeadLetters lists dead-lettered messages (Admin)
+func (s *NotificationService) GetDeadLetters(includeRequeued bool, page, limit int) (*DeadLetterListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	deadLetters, total, err := s.repo.FindDeadLetters(includeRequeued, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeadLetterListResponse{
+		DeadLetters: deadLetters,
+		Pagination:  utils.NewPagination(page, limit, total, ""),
+	}, nil
+}
+
+// RequeueDeadLetter puts a dead-lettered message back into the outbox (Admin)
+func (s *NotificationService) RequeueDeadLetter(deadLetterID, adminID uint) (*OutboundMessage, error) {
+	deadLetter, err := s.repo.FindDeadLetterByID(deadLetterID)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter.RequeuedAt != nil {
+		return nil, ErrDeadLetterRequeued
+	}
+	return s.repo.Requeue(deadLetter, adminID, time.Now())
+}
+
+// GetAdminIDs returns all active admins, used as fallback recipients
+func (s *NotificationService) GetAdminIDs() ([]uint, error) {
+	return s.repo.FindActiveAdminIDs()
+}
+
+// GetNotifications lists notifications of the current user
+func (s *NotificationService) GetNotifications(params NotificationListParams) (*NotificationListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	notifications, total, err := s.repo.FindAll(params)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.repo.CountUnread(params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationListResponse{
+		Notifications: notifications,
+		Unread:        unread,
+		Pagination:    utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+func (s *NotificationService) MarkRead(userID, notificationID uint) error {
+	return s.repo.MarkRead(userID, notificationID)
+}
+
+func (s *NotificationService) MarkAllRead(userID uint) error {
+	return s.repo.MarkAllRead(userID)
+}
@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Email templates live in templates/<name>.tmpl and define "<name>.subject" and "<name>.body"
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var emailTemplates = template.Must(
+	template.New("email").Funcs(template.FuncMap{
+		"points": formatPoints,
+		"date":   func(t time.Time) string { return t.Format("02 Jan 2006") },
+		"pad":    func(width int, s string) string { return fmt.Sprintf("%-*s", width, s) },
+		"lower":  strings.ToLower,
+	}).ParseFS(templateFS, "templates/*.tmpl"),
+)
+
+// RenderEmail renders the subject and plain-text body of a named email template
+func RenderEmail(name string, data interface{}) (string, string, error) {
+	var subject, body bytes.Buffer
+	if err := emailTemplates.ExecuteTemplate(&subject, name+".subject", data); err != nil {
+		return "", "", err
+	}
+	if err := emailTemplates.ExecuteTemplate(&body, name+".body", data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
+
+// formatPoints renders a number with thousands separators (12.500), as used on the student dashboard
+func formatPoints(value interface{}) string {
+	var n int64
+	switch v := value.(type) {
+	case int:
+		n = int64(v)
+	case int64:
+		n = v
+	default:
+		return fmt.Sprint(value)
+	}
+
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	digits := strconv.FormatInt(n, 10)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "." + digits[i:]
+	}
+	return sign + digits
+}
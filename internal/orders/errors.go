@@ -0,0 +1,10 @@
+package orders
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrOrderNotFound = utils.NewAppError("ORDER_NOT_FOUND", http.StatusNotFound, "order not found")
+)
@@ -0,0 +1,78 @@
+package orders
+
+import (
+	"net/http"
+	"strconv"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrderHandler struct {
+	service *Service
+}
+
+func NewOrderHandler(service *Service) *OrderHandler {
+	return &OrderHandler{service: service}
+}
+
+// GetAll handles listing the student's orders
+// @Summary Get my orders
+// @Description Purchase history: the student's purchases and checkouts, newest first, with their totals, status and item_count. Gifts bought for others are included.
+// @Tags Mahasiswa - Orders
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, paid, fulfilled, cancelled)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=OrderListResponse}
+// @Router /mahasiswa/marketplace/orders [get]
+func (h *OrderHandler) GetAll(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "order_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetOrders(c.Request.Context(), OrderListParams{
+		UserID: c.GetUint("user_id"),
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve orders", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Orders retrieved successfully", "orders", response.Orders, response.Pagination, nil)
+}
+
+// GetByID handles getting one of the student's orders
+// @Summary Get my order
+// @Description An order with its items: product, unit price, quantity, promotion discount, total and delivery time of each
+// @Tags Mahasiswa - Orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.Response{data=Order}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/orders/{id} [get]
+func (h *OrderHandler) GetByID(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid order ID", nil)
+		return
+	}
+
+	order, err := h.service.GetOrder(c.Request.Context(), c.GetUint("user_id"), uint(orderID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order retrieved successfully", order)
+}
@@ -0,0 +1,80 @@
+package orders
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+// Order statuses. An order is pending while its purchase is running, paid once
+// the points are taken, fulfilled once every item is delivered (print quota at
+// once, physical products when collected) and cancelled when the purchase was
+// rolled back.
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusFulfilled = "fulfilled"
+	StatusCancelled = "cancelled"
+)
+
+// Order is one purchase or cart checkout of a student, with its items. The
+// wallet side of it is in wallet_transactions; each item also has the
+// marketplace transaction the admin sales history lists.
+type Order struct {
+	ID          uint        `json:"id" gorm:"primaryKey"`
+	TenantID    uint        `json:"tenant_id" gorm:"not null;default:1;index"`
+	UserID      uint        `json:"user_id" gorm:"not null;index"` // the buyer, whose wallet pays
+	WalletID    uint        `json:"wallet_id" gorm:"not null"`
+	RecipientID *uint       `json:"recipient_user_id,omitempty" gorm:"column:recipient_user_id"` // gifts: the student the items are issued to
+	Source      string      `json:"source" gorm:"size:20;not null"`                              // purchase or checkout
+	Status      string      `json:"status" gorm:"type:enum('pending','paid','fulfilled','cancelled');default:'pending';not null;index"`
+	Subtotal    int         `json:"subtotal" gorm:"not null"`
+	Discount    int         `json:"discount" gorm:"not null;default:0"`
+	Total       int         `json:"total" gorm:"not null"`
+	PaidAt      *time.Time  `json:"paid_at"`
+	FulfilledAt *time.Time  `json:"fulfilled_at"`
+	CancelledAt *time.Time  `json:"cancelled_at"`
+	CreatedAt   time.Time   `json:"created_at" gorm:"index"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Items       []OrderItem `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+}
+
+func (Order) TableName() string {
+	return "orders"
+}
+
+// OrderItem is one product of an order. Total is UnitPrice times Quantity less
+// the Discount of promotions.
+type OrderItem struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	OrderID       uint       `json:"order_id" gorm:"not null;index"`
+	ProductID     uint       `json:"product_id" gorm:"not null;index"`
+	ProductName   string     `json:"product_name" gorm:"size:255;not null"`
+	UnitPrice     int        `json:"unit_price" gorm:"not null"`
+	Quantity      int        `json:"quantity" gorm:"not null"`
+	Discount      int        `json:"discount" gorm:"not null;default:0"`
+	Total         int        `json:"total" gorm:"not null"`
+	TransactionID uint       `json:"transaction_id" gorm:"index"` // the marketplace transaction of the item
+	DeliveredAt   *time.Time `json:"delivered_at"`
+}
+
+func (OrderItem) TableName() string {
+	return "order_items"
+}
+
+// OrderSummary is an order in a history list: its items are counted, not listed
+type OrderSummary struct {
+	Order
+	ItemCount int `json:"item_count"`
+}
+
+type OrderListParams struct {
+	UserID uint
+	Status string
+	Page   int
+	Limit  int
+}
+
+type OrderListResponse struct {
+	Orders []OrderSummary `json:"orders"`
+	utils.Pagination
+}
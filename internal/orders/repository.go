@@ -0,0 +1,91 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create creates an order with its items
+func (r *Repository) Create(tx *gorm.DB, order *Order) error {
+	return tx.Create(order).Error
+}
+
+func (r *Repository) lock(tx *gorm.DB, id uint) (*Order, error) {
+	var order Order
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	return &order, err
+}
+
+func (r *Repository) update(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	return tx.Model(&Order{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// deliverItem marks the item of a marketplace transaction delivered and
+// returns its order ID, or 0 when no order has the transaction
+func (r *Repository) deliverItem(tx *gorm.DB, transactionID uint, at time.Time) (uint, error) {
+	var item OrderItem
+	err := tx.Where("transaction_id = ?", transactionID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&item).Update("delivered_at", at).Error; err != nil {
+		return 0, err
+	}
+	return item.OrderID, nil
+}
+
+// undelivered counts the items of an order not delivered yet
+func (r *Repository) undelivered(tx *gorm.DB, orderID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&OrderItem{}).Where("order_id = ? AND delivered_at IS NULL", orderID).Count(&count).Error
+	return count, err
+}
+
+// FindByUser returns a page of the orders of a buyer, newest first
+func (r *Repository) FindByUser(ctx context.Context, params OrderListParams) ([]OrderSummary, int64, error) {
+	query := r.db.WithContext(ctx).Model(&Order{}).Where("orders.user_id = ?", params.UserID)
+	if params.Status != "" {
+		query = query.Where("orders.status = ?", params.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []OrderSummary
+	err := query.
+		Select("orders.*, (SELECT COUNT(*) FROM order_items i WHERE i.order_id = orders.id) AS item_count").
+		Order("orders.created_at DESC").Order("orders.id DESC").
+		Offset((params.Page - 1) * params.Limit).Limit(params.Limit).
+		Scan(&orders).Error
+	return orders, total, err
+}
+
+// FindByID loads an order of the campus of ctx with its items
+func (r *Repository) FindByID(ctx context.Context, id uint) (*Order, error) {
+	var order Order
+	err := r.db.WithContext(ctx).Preload("Items").First(&order, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	return &order, err
+}
@@ -0,0 +1,100 @@
+package orders
+
+import (
+	"context"
+	"time"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// Service keeps the orders of the marketplace. The order saga of the
+// marketplace creates them and moves them along; students read their history.
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create records a pending order in the transaction of the purchase
+func (s *Service) Create(tx *gorm.DB, order *Order) error {
+	order.Status = StatusPending
+	return s.repo.Create(tx, order)
+}
+
+// MarkPaid records that the purchase of an order completed. An order whose
+// items were all delivered on the way is fulfilled at once.
+func (s *Service) MarkPaid(tx *gorm.DB, orderID uint) error {
+	order, err := s.repo.lock(tx, orderID)
+	if err != nil || order.Status != StatusPending {
+		return err
+	}
+	now := time.Now()
+	updates := map[string]interface{}{"status": StatusPaid, "paid_at": now}
+	undelivered, err := s.repo.undelivered(tx, orderID)
+	if err != nil {
+		return err
+	}
+	if undelivered == 0 {
+		updates["status"], updates["fulfilled_at"] = StatusFulfilled, now
+	}
+	return s.repo.update(tx, orderID, updates)
+}
+
+// Cancel records that the purchase of an order was rolled back
+func (s *Service) Cancel(tx *gorm.DB, orderID uint) error {
+	order, err := s.repo.lock(tx, orderID)
+	if err != nil || order.Status == StatusCancelled {
+		return err
+	}
+	return s.repo.update(tx, orderID, map[string]interface{}{"status": StatusCancelled, "cancelled_at": time.Now()})
+}
+
+// ItemDelivered records the delivery of the item of a marketplace transaction;
+// a paid order is fulfilled with its last item. Transactions of no order, made
+// before orders were kept, are ignored.
+func (s *Service) ItemDelivered(tx *gorm.DB, transactionID uint) error {
+	now := time.Now()
+	orderID, err := s.repo.deliverItem(tx, transactionID, now)
+	if err != nil || orderID == 0 {
+		return err
+	}
+	order, err := s.repo.lock(tx, orderID)
+	if err != nil || order.Status != StatusPaid {
+		return err
+	}
+	undelivered, err := s.repo.undelivered(tx, orderID)
+	if err != nil || undelivered > 0 {
+		return err
+	}
+	return s.repo.update(tx, orderID, map[string]interface{}{"status": StatusFulfilled, "fulfilled_at": now})
+}
+
+// GetOrders returns a page of a student's orders, newest first
+func (s *Service) GetOrders(ctx context.Context, params OrderListParams) (*OrderListResponse, error) {
+	orders, total, err := s.repo.FindByUser(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []OrderSummary{}
+	}
+	return &OrderListResponse{
+		Orders:     orders,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// GetOrder returns an order of userID with its items; other students' orders are not found
+func (s *Service) GetOrder(ctx context.Context, userID, orderID uint) (*Order, error) {
+	order, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
@@ -0,0 +1,250 @@
+// Package partition keeps the large append-only tables (wallet_transactions,
+// marketplace_transactions, audit_logs) partitioned by month on created_at, so
+// queries over a date range only read the months they cover and old months can
+// be dropped in one cheap statement instead of a long DELETE.
+package partition
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tables are the tables managed here. Their primary key is (id, created_at), as
+// MySQL requires every unique key of a partitioned table to include the
+// partitioning column.
+var Tables = []string{"wallet_transactions", "marketplace_transactions", "audit_logs"}
+
+const (
+	monthLayout  = "200601"
+	boundLayout  = "2006-01-02 15:04:05"
+	overflowName = "pmax" // catches rows past the last monthly partition
+)
+
+// PruneHook runs before the partition holding [from, to) of a table is dropped,
+// e.g. to archive its rows; an error keeps the partition
+type PruneHook func(ctx context.Context, from, to time.Time) error
+
+// Partition is one monthly partition; Month is zero for the overflow partition
+type Partition struct {
+	Name  string    `json:"name"`
+	Month time.Time `json:"month"`
+	Rows  int64     `json:"rows"` // InnoDB estimate
+}
+
+// TableStatus lists the partitions of a table
+type TableStatus struct {
+	Table       string      `json:"table"`
+	Partitioned bool        `json:"partitioned"`
+	Partitions  []Partition `json:"partitions,omitempty"`
+}
+
+type Manager struct {
+	db        *gorm.DB
+	ahead     int
+	retention map[string]int
+	hooks     map[string][]PruneHook
+}
+
+// NewManager keeps monthsAhead future months partitioned and drops the months of a
+// table older than its retention (in months); tables without one are kept forever
+func NewManager(db *gorm.DB, monthsAhead int, retention map[string]int) *Manager {
+	return &Manager{db: db, ahead: monthsAhead, retention: retention, hooks: make(map[string][]PruneHook)}
+}
+
+// OnPrune registers hook to run before a partition of table is dropped
+func (m *Manager) OnPrune(table string, hook PruneHook) {
+	m.hooks[table] = append(m.hooks[table], hook)
+}
+
+// Status lists the partitions of every managed table
+func (m *Manager) Status(ctx context.Context) ([]TableStatus, error) {
+	statuses := make([]TableStatus, 0, len(Tables))
+	for _, table := range Tables {
+		partitions, err := m.partitions(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, TableStatus{Table: table, Partitioned: len(partitions) > 0, Partitions: partitions})
+	}
+	return statuses, nil
+}
+
+// Convert partitions an existing table by month, from its oldest row up to the
+// months ahead. MySQL rebuilds the table, so run it (walletctl partitions init)
+// in a quiet period, e.g. with maintenance mode on. Tables already partitioned
+// are left alone.
+func (m *Manager) Convert(ctx context.Context, table string) (bool, error) {
+	if !managed(table) {
+		return false, fmt.Errorf("partition: %s is not a partitioned table", table)
+	}
+	existing, err := m.partitions(ctx, table)
+	if err != nil || len(existing) > 0 {
+		return false, err
+	}
+
+	var oldest *time.Time
+	if err := m.db.WithContext(ctx).Table(table).Select("MIN(created_at)").Row().Scan(&oldest); err != nil {
+		return false, err
+	}
+	from := monthStart(time.Now())
+	if oldest != nil {
+		from = monthStart(*oldest)
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE `%s` MODIFY created_at DATETIME(3) NOT NULL, DROP PRIMARY KEY, ADD PRIMARY KEY (id, created_at) "+
+		"PARTITION BY RANGE COLUMNS(created_at) (%s)", table, definitions(from, m.lastMonth()))
+	return true, m.db.WithContext(ctx).Exec(sql).Error
+}
+
+// Ensure creates the monthly partitions up to the months ahead by splitting them
+// off the (empty) overflow partition, and returns how many it added
+func (m *Manager) Ensure(ctx context.Context) (int, error) {
+	added := 0
+	for _, table := range Tables {
+		partitions, err := m.partitions(ctx, table)
+		if err != nil {
+			return added, err
+		}
+		latest := latestMonth(partitions)
+		if latest.IsZero() || !latest.Before(m.lastMonth()) {
+			continue
+		}
+
+		from := latest.AddDate(0, 1, 0)
+		sql := fmt.Sprintf("ALTER TABLE `%s` REORGANIZE PARTITION %s INTO (%s)", table, overflowName, definitions(from, m.lastMonth()))
+		if err := m.db.WithContext(ctx).Exec(sql).Error; err != nil {
+			return added, fmt.Errorf("partition: extending %s: %w", table, err)
+		}
+		added += months(from, m.lastMonth())
+	}
+	return added, nil
+}
+
+// Prune drops the monthly partitions older than each table's retention, after
+// running its prune hooks, and returns how many it dropped
+func (m *Manager) Prune(ctx context.Context, now time.Time) (int, error) {
+	dropped := 0
+	for _, table := range Tables {
+		keep, ok := m.retention[table]
+		if !ok || keep <= 0 {
+			continue
+		}
+		cutoff := monthStart(now).AddDate(0, -keep, 0)
+
+		partitions, err := m.partitions(ctx, table)
+		if err != nil {
+			return dropped, err
+		}
+		for _, partition := range partitions {
+			if partition.Month.IsZero() || !partition.Month.Before(cutoff) {
+				continue
+			}
+			from, to := partition.Month, partition.Month.AddDate(0, 1, 0)
+			for _, hook := range m.hooks[table] {
+				if err := hook(ctx, from, to); err != nil {
+					return dropped, fmt.Errorf("partition: prune hook for %s %s: %w", table, partition.Name, err)
+				}
+			}
+			if err := m.db.WithContext(ctx).Exec(fmt.Sprintf("ALTER TABLE `%s` DROP PARTITION %s", table, partition.Name)).Error; err != nil {
+				return dropped, err
+			}
+			slog.InfoContext(ctx, "partition: dropped", "table", table, "partition", partition.Name, "rows", partition.Rows)
+			dropped++
+		}
+	}
+	return dropped, nil
+}
+
+// Maintain runs Ensure and Prune, for the scheduler
+func (m *Manager) Maintain(ctx context.Context) (string, error) {
+	added, err := m.Ensure(ctx)
+	if err != nil {
+		return "", err
+	}
+	dropped, err := m.Prune(ctx, time.Now())
+	return fmt.Sprintf("added %d and dropped %d monthly partitions", added, dropped), err
+}
+
+// partitions lists the partitions of table; none means it is not partitioned
+func (m *Manager) partitions(ctx context.Context, table string) ([]Partition, error) {
+	var rows []struct {
+		PartitionName string
+		TableRows     int64
+	}
+	err := m.db.WithContext(ctx).
+		Table("information_schema.PARTITIONS").
+		Select("PARTITION_NAME AS partition_name, TABLE_ROWS AS table_rows").
+		Where("TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL", table).
+		Order("PARTITION_ORDINAL_POSITION").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]Partition, 0, len(rows))
+	for _, row := range rows {
+		partition := Partition{Name: row.PartitionName, Rows: row.TableRows}
+		if month, err := time.ParseInLocation(monthLayout, strings.TrimPrefix(row.PartitionName, "p"), time.Local); err == nil {
+			partition.Month = month
+		}
+		partitions = append(partitions, partition)
+	}
+	return partitions, nil
+}
+
+// lastMonth is the last month that gets its own partition
+func (m *Manager) lastMonth() time.Time {
+	return monthStart(time.Now()).AddDate(0, m.ahead, 0)
+}
+
+func managed(table string) bool {
+	for _, t := range Tables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+func latestMonth(partitions []Partition) time.Time {
+	months := make([]time.Time, 0, len(partitions))
+	for _, partition := range partitions {
+		if !partition.Month.IsZero() {
+			months = append(months, partition.Month)
+		}
+	}
+	if len(months) == 0 {
+		return time.Time{}
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+	return months[len(months)-1]
+}
+
+// definitions returns one partition per month from first to last, then the overflow
+func definitions(first, last time.Time) string {
+	var parts []string
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		parts = append(parts, fmt.Sprintf("PARTITION p%s VALUES LESS THAN ('%s')",
+			month.Format(monthLayout), month.AddDate(0, 1, 0).Format(boundLayout)))
+	}
+	parts = append(parts, fmt.Sprintf("PARTITION %s VALUES LESS THAN (MAXVALUE)", overflowName))
+	return strings.Join(parts, ", ")
+}
+
+func months(first, last time.Time) int {
+	n := 0
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		n++
+	}
+	return n
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,17 @@
+package payment
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrTopUpNotFound         = utils.NewAppError("TOPUP_NOT_FOUND", http.StatusNotFound, "top-up not found")
+	ErrTopUpNotPending       = utils.NewAppError("TOPUP_NOT_PENDING", http.StatusConflict, "top-up is no longer awaiting payment")
+	ErrTopUpAmountMismatch   = utils.NewAppError("TOPUP_AMOUNT_MISMATCH", http.StatusBadRequest, "paid amount does not match the top-up")
+	ErrTopUpPointsOutOfRange = utils.NewAppError("TOPUP_POINTS_OUT_OF_RANGE", http.StatusBadRequest, "top-up points are outside the allowed range")
+	ErrProviderUnavailable   = utils.NewAppError("PAYMENT_PROVIDER_UNAVAILABLE", http.StatusBadRequest, "payment provider is not available")
+	ErrProviderFailed        = utils.NewAppError("PAYMENT_PROVIDER_FAILED", http.StatusBadGateway, "payment provider could not start the payment")
+	ErrNotificationInvalid   = utils.NewAppError("PAYMENT_NOTIFICATION_INVALID", http.StatusUnauthorized, "payment notification could not be verified")
+	ErrNotManuallyConfirmed  = utils.NewAppError("TOPUP_NOT_MANUAL", http.StatusConflict, "only bank transfer top-ups are confirmed by an admin")
+)
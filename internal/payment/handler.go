@@ -0,0 +1,271 @@
+package payment
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewPaymentHandler(service *Service, auditService audit.Logger) *PaymentHandler {
+	return &PaymentHandler{service: service, auditService: auditService}
+}
+
+// GetOptions handles listing the payment providers and point price for top-ups
+// @Summary Get top-up options
+// @Description Enabled payment providers (the first is the default) and the price of a point in rupiah
+// @Tags Top-ups
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=TopUpOptions}
+// @Router /mahasiswa/topups/options [get]
+func (h *PaymentHandler) GetOptions(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Top-up options retrieved successfully", h.service.Options())
+}
+
+// CreateTopUp handles buying points
+// @Summary Create top-up
+// @Description Start buying points with the chosen provider: pay on payment_url (Midtrans) or follow the instructions (bank transfer). Points are credited once the payment is confirmed.
+// @Tags Top-ups
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateTopUpRequest true "Points and provider"
+// @Success 201 {object} utils.Response{data=TopUp}
+// @Failure 400 {object} utils.Response
+// @Failure 502 {object} utils.Response
+// @Router /mahasiswa/topups [post]
+func (h *PaymentHandler) CreateTopUp(c *gin.Context) {
+	var req CreateTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	topUp, err := h.service.CreateTopUp(c.Request.Context(), c.GetUint("user_id"), req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Top-up created", topUp)
+}
+
+// GetMyTopUps handles listing the current user's top-ups
+// @Summary List my top-ups
+// @Tags Top-ups
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, paid, failed, expired)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=TopUpListResponse}
+// @Router /mahasiswa/topups [get]
+func (h *PaymentHandler) GetMyTopUps(c *gin.Context) {
+	h.list(c, c.GetUint("user_id"))
+}
+
+// GetMyTopUp handles getting one of the current user's top-ups
+// @Summary Get my top-up
+// @Tags Top-ups
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Top-up ID"
+// @Success 200 {object} utils.Response{data=TopUp}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/topups/{id} [get]
+func (h *PaymentHandler) GetMyTopUp(c *gin.Context) {
+	h.get(c, false)
+}
+
+// GetTopUps handles listing the top-ups of the campus (Admin)
+// @Summary List top-ups
+// @Description Bank transfer top-ups waiting for confirmation are listed with status=pending&provider=manual
+// @Tags Admin - Top-ups
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, paid, failed, expired)
+// @Param provider query string false "Filter by provider" example(manual)
+// @Param user_id query int false "Filter by user"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=TopUpListResponse}
+// @Router /admin/topups [get]
+func (h *PaymentHandler) GetTopUps(c *gin.Context) {
+	userID, _ := strconv.ParseUint(c.Query("user_id"), 10, 32)
+	h.list(c, uint(userID))
+}
+
+// GetTopUp handles getting a top-up (Admin)
+// @Summary Get top-up
+// @Tags Admin - Top-ups
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Top-up ID"
+// @Success 200 {object} utils.Response{data=TopUp}
+// @Failure 404 {object} utils.Response
+// @Router /admin/topups/{id} [get]
+func (h *PaymentHandler) GetTopUp(c *gin.Context) {
+	h.get(c, true)
+}
+
+// ConfirmTopUp handles confirming a received bank transfer (Admin)
+// @Summary Confirm bank transfer top-up
+// @Description Credit the points of a bank transfer top-up once the money shows on the account statement
+// @Tags Admin - Top-ups
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Top-up ID"
+// @Success 200 {object} utils.Response{data=TopUp}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/topups/{id}/confirm [post]
+func (h *PaymentHandler) ConfirmTopUp(c *gin.Context) {
+	topUpID, ok := topUpIDParam(c)
+	if !ok {
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	topUp, err := h.service.Confirm(c.Request.Context(), topUpID, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Top-up confirmed", topUp)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CONFIRM_TOPUP",
+		Entity:    "TOPUP",
+		EntityID:  topUp.ID,
+		Details:   fmt.Sprintf("Confirmed bank transfer %s: %d points for Rp%d to user %d", topUp.OrderID, topUp.Points, topUp.Amount, topUp.UserID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// RejectTopUp handles rejecting a bank transfer that never arrived (Admin)
+// @Summary Reject bank transfer top-up
+// @Tags Admin - Top-ups
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Top-up ID"
+// @Param request body RejectTopUpRequest true "Reason shown to the user"
+// @Success 200 {object} utils.Response{data=TopUp}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/topups/{id}/reject [post]
+func (h *PaymentHandler) RejectTopUp(c *gin.Context) {
+	topUpID, ok := topUpIDParam(c)
+	if !ok {
+		return
+	}
+	var req RejectTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	topUp, err := h.service.Reject(c.Request.Context(), topUpID, adminID, req.Reason)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Top-up rejected", topUp)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "REJECT_TOPUP",
+		Entity:    "TOPUP",
+		EntityID:  topUp.ID,
+		Details:   fmt.Sprintf("Rejected bank transfer %s | Reason: %s", topUp.OrderID, req.Reason),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Webhook handles payment notifications of every provider
+// @Summary Payment provider notification
+// @Description Called by the payment provider (e.g. Midtrans' HTTP notification) when a payment changes. The call is verified with the provider's signature; repeated notifications are harmless.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider" Enums(midtrans)
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /payment/webhooks/{provider} [post]
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	provider := c.Param("provider")
+	topUp, err := h.service.HandleNotification(c.Request.Context(), provider, c.Request)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "payment: notification rejected", "provider", provider, "error", err)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification processed", gin.H{"order_id": topUp.OrderID, "status": topUp.Status})
+}
+
+func (h *PaymentHandler) list(c *gin.Context, userID uint) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "topup_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetTopUps(c.Request.Context(), TopUpListParams{
+		UserID:   userID,
+		Status:   status,
+		Provider: c.Query("provider"),
+		Page:     page,
+		Limit:    limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve top-ups", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Top-ups retrieved successfully", "topups", response.TopUps, response.Pagination, nil)
+}
+
+func (h *PaymentHandler) get(c *gin.Context, isAdmin bool) {
+	topUpID, ok := topUpIDParam(c)
+	if !ok {
+		return
+	}
+
+	topUp, err := h.service.GetTopUp(c.Request.Context(), topUpID, c.GetUint("user_id"), isAdmin)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Top-up retrieved successfully", topUp)
+}
+
+func topUpIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid top-up ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,32 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ManualTransfer takes payments by bank transfer: the user transfers the amount
+// with the order ID as the transfer note, and an admin confirms the top-up once
+// the money shows on the account statement.
+type ManualTransfer struct {
+	Bank    string
+	Account string
+	Holder  string
+}
+
+func (p *ManualTransfer) Info() ProviderInfo {
+	return ProviderInfo{Name: "manual", DisplayName: "Transfer bank " + p.Bank}
+}
+
+func (p *ManualTransfer) Checkout(ctx context.Context, topUp *TopUp) (*Checkout, error) {
+	return &Checkout{
+		Instructions: fmt.Sprintf("Transfer Rp%d ke rekening %s %s a.n. %s dengan berita transfer %s sebelum %s. Poin ditambahkan setelah transfer dikonfirmasi admin.",
+			topUp.Amount, p.Bank, p.Account, p.Holder, topUp.OrderID, topUp.ExpiresAt.Format("02-01-2006 15:04")),
+	}, nil
+}
+
+// ParseNotification fails: bank transfers are confirmed by an admin, not by a webhook
+func (p *ManualTransfer) ParseNotification(r *http.Request) (*Notification, error) {
+	return nil, ErrProviderUnavailable
+}
@@ -0,0 +1,93 @@
+package payment
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	StatusPending = "pending"
+	StatusPaid    = "paid"
+	StatusFailed  = "failed"
+	StatusExpired = "expired"
+)
+
+// TopUp is the purchase of wallet points with money through a payment provider.
+// OrderID is the reference the provider knows it by; Amount is in rupiah.
+type TopUp struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	TenantID     uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	OrderID      string     `json:"order_id" gorm:"size:50;uniqueIndex;not null"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	WalletID     uint       `json:"wallet_id" gorm:"not null"`
+	Provider     string     `json:"provider" gorm:"size:30;not null"`
+	Points       int        `json:"points" gorm:"not null"`
+	Amount       int64      `json:"amount" gorm:"not null"`
+	Status       string     `json:"status" gorm:"type:enum('pending','paid','failed','expired');default:'pending';not null;index"`
+	ProviderRef  string     `json:"provider_ref,omitempty" gorm:"size:100"`
+	PaymentURL   string     `json:"payment_url,omitempty" gorm:"size:500"`
+	Instructions string     `json:"instructions,omitempty" gorm:"type:text"`
+	Note         string     `json:"note,omitempty" gorm:"size:255"` // why it failed, or the admin's remark
+	ConfirmedBy  *uint      `json:"confirmed_by,omitempty"`         // admin who settled a bank transfer
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null"`
+	PaidAt       *time.Time `json:"paid_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (TopUp) TableName() string {
+	return "topups"
+}
+
+// Checkout is what a provider returns for a new top-up: where (or how) to pay
+type Checkout struct {
+	Reference    string
+	PaymentURL   string
+	Instructions string
+}
+
+// Notification is a payment update, from a provider's webhook or an admin's
+// confirmation of a bank transfer. Every update goes through Service.Settle.
+type Notification struct {
+	OrderID   string
+	Reference string
+	Status    string // StatusPaid, StatusFailed, StatusExpired, or StatusPending for no change
+	Amount    int64
+	Note      string
+}
+
+// ProviderInfo describes a provider users can choose
+type ProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type CreateTopUpRequest struct {
+	Points   int    `json:"points" binding:"required,gt=0"`
+	Provider string `json:"provider" example:"midtrans"` // defaults to the first enabled provider
+}
+
+type RejectTopUpRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// TopUpOptions returns the providers and the price of a point, for the top-up form
+type TopUpOptions struct {
+	Providers  []ProviderInfo `json:"providers"`
+	PointPrice int64          `json:"point_price"`
+	MinPoints  int            `json:"min_points"`
+	MaxPoints  int            `json:"max_points"`
+}
+
+type TopUpListParams struct {
+	UserID   uint
+	Status   string
+	Provider string
+	Page     int
+	Limit    int
+}
+
+type TopUpListResponse struct {
+	TopUps []TopUp `json:"topups"`
+	utils.Pagination
+}
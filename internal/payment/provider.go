@@ -0,0 +1,22 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+)
+
+var (
+	_ Provider = (*Midtrans)(nil)
+	_ Provider = (*ManualTransfer)(nil)
+)
+
+// Provider takes payments for top-ups. New gateways implement it and are
+// registered with Service.RegisterProvider; users pick one per top-up.
+type Provider interface {
+	Info() ProviderInfo
+	// Checkout starts the payment of topUp
+	Checkout(ctx context.Context, topUp *TopUp) (*Checkout, error)
+	// ParseNotification verifies a webhook call of the provider and reads the
+	// payment update it carries
+	ParseNotification(r *http.Request) (*Notification, error)
+}
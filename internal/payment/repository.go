@@ -0,0 +1,88 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, topUp *TopUp) error {
+	return r.db.WithContext(ctx).Create(topUp).Error
+}
+
+func (r *Repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&TopUp{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *Repository) FindByID(ctx context.Context, id uint) (*TopUp, error) {
+	var topUp TopUp
+	if err := r.db.WithContext(ctx).First(&topUp, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTopUpNotFound
+		}
+		return nil, err
+	}
+	return &topUp, nil
+}
+
+// LockByOrderID loads a top-up for update within tx, so concurrent notifications
+// of the same payment settle it once
+func (r *Repository) LockByOrderID(tx *gorm.DB, orderID string) (*TopUp, error) {
+	var topUp TopUp
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).First(&topUp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTopUpNotFound
+		}
+		return nil, err
+	}
+	return &topUp, nil
+}
+
+// FindAll lists top-ups newest first
+func (r *Repository) FindAll(ctx context.Context, params TopUpListParams) ([]TopUp, int64, error) {
+	var topUps []TopUp
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&TopUp{})
+	if params.UserID != 0 {
+		query = query.Where("user_id = ?", params.UserID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.Provider != "" {
+		query = query.Where("provider = ?", params.Provider)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("created_at DESC").Order("id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&topUps).Error
+	return topUps, total, err
+}
+
+// FindExpired returns the order ID and provider of the pending top-ups past their expiry
+func (r *Repository) FindExpired(ctx context.Context, now time.Time) ([]TopUp, error) {
+	var topUps []TopUp
+	err := r.db.WithContext(ctx).Select("id", "order_id", "provider").
+		Where("status = ? AND expires_at < ?", StatusPending, now).
+		Order("id").
+		Find(&topUps).Error
+	return topUps, err
+}
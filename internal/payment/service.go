@@ -0,0 +1,375 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"wallet-point/internal/alert"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// Config prices points and bounds a single top-up
+type Config struct {
+	PointPrice int64 // rupiah per point
+	MinPoints  int
+	MaxPoints  int
+	Expiry     time.Duration // how long a top-up waits for its payment
+}
+
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	walletService *wallet.WalletService
+	notifications *notification.NotificationService
+	alerts        *alert.Service
+	cfg           Config
+	providers     map[string]Provider
+	order         []string // provider names in registration order; the first is the default
+}
+
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService, cfg Config) *Service {
+	return &Service{
+		repo:          repo,
+		db:            db,
+		walletService: walletService,
+		cfg:           cfg,
+		providers:     make(map[string]Provider),
+	}
+}
+
+// RegisterProvider makes provider available for new top-ups and its webhook
+func (s *Service) RegisterProvider(provider Provider) {
+	name := provider.Info().Name
+	if _, exists := s.providers[name]; !exists {
+		s.order = append(s.order, name)
+	}
+	s.providers[name] = provider
+}
+
+// SetNotifications tells users when their top-up is paid, rejected or expired
+func (s *Service) SetNotifications(notifications *notification.NotificationService) {
+	s.notifications = notifications
+}
+
+// Options lists the enabled providers and the pricing, for the top-up form
+func (s *Service) Options() TopUpOptions {
+	options := TopUpOptions{
+		Providers:  make([]ProviderInfo, 0, len(s.order)),
+		PointPrice: s.cfg.PointPrice,
+		MinPoints:  s.cfg.MinPoints,
+		MaxPoints:  s.cfg.MaxPoints,
+	}
+	for _, name := range s.order {
+		options.Providers = append(options.Providers, s.providers[name].Info())
+	}
+	return options
+}
+
+// CreateTopUp records a pending top-up and starts its payment with the chosen
+// provider (the first enabled one when none is given)
+func (s *Service) CreateTopUp(ctx context.Context, userID uint, req CreateTopUpRequest) (*TopUp, error) {
+	name := req.Provider
+	if name == "" && len(s.order) > 0 {
+		name = s.order[0]
+	}
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrProviderUnavailable
+	}
+	if req.Points < s.cfg.MinPoints || req.Points > s.cfg.MaxPoints {
+		return nil, ErrTopUpPointsOutOfRange
+	}
+
+	userWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	topUp := &TopUp{
+		OrderID:   newOrderID(now),
+		UserID:    userID,
+		WalletID:  userWallet.ID,
+		Provider:  name,
+		Points:    req.Points,
+		Amount:    int64(req.Points) * s.cfg.PointPrice,
+		Status:    StatusPending,
+		ExpiresAt: now.Add(s.cfg.Expiry),
+		CreatedAt: now,
+	}
+	if err := s.repo.Create(ctx, topUp); err != nil {
+		return nil, err
+	}
+
+	checkout, err := provider.Checkout(ctx, topUp)
+	if err != nil {
+		slog.ErrorContext(ctx, "payment: checkout failed", "provider", name, "order_id", topUp.OrderID, "error", err)
+		topUp.Status = StatusFailed
+		topUp.Note = "checkout failed"
+		if err := s.repo.Update(ctx, topUp.ID, map[string]interface{}{"status": topUp.Status, "note": topUp.Note}); err != nil {
+			slog.ErrorContext(ctx, "payment: marking top-up failed", "order_id", topUp.OrderID, "error", err)
+		}
+		return nil, ErrProviderFailed
+	}
+
+	topUp.ProviderRef = checkout.Reference
+	topUp.PaymentURL = checkout.PaymentURL
+	topUp.Instructions = checkout.Instructions
+	err = s.repo.Update(ctx, topUp.ID, map[string]interface{}{
+		"provider_ref": topUp.ProviderRef,
+		"payment_url":  topUp.PaymentURL,
+		"instructions": topUp.Instructions,
+	})
+	return topUp, err
+}
+
+// GetTopUp returns a top-up to its owner, or to an admin
+func (s *Service) GetTopUp(ctx context.Context, id, userID uint, isAdmin bool) (*TopUp, error) {
+	topUp, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && topUp.UserID != userID {
+		return nil, ErrTopUpNotFound
+	}
+	return topUp, nil
+}
+
+func (s *Service) GetTopUps(ctx context.Context, params TopUpListParams) (*TopUpListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	topUps, total, err := s.repo.FindAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &TopUpListResponse{
+		TopUps:     topUps,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// SetAlerts reports rejected or failed provider webhooks to the operations channel
+func (s *Service) SetAlerts(alerts *alert.Service) {
+	s.alerts = alerts
+}
+
+// HandleNotification verifies and applies a webhook call of the named provider
+func (s *Service) HandleNotification(ctx context.Context, name string, r *http.Request) (*TopUp, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrProviderUnavailable
+	}
+	notification, err := provider.ParseNotification(r)
+	if err != nil {
+		s.alertWebhook(name, "", err)
+		return nil, err
+	}
+	topUp, err := s.Settle(ctx, name, notification, nil)
+	if err != nil {
+		s.alertWebhook(name, notification.OrderID, err)
+	}
+	return topUp, err
+}
+
+// alertWebhook reports a provider notification that could not be applied; a
+// paid top-up stuck behind it needs an admin
+func (s *Service) alertWebhook(provider, orderID string, err error) {
+	if s.alerts == nil {
+		return
+	}
+	fields := map[string]string{"provider": provider}
+	if orderID != "" {
+		fields["order"] = orderID
+	}
+	s.alerts.Send(alert.Alert{
+		Key:      "payment.webhook." + provider,
+		Severity: alert.SeverityWarning,
+		Title:    "Payment notification failed",
+		Text:     err.Error(),
+		Fields:   fields,
+	})
+}
+
+// Confirm settles a bank transfer top-up once an admin has seen the money arrive
+func (s *Service) Confirm(ctx context.Context, id, adminID uint) (*TopUp, error) {
+	topUp, err := s.manualTopUp(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Settle(ctx, topUp.Provider, &Notification{
+		OrderID: topUp.OrderID,
+		Status:  StatusPaid,
+		Amount:  topUp.Amount,
+		Note:    "transfer confirmed",
+	}, &adminID)
+}
+
+// Reject fails a bank transfer top-up whose money never arrived
+func (s *Service) Reject(ctx context.Context, id, adminID uint, reason string) (*TopUp, error) {
+	topUp, err := s.manualTopUp(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Settle(ctx, topUp.Provider, &Notification{
+		OrderID: topUp.OrderID,
+		Status:  StatusFailed,
+		Note:    reason,
+	}, &adminID)
+}
+
+func (s *Service) manualTopUp(ctx context.Context, id uint) (*TopUp, error) {
+	topUp, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if topUp.Provider != "manual" {
+		return nil, ErrNotManuallyConfirmed
+	}
+	return topUp, nil
+}
+
+// Settle applies a payment update of the named provider, whether it came from a
+// webhook or an admin (adminID). Paying credits the points in the same
+// transaction. Repeated updates are no-ops, so providers may redeliver freely;
+// money received for an expired top-up is still credited.
+func (s *Service) Settle(ctx context.Context, provider string, update *Notification, adminID *uint) (*TopUp, error) {
+	var topUp *TopUp
+	changed := false
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		var err error
+		changed = false
+		topUp, err = s.repo.LockByOrderID(tx, update.OrderID)
+		if err != nil {
+			return err
+		}
+		if topUp.Provider != provider {
+			return ErrTopUpNotFound
+		}
+
+		switch {
+		case update.Status == StatusPending || update.Status == topUp.Status:
+			return nil
+		case topUp.Status == StatusPaid || topUp.Status == StatusFailed:
+			return ErrTopUpNotPending
+		case topUp.Status == StatusExpired && update.Status != StatusPaid:
+			return ErrTopUpNotPending
+		}
+
+		topUp.Status = update.Status
+		topUp.Note = update.Note
+		updates := map[string]interface{}{"status": topUp.Status, "note": topUp.Note}
+		if update.Reference != "" {
+			topUp.ProviderRef = update.Reference
+			updates["provider_ref"] = topUp.ProviderRef
+		}
+		if adminID != nil {
+			topUp.ConfirmedBy = adminID
+			updates["confirmed_by"] = *adminID
+		}
+		if update.Status == StatusPaid {
+			if update.Amount != topUp.Amount {
+				slog.WarnContext(ctx, "payment: amount mismatch", "order_id", topUp.OrderID, "expected", topUp.Amount, "paid", update.Amount)
+				return ErrTopUpAmountMismatch
+			}
+			description := fmt.Sprintf("Top-up %s via %s", topUp.OrderID, s.displayName(provider))
+			if err := s.walletService.CreditWithTransaction(tx, topUp.WalletID, topUp.Points, "topup", description); err != nil {
+				return err
+			}
+			paidAt := time.Now()
+			topUp.PaidAt = &paidAt
+			updates["paid_at"] = paidAt
+		}
+		if err := tx.Model(topUp).Updates(updates).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		s.notify(topUp)
+	}
+	return topUp, nil
+}
+
+// ExpirePending expires the pending top-ups whose payment window has passed
+func (s *Service) ExpirePending(ctx context.Context) (string, error) {
+	topUps, err := s.repo.FindExpired(ctx, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	expired := 0
+	for _, topUp := range topUps {
+		_, err := s.Settle(ctx, topUp.Provider, &Notification{OrderID: topUp.OrderID, Status: StatusExpired, Note: "payment window passed"}, nil)
+		if errors.Is(err, ErrTopUpNotPending) {
+			continue // paid or failed meanwhile
+		}
+		if err != nil {
+			return fmt.Sprintf("expired %d top-ups", expired), err
+		}
+		expired++
+	}
+	return fmt.Sprintf("expired %d top-ups", expired), nil
+}
+
+func (s *Service) notify(topUp *TopUp) {
+	if s.notifications == nil {
+		return
+	}
+
+	params := notification.NotifyParams{
+		UserID: topUp.UserID,
+		Type:   "topup_" + topUp.Status,
+		Link:   fmt.Sprintf("/topups/%d", topUp.ID),
+	}
+	switch topUp.Status {
+	case StatusPaid:
+		params.Title = "Top-up berhasil"
+		params.Message = fmt.Sprintf("%d poin sudah ditambahkan ke dompet Anda (%s).", topUp.Points, topUp.OrderID)
+		params.SendEmail = true
+	case StatusFailed:
+		params.Title = "Top-up gagal"
+		params.Message = fmt.Sprintf("Top-up %s tidak dapat diproses: %s", topUp.OrderID, topUp.Note)
+	case StatusExpired:
+		params.Title = "Top-up kedaluwarsa"
+		params.Message = fmt.Sprintf("Pembayaran top-up %s tidak diterima sebelum batas waktu.", topUp.OrderID)
+	default:
+		return
+	}
+	if err := s.notifications.Notify(params); err != nil {
+		slog.Error("payment: notify user failed", "order_id", topUp.OrderID, "error", err)
+	}
+}
+
+func (s *Service) displayName(provider string) string {
+	if p, ok := s.providers[provider]; ok {
+		return p.Info().DisplayName
+	}
+	return provider
+}
+
+// newOrderID returns a unique, human readable order ID, e.g. TU-20261017-9F2C4A1B
+func newOrderID(now time.Time) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return "TU-" + now.Format("20060102") + "-" + strings.ToUpper(hex.EncodeToString(suffix))
+}
@@ -0,0 +1,15 @@
+package pointgift
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrGiftNotFound       = utils.NewAppError("POINT_GIFT_NOT_FOUND", http.StatusNotFound, "points gift not found")
+	ErrGiftToSelf         = utils.NewAppError("POINT_GIFT_TO_SELF", http.StatusBadRequest, "you cannot send a points gift to yourself")
+	ErrRecipientNotFound  = utils.NewAppError("POINT_GIFT_RECIPIENT_NOT_FOUND", http.StatusNotFound, "recipient not found or has no wallet")
+	ErrInvalidDeliverDate = utils.NewAppError("POINT_GIFT_INVALID_DELIVER_AT", http.StatusBadRequest, "deliver_at must be between now and one year ahead")
+	ErrGiftNotDelivered   = utils.NewAppError("POINT_GIFT_NOT_DELIVERED", http.StatusConflict, "points gift cannot be claimed before its delivery date")
+	ErrGiftClosed         = utils.NewAppError("POINT_GIFT_CLOSED", http.StatusConflict, "points gift was already claimed or has expired")
+)
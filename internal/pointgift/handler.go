@@ -0,0 +1,138 @@
+package pointgift
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PointGiftHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewPointGiftHandler(service *Service, auditService audit.Logger) *PointGiftHandler {
+	return &PointGiftHandler{service: service, auditService: auditService}
+}
+
+// Send handles sending a points gift (Mahasiswa)
+// @Summary Send points gift
+// @Description Sends points with a message card. The points leave your wallet now and are held for the recipient, who is notified and can claim them from deliver_at (right away when left out) until transfer.gift_expiry_days later; an unclaimed gift returns to you. Gifts count towards the transfer limits.
+// @Tags Mahasiswa - Point Gifts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SendGiftRequest true "Gift"
+// @Success 201 {object} utils.Response{data=Gift}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/point-gifts [post]
+func (h *PointGiftHandler) Send(c *gin.Context) {
+	var req SendGiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	gift, err := h.service.Send(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Points gift sent", gift)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "SEND_POINT_GIFT",
+		Entity:    "POINT_GIFT",
+		EntityID:  gift.ID,
+		Details:   fmt.Sprintf("Sent a %d point gift to user %d, deliverable from %s", gift.Amount, gift.RecipientID, gift.DeliverAt.Format("2006-01-02 15:04")),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetAll handles listing the student's points gifts (Mahasiswa)
+// @Summary List points gifts
+// @Description Gifts you received (shown once delivered) or sent, newest first
+// @Tags Mahasiswa - Point Gifts
+// @Security BearerAuth
+// @Produce json
+// @Param box query string false "Received or sent gifts" Enums(received, sent) default(received)
+// @Param status query string false "Filter by status" Enums(pending, claimed, expired)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=GiftListResponse}
+// @Router /mahasiswa/point-gifts [get]
+func (h *PointGiftHandler) GetAll(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	box, ok := utils.QueryEnum(c, "box", "point_gift_box")
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "point_gift_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetGifts(c.Request.Context(), GiftListParams{
+		UserID: c.GetUint("user_id"),
+		Box:    box,
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve points gifts", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Points gifts retrieved successfully", "gifts", response.Gifts, response.Pagination, nil)
+}
+
+// Claim handles claiming a received points gift (Mahasiswa)
+// @Summary Claim points gift
+// @Description Credits a delivered gift to your wallet
+// @Tags Mahasiswa - Point Gifts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Gift ID"
+// @Success 200 {object} utils.Response{data=Gift}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /mahasiswa/point-gifts/{id}/claim [post]
+func (h *PointGiftHandler) Claim(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid gift ID", nil)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	gift, err := h.service.Claim(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Points gift claimed", gift)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CLAIM_POINT_GIFT",
+		Entity:    "POINT_GIFT",
+		EntityID:  gift.ID,
+		Details:   fmt.Sprintf("Claimed a %d point gift from user %d", gift.Amount, gift.SenderID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,71 @@
+package pointgift
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	StatusPending = "pending" // points held, claimable from DeliverAt until ExpiresAt
+	StatusClaimed = "claimed"
+	StatusExpired = "expired" // returned to the sender unclaimed
+)
+
+// Gift is an amount of points sent with a message card. The points leave the
+// sender's wallet when the gift is sent and are held until the recipient claims
+// them, which they can from DeliverAt on; a gift still unclaimed at ExpiresAt
+// goes back to the sender.
+type Gift struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	TenantID       uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	SenderID       uint       `json:"sender_id" gorm:"not null;index"`
+	SenderWalletID uint       `json:"sender_wallet_id" gorm:"not null"`
+	RecipientID    uint       `json:"recipient_id" gorm:"not null;index"`
+	Amount         int        `json:"amount" gorm:"not null"`
+	DebitID        uint       `json:"-" gorm:"not null;default:0"` // the sender's wallet debit, refunded when the gift expires
+	Message        string     `json:"message" gorm:"size:500"`
+	Status         string     `json:"status" gorm:"type:enum('pending','claimed','expired');default:'pending';not null;index"`
+	DeliverAt      time.Time  `json:"deliver_at" gorm:"not null;index"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"not null;index"`
+	NotifiedAt     *time.Time `json:"-"` // when the recipient was told the gift arrived
+	ClaimedAt      *time.Time `json:"claimed_at"`
+	ExpiredAt      *time.Time `json:"expired_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Gift) TableName() string {
+	return "point_gifts"
+}
+
+// GiftWithNames is a gift as listed to its sender or recipient
+type GiftWithNames struct {
+	Gift
+	SenderName    string `json:"sender_name"`
+	RecipientName string `json:"recipient_name"`
+}
+
+// SendGiftRequest sends points with a message card; without deliver_at the
+// recipient can claim them right away
+type SendGiftRequest struct {
+	RecipientUserID uint       `json:"recipient_user_id" binding:"required"`
+	Amount          int        `json:"amount" binding:"required,points"`
+	Message         string     `json:"message" binding:"max=500"`
+	DeliverAt       *time.Time `json:"deliver_at"`
+	PIN             string     `json:"pin" binding:"required"`
+}
+
+// GiftListParams lists the gifts a user received or sent. Received gifts show
+// only once delivered, so a scheduled gift stays a surprise.
+type GiftListParams struct {
+	UserID uint
+	Box    string // received (default) or sent
+	Status string
+	Page   int
+	Limit  int
+}
+
+type GiftListResponse struct {
+	Gifts []GiftWithNames `json:"gifts"`
+	utils.Pagination
+}
@@ -0,0 +1,102 @@
+package pointgift
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(tx *gorm.DB, gift *Gift) error {
+	return tx.Create(gift).Error
+}
+
+// Lock loads a gift for update within tx, so it is claimed or expired once
+func (r *Repository) Lock(tx *gorm.DB, id uint) (*Gift, error) {
+	var gift Gift
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&gift, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGiftNotFound
+		}
+		return nil, err
+	}
+	return &gift, nil
+}
+
+func (r *Repository) Update(tx *gorm.DB, gift *Gift, updates map[string]interface{}) error {
+	return tx.Model(gift).Updates(updates).Error
+}
+
+// FindAll lists the gifts of params.UserID, newest first
+func (r *Repository) FindAll(ctx context.Context, params GiftListParams, now time.Time) ([]GiftWithNames, int64, error) {
+	var gifts []GiftWithNames
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Gift{})
+	if params.Box == "sent" {
+		query = query.Where("point_gifts.sender_id = ?", params.UserID)
+	} else {
+		query = query.Where("point_gifts.recipient_id = ? AND point_gifts.deliver_at <= ?", params.UserID, now)
+	}
+	if params.Status != "" {
+		query = query.Where("point_gifts.status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.
+		Select("point_gifts.*, sender.full_name AS sender_name, recipient.full_name AS recipient_name").
+		Joins("LEFT JOIN users sender ON sender.id = point_gifts.sender_id").
+		Joins("LEFT JOIN users recipient ON recipient.id = point_gifts.recipient_id").
+		Order("point_gifts.created_at DESC").Order("point_gifts.id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&gifts).Error
+	return gifts, total, err
+}
+
+// findArrived returns the pending gifts delivered by now whose recipient was not told yet
+func (r *Repository) findArrived(ctx context.Context, now time.Time) ([]Gift, error) {
+	var gifts []Gift
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND notified_at IS NULL AND deliver_at <= ?", StatusPending, now).
+		Order("id ASC").
+		Find(&gifts).Error
+	return gifts, err
+}
+
+func (r *Repository) markNotified(ctx context.Context, id uint, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&Gift{}).Where("id = ?", id).Update("notified_at", now).Error
+}
+
+// findExpiredIDs returns the pending gifts nobody claimed by now
+func (r *Repository) findExpiredIDs(ctx context.Context, now time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&Gift{}).
+		Where("status = ? AND expires_at <= ?", StatusPending, now).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// fullName returns the name of a user, empty when there is none
+func (r *Repository) fullName(ctx context.Context, userID uint) (string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).Table("users").Where("id = ?", userID).Limit(1).Pluck("full_name", &names).Error
+	if err != nil || len(names) == 0 {
+		return "", err
+	}
+	return names[0], nil
+}
@@ -0,0 +1,312 @@
+package pointgift
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-point/internal/auth"
+	"wallet-point/internal/feature"
+	"wallet-point/internal/metrics"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/settings"
+	"wallet-point/internal/transfer"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// maxScheduleAhead is how far ahead a gift may be scheduled
+const maxScheduleAhead = 365 * 24 * time.Hour
+
+// Service sends points as gifts: held from the sender, claimed by the recipient
+// and returned when left unclaimed. Gifts move points between students like
+// transfers, so they share the transfer switch and limits.
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	walletService *wallet.WalletService
+	authService   *auth.AuthService
+	transfers     *transfer.Service
+	settings      *settings.Service
+	flags         *feature.FlagService
+	notifications *notification.NotificationService
+}
+
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService, authService *auth.AuthService, transferService *transfer.Service, settingsService *settings.Service) *Service {
+	return &Service{
+		repo:          repo,
+		db:            db,
+		walletService: walletService,
+		authService:   authService,
+		transfers:     transferService,
+		settings:      settingsService,
+	}
+}
+
+// SetFeatureFlags switches gifts off together with transfers
+func (s *Service) SetFeatureFlags(flags *feature.FlagService) {
+	s.flags = flags
+}
+
+// SetNotifications tells recipients a gift arrived and senders what became of it
+func (s *Service) SetNotifications(notifications *notification.NotificationService) {
+	s.notifications = notifications
+}
+
+// Send takes amount from the sender's wallet and holds it as a gift to the
+// recipient, claimable from deliver_at (now when not set)
+func (s *Service) Send(ctx context.Context, senderID uint, req SendGiftRequest) (_ *Gift, err error) {
+	defer func() { metrics.ObserveWalletOperation("point_gift", err) }()
+
+	if s.flags != nil && !s.flags.Enabled(feature.Transfers) {
+		return nil, feature.ErrFeatureDisabled
+	}
+	if err := s.authService.VerifyPIN(senderID, req.PIN); err != nil {
+		return nil, err
+	}
+	if req.RecipientUserID == senderID {
+		return nil, ErrGiftToSelf
+	}
+
+	now := time.Now()
+	deliverAt := now
+	if req.DeliverAt != nil {
+		// A minute of slack for a "now" picked on a slow clock
+		if req.DeliverAt.Before(now.Add(-time.Minute)) || req.DeliverAt.After(now.Add(maxScheduleAhead)) {
+			return nil, ErrInvalidDeliverDate
+		}
+		if req.DeliverAt.After(now) {
+			deliverAt = *req.DeliverAt
+		}
+	}
+
+	senderWallet, err := s.walletService.GetWalletByUserID(senderID)
+	if err != nil {
+		return nil, transfer.ErrSenderWalletNotFound
+	}
+	recipientWallet, err := s.walletService.GetWalletByUserID(req.RecipientUserID)
+	if err != nil {
+		return nil, ErrRecipientNotFound
+	}
+	if err := s.walletService.CheckWalletTenant(ctx, recipientWallet.ID); err != nil {
+		return nil, ErrRecipientNotFound
+	}
+	if senderWallet.Balance < req.Amount {
+		return nil, wallet.ErrInsufficientBalance
+	}
+	if limit := s.settings.Int(settings.TransferMaxAmount); limit > 0 && req.Amount > limit {
+		return nil, fmt.Errorf("%w (%d points)", transfer.ErrAmountOverLimit, limit)
+	}
+
+	gift := &Gift{
+		SenderID:       senderID,
+		SenderWalletID: senderWallet.ID,
+		RecipientID:    req.RecipientUserID,
+		Amount:         req.Amount,
+		Message:        req.Message,
+		Status:         StatusPending,
+		DeliverAt:      deliverAt,
+		ExpiresAt:      deliverAt.AddDate(0, 0, s.settings.Int(settings.PointGiftExpiryDays)),
+	}
+	err = utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		// The balance read above is unlocked; check it again on the locked
+		// row so two gifts sent at once cannot overdraw the wallet
+		locked, err := s.walletService.LockWallet(tx, senderWallet.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Balance < req.Amount {
+			return wallet.ErrInsufficientBalance
+		}
+		if err := s.transfers.CheckDailyLimit(tx, senderWallet.ID, req.Amount); err != nil {
+			return err
+		}
+		description := fmt.Sprintf("Points gift to user %d", req.RecipientUserID)
+		debitID, err := s.walletService.DebitWithTransactionID(tx, senderWallet.ID, req.Amount, "transfer_out", description)
+		if err != nil {
+			return err
+		}
+		gift.DebitID = debitID
+		return s.repo.Create(tx, gift)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !gift.DeliverAt.After(now) {
+		s.announce(ctx, gift, now)
+	}
+	return gift, nil
+}
+
+// Claim credits a delivered gift to its recipient
+func (s *Service) Claim(ctx context.Context, userID, id uint) (*Gift, error) {
+	var claimed *Gift
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		gift, err := s.repo.Lock(tx, id)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		switch {
+		case gift.RecipientID != userID:
+			return ErrGiftNotFound
+		case gift.Status != StatusPending || !now.Before(gift.ExpiresAt):
+			return ErrGiftClosed
+		case now.Before(gift.DeliverAt):
+			return ErrGiftNotDelivered
+		}
+
+		recipientWallet, err := s.walletService.GetWalletByUserID(userID)
+		if err != nil {
+			return ErrRecipientNotFound
+		}
+		description := fmt.Sprintf("Points gift from user %d", gift.SenderID)
+		if err := s.walletService.CreditWithTransaction(tx, recipientWallet.ID, gift.Amount, "transfer_in", description); err != nil {
+			return err
+		}
+		if err := s.repo.Update(tx, gift, map[string]interface{}{"status": StatusClaimed, "claimed_at": now}); err != nil {
+			return err
+		}
+		gift.Status = StatusClaimed
+		gift.ClaimedAt = &now
+		claimed = gift
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := s.repo.fullName(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "point gift: recipient name lookup failed", "gift_id", claimed.ID, "error", err)
+	}
+	s.notify(claimed.SenderID, "point_gift_claimed", "Hadiah poin diterima",
+		fmt.Sprintf("%s telah menerima hadiah %d poin dari Anda.", nameOr(name, "Penerima"), claimed.Amount))
+	return claimed, nil
+}
+
+// GetGifts lists the gifts a user received or sent
+func (s *Service) GetGifts(ctx context.Context, params GiftListParams) (*GiftListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	gifts, total, err := s.repo.FindAll(ctx, params, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if gifts == nil {
+		gifts = []GiftWithNames{}
+	}
+	return &GiftListResponse{
+		Gifts:      gifts,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// RunScheduled is the cron job of gifts: it tells recipients about scheduled
+// gifts whose delivery date came and returns unclaimed gifts to their senders
+func (s *Service) RunScheduled(ctx context.Context) (string, error) {
+	now := time.Now()
+	arrived, err := s.repo.findArrived(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	for i := range arrived {
+		s.announce(ctx, &arrived[i], now)
+	}
+
+	ids, err := s.repo.findExpiredIDs(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	returned := 0
+	for _, id := range ids {
+		ok, err := s.expire(ctx, id, now)
+		if err != nil {
+			// Stays pending, so the next run retries it
+			slog.ErrorContext(ctx, "point gift: returning unclaimed gift failed", "gift_id", id, "error", err)
+			continue
+		}
+		if ok {
+			returned++
+		}
+	}
+	return fmt.Sprintf("delivered %d gifts, returned %d of %d unclaimed gifts", len(arrived), returned, len(ids)), nil
+}
+
+// expire returns an unclaimed gift to its sender; false when it was claimed meanwhile
+func (s *Service) expire(ctx context.Context, id uint, now time.Time) (bool, error) {
+	var expired *Gift
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		gift, err := s.repo.Lock(tx, id)
+		if err != nil {
+			return err
+		}
+		if gift.Status != StatusPending {
+			return nil
+		}
+		description := fmt.Sprintf("Points gift to user %d returned unclaimed", gift.RecipientID)
+		if err := s.walletService.RefundWithTransaction(tx, gift.SenderWalletID, gift.DebitID, gift.Amount, "transfer_in", description); err != nil {
+			return err
+		}
+		if err := s.repo.Update(tx, gift, map[string]interface{}{"status": StatusExpired, "expired_at": now}); err != nil {
+			return err
+		}
+		expired = gift
+		return nil
+	})
+	if err != nil || expired == nil {
+		return false, err
+	}
+
+	s.notify(expired.SenderID, "point_gift_expired", "Hadiah poin dikembalikan",
+		fmt.Sprintf("Hadiah %d poin Anda tidak diklaim sebelum %s dan telah dikembalikan ke dompet Anda.", expired.Amount, expired.ExpiresAt.Format("02-01-2006")))
+	return true, nil
+}
+
+// announce sends the recipient the message card of a delivered gift
+func (s *Service) announce(ctx context.Context, gift *Gift, now time.Time) {
+	sender, err := s.repo.fullName(ctx, gift.SenderID)
+	if err != nil {
+		slog.WarnContext(ctx, "point gift: sender name lookup failed", "gift_id", gift.ID, "error", err)
+	}
+	message := fmt.Sprintf("%s mengirimi Anda %d poin. Klaim sebelum %s.", nameOr(sender, "Seseorang"), gift.Amount, gift.ExpiresAt.Format("02-01-2006"))
+	if gift.Message != "" {
+		message = fmt.Sprintf("%s mengirimi Anda %d poin: \"%s\" Klaim sebelum %s.", nameOr(sender, "Seseorang"), gift.Amount, gift.Message, gift.ExpiresAt.Format("02-01-2006"))
+	}
+	s.notify(gift.RecipientID, "point_gift_received", "Anda menerima hadiah poin", message)
+
+	if err := s.repo.markNotified(ctx, gift.ID, now); err != nil {
+		slog.ErrorContext(ctx, "point gift: marking gift delivered failed", "gift_id", gift.ID, "error", err)
+	}
+}
+
+func (s *Service) notify(userID uint, notificationType, title, message string) {
+	if s.notifications == nil {
+		return
+	}
+	err := s.notifications.Notify(notification.NotifyParams{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Message: message,
+		Link:    "/point-gifts",
+	})
+	if err != nil {
+		slog.Error("point gift: notify failed", "user_id", userID, "error", err)
+	}
+}
+
+func nameOr(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
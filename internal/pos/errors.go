@@ -0,0 +1,18 @@
+package pos
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrInvalidAPIKey       = utils.NewAppError("POS_INVALID_API_KEY", http.StatusUnauthorized, "POS API key is missing, invalid or revoked")
+	ErrTerminalNotFound    = utils.NewAppError("POS_TERMINAL_NOT_FOUND", http.StatusNotFound, "POS terminal not found")
+	ErrPayCodeInvalid      = utils.NewAppError("POS_PAY_CODE_INVALID", http.StatusBadRequest, "pay code is invalid, expired or already used")
+	ErrChargeTooLarge      = utils.NewAppError("POS_CHARGE_TOO_LARGE", http.StatusBadRequest, "amount exceeds the limit of this terminal")
+	ErrDailyLimitReached   = utils.NewAppError("POS_DAILY_LIMIT_REACHED", http.StatusBadRequest, "charge would exceed the student's daily canteen limit")
+	ErrReferenceReused     = utils.NewAppError("POS_REFERENCE_REUSED", http.StatusConflict, "reference was already used for a different charge")
+	ErrChargeNotFound      = utils.NewAppError("POS_CHARGE_NOT_FOUND", http.StatusNotFound, "POS charge not found")
+	ErrChargeAlreadyVoided = utils.NewAppError("POS_CHARGE_ALREADY_VOIDED", http.StatusConflict, "POS charge is already voided")
+	ErrVoidWindowPassed    = utils.NewAppError("POS_VOID_WINDOW_PASSED", http.StatusConflict, "POS charge can no longer be voided")
+)
@@ -0,0 +1,332 @@
+package pos
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key of a terminal
+const APIKeyHeader = "X-API-Key"
+
+// terminalKey is where RequireTerminal stores the authenticated terminal
+const terminalKey = "pos_terminal"
+
+type POSHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewPOSHandler(service *Service, auditService audit.Logger) *POSHandler {
+	return &POSHandler{service: service, auditService: auditService}
+}
+
+// RequireTerminal authenticates a till by its X-API-Key and scopes the request
+// to the terminal's campus
+func (h *POSHandler) RequireTerminal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		terminal, err := h.service.Authenticate(c.Request.Context(), c.GetHeader(APIKeyHeader))
+		if err != nil {
+			utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(terminalKey, terminal)
+		c.Set("tenant_id", terminal.TenantID)
+		c.Request = c.Request.WithContext(utils.WithTenant(c.Request.Context(), terminal.TenantID))
+		c.Next()
+	}
+}
+
+func terminalOf(c *gin.Context) *Terminal {
+	return c.MustGet(terminalKey).(*Terminal)
+}
+
+// Charge handles a till charging a student's scanned pay code
+// @Summary Charge student wallet
+// @Description Called by a canteen till (X-API-Key of a POS terminal) after scanning the student's pay code. The amount is limited per charge (POS_MAX_CHARGE or the terminal's lower max_charge) and per student per day (POS_DAILY_LIMIT). Retrying with the same reference returns the recorded charge with 200. The student gets a receipt notification.
+// @Tags POS
+// @Accept json
+// @Produce json
+// @Param X-API-Key header string true "Terminal API key"
+// @Param request body ChargeRequest true "Charge"
+// @Success 201 {object} utils.Response{data=ChargeReceipt}
+// @Success 200 {object} utils.Response{data=ChargeReceipt}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /pos/charges [post]
+func (h *POSHandler) Charge(c *gin.Context) {
+	var req ChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	receipt, replayed, err := h.service.Charge(c.Request.Context(), terminalOf(c), req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if replayed {
+		status = http.StatusOK
+	}
+	utils.SuccessResponse(c, status, "POS charge completed", receipt)
+}
+
+// VoidCharge handles a till voiding a mistaken charge
+// @Summary Void POS charge
+// @Description Refund a charge of this terminal within POS_VOID_MINUTES of making it
+// @Tags POS
+// @Accept json
+// @Produce json
+// @Param X-API-Key header string true "Terminal API key"
+// @Param reference path string true "Charge reference"
+// @Param request body VoidChargeRequest true "Reason"
+// @Success 200 {object} utils.Response{data=Charge}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /pos/charges/{reference}/void [post]
+func (h *POSHandler) VoidCharge(c *gin.Context) {
+	var req VoidChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	charge, err := h.service.Void(c.Request.Context(), terminalOf(c), c.Param("reference"), req.Reason)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "POS charge voided", charge)
+}
+
+// GetTerminalCharges handles a till listing its own charges
+// @Summary List terminal charges
+// @Tags POS
+// @Produce json
+// @Param X-API-Key header string true "Terminal API key"
+// @Param status query string false "Filter by status" Enums(completed, voided)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ChargeListResponse}
+// @Router /pos/charges [get]
+func (h *POSHandler) GetTerminalCharges(c *gin.Context) {
+	h.listCharges(c, terminalOf(c).ID)
+}
+
+// IssuePayCode handles a student requesting a code to pay at the canteen
+// @Summary Get canteen pay code
+// @Description A single-use code (and its QR image) the till scans to charge the wallet; it expires after POS_PAY_CODE_SECONDS
+// @Tags POS
+// @Security BearerAuth
+// @Produce json
+// @Success 201 {object} utils.Response{data=PayCodeResponse}
+// @Router /mahasiswa/pos/pay-code [post]
+func (h *POSHandler) IssuePayCode(c *gin.Context) {
+	code, err := h.service.IssuePayCode(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to issue pay code", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Pay code issued", code)
+}
+
+// GetTerminals handles listing the POS terminals (Admin)
+// @Summary List POS terminals
+// @Tags Admin - POS
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Terminal}
+// @Router /admin/pos/terminals [get]
+func (h *POSHandler) GetTerminals(c *gin.Context) {
+	terminals, err := h.service.GetTerminals(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve POS terminals", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "POS terminals retrieved successfully", terminals)
+}
+
+// CreateTerminal handles registering a canteen till (Admin)
+// @Summary Create POS terminal
+// @Description The response holds the terminal's API key; it is not shown again
+// @Tags Admin - POS
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateTerminalRequest true "Terminal"
+// @Success 201 {object} utils.Response{data=TerminalWithKey}
+// @Failure 400 {object} utils.Response
+// @Router /admin/pos/terminals [post]
+func (h *POSHandler) CreateTerminal(c *gin.Context) {
+	var req CreateTerminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	terminal, err := h.service.CreateTerminal(c.Request.Context(), req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "POS terminal created", terminal)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_POS_TERMINAL",
+		Entity:    "POS_TERMINAL",
+		EntityID:  terminal.ID,
+		Details:   fmt.Sprintf("Registered POS terminal %s (key %s...)", terminal.Name, terminal.KeyPrefix),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateTerminal handles changing a POS terminal (Admin)
+// @Summary Update POS terminal
+// @Description Rename it, change its charge limit, or deactivate it (active=false) to refuse its key
+// @Tags Admin - POS
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Terminal ID"
+// @Param request body UpdateTerminalRequest true "Fields to change"
+// @Success 200 {object} utils.Response{data=Terminal}
+// @Failure 404 {object} utils.Response
+// @Router /admin/pos/terminals/{id} [put]
+func (h *POSHandler) UpdateTerminal(c *gin.Context) {
+	terminalID, ok := terminalIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateTerminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	terminal, err := h.service.UpdateTerminal(c.Request.Context(), terminalID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "POS terminal updated", terminal)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_POS_TERMINAL",
+		Entity:    "POS_TERMINAL",
+		EntityID:  terminal.ID,
+		Details:   fmt.Sprintf("Updated POS terminal %s: max_charge=%d, active=%t", terminal.Name, terminal.MaxCharge, terminal.Active),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// RotateKey handles replacing a POS terminal's API key (Admin)
+// @Summary Rotate POS terminal key
+// @Description The old key stops working immediately; the response holds the new one
+// @Tags Admin - POS
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Terminal ID"
+// @Success 200 {object} utils.Response{data=TerminalWithKey}
+// @Failure 404 {object} utils.Response
+// @Router /admin/pos/terminals/{id}/rotate-key [post]
+func (h *POSHandler) RotateKey(c *gin.Context) {
+	terminalID, ok := terminalIDParam(c)
+	if !ok {
+		return
+	}
+
+	terminal, err := h.service.RotateKey(c.Request.Context(), terminalID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "POS terminal key rotated", terminal)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "ROTATE_POS_TERMINAL_KEY",
+		Entity:    "POS_TERMINAL",
+		EntityID:  terminal.ID,
+		Details:   fmt.Sprintf("Rotated the API key of POS terminal %s (now %s...)", terminal.Name, terminal.KeyPrefix),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetCharges handles listing POS charges (Admin)
+// @Summary List POS charges
+// @Tags Admin - POS
+// @Security BearerAuth
+// @Produce json
+// @Param terminal_id query int false "Filter by terminal"
+// @Param status query string false "Filter by status" Enums(completed, voided)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ChargeListResponse}
+// @Router /admin/pos/charges [get]
+func (h *POSHandler) GetCharges(c *gin.Context) {
+	var terminalID uint
+	if value := c.Query("terminal_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid POS terminal ID", nil)
+			return
+		}
+		terminalID = uint(id)
+	}
+	h.listCharges(c, terminalID)
+}
+
+func (h *POSHandler) listCharges(c *gin.Context, terminalID uint) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "pos_charge_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetCharges(c.Request.Context(), ChargeListParams{
+		TerminalID: terminalID,
+		Status:     status,
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve POS charges", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "POS charges retrieved successfully", "charges", response.Charges, response.Pagination, nil)
+}
+
+func terminalIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid POS terminal ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
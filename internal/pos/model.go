@@ -0,0 +1,123 @@
+package pos
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	ChargeCompleted = "completed"
+	ChargeVoided    = "voided"
+)
+
+// Terminal is a canteen till allowed to charge student wallets. It authenticates
+// with an API key of which only the SHA-256 hash is stored.
+type Terminal struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	TenantID   uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	KeyPrefix  string     `json:"key_prefix" gorm:"size:20;not null"` // shown to tell keys apart
+	KeyHash    string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	MaxCharge  int        `json:"max_charge" gorm:"default:0;not null"` // 0 uses POS_MAX_CHARGE
+	Active     bool       `json:"active" gorm:"default:true;not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedBy  uint       `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (Terminal) TableName() string {
+	return "pos_terminals"
+}
+
+// PayCode is the single-use code a student shows as a QR code at the till
+type PayCode struct {
+	ID        uint       `json:"-" gorm:"primaryKey"`
+	Code      string     `json:"code" gorm:"size:64;uniqueIndex;not null"`
+	UserID    uint       `json:"-" gorm:"not null;index"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+}
+
+func (PayCode) TableName() string {
+	return "pos_pay_codes"
+}
+
+// Charge is a sale a terminal charged to a student's wallet. (TerminalID,
+// Reference) is unique so a till retrying a charge gets the recorded one back.
+type Charge struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	TenantID     uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	TerminalID   uint       `json:"terminal_id" gorm:"not null;uniqueIndex:idx_pos_charges_ref,priority:1"`
+	Reference    string     `json:"reference" gorm:"size:100;not null;uniqueIndex:idx_pos_charges_ref,priority:2"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	WalletID     uint       `json:"wallet_id" gorm:"not null"`
+	Amount       int        `json:"amount" gorm:"not null"`
+	Description  string     `json:"description" gorm:"size:255"`
+	BalanceAfter int        `json:"balance_after" gorm:"not null"`
+	DebitID      uint       `json:"-" gorm:"not null;default:0"` // the wallet debit a void refunds
+	Status       string     `json:"status" gorm:"type:enum('completed','voided');default:'completed';not null;index"`
+	VoidReason   string     `json:"void_reason,omitempty" gorm:"size:255"`
+	VoidedAt     *time.Time `json:"voided_at"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"index"`
+}
+
+func (Charge) TableName() string {
+	return "pos_charges"
+}
+
+type CreateTerminalRequest struct {
+	Name      string `json:"name" binding:"required,max=100" example:"Kantin Teknik - Kasir 1"`
+	MaxCharge int    `json:"max_charge" binding:"min=0"`
+}
+
+type UpdateTerminalRequest struct {
+	Name      *string `json:"name" binding:"omitempty,max=100"`
+	MaxCharge *int    `json:"max_charge" binding:"omitempty,min=0"`
+	Active    *bool   `json:"active"`
+}
+
+// TerminalWithKey is returned when a terminal is created or its key rotated, the
+// only times the API key is shown
+type TerminalWithKey struct {
+	Terminal
+	APIKey string `json:"api_key"`
+}
+
+// PayCodeResponse is the code a student shows at the till
+type PayCodeResponse struct {
+	Code         string    `json:"code"`
+	QRCodeBase64 string    `json:"qr_code_base64"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type ChargeRequest struct {
+	Code        string `json:"code" binding:"required,max=64"`
+	Amount      int    `json:"amount" binding:"required,points"`
+	Reference   string `json:"reference" binding:"required,max=100" example:"INV-0001234"`
+	Description string `json:"description" binding:"max=255" example:"Nasi goreng + es teh"`
+}
+
+type VoidChargeRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// ChargeReceipt is what the till prints
+type ChargeReceipt struct {
+	Charge
+	StudentName string `json:"student_name"`
+	StudentNIM  string `json:"student_nim"`
+}
+
+type ChargeListParams struct {
+	TerminalID uint
+	Status     string
+	Page       int
+	Limit      int
+}
+
+type ChargeListResponse struct {
+	Charges []Charge `json:"charges"`
+	utils.Pagination
+}
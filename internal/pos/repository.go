@@ -0,0 +1,174 @@
+package pos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindActiveTerminalByKey returns the active terminal whose key hashes to keyHash,
+// across campuses (the request has no campus until the terminal is known)
+func (r *Repository) FindActiveTerminalByKey(ctx context.Context, keyHash string) (*Terminal, error) {
+	var terminal Terminal
+	err := r.db.WithContext(ctx).Where("key_hash = ? AND active = ?", keyHash, true).Take(&terminal).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	return &terminal, err
+}
+
+func (r *Repository) TouchTerminal(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&Terminal{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
+
+func (r *Repository) CreateTerminal(ctx context.Context, terminal *Terminal) error {
+	return r.db.WithContext(ctx).Create(terminal).Error
+}
+
+func (r *Repository) FindTerminals(ctx context.Context) ([]Terminal, error) {
+	var terminals []Terminal
+	err := r.db.WithContext(ctx).Order("name").Find(&terminals).Error
+	return terminals, err
+}
+
+func (r *Repository) FindTerminalByID(ctx context.Context, id uint) (*Terminal, error) {
+	var terminal Terminal
+	if err := r.db.WithContext(ctx).First(&terminal, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTerminalNotFound
+		}
+		return nil, err
+	}
+	return &terminal, nil
+}
+
+func (r *Repository) UpdateTerminal(ctx context.Context, terminal *Terminal, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(terminal).Updates(updates).Error
+}
+
+func (r *Repository) CreatePayCode(ctx context.Context, code *PayCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+// consumePayCode marks an unexpired, unused code used and returns its student
+func (r *Repository) consumePayCode(tx *gorm.DB, code string, now time.Time) (uint, error) {
+	result := tx.Model(&PayCode{}).
+		Where("code = ? AND used_at IS NULL AND expires_at > ?", code, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrPayCodeInvalid
+	}
+
+	var payCode PayCode
+	if err := tx.Select("user_id").Where("code = ?", code).Take(&payCode).Error; err != nil {
+		return 0, err
+	}
+	return payCode.UserID, nil
+}
+
+// DeleteExpiredPayCodes removes codes that expired before cutoff
+func (r *Repository) DeleteExpiredPayCodes(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", cutoff).Delete(&PayCode{})
+	return result.RowsAffected, result.Error
+}
+
+// student is the wallet owner a charge is made to
+type student struct {
+	UserID   uint
+	FullName string
+	NimNip   string
+	WalletID uint
+	Balance  int
+}
+
+// lockStudent loads an active student of the campus and locks their wallet, so
+// charges of one student are checked against the daily limit one at a time
+func (r *Repository) lockStudent(tx *gorm.DB, userID, tenantID uint) (*student, error) {
+	var found student
+	err := tx.Table("users").
+		Select("users.id AS user_id, users.full_name, users.nim_nip, wallets.id AS wallet_id, wallets.balance").
+		Joins("INNER JOIN wallets ON wallets.user_id = users.id").
+		Where("users.id = ? AND users.tenant_id = ? AND users.role = ? AND users.status = ? AND users.deleted_at IS NULL", userID, tenantID, "mahasiswa", "active").
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "wallets"}}).
+		Take(&found).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPayCodeInvalid
+	}
+	return &found, err
+}
+
+// findStudentName returns the name and NIM of a charged student, for a receipt
+func (r *Repository) findStudentName(ctx context.Context, userID uint) (*student, error) {
+	var found student
+	err := r.db.WithContext(ctx).Table("users").Select("id AS user_id, full_name, nim_nip").Where("id = ?", userID).Take(&found).Error
+	return &found, err
+}
+
+// spentSince sums the completed charges of a student since the given time
+func (r *Repository) spentSince(tx *gorm.DB, userID uint, since time.Time) (int, error) {
+	var total int
+	err := tx.Model(&Charge{}).
+		Where("user_id = ? AND status = ? AND created_at >= ?", userID, ChargeCompleted, since).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
+
+// FindChargeByReference returns the charge a terminal made with reference, or nil
+func (r *Repository) FindChargeByReference(ctx context.Context, terminalID uint, reference string) (*Charge, error) {
+	var charge Charge
+	err := r.db.WithContext(ctx).Where("terminal_id = ? AND reference = ?", terminalID, reference).Take(&charge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &charge, err
+}
+
+// lockCharge loads a terminal's charge for update within tx
+func (r *Repository) lockCharge(tx *gorm.DB, terminalID uint, reference string) (*Charge, error) {
+	var charge Charge
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("terminal_id = ? AND reference = ?", terminalID, reference).
+		Take(&charge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrChargeNotFound
+	}
+	return &charge, err
+}
+
+// FindCharges lists charges newest first
+func (r *Repository) FindCharges(ctx context.Context, params ChargeListParams) ([]Charge, int64, error) {
+	var charges []Charge
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Charge{})
+	if params.TerminalID != 0 {
+		query = query.Where("terminal_id = ?", params.TerminalID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("created_at DESC").Order("id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&charges).Error
+	return charges, total, err
+}
@@ -0,0 +1,343 @@
+package pos
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+// Config holds the limits of canteen charges
+type Config struct {
+	MaxCharge  int           // largest single charge, unless a terminal sets a lower one
+	DailyLimit int           // most a student can be charged per day; 0 disables it
+	VoidWindow time.Duration // how long after a charge the till may void it
+	PayCodeTTL time.Duration // how long a student's pay code is valid
+}
+
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	walletService *wallet.WalletService
+	notifications *notification.NotificationService
+	config        Config
+}
+
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService, config Config) *Service {
+	return &Service{repo: repo, db: db, walletService: walletService, config: config}
+}
+
+// SetNotifications sends students a receipt for every charge and void
+func (s *Service) SetNotifications(notifications *notification.NotificationService) {
+	s.notifications = notifications
+}
+
+// Authenticate returns the active terminal of an API key and records its use
+func (s *Service) Authenticate(ctx context.Context, apiKey string) (*Terminal, error) {
+	if apiKey == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	terminal, err := s.repo.FindActiveTerminalByKey(ctx, hashKey(apiKey))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.TouchTerminal(ctx, terminal.ID, time.Now()); err != nil {
+		slog.WarnContext(ctx, "pos: record terminal use failed", "terminal_id", terminal.ID, "error", err)
+	}
+	return terminal, nil
+}
+
+func (s *Service) GetTerminals(ctx context.Context) ([]Terminal, error) {
+	return s.repo.FindTerminals(ctx)
+}
+
+// CreateTerminal registers a till; the API key is only returned here and by RotateKey
+func (s *Service) CreateTerminal(ctx context.Context, req CreateTerminalRequest, adminID uint) (*TerminalWithKey, error) {
+	apiKey := newAPIKey()
+	terminal := &Terminal{
+		Name:      req.Name,
+		KeyPrefix: apiKey[:12],
+		KeyHash:   hashKey(apiKey),
+		MaxCharge: req.MaxCharge,
+		Active:    true,
+		CreatedBy: adminID,
+	}
+	if err := s.repo.CreateTerminal(ctx, terminal); err != nil {
+		return nil, err
+	}
+	return &TerminalWithKey{Terminal: *terminal, APIKey: apiKey}, nil
+}
+
+func (s *Service) UpdateTerminal(ctx context.Context, id uint, req UpdateTerminalRequest) (*Terminal, error) {
+	terminal, err := s.repo.FindTerminalByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.MaxCharge != nil {
+		updates["max_charge"] = *req.MaxCharge
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) > 0 {
+		if err := s.repo.UpdateTerminal(ctx, terminal, updates); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindTerminalByID(ctx, id)
+}
+
+// RotateKey replaces a terminal's API key; the old key stops working at once
+func (s *Service) RotateKey(ctx context.Context, id uint) (*TerminalWithKey, error) {
+	terminal, err := s.repo.FindTerminalByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := newAPIKey()
+	terminal.KeyPrefix = apiKey[:12]
+	terminal.KeyHash = hashKey(apiKey)
+	if err := s.repo.UpdateTerminal(ctx, terminal, map[string]interface{}{"key_prefix": terminal.KeyPrefix, "key_hash": terminal.KeyHash}); err != nil {
+		return nil, err
+	}
+	return &TerminalWithKey{Terminal: *terminal, APIKey: apiKey}, nil
+}
+
+// IssuePayCode gives a student a single-use code to show at the till
+func (s *Service) IssuePayCode(ctx context.Context, userID uint) (*PayCodeResponse, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	code := &PayCode{
+		Code:      "WPP:" + hex.EncodeToString(b),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.config.PayCodeTTL),
+	}
+
+	qrCode, err := qrcode.Encode(code.Code, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreatePayCode(ctx, code); err != nil {
+		return nil, err
+	}
+	return &PayCodeResponse{
+		Code:         code.Code,
+		QRCodeBase64: base64.StdEncoding.EncodeToString(qrCode),
+		ExpiresAt:    code.ExpiresAt,
+	}, nil
+}
+
+// DailyAllowance returns what a student may still be charged at the canteen
+// today, or nil when POS_DAILY_LIMIT is off
+func (s *Service) DailyAllowance(ctx context.Context, userID uint) (*utils.Allowance, error) {
+	if s.config.DailyLimit <= 0 {
+		return nil, nil
+	}
+	start, end := utils.Today()
+	spent, err := s.repo.spentSince(s.db.WithContext(ctx), userID, start)
+	if err != nil {
+		return nil, err
+	}
+	return utils.NewAllowance(s.config.DailyLimit, spent, &end), nil
+}
+
+// Charge debits the student of a scanned pay code. A reference the terminal used
+// before returns the recorded charge with replayed set, or ErrReferenceReused
+// when the amount differs.
+func (s *Service) Charge(ctx context.Context, terminal *Terminal, req ChargeRequest) (receipt *ChargeReceipt, replayed bool, err error) {
+	maxCharge := s.config.MaxCharge
+	if terminal.MaxCharge > 0 && terminal.MaxCharge < maxCharge {
+		maxCharge = terminal.MaxCharge
+	}
+	if req.Amount > maxCharge {
+		return nil, false, ErrChargeTooLarge
+	}
+
+	existing, err := s.repo.FindChargeByReference(ctx, terminal.ID, req.Reference)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		if existing.Amount != req.Amount {
+			return nil, false, ErrReferenceReused
+		}
+		student, err := s.repo.findStudentName(ctx, existing.UserID)
+		if err != nil {
+			return nil, false, err
+		}
+		return &ChargeReceipt{Charge: *existing, StudentName: student.FullName, StudentNIM: student.NimNip}, true, nil
+	}
+
+	var student *student
+	charge := &Charge{
+		TenantID:    terminal.TenantID,
+		TerminalID:  terminal.ID,
+		Reference:   req.Reference,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Status:      ChargeCompleted,
+	}
+	err = utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		now := time.Now()
+		userID, err := s.repo.consumePayCode(tx, strings.TrimSpace(req.Code), now)
+		if err != nil {
+			return err
+		}
+		student, err = s.repo.lockStudent(tx, userID, terminal.TenantID)
+		if err != nil {
+			return err
+		}
+
+		if s.config.DailyLimit > 0 {
+			year, month, day := now.Date()
+			spent, err := s.repo.spentSince(tx, student.UserID, time.Date(year, month, day, 0, 0, 0, 0, now.Location()))
+			if err != nil {
+				return err
+			}
+			if spent+req.Amount > s.config.DailyLimit {
+				return ErrDailyLimitReached
+			}
+		}
+
+		// Canteen sales are spending like marketplace purchases
+		charge.DebitID, err = s.walletService.DebitWithTransactionID(tx, student.WalletID, req.Amount, "marketplace", charge.walletDescription(terminal))
+		if err != nil {
+			return err
+		}
+
+		charge.UserID = student.UserID
+		charge.WalletID = student.WalletID
+		charge.BalanceAfter = student.Balance - req.Amount
+		return tx.Create(charge).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.notify(charge, terminal, "pos_receipt", "Pembayaran kantin",
+		fmt.Sprintf("%d poin dibayarkan di %s (%s). Sisa saldo %d poin.", charge.Amount, terminal.Name, charge.Reference, charge.BalanceAfter))
+	return &ChargeReceipt{Charge: *charge, StudentName: student.FullName, StudentNIM: student.NimNip}, false, nil
+}
+
+// Void refunds a charge the terminal made within the void window
+func (s *Service) Void(ctx context.Context, terminal *Terminal, reference, reason string) (*Charge, error) {
+	var charge *Charge
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		var err error
+		charge, err = s.repo.lockCharge(tx, terminal.ID, reference)
+		if err != nil {
+			return err
+		}
+		if charge.Status == ChargeVoided {
+			return ErrChargeAlreadyVoided
+		}
+		now := time.Now()
+		if now.Sub(charge.CreatedAt) > s.config.VoidWindow {
+			return ErrVoidWindowPassed
+		}
+
+		// A void reverses the marketplace debit of the sale
+		description := fmt.Sprintf("Pembatalan kantin %s: %s", charge.Reference, reason)
+		if err := s.walletService.RefundWithTransaction(tx, charge.WalletID, charge.DebitID, charge.Amount, "marketplace", description); err != nil {
+			return err
+		}
+
+		charge.Status = ChargeVoided
+		charge.VoidReason = reason
+		charge.VoidedAt = &now
+		return tx.Model(charge).Updates(map[string]interface{}{
+			"status":      charge.Status,
+			"void_reason": reason,
+			"voided_at":   now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(charge, terminal, "pos_void", "Pembayaran kantin dibatalkan",
+		fmt.Sprintf("Pembayaran %d poin di %s (%s) dibatalkan dan poin dikembalikan: %s", charge.Amount, terminal.Name, charge.Reference, reason))
+	return charge, nil
+}
+
+// GetCharges lists charges; a TerminalID limits them to one till
+func (s *Service) GetCharges(ctx context.Context, params ChargeListParams) (*ChargeListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	charges, total, err := s.repo.FindCharges(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &ChargeListResponse{
+		Charges:    charges,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// CleanupPayCodes deletes pay codes that expired over a day ago, for the scheduler
+func (s *Service) CleanupPayCodes(ctx context.Context) (string, error) {
+	deleted, err := s.repo.DeleteExpiredPayCodes(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("deleted %d expired pay codes", deleted), nil
+}
+
+func (s *Service) notify(charge *Charge, terminal *Terminal, notificationType, title, message string) {
+	if s.notifications == nil {
+		return
+	}
+
+	err := s.notifications.Notify(notification.NotifyParams{
+		UserID:  charge.UserID,
+		Type:    notificationType,
+		Title:   title,
+		Message: message,
+		Link:    "/transactions",
+	})
+	if err != nil {
+		slog.Error("pos: notify student failed", "terminal_id", terminal.ID, "reference", charge.Reference, "error", err)
+	}
+}
+
+func (c *Charge) walletDescription(terminal *Terminal) string {
+	if c.Description == "" {
+		return fmt.Sprintf("Kantin %s (%s)", terminal.Name, c.Reference)
+	}
+	return fmt.Sprintf("Kantin %s (%s): %s", terminal.Name, c.Reference, c.Description)
+}
+
+// newAPIKey returns a random terminal key, e.g. pos_3f9a...
+func newAPIKey() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return "pos_" + hex.EncodeToString(b)
+}
+
+func hashKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
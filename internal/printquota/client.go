@@ -0,0 +1,94 @@
+package printquota
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"wallet-point/internal/resilience"
+)
+
+// Client adds pages to a student's quota on the campus print server: a POST of
+// {"reference", "student_nim", "pages"} to <base URL>/quota/topups with a bearer
+// token, answered by {"id": "..."}. The server must treat a repeated reference
+// as the same top-up.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	policy  resilience.Policy
+}
+
+func NewClient(baseURL, token string, policy resilience.Policy) *Client {
+	if policy.Retryable == nil {
+		policy.Retryable = retryablePrintError
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), token: token, client: &http.Client{}, policy: policy}
+}
+
+// printStatusError is a non-2xx reply of the print server
+type printStatusError struct {
+	status int
+	body   string
+}
+
+func (e *printStatusError) Error() string {
+	return fmt.Sprintf("print server returned status %d: %s", e.status, e.body)
+}
+
+// retryablePrintError retries everything except a 4xx other than 429, which
+// means the request itself was rejected
+func retryablePrintError(err error) bool {
+	statusErr, ok := err.(*printStatusError)
+	if !ok {
+		return true
+	}
+	return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+}
+
+type topUpRequest struct {
+	Reference  string `json:"reference"`
+	StudentNIM string `json:"student_nim"`
+	Pages      int    `json:"pages"`
+}
+
+type topUpResponse struct {
+	ID string `json:"id"`
+}
+
+// TopUp adds pages to the quota of the student and returns the server's ID of the top-up
+func (c *Client) TopUp(ctx context.Context, reference, studentNIM string, pages int) (string, error) {
+	body, err := json.Marshal(topUpRequest{Reference: reference, StudentNIM: studentNIM, Pages: pages})
+	if err != nil {
+		return "", err
+	}
+
+	var result topUpResponse
+	err = c.policy.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/quota/topups", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+c.token)
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return &printStatusError{status: resp.StatusCode, body: string(body)}
+		}
+		result = topUpResponse{}
+		// The ID is informational; a 2xx without one still counts
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&result)
+		return nil
+	})
+	return result.ID, err
+}
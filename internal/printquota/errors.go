@@ -0,0 +1,11 @@
+package printquota
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrTopUpNotFound = utils.NewAppError("PRINT_QUOTA_TOPUP_NOT_FOUND", http.StatusNotFound, "print quota top-up not found")
+	ErrNotConfigured = utils.NewAppError("PRINT_SERVER_NOT_CONFIGURED", http.StatusServiceUnavailable, "print server is not configured")
+)
@@ -0,0 +1,70 @@
+package printquota
+
+import (
+	"net/http"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrintQuotaHandler struct {
+	service *Service
+}
+
+func NewPrintQuotaHandler(service *Service) *PrintQuotaHandler {
+	return &PrintQuotaHandler{service: service}
+}
+
+// GetMyTopUps handles listing the student's print quota purchases
+// @Summary My print quota top-ups
+// @Description Pages bought through print quota products and whether the print server has added them (completed) or the points were refunded
+// @Tags Print Quota
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, completed, refunded)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=TopUpListResponse}
+// @Router /mahasiswa/print-quota/topups [get]
+func (h *PrintQuotaHandler) GetMyTopUps(c *gin.Context) {
+	h.list(c, c.GetUint("user_id"))
+}
+
+// GetAll handles listing all print quota top-ups (Admin)
+// @Summary List print quota top-ups
+// @Description Deliveries to the print server; failed attempts show the last error, and top-ups the server never accepted are refunded automatically
+// @Tags Admin - Print Quota
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, completed, refunded)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=TopUpListResponse}
+// @Router /admin/print-quota/topups [get]
+func (h *PrintQuotaHandler) GetAll(c *gin.Context) {
+	h.list(c, 0)
+}
+
+func (h *PrintQuotaHandler) list(c *gin.Context, userID uint) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "print_quota_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetTopUps(c.Request.Context(), TopUpListParams{
+		UserID: userID,
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve print quota top-ups", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Print quota top-ups retrieved successfully", "topups", response.TopUps, response.Pagination, nil)
+}
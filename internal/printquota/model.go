@@ -0,0 +1,52 @@
+package printquota
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusRefunded  = "refunded"
+)
+
+// TopUp is the delivery of a print quota purchase to the print server. It is
+// retried until the server accepts it; when it never does the points are
+// refunded. Reference lets the print server ignore repeated calls.
+type TopUp struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TenantID    uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	Reference   string     `json:"reference" gorm:"size:50;uniqueIndex;not null"`
+	OrderID     uint       `json:"order_id" gorm:"not null;index"` // marketplace transaction
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	WalletID    uint       `json:"wallet_id" gorm:"not null"`
+	ProductID   uint       `json:"product_id" gorm:"not null"`
+	Quantity    int        `json:"quantity" gorm:"not null"`
+	Pages       int        `json:"pages" gorm:"not null"`
+	Points      int        `json:"points" gorm:"not null"`
+	Status      string     `json:"status" gorm:"type:enum('pending','completed','refunded');default:'pending';not null;index"`
+	Attempts    int        `json:"attempts" gorm:"default:0;not null"`
+	Error       string     `json:"error,omitempty" gorm:"size:255"`
+	RemoteRef   string     `json:"remote_ref,omitempty" gorm:"size:100"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	RefundedAt  *time.Time `json:"refunded_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (TopUp) TableName() string {
+	return "print_quota_topups"
+}
+
+type TopUpListParams struct {
+	UserID uint
+	Status string
+	Page   int
+	Limit  int
+}
+
+type TopUpListResponse struct {
+	TopUps []TopUp `json:"topups"`
+	utils.Pagination
+}
@@ -0,0 +1,75 @@
+package printquota
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) FindByID(ctx context.Context, id uint) (*TopUp, error) {
+	var topUp TopUp
+	if err := r.db.WithContext(ctx).First(&topUp, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTopUpNotFound
+		}
+		return nil, err
+	}
+	return &topUp, nil
+}
+
+// Lock loads a top-up for update within tx, so a delivery and a refund cannot overlap
+func (r *Repository) Lock(tx *gorm.DB, id uint) (*TopUp, error) {
+	var topUp TopUp
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&topUp, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTopUpNotFound
+		}
+		return nil, err
+	}
+	return &topUp, nil
+}
+
+func (r *Repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&TopUp{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// findNIM returns the NIM the print server knows the student by
+func (r *Repository) findNIM(ctx context.Context, userID uint) (string, error) {
+	var nim string
+	err := r.db.WithContext(ctx).Table("users").Where("id = ?", userID).Select("nim_nip").Scan(&nim).Error
+	return nim, err
+}
+
+// FindAll lists top-ups newest first
+func (r *Repository) FindAll(ctx context.Context, params TopUpListParams) ([]TopUp, int64, error) {
+	var topUps []TopUp
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&TopUp{})
+	if params.UserID != 0 {
+		query = query.Where("user_id = ?", params.UserID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("created_at DESC").Order("id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&topUps).Error
+	return topUps, total, err
+}
@@ -0,0 +1,226 @@
+package printquota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// JobDeliver is the job type sending one top-up to the print server; the job
+// queue's backoff paces the retries
+const JobDeliver = "print_quota.deliver"
+
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	walletService *wallet.WalletService
+	marketplace   *marketplace.MarketplaceService
+	notifications *notification.NotificationService
+	client        *Client
+	queue         *jobs.Queue
+	maxAttempts   int
+}
+
+type deliverJob struct {
+	TopUpID uint `json:"topup_id"`
+}
+
+// NewService tries each delivery up to maxAttempts times before refunding it
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService, marketplaceService *marketplace.MarketplaceService, queue *jobs.Queue, maxAttempts int) *Service {
+	s := &Service{
+		repo:          repo,
+		db:            db,
+		walletService: walletService,
+		marketplace:   marketplaceService,
+		queue:         queue,
+		maxAttempts:   maxAttempts,
+	}
+	queue.Register(JobDeliver, jobs.QueueDefault, s.deliver)
+	return s
+}
+
+// SetClient connects the print server and makes print quota products sellable
+func (s *Service) SetClient(client *Client) {
+	s.client = client
+	s.marketplace.RegisterFulfiller(marketplace.ProductPrintQuota, s)
+}
+
+// SetNotifications tells students when their pages are added or refunded
+func (s *Service) SetNotifications(notifications *notification.NotificationService) {
+	s.notifications = notifications
+}
+
+// Fulfill records the top-up of a print quota order and queues its delivery in
+// the purchase transaction
+func (s *Service) Fulfill(tx *gorm.DB, userID uint, product *marketplace.Product, order *marketplace.MarketplaceTransaction) error {
+	topUp := &TopUp{
+		TenantID:  product.TenantID,
+		Reference: fmt.Sprintf("PQ-%d", order.ID),
+		OrderID:   order.ID,
+		UserID:    userID,
+		WalletID:  order.WalletID,
+		ProductID: product.ID,
+		Quantity:  order.Quantity,
+		Pages:     product.PrintPages * order.Quantity,
+		Points:    order.TotalAmount,
+		Status:    StatusPending,
+	}
+	if err := tx.Create(topUp).Error; err != nil {
+		return err
+	}
+	_, err := s.queue.EnqueueTx(tx, JobDeliver, deliverJob{TopUpID: topUp.ID}, jobs.EnqueueOptions{MaxAttempts: s.maxAttempts})
+	return err
+}
+
+// deliver is the JobDeliver handler: it sends the top-up to the print server and
+// refunds it once the server rejects it or the last attempt has failed
+func (s *Service) deliver(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	var payload deliverJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	topUp, err := s.repo.FindByID(ctx, payload.TopUpID)
+	if err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	if topUp.Status != StatusPending {
+		return nil, nil
+	}
+
+	nim, err := s.repo.findNIM(ctx, topUp.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var remoteRef string
+	var sendErr error = ErrNotConfigured
+	if s.client != nil {
+		remoteRef, sendErr = s.client.TopUp(ctx, topUp.Reference, nim, topUp.Pages)
+	}
+	if sendErr == nil {
+		now := time.Now()
+		err := s.repo.Update(ctx, topUp.ID, map[string]interface{}{
+			"status":       StatusCompleted,
+			"attempts":     topUp.Attempts + 1,
+			"error":        "",
+			"remote_ref":   remoteRef,
+			"delivered_at": now,
+		})
+		if err != nil {
+			// The print server has the pages; a retry is a no-op for it thanks to the reference
+			return nil, err
+		}
+		s.notify(topUp, "print_quota_added", "Kuota cetak ditambahkan",
+			fmt.Sprintf("%d halaman sudah ditambahkan ke kuota cetak Anda (%s).", topUp.Pages, topUp.Reference))
+		return map[string]interface{}{"reference": topUp.Reference, "remote_ref": remoteRef}, nil
+	}
+
+	message := sendErr.Error()
+	if len(message) > 255 {
+		message = message[:255]
+	}
+	if err := s.repo.Update(ctx, topUp.ID, map[string]interface{}{"attempts": topUp.Attempts + 1, "error": message}); err != nil {
+		slog.ErrorContext(ctx, "print quota: record attempt failed", "reference", topUp.Reference, "error", err)
+	}
+
+	if retryablePrintError(sendErr) && job.Attempts < job.MaxAttempts {
+		return nil, sendErr
+	}
+	if err := s.refund(ctx, topUp.ID); err != nil {
+		// Not refunded yet: let the queue run the job again, which retries the refund
+		return nil, fmt.Errorf("refund after failed delivery: %w", err)
+	}
+	return nil, jobs.Permanent(sendErr)
+}
+
+// refund returns the points of an undelivered top-up, marks its order failed and
+// puts the stock back
+func (s *Service) refund(ctx context.Context, topUpID uint) error {
+	var topUp *TopUp
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		var err error
+		topUp, err = s.repo.Lock(tx, topUpID)
+		if err != nil {
+			return err
+		}
+		if topUp.Status != StatusPending {
+			return errors.New("top-up is no longer pending")
+		}
+
+		// A refund reverses the marketplace debit of the purchase
+		var order marketplace.MarketplaceTransaction
+		if err := tx.Select("id", "debit_id").First(&order, topUp.OrderID).Error; err != nil {
+			return err
+		}
+		description := fmt.Sprintf("Refund: print quota %s could not be delivered", topUp.Reference)
+		if err := s.walletService.RefundWithTransaction(tx, topUp.WalletID, order.DebitID, topUp.Points, "marketplace", description); err != nil {
+			return err
+		}
+		if err := tx.Model(&marketplace.MarketplaceTransaction{}).Where("id = ?", topUp.OrderID).Update("status", "failed").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&marketplace.Product{}).Where("id = ?", topUp.ProductID).
+			Update("stock", gorm.Expr("stock + ?", topUp.Quantity)).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		topUp.Status = StatusRefunded
+		topUp.RefundedAt = &now
+		return tx.Model(topUp).Updates(map[string]interface{}{"status": topUp.Status, "refunded_at": now}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.marketplace.ProductChanged(topUp.ProductID)
+	s.notify(topUp, "print_quota_refunded", "Kuota cetak gagal ditambahkan",
+		fmt.Sprintf("Server cetak tidak dapat menambahkan %d halaman (%s); %d poin telah dikembalikan ke dompet Anda.", topUp.Pages, topUp.Reference, topUp.Points))
+	return nil
+}
+
+// GetTopUps lists top-ups; a UserID limits them to one student
+func (s *Service) GetTopUps(ctx context.Context, params TopUpListParams) (*TopUpListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	topUps, total, err := s.repo.FindAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &TopUpListResponse{
+		TopUps:     topUps,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+func (s *Service) notify(topUp *TopUp, notificationType, title, message string) {
+	if s.notifications == nil {
+		return
+	}
+
+	err := s.notifications.Notify(notification.NotifyParams{
+		UserID:  topUp.UserID,
+		Type:    notificationType,
+		Title:   title,
+		Message: message,
+		Link:    "/print-quota",
+	})
+	if err != nil {
+		slog.Error("print quota: notify student failed", "reference", topUp.Reference, "error", err)
+	}
+}
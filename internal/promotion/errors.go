@@ -0,0 +1,12 @@
+package promotion
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrPromotionNotFound = utils.NewAppError("PROMOTION_NOT_FOUND", http.StatusNotFound, "promotion not found")
+	ErrInvalidPromotion  = utils.NewAppError("PROMOTION_INVALID", http.StatusBadRequest, "promotion is incomplete for its type")
+	ErrFirstPurchaseUsed = utils.NewAppError("PROMOTION_FIRST_PURCHASE_USED", http.StatusConflict, "the first purchase discount only applies to your first order, price the order again")
+)
@@ -0,0 +1,181 @@
+package promotion
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewPromotionHandler(service *Service, auditService audit.Logger) *PromotionHandler {
+	return &PromotionHandler{service: service, auditService: auditService}
+}
+
+// GetAll handles listing promotions (Admin)
+// @Summary List promotions
+// @Description Promotions of the admin's campus in evaluation order: highest priority first
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Promotion}
+// @Router /admin/promotions [get]
+func (h *PromotionHandler) GetAll(c *gin.Context) {
+	promotions, err := h.service.GetPromotions(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve promotions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promotions retrieved successfully", promotions)
+}
+
+// Create handles adding a promotion (Admin)
+// @Summary Create promotion
+// @Description buy_x_get_y needs product_id, buy_quantity and free_quantity (every buy+free units, free are free); category_percent needs category_id and percent, and covers the products of its subcategories too; first_purchase needs amount, the points taken off a student's first order. When a cart is priced, promotions are tried from the highest priority down: a stackable promotion combines with the others, one that is not only applies to a cart without discount and ends the evaluation.
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PromotionRequest true "Promotion"
+// @Success 201 {object} utils.Response{data=Promotion}
+// @Failure 400 {object} utils.Response
+// @Router /admin/promotions [post]
+func (h *PromotionHandler) Create(c *gin.Context) {
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	promotion, err := h.service.CreatePromotion(c.Request.Context(), req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Promotion created successfully", promotion)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_PROMOTION",
+		Entity:    "PROMOTION",
+		EntityID:  promotion.ID,
+		Details:   fmt.Sprintf("Created %s promotion %q (priority %d, stackable %t)", promotion.Type, promotion.Name, promotion.Priority, promotion.Stackable),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Update handles replacing a promotion (Admin)
+// @Summary Update promotion
+// @Description Replaces every field; see Create promotion for the fields of each type. Redemptions already made keep their discount.
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Param request body PromotionRequest true "Promotion"
+// @Success 200 {object} utils.Response{data=Promotion}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/promotions/{id} [put]
+func (h *PromotionHandler) Update(c *gin.Context) {
+	id, ok := promotionIDParam(c)
+	if !ok {
+		return
+	}
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	promotion, err := h.service.UpdatePromotion(c.Request.Context(), id, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promotion updated successfully", promotion)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_PROMOTION",
+		Entity:    "PROMOTION",
+		EntityID:  promotion.ID,
+		Details:   fmt.Sprintf("Updated %s promotion %q (priority %d, stackable %t, active %t)", promotion.Type, promotion.Name, promotion.Priority, promotion.Stackable, promotion.Active),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Delete handles removing a promotion (Admin)
+// @Summary Delete promotion
+// @Description The promotion stops applying; its redemptions stay in the usage analytics
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/promotions/{id} [delete]
+func (h *PromotionHandler) Delete(c *gin.Context) {
+	id, ok := promotionIDParam(c)
+	if !ok {
+		return
+	}
+
+	promotion, err := h.service.DeletePromotion(c.Request.Context(), id)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promotion deleted successfully", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "DELETE_PROMOTION",
+		Entity:    "PROMOTION",
+		EntityID:  id,
+		Details:   fmt.Sprintf("Deleted promotion %q", promotion.Name),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetUsage handles the promotion usage analytics (Admin)
+// @Summary Promotion usage
+// @Description Per promotion of the admin's campus, deleted ones included: completed orders it was redeemed on, distinct students, points given away and the last redemption. Most redeemed first.
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Usage}
+// @Router /admin/promotions/usage [get]
+func (h *PromotionHandler) GetUsage(c *gin.Context) {
+	usage, err := h.service.GetUsage(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve promotion usage", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promotion usage retrieved successfully", usage)
+}
+
+func promotionIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid promotion ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,114 @@
+package promotion
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Promotion types
+const (
+	TypeBuyXGetY        = "buy_x_get_y"      // every BuyQuantity+FreeQuantity units of ProductID, FreeQuantity are free
+	TypeCategoryPercent = "category_percent" // Percent off the products of CategoryID and its subcategories
+	TypeFirstPurchase   = "first_purchase"   // Amount points off a student's first order
+)
+
+// Promotion is a discount applied when a cart is priced. Promotions are tried
+// from the highest Priority down; a stackable one combines with the others
+// applied, one that is not only applies to a cart nothing was taken off yet
+// and ends the evaluation.
+type Promotion struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	TenantID     uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	Name         string         `json:"name" gorm:"size:100;not null"`
+	Description  string         `json:"description" gorm:"type:text"`
+	Type         string         `json:"type" gorm:"type:enum('buy_x_get_y','category_percent','first_purchase');not null"`
+	Priority     int            `json:"priority" gorm:"default:0;not null"`
+	Stackable    bool           `json:"stackable" gorm:"default:false;not null"`
+	ProductID    *uint          `json:"product_id,omitempty"` // buy_x_get_y
+	BuyQuantity  int            `json:"buy_quantity,omitempty" gorm:"default:0;not null"`
+	FreeQuantity int            `json:"free_quantity,omitempty" gorm:"default:0;not null"`
+	CategoryID   *uint          `json:"category_id,omitempty"` // category_percent
+	Percent      int            `json:"percent,omitempty" gorm:"default:0;not null"`
+	Amount       int            `json:"amount,omitempty" gorm:"default:0;not null"` // first_purchase
+	StartsAt     *time.Time     `json:"starts_at"`
+	EndsAt       *time.Time     `json:"ends_at"`
+	Active       bool           `json:"active" gorm:"default:true;not null;index"`
+	CreatedBy    uint           `json:"created_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// Redemption records a promotion taken off a completed order
+type Redemption struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TenantID    uint      `json:"tenant_id" gorm:"not null;default:1;index"`
+	PromotionID uint      `json:"promotion_id" gorm:"not null;index"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	Discount    int       `json:"discount" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+func (Redemption) TableName() string {
+	return "promotion_redemptions"
+}
+
+// PromotionRequest creates a promotion or replaces all of its fields. Only the
+// fields of the type are used.
+type PromotionRequest struct {
+	Name         string     `json:"name" binding:"required,max=100"`
+	Description  string     `json:"description"`
+	Type         string     `json:"type" binding:"required,promotion_type" enums:"buy_x_get_y,category_percent,first_purchase"`
+	Priority     int        `json:"priority"`
+	Stackable    bool       `json:"stackable"`
+	ProductID    *uint      `json:"product_id"`
+	BuyQuantity  int        `json:"buy_quantity" binding:"gte=0"`
+	FreeQuantity int        `json:"free_quantity" binding:"gte=0"`
+	CategoryID   *uint      `json:"category_id"`
+	Percent      int        `json:"percent" binding:"gte=0,lte=100"`
+	Amount       int        `json:"amount" binding:"gte=0,lte=10000000"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	Active       *bool      `json:"active"` // defaults to true
+}
+
+// Line is a cart line to price
+type Line struct {
+	ProductID uint
+	Price     int
+	Quantity  int
+}
+
+// Applied is a promotion taken off a cart
+type Applied struct {
+	PromotionID uint   `json:"promotion_id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Discount    int    `json:"discount"`
+}
+
+// Pricing is a priced cart. LineDiscounts holds the points taken off each line,
+// in line order.
+type Pricing struct {
+	Subtotal      int       `json:"subtotal"`
+	Discount      int       `json:"discount"`
+	Total         int       `json:"total"`
+	Applied       []Applied `json:"applied"`
+	LineDiscounts []int     `json:"-"`
+}
+
+// Usage is the redemption analytics of one promotion
+type Usage struct {
+	PromotionID   uint       `json:"promotion_id"`
+	Name          string     `json:"name"`
+	Type          string     `json:"type"`
+	Redemptions   int64      `json:"redemptions"`
+	Users         int64      `json:"users"`
+	TotalDiscount int64      `json:"total_discount"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+}
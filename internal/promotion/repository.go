@@ -0,0 +1,123 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) FindAll(ctx context.Context) ([]Promotion, error) {
+	var promotions []Promotion
+	err := r.db.WithContext(ctx).Order("priority DESC").Order("id ASC").Find(&promotions).Error
+	return promotions, err
+}
+
+func (r *Repository) FindByID(ctx context.Context, id uint) (*Promotion, error) {
+	var promotion Promotion
+	err := r.db.WithContext(ctx).First(&promotion, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPromotionNotFound
+	}
+	return &promotion, err
+}
+
+func (r *Repository) Create(ctx context.Context, promotion *Promotion) error {
+	return r.db.WithContext(ctx).Create(promotion).Error
+}
+
+// Save writes every field, so a request can clear the optional ones
+func (r *Repository) Save(ctx context.Context, promotion *Promotion) error {
+	return r.db.WithContext(ctx).Select("*").Omit("created_at", "created_by").Save(promotion).Error
+}
+
+func (r *Repository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&Promotion{}, id).Error
+}
+
+// findRunning returns the active promotions whose window includes now, in evaluation order
+func (r *Repository) findRunning(db *gorm.DB, now time.Time) ([]Promotion, error) {
+	var promotions []Promotion
+	err := db.Where("active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("priority DESC").Order("id ASC").
+		Find(&promotions).Error
+	return promotions, err
+}
+
+// productCategories returns the category IDs of each product together with
+// their ancestors, so a category promotion covers its whole subtree
+func (r *Repository) productCategories(db *gorm.DB, productIDs []uint) (map[uint][]uint, error) {
+	var rows []struct {
+		ProductID  uint
+		CategoryID uint
+	}
+	err := db.Table("product_categories").Select("product_id, category_id").Where("product_id IN ?", productIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	var parents []struct {
+		ID       uint
+		ParentID *uint
+	}
+	if err := db.Table("categories").Select("id, parent_id").Where("parent_id IS NOT NULL").Scan(&parents).Error; err != nil {
+		return nil, err
+	}
+	parentOf := make(map[uint]uint, len(parents))
+	for _, category := range parents {
+		parentOf[category.ID] = *category.ParentID
+	}
+
+	categories := make(map[uint][]uint, len(productIDs))
+	for _, row := range rows {
+		categories[row.ProductID] = append(categories[row.ProductID], withAncestors(row.CategoryID, parentOf)...)
+	}
+	return categories, nil
+}
+
+// withAncestors returns categoryID followed by its parent, grandparent and so
+// on; a cycle ends the walk
+func withAncestors(categoryID uint, parentOf map[uint]uint) []uint {
+	ids := []uint{categoryID}
+	seen := map[uint]bool{categoryID: true}
+	for parent, ok := parentOf[categoryID]; ok && !seen[parent]; parent, ok = parentOf[parent] {
+		seen[parent] = true
+		ids = append(ids, parent)
+	}
+	return ids
+}
+
+// hasOrdered reports whether a wallet has a successful marketplace order
+func (r *Repository) hasOrdered(db *gorm.DB, walletID uint) (bool, error) {
+	var count int64
+	err := db.Table("marketplace_transactions").Where("wallet_id = ? AND status = ?", walletID, "success").Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *Repository) createRedemptions(tx *gorm.DB, redemptions []Redemption) error {
+	return tx.Create(&redemptions).Error
+}
+
+// usage sums the redemptions of every promotion of the campus of ctx, deleted ones included
+func (r *Repository) usage(ctx context.Context) ([]Usage, error) {
+	var usage []Usage
+	err := r.db.WithContext(ctx).Model(&Promotion{}).Unscoped().
+		Select("promotions.id AS promotion_id, promotions.name, promotions.type, " +
+			"COUNT(pr.id) AS redemptions, COUNT(DISTINCT pr.user_id) AS users, " +
+			"COALESCE(SUM(pr.discount), 0) AS total_discount, MAX(pr.created_at) AS last_used_at").
+		Joins("LEFT JOIN promotion_redemptions pr ON pr.promotion_id = promotions.id").
+		Group("promotions.id, promotions.name, promotions.type").
+		Order("redemptions DESC").Order("promotions.id ASC").
+		Scan(&usage).Error
+	return usage, err
+}
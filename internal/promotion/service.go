@@ -0,0 +1,238 @@
+package promotion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Service struct {
+	repo *Repository
+	db   *gorm.DB
+}
+
+func NewService(repo *Repository, db *gorm.DB) *Service {
+	return &Service{repo: repo, db: db}
+}
+
+func (s *Service) GetPromotions(ctx context.Context) ([]Promotion, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// CreatePromotion adds a promotion to the campus of ctx
+func (s *Service) CreatePromotion(ctx context.Context, req PromotionRequest, adminID uint) (*Promotion, error) {
+	promotion := &Promotion{CreatedBy: adminID}
+	if err := apply(promotion, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion replaces the fields of a promotion; past redemptions keep their discount
+func (s *Service) UpdatePromotion(ctx context.Context, id uint, req PromotionRequest) (*Promotion, error) {
+	promotion, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(promotion, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// DeletePromotion stops a promotion; its redemptions stay in the usage analytics
+func (s *Service) DeletePromotion(ctx context.Context, id uint) (*Promotion, error) {
+	promotion, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return promotion, s.repo.Delete(ctx, id)
+}
+
+func (s *Service) GetUsage(ctx context.Context) ([]Usage, error) {
+	return s.repo.usage(ctx)
+}
+
+// apply validates req for its type and copies it onto promotion, clearing the
+// fields of the other types
+func apply(promotion *Promotion, req PromotionRequest) error {
+	promotion.Name = req.Name
+	promotion.Description = req.Description
+	promotion.Type = req.Type
+	promotion.Priority = req.Priority
+	promotion.Stackable = req.Stackable
+	promotion.StartsAt = req.StartsAt
+	promotion.EndsAt = req.EndsAt
+	promotion.Active = req.Active == nil || *req.Active
+	promotion.ProductID, promotion.BuyQuantity, promotion.FreeQuantity = nil, 0, 0
+	promotion.CategoryID, promotion.Percent = nil, 0
+	promotion.Amount = 0
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+	switch req.Type {
+	case TypeBuyXGetY:
+		if req.ProductID == nil || req.BuyQuantity < 1 || req.FreeQuantity < 1 {
+			return fmt.Errorf("%w: buy_x_get_y needs product_id, buy_quantity and free_quantity", ErrInvalidPromotion)
+		}
+		promotion.ProductID, promotion.BuyQuantity, promotion.FreeQuantity = req.ProductID, req.BuyQuantity, req.FreeQuantity
+	case TypeCategoryPercent:
+		if req.CategoryID == nil || req.Percent < 1 {
+			return fmt.Errorf("%w: category_percent needs category_id and percent", ErrInvalidPromotion)
+		}
+		promotion.CategoryID, promotion.Percent = req.CategoryID, req.Percent
+	case TypeFirstPurchase:
+		if req.Amount < 1 {
+			return fmt.Errorf("%w: first_purchase needs amount", ErrInvalidPromotion)
+		}
+		promotion.Amount = req.Amount
+	}
+	return nil
+}
+
+// Price applies the running promotions of the campus of ctx to the lines of an
+// order of walletID, see price
+func (s *Service) Price(ctx context.Context, walletID uint, lines []Line) (*Pricing, error) {
+	db := s.db.WithContext(ctx)
+	if len(lines) == 0 {
+		return price(lines, nil, nil, false), nil
+	}
+	promotions, err := s.repo.findRunning(db, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var categories map[uint][]uint
+	ordered := false
+	loaded := map[string]bool{}
+	for _, promotion := range promotions {
+		if loaded[promotion.Type] {
+			continue
+		}
+		loaded[promotion.Type] = true
+		switch promotion.Type {
+		case TypeCategoryPercent:
+			productIDs := make([]uint, len(lines))
+			for i, line := range lines {
+				productIDs[i] = line.ProductID
+			}
+			if categories, err = s.repo.productCategories(db, productIDs); err != nil {
+				return nil, err
+			}
+		case TypeFirstPurchase:
+			if ordered, err = s.repo.hasOrdered(db, walletID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return price(lines, promotions, categories, ordered), nil
+}
+
+// price applies promotions, in evaluation order, to lines. Promotions are
+// tried from the highest priority down: a stackable one is applied next to the
+// others, one that is not only to a cart without discount, after which no
+// other promotion is tried. Each discount is taken from what is left of the
+// lines, so lines never go below zero. categories holds the categories of each
+// product with their ancestors, ordered whether the wallet ordered before.
+func price(lines []Line, promotions []Promotion, categories map[uint][]uint, ordered bool) *Pricing {
+	pricing := &Pricing{Applied: []Applied{}, LineDiscounts: make([]int, len(lines))}
+	left := make([]int, len(lines))
+	for i, line := range lines {
+		left[i] = line.Price * line.Quantity
+		pricing.Subtotal += left[i]
+	}
+
+	for _, promotion := range promotions {
+		if !promotion.Stackable && len(pricing.Applied) > 0 {
+			continue
+		}
+
+		discounts := make([]int, len(lines))
+		switch promotion.Type {
+		case TypeBuyXGetY:
+			for i, line := range lines {
+				if line.ProductID == *promotion.ProductID {
+					free := line.Quantity / (promotion.BuyQuantity + promotion.FreeQuantity) * promotion.FreeQuantity
+					discounts[i] = min(free*line.Price, left[i])
+				}
+			}
+		case TypeCategoryPercent:
+			for i, line := range lines {
+				for _, categoryID := range categories[line.ProductID] {
+					if categoryID == *promotion.CategoryID {
+						discounts[i] = left[i] * promotion.Percent / 100
+						break
+					}
+				}
+			}
+		case TypeFirstPurchase:
+			if ordered {
+				continue
+			}
+			remaining := promotion.Amount
+			for i := range lines {
+				discounts[i] = min(remaining, left[i])
+				remaining -= discounts[i]
+			}
+		}
+
+		total := 0
+		for i, discount := range discounts {
+			left[i] -= discount
+			pricing.LineDiscounts[i] += discount
+			total += discount
+		}
+		if total == 0 {
+			continue
+		}
+		pricing.Applied = append(pricing.Applied, Applied{PromotionID: promotion.ID, Name: promotion.Name, Type: promotion.Type, Discount: total})
+		pricing.Discount += total
+		if !promotion.Stackable {
+			break
+		}
+	}
+	pricing.Total = pricing.Subtotal - pricing.Discount
+	return pricing
+}
+
+// CheckFirstPurchase fails when applied holds a first purchase discount but
+// walletID has ordered since the order was priced. It runs in the transaction
+// that debits the order, under the wallet lock, so two concurrent first orders
+// cannot both get the discount.
+func (s *Service) CheckFirstPurchase(tx *gorm.DB, walletID uint, applied []Applied) error {
+	for _, promotion := range applied {
+		if promotion.Type != TypeFirstPurchase {
+			continue
+		}
+		ordered, err := s.repo.hasOrdered(tx, walletID)
+		if err != nil {
+			return err
+		}
+		if ordered {
+			return ErrFirstPurchaseUsed
+		}
+		return nil
+	}
+	return nil
+}
+
+// Redeem records the promotions applied to a completed order within its transaction
+func (s *Service) Redeem(tx *gorm.DB, userID uint, applied []Applied) error {
+	if len(applied) == 0 {
+		return nil
+	}
+	redemptions := make([]Redemption, len(applied))
+	for i, promotion := range applied {
+		redemptions[i] = Redemption{PromotionID: promotion.PromotionID, UserID: userID, Discount: promotion.Discount}
+	}
+	return s.repo.createRedemptions(tx, redemptions)
+}
@@ -0,0 +1,176 @@
+package promotion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrice(t *testing.T) {
+	id := func(v uint) *uint { return &v }
+	notebook := Line{ProductID: 1, Price: 100, Quantity: 2} // 200
+	pen := Line{ProductID: 2, Price: 30, Quantity: 5}       // 150
+	categories := map[uint][]uint{
+		1: withAncestors(11, map[uint]uint{11: 10}), // Stationery (10) > Notebooks (11)
+		2: {20},
+	}
+
+	tests := []struct {
+		name          string
+		lines         []Line
+		promotions    []Promotion
+		ordered       bool
+		wantDiscount  int
+		wantLines     []int
+		wantPromotion []uint
+	}{
+		{
+			name:         "no promotions",
+			lines:        []Line{notebook, pen},
+			wantLines:    []int{0, 0},
+			wantDiscount: 0,
+		},
+		{
+			name:  "buy 2 get 1 takes full groups only",
+			lines: []Line{pen},
+			promotions: []Promotion{
+				{ID: 1, Type: TypeBuyXGetY, ProductID: id(2), BuyQuantity: 2, FreeQuantity: 1},
+			},
+			wantLines:     []int{30},
+			wantDiscount:  30,
+			wantPromotion: []uint{1},
+		},
+		{
+			name:  "category percent covers subcategories",
+			lines: []Line{notebook, pen},
+			promotions: []Promotion{
+				{ID: 2, Type: TypeCategoryPercent, CategoryID: id(10), Percent: 25},
+			},
+			wantLines:     []int{50, 0},
+			wantDiscount:  50,
+			wantPromotion: []uint{2},
+		},
+		{
+			name:  "category percent does not cover the parent",
+			lines: []Line{notebook},
+			promotions: []Promotion{
+				{ID: 2, Type: TypeCategoryPercent, CategoryID: id(12), Percent: 25},
+			},
+			wantLines: []int{0},
+		},
+		{
+			name:  "first purchase spreads over the lines",
+			lines: []Line{notebook, pen},
+			promotions: []Promotion{
+				{ID: 3, Type: TypeFirstPurchase, Amount: 250},
+			},
+			wantLines:     []int{200, 50},
+			wantDiscount:  250,
+			wantPromotion: []uint{3},
+		},
+		{
+			name:  "first purchase skipped after an order",
+			lines: []Line{notebook},
+			promotions: []Promotion{
+				{ID: 3, Type: TypeFirstPurchase, Amount: 50},
+			},
+			ordered:   true,
+			wantLines: []int{0},
+		},
+		{
+			name:  "first purchase never goes below zero",
+			lines: []Line{pen},
+			promotions: []Promotion{
+				{ID: 3, Type: TypeFirstPurchase, Amount: 1000},
+			},
+			wantLines:     []int{150},
+			wantDiscount:  150,
+			wantPromotion: []uint{3},
+		},
+		{
+			name:  "stackable promotions apply on what is left",
+			lines: []Line{notebook},
+			promotions: []Promotion{
+				{ID: 2, Type: TypeCategoryPercent, CategoryID: id(11), Percent: 50, Stackable: true},
+				{ID: 3, Type: TypeFirstPurchase, Amount: 30, Stackable: true},
+			},
+			wantLines:     []int{130},
+			wantDiscount:  130,
+			wantPromotion: []uint{2, 3},
+		},
+		{
+			name:  "non-stackable promotion ends the evaluation",
+			lines: []Line{notebook},
+			promotions: []Promotion{
+				{ID: 2, Type: TypeCategoryPercent, CategoryID: id(11), Percent: 10},
+				{ID: 3, Type: TypeFirstPurchase, Amount: 30, Stackable: true},
+			},
+			wantLines:     []int{20},
+			wantDiscount:  20,
+			wantPromotion: []uint{2},
+		},
+		{
+			name:  "non-stackable promotion skips a discounted cart",
+			lines: []Line{notebook},
+			promotions: []Promotion{
+				{ID: 3, Type: TypeFirstPurchase, Amount: 30, Stackable: true},
+				{ID: 2, Type: TypeCategoryPercent, CategoryID: id(11), Percent: 10},
+			},
+			wantLines:     []int{30},
+			wantDiscount:  30,
+			wantPromotion: []uint{3},
+		},
+		{
+			name:  "promotion without effect is not applied",
+			lines: []Line{notebook},
+			promotions: []Promotion{
+				{ID: 1, Type: TypeBuyXGetY, ProductID: id(2), BuyQuantity: 1, FreeQuantity: 1},
+				{ID: 2, Type: TypeCategoryPercent, CategoryID: id(10), Percent: 10},
+			},
+			wantLines:     []int{20},
+			wantDiscount:  20,
+			wantPromotion: []uint{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricing := price(tt.lines, tt.promotions, categories, tt.ordered)
+
+			subtotal := 0
+			for _, line := range tt.lines {
+				subtotal += line.Price * line.Quantity
+			}
+			if pricing.Subtotal != subtotal || pricing.Discount != tt.wantDiscount || pricing.Total != subtotal-tt.wantDiscount {
+				t.Errorf("subtotal, discount, total = %d, %d, %d, want %d, %d, %d",
+					pricing.Subtotal, pricing.Discount, pricing.Total, subtotal, tt.wantDiscount, subtotal-tt.wantDiscount)
+			}
+			if !reflect.DeepEqual(pricing.LineDiscounts, tt.wantLines) {
+				t.Errorf("line discounts = %v, want %v", pricing.LineDiscounts, tt.wantLines)
+			}
+			var applied []uint
+			for _, promotion := range pricing.Applied {
+				applied = append(applied, promotion.PromotionID)
+			}
+			if !reflect.DeepEqual(applied, tt.wantPromotion) {
+				t.Errorf("applied = %v, want %v", applied, tt.wantPromotion)
+			}
+		})
+	}
+}
+
+func TestWithAncestors(t *testing.T) {
+	parentOf := map[uint]uint{3: 2, 2: 1, 5: 6, 6: 5}
+	tests := []struct {
+		category uint
+		want     []uint
+	}{
+		{category: 1, want: []uint{1}},
+		{category: 3, want: []uint{3, 2, 1}},
+		{category: 5, want: []uint{5, 6}}, // cycle
+	}
+	for _, tt := range tests {
+		if got := withAncestors(tt.category, parentOf); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("withAncestors(%d) = %v, want %v", tt.category, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package quota
+
+import (
+	"net/http"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuotaHandler struct {
+	service *Service
+}
+
+func NewQuotaHandler(service *Service) *QuotaHandler {
+	return &QuotaHandler{service: service}
+}
+
+// GetMyQuotas handles the current user's remaining allowances
+// @Summary Get my quotas
+// @Description What the user may still spend on marketplace orders and at the canteen today, transfer today and in one transfer, and buy of each product with a purchase limit. A null allowance means that limit is off; daily allowances carry the time they reset.
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=Quotas}
+// @Failure 404 {object} utils.Response
+// @Router /users/me/quotas [get]
+func (h *QuotaHandler) GetMyQuotas(c *gin.Context) {
+	quotas, err := h.service.GetQuotas(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Quotas retrieved successfully", quotas)
+}
@@ -0,0 +1,16 @@
+package quota
+
+import (
+	"wallet-point/internal/marketplace"
+	"wallet-point/utils"
+)
+
+// Quotas is what the current user may still do today. A nil allowance means
+// the limit is off.
+type Quotas struct {
+	DailySpend    *utils.Allowance               `json:"daily_spend"`    // marketplace orders (marketplace.daily_spend_limit)
+	CanteenDaily  *utils.Allowance               `json:"canteen_daily"`  // canteen charges (POS_DAILY_LIMIT)
+	TransferDaily *utils.Allowance               `json:"transfer_daily"` // transfers (transfer.daily_limit)
+	TransferMax   int                            `json:"transfer_max"`   // largest single transfer (transfer.max_amount); 0 means no limit
+	Products      []marketplace.ProductAllowance `json:"products"`       // active products with a purchase limit
+}
@@ -0,0 +1,45 @@
+package quota
+
+import (
+	"context"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/pos"
+	"wallet-point/internal/transfer"
+	"wallet-point/internal/wallet"
+)
+
+// Service sums up the limits the marketplace, canteen and transfer services
+// enforce, so clients can show them instead of finding out from a rejection
+type Service struct {
+	walletService *wallet.WalletService
+	marketplace   *marketplace.MarketplaceService
+	pos           *pos.Service
+	transfers     *transfer.Service
+}
+
+func NewService(walletService *wallet.WalletService, marketplaceService *marketplace.MarketplaceService, posService *pos.Service, transferService *transfer.Service) *Service {
+	return &Service{walletService: walletService, marketplace: marketplaceService, pos: posService, transfers: transferService}
+}
+
+// GetQuotas returns the remaining allowances of a user, as of now
+func (s *Service) GetQuotas(ctx context.Context, userID uint) (*Quotas, error) {
+	userWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas := &Quotas{}
+	if quotas.DailySpend, err = s.marketplace.DailySpendAllowance(ctx, userWallet.ID); err != nil {
+		return nil, err
+	}
+	if quotas.CanteenDaily, err = s.pos.DailyAllowance(ctx, userID); err != nil {
+		return nil, err
+	}
+	if quotas.TransferMax, quotas.TransferDaily, err = s.transfers.Limits(ctx, userWallet.ID); err != nil {
+		return nil, err
+	}
+	if quotas.Products, err = s.marketplace.ProductAllowances(ctx, userWallet.ID); err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
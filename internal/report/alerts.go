@@ -0,0 +1,67 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"wallet-point/internal/alert"
+)
+
+const (
+	// FlaggedAlertInterval is the schedule of the flagged transaction scan; each
+	// run covers the interval before it
+	FlaggedAlertInterval = 5 * time.Minute
+	flaggedAlertExamples = 5
+)
+
+// SetAlerts reports flagged transactions to the operations channel, see AlertFlaggedTransactions
+func (s *ReportService) SetAlerts(alerts *alert.Service) {
+	s.alerts = alerts
+}
+
+// AlertFlaggedTransactions posts the failed and large wallet transactions of the
+// last FlaggedAlertInterval, for the cron job running on that interval
+func (s *ReportService) AlertFlaggedTransactions(ctx context.Context) (string, error) {
+	if s.alerts == nil {
+		return "alerts disabled", nil
+	}
+
+	end := time.Now().Truncate(FlaggedAlertInterval)
+	period := dateRange{Start: end.Add(-FlaggedAlertInterval), End: end}
+	transactions, err := s.repo.FindFlaggedTransactions(ctx, period, s.largeTransactionThreshold, flaggedTransactionLimit+1)
+	if err != nil {
+		return "", err
+	}
+	if len(transactions) == 0 {
+		return "no flagged transactions", nil
+	}
+
+	count := fmt.Sprintf("%d", len(transactions))
+	if len(transactions) > flaggedTransactionLimit {
+		count = fmt.Sprintf("over %d", flaggedTransactionLimit)
+	}
+
+	var lines []string
+	for i, t := range transactions {
+		if i == flaggedAlertExamples {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("#%d %s %s %d points by %s (%s): %s", t.ID, t.Type, t.Direction, t.Amount, t.UserName, t.NimNip, t.Reason))
+	}
+
+	fields := map[string]string{
+		"period": fmt.Sprintf("%s to %s", period.Start.Format("15:04"), period.End.Format("15:04")),
+	}
+	if s.largeTransactionThreshold > 0 {
+		fields["large amount"] = fmt.Sprintf("%d points or more", s.largeTransactionThreshold)
+	}
+	s.alerts.Send(alert.Alert{
+		Key:      "wallet.flagged_transactions",
+		Severity: alert.SeverityWarning,
+		Title:    fmt.Sprintf("%s flagged wallet transactions", count),
+		Text:     strings.Join(lines, "\n"),
+		Fields:   fields,
+	})
+	return fmt.Sprintf("%s flagged transactions", count), nil
+}
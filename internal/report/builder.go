@@ -0,0 +1,324 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// The custom report builder only ever compiles these whitelisted SQL fragments;
+// user input is limited to picking keys from the maps and to bound parameter values.
+
+type queryDimension struct {
+	Columns []QueryColumn // output columns (an ID and its label, or a single value)
+	Selects []string      // one expression per column
+	Group   string
+	Filter  string // column compared by filters, empty when the dimension is not filterable
+	Joins   []string
+	Numeric bool // filter values are IDs
+}
+
+// primaryCategoryJoin attributes each product to its lowest-ID category
+const primaryCategoryJoin = "LEFT JOIN (SELECT product_id, MIN(category_id) as category_id FROM product_categories GROUP BY product_id) pc ON pc.product_id = mt.product_id"
+
+var queryDimensions = map[string]queryDimension{
+	"product": {
+		Columns: []QueryColumn{{Name: "product_id", Type: "integer"}, {Name: "product_name", Type: "string"}},
+		Selects: []string{"mt.product_id", "p.name"},
+		Group:   "mt.product_id, p.name",
+		Filter:  "mt.product_id",
+		Joins:   []string{"LEFT JOIN products p ON p.id = mt.product_id"},
+		Numeric: true,
+	},
+	"category": {
+		Columns: []QueryColumn{{Name: "category_id", Type: "integer"}, {Name: "category_name", Type: "string"}},
+		Selects: []string{"pc.category_id", "COALESCE(c.name, 'Uncategorized')"},
+		Group:   "pc.category_id, c.name",
+		Filter:  "category", // matched against any category of the product, sub-categories included
+		Joins:   []string{primaryCategoryJoin, "LEFT JOIN categories c ON c.id = pc.category_id"},
+		Numeric: true,
+	},
+	// Faculty is the study programme the buyer entered at purchase time; users carry no faculty of their own
+	"faculty": {
+		Columns: []QueryColumn{{Name: "faculty", Type: "string"}},
+		Selects: []string{"COALESCE(NULLIF(mt.student_major, ''), 'Unknown')"},
+		Group:   "COALESCE(NULLIF(mt.student_major, ''), 'Unknown')",
+		Filter:  "mt.student_major",
+	},
+	"batch": {
+		Columns: []QueryColumn{{Name: "batch", Type: "string"}},
+		Selects: []string{"COALESCE(NULLIF(mt.student_batch, ''), 'Unknown')"},
+		Group:   "COALESCE(NULLIF(mt.student_batch, ''), 'Unknown')",
+		Filter:  "mt.student_batch",
+	},
+	"day": {
+		Columns: []QueryColumn{{Name: "day", Type: "string"}},
+		Selects: []string{"DATE_FORMAT(mt.created_at, '%Y-%m-%d')"},
+		Group:   "DATE_FORMAT(mt.created_at, '%Y-%m-%d')",
+	},
+	"week": {
+		Columns: []QueryColumn{{Name: "week", Type: "string"}},
+		Selects: []string{"DATE_FORMAT(mt.created_at, '%x-W%v')"},
+		Group:   "DATE_FORMAT(mt.created_at, '%x-W%v')",
+	},
+	"month": {
+		Columns: []QueryColumn{{Name: "month", Type: "string"}},
+		Selects: []string{"DATE_FORMAT(mt.created_at, '%Y-%m')"},
+		Group:   "DATE_FORMAT(mt.created_at, '%Y-%m')",
+	},
+}
+
+var queryMeasures = map[string]string{
+	"units":  "CAST(COALESCE(SUM(mt.quantity), 0) AS SIGNED)",
+	"points": "CAST(COALESCE(SUM(mt.total_amount), 0) AS SIGNED)",
+	"orders": "COUNT(*)",
+	"buyers": "COUNT(DISTINCT mt.wallet_id)",
+}
+
+// queryFilterOps are the filter operators; neq and not_in exclude the values
+var queryFilterOps = map[string]bool{
+	"eq":     true,
+	"neq":    true,
+	"in":     true,
+	"not_in": true,
+}
+
+var queryComparisons = map[string]string{
+	"eq":  "=",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+)
+
+// compiledQuery is a validated report query ready to run
+type compiledQuery struct {
+	columns    []QueryColumn
+	selects    []string
+	groups     []string
+	joins      []string
+	filters    []QueryFilter
+	having     []QueryHaving
+	categories map[int][]uint // filter index -> category IDs with descendants
+	orderBy    string
+	limit      int
+}
+
+// compileQuery validates a report query against the whitelists
+func compileQuery(req QueryRequest) (*compiledQuery, error) {
+	q := &compiledQuery{categories: map[int][]uint{}, limit: req.Limit}
+	if q.limit < 1 {
+		q.limit = defaultQueryLimit
+	}
+	if q.limit > maxQueryLimit {
+		q.limit = maxQueryLimit
+	}
+
+	seenJoins := map[string]bool{}
+	seen := map[string]bool{}
+	for _, name := range req.Dimensions {
+		dimension, ok := queryDimensions[name]
+		if !ok {
+			return nil, errQuery{fmt.Errorf("unknown dimension %s", name)}
+		}
+		if seen[name] {
+			return nil, errQuery{fmt.Errorf("dimension %s selected twice", name)}
+		}
+		seen[name] = true
+
+		for i, column := range dimension.Columns {
+			q.columns = append(q.columns, column)
+			q.selects = append(q.selects, dimension.Selects[i]+" as "+column.Name)
+		}
+		q.groups = append(q.groups, dimension.Group)
+		for _, join := range dimension.Joins {
+			if !seenJoins[join] {
+				seenJoins[join] = true
+				q.joins = append(q.joins, join)
+			}
+		}
+	}
+
+	for _, name := range req.Measures {
+		expression, ok := queryMeasures[name]
+		if !ok {
+			return nil, errQuery{fmt.Errorf("unknown measure %s", name)}
+		}
+		if seen[name] {
+			return nil, errQuery{fmt.Errorf("measure %s selected twice", name)}
+		}
+		seen[name] = true
+		q.columns = append(q.columns, QueryColumn{Name: name, Type: "integer"})
+		q.selects = append(q.selects, expression+" as "+name)
+	}
+
+	for _, filter := range req.Filters {
+		dimension, ok := queryDimensions[filter.Dimension]
+		if !ok || dimension.Filter == "" {
+			return nil, errQuery{fmt.Errorf("dimension %s cannot be filtered", filter.Dimension)}
+		}
+		if !queryFilterOps[filter.Op] {
+			return nil, errQuery{fmt.Errorf("unknown filter operator %s", filter.Op)}
+		}
+		if (filter.Op == "eq" || filter.Op == "neq") && len(filter.Values) != 1 {
+			return nil, errQuery{fmt.Errorf("filter %s %s takes exactly one value", filter.Dimension, filter.Op)}
+		}
+		values := make([]interface{}, len(filter.Values))
+		for i, value := range filter.Values {
+			switch v := value.(type) {
+			case float64: // JSON numbers
+				if !dimension.Numeric || v < 1 || v != float64(uint(v)) {
+					return nil, errQuery{fmt.Errorf("filter %s takes %s", filter.Dimension, filterValueKind(dimension))}
+				}
+				values[i] = uint(v)
+			case string:
+				if dimension.Numeric {
+					return nil, errQuery{fmt.Errorf("filter %s takes %s", filter.Dimension, filterValueKind(dimension))}
+				}
+				values[i] = v
+			default:
+				return nil, errQuery{fmt.Errorf("filter %s takes %s", filter.Dimension, filterValueKind(dimension))}
+			}
+		}
+		filter.Values = values
+		q.filters = append(q.filters, filter)
+	}
+
+	for _, having := range req.Having {
+		if _, ok := queryMeasures[having.Measure]; !ok {
+			return nil, errQuery{fmt.Errorf("unknown measure %s in having", having.Measure)}
+		}
+		if _, ok := queryComparisons[having.Op]; !ok {
+			return nil, errQuery{fmt.Errorf("unknown comparison %s", having.Op)}
+		}
+		q.having = append(q.having, having)
+	}
+
+	sortBy := req.SortBy
+	if sortBy == "" {
+		sortBy = req.Measures[0]
+	}
+	sortable := false
+	for _, column := range q.columns {
+		if column.Name == sortBy {
+			sortable = true
+		}
+	}
+	if !sortable {
+		return nil, errQuery{errors.New("sort_by must be one of the selected columns")}
+	}
+	direction := "DESC"
+	if req.SortDesc != nil && !*req.SortDesc {
+		direction = "ASC"
+	}
+	q.orderBy = sortBy + " " + direction
+
+	return q, nil
+}
+
+// RunQuery executes a compiled report query over successful marketplace transactions
+func (r *ReportRepository) RunQuery(ctx context.Context, q *compiledQuery, period dateRange) ([]map[string]interface{}, error) {
+	query := r.replica.WithContext(ctx).Table("marketplace_transactions mt").
+		Select(strings.Join(q.selects, ", ")).
+		Where("mt.status = ? AND mt.created_at >= ? AND mt.created_at < ?", "success", period.Start, period.End).
+		Scopes(utils.ScopeTenantOwner(ctx, "mt.product_id", "products"))
+	for _, join := range q.joins {
+		query = query.Joins(join)
+	}
+
+	for i, filter := range q.filters {
+		query = applyQueryFilter(query, filter, q.categories[i])
+	}
+
+	if len(q.groups) > 0 {
+		query = query.Group(strings.Join(q.groups, ", "))
+	}
+	for _, having := range q.having {
+		query = query.Having(queryMeasures[having.Measure]+" "+queryComparisons[having.Op]+" ?", having.Value)
+	}
+
+	rows, err := query.Order(q.orderBy).Limit(q.limit).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []map[string]interface{}{}
+	for rows.Next() {
+		destinations := make([]interface{}, len(q.columns))
+		for i, column := range q.columns {
+			if column.Type == "integer" {
+				destinations[i] = new(sql.NullInt64)
+			} else {
+				destinations[i] = new(sql.NullString)
+			}
+		}
+		if err := rows.Scan(destinations...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(q.columns))
+		for i, column := range q.columns {
+			switch value := destinations[i].(type) {
+			case *sql.NullInt64:
+				if value.Valid {
+					row[column.Name] = value.Int64
+				} else {
+					row[column.Name] = nil
+				}
+			case *sql.NullString:
+				if value.Valid {
+					row[column.Name] = value.String
+				} else {
+					row[column.Name] = nil
+				}
+			}
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+func filterValueKind(dimension queryDimension) string {
+	if dimension.Numeric {
+		return "positive integer IDs"
+	}
+	return "text values"
+}
+
+func applyQueryFilter(query *gorm.DB, filter QueryFilter, categoryIDs []uint) *gorm.DB {
+	dimension := queryDimensions[filter.Dimension]
+	negate := filter.Op == "neq" || filter.Op == "not_in"
+
+	if dimension.Filter == "category" {
+		membership := "mt.product_id IN (SELECT product_id FROM product_categories WHERE category_id IN ?)"
+		if negate {
+			return query.Where("NOT "+membership, categoryIDs)
+		}
+		return query.Where(membership, categoryIDs)
+	}
+
+	if negate {
+		return query.Where(dimension.Filter+" NOT IN ?", filter.Values)
+	}
+	return query.Where(dimension.Filter+" IN ?", filter.Values)
+}
+
+// errQuery wraps builder validation errors so handlers can answer 400
+type errQuery struct{ error }
+
+// IsQueryError reports whether err was caused by an invalid report query
+func IsQueryError(err error) bool {
+	var queryErr errQuery
+	return errors.As(err, &queryErr)
+}
@@ -0,0 +1,139 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompileQuery(t *testing.T) {
+	desc := false
+	tests := []struct {
+		name    string
+		req     QueryRequest
+		wantErr string
+		check   func(t *testing.T, q *compiledQuery)
+	}{
+		{
+			name: "dimensions and measures become columns in order",
+			req:  QueryRequest{Dimensions: []string{"product", "day"}, Measures: []string{"units", "points"}},
+			check: func(t *testing.T, q *compiledQuery) {
+				var names []string
+				for _, column := range q.columns {
+					names = append(names, column.Name)
+				}
+				if got := strings.Join(names, ","); got != "product_id,product_name,day,units,points" {
+					t.Errorf("columns = %s", got)
+				}
+				if len(q.groups) != 2 || len(q.joins) != 1 {
+					t.Errorf("groups = %v, joins = %v", q.groups, q.joins)
+				}
+				if q.orderBy != "units DESC" {
+					t.Errorf("orderBy = %q, want the first measure descending", q.orderBy)
+				}
+				if q.limit != defaultQueryLimit {
+					t.Errorf("limit = %d, want %d", q.limit, defaultQueryLimit)
+				}
+			},
+		},
+		{
+			name: "limit is capped and sort can ascend",
+			req:  QueryRequest{Dimensions: []string{"faculty"}, Measures: []string{"buyers"}, SortBy: "faculty", SortDesc: &desc, Limit: 5000},
+			check: func(t *testing.T, q *compiledQuery) {
+				if q.limit != maxQueryLimit {
+					t.Errorf("limit = %d, want %d", q.limit, maxQueryLimit)
+				}
+				if q.orderBy != "faculty ASC" {
+					t.Errorf("orderBy = %q", q.orderBy)
+				}
+			},
+		},
+		{
+			name: "numeric filter values become IDs",
+			req: QueryRequest{Measures: []string{"units"}, Filters: []QueryFilter{
+				{Dimension: "product", Op: "not_in", Values: []interface{}{float64(3), float64(4)}},
+			}},
+			check: func(t *testing.T, q *compiledQuery) {
+				values := q.filters[0].Values
+				if len(values) != 2 || values[0] != uint(3) || values[1] != uint(4) {
+					t.Errorf("values = %#v", values)
+				}
+			},
+		},
+		{
+			name:    "unknown dimension",
+			req:     QueryRequest{Dimensions: []string{"wallet"}, Measures: []string{"units"}},
+			wantErr: "unknown dimension wallet",
+		},
+		{
+			name:    "duplicate measure",
+			req:     QueryRequest{Measures: []string{"units", "units"}},
+			wantErr: "measure units selected twice",
+		},
+		{
+			name: "unknown filter operator",
+			req: QueryRequest{Measures: []string{"units"}, Filters: []QueryFilter{
+				{Dimension: "product", Op: "like", Values: []interface{}{float64(1)}},
+			}},
+			wantErr: "unknown filter operator like",
+		},
+		{
+			name: "eq takes one value",
+			req: QueryRequest{Measures: []string{"units"}, Filters: []QueryFilter{
+				{Dimension: "product", Op: "eq", Values: []interface{}{float64(1), float64(2)}},
+			}},
+			wantErr: "takes exactly one value",
+		},
+		{
+			name: "dimension without a filter column",
+			req: QueryRequest{Measures: []string{"units"}, Filters: []QueryFilter{
+				{Dimension: "day", Op: "in", Values: []interface{}{"2024-01-01"}},
+			}},
+			wantErr: "dimension day cannot be filtered",
+		},
+		{
+			name: "text value on a numeric dimension",
+			req: QueryRequest{Measures: []string{"units"}, Filters: []QueryFilter{
+				{Dimension: "category", Op: "in", Values: []interface{}{"books"}},
+			}},
+			wantErr: "takes positive integer IDs",
+		},
+		{
+			name: "fractional ID",
+			req: QueryRequest{Measures: []string{"units"}, Filters: []QueryFilter{
+				{Dimension: "product", Op: "in", Values: []interface{}{1.5}},
+			}},
+			wantErr: "takes positive integer IDs",
+		},
+		{
+			name: "unknown having comparison",
+			req: QueryRequest{Measures: []string{"units"}, Having: []QueryHaving{
+				{Measure: "units", Op: "ne", Value: 1},
+			}},
+			wantErr: "unknown comparison ne",
+		},
+		{
+			name:    "sort by a column that is not selected",
+			req:     QueryRequest{Measures: []string{"units"}, SortBy: "points"},
+			wantErr: "sort_by must be one of the selected columns",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := compileQuery(tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if !IsQueryError(err) {
+					t.Errorf("err = %v is not a query error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, q)
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Cell kinds decide the number format of a column so finance gets real numbers and dates, not text
+const (
+	cellText = iota
+	cellInt
+	cellDate
+	cellDateTime
+)
+
+type exportColumn struct {
+	Header string
+	Kind   int
+	Width  float64
+}
+
+// exportSheet is a tabular report ready to be written as a worksheet
+type exportSheet struct {
+	Name    string
+	Columns []exportColumn
+	Rows    [][]interface{}
+}
+
+// summaryLine is a label/value pair on the summary sheet
+type summaryLine struct {
+	Label string
+	Value interface{}
+	Kind  int
+}
+
+// buildWorkbook writes a "Summary" sheet followed by the data sheet and returns the xlsx bytes
+func buildWorkbook(title string, summary []summaryLine, data exportSheet) (*bytes.Buffer, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newExportStyles(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Summary sheet (the default sheet is renamed so it stays first)
+	if err := f.SetSheetName("Sheet1", "Summary"); err != nil {
+		return nil, err
+	}
+	f.SetCellValue("Summary", "A1", title)
+	f.SetCellStyle("Summary", "A1", "A1", styles.title)
+	f.SetColWidth("Summary", "A", "A", 24)
+	f.SetColWidth("Summary", "B", "B", 22)
+
+	for i, line := range append(summary, summaryLine{Label: "Generated At", Value: time.Now(), Kind: cellDateTime}) {
+		row := i + 3
+		labelCell, _ := excelize.CoordinatesToCellName(1, row)
+		valueCell, _ := excelize.CoordinatesToCellName(2, row)
+		f.SetCellValue("Summary", labelCell, line.Label)
+		f.SetCellStyle("Summary", labelCell, labelCell, styles.header)
+		f.SetCellValue("Summary", valueCell, line.Value)
+		f.SetCellStyle("Summary", valueCell, valueCell, styles.forKind(line.Kind))
+	}
+
+	// Data sheet
+	if _, err := f.NewSheet(data.Name); err != nil {
+		return nil, err
+	}
+	for col, column := range data.Columns {
+		cell, _ := excelize.CoordinatesToCellName(col+1, 1)
+		name, _ := excelize.ColumnNumberToName(col + 1)
+		f.SetCellValue(data.Name, cell, column.Header)
+		f.SetCellStyle(data.Name, cell, cell, styles.header)
+		if column.Width > 0 {
+			f.SetColWidth(data.Name, name, name, column.Width)
+		}
+	}
+	for i, row := range data.Rows {
+		for col, value := range row {
+			cell, _ := excelize.CoordinatesToCellName(col+1, i+2)
+			f.SetCellValue(data.Name, cell, value)
+			f.SetCellStyle(data.Name, cell, cell, styles.forKind(data.Columns[col].Kind))
+		}
+	}
+
+	if len(data.Columns) > 0 {
+		lastCol, _ := excelize.ColumnNumberToName(len(data.Columns))
+		if err := f.AutoFilter(data.Name, fmt.Sprintf("A1:%s%d", lastCol, len(data.Rows)+1), nil); err != nil {
+			return nil, err
+		}
+		f.SetPanes(data.Name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	}
+
+	return f.WriteToBuffer()
+}
+
+type exportStyles struct {
+	title, header, text, integer, date, dateTime int
+}
+
+func newExportStyles(f *excelize.File) (*exportStyles, error) {
+	var styles exportStyles
+	var err error
+
+	if styles.title, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}}); err != nil {
+		return nil, err
+	}
+	if styles.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"E7E6E6"}},
+	}); err != nil {
+		return nil, err
+	}
+	if styles.text, err = f.NewStyle(&excelize.Style{}); err != nil {
+		return nil, err
+	}
+	if styles.integer, err = f.NewStyle(&excelize.Style{NumFmt: 3}); err != nil { // #,##0
+		return nil, err
+	}
+	dateFormat, dateTimeFormat := "yyyy-mm-dd", "yyyy-mm-dd hh:mm"
+	if styles.date, err = f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return nil, err
+	}
+	if styles.dateTime, err = f.NewStyle(&excelize.Style{CustomNumFmt: &dateTimeFormat}); err != nil {
+		return nil, err
+	}
+	return &styles, nil
+}
+
+func (s *exportStyles) forKind(kind int) int {
+	switch kind {
+	case cellInt:
+		return s.integer
+	case cellDate:
+		return s.date
+	case cellDateTime:
+		return s.dateTime
+	}
+	return s.text
+}
+
+// Exporters per report
+
+func (r *SalesReportResponse) workbook() (*bytes.Buffer, error) {
+	periodColumn := exportColumn{Header: "Period", Kind: cellText, Width: 14}
+	if r.GroupBy == "day" {
+		periodColumn.Kind = cellDate
+	}
+
+	sheet := exportSheet{
+		Name: "Sales",
+		Columns: []exportColumn{
+			periodColumn,
+			{Header: "Orders", Kind: cellInt, Width: 12},
+			{Header: "Units Sold", Kind: cellInt, Width: 12},
+			{Header: "Points Revenue", Kind: cellInt, Width: 16},
+		},
+	}
+	for _, p := range r.Periods {
+		var period interface{} = p.Period
+		if r.GroupBy == "day" {
+			if day, err := time.ParseInLocation(dateLayout, p.Period, time.Local); err == nil {
+				period = day
+			}
+		}
+		sheet.Rows = append(sheet.Rows, []interface{}{period, p.Orders, p.UnitsSold, p.PointsRevenue})
+	}
+
+	summary := append(rangeSummary(r.From, r.To), []summaryLine{
+		{Label: "Grouped By", Value: r.GroupBy, Kind: cellText},
+		{Label: "Total Orders", Value: r.Totals.Orders, Kind: cellInt},
+		{Label: "Total Units Sold", Value: r.Totals.UnitsSold, Kind: cellInt},
+		{Label: "Total Points Revenue", Value: r.Totals.PointsRevenue, Kind: cellInt},
+	}...)
+
+	return buildWorkbook("Sales Report", summary, sheet)
+}
+
+func (r *TopProductsResponse) workbook() (*bytes.Buffer, error) {
+	sheet := exportSheet{
+		Name: "Top Products",
+		Columns: []exportColumn{
+			{Header: "Rank", Kind: cellInt, Width: 8},
+			{Header: "Product ID", Kind: cellInt, Width: 12},
+			{Header: "Product", Kind: cellText, Width: 36},
+			{Header: "Orders", Kind: cellInt, Width: 12},
+			{Header: "Units Sold", Kind: cellInt, Width: 12},
+			{Header: "Points Revenue", Kind: cellInt, Width: 16},
+		},
+	}
+
+	var units, points int64
+	for _, p := range r.Products {
+		sheet.Rows = append(sheet.Rows, []interface{}{p.Rank, p.ProductID, p.ProductName, p.Orders, p.UnitsSold, p.PointsRevenue})
+		units += p.UnitsSold
+		points += p.PointsRevenue
+	}
+
+	summary := append(rangeSummary(r.From, r.To), []summaryLine{
+		{Label: "Category ID", Value: categoryLabel(r.CategoryID), Kind: cellText},
+		{Label: "Ranked By", Value: r.SortBy, Kind: cellText},
+		{Label: "Products Listed", Value: len(r.Products), Kind: cellInt},
+		{Label: "Units Sold (listed)", Value: units, Kind: cellInt},
+		{Label: "Points Revenue (listed)", Value: points, Kind: cellInt},
+	}...)
+
+	return buildWorkbook("Top Products", summary, sheet)
+}
+
+func (r *TopBuyersResponse) workbook() (*bytes.Buffer, error) {
+	sheet := exportSheet{
+		Name: "Top Buyers",
+		Columns: []exportColumn{
+			{Header: "Rank", Kind: cellInt, Width: 8},
+			{Header: "User ID", Kind: cellInt, Width: 10},
+			{Header: "Name", Kind: cellText, Width: 28},
+			{Header: "Email", Kind: cellText, Width: 30},
+			{Header: "NIM/NIP", Kind: cellText, Width: 16},
+			{Header: "Orders", Kind: cellInt, Width: 12},
+			{Header: "Units Bought", Kind: cellInt, Width: 14},
+			{Header: "Points Spent", Kind: cellInt, Width: 14},
+		},
+	}
+
+	var points int64
+	for _, b := range r.Buyers {
+		sheet.Rows = append(sheet.Rows, []interface{}{b.Rank, b.UserID, b.FullName, b.Email, b.NimNip, b.Orders, b.UnitsBought, b.PointsSpent})
+		points += b.PointsSpent
+	}
+
+	summary := append(rangeSummary(r.From, r.To), []summaryLine{
+		{Label: "Category ID", Value: categoryLabel(r.CategoryID), Kind: cellText},
+		{Label: "Buyers Listed", Value: len(r.Buyers), Kind: cellInt},
+		{Label: "Points Spent (listed)", Value: points, Kind: cellInt},
+	}...)
+
+	return buildWorkbook("Top Buyers", summary, sheet)
+}
+
+func rangeSummary(from, to string) []summaryLine {
+	fromDate, _ := time.ParseInLocation(dateLayout, from, time.Local)
+	toDate, _ := time.ParseInLocation(dateLayout, to, time.Local)
+	return []summaryLine{
+		{Label: "From", Value: fromDate, Kind: cellDate},
+		{Label: "To", Value: toDate, Kind: cellDate},
+	}
+}
+
+func categoryLabel(categoryID uint) string {
+	if categoryID == 0 {
+		return "All"
+	}
+	return fmt.Sprintf("%d", categoryID)
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/storage"
+	"wallet-point/utils"
+)
+
+const JobExport = "report.export"
+
+// exportPayload is the job payload of an export: the request and the campus it covers
+type exportPayload struct {
+	CreateExportRequest
+	TenantID uint `json:"tenant_id,omitempty"`
+}
+
+// SetJobQueue enables background xlsx exports kept in files under storage.PrefixExports
+func (s *ReportService) SetJobQueue(queue *jobs.Queue, files storage.Storage) {
+	s.jobQueue = queue
+	s.files = files
+	queue.Register(JobExport, jobs.QueueExports, s.runExport)
+}
+
+// RequestExport queues an export for large ranges that would time out as a download
+// (Admin). The export covers the campus of ctx, like the report endpoints.
+func (s *ReportService) RequestExport(ctx context.Context, req CreateExportRequest, adminID uint) (*jobs.Job, error) {
+	payload := exportPayload{CreateExportRequest: req}
+	payload.TenantID, _ = utils.TenantFromContext(ctx)
+	return s.jobQueue.Enqueue(JobExport, payload, jobs.EnqueueOptions{CreatedBy: &adminID, MaxAttempts: 3})
+}
+
+func (s *ReportService) runExport(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	var payload exportPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	req := payload.CreateExportRequest
+	if payload.TenantID != 0 {
+		ctx = utils.WithTenant(ctx, payload.TenantID)
+	}
+
+	from, to, workbook, err := s.buildExport(ctx, req)
+	if err != nil {
+		if IsBadRequest(err) || err.Error() == "category not found" {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+
+	buf, err := workbook()
+	if err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.xlsx", req.Report, from, to)
+	key := fmt.Sprintf("%s/job%d_%s", storage.PrefixExports, job.ID, filename)
+	if err := s.files.Put(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len()), xlsxContentType); err != nil {
+		return nil, err
+	}
+	job.ResultFile = key
+
+	return ExportResult{Report: req.Report, From: from, To: to, Filename: filename}, nil
+}
+
+// buildExport runs the requested report and returns its resolved period and workbook builder
+func (s *ReportService) buildExport(ctx context.Context, req CreateExportRequest) (string, string, func() (*bytes.Buffer, error), error) {
+	ranking := RankingParams{From: req.From, To: req.To, CategoryID: req.CategoryID, SortBy: req.SortBy, Limit: req.Limit}
+	if ranking.Limit == 0 {
+		ranking.Limit = defaultRankLimit
+	}
+
+	switch req.Report {
+	case "sales":
+		response, err := s.GetSalesReport(ctx, SalesReportParams{From: req.From, To: req.To, GroupBy: req.GroupBy})
+		if err != nil {
+			return "", "", nil, err
+		}
+		return response.From, response.To, response.workbook, nil
+	case "top_products":
+		response, err := s.GetTopProducts(ctx, ranking)
+		if err != nil {
+			return "", "", nil, err
+		}
+		return response.From, response.To, response.workbook, nil
+	case "top_buyers":
+		response, err := s.GetTopBuyers(ctx, ranking)
+		if err != nil {
+			return "", "", nil, err
+		}
+		return response.From, response.To, response.workbook, nil
+	}
+	return "", "", nil, jobs.Permanent(fmt.Errorf("unknown report %q", req.Report))
+}
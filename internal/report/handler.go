@@ -0,0 +1,623 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	service      *ReportService
+	auditService audit.Logger
+}
+
+func NewReportHandler(service *ReportService, auditService audit.Logger) *ReportHandler {
+	return &ReportHandler{service: service, auditService: auditService}
+}
+
+// GetSales handles the sales report
+// @Summary Get sales report
+// @Description Aggregate marketplace sales into units sold, points revenue and order counts per period (Admin only)
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param group_by query string false "Bucket size" Enums(day, week, month) default(day)
+// @Param format query string false "Output format" Enums(json, xlsx) default(json)
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {object} utils.Response{data=SalesReportResponse}
+// @Failure 400 {object} utils.Response
+// @Router /admin/reports/sales [get]
+func (h *ReportHandler) GetSales(c *gin.Context) {
+	if !validFormat(c) {
+		return
+	}
+
+	params := SalesReportParams{
+		From:    c.Query("from"),
+		To:      c.Query("to"),
+		GroupBy: c.DefaultQuery("group_by", "day"),
+	}
+
+	response, err := h.service.GetSalesReport(c.Request.Context(), params)
+	if err != nil {
+		if IsBadRequest(err) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate sales report", err.Error())
+		return
+	}
+
+	if wantsXLSX(c) {
+		sendWorkbook(c, fmt.Sprintf("sales_%s_%s.xlsx", response.From, response.To), response.workbook)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sales report generated successfully", response)
+}
+
+// GetTopProducts handles the top products report
+// @Summary Get top products
+// @Description Rank products by units sold or points revenue over a period (Admin only)
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param category_id query int false "Only products in this category or its sub-categories"
+// @Param sort_by query string false "Ranking measure" Enums(units, points) default(units)
+// @Param limit query int false "Number of products" default(10) minimum(1) maximum(100)
+// @Param format query string false "Output format" Enums(json, xlsx) default(json)
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {object} utils.Response{data=TopProductsResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/reports/top-products [get]
+func (h *ReportHandler) GetTopProducts(c *gin.Context) {
+	if !validFormat(c) {
+		return
+	}
+
+	params, ok := rankingParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetTopProducts(c.Request.Context(), params)
+	if err != nil {
+		rankingError(c, err)
+		return
+	}
+
+	if wantsXLSX(c) {
+		sendWorkbook(c, fmt.Sprintf("top_products_%s_%s.xlsx", response.From, response.To), response.workbook)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Top products retrieved successfully", response)
+}
+
+// GetMarketplaceAnalytics handles the sales analytics of the marketplace
+// @Summary Get marketplace analytics
+// @Description Orders, points revenue, units sold, the average order, the best selling products and a daily series (one row per day, including days without sales) of the successful sales between from and to (Admin only). Orders counts checkouts; the daily and product rows count lines, one per product sold.
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param top query int false "Number of top products" default(10) minimum(1) maximum(50)
+// @Success 200 {object} utils.Response{data=MarketplaceAnalytics}
+// @Failure 400 {object} utils.Response
+// @Router /admin/marketplace/analytics [get]
+func (h *ReportHandler) GetMarketplaceAnalytics(c *gin.Context) {
+	top, ok := utils.QueryInt(c, "top", 10, 1, 50)
+	if !ok {
+		return
+	}
+
+	analytics, err := h.service.GetMarketplaceAnalytics(c.Request.Context(), AnalyticsParams{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+		Top:  top,
+	})
+	if err != nil {
+		if IsBadRequest(err) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve sales analytics", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sales analytics retrieved successfully", analytics)
+}
+
+// GetTopBuyers handles the top buyers report
+// @Summary Get top buyers
+// @Description Rank users by points spent in the marketplace over a period (Admin only)
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param category_id query int false "Only spend on products in this category or its sub-categories"
+// @Param limit query int false "Number of buyers" default(10) minimum(1) maximum(100)
+// @Param format query string false "Output format" Enums(json, xlsx) default(json)
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {object} utils.Response{data=TopBuyersResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/reports/top-buyers [get]
+func (h *ReportHandler) GetTopBuyers(c *gin.Context) {
+	if !validFormat(c) {
+		return
+	}
+
+	params, ok := rankingParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetTopBuyers(c.Request.Context(), params)
+	if err != nil {
+		rankingError(c, err)
+		return
+	}
+
+	if wantsXLSX(c) {
+		sendWorkbook(c, fmt.Sprintf("top_buyers_%s_%s.xlsx", response.From, response.To), response.workbook)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Top buyers retrieved successfully", response)
+}
+
+func rankingParams(c *gin.Context) (RankingParams, bool) {
+	categoryID, _ := strconv.Atoi(c.Query("category_id"))
+	limit, ok := utils.LimitQuery(c, 10)
+	if !ok {
+		return RankingParams{}, false
+	}
+
+	return RankingParams{
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+		CategoryID: uint(categoryID),
+		SortBy:     c.Query("sort_by"),
+		Limit:      limit,
+	}, true
+}
+
+func rankingError(c *gin.Context, err error) {
+	switch {
+	case IsBadRequest(err):
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case err.Error() == "category not found":
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate report", err.Error())
+	}
+}
+
+// validFormat rejects unknown ?format= values before any report is computed
+func validFormat(c *gin.Context) bool {
+	switch c.DefaultQuery("format", "json") {
+	case "json", "xlsx":
+		return true
+	}
+	utils.ErrorResponse(c, http.StatusBadRequest, ErrInvalidFormat.Error(), nil)
+	return false
+}
+
+func wantsXLSX(c *gin.Context) bool {
+	return c.Query("format") == "xlsx"
+}
+
+// sendWorkbook renders an xlsx export as a file download
+func sendWorkbook(c *gin.Context, filename string, build func() (*bytes.Buffer, error)) {
+	buf, err := build()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export report", err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, xlsxContentType, buf.Bytes())
+}
+
+// CreateExport handles queueing an xlsx report export
+// @Summary Queue report export
+// @Description Generate the sales, top products or top buyers xlsx in the background, for ranges too large to download directly. Poll GET /jobs/{id} and fetch the file from GET /jobs/{id}/download (Admin only).
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateExportRequest true "Export"
+// @Success 202 {object} utils.Response{data=jobs.Job}
+// @Failure 400 {object} utils.Response
+// @Router /admin/reports/exports [post]
+func (h *ReportHandler) CreateExport(c *gin.Context) {
+	var req CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	job, err := h.service.RequestExport(c.Request.Context(), req, adminID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to queue export", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Export queued", job)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "EXPORT_REPORT",
+		Entity:    "JOB",
+		EntityID:  job.ID,
+		Details:   fmt.Sprintf("Queued %s report export (%s to %s)", req.Report, req.From, req.To),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// CreateJournal handles queueing an accounting journal export
+// @Summary Queue accounting journal export
+// @Description Generate a double-entry journal (csv) of mission rewards, top-ups, marketplace purchases and adjustments, one balanced entry per day and transaction type, posted to the accounts of ACCOUNTING_ACCOUNTS. Defaults to the previous calendar month. Poll GET /jobs/{id} and fetch the file from GET /jobs/{id}/download (Admin only).
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateJournalRequest false "Period"
+// @Success 202 {object} utils.Response{data=jobs.Job}
+// @Failure 400 {object} utils.Response
+// @Router /admin/reports/journal [post]
+func (h *ReportHandler) CreateJournal(c *gin.Context) {
+	var req CreateJournalRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BindingErrorResponse(c, err)
+			return
+		}
+	}
+
+	adminID := c.GetUint("user_id")
+	job, err := h.service.RequestJournal(c.Request.Context(), &req, adminID)
+	if err != nil {
+		if IsBadRequest(err) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to queue export", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Export queued", job)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "EXPORT_JOURNAL",
+		Entity:    "JOB",
+		EntityID:  job.ID,
+		Details:   fmt.Sprintf("Queued accounting journal export (%s to %s)", req.From, req.To),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetBreakage handles the point breakage report
+// @Summary Get point breakage report
+// @Description Points issued but never redeemed per user cohort and month, for budget sizing (Admin only). Points do not expire yet, so expired is always 0.
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param cohort_by query string false "Cohort grouping" Enums(signup_month, signup_year, role) default(signup_month)
+// @Param role query string false "Only users with this role" Enums(admin, dosen, mahasiswa)
+// @Success 200 {object} utils.Response{data=BreakageReportResponse}
+// @Failure 400 {object} utils.Response
+// @Router /admin/reports/breakage [get]
+func (h *ReportHandler) GetBreakage(c *gin.Context) {
+	params := BreakageParams{
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		CohortBy: c.DefaultQuery("cohort_by", "signup_month"),
+		Role:     c.Query("role"),
+	}
+
+	response, err := h.service.GetBreakageReport(c.Request.Context(), params)
+	if err != nil {
+		if IsBadRequest(err) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate breakage report", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Breakage report generated successfully", response)
+}
+
+// GetCohortRetention handles the point usage retention report
+// @Summary Get cohort retention of point usage
+// @Description For each enrollment (signup) month, the share of users who spent points in the marketplace in each following month (Admin only)
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from_cohort query string false "First enrollment month (YYYY-MM)"
+// @Param to_cohort query string false "Last enrollment month (YYYY-MM), defaults to the current month"
+// @Param months query int false "Months to follow after enrollment (1-24)" default(6)
+// @Param role query string false "Only users with this role" Enums(admin, dosen, mahasiswa)
+// @Success 200 {object} utils.Response{data=RetentionResponse}
+// @Failure 400 {object} utils.Response
+// @Router /admin/reports/retention [get]
+func (h *ReportHandler) GetCohortRetention(c *gin.Context) {
+	months, err := strconv.Atoi(c.DefaultQuery("months", "6"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, ErrInvalidMonths.Error(), nil)
+		return
+	}
+
+	params := RetentionParams{
+		FromCohort: c.Query("from_cohort"),
+		ToCohort:   c.Query("to_cohort"),
+		Months:     months,
+		Role:       c.Query("role"),
+	}
+
+	response, err := h.service.GetCohortRetention(c.Request.Context(), params)
+	if err != nil {
+		if IsBadRequest(err) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate retention report", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Retention report generated successfully", response)
+}
+
+// GetTransactionVolume handles the wallet transaction volume time series
+// @Summary Get transaction volume series
+// @Description Bucketed counts and point sums of wallet transactions with type and status breakdowns, for charting (Admin only)
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param interval query string false "Bucket size; hourly series are limited to 31 days" Enums(hour, day) default(day)
+// @Param type query string false "Only this transaction type" Enums(mission, transfer_in, transfer_out, marketplace, adjustment, topup)
+// @Param status query string false "Only this transaction status" Enums(success, failed, pending)
+// @Success 200 {object} utils.Response{data=VolumeResponse}
+// @Failure 400 {object} utils.Response
+// @Router /admin/reports/transactions/volume [get]
+func (h *ReportHandler) GetTransactionVolume(c *gin.Context) {
+	status, ok := utils.QueryEnum(c, "status", "transaction_status")
+	if !ok {
+		return
+	}
+
+	params := VolumeParams{
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Interval: c.DefaultQuery("interval", "day"),
+		Type:     c.Query("type"),
+		Status:   status,
+	}
+
+	response, err := h.service.GetTransactionVolume(c.Request.Context(), params)
+	if err != nil {
+		if IsBadRequest(err) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve transaction volume", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transaction volume retrieved successfully", response)
+}
+
+// GetCategoryBreakdown handles the category sales breakdown
+// @Summary Get category sales breakdown
+// @Description Revenue and units per category with nested sub-categories and drill-down links to the product ranking (Admin only)
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} utils.Response{data=CategoryBreakdownResponse}
+// @Failure 400 {object} utils.Response
+// @Router /admin/reports/categories [get]
+func (h *ReportHandler) GetCategoryBreakdown(c *gin.Context) {
+	response, err := h.service.GetCategoryBreakdown(c.Request.Context(), c.Query("from"), c.Query("to"))
+	if err != nil {
+		if IsBadRequest(err) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate category breakdown", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category breakdown generated successfully", response)
+}
+
+// RunQuery handles the custom report builder
+// @Summary Run a custom report
+// @Description Aggregate marketplace sales by chosen dimensions (product, category, faculty, batch, day, week, month) and measures (units, points, orders, buyers) with filters. Only whitelisted fields are compiled to SQL. (Admin only)
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body QueryRequest true "Report query"
+// @Success 200 {object} utils.Response{data=QueryResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/reports/query [post]
+func (h *ReportHandler) RunQuery(c *gin.Context) {
+	var req QueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	response, err := h.service.RunQuery(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case IsQueryError(err), IsBadRequest(err):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		case err.Error() == "category not found":
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to run report", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Report generated successfully", response)
+}
+
+// GetSubscriptions handles listing the admin's report email subscriptions
+// @Summary Get my report subscriptions
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]ReportSubscription}
+// @Router /admin/reports/subscriptions [get]
+func (h *ReportHandler) GetSubscriptions(c *gin.Context) {
+	subscriptions, err := h.service.GetSubscriptions(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve subscriptions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Subscriptions retrieved successfully", subscriptions)
+}
+
+// CreateSubscription handles subscribing to a scheduled report email
+// @Summary Subscribe to a report email
+// @Description Receive the sales summary, low stock or flagged transactions report by email every week (Monday 07:00) or month (1st, 07:00)
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateSubscriptionRequest true "Subscription"
+// @Success 201 {object} utils.Response{data=ReportSubscription}
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/reports/subscriptions [post]
+func (h *ReportHandler) CreateSubscription(c *gin.Context) {
+	var req CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	subscription, err := h.service.CreateSubscription(c.Request.Context(), userID, req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "subscription already exists" {
+			statusCode = http.StatusConflict
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Subscribed to report", subscription)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "SUBSCRIBE_REPORT",
+		Entity:    "REPORT_SUBSCRIPTION",
+		EntityID:  subscription.ID,
+		Details:   fmt.Sprintf("Subscribed to %s %s report email", subscription.Frequency, subscription.Report),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteSubscription handles unsubscribing from a report email
+// @Summary Unsubscribe from a report email
+// @Tags Admin - Reports
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/reports/subscriptions/{id} [delete]
+func (h *ReportHandler) DeleteSubscription(c *gin.Context) {
+	subscriptionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid subscription ID", nil)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service.DeleteSubscription(c.Request.Context(), userID, uint(subscriptionID)); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "subscription not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Unsubscribed from report", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "UNSUBSCRIBE_REPORT",
+		Entity:    "REPORT_SUBSCRIPTION",
+		EntityID:  uint(subscriptionID),
+		Details:   fmt.Sprintf("Removed report subscription #%d", subscriptionID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetMyAnalytics handles the current user's spending analytics
+// @Summary Get my spending analytics
+// @Description Spend by category and month, largest purchases, and points earned vs spent, computed from the user's ledger
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param months query int false "Number of months including the current one (1-24)" default(12)
+// @Success 200 {object} utils.Response{data=UserAnalytics}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/me/analytics [get]
+func (h *ReportHandler) GetMyAnalytics(c *gin.Context) {
+	months, err := strconv.Atoi(c.DefaultQuery("months", "12"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, ErrInvalidMonths.Error(), nil)
+		return
+	}
+
+	response, err := h.service.GetUserAnalytics(c.Request.Context(), c.GetUint("user_id"), months)
+	if err != nil {
+		switch {
+		case IsBadRequest(err):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		case err.Error() == "wallet not found":
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to compute analytics", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Analytics retrieved successfully", response)
+}
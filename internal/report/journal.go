@@ -0,0 +1,180 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/storage"
+	"wallet-point/utils"
+)
+
+const (
+	JobJournal = "report.journal"
+
+	csvContentType = "text/csv"
+
+	// JournalLiabilityAccount is the mapping key of the points liability account;
+	// the other keys are wallet transaction types
+	JournalLiabilityAccount = "liability"
+)
+
+// JournalTypes are the wallet transaction types posted to the journal. Transfers
+// move points between two wallets, so both legs hit the liability account and
+// they are left out.
+var JournalTypes = []string{"mission", "topup", "marketplace", "adjustment"}
+
+// journalLabels describe an entry per transaction type and wallet direction
+var journalLabels = map[string]map[string]string{
+	"mission":     {"credit": "Mission rewards", "debit": "Mission reward reversals"},
+	"topup":       {"credit": "Point top-ups", "debit": "Point top-up reversals"},
+	"marketplace": {"credit": "Marketplace refunds", "debit": "Marketplace purchases"},
+	"adjustment":  {"credit": "Admin adjustments (credit)", "debit": "Admin adjustments (debit)"},
+}
+
+// JournalConfig maps wallet activity onto the chart of accounts of the accounting software
+type JournalConfig struct {
+	Accounts   map[string]string // account code of "liability" and of every type in JournalTypes
+	PointValue int               // currency units per point
+}
+
+// journalPayload is the job payload of a journal export
+type journalPayload struct {
+	CreateJournalRequest
+	TenantID uint `json:"tenant_id,omitempty"`
+}
+
+// EnableJournal enables double-entry journal exports; call after SetJobQueue
+func (s *ReportService) EnableJournal(cfg JournalConfig) {
+	s.journal = cfg
+	s.jobQueue.Register(JobJournal, jobs.QueueExports, s.runJournal)
+}
+
+// RequestJournal queues a journal export of the campus of ctx (Admin). Without
+// dates it covers the previous calendar month; req is filled with the resolved period.
+func (s *ReportService) RequestJournal(ctx context.Context, req *CreateJournalRequest, adminID uint) (*jobs.Job, error) {
+	period, err := resolveJournalRange(*req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req.From = period.Start.Format(dateLayout)
+	req.To = period.End.AddDate(0, 0, -1).Format(dateLayout)
+
+	payload := journalPayload{CreateJournalRequest: *req}
+	payload.TenantID, _ = utils.TenantFromContext(ctx)
+	return s.jobQueue.Enqueue(JobJournal, payload, jobs.EnqueueOptions{CreatedBy: &adminID, MaxAttempts: 3})
+}
+
+// QueueMonthlyJournals queues last month's journal of every campus, for the monthly cron job
+func (s *ReportService) QueueMonthlyJournals(tenantIDs []uint, now time.Time) (int, error) {
+	period := subscriptionPeriod("monthly", now)
+	req := CreateJournalRequest{
+		From: period.Start.Format(dateLayout),
+		To:   period.End.AddDate(0, 0, -1).Format(dateLayout),
+	}
+
+	queued := 0
+	for _, tenantID := range tenantIDs {
+		payload := journalPayload{CreateJournalRequest: req, TenantID: tenantID}
+		if _, err := s.jobQueue.Enqueue(JobJournal, payload, jobs.EnqueueOptions{MaxAttempts: 3}); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+func (s *ReportService) runJournal(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	var payload journalPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	if payload.TenantID != 0 {
+		ctx = utils.WithTenant(ctx, payload.TenantID)
+	}
+
+	period, err := resolveRange(payload.From, payload.To)
+	if err != nil {
+		return nil, jobs.Permanent(err)
+	}
+
+	activity, err := s.repo.JournalActivity(ctx, period, JournalTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := JournalResult{
+		From: period.Start.Format(dateLayout),
+		To:   period.End.AddDate(0, 0, -1).Format(dateLayout),
+	}
+	buf, err := s.writeJournal(activity, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Filename = fmt.Sprintf("journal_%s_%s.csv", result.From, result.To)
+	key := fmt.Sprintf("%s/job%d_%s", storage.PrefixExports, job.ID, result.Filename)
+	if err := s.files.Put(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len()), csvContentType); err != nil {
+		return nil, err
+	}
+	job.ResultFile = key
+
+	return result, nil
+}
+
+// writeJournal renders one balanced two-line entry per day, transaction type and
+// direction. Credits to wallets raise the points liability; debits lower it.
+func (s *ReportService) writeJournal(activity []journalActivity, result *JournalResult) (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	if err := w.Write([]string{"journal_no", "date", "account", "description", "debit", "credit"}); err != nil {
+		return nil, err
+	}
+
+	liability := s.journal.Accounts[JournalLiabilityAccount]
+	day, seq := "", 0
+	for _, row := range activity {
+		if row.Day != day {
+			day, seq = row.Day, 0
+		}
+		seq++
+
+		contra := s.journal.Accounts[row.Type]
+		debit, credit := contra, liability
+		if row.Direction == "debit" {
+			debit, credit = liability, contra
+		}
+
+		number := fmt.Sprintf("WP-%s-%03d", strings.ReplaceAll(row.Day, "-", ""), seq)
+		description := fmt.Sprintf("%s, %d transactions", journalLabels[row.Type][row.Direction], row.Transactions)
+		value := row.Amount * int64(s.journal.PointValue)
+		amount := strconv.FormatInt(value, 10)
+
+		if err := w.Write([]string{number, row.Day, debit, description, amount, "0"}); err != nil {
+			return nil, err
+		}
+		if err := w.Write([]string{number, row.Day, credit, description, "0", amount}); err != nil {
+			return nil, err
+		}
+
+		result.Entries++
+		result.Transactions += row.Transactions
+		result.TotalDebit += value
+	}
+
+	w.Flush()
+	return buf, w.Error()
+}
+
+// resolveJournalRange defaults a journal to the calendar month before now
+func resolveJournalRange(req CreateJournalRequest, now time.Time) (dateRange, error) {
+	if req.From == "" && req.To == "" {
+		return subscriptionPeriod("monthly", now), nil
+	}
+	return resolveRange(req.From, req.To)
+}
@@ -0,0 +1,510 @@
+package report
+
+import (
+	"time"
+)
+
+// SalesReportParams holds the raw query of a sales report request
+type SalesReportParams struct {
+	From    string // YYYY-MM-DD, inclusive
+	To      string // YYYY-MM-DD, inclusive
+	GroupBy string // day, week, month
+}
+
+// SalesPeriod is one bucket of the sales report
+type SalesPeriod struct {
+	Period        string `json:"period"`
+	Orders        int64  `json:"orders"`
+	UnitsSold     int64  `json:"units_sold"`
+	PointsRevenue int64  `json:"points_revenue"`
+}
+
+type SalesTotals struct {
+	Orders        int64 `json:"orders"`
+	UnitsSold     int64 `json:"units_sold"`
+	PointsRevenue int64 `json:"points_revenue"`
+}
+
+type SalesReportResponse struct {
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	GroupBy string        `json:"group_by"`
+	Periods []SalesPeriod `json:"periods"`
+	Totals  SalesTotals   `json:"totals"`
+}
+
+// dateRange is a resolved half-open [Start, End) interval
+type dateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// RankingParams holds the raw query of a top products / top buyers request
+type RankingParams struct {
+	From       string
+	To         string
+	CategoryID uint   // 0 = all categories; includes sub-categories
+	SortBy     string // units, points (top products only)
+	Limit      int
+}
+
+type TopProduct struct {
+	Rank          int    `json:"rank" gorm:"-"`
+	ProductID     uint   `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	Orders        int64  `json:"orders"`
+	UnitsSold     int64  `json:"units_sold"`
+	PointsRevenue int64  `json:"points_revenue"`
+}
+
+type TopBuyer struct {
+	Rank        int    `json:"rank" gorm:"-"`
+	UserID      uint   `json:"user_id"`
+	FullName    string `json:"full_name"`
+	Email       string `json:"email"`
+	NimNip      string `json:"nim_nip"`
+	Orders      int64  `json:"orders"`
+	UnitsBought int64  `json:"units_bought"`
+	PointsSpent int64  `json:"points_spent"`
+}
+
+type TopProductsResponse struct {
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	CategoryID uint         `json:"category_id,omitempty"`
+	SortBy     string       `json:"sort_by"`
+	Products   []TopProduct `json:"products"`
+}
+
+// AnalyticsParams are the inclusive YYYY-MM-DD dates of the marketplace
+// analytics and the number of top products
+type AnalyticsParams struct {
+	From string
+	To   string
+	Top  int
+}
+
+// MarketplaceAnalytics sums the successful sales of a period. Orders counts
+// checkouts, so a cart of three products is one order; the daily and product
+// rows count lines, one per product sold.
+type MarketplaceAnalytics struct {
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+	Orders        int64          `json:"orders"`
+	Lines         int64          `json:"lines"`
+	UnitsSold     int64          `json:"units_sold"`
+	PointsRevenue int64          `json:"points_revenue"`
+	AverageOrder  int64          `json:"average_order"` // points revenue per order
+	TopProducts   []ProductSales `json:"top_products"`
+	Daily         []DailySales   `json:"daily"`
+}
+
+type ProductSales struct {
+	Rank          int    `json:"rank"`
+	ProductID     uint   `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	Lines         int64  `json:"lines"`
+	UnitsSold     int64  `json:"units_sold"`
+	PointsRevenue int64  `json:"points_revenue"`
+}
+
+type DailySales struct {
+	Date          string `json:"date"`
+	Lines         int64  `json:"lines"`
+	UnitsSold     int64  `json:"units_sold"`
+	PointsRevenue int64  `json:"points_revenue"`
+}
+
+type TopBuyersResponse struct {
+	From       string     `json:"from"`
+	To         string     `json:"to"`
+	CategoryID uint       `json:"category_id,omitempty"`
+	Buyers     []TopBuyer `json:"buyers"`
+}
+
+// DailyProductSales is the nightly rollup of successful marketplace transactions per product
+type DailyProductSales struct {
+	Date          time.Time `json:"date" gorm:"type:date;primaryKey"`
+	ProductID     uint      `json:"product_id" gorm:"primaryKey;index"`
+	Orders        int64     `json:"orders" gorm:"not null"`
+	UnitsSold     int64     `json:"units_sold" gorm:"not null"`
+	PointsRevenue int64     `json:"points_revenue" gorm:"not null"`
+}
+
+func (DailyProductSales) TableName() string {
+	return "daily_product_sales"
+}
+
+// DailyUserSpend is the nightly rollup of successful marketplace transactions per buyer
+type DailyUserSpend struct {
+	Date        time.Time `json:"date" gorm:"type:date;primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"primaryKey;index"`
+	Orders      int64     `json:"orders" gorm:"not null"`
+	UnitsBought int64     `json:"units_bought" gorm:"not null"`
+	PointsSpent int64     `json:"points_spent" gorm:"not null"`
+}
+
+func (DailyUserSpend) TableName() string {
+	return "daily_user_spend"
+}
+
+// AggregationRun records that a day has been rolled up; the latest date is the summary watermark
+type AggregationRun struct {
+	Date        time.Time `json:"date" gorm:"type:date;primaryKey"`
+	ProductRows int64     `json:"product_rows"`
+	UserRows    int64     `json:"user_rows"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+func (AggregationRun) TableName() string {
+	return "report_aggregation_runs"
+}
+
+// UserAnalytics is a student's own spending overview, computed from their ledger
+type UserAnalytics struct {
+	From             string            `json:"from"`
+	To               string            `json:"to"`
+	PointsEarned     int64             `json:"points_earned"`
+	PointsSpent      int64             `json:"points_spent"`
+	NetPoints        int64             `json:"net_points"`
+	Monthly          []MonthlyFlow     `json:"monthly"`
+	SpendByCategory  []CategorySpend   `json:"spend_by_category"`
+	SpendByType      []TypeSpend       `json:"spend_by_type"`
+	LargestPurchases []LargestPurchase `json:"largest_purchases"`
+}
+
+// MonthlyFlow is points earned vs spent in one month
+type MonthlyFlow struct {
+	Month  string `json:"month"`
+	Earned int64  `json:"earned"`
+	Spent  int64  `json:"spent"`
+}
+
+// CategorySpend is marketplace spend attributed to a product's primary (lowest ID) category
+type CategorySpend struct {
+	CategoryID   *uint  `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Orders       int64  `json:"orders"`
+	PointsSpent  int64  `json:"points_spent"`
+}
+
+// TypeSpend is ledger debits grouped by transaction type (marketplace, transfer_out, ...)
+type TypeSpend struct {
+	Type        string `json:"type"`
+	Count       int64  `json:"count"`
+	PointsSpent int64  `json:"points_spent"`
+}
+
+type LargestPurchase struct {
+	TransactionID uint      `json:"transaction_id"`
+	ProductID     uint      `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	Quantity      int       `json:"quantity"`
+	TotalAmount   int       `json:"total_amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BreakageParams holds the raw query of a point breakage request
+type BreakageParams struct {
+	From     string
+	To       string
+	CohortBy string // signup_month, signup_year, role
+	Role     string // optional user role filter
+}
+
+// BreakagePeriod is points issued vs redeemed by a cohort in one month
+type BreakagePeriod struct {
+	Period   string `json:"period"`
+	Issued   int64  `json:"issued"`
+	Redeemed int64  `json:"redeemed"`
+	Unspent  int64  `json:"unspent"`
+}
+
+// BreakageCohort summarizes points issued but never spent by one cohort of users
+type BreakageCohort struct {
+	Cohort             string           `json:"cohort"`
+	Users              int64            `json:"users"`
+	Issued             int64            `json:"issued"`
+	Redeemed           int64            `json:"redeemed"`
+	Unspent            int64            `json:"unspent"`
+	BreakageRate       float64          `json:"breakage_rate"` // Unspent / Issued, 0..1
+	Expired            int64            `json:"expired"`
+	OutstandingBalance int64            `json:"outstanding_balance"` // Current wallet balances of the cohort
+	Periods            []BreakagePeriod `json:"periods"`
+}
+
+type BreakageReportResponse struct {
+	From          string           `json:"from"`
+	To            string           `json:"to"`
+	CohortBy      string           `json:"cohort_by"`
+	ExpiryTracked bool             `json:"expiry_tracked"` // Points never expire yet, so Expired is always 0
+	Cohorts       []BreakageCohort `json:"cohorts"`
+	Totals        BreakageCohort   `json:"totals"`
+}
+
+type breakageFlowRow struct {
+	Cohort   string
+	Period   string
+	Issued   int64
+	Redeemed int64
+}
+
+type breakageBalanceRow struct {
+	Cohort  string
+	Users   int64
+	Balance int64
+}
+
+// VolumeParams holds the raw query of a transaction volume request
+type VolumeParams struct {
+	From     string
+	To       string
+	Interval string // hour, day
+	Type     string // optional wallet transaction type filter
+	Status   string // optional wallet transaction status filter
+}
+
+// VolumeBreakdown is the count and points of one type or status inside a bucket
+type VolumeBreakdown struct {
+	Count  int64 `json:"count"`
+	Amount int64 `json:"amount"`
+}
+
+// VolumeBucket is one point of the transaction volume time series
+type VolumeBucket struct {
+	Bucket   string                     `json:"bucket"`
+	Count    int64                      `json:"count"`
+	Amount   int64                      `json:"amount"`
+	ByType   map[string]VolumeBreakdown `json:"by_type"`
+	ByStatus map[string]VolumeBreakdown `json:"by_status"`
+}
+
+type VolumeResponse struct {
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Interval string         `json:"interval"`
+	Buckets  []VolumeBucket `json:"buckets"`
+}
+
+type volumeRow struct {
+	Bucket string
+	Type   string
+	Status string
+	Count  int64
+	Amount int64
+}
+
+// ReportSubscription makes the scheduler email a report to an admin every week or month
+type ReportSubscription struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_report_subscription"`
+	Report     string     `json:"report" gorm:"type:enum('sales_summary','low_stock','flagged_transactions');not null;uniqueIndex:idx_report_subscription"`
+	Frequency  string     `json:"frequency" gorm:"type:enum('weekly','monthly');not null;uniqueIndex:idx_report_subscription"`
+	NextRunAt  time.Time  `json:"next_run_at" gorm:"index"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	LastError  string     `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (ReportSubscription) TableName() string {
+	return "report_subscriptions"
+}
+
+// dueSubscription is a subscription to send with the campus its report covers
+type dueSubscription struct {
+	ReportSubscription
+	TenantID uint
+}
+
+type CreateSubscriptionRequest struct {
+	Report    string `json:"report" binding:"required,oneof=sales_summary low_stock flagged_transactions"`
+	Frequency string `json:"frequency" binding:"required,oneof=weekly monthly"`
+}
+
+// LowStockItem is an unresolved stock alert listed in the low stock email
+type LowStockItem struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Stock       int    `json:"stock"`
+	Threshold   int    `json:"threshold"`
+	Status      string `json:"status"`
+}
+
+// FlaggedTransaction is a failed or unusually large wallet transaction
+type FlaggedTransaction struct {
+	ID        uint      `json:"id"`
+	UserName  string    `json:"user_name"`
+	NimNip    string    `json:"nim_nip"`
+	Type      string    `json:"type"`
+	Direction string    `json:"direction"`
+	Amount    int       `json:"amount"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// QueryRequest is a custom report built from whitelisted dimensions and measures
+type QueryRequest struct {
+	Dimensions []string      `json:"dimensions" binding:"max=3"`
+	Measures   []string      `json:"measures" binding:"required,min=1"`
+	Filters    []QueryFilter `json:"filters" binding:"dive"`
+	Having     []QueryHaving `json:"having" binding:"dive"`
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	SortBy     string        `json:"sort_by"`
+	SortDesc   *bool         `json:"sort_desc"`
+	Limit      int           `json:"limit"`
+}
+
+// QueryFilter restricts a dimension, e.g. {"dimension":"category","op":"in","values":[3,4]}
+type QueryFilter struct {
+	Dimension string        `json:"dimension" binding:"required"`
+	Op        string        `json:"op" binding:"required,oneof=eq neq in not_in"`
+	Values    []interface{} `json:"values" binding:"required,min=1"`
+}
+
+// QueryHaving restricts an aggregated measure, e.g. {"measure":"units","op":"gte","value":10}
+type QueryHaving struct {
+	Measure string `json:"measure" binding:"required"`
+	Op      string `json:"op" binding:"required,oneof=eq gt gte lt lte"`
+	Value   int64  `json:"value"`
+}
+
+type QueryColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // integer, string
+}
+
+type QueryResponse struct {
+	From    string                   `json:"from"`
+	To      string                   `json:"to"`
+	Columns []QueryColumn            `json:"columns"`
+	Rows    []map[string]interface{} `json:"rows"`
+}
+
+// CategorySales is one node of the category breakdown; figures include all sub-categories
+type CategorySales struct {
+	CategoryID    *uint           `json:"category_id"`
+	Name          string          `json:"name"`
+	ParentID      *uint           `json:"parent_id"`
+	Orders        int64           `json:"orders"`
+	UnitsSold     int64           `json:"units_sold"`
+	PointsRevenue int64           `json:"points_revenue"`
+	RevenueShare  float64         `json:"revenue_share"` // of total revenue in the period, 0..1
+	ProductsURL   string          `json:"products_url"`  // drill-down into the product ranking
+	Children      []CategorySales `json:"children"`
+}
+
+type CategoryBreakdownResponse struct {
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Categories []CategorySales `json:"categories"`
+	// Products without any category; categories can overlap, so only this and Totals add up exactly
+	Uncategorized CategorySales `json:"uncategorized"`
+	Totals        SalesTotals   `json:"totals"`
+}
+
+type productSalesRow struct {
+	ProductID     uint
+	Orders        int64
+	UnitsSold     int64
+	PointsRevenue int64
+}
+
+type categoryRow struct {
+	ID       uint
+	Name     string
+	ParentID *uint
+}
+
+type productCategoryRow struct {
+	ProductID  uint
+	CategoryID uint
+}
+
+// RetentionParams holds the raw query of a cohort retention request
+type RetentionParams struct {
+	FromCohort string // YYYY-MM, first enrollment month
+	ToCohort   string // YYYY-MM, last enrollment month
+	Months     int    // number of months after enrollment to follow
+	Role       string
+}
+
+// RetentionCell is the share of a cohort that spent points N months after enrolling
+type RetentionCell struct {
+	MonthOffset int     `json:"month_offset"` // 0 = enrollment month
+	Month       string  `json:"month"`
+	ActiveUsers int64   `json:"active_users"`
+	Rate        float64 `json:"rate"` // ActiveUsers / cohort size, 0..1
+}
+
+type RetentionCohort struct {
+	Cohort string          `json:"cohort"` // enrollment month
+	Users  int64           `json:"users"`
+	Months []RetentionCell `json:"months"`
+}
+
+type RetentionResponse struct {
+	FromCohort string            `json:"from_cohort"`
+	ToCohort   string            `json:"to_cohort"`
+	Role       string            `json:"role,omitempty"`
+	Cohorts    []RetentionCohort `json:"cohorts"`
+}
+
+type cohortSizeRow struct {
+	Cohort string
+	Users  int64
+}
+
+type retentionRow struct {
+	Cohort      string
+	MonthOffset int
+	ActiveUsers int64
+}
+
+// CreateExportRequest queues an xlsx export; the fields mirror the query
+// parameters of the corresponding report endpoint
+type CreateExportRequest struct {
+	Report     string `json:"report" binding:"required,oneof=sales top_products top_buyers"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	GroupBy    string `json:"group_by" binding:"omitempty,oneof=day week month"`
+	CategoryID uint   `json:"category_id"`
+	SortBy     string `json:"sort_by" binding:"omitempty,oneof=units points"`
+	Limit      int    `json:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ExportResult is stored as the result of an export job; the file is fetched from /jobs/{id}/download
+type ExportResult struct {
+	Report   string `json:"report"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Filename string `json:"filename"`
+}
+
+// CreateJournalRequest queues a double-entry journal export; without dates it
+// covers the previous calendar month
+type CreateJournalRequest struct {
+	From string `json:"from"` // YYYY-MM-DD, inclusive
+	To   string `json:"to"`   // YYYY-MM-DD, inclusive
+}
+
+// JournalResult is stored as the result of a journal job; the csv is fetched from /jobs/{id}/download
+type JournalResult struct {
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Filename     string `json:"filename"`
+	Entries      int    `json:"entries"`
+	Transactions int64  `json:"transactions"`
+	TotalDebit   int64  `json:"total_debit"` // equals the total credit, in currency units
+}
+
+// journalActivity is the sum of successful wallet transactions of one day, type and direction
+type journalActivity struct {
+	Day          string
+	Type         string
+	Direction    string
+	Amount       int64
+	Transactions int64
+}
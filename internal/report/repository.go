@@ -0,0 +1,568 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// periodFormats maps a grouping to the MySQL DATE_FORMAT pattern of its bucket label
+var periodFormats = map[string]string{
+	"day":   "%Y-%m-%d",
+	"week":  "%x-W%v", // ISO year and week
+	"month": "%Y-%m",
+}
+
+type ReportRepository struct {
+	db      *gorm.DB
+	replica *gorm.DB // report queries; the aggregator and subscriptions stay on db
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db, replica: utils.ReadReplica(db)}
+}
+
+// productSales is the per-product sales source of the reports: rolled-up days before cutoff
+// come from daily_product_sales, anything after it is read from the raw transactions
+func (r *ReportRepository) productSales(ctx context.Context, period dateRange, cutoff time.Time) *gorm.DB {
+	summaryEnd, rawStart := splitAt(period, cutoff)
+
+	tenant := utils.ScopeTenantOwner(ctx, "product_id", "products")
+	summary := r.replica.WithContext(ctx).Table("daily_product_sales").
+		Select("date as day, product_id, orders, units_sold, points_revenue").
+		Where("date >= ? AND date < ?", period.Start, summaryEnd).
+		Scopes(tenant)
+	raw := r.replica.WithContext(ctx).Table("marketplace_transactions").
+		Select("DATE(created_at) as day, product_id, 1 as orders, quantity as units_sold, total_amount as points_revenue").
+		Where("status = ? AND created_at >= ? AND created_at < ?", "success", rawStart, period.End).
+		Scopes(tenant)
+
+	return r.replica.WithContext(ctx).Table("(? UNION ALL ?) as src", summary, raw)
+}
+
+// userSpend is the per-buyer counterpart of productSales
+func (r *ReportRepository) userSpend(ctx context.Context, period dateRange, cutoff time.Time) *gorm.DB {
+	summaryEnd, rawStart := splitAt(period, cutoff)
+
+	summary := r.replica.WithContext(ctx).Table("daily_user_spend").
+		Select("user_id, orders, units_bought, points_spent").
+		Where("date >= ? AND date < ?", period.Start, summaryEnd).
+		Scopes(utils.ScopeTenantOwner(ctx, "user_id", "users"))
+	raw := r.replica.WithContext(ctx).Table("marketplace_transactions mt").
+		Select("w.user_id, 1 as orders, mt.quantity as units_bought, mt.total_amount as points_spent").
+		Joins("JOIN wallets w ON w.id = mt.wallet_id").
+		Where("mt.status = ? AND mt.created_at >= ? AND mt.created_at < ?", "success", rawStart, period.End).
+		Scopes(utils.ScopeTenantOwner(ctx, "w.user_id", "users"))
+
+	return r.replica.WithContext(ctx).Table("(? UNION ALL ?) as src", summary, raw)
+}
+
+// splitAt clamps cutoff into the period, returning the end of the summary part and the start of the raw part
+func splitAt(period dateRange, cutoff time.Time) (time.Time, time.Time) {
+	if cutoff.Before(period.Start) {
+		cutoff = period.Start
+	}
+	if cutoff.After(period.End) {
+		cutoff = period.End
+	}
+	return cutoff, cutoff
+}
+
+// SalesByPeriod aggregates successful marketplace sales into buckets in SQL
+func (r *ReportRepository) SalesByPeriod(ctx context.Context, groupBy string, period dateRange, cutoff time.Time) ([]SalesPeriod, error) {
+	var rows []SalesPeriod
+	bucket := "DATE_FORMAT(src.day, '" + periodFormats[groupBy] + "')"
+
+	err := r.productSales(ctx, period, cutoff).
+		Select(bucket + " as period, COALESCE(SUM(src.orders), 0) as orders, COALESCE(SUM(src.units_sold), 0) as units_sold, COALESCE(SUM(src.points_revenue), 0) as points_revenue").
+		Group("period").
+		Order("period ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// TopProducts ranks products by units sold or points revenue
+func (r *ReportRepository) TopProducts(ctx context.Context, period dateRange, cutoff time.Time, categoryIDs []uint, sortBy string, limit int) ([]TopProduct, error) {
+	var rows []TopProduct
+	orderBy := "units_sold DESC, points_revenue DESC"
+	if sortBy == "points" {
+		orderBy = "points_revenue DESC, units_sold DESC"
+	}
+
+	query := r.productSales(ctx, period, cutoff).
+		Select("src.product_id, p.name as product_name, SUM(src.orders) as orders, SUM(src.units_sold) as units_sold, SUM(src.points_revenue) as points_revenue").
+		Joins("JOIN products p ON p.id = src.product_id")
+	query = filterByCategories(query, "src.product_id", categoryIDs)
+
+	err := query.Group("src.product_id, p.name").
+		Order(orderBy).
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CountOrders counts the checkouts behind the successful sales of a period: the
+// lines of an order count once, and a sale without an order (placed before
+// orders were recorded) is its own order. The rollups have no order dimension,
+// so it reads the raw transactions.
+func (r *ReportRepository) CountOrders(ctx context.Context, period dateRange) (int64, error) {
+	var count int64
+	err := r.replica.WithContext(ctx).Table("marketplace_transactions mt").
+		Select("COUNT(DISTINCT oi.order_id) + COALESCE(SUM(oi.order_id IS NULL), 0)").
+		Joins("LEFT JOIN order_items oi ON oi.transaction_id = mt.id").
+		Where("mt.status = ? AND mt.created_at >= ? AND mt.created_at < ?", "success", period.Start, period.End).
+		Scopes(utils.ScopeTenantOwner(ctx, "mt.product_id", "products")).
+		Scan(&count).Error
+	return count, err
+}
+
+// TopBuyers ranks users by points spent in the marketplace.
+// The per-user rollup has no product dimension, so category-filtered rankings read the raw transactions.
+func (r *ReportRepository) TopBuyers(ctx context.Context, period dateRange, cutoff time.Time, categoryIDs []uint, limit int) ([]TopBuyer, error) {
+	var rows []TopBuyer
+
+	var query *gorm.DB
+	if len(categoryIDs) == 0 {
+		query = r.userSpend(ctx, period, cutoff)
+	} else {
+		raw := r.replica.WithContext(ctx).Table("marketplace_transactions mt").
+			Select("w.user_id, 1 as orders, mt.quantity as units_bought, mt.total_amount as points_spent").
+			Joins("JOIN wallets w ON w.id = mt.wallet_id").
+			Where("mt.status = ? AND mt.created_at >= ? AND mt.created_at < ?", "success", period.Start, period.End).
+			Scopes(utils.ScopeTenantOwner(ctx, "w.user_id", "users"))
+		raw = filterByCategories(raw, "mt.product_id", categoryIDs)
+		query = r.replica.WithContext(ctx).Table("(?) as src", raw)
+	}
+
+	err := query.
+		Select("u.id as user_id, u.full_name, u.email, u.nim_nip, SUM(src.orders) as orders, SUM(src.units_bought) as units_bought, SUM(src.points_spent) as points_spent").
+		Joins("JOIN users u ON u.id = src.user_id").
+		Group("u.id, u.full_name, u.email, u.nim_nip").
+		Order("points_spent DESC, units_bought DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CategoryWithDescendants returns the category ID together with all of its sub-category IDs
+func (r *ReportRepository) CategoryWithDescendants(ctx context.Context, categoryID uint) ([]uint, error) {
+	var categories []struct {
+		ID       uint
+		ParentID *uint
+	}
+	err := r.replica.WithContext(ctx).Table("categories").Select("id, parent_id").
+		Scopes(utils.ScopeTenant(ctx, "tenant_id")).
+		Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]uint)
+	found := false
+	for _, c := range categories {
+		if c.ID == categoryID {
+			found = true
+		}
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+	if !found {
+		return nil, errors.New("category not found")
+	}
+
+	ids := []uint{categoryID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
+
+// filterByCategories restricts the product column to products in the given categories
+func filterByCategories(query *gorm.DB, column string, categoryIDs []uint) *gorm.DB {
+	if len(categoryIDs) == 0 {
+		return query
+	}
+	return query.Where(column+" IN (SELECT product_id FROM product_categories WHERE category_id IN ?)", categoryIDs)
+}
+
+// Aggregation
+
+// LastAggregatedDate returns the latest rolled-up day, or nil when nothing has been aggregated yet
+func (r *ReportRepository) LastAggregatedDate(ctx context.Context) (*time.Time, error) {
+	var run AggregationRun
+	err := r.db.WithContext(ctx).Order("date DESC").First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run.Date, nil
+}
+
+// FirstTransactionDate returns the creation time of the oldest marketplace transaction
+func (r *ReportRepository) FirstTransactionDate(ctx context.Context) (*time.Time, error) {
+	var first *time.Time
+	err := r.db.WithContext(ctx).Table("marketplace_transactions").Select("MIN(created_at)").Scan(&first).Error
+	return first, err
+}
+
+// AggregateDay rebuilds the summary rows of one day; re-running it for the same day is safe
+func (r *ReportRepository) AggregateDay(ctx context.Context, day time.Time) (*AggregationRun, error) {
+	next := day.AddDate(0, 0, 1)
+	run := &AggregationRun{Date: day}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("date = ?", day).Delete(&DailyProductSales{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("date = ?", day).Delete(&DailyUserSpend{}).Error; err != nil {
+			return err
+		}
+
+		products := tx.Exec(`INSERT INTO daily_product_sales (date, product_id, orders, units_sold, points_revenue)
+			SELECT ?, product_id, COUNT(*), SUM(quantity), SUM(total_amount)
+			FROM marketplace_transactions
+			WHERE status = 'success' AND created_at >= ? AND created_at < ?
+			GROUP BY product_id`, day, day, next)
+		if products.Error != nil {
+			return products.Error
+		}
+
+		users := tx.Exec(`INSERT INTO daily_user_spend (date, user_id, orders, units_bought, points_spent)
+			SELECT ?, w.user_id, COUNT(*), SUM(mt.quantity), SUM(mt.total_amount)
+			FROM marketplace_transactions mt
+			JOIN wallets w ON w.id = mt.wallet_id
+			WHERE mt.status = 'success' AND mt.created_at >= ? AND mt.created_at < ?
+			GROUP BY w.user_id`, day, day, next)
+		if users.Error != nil {
+			return users.Error
+		}
+
+		run.ProductRows = products.RowsAffected
+		run.UserRows = users.RowsAffected
+		run.CompletedAt = time.Now()
+		return tx.Save(run).Error
+	})
+	return run, err
+}
+
+// Per-user analytics
+
+// UserWalletID returns the wallet of a user
+func (r *ReportRepository) UserWalletID(ctx context.Context, userID uint) (uint, error) {
+	var walletID uint
+	err := r.replica.WithContext(ctx).Table("wallets").Where("user_id = ?", userID).Select("id").Scan(&walletID).Error
+	if err == nil && walletID == 0 {
+		return 0, errors.New("wallet not found")
+	}
+	return walletID, err
+}
+
+// LedgerByMonth sums successful credits and debits of a wallet per month
+func (r *ReportRepository) LedgerByMonth(ctx context.Context, walletID uint, period dateRange) ([]MonthlyFlow, error) {
+	var rows []MonthlyFlow
+	err := r.replica.WithContext(ctx).Table("wallet_transactions").
+		Select("DATE_FORMAT(created_at, '%Y-%m') as month, "+
+			"COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE 0 END), 0) as earned, "+
+			"COALESCE(SUM(CASE WHEN direction = 'debit' THEN amount ELSE 0 END), 0) as spent").
+		Where("wallet_id = ? AND status = ? AND created_at >= ? AND created_at < ?", walletID, "success", period.Start, period.End).
+		Group("month").
+		Order("month ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// LedgerDebitsByType groups successful debits of a wallet by transaction type
+func (r *ReportRepository) LedgerDebitsByType(ctx context.Context, walletID uint, period dateRange) ([]TypeSpend, error) {
+	var rows []TypeSpend
+	err := r.replica.WithContext(ctx).Table("wallet_transactions").
+		Select("type, COUNT(*) as count, COALESCE(SUM(amount), 0) as points_spent").
+		Where("wallet_id = ? AND direction = ? AND status = ? AND created_at >= ? AND created_at < ?", walletID, "debit", "success", period.Start, period.End).
+		Group("type").
+		Order("points_spent DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// SpendByCategory groups a wallet's marketplace purchases by the primary category of each product
+func (r *ReportRepository) SpendByCategory(ctx context.Context, walletID uint, period dateRange) ([]CategorySpend, error) {
+	var rows []CategorySpend
+	primary := r.replica.WithContext(ctx).Table("product_categories").Select("product_id, MIN(category_id) as category_id").Group("product_id")
+
+	err := r.replica.WithContext(ctx).Table("marketplace_transactions mt").
+		Select("pc.category_id, COALESCE(c.name, 'Uncategorized') as category_name, COUNT(*) as orders, COALESCE(SUM(mt.total_amount), 0) as points_spent").
+		Joins("LEFT JOIN (?) pc ON pc.product_id = mt.product_id", primary).
+		Joins("LEFT JOIN categories c ON c.id = pc.category_id").
+		Where("mt.wallet_id = ? AND mt.status = ? AND mt.created_at >= ? AND mt.created_at < ?", walletID, "success", period.Start, period.End).
+		Group("pc.category_id, c.name").
+		Order("points_spent DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// LargestPurchases returns a wallet's most expensive marketplace purchases
+func (r *ReportRepository) LargestPurchases(ctx context.Context, walletID uint, period dateRange, limit int) ([]LargestPurchase, error) {
+	var rows []LargestPurchase
+	err := r.replica.WithContext(ctx).Table("marketplace_transactions mt").
+		Select("mt.id as transaction_id, mt.product_id, p.name as product_name, mt.quantity, mt.total_amount, mt.created_at").
+		Joins("LEFT JOIN products p ON p.id = mt.product_id").
+		Where("mt.wallet_id = ? AND mt.status = ? AND mt.created_at >= ? AND mt.created_at < ?", walletID, "success", period.Start, period.End).
+		Order("mt.total_amount DESC, mt.created_at DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Breakage
+
+// cohortExpressions maps a cohort grouping to the SQL expression over users (aliased u)
+var cohortExpressions = map[string]string{
+	"signup_month": "DATE_FORMAT(u.created_at, '%Y-%m')",
+	"signup_year":  "DATE_FORMAT(u.created_at, '%Y')",
+	"role":         "u.role",
+}
+
+// issuanceTypes are the ledger credits that create new points; transfers only move existing ones
+var issuanceTypes = []string{"mission", "adjustment", "topup"}
+
+// BreakageFlows sums points issued and redeemed in the marketplace per cohort and month
+func (r *ReportRepository) BreakageFlows(ctx context.Context, cohortBy, role string, period dateRange) ([]breakageFlowRow, error) {
+	var rows []breakageFlowRow
+
+	query := r.replica.WithContext(ctx).Table("wallet_transactions wt").
+		Select(cohortExpressions[cohortBy]+" as cohort, DATE_FORMAT(wt.created_at, '%Y-%m') as period, "+
+			"COALESCE(SUM(CASE WHEN wt.direction = 'credit' AND wt.type IN ? THEN wt.amount ELSE 0 END), 0) as issued, "+
+			"COALESCE(SUM(CASE WHEN wt.direction = 'debit' AND wt.type = 'marketplace' THEN wt.amount ELSE 0 END), 0) as redeemed", issuanceTypes).
+		Joins("JOIN wallets w ON w.id = wt.wallet_id").
+		Joins("JOIN users u ON u.id = w.user_id").
+		Where("wt.status = ? AND wt.created_at >= ? AND wt.created_at < ?", "success", period.Start, period.End).
+		Scopes(utils.ScopeTenant(ctx, "u.tenant_id"))
+	if role != "" {
+		query = query.Where("u.role = ?", role)
+	}
+
+	err := query.Group("cohort, period").
+		Order("cohort ASC, period ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// BreakageBalances counts users and sums their current balances per cohort
+func (r *ReportRepository) BreakageBalances(ctx context.Context, cohortBy, role string) ([]breakageBalanceRow, error) {
+	var rows []breakageBalanceRow
+
+	query := r.replica.WithContext(ctx).Table("users u").
+		Select(cohortExpressions[cohortBy] + " as cohort, COUNT(*) as users, COALESCE(SUM(w.balance), 0) as balance").
+		Joins("JOIN wallets w ON w.user_id = u.id").
+		Scopes(utils.ScopeTenant(ctx, "u.tenant_id"))
+	if role != "" {
+		query = query.Where("u.role = ?", role)
+	}
+
+	err := query.Group("cohort").Scan(&rows).Error
+	return rows, err
+}
+
+// Transaction volume
+
+// volumeIntervals maps a series interval to the MySQL DATE_FORMAT pattern of its bucket label
+var volumeIntervals = map[string]string{
+	"hour": "%Y-%m-%d %H:00",
+	"day":  "%Y-%m-%d",
+}
+
+// TransactionVolume counts and sums wallet transactions per bucket, type and status
+func (r *ReportRepository) TransactionVolume(ctx context.Context, interval, txnType, status string, period dateRange) ([]volumeRow, error) {
+	var rows []volumeRow
+
+	query := r.replica.WithContext(ctx).Table("wallet_transactions").
+		Select("DATE_FORMAT(created_at, '"+volumeIntervals[interval]+"') as bucket, type, status, COUNT(*) as count, COALESCE(SUM(amount), 0) as amount").
+		Where("created_at >= ? AND created_at < ?", period.Start, period.End).
+		Scopes(wallet.ScopeTenantWallets(ctx, "wallet_id"))
+	if txnType != "" {
+		query = query.Where("type = ?", txnType)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Group("bucket, type, status").
+		Order("bucket ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Subscriptions
+
+func (r *ReportRepository) CreateSubscription(ctx context.Context, subscription *ReportSubscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+func (r *ReportRepository) FindSubscriptions(ctx context.Context, userID uint) ([]ReportSubscription, error) {
+	var subscriptions []ReportSubscription
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *ReportRepository) SubscriptionExists(ctx context.Context, userID uint, report, frequency string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&ReportSubscription{}).
+		Where("user_id = ? AND report = ? AND frequency = ?", userID, report, frequency).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteSubscription removes a subscription owned by the user
+func (r *ReportRepository) DeleteSubscription(ctx context.Context, userID, subscriptionID uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", subscriptionID, userID).Delete(&ReportSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("subscription not found")
+	}
+	return nil
+}
+
+// FindDueSubscriptions returns subscriptions of active admins whose next run has passed,
+// with the campus each report covers (0 for super admins, who get the whole deployment)
+func (r *ReportRepository) FindDueSubscriptions(ctx context.Context, now time.Time, limit int) ([]dueSubscription, error) {
+	var subscriptions []dueSubscription
+	err := r.db.WithContext(ctx).Table("report_subscriptions rs").
+		Select("rs.*, CASE WHEN u.role = 'superadmin' THEN 0 ELSE u.tenant_id END as tenant_id").
+		Joins("JOIN users u ON u.id = rs.user_id").
+		Where("rs.next_run_at <= ? AND u.role IN ? AND u.status = ?", now, []string{"admin", "superadmin"}, "active").
+		Order("rs.next_run_at ASC").
+		Limit(limit).
+		Scan(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *ReportRepository) UpdateSubscription(ctx context.Context, subscriptionID uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&ReportSubscription{}).Where("id = ?", subscriptionID).Updates(updates).Error
+}
+
+func (r *ReportRepository) FindUserName(ctx context.Context, userID uint) (string, error) {
+	var name string
+	err := r.db.WithContext(ctx).Table("users").Where("id = ?", userID).Select("full_name").Scan(&name).Error
+	return name, err
+}
+
+// FindLowStockItems lists products with an unresolved stock alert
+func (r *ReportRepository) FindLowStockItems(ctx context.Context) ([]LowStockItem, error) {
+	var items []LowStockItem
+	err := r.replica.WithContext(ctx).Table("stock_alerts sa").
+		Select("sa.product_id, p.name as product_name, p.stock, sa.threshold, sa.status").
+		Joins("JOIN products p ON p.id = sa.product_id").
+		Where("sa.status <> ?", "resolved").
+		Scopes(utils.ScopeTenant(ctx, "p.tenant_id")).
+		Order("p.stock ASC, p.name ASC").
+		Scan(&items).Error
+	return items, err
+}
+
+// FindFlaggedTransactions lists failed wallet transactions and those at or above the large-amount threshold
+func (r *ReportRepository) FindFlaggedTransactions(ctx context.Context, period dateRange, threshold, limit int) ([]FlaggedTransaction, error) {
+	var transactions []FlaggedTransaction
+
+	flagged := r.replica.WithContext(ctx).Where("wt.status = ?", "failed")
+	if threshold > 0 {
+		flagged = flagged.Or("wt.amount >= ?", threshold)
+	}
+
+	err := r.replica.WithContext(ctx).Table("wallet_transactions wt").
+		Select("wt.id, u.full_name as user_name, u.nim_nip, wt.type, wt.direction, wt.amount, wt.status, wt.created_at, "+
+			"CASE WHEN wt.status = 'failed' THEN 'failed' ELSE 'large amount' END as reason").
+		Joins("JOIN wallets w ON w.id = wt.wallet_id").
+		Joins("JOIN users u ON u.id = w.user_id").
+		Where("wt.created_at >= ? AND wt.created_at < ?", period.Start, period.End).
+		Where(flagged).
+		Scopes(utils.ScopeTenant(ctx, "u.tenant_id")).
+		Order("wt.created_at DESC").
+		Limit(limit).
+		Scan(&transactions).Error
+	return transactions, err
+}
+
+// Category breakdown
+
+// SalesPerProduct totals sales per product over a period
+func (r *ReportRepository) SalesPerProduct(ctx context.Context, period dateRange, cutoff time.Time) ([]productSalesRow, error) {
+	var rows []productSalesRow
+	err := r.productSales(ctx, period, cutoff).
+		Select("src.product_id, SUM(src.orders) as orders, SUM(src.units_sold) as units_sold, SUM(src.points_revenue) as points_revenue").
+		Group("src.product_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *ReportRepository) FindCategories(ctx context.Context) ([]categoryRow, error) {
+	var rows []categoryRow
+	err := r.replica.WithContext(ctx).Table("categories").Select("id, name, parent_id").
+		Scopes(utils.ScopeTenant(ctx, "tenant_id")).
+		Order("name ASC").Scan(&rows).Error
+	return rows, err
+}
+
+func (r *ReportRepository) FindProductCategories(ctx context.Context) ([]productCategoryRow, error) {
+	var rows []productCategoryRow
+	err := r.replica.WithContext(ctx).Table("product_categories").Select("product_id, category_id").Scan(&rows).Error
+	return rows, err
+}
+
+// Cohort retention
+
+// CohortSizes counts users per enrollment (signup) month
+func (r *ReportRepository) CohortSizes(ctx context.Context, role string, cohorts dateRange) ([]cohortSizeRow, error) {
+	var rows []cohortSizeRow
+	query := r.replica.WithContext(ctx).Table("users u").
+		Select("DATE_FORMAT(u.created_at, '%Y-%m') as cohort, COUNT(*) as users").
+		Where("u.created_at >= ? AND u.created_at < ?", cohorts.Start, cohorts.End).
+		Scopes(utils.ScopeTenant(ctx, "u.tenant_id"))
+	if role != "" {
+		query = query.Where("u.role = ?", role)
+	}
+	err := query.Group("cohort").Order("cohort ASC").Scan(&rows).Error
+	return rows, err
+}
+
+// RetentionActivity counts distinct users per cohort who spent points in the marketplace, per month since enrollment
+func (r *ReportRepository) RetentionActivity(ctx context.Context, role string, cohorts dateRange, months int) ([]retentionRow, error) {
+	var rows []retentionRow
+	query := r.replica.WithContext(ctx).Table("wallet_transactions wt").
+		Select("DATE_FORMAT(u.created_at, '%Y-%m') as cohort, "+
+			"PERIOD_DIFF(DATE_FORMAT(wt.created_at, '%Y%m'), DATE_FORMAT(u.created_at, '%Y%m')) as month_offset, "+
+			"COUNT(DISTINCT u.id) as active_users").
+		Joins("JOIN wallets w ON w.id = wt.wallet_id").
+		Joins("JOIN users u ON u.id = w.user_id").
+		Where("wt.type = ? AND wt.direction = ? AND wt.status = ?", "marketplace", "debit", "success").
+		Where("u.created_at >= ? AND u.created_at < ?", cohorts.Start, cohorts.End).
+		Scopes(utils.ScopeTenant(ctx, "u.tenant_id"))
+	if role != "" {
+		query = query.Where("u.role = ?", role)
+	}
+	err := query.Group("cohort, month_offset").
+		Having("month_offset BETWEEN 0 AND ?", months-1).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// JournalActivity sums successful wallet transactions of the given types per day, type and direction
+func (r *ReportRepository) JournalActivity(ctx context.Context, period dateRange, types []string) ([]journalActivity, error) {
+	var rows []journalActivity
+	err := r.replica.WithContext(ctx).Table("wallet_transactions wt").
+		Select("DATE_FORMAT(wt.created_at, '%Y-%m-%d') as day, wt.type, wt.direction, SUM(wt.amount) as amount, COUNT(*) as transactions").
+		Joins("JOIN wallets w ON w.id = wt.wallet_id").
+		Where("wt.status = ? AND wt.type IN ? AND wt.created_at >= ? AND wt.created_at < ?", "success", types, period.Start, period.End).
+		Scopes(utils.ScopeTenantOwner(ctx, "w.user_id", "users")).
+		Group("day, wt.type, wt.direction").
+		Order("day ASC, wt.type ASC, wt.direction ASC").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,873 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+	"wallet-point/internal/alert"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/storage"
+	"wallet-point/utils"
+)
+
+const (
+	dateLayout        = "2006-01-02"
+	defaultReportDays = 30
+	maxReportDays     = 366 * 2
+	defaultRankLimit  = 10
+	maxRankLimit      = 100
+
+	defaultAnalyticsMonths = 12
+	maxAnalyticsMonths     = 24
+	largestPurchasesLimit  = 5
+	maxHourlyDays          = 31
+	maxCategoryDepth       = 10 // guards against parent_id cycles
+	defaultRetentionMonths = 6
+	maxRetentionMonths     = 24
+
+	aggregationHour   = 0 // nightly rollup runs at 00:15 local time
+	aggregationMinute = 15
+)
+
+var (
+	ErrInvalidGroupBy     = errors.New("group_by must be one of day, week, month")
+	ErrInvalidFrom        = errors.New("invalid from date, expected YYYY-MM-DD")
+	ErrInvalidTo          = errors.New("invalid to date, expected YYYY-MM-DD")
+	ErrInvalidRange       = errors.New("from date must not be after to date")
+	ErrRangeTooLarge      = errors.New("date range is too large")
+	ErrInvalidSortBy      = errors.New("sort_by must be one of units, points")
+	ErrInvalidFormat      = errors.New("format must be one of json, xlsx")
+	ErrInvalidMonths      = errors.New("months must be between 1 and 24")
+	ErrInvalidCohort      = errors.New("cohort_by must be one of signup_month, signup_year, role")
+	ErrInvalidRole        = errors.New("role must be one of admin, dosen, mahasiswa")
+	ErrInvalidInterval    = errors.New("interval must be one of hour, day")
+	ErrHourlyRange        = errors.New("hourly series are limited to 31 days")
+	ErrInvalidCohortMonth = errors.New("cohort months must be YYYY-MM with from_cohort not after to_cohort")
+)
+
+// IsBadRequest reports whether err was caused by invalid report parameters
+func IsBadRequest(err error) bool {
+	switch err {
+	case ErrInvalidGroupBy, ErrInvalidFrom, ErrInvalidTo, ErrInvalidRange, ErrRangeTooLarge,
+		ErrInvalidSortBy, ErrInvalidFormat, ErrInvalidMonths, ErrInvalidCohort, ErrInvalidRole,
+		ErrInvalidInterval, ErrHourlyRange, ErrInvalidCohortMonth:
+		return true
+	}
+	return false
+}
+
+type ReportService struct {
+	repo *ReportRepository
+
+	// Scheduled report emails, enabled with EnableSubscriptions
+	notifier                  *notification.NotificationService
+	largeTransactionThreshold int
+
+	// Background xlsx exports, enabled with SetJobQueue
+	jobQueue *jobs.Queue
+	files    storage.Storage
+
+	// Accounting journal exports, enabled with EnableJournal
+	journal JournalConfig
+
+	// Flagged transaction alerts, enabled with SetAlerts
+	alerts *alert.Service
+}
+
+func NewReportService(repo *ReportRepository) *ReportService {
+	return &ReportService{repo: repo}
+}
+
+// GetSalesReport returns units sold, points revenue and order counts per period
+func (s *ReportService) GetSalesReport(ctx context.Context, params SalesReportParams) (*SalesReportResponse, error) {
+	if params.GroupBy == "" {
+		params.GroupBy = "day"
+	}
+	if _, ok := periodFormats[params.GroupBy]; !ok {
+		return nil, ErrInvalidGroupBy
+	}
+
+	period, err := resolveRange(params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff, err := s.summaryCutoff(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := s.repo.SalesByPeriod(ctx, params.GroupBy, period, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if periods == nil {
+		periods = []SalesPeriod{}
+	}
+
+	var totals SalesTotals
+	for _, p := range periods {
+		totals.Orders += p.Orders
+		totals.UnitsSold += p.UnitsSold
+		totals.PointsRevenue += p.PointsRevenue
+	}
+
+	return &SalesReportResponse{
+		From:    period.Start.Format(dateLayout),
+		To:      period.End.AddDate(0, 0, -1).Format(dateLayout),
+		GroupBy: params.GroupBy,
+		Periods: periods,
+		Totals:  totals,
+	}, nil
+}
+
+// GetTopProducts ranks products by units sold or points revenue over a period
+func (s *ReportService) GetTopProducts(ctx context.Context, params RankingParams) (*TopProductsResponse, error) {
+	if params.SortBy == "" {
+		params.SortBy = "units"
+	}
+	if params.SortBy != "units" && params.SortBy != "points" {
+		return nil, ErrInvalidSortBy
+	}
+
+	period, categoryIDs, err := s.resolveRanking(ctx, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff, err := s.summaryCutoff(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.repo.TopProducts(ctx, period, cutoff, categoryIDs, params.SortBy, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []TopProduct{}
+	}
+	for i := range products {
+		products[i].Rank = i + 1
+	}
+
+	return &TopProductsResponse{
+		From:       period.Start.Format(dateLayout),
+		To:         period.End.AddDate(0, 0, -1).Format(dateLayout),
+		CategoryID: params.CategoryID,
+		SortBy:     params.SortBy,
+		Products:   products,
+	}, nil
+}
+
+// GetMarketplaceAnalytics sums the successful sales of a period from the sales
+// and top products queries, with a daily series that has a row for every day,
+// sales or not. It lives here rather than in the marketplace package so the
+// dashboard reads the same figures as the sales reports, rollups included.
+func (s *ReportService) GetMarketplaceAnalytics(ctx context.Context, params AnalyticsParams) (*MarketplaceAnalytics, error) {
+	period, err := resolveRange(params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff, err := s.summaryCutoff(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.repo.SalesByPeriod(ctx, "day", period, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.TopProducts(ctx, period, cutoff, nil, "units", params.Top)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.repo.CountOrders(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &MarketplaceAnalytics{
+		From:        period.Start.Format(dateLayout),
+		To:          period.End.AddDate(0, 0, -1).Format(dateLayout),
+		Orders:      orders,
+		TopProducts: make([]ProductSales, 0, len(products)),
+		Daily:       []DailySales{},
+	}
+	for i, p := range products {
+		analytics.TopProducts = append(analytics.TopProducts, ProductSales{
+			Rank:          i + 1,
+			ProductID:     p.ProductID,
+			ProductName:   p.ProductName,
+			Lines:         p.Orders,
+			UnitsSold:     p.UnitsSold,
+			PointsRevenue: p.PointsRevenue,
+		})
+	}
+
+	byDate := make(map[string]SalesPeriod, len(days))
+	for _, day := range days {
+		byDate[day.Period] = day
+	}
+	for date := period.Start; date.Before(period.End); date = date.AddDate(0, 0, 1) {
+		day := byDate[date.Format(dateLayout)]
+		analytics.Daily = append(analytics.Daily, DailySales{
+			Date:          date.Format(dateLayout),
+			Lines:         day.Orders,
+			UnitsSold:     day.UnitsSold,
+			PointsRevenue: day.PointsRevenue,
+		})
+		analytics.Lines += day.Orders
+		analytics.UnitsSold += day.UnitsSold
+		analytics.PointsRevenue += day.PointsRevenue
+	}
+	if analytics.Orders > 0 {
+		analytics.AverageOrder = analytics.PointsRevenue / analytics.Orders
+	}
+	return analytics, nil
+}
+
+// GetTopBuyers ranks users by points spent over a period
+func (s *ReportService) GetTopBuyers(ctx context.Context, params RankingParams) (*TopBuyersResponse, error) {
+	period, categoryIDs, err := s.resolveRanking(ctx, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff, err := s.summaryCutoff(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buyers, err := s.repo.TopBuyers(ctx, period, cutoff, categoryIDs, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if buyers == nil {
+		buyers = []TopBuyer{}
+	}
+	for i := range buyers {
+		buyers[i].Rank = i + 1
+	}
+
+	return &TopBuyersResponse{
+		From:       period.Start.Format(dateLayout),
+		To:         period.End.AddDate(0, 0, -1).Format(dateLayout),
+		CategoryID: params.CategoryID,
+		Buyers:     buyers,
+	}, nil
+}
+
+// resolveRanking validates the shared ranking parameters and expands the category filter
+func (s *ReportService) resolveRanking(ctx context.Context, params *RankingParams) (dateRange, []uint, error) {
+	if params.Limit < 1 {
+		params.Limit = defaultRankLimit
+	}
+	if params.Limit > maxRankLimit {
+		params.Limit = maxRankLimit
+	}
+
+	period, err := resolveRange(params.From, params.To)
+	if err != nil {
+		return dateRange{}, nil, err
+	}
+
+	var categoryIDs []uint
+	if params.CategoryID > 0 {
+		categoryIDs, err = s.repo.CategoryWithDescendants(ctx, params.CategoryID)
+		if err != nil {
+			return dateRange{}, nil, err
+		}
+	}
+
+	return period, categoryIDs, nil
+}
+
+// resolveRange parses inclusive from/to dates, defaulting to the last 30 days
+func resolveRange(from, to string) (dateRange, error) {
+	end := startOfDay(time.Now())
+	if to != "" {
+		parsed, err := time.ParseInLocation(dateLayout, to, time.Local)
+		if err != nil {
+			return dateRange{}, ErrInvalidTo
+		}
+		end = parsed
+	}
+
+	start := end.AddDate(0, 0, -(defaultReportDays - 1))
+	if from != "" {
+		parsed, err := time.ParseInLocation(dateLayout, from, time.Local)
+		if err != nil {
+			return dateRange{}, ErrInvalidFrom
+		}
+		start = parsed
+	}
+
+	if start.After(end) {
+		return dateRange{}, ErrInvalidRange
+	}
+	if end.Sub(start) > maxReportDays*24*time.Hour {
+		return dateRange{}, ErrRangeTooLarge
+	}
+
+	// Make the upper bound exclusive so the whole "to" day is included
+	return dateRange{Start: start, End: end.AddDate(0, 0, 1)}, nil
+}
+
+// GetUserAnalytics summarizes a user's own ledger over the last months (current month included)
+func (s *ReportService) GetUserAnalytics(ctx context.Context, userID uint, months int) (*UserAnalytics, error) {
+	if months == 0 {
+		months = defaultAnalyticsMonths
+	}
+	if months < 1 || months > maxAnalyticsMonths {
+		return nil, ErrInvalidMonths
+	}
+
+	walletID, err := s.repo.UserWalletID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := startOfDay(time.Now())
+	firstOfMonth := today.AddDate(0, 0, 1-today.Day())
+	period := dateRange{Start: firstOfMonth.AddDate(0, -(months - 1), 0), End: today.AddDate(0, 0, 1)}
+
+	monthly, err := s.repo.LedgerByMonth(ctx, walletID, period)
+	if err != nil {
+		return nil, err
+	}
+	byType, err := s.repo.LedgerDebitsByType(ctx, walletID, period)
+	if err != nil {
+		return nil, err
+	}
+	byCategory, err := s.repo.SpendByCategory(ctx, walletID, period)
+	if err != nil {
+		return nil, err
+	}
+	largest, err := s.repo.LargestPurchases(ctx, walletID, period, largestPurchasesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill months without activity so charts get a continuous axis
+	flows := make(map[string]MonthlyFlow, len(monthly))
+	for _, m := range monthly {
+		flows[m.Month] = m
+	}
+	analytics := &UserAnalytics{
+		From:             period.Start.Format(dateLayout),
+		To:               today.Format(dateLayout),
+		Monthly:          make([]MonthlyFlow, 0, months),
+		SpendByCategory:  byCategory,
+		SpendByType:      byType,
+		LargestPurchases: largest,
+	}
+	for month := period.Start; month.Before(period.End); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		flow, ok := flows[key]
+		if !ok {
+			flow = MonthlyFlow{Month: key}
+		}
+		analytics.Monthly = append(analytics.Monthly, flow)
+		analytics.PointsEarned += flow.Earned
+		analytics.PointsSpent += flow.Spent
+	}
+	analytics.NetPoints = analytics.PointsEarned - analytics.PointsSpent
+
+	if analytics.SpendByCategory == nil {
+		analytics.SpendByCategory = []CategorySpend{}
+	}
+	if analytics.SpendByType == nil {
+		analytics.SpendByType = []TypeSpend{}
+	}
+	if analytics.LargestPurchases == nil {
+		analytics.LargestPurchases = []LargestPurchase{}
+	}
+
+	return analytics, nil
+}
+
+// GetBreakageReport compares points issued with points redeemed per user cohort and month
+func (s *ReportService) GetBreakageReport(ctx context.Context, params BreakageParams) (*BreakageReportResponse, error) {
+	if params.CohortBy == "" {
+		params.CohortBy = "signup_month"
+	}
+	if _, ok := cohortExpressions[params.CohortBy]; !ok {
+		return nil, ErrInvalidCohort
+	}
+	switch params.Role {
+	case "", "admin", "dosen", "mahasiswa":
+	default:
+		return nil, ErrInvalidRole
+	}
+
+	period, err := resolveRange(params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+
+	flows, err := s.repo.BreakageFlows(ctx, params.CohortBy, params.Role, period)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.repo.BreakageBalances(ctx, params.CohortBy, params.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	cohorts := make(map[string]*BreakageCohort)
+	cohortOf := func(name string) *BreakageCohort {
+		if c, ok := cohorts[name]; ok {
+			return c
+		}
+		c := &BreakageCohort{Cohort: name, Periods: []BreakagePeriod{}}
+		cohorts[name] = c
+		return c
+	}
+
+	for _, b := range balances {
+		c := cohortOf(b.Cohort)
+		c.Users = b.Users
+		c.OutstandingBalance = b.Balance
+	}
+	for _, f := range flows {
+		c := cohortOf(f.Cohort)
+		c.Issued += f.Issued
+		c.Redeemed += f.Redeemed
+		c.Periods = append(c.Periods, BreakagePeriod{
+			Period:   f.Period,
+			Issued:   f.Issued,
+			Redeemed: f.Redeemed,
+			Unspent:  f.Issued - f.Redeemed,
+		})
+	}
+
+	response := &BreakageReportResponse{
+		From:     period.Start.Format(dateLayout),
+		To:       period.End.AddDate(0, 0, -1).Format(dateLayout),
+		CohortBy: params.CohortBy,
+		Cohorts:  make([]BreakageCohort, 0, len(cohorts)),
+		Totals:   BreakageCohort{Cohort: "all", Periods: []BreakagePeriod{}},
+	}
+	for _, c := range cohorts {
+		c.Unspent = c.Issued - c.Redeemed
+		c.BreakageRate = breakageRate(c.Unspent, c.Issued)
+		response.Cohorts = append(response.Cohorts, *c)
+
+		response.Totals.Users += c.Users
+		response.Totals.Issued += c.Issued
+		response.Totals.Redeemed += c.Redeemed
+		response.Totals.OutstandingBalance += c.OutstandingBalance
+	}
+	sort.Slice(response.Cohorts, func(i, j int) bool {
+		return response.Cohorts[i].Cohort < response.Cohorts[j].Cohort
+	})
+	response.Totals.Unspent = response.Totals.Issued - response.Totals.Redeemed
+	response.Totals.BreakageRate = breakageRate(response.Totals.Unspent, response.Totals.Issued)
+
+	return response, nil
+}
+
+// breakageRate is the unspent share of issued points, clamped to 0..1 (cohorts can spend older points)
+func breakageRate(unspent, issued int64) float64 {
+	if issued <= 0 || unspent <= 0 {
+		return 0
+	}
+	rate := float64(unspent) / float64(issued)
+	return math.Round(math.Min(rate, 1)*10000) / 10000
+}
+
+// GetTransactionVolume returns a gap-free time series of wallet transaction counts and sums
+func (s *ReportService) GetTransactionVolume(ctx context.Context, params VolumeParams) (*VolumeResponse, error) {
+	if params.Interval == "" {
+		params.Interval = "day"
+	}
+	if _, ok := volumeIntervals[params.Interval]; !ok {
+		return nil, ErrInvalidInterval
+	}
+
+	period, err := resolveRange(params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+	if params.Interval == "hour" && period.End.Sub(period.Start) > maxHourlyDays*24*time.Hour {
+		return nil, ErrHourlyRange
+	}
+
+	rows, err := s.repo.TransactionVolume(ctx, params.Interval, params.Type, params.Status, period)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, next := dateLayout, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	if params.Interval == "hour" {
+		layout, next = "2006-01-02 15:00", func(t time.Time) time.Time { return t.Add(time.Hour) }
+	}
+
+	// Pre-fill every bucket so charts get zeros instead of gaps
+	buckets := make([]VolumeBucket, 0)
+	index := make(map[string]int)
+	for t := period.Start; t.Before(period.End); t = next(t) {
+		label := t.Format(layout)
+		if _, exists := index[label]; exists {
+			continue // DST fall-back repeats an hour
+		}
+		index[label] = len(buckets)
+		buckets = append(buckets, VolumeBucket{
+			Bucket:   label,
+			ByType:   map[string]VolumeBreakdown{},
+			ByStatus: map[string]VolumeBreakdown{},
+		})
+	}
+
+	for _, row := range rows {
+		i, ok := index[row.Bucket]
+		if !ok {
+			continue
+		}
+		b := &buckets[i]
+		b.Count += row.Count
+		b.Amount += row.Amount
+
+		byType := b.ByType[row.Type]
+		byType.Count += row.Count
+		byType.Amount += row.Amount
+		b.ByType[row.Type] = byType
+
+		byStatus := b.ByStatus[row.Status]
+		byStatus.Count += row.Count
+		byStatus.Amount += row.Amount
+		b.ByStatus[row.Status] = byStatus
+	}
+
+	return &VolumeResponse{
+		From:     period.Start.Format(dateLayout),
+		To:       period.End.AddDate(0, 0, -1).Format(dateLayout),
+		Interval: params.Interval,
+		Buckets:  buckets,
+	}, nil
+}
+
+// RunQuery compiles a custom report against the builder whitelists and runs it
+func (s *ReportService) RunQuery(ctx context.Context, req QueryRequest) (*QueryResponse, error) {
+	compiled, err := compileQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := resolveRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, filter := range compiled.filters {
+		if filter.Dimension != "category" {
+			continue
+		}
+		var categoryIDs []uint
+		for _, value := range filter.Values {
+			ids, err := s.repo.CategoryWithDescendants(ctx, value.(uint))
+			if err != nil {
+				return nil, err
+			}
+			categoryIDs = append(categoryIDs, ids...)
+		}
+		compiled.categories[i] = categoryIDs
+	}
+
+	rows, err := s.repo.RunQuery(ctx, compiled, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryResponse{
+		From:    period.Start.Format(dateLayout),
+		To:      period.End.AddDate(0, 0, -1).Format(dateLayout),
+		Columns: compiled.columns,
+		Rows:    rows,
+	}, nil
+}
+
+// GetCategoryBreakdown splits revenue and units by category, nested by sub-category
+func (s *ReportService) GetCategoryBreakdown(ctx context.Context, from, to string) (*CategoryBreakdownResponse, error) {
+	period, err := resolveRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	cutoff, err := s.summaryCutoff(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.repo.SalesPerProduct(ctx, period, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.FindCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := s.repo.FindProductCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CategoryBreakdownResponse{
+		From:       period.Start.Format(dateLayout),
+		To:         period.End.AddDate(0, 0, -1).Format(dateLayout),
+		Categories: []CategorySales{},
+	}
+
+	productsOf := make(map[uint][]uint) // category -> direct products
+	categorized := make(map[uint]bool)
+	for _, m := range memberships {
+		productsOf[m.CategoryID] = append(productsOf[m.CategoryID], m.ProductID)
+		categorized[m.ProductID] = true
+	}
+	childrenOf := make(map[uint][]categoryRow)
+	var roots []categoryRow
+	for _, c := range categories {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+		} else {
+			childrenOf[*c.ParentID] = append(childrenOf[*c.ParentID], c)
+		}
+	}
+
+	salesOf := make(map[uint]productSalesRow, len(sales))
+	response.Uncategorized = CategorySales{Name: "Uncategorized", Children: []CategorySales{}}
+	for _, row := range sales {
+		salesOf[row.ProductID] = row
+		response.Totals.Orders += row.Orders
+		response.Totals.UnitsSold += row.UnitsSold
+		response.Totals.PointsRevenue += row.PointsRevenue
+		if !categorized[row.ProductID] {
+			response.Uncategorized.Orders += row.Orders
+			response.Uncategorized.UnitsSold += row.UnitsSold
+			response.Uncategorized.PointsRevenue += row.PointsRevenue
+		}
+	}
+	response.Uncategorized.RevenueShare = ratio(response.Uncategorized.PointsRevenue, response.Totals.PointsRevenue)
+
+	// build returns the node and the products of its whole subtree, so a product listed
+	// in both a category and its sub-category is only counted once at the parent
+	var build func(c categoryRow, depth int) (CategorySales, map[uint]bool)
+	build = func(c categoryRow, depth int) (CategorySales, map[uint]bool) {
+		id := c.ID
+		node := CategorySales{
+			CategoryID:  &id,
+			Name:        c.Name,
+			ParentID:    c.ParentID,
+			ProductsURL: fmt.Sprintf("/api/v1/admin/reports/top-products?category_id=%d&from=%s&to=%s", c.ID, response.From, response.To),
+			Children:    []CategorySales{},
+		}
+		products := make(map[uint]bool)
+		for _, productID := range productsOf[c.ID] {
+			products[productID] = true
+		}
+		if depth < maxCategoryDepth {
+			for _, child := range childrenOf[c.ID] {
+				childNode, childProducts := build(child, depth+1)
+				node.Children = append(node.Children, childNode)
+				for productID := range childProducts {
+					products[productID] = true
+				}
+			}
+		}
+
+		for productID := range products {
+			row := salesOf[productID]
+			node.Orders += row.Orders
+			node.UnitsSold += row.UnitsSold
+			node.PointsRevenue += row.PointsRevenue
+		}
+		node.RevenueShare = ratio(node.PointsRevenue, response.Totals.PointsRevenue)
+		sortCategorySales(node.Children)
+		return node, products
+	}
+
+	for _, root := range roots {
+		node, _ := build(root, 0)
+		response.Categories = append(response.Categories, node)
+	}
+	sortCategorySales(response.Categories)
+
+	return response, nil
+}
+
+func sortCategorySales(nodes []CategorySales) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].PointsRevenue > nodes[j].PointsRevenue
+	})
+}
+
+// ratio is part/total rounded to 4 decimals, 0 when total is empty
+func ratio(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
+
+// GetCohortRetention shows, per enrollment cohort, the share of users spending points in each following month
+func (s *ReportService) GetCohortRetention(ctx context.Context, params RetentionParams) (*RetentionResponse, error) {
+	if params.Months == 0 {
+		params.Months = defaultRetentionMonths
+	}
+	if params.Months < 1 || params.Months > maxRetentionMonths {
+		return nil, ErrInvalidMonths
+	}
+	switch params.Role {
+	case "", "admin", "dosen", "mahasiswa":
+	default:
+		return nil, ErrInvalidRole
+	}
+
+	today := startOfDay(time.Now())
+	thisMonth := today.AddDate(0, 0, 1-today.Day())
+
+	toCohort := thisMonth
+	if params.ToCohort != "" {
+		parsed, err := time.ParseInLocation("2006-01", params.ToCohort, time.Local)
+		if err != nil {
+			return nil, ErrInvalidCohortMonth
+		}
+		toCohort = parsed
+	}
+	fromCohort := toCohort.AddDate(0, -(params.Months - 1), 0)
+	if params.FromCohort != "" {
+		parsed, err := time.ParseInLocation("2006-01", params.FromCohort, time.Local)
+		if err != nil {
+			return nil, ErrInvalidCohortMonth
+		}
+		fromCohort = parsed
+	}
+	if fromCohort.After(toCohort) {
+		return nil, ErrInvalidCohortMonth
+	}
+	cohorts := dateRange{Start: fromCohort, End: toCohort.AddDate(0, 1, 0)}
+
+	sizes, err := s.repo.CohortSizes(ctx, params.Role, cohorts)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.repo.RetentionActivity(ctx, params.Role, cohorts, params.Months)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[string]map[int]int64)
+	for _, row := range activity {
+		if active[row.Cohort] == nil {
+			active[row.Cohort] = make(map[int]int64)
+		}
+		active[row.Cohort][row.MonthOffset] = row.ActiveUsers
+	}
+
+	response := &RetentionResponse{
+		FromCohort: fromCohort.Format("2006-01"),
+		ToCohort:   toCohort.Format("2006-01"),
+		Role:       params.Role,
+		Cohorts:    make([]RetentionCohort, 0, len(sizes)),
+	}
+	for _, size := range sizes {
+		start, err := time.ParseInLocation("2006-01", size.Cohort, time.Local)
+		if err != nil {
+			return nil, err
+		}
+
+		cohort := RetentionCohort{Cohort: size.Cohort, Users: size.Users, Months: []RetentionCell{}}
+		// Only months that have started; the current month is partial
+		for offset := 0; offset < params.Months; offset++ {
+			month := start.AddDate(0, offset, 0)
+			if month.After(thisMonth) {
+				break
+			}
+			users := active[size.Cohort][offset]
+			cohort.Months = append(cohort.Months, RetentionCell{
+				MonthOffset: offset,
+				Month:       month.Format("2006-01"),
+				ActiveUsers: users,
+				Rate:        ratio(users, size.Users),
+			})
+		}
+		response.Cohorts = append(response.Cohorts, cohort)
+	}
+
+	return response, nil
+}
+
+// summaryCutoff is the first day not covered by the summary tables; earlier days are read from the rollups
+func (s *ReportService) summaryCutoff(ctx context.Context) (time.Time, error) {
+	last, err := s.repo.LastAggregatedDate(ctx)
+	if err != nil || last == nil {
+		return time.Time{}, err
+	}
+	return startOfDay(*last).AddDate(0, 0, 1), nil
+}
+
+// RunAggregation rolls up every completed day since the last run (or since the first transaction)
+func (s *ReportService) RunAggregation(ctx context.Context) error {
+	today := startOfDay(time.Now())
+
+	last, err := s.repo.LastAggregatedDate(ctx)
+	if err != nil {
+		return err
+	}
+
+	var day time.Time
+	if last != nil {
+		day = startOfDay(*last).AddDate(0, 0, 1)
+	} else {
+		first, err := s.repo.FirstTransactionDate(ctx)
+		if err != nil {
+			return err
+		}
+		if first == nil {
+			return nil
+		}
+		day = startOfDay(*first)
+	}
+
+	for ; day.Before(today); day = day.AddDate(0, 0, 1) {
+		run, err := s.repo.AggregateDay(ctx, day)
+		if err != nil {
+			return err
+		}
+		slog.Info("report: day aggregated", "day", day.Format(dateLayout), "product_rows", run.ProductRows, "user_rows", run.UserRows)
+	}
+	return nil
+}
+
+// StartAggregator catches up on startup and then rolls up the previous day every night
+func (s *ReportService) StartAggregator() {
+	utils.Go(func() {
+		for {
+			if err := s.RunAggregation(context.Background()); err != nil {
+				slog.Error("report: aggregation failed", "error", err)
+			}
+			if !utils.Sleep(time.Until(nextAggregation(time.Now()))) {
+				return
+			}
+		}
+	})
+}
+
+func nextAggregation(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), aggregationHour, aggregationMinute, 0, 0, time.Local)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
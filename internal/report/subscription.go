@@ -0,0 +1,206 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-point/internal/notification"
+	"wallet-point/utils"
+)
+
+const (
+	subscriptionSendHour    = 7 // reports are emailed at 07:00 local time
+	subscriptionBatchSize   = 20
+	flaggedTransactionLimit = 100
+	emailTopProducts        = 5
+)
+
+// EnableSubscriptions wires the notification service used to email scheduled reports
+func (s *ReportService) EnableSubscriptions(notifier *notification.NotificationService, largeTransactionThreshold int) {
+	s.notifier = notifier
+	s.largeTransactionThreshold = largeTransactionThreshold
+}
+
+// CreateSubscription subscribes an admin to a weekly or monthly report email
+func (s *ReportService) CreateSubscription(ctx context.Context, userID uint, req CreateSubscriptionRequest) (*ReportSubscription, error) {
+	exists, err := s.repo.SubscriptionExists(ctx, userID, req.Report, req.Frequency)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("subscription already exists")
+	}
+
+	subscription := &ReportSubscription{
+		UserID:    userID,
+		Report:    req.Report,
+		Frequency: req.Frequency,
+		NextRunAt: nextSubscriptionRun(req.Frequency, time.Now()),
+	}
+	if err := s.repo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *ReportService) GetSubscriptions(ctx context.Context, userID uint) ([]ReportSubscription, error) {
+	subscriptions, err := s.repo.FindSubscriptions(ctx, userID)
+	if subscriptions == nil {
+		subscriptions = []ReportSubscription{}
+	}
+	return subscriptions, err
+}
+
+func (s *ReportService) DeleteSubscription(ctx context.Context, userID, subscriptionID uint) error {
+	return s.repo.DeleteSubscription(ctx, userID, subscriptionID)
+}
+
+// ProcessSubscriptions emails every due report and schedules the next run
+func (s *ReportService) ProcessSubscriptions(ctx context.Context) {
+	if s.notifier == nil {
+		return
+	}
+
+	now := time.Now()
+	subscriptions, err := s.repo.FindDueSubscriptions(ctx, now, subscriptionBatchSize)
+	if err != nil {
+		slog.Error("report: load due subscriptions failed", "error", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		updates := map[string]interface{}{
+			"next_run_at": nextSubscriptionRun(subscription.Frequency, now),
+		}
+
+		reportCtx := ctx
+		if subscription.TenantID != 0 {
+			reportCtx = utils.WithTenant(ctx, subscription.TenantID)
+		}
+		if err := s.sendSubscription(reportCtx, subscription.ReportSubscription); err != nil {
+			slog.Error("report: subscription failed", "subscription_id", subscription.ID, "error", err)
+			updates["last_error"] = err.Error()
+		} else {
+			updates["last_sent_at"] = now
+			updates["last_error"] = ""
+		}
+
+		if err := s.repo.UpdateSubscription(ctx, subscription.ID, updates); err != nil {
+			slog.Error("report: update subscription failed", "subscription_id", subscription.ID, "error", err)
+		}
+	}
+}
+
+// StartSubscriptionDispatcher checks for due report emails in the background
+func (s *ReportService) StartSubscriptionDispatcher(interval time.Duration) {
+	utils.Go(func() {
+		for {
+			s.ProcessSubscriptions(context.Background())
+			if !utils.Sleep(interval) {
+				return
+			}
+		}
+	})
+}
+
+// sendSubscription renders the subscribed report for the period that ended at the scheduled run
+func (s *ReportService) sendSubscription(ctx context.Context, subscription ReportSubscription) error {
+	name, err := s.repo.FindUserName(ctx, subscription.UserID)
+	if err != nil {
+		return err
+	}
+
+	period := subscriptionPeriod(subscription.Frequency, subscription.NextRunAt)
+	data := map[string]interface{}{
+		"RecipientName": name,
+		"Frequency":     frequencyLabel(subscription.Frequency),
+		"From":          period.Start.Format(dateLayout),
+		"To":            period.End.AddDate(0, 0, -1).Format(dateLayout),
+		"GeneratedAt":   time.Now(),
+	}
+
+	switch subscription.Report {
+	case "sales_summary":
+		groupBy := "day"
+		if subscription.Frequency == "monthly" {
+			groupBy = "week"
+		}
+		sales, err := s.GetSalesReport(ctx, SalesReportParams{From: data["From"].(string), To: data["To"].(string), GroupBy: groupBy})
+		if err != nil {
+			return err
+		}
+		top, err := s.GetTopProducts(ctx, RankingParams{From: data["From"].(string), To: data["To"].(string), Limit: emailTopProducts})
+		if err != nil {
+			return err
+		}
+		data["Sales"] = sales
+		data["TopProducts"] = top.Products
+
+	case "low_stock":
+		alerts, err := s.repo.FindLowStockItems(ctx)
+		if err != nil {
+			return err
+		}
+		data["Alerts"] = alerts
+
+	case "flagged_transactions":
+		transactions, err := s.repo.FindFlaggedTransactions(ctx, period, s.largeTransactionThreshold, flaggedTransactionLimit+1)
+		if err != nil {
+			return err
+		}
+		data["Truncated"] = len(transactions) > flaggedTransactionLimit
+		if len(transactions) > flaggedTransactionLimit {
+			transactions = transactions[:flaggedTransactionLimit]
+		}
+		data["Transactions"] = transactions
+		data["Threshold"] = s.largeTransactionThreshold
+
+	default:
+		return fmt.Errorf("unknown report %s", subscription.Report)
+	}
+
+	subject, body, err := notification.RenderEmail("report_"+subscription.Report, data)
+	if err != nil {
+		return err
+	}
+	return s.notifier.EmailUser(subscription.UserID, subject, body)
+}
+
+// nextSubscriptionRun is the next Monday (weekly) or first of the month (monthly) at the send hour
+func nextSubscriptionRun(frequency string, after time.Time) time.Time {
+	day := startOfDay(after)
+	var next time.Time
+	if frequency == "monthly" {
+		next = day.AddDate(0, 0, 1-day.Day()).Add(subscriptionSendHour * time.Hour)
+		if !next.After(after) {
+			next = day.AddDate(0, 1, 1-day.Day()).Add(subscriptionSendHour * time.Hour)
+		}
+		return next
+	}
+
+	daysUntilMonday := (int(time.Monday) - int(day.Weekday()) + 7) % 7
+	next = day.AddDate(0, 0, daysUntilMonday).Add(subscriptionSendHour * time.Hour)
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// subscriptionPeriod is the completed week or month before a scheduled run
+func subscriptionPeriod(frequency string, runAt time.Time) dateRange {
+	end := startOfDay(runAt)
+	if frequency == "monthly" {
+		end = end.AddDate(0, 0, 1-end.Day())
+		return dateRange{Start: end.AddDate(0, -1, 0), End: end}
+	}
+	return dateRange{Start: end.AddDate(0, 0, -7), End: end}
+}
+
+func frequencyLabel(frequency string) string {
+	if frequency == "monthly" {
+		return "Monthly"
+	}
+	return "Weekly"
+}
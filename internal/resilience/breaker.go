@@ -0,0 +1,105 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"wallet-point/internal/metrics"
+)
+
+// ErrOpen is returned instead of calling a dependency whose circuit is open
+var ErrOpen = errors.New("circuit open: dependency is failing, try again later")
+
+// Circuit states, also exported as the walletpoint_circuit_state gauge
+const (
+	StateClosed   = "closed"
+	StateHalfOpen = "half_open"
+	StateOpen     = "open"
+)
+
+var stateValues = map[string]float64{StateClosed: 0, StateHalfOpen: 1, StateOpen: 2}
+
+// Breaker stops calls to a dependency after threshold consecutive failures. While
+// open every call fails fast with ErrOpen; after cooldown one probe call is let
+// through (half-open) and its outcome closes or re-opens the circuit. A nil
+// Breaker never opens.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	b := &Breaker{name: name, threshold: threshold, cooldown: cooldown, state: StateClosed}
+	metrics.CircuitState.WithLabelValues(name).Set(stateValues[StateClosed])
+	return b
+}
+
+// Allow reports whether a call may go ahead, returning ErrOpen when it may not.
+// Every allowed call must be followed by Record.
+func (b *Breaker) Allow() error {
+	if b == nil || b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrOpen
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record counts the outcome of an allowed call
+func (b *Breaker) Record(err error) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		b.setState(StateClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(StateOpen)
+	}
+}
+
+// State returns the current state, for health and admin endpoints
+func (b *Breaker) State() string {
+	if b == nil {
+		return StateClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) setState(state string) {
+	b.state = state
+	metrics.CircuitState.WithLabelValues(b.name).Set(stateValues[state])
+}
@@ -0,0 +1,103 @@
+// Package resilience is the shared timeout, retry and circuit-breaking layer for
+// calls to external services (SMTP, the SMS gateway, webhook receivers, payment
+// providers), so a slow or failing provider costs a bounded amount of time and
+// is not hammered while it is down.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+	"wallet-point/internal/metrics"
+)
+
+// Policy describes how one external dependency is called
+type Policy struct {
+	Name        string        // metrics label, e.g. "smtp"
+	Timeout     time.Duration // per attempt; 0 means no timeout
+	MaxAttempts int           // including the first; values below 1 mean 1
+	BaseDelay   time.Duration // backoff before the second attempt
+	MaxDelay    time.Duration // backoff cap
+	Breaker     *Breaker      // nil disables circuit breaking
+
+	// Retryable decides whether a failed attempt is tried again; nil retries every
+	// error. ErrOpen and a cancelled or expired ctx are never retried.
+	Retryable func(error) bool
+}
+
+// Do calls fn until it succeeds, returns an error that is not retryable, or the
+// attempts run out, sleeping a jittered exponential backoff between attempts.
+// Each attempt gets its own ctx deadline of Timeout.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	attempts := max(p.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = p.attempt(ctx, fn)
+		metrics.ExternalCalls.WithLabelValues(p.Name, resultLabel(err)).Inc()
+		if err == nil || attempt == attempts || !p.retryable(ctx, err) {
+			return err
+		}
+
+		timer := time.NewTimer(Backoff(attempt, p.BaseDelay, p.MaxDelay))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+func (p Policy) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := p.Breaker.Allow(); err != nil {
+		return err
+	}
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	err := fn(ctx)
+	p.Breaker.Record(err)
+	return err
+}
+
+func (p Policy) retryable(ctx context.Context, err error) bool {
+	if errors.Is(err, ErrOpen) || ctx.Err() != nil {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// Backoff is the delay before retry number attempt (1 for the first retry): base
+// doubled per attempt, capped at max, with up to half of it randomised so clients
+// that failed together do not retry together
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 1; i < attempt && (max <= 0 || delay < max); i++ {
+		delay *= 2
+	}
+	if max > 0 && delay > max {
+		delay = max
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+func resultLabel(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrOpen):
+		return "rejected"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
@@ -0,0 +1,52 @@
+package saga
+
+import (
+	"net/http"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SagaHandler struct {
+	coordinator *Coordinator
+}
+
+func NewSagaHandler(coordinator *Coordinator) *SagaHandler {
+	return &SagaHandler{coordinator: coordinator}
+}
+
+// GetAll handles listing sagas (Super admin)
+// @Summary Get sagas
+// @Description Multi-step operations such as purchases and checkouts with their progress. A failed one shows the failed step and error; compensating ones are still being rolled back and are retried by the saga_recovery job. A running one with an error failed after its pivot step (e.g. after delivery) and is completed by the saga_recovery job instead.
+// @Tags Admin - Sagas
+// @Security BearerAuth
+// @Produce json
+// @Param type query string false "Filter by saga type, e.g. marketplace.order.v2"
+// @Param status query string false "Filter by status" Enums(running, completed, compensating, compensated)
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=SagaListResponse}
+// @Router /admin/sagas [get]
+func (h *SagaHandler) GetAll(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "saga_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.coordinator.GetSagas(c.Request.Context(), SagaListParams{
+		Type:   c.Query("type"),
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve sagas", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Sagas retrieved successfully", "sagas", response.Sagas, response.Pagination, nil)
+}
@@ -0,0 +1,50 @@
+package saga
+
+import (
+	"encoding/json"
+	"time"
+	"wallet-point/utils"
+)
+
+// Saga statuses. A running saga that fails turns compensating and, once every
+// completed step is undone, compensated; one that fails past its pivot step
+// stays running until Recover completes it.
+const (
+	StatusRunning      = "running"
+	StatusCompleted    = "completed"
+	StatusCompensating = "compensating"
+	StatusCompensated  = "compensated"
+)
+
+// Saga is the persisted progress of one run of a Definition. Step counts the
+// steps done and not yet compensated; State is the definition's state as of
+// the last step, which is all a compensation gets to work with.
+type Saga struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	TenantID    uint            `json:"tenant_id" gorm:"index"`
+	Type        string          `json:"type" gorm:"size:100;not null;index"`
+	Status      string          `json:"status" gorm:"type:enum('running','completed','compensating','compensated');default:'running';index:idx_sagas_status,priority:1"`
+	Step        int             `json:"step" gorm:"not null;default:0"`
+	State       json.RawMessage `json:"state" gorm:"type:text" swaggertype:"object"`
+	FailedStep  string          `json:"failed_step,omitempty" gorm:"size:100"`
+	Error       string          `json:"error,omitempty" gorm:"size:500"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"index:idx_sagas_status,priority:2"`
+	CompletedAt *time.Time      `json:"completed_at"`
+}
+
+func (Saga) TableName() string {
+	return "sagas"
+}
+
+type SagaListParams struct {
+	Type   string
+	Status string
+	Page   int
+	Limit  int
+}
+
+type SagaListResponse struct {
+	Sagas []Saga `json:"sagas"`
+	utils.Pagination
+}
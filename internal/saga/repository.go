@@ -0,0 +1,71 @@
+package saga
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, saga *Saga) error {
+	return r.db.WithContext(ctx).Create(saga).Error
+}
+
+// Lock loads a saga for update within tx; steps and compensations of one saga
+// never run at the same time
+func (r *Repository) Lock(tx *gorm.DB, id uint) (*Saga, error) {
+	var saga Saga
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&saga, id).Error; err != nil {
+		return nil, err
+	}
+	return &saga, nil
+}
+
+func (r *Repository) Update(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	return tx.Model(&Saga{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindStale returns the IDs of sagas left running since before runningBefore,
+// whose process presumably died, and of sagas whose compensation stopped
+// before compensatingBefore
+func (r *Repository) FindStale(ctx context.Context, runningBefore, compensatingBefore time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&Saga{}).
+		Where("(status = ? AND updated_at < ?) OR (status = ? AND updated_at < ?)",
+			StatusRunning, runningBefore, StatusCompensating, compensatingBefore).
+		Order("id ASC").
+		Limit(500).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindAll lists sagas newest first
+func (r *Repository) FindAll(ctx context.Context, params SagaListParams) ([]Saga, int64, error) {
+	var sagas []Saga
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Saga{})
+	if params.Type != "" {
+		query = query.Where("type = ?", params.Type)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("id DESC").Limit(params.Limit).Offset(offset).Find(&sagas).Error
+	return sagas, total, err
+}
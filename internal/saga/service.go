@@ -0,0 +1,366 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrAborted is returned by Run when the saga was taken over by the recovery
+// job between two steps, which only happens after a very slow step
+var ErrAborted = errors.New("saga aborted while running")
+
+// Step is one forward action and the action undoing it. Run and Compensate get
+// the transaction the step's progress is recorded in, so a step is either done
+// and recorded or not done at all; a failed Run leaves nothing to undo. Both may
+// change state, which is saved with the progress.
+//
+// A Pivot step cannot be undone by the steps before it, e.g. a delivery: once
+// it is done the saga is no longer compensated, and the steps after it and
+// OnComplete are retried by Recover until they succeed.
+type Step struct {
+	Name       string
+	Run        func(tx *gorm.DB, state interface{}) error
+	Compensate func(tx *gorm.DB, state interface{}) error // nil when there is nothing to undo
+	Pivot      bool
+}
+
+// Definition is a kind of saga. NewState returns a pointer to an empty state,
+// which compensations decode the persisted state into; OnComplete, if set, runs
+// in the transaction that marks the saga completed and counts as a last step.
+type Definition struct {
+	Type       string
+	NewState   func() interface{}
+	Steps      []Step
+	OnComplete func(tx *gorm.DB, state interface{}) error
+}
+
+// committed reports whether a saga with done steps is past its last pivot step
+func (d *Definition) committed(done int) bool {
+	for i := done - 1; i >= 0; i-- {
+		if d.Steps[i].Pivot {
+			return true
+		}
+	}
+	return false
+}
+
+// Coordinator runs sagas step by step, recording their progress, and undoes
+// the completed steps of one that fails. Sagas interrupted by a crash are
+// compensated by Recover.
+type Coordinator struct {
+	repo        *Repository
+	db          *gorm.DB
+	definitions map[string]*Definition
+	staleAfter  time.Duration
+}
+
+// NewCoordinator treats a saga that made no progress for staleAfter as
+// abandoned; it must exceed the slowest step
+func NewCoordinator(repo *Repository, db *gorm.DB, staleAfter time.Duration) *Coordinator {
+	return &Coordinator{repo: repo, db: db, definitions: make(map[string]*Definition), staleAfter: staleAfter}
+}
+
+// Register makes a definition runnable; register every definition before
+// Recover first runs, or its abandoned sagas cannot be compensated
+func (c *Coordinator) Register(definition *Definition) {
+	c.definitions[definition.Type] = definition
+}
+
+// Run executes a new saga of sagaType on state (a pointer, as NewState returns).
+// When a step fails, the steps before it are compensated and the step's error
+// is returned; a compensation that fails too is left to Recover. A failure
+// after a pivot step is left to Recover to retry and Run returns nil, since
+// the saga will complete.
+func (c *Coordinator) Run(ctx context.Context, sagaType string, state interface{}) error {
+	definition, ok := c.definitions[sagaType]
+	if !ok {
+		return fmt.Errorf("saga: unknown type %s", sagaType)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	saga := &Saga{Type: sagaType, Status: StatusRunning, State: data}
+	if err := c.repo.Create(ctx, saga); err != nil {
+		return err
+	}
+
+	for i, step := range definition.Steps {
+		err := utils.WithTx(ctx, c.db, func(tx *gorm.DB) error {
+			if err := c.claim(tx, saga.ID, i); err != nil {
+				return err
+			}
+			if err := step.Run(tx, state); err != nil {
+				return err
+			}
+			return c.record(tx, saga.ID, state, map[string]interface{}{"step": i + 1})
+		})
+		if err != nil {
+			if definition.committed(i) && !errors.Is(err, ErrAborted) {
+				c.retryLater(ctx, saga.ID, step.Name, err)
+				return nil
+			}
+			c.fail(ctx, saga.ID, step.Name, err)
+			return err
+		}
+	}
+
+	err = utils.WithTx(ctx, c.db, func(tx *gorm.DB) error {
+		if err := c.claim(tx, saga.ID, len(definition.Steps)); err != nil {
+			return err
+		}
+		if definition.OnComplete != nil {
+			if err := definition.OnComplete(tx, state); err != nil {
+				return err
+			}
+		}
+		return c.record(tx, saga.ID, state, map[string]interface{}{"status": StatusCompleted, "completed_at": time.Now()})
+	})
+	if err != nil && definition.committed(len(definition.Steps)) && !errors.Is(err, ErrAborted) {
+		c.retryLater(ctx, saga.ID, "complete", err)
+		return nil
+	}
+	if err != nil {
+		c.fail(ctx, saga.ID, "complete", err)
+	}
+	return err
+}
+
+// retryLater records why a saga past its pivot step stopped; it stays running,
+// so Recover picks it up once it is stale and finishes it
+func (c *Coordinator) retryLater(ctx context.Context, id uint, stepName string, cause error) {
+	slog.ErrorContext(ctx, "saga: step after the pivot failed, left to recovery", "saga_id", id, "failed_step", stepName, "error", cause)
+	err := c.db.WithContext(ctx).Model(&Saga{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"failed_step": stepName, "error": truncate(cause.Error())}).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "saga: recording failure", "saga_id", id, "error", err)
+	}
+}
+
+// resume runs the remaining steps of a running saga past its pivot, then
+// completes it, one transaction per step like Run
+func (c *Coordinator) resume(ctx context.Context, id uint) error {
+	for {
+		finished := false
+		err := utils.WithTx(ctx, c.db, func(tx *gorm.DB) error {
+			saga, err := c.repo.Lock(tx, id)
+			if err != nil {
+				return err
+			}
+			if saga.Status != StatusRunning {
+				finished = true
+				return nil
+			}
+
+			definition, ok := c.definitions[saga.Type]
+			if !ok {
+				return fmt.Errorf("saga: unknown type %s", saga.Type)
+			}
+			state := definition.NewState()
+			if err := json.Unmarshal(saga.State, state); err != nil {
+				return err
+			}
+			if saga.Step < len(definition.Steps) {
+				step := definition.Steps[saga.Step]
+				if err := step.Run(tx, state); err != nil {
+					return fmt.Errorf("%s: %w", step.Name, err)
+				}
+				return c.record(tx, id, state, map[string]interface{}{"step": saga.Step + 1})
+			}
+
+			finished = true
+			if definition.OnComplete != nil {
+				if err := definition.OnComplete(tx, state); err != nil {
+					return fmt.Errorf("complete: %w", err)
+				}
+			}
+			return c.record(tx, id, state, map[string]interface{}{"status": StatusCompleted, "completed_at": time.Now()})
+		})
+		if err != nil {
+			// Touch the saga so Recover waits a while before the next attempt
+			c.db.WithContext(ctx).Model(&Saga{}).Where("id = ?", id).Update("error", truncate(err.Error()))
+			return err
+		}
+		if finished {
+			return nil
+		}
+	}
+}
+
+// claim locks the saga and checks it is still running at step
+func (c *Coordinator) claim(tx *gorm.DB, id uint, step int) error {
+	saga, err := c.repo.Lock(tx, id)
+	if err != nil {
+		return err
+	}
+	if saga.Status != StatusRunning || saga.Step != step {
+		return ErrAborted
+	}
+	return nil
+}
+
+func (c *Coordinator) record(tx *gorm.DB, id uint, state interface{}, updates map[string]interface{}) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	updates["state"] = data
+	return c.repo.Update(tx, id, updates)
+}
+
+// fail switches a running saga to compensating and compensates it right away
+func (c *Coordinator) fail(ctx context.Context, id uint, stepName string, cause error) {
+	if errors.Is(cause, ErrAborted) {
+		return // the recovery job owns it now
+	}
+	err := utils.WithTx(ctx, c.db, func(tx *gorm.DB) error {
+		saga, err := c.repo.Lock(tx, id)
+		if err != nil || saga.Status != StatusRunning {
+			return err
+		}
+		return c.repo.Update(tx, id, map[string]interface{}{
+			"status":      StatusCompensating,
+			"failed_step": stepName,
+			"error":       truncate(cause.Error()),
+		})
+	})
+	if err == nil {
+		err = c.compensate(ctx, id)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "saga: compensation failed, left to recovery", "saga_id", id, "failed_step", stepName, "error", err)
+	}
+}
+
+// compensate undoes the completed steps of a compensating saga, last first, one
+// transaction per step, so it resumes where it stopped when called again
+func (c *Coordinator) compensate(ctx context.Context, id uint) error {
+	for {
+		finished := false
+		err := utils.WithTx(ctx, c.db, func(tx *gorm.DB) error {
+			saga, err := c.repo.Lock(tx, id)
+			if err != nil {
+				return err
+			}
+			if saga.Status != StatusCompensating {
+				finished = true
+				return nil
+			}
+			if saga.Step == 0 {
+				finished = true
+				return c.repo.Update(tx, id, map[string]interface{}{"status": StatusCompensated, "completed_at": time.Now()})
+			}
+
+			definition, ok := c.definitions[saga.Type]
+			if !ok {
+				return fmt.Errorf("saga: unknown type %s", saga.Type)
+			}
+			state := definition.NewState()
+			if err := json.Unmarshal(saga.State, state); err != nil {
+				return err
+			}
+			if step := definition.Steps[saga.Step-1]; step.Compensate != nil {
+				if err := step.Compensate(tx, state); err != nil {
+					return fmt.Errorf("compensate %s: %w", step.Name, err)
+				}
+			}
+			return c.record(tx, id, state, map[string]interface{}{"step": saga.Step - 1})
+		})
+		if err != nil {
+			// Touch the saga so Recover waits a while before the next attempt
+			c.db.WithContext(ctx).Model(&Saga{}).Where("id = ?", id).Update("error", truncate(err.Error()))
+			return err
+		}
+		if finished {
+			return nil
+		}
+	}
+}
+
+// Recover compensates sagas abandoned mid-run by a crashed or restarted
+// instance, finishes those abandoned past their pivot step and retries
+// compensations that failed, for the scheduler
+func (c *Coordinator) Recover(ctx context.Context) (string, error) {
+	now := time.Now()
+	ids, err := c.repo.FindStale(ctx, now.Add(-c.staleAfter), now.Add(-time.Minute))
+	if err != nil {
+		return "", err
+	}
+
+	compensated, resumed, failed := 0, 0, 0
+	for _, id := range ids {
+		forward := false
+		err := utils.WithTx(ctx, c.db, func(tx *gorm.DB) error {
+			saga, err := c.repo.Lock(tx, id)
+			if err != nil {
+				return err
+			}
+			// Re-check under the lock: a slow step may have just finished
+			if saga.Status != StatusRunning || saga.UpdatedAt.After(now.Add(-c.staleAfter)) {
+				return nil
+			}
+			if definition, ok := c.definitions[saga.Type]; ok && definition.committed(saga.Step) {
+				forward = true
+				return nil
+			}
+			return c.repo.Update(tx, id, map[string]interface{}{
+				"status": StatusCompensating,
+				"error":  "abandoned while running",
+			})
+		})
+		switch {
+		case err != nil:
+		case forward:
+			err = c.resume(ctx, id)
+		default:
+			err = c.compensate(ctx, id)
+		}
+		if err != nil {
+			failed++
+			slog.ErrorContext(ctx, "saga: recovery failed", "saga_id", id, "error", err)
+			continue
+		}
+		if forward {
+			resumed++
+		} else {
+			compensated++
+		}
+	}
+	return fmt.Sprintf("recovered %d sagas, completed %d, %d still failing", compensated, resumed, failed), nil
+}
+
+// GetSagas lists sagas (Admin)
+func (c *Coordinator) GetSagas(ctx context.Context, params SagaListParams) (*SagaListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	sagas, total, err := c.repo.FindAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if sagas == nil {
+		sagas = []Saga{}
+	}
+	return &SagaListResponse{
+		Sagas:      sagas,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+func truncate(message string) string {
+	if len(message) > 500 {
+		return message[:500]
+	}
+	return message
+}
@@ -0,0 +1,11 @@
+package scheduler
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrJobNotFound = utils.NewAppError("SCHEDULED_JOB_NOT_FOUND", http.StatusNotFound, "scheduled job not found")
+	ErrJobDisabled = utils.NewAppError("SCHEDULED_JOB_DISABLED", http.StatusConflict, "scheduled job is disabled")
+)
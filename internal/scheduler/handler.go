@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"fmt"
+	"net/http"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SchedulerHandler struct {
+	scheduler    *Scheduler
+	auditService audit.Logger
+}
+
+func NewSchedulerHandler(scheduler *Scheduler, auditService audit.Logger) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: scheduler, auditService: auditService}
+}
+
+// GetJobs handles listing the scheduled jobs (Admin)
+// @Summary Get scheduled jobs
+// @Description List cron jobs with their schedule, whether they are enabled (SCHEDULER_DISABLED_JOBS), next run and last run (Admin only)
+// @Tags Admin - Scheduler
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]JobInfo}
+// @Router /admin/scheduler/jobs [get]
+func (h *SchedulerHandler) GetJobs(c *gin.Context) {
+	jobs, err := h.scheduler.GetJobs()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve scheduled jobs", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scheduled jobs retrieved successfully", jobs)
+}
+
+// GetRuns handles listing the run history of scheduled jobs (Admin)
+// @Summary Get scheduled job runs
+// @Tags Admin - Scheduler
+// @Security BearerAuth
+// @Produce json
+// @Param job query string false "Filter by job name"
+// @Param status query string false "Filter by status" Enums(running, succeeded, failed)
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=RunListResponse}
+// @Router /admin/scheduler/runs [get]
+func (h *SchedulerHandler) GetRuns(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "scheduler_run_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.scheduler.GetRuns(RunListParams{
+		Job:    c.Query("job"),
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve job runs", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Job runs retrieved successfully", "runs", response.Runs, response.Pagination, nil)
+}
+
+// RunNow handles triggering a scheduled job immediately (Admin)
+// @Summary Run scheduled job now
+// @Description Start a job outside its schedule; follow its progress in the run history (Admin only)
+// @Tags Admin - Scheduler
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "Job name"
+// @Success 202 {object} utils.Response{data=JobRun}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/scheduler/jobs/{name}/run [post]
+func (h *SchedulerHandler) RunNow(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	run, err := h.scheduler.RunNow(c.Param("name"), adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Job started", run)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "RUN_SCHEDULED_JOB",
+		Entity:    "SCHEDULED_JOB",
+		EntityID:  run.ID,
+		Details:   fmt.Sprintf("Admin manually started scheduled job %s", run.Job),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
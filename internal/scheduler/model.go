@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+// JobRun is one execution of a scheduled job. The unique (job, scheduled_at) pair
+// makes every instance's cron tick for the same minute collapse into a single run.
+type JobRun struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Job         string     `json:"job" gorm:"size:100;not null;uniqueIndex:idx_job_runs_schedule,priority:1;index"`
+	ScheduledAt time.Time  `json:"scheduled_at" gorm:"not null;uniqueIndex:idx_job_runs_schedule,priority:2"`
+	Trigger     string     `json:"trigger" gorm:"type:enum('schedule','manual');not null"`
+	TriggeredBy *uint      `json:"triggered_by"`
+	Status      string     `json:"status" gorm:"type:enum('running','succeeded','failed');default:'running';not null"`
+	Output      string     `json:"output,omitempty" gorm:"type:text"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DurationMs  int64      `json:"duration_ms"`
+}
+
+func (JobRun) TableName() string {
+	return "scheduled_job_runs"
+}
+
+// JobInfo describes a registered job for the admin API
+type JobInfo struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`
+	Enabled     bool       `json:"enabled"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	LastRun     *JobRun    `json:"last_run,omitempty"`
+}
+
+type RunListParams struct {
+	Job    string
+	Status string
+	Page   int
+	Limit  int
+}
+
+type RunListResponse struct {
+	Runs []JobRun `json:"runs"`
+	utils.Pagination
+}
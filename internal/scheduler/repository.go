@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Claim records the start of a run; it reports false when another instance already started it
+func (r *Repository) Claim(run *JobRun) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *Repository) Update(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&JobRun{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindLast returns the most recent run of job, or nil when it never ran
+func (r *Repository) FindLast(job string) (*JobRun, error) {
+	var run JobRun
+	err := r.db.Where("job = ?", job).Order("started_at DESC").First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindAll lists runs newest first
+func (r *Repository) FindAll(params RunListParams) ([]JobRun, int64, error) {
+	var runs []JobRun
+	var total int64
+
+	query := r.db.Model(&JobRun{})
+	if params.Job != "" {
+		query = query.Where("job = ?", params.Job)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("started_at DESC").Limit(params.Limit).Offset(offset).Find(&runs).Error
+	return runs, total, err
+}
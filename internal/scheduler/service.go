@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+	"wallet-point/utils"
+
+	"github.com/robfig/cron/v3"
+)
+
+// JobFunc does the work of a scheduled job and returns a one-line summary for the run history
+type JobFunc func(ctx context.Context) (string, error)
+
+type job struct {
+	name        string
+	description string
+	schedule    string
+	enabled     bool
+	run         JobFunc
+	entryID     cron.EntryID
+}
+
+type Scheduler struct {
+	repo     *Repository
+	cron     *cron.Cron
+	jobs     map[string]*job
+	disabled map[string]bool
+	timeout  time.Duration
+}
+
+// NewScheduler skips the jobs named in disabled; each run is cancelled after timeout
+func NewScheduler(repo *Repository, disabled []string, timeout time.Duration) *Scheduler {
+	disabledSet := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		disabledSet[name] = true
+	}
+
+	return &Scheduler{
+		repo:     repo,
+		cron:     cron.New(cron.WithLocation(time.Local)),
+		jobs:     make(map[string]*job),
+		disabled: disabledSet,
+		timeout:  timeout,
+	}
+}
+
+// Register adds a job on a standard 5-field cron schedule (e.g. "30 2 * * *").
+// Disabled jobs are listed but neither scheduled nor manually runnable.
+func (s *Scheduler) Register(name, schedule, description string, run JobFunc) error {
+	j := &job{
+		name:        name,
+		description: description,
+		schedule:    schedule,
+		enabled:     !s.disabled[name],
+		run:         run,
+	}
+
+	if j.enabled {
+		entryID, err := s.cron.AddFunc(schedule, func() { s.runScheduled(j) })
+		if err != nil {
+			return fmt.Errorf("scheduler: job %s: %w", name, err)
+		}
+		j.entryID = entryID
+	}
+
+	s.jobs[name] = j
+	return nil
+}
+
+// Start runs the cron loop until shutdown, then waits for running jobs to finish
+func (s *Scheduler) Start() {
+	s.cron.Start()
+	utils.Go(func() {
+		for utils.Sleep(time.Hour) {
+		}
+		<-s.cron.Stop().Done()
+	})
+}
+
+func (s *Scheduler) runScheduled(j *job) {
+	if utils.ShuttingDown() {
+		return
+	}
+	// Every instance ticks at the same minute; the first to claim it runs the job
+	s.execute(j, TriggerSchedule, nil, time.Now().Truncate(time.Minute))
+}
+
+// RunNow starts a job outside its schedule and returns the run record (Admin)
+func (s *Scheduler) RunNow(name string, adminID uint) (*JobRun, error) {
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	if !j.enabled {
+		return nil, ErrJobDisabled
+	}
+
+	run, err := s.claim(j, TriggerManual, &adminID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	utils.Go(func() { s.finish(j, run) })
+	return run, nil
+}
+
+func (s *Scheduler) execute(j *job, trigger string, triggeredBy *uint, scheduledAt time.Time) {
+	run, err := s.claim(j, trigger, triggeredBy, scheduledAt)
+	if err != nil {
+		slog.Error("scheduler: claim run failed", "job", j.name, "error", err)
+		return
+	}
+	if run == nil {
+		return // Another instance runs it
+	}
+	s.finish(j, run)
+}
+
+// claim records a running run; it returns nil when the run was already claimed
+func (s *Scheduler) claim(j *job, trigger string, triggeredBy *uint, scheduledAt time.Time) (*JobRun, error) {
+	run := &JobRun{
+		Job:         j.name,
+		ScheduledAt: scheduledAt,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Status:      RunStatusRunning,
+		StartedAt:   time.Now(),
+	}
+	claimed, err := s.repo.Claim(run)
+	if err != nil || !claimed {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (s *Scheduler) finish(j *job, run *JobRun) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	output, err := s.safeRun(ctx, j)
+
+	finishedAt := time.Now()
+	updates := map[string]interface{}{
+		"status":      RunStatusSucceeded,
+		"output":      output,
+		"finished_at": finishedAt,
+		"duration_ms": finishedAt.Sub(run.StartedAt).Milliseconds(),
+	}
+	if err != nil {
+		updates["status"] = RunStatusFailed
+		updates["error"] = err.Error()
+		slog.Error("scheduler: job failed", "job", j.name, "trigger", run.Trigger, "error", err)
+	} else {
+		slog.Info("scheduler: job finished", "job", j.name, "trigger", run.Trigger, "output", output, "duration", finishedAt.Sub(run.StartedAt))
+	}
+
+	if err := s.repo.Update(run.ID, updates); err != nil {
+		slog.Error("scheduler: record run failed", "job", j.name, "error", err)
+	}
+}
+
+// safeRun turns a job panic into a failed run instead of crashing the scheduler
+func (s *Scheduler) safeRun(ctx context.Context, j *job) (output string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return j.run(ctx)
+}
+
+// GetJobs lists registered jobs with their next and last run (Admin)
+func (s *Scheduler) GetJobs() ([]JobInfo, error) {
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	infos := make([]JobInfo, 0, len(names))
+	for _, name := range names {
+		j := s.jobs[name]
+		info := JobInfo{
+			Name:        j.name,
+			Description: j.description,
+			Schedule:    j.schedule,
+			Enabled:     j.enabled,
+		}
+		if j.enabled {
+			if next := s.cron.Entry(j.entryID).Next; !next.IsZero() {
+				info.NextRun = &next
+			}
+		}
+
+		lastRun, err := s.repo.FindLast(name)
+		if err != nil {
+			return nil, err
+		}
+		info.LastRun = lastRun
+
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// GetRuns lists the run history (Admin)
+func (s *Scheduler) GetRuns(params RunListParams) (*RunListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	runs, total, err := s.repo.FindAll(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RunListResponse{
+		Runs:       runs,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
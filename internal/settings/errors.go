@@ -0,0 +1,11 @@
+package settings
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrSettingNotFound = utils.NewAppError("SETTING_NOT_FOUND", http.StatusNotFound, "setting not found")
+	ErrInvalidValue    = utils.NewAppError("SETTING_INVALID_VALUE", http.StatusBadRequest, "invalid setting value")
+)
@@ -0,0 +1,105 @@
+package settings
+
+import (
+	"fmt"
+	"net/http"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettingsHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewSettingsHandler(service *Service, auditService audit.Logger) *SettingsHandler {
+	return &SettingsHandler{service: service, auditService: auditService}
+}
+
+// GetAll handles listing runtime settings (Super admin)
+// @Summary Get settings
+// @Description Every setting known to this version with its type, allowed range, default and effective value; custom is true when an admin changed it
+// @Tags Admin - Settings
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]SettingResponse}
+// @Router /admin/settings [get]
+func (h *SettingsHandler) GetAll(c *gin.Context) {
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve settings", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Settings retrieved successfully", settings)
+}
+
+// Update handles changing a runtime setting (Super admin)
+// @Summary Update setting
+// @Description Set a value, given as a string and checked against the setting's type and range. Other instances pick up the change within a minute.
+// @Tags Admin - Settings
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param key path string true "Setting key, e.g. transfer.daily_limit"
+// @Param request body UpdateSettingRequest true "New value"
+// @Success 200 {object} utils.Response{data=SettingResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/settings/{key} [put]
+func (h *SettingsHandler) Update(c *gin.Context) {
+	var req UpdateSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	setting, previous, err := h.service.UpdateSetting(c.Param("key"), req.Value, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Setting updated", setting)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "UPDATE_SETTING",
+		Entity:    "SETTING",
+		Details:   fmt.Sprintf("Set %s from %q to %q", setting.Key, previous, setting.Value),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Reset handles restoring a setting's default (Super admin)
+// @Summary Reset setting
+// @Tags Admin - Settings
+// @Security BearerAuth
+// @Produce json
+// @Param key path string true "Setting key"
+// @Success 200 {object} utils.Response{data=SettingResponse}
+// @Failure 404 {object} utils.Response
+// @Router /admin/settings/{key} [delete]
+func (h *SettingsHandler) Reset(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	setting, previous, err := h.service.ResetSetting(c.Param("key"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Setting reset to its default", setting)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "RESET_SETTING",
+		Entity:    "SETTING",
+		Details:   fmt.Sprintf("Reset %s from %q to its default %q", setting.Key, previous, setting.Value),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,98 @@
+package settings
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+// Value types of a setting
+const (
+	TypeInt    = "int"
+	TypeBool   = "bool"
+	TypeString = "string"
+)
+
+// Settings known to the code. A setting without a stored value has its default,
+// which routes may take from the environment (see SetDefault).
+const (
+	TransferMaxAmount   = "transfer.max_amount"
+	TransferDailyLimit  = "transfer.daily_limit"
+	LowStockThreshold   = "marketplace.low_stock_threshold"
+	CartItemTTLDays     = "marketplace.cart_item_ttl_days"
+	DailySpendLimit     = "marketplace.daily_spend_limit"
+	PointGiftExpiryDays = "transfer.gift_expiry_days"
+
+	// Wallet anomaly detection, see internal/anomaly
+	AnomalySpendMultiplier = "anomaly.spend_multiplier"
+	AnomalyMinSpend        = "anomaly.min_spend"
+	AnomalyNightStartHour  = "anomaly.night_start_hour"
+	AnomalyNightEndHour    = "anomaly.night_end_hour"
+
+	// Alerts to super admins about an admin's volume of sensitive actions, see internal/adminwatch
+	AdminWatchWindowMinutes = "admin_watch.window_minutes"
+	AdminWatchPriceChanges  = "admin_watch.price_changes"
+	AdminWatchManualCredits = "admin_watch.manual_credits"
+	AdminWatchWalletResets  = "admin_watch.wallet_resets"
+
+	// Response cache TTLs of public read endpoints, in seconds
+	StorefrontProductsCacheSeconds   = "cache.storefront_products_seconds"
+	StorefrontCategoriesCacheSeconds = "cache.storefront_categories_seconds"
+)
+
+// Definition describes a setting: its type, default and, for ints, the
+// inclusive range an admin may set
+type Definition struct {
+	Key         string `json:"key"`
+	Type        string `json:"type" enums:"int,bool,string"`
+	Description string `json:"description"`
+	Default     string `json:"default"`
+	Min         int    `json:"min,omitempty"`
+	Max         int    `json:"max,omitempty"`
+	MaxLength   int    `json:"max_length,omitempty"` // strings
+}
+
+var definitions = []Definition{
+	{Key: TransferMaxAmount, Type: TypeInt, Description: "Largest single student-to-student transfer in points; 0 means no limit", Default: "0", Min: 0, Max: utils.MaxPointsPerOperation},
+	{Key: TransferDailyLimit, Type: TypeInt, Description: "Points a student may transfer per day in total; 0 means no limit", Default: "0", Min: 0, Max: utils.MaxPointsPerOperation},
+	{Key: PointGiftExpiryDays, Type: TypeInt, Description: "Days after delivery a points gift can be claimed before it returns to the sender", Default: "14", Min: 1, Max: 365},
+	{Key: LowStockThreshold, Type: TypeInt, Description: "Low-stock alert threshold of products without their own", Default: "5", Min: 0, Max: 100000},
+	{Key: CartItemTTLDays, Type: TypeInt, Description: "Days an untouched cart item is kept before cart_cleanup removes it", Default: "30", Min: 1, Max: 365},
+	{Key: DailySpendLimit, Type: TypeInt, Description: "Points a student may spend on marketplace orders per day in total; 0 means no limit", Default: "0", Min: 0, Max: utils.MaxPointsPerOperation},
+	{Key: AnomalySpendMultiplier, Type: TypeInt, Description: "A student's spending of a day is flagged from this many times their average daily spending of the last 30 days", Default: "10", Min: 2, Max: 1000},
+	{Key: AnomalyMinSpend, Type: TypeInt, Description: "Daily spending below this many points is never flagged as a spike", Default: "100", Min: 0, Max: utils.MaxPointsPerOperation},
+	{Key: AnomalyNightStartHour, Type: TypeInt, Description: "First hour (0-23, server time) in which wallet activity from an IP address new for the student is flagged", Default: "0", Min: 0, Max: 23},
+	{Key: AnomalyNightEndHour, Type: TypeInt, Description: "Hour (0-23) the flagged night hours end; equal to the start hour turns the check off", Default: "5", Min: 0, Max: 23},
+	{Key: AdminWatchWindowMinutes, Type: TypeInt, Description: "Window in minutes the sensitive actions of one admin are counted over", Default: "15", Min: 5, Max: 1440},
+	{Key: AdminWatchPriceChanges, Type: TypeInt, Description: "Product price changes by one admin within the window that alert the super admins; 0 turns the alert off", Default: "20", Min: 0, Max: 10000},
+	{Key: AdminWatchManualCredits, Type: TypeInt, Description: "Manual point credits by one admin within the window that alert the super admins; 0 turns the alert off", Default: "10", Min: 0, Max: 10000},
+	{Key: AdminWatchWalletResets, Type: TypeInt, Description: "Wallet resets by one admin within the window that alert the super admins; 0 turns the alert off", Default: "3", Min: 0, Max: 10000},
+	{Key: StorefrontProductsCacheSeconds, Type: TypeInt, Description: "Seconds storefront product listings and details are served from the response cache; 0 turns caching off", Default: "60", Min: 0, Max: 3600},
+	{Key: StorefrontCategoriesCacheSeconds, Type: TypeInt, Description: "Seconds the storefront category list is served from the response cache; 0 turns caching off", Default: "300", Min: 0, Max: 3600},
+}
+
+// Setting is a value set by an admin, overriding the default
+type Setting struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Key       string    `json:"key" gorm:"size:100;uniqueIndex;not null"`
+	Value     string    `json:"value" gorm:"size:1000;not null"`
+	UpdatedBy *uint     `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Setting) TableName() string {
+	return "settings"
+}
+
+// SettingResponse is a setting with its definition and effective value
+type SettingResponse struct {
+	Definition
+	Value     string     `json:"value"`
+	Custom    bool       `json:"custom"` // set by an admin rather than the default
+	UpdatedBy *uint      `json:"updated_by"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type UpdateSettingRequest struct {
+	Value string `json:"value"`
+}
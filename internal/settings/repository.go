@@ -0,0 +1,38 @@
+package settings
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) FindAll() ([]Setting, error) {
+	var settings []Setting
+	err := r.db.Order("`key` ASC").Find(&settings).Error
+	return settings, err
+}
+
+// Upsert stores value for key, creating the row on first change
+func (r *Repository) Upsert(key, value string, adminID uint) error {
+	var setting Setting
+	err := r.db.Where("`key` = ?", key).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.db.Create(&Setting{Key: key, Value: value, UpdatedBy: &adminID}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return r.db.Model(&setting).Updates(map[string]interface{}{"value": value, "updated_by": adminID}).Error
+}
+
+func (r *Repository) Delete(key string) error {
+	return r.db.Where("`key` = ?", key).Delete(&Setting{}).Error
+}
@@ -0,0 +1,224 @@
+package settings
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Service serves runtime settings: thresholds and limits admins change without
+// a redeploy. Stored values are cached for ttl, so every instance picks up a
+// change within that window.
+type Service struct {
+	repo        *Repository
+	ttl         time.Duration
+	definitions map[string]*Definition
+
+	mu       sync.RWMutex
+	values   map[string]Setting
+	loadedAt time.Time
+}
+
+func NewService(repo *Repository, ttl time.Duration) *Service {
+	s := &Service{repo: repo, ttl: ttl, definitions: make(map[string]*Definition, len(definitions))}
+	for i := range definitions {
+		definition := definitions[i]
+		s.definitions[definition.Key] = &definition
+	}
+	return s
+}
+
+// SetDefault replaces the default of key, e.g. with the value of the
+// environment variable that configured it before it became a setting
+func (s *Service) SetDefault(key string, value interface{}) {
+	definition, ok := s.definitions[key]
+	if !ok {
+		panic("settings: unknown key " + key)
+	}
+	definition.Default = fmt.Sprint(value)
+}
+
+// Int returns the value of an int setting; a stored value that no longer
+// parses falls back to the default
+func (s *Service) Int(key string) int {
+	value, err := strconv.Atoi(s.value(key))
+	if err != nil {
+		value, _ = strconv.Atoi(s.definitions[key].Default)
+	}
+	return value
+}
+
+func (s *Service) Bool(key string) bool {
+	value, err := strconv.ParseBool(s.value(key))
+	if err != nil {
+		value, _ = strconv.ParseBool(s.definitions[key].Default)
+	}
+	return value
+}
+
+func (s *Service) String(key string) string {
+	return s.value(key)
+}
+
+func (s *Service) value(key string) string {
+	definition, ok := s.definitions[key]
+	if !ok {
+		panic("settings: unknown key " + key)
+	}
+	s.refresh()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if setting, ok := s.values[key]; ok {
+		return setting.Value
+	}
+	return definition.Default
+}
+
+// GetSettings lists every known setting with its effective value (Admin)
+func (s *Service) GetSettings() ([]SettingResponse, error) {
+	stored, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	byKey := indexSettings(stored)
+
+	response := make([]SettingResponse, 0, len(definitions))
+	for _, known := range definitions {
+		response = append(response, s.describe(s.definitions[known.Key], byKey))
+	}
+	return response, nil
+}
+
+// UpdateSetting validates and stores a value (Admin); the change applies to
+// this instance immediately. It returns the setting and its previous value.
+func (s *Service) UpdateSetting(key, value string, adminID uint) (*SettingResponse, string, error) {
+	definition, ok := s.definitions[key]
+	if !ok {
+		return nil, "", ErrSettingNotFound
+	}
+	value, err := definition.normalize(value)
+	if err != nil {
+		return nil, "", err
+	}
+
+	previous := s.value(key)
+	if err := s.repo.Upsert(key, value, adminID); err != nil {
+		return nil, "", err
+	}
+	s.invalidate()
+
+	setting, err := s.get(definition)
+	return setting, previous, err
+}
+
+// ResetSetting drops the stored value of key, restoring its default (Admin)
+func (s *Service) ResetSetting(key string) (*SettingResponse, string, error) {
+	definition, ok := s.definitions[key]
+	if !ok {
+		return nil, "", ErrSettingNotFound
+	}
+
+	previous := s.value(key)
+	if err := s.repo.Delete(key); err != nil {
+		return nil, "", err
+	}
+	s.invalidate()
+
+	setting, err := s.get(definition)
+	return setting, previous, err
+}
+
+func (s *Service) get(definition *Definition) (*SettingResponse, error) {
+	stored, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	setting := s.describe(definition, indexSettings(stored))
+	return &setting, nil
+}
+
+func (s *Service) describe(definition *Definition, stored map[string]Setting) SettingResponse {
+	response := SettingResponse{Definition: *definition, Value: definition.Default}
+	if setting, ok := stored[definition.Key]; ok {
+		response.Value = setting.Value
+		response.Custom = true
+		response.UpdatedBy = setting.UpdatedBy
+		updatedAt := setting.UpdatedAt
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
+
+// normalize checks value against the definition and returns its canonical form
+func (d *Definition) normalize(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch d.Type {
+	case TypeInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s must be a whole number", ErrInvalidValue, d.Key)
+		}
+		if n < d.Min || n > d.Max {
+			return "", fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidValue, d.Key, d.Min, d.Max)
+		}
+		return strconv.Itoa(n), nil
+	case TypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s must be true or false", ErrInvalidValue, d.Key)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	if d.MaxLength > 0 && len(value) > d.MaxLength {
+		return "", fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidValue, d.Key, d.MaxLength)
+	}
+	return value, nil
+}
+
+// refresh reloads the stored values once the cache is older than ttl. When the
+// database is unreachable the last known values (or the defaults) stay in use.
+func (s *Service) refresh() {
+	s.mu.RLock()
+	fresh := s.values != nil && time.Since(s.loadedAt) < s.ttl
+	s.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values != nil && time.Since(s.loadedAt) < s.ttl {
+		return
+	}
+
+	stored, err := s.repo.FindAll()
+	if err != nil {
+		slog.Error("settings: reload failed", "error", err)
+		if s.values == nil {
+			s.values = map[string]Setting{}
+		}
+		// Retry on the next round instead of on every call
+		s.loadedAt = time.Now()
+		return
+	}
+	s.values = indexSettings(stored)
+	s.loadedAt = time.Now()
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func indexSettings(settings []Setting) map[string]Setting {
+	index := make(map[string]Setting, len(settings))
+	for _, setting := range settings {
+		index[setting.Key] = setting
+	}
+	return index
+}
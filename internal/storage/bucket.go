@@ -0,0 +1,318 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-point/internal/resilience"
+)
+
+const (
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFormat   = "20060102T150405Z"
+)
+
+// Bucket keeps files in an S3 bucket, or any service speaking the S3 API with
+// Signature Version 4 (Google Cloud Storage's XML API, MinIO). Calls go through
+// policy: network errors, timeouts, 5xx and 429 replies are retried.
+type Bucket struct {
+	base      *url.URL // bucket URL; object keys are appended to its path
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+	policy    resilience.Policy
+}
+
+// NewBucket addresses bucket virtual-hosted on AWS when endpoint is empty, and
+// path-style (endpoint/bucket) otherwise
+func NewBucket(endpoint, bucket, region, accessKey, secretKey string, policy resilience.Policy) (*Bucket, error) {
+	raw := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+	if endpoint != "" {
+		raw = strings.TrimSuffix(endpoint, "/") + "/" + bucket
+	}
+	base, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("storage: invalid endpoint: %w", err)
+	}
+
+	if policy.Retryable == nil {
+		policy.Retryable = retryableBucketError
+	}
+	return &Bucket{
+		base:      base,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{},
+		policy:    policy,
+	}, nil
+}
+
+// bucketStatusError is a non-2xx reply of the storage service
+type bucketStatusError struct {
+	status int
+	body   string
+}
+
+func (e *bucketStatusError) Error() string {
+	return fmt.Sprintf("storage service returned status %d: %s", e.status, e.body)
+}
+
+// retryableBucketError retries everything except a 4xx other than 429, which
+// means the request itself was rejected
+func retryableBucketError(err error) bool {
+	statusErr, ok := err.(*bucketStatusError)
+	if !ok {
+		return err != ErrFileNotFound
+	}
+	return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+}
+
+func (b *Bucket) Put(ctx context.Context, key string, body io.ReadSeeker, size int64, contentType string) error {
+	return b.policy.Do(ctx, func(ctx context.Context) error {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		req, err := b.request(ctx, http.MethodPut, key, nil, io.NopCloser(body))
+		if err != nil {
+			return err
+		}
+		req.ContentLength = size
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		return b.do(req, nil)
+	})
+}
+
+func (b *Bucket) Stat(ctx context.Context, key string) (*Object, error) {
+	var object *Object
+	err := b.policy.Do(ctx, func(ctx context.Context) error {
+		req, err := b.request(ctx, http.MethodHead, key, nil, nil)
+		if err != nil {
+			return err
+		}
+		return b.do(req, func(resp *http.Response) error {
+			modified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+			object = &Object{Key: key, Size: resp.ContentLength, ModifiedAt: modified}
+			return nil
+		})
+	})
+	return object, err
+}
+
+func (b *Bucket) Delete(ctx context.Context, key string) error {
+	return b.policy.Do(ctx, func(ctx context.Context) error {
+		req, err := b.request(ctx, http.MethodDelete, key, nil, nil)
+		if err != nil {
+			return err
+		}
+		// Deleting a missing object succeeds on S3, but not on every S3-compatible server
+		if err := b.do(req, nil); err != nil && err != ErrFileNotFound {
+			return err
+		}
+		return nil
+	})
+}
+
+// listBucketResult is the reply of ListObjectsV2
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (b *Bucket) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		var page listBucketResult
+		err := b.policy.Do(ctx, func(ctx context.Context) error {
+			req, err := b.request(ctx, http.MethodGet, "", query, nil)
+			if err != nil {
+				return err
+			}
+			return b.do(req, func(resp *http.Response) error {
+				page = listBucketResult{}
+				return xml.NewDecoder(resp.Body).Decode(&page)
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, content := range page.Contents {
+			objects = append(objects, Object{Key: content.Key, Size: content.Size, ModifiedAt: content.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// SignedURL presigns a GET of key in the query string, so the link works in a
+// browser without credentials
+func (b *Bucket) SignedURL(ctx context.Context, key string, ttl time.Duration, filename string) (string, error) {
+	now := time.Now().UTC()
+	target := b.objectURL(key)
+
+	query := url.Values{
+		"X-Amz-Algorithm":     {signAlgorithm},
+		"X-Amz-Credential":    {b.accessKey + "/" + b.scope(now)},
+		"X-Amz-Date":          {now.Format(amzDateFormat)},
+		"X-Amz-Expires":       {strconv.Itoa(int(ttl.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	if filename != "" {
+		query.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	target.RawQuery = canonicalQuery(query)
+
+	signature := b.signature(now, http.MethodGet, target, http.Header{}, []string{"host"}, unsignedPayload)
+	target.RawQuery += "&X-Amz-Signature=" + signature
+	return target.String(), nil
+}
+
+// request builds a header-signed request for key (the bucket itself when empty)
+func (b *Bucket) request(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	target := b.objectURL(key)
+	target.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	signature := b.signature(now, method, target, req.Header, signed, unsignedPayload)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, b.accessKey, b.scope(now), strings.Join(signed, ";"), signature))
+	return req, nil
+}
+
+// do sends req and hands a 2xx reply to read (when set); a 404 is ErrFileNotFound
+func (b *Bucket) do(req *http.Request, read func(*http.Response) error) error {
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrFileNotFound
+	case resp.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &bucketStatusError{status: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	case read != nil:
+		return read(resp)
+	}
+	return nil
+}
+
+func (b *Bucket) objectURL(key string) *url.URL {
+	target := *b.base
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/" + key
+	target.RawPath = strings.TrimSuffix(b.base.EscapedPath(), "/") + "/" + uriEncode(key, false)
+	return &target
+}
+
+func (b *Bucket) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + b.region + "/s3/aws4_request"
+}
+
+// signature computes the Signature Version 4 of a request whose signedHeaders
+// (lower case, sorted) are taken from header, apart from host
+func (b *Bucket) signature(now time.Time, method string, target *url.URL, header http.Header, signedHeaders []string, payloadHash string) string {
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		value := header.Get(name)
+		if name == "host" {
+			value = target.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonical := strings.Join([]string{
+		method,
+		target.EscapedPath(),
+		target.RawQuery,
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{signAlgorithm, now.Format(amzDateFormat), b.scope(now), hex.EncodeToString(hash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+b.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, b.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query sorted by name, as Signature Version 4 requires
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but the RFC 3986 unreserved characters;
+// slashes are kept unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			out.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			out.WriteByte(c)
+		default:
+			fmt.Fprintf(&out, "%%%02X", c)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,11 @@
+package storage
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrFileNotFound = utils.NewAppError("FILE_NOT_FOUND", http.StatusNotFound, "file not found")
+	ErrLinkExpired  = utils.NewAppError("FILE_LINK_EXPIRED", http.StatusForbidden, "download link is invalid or has expired")
+)
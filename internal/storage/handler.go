@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxUploadSize caps the generic upload endpoint
+const MaxUploadSize = 10 << 20 // 10MB
+
+type Handler struct {
+	files Storage
+	ttl   time.Duration
+}
+
+// NewHandler serves the files of files; signed links are valid for ttl
+func NewHandler(files Storage, ttl time.Duration) *Handler {
+	return &Handler{files: files, ttl: ttl}
+}
+
+// SaveUpload stores an uploaded file under a fresh key below prefix and returns
+// the URL it is served from
+func SaveUpload(ctx context.Context, files Storage, prefix string, file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	key := NewKey(prefix, file.Filename)
+	if err := files.Put(ctx, key, src, file.Size, file.Header.Get("Content-Type")); err != nil {
+		return "", err
+	}
+	return URL(key), nil
+}
+
+// Upload handles the generic file upload
+// @Summary Upload file
+// @Description Upload a file (at most 10MB) and get the URL it is served from
+// @Tags Files
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /upload [post]
+func (h *Handler) Upload(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "No file uploaded", nil)
+		return
+	}
+
+	if file.Size > MaxUploadSize {
+		utils.ErrorResponse(c, http.StatusBadRequest, "File size exceeds limit (10MB)", nil)
+		return
+	}
+
+	fileURL, err := SaveUpload(c.Request.Context(), h.files, "", file)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save file", err.Error())
+		return
+	}
+
+	// Return relative URL for storage and retrieval
+	utils.SuccessResponse(c, http.StatusOK, "File uploaded successfully", gin.H{
+		"file_url": fileURL,
+	})
+}
+
+// Serve answers GET /uploads/<key>. The local driver sends the file; bucket
+// drivers redirect to a short-lived signed link. Private files (exports) need the
+// signature of a local SignedURL.
+func (h *Handler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !ValidKey(key) {
+		utils.ServiceErrorResponse(c, http.StatusNotFound, ErrFileNotFound)
+		return
+	}
+
+	local, isLocal := h.files.(*Local)
+	if !isLocal {
+		if IsPrivate(key) {
+			utils.ServiceErrorResponse(c, http.StatusNotFound, ErrFileNotFound)
+			return
+		}
+		h.redirect(c, key, "")
+		return
+	}
+
+	if IsPrivate(key) && !local.Verify(key, c.Query("expires"), c.Query("signature")) {
+		utils.ServiceErrorResponse(c, http.StatusForbidden, ErrLinkExpired)
+		return
+	}
+	if _, err := local.Stat(c.Request.Context(), key); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	if filename := c.Query("download"); filename != "" {
+		c.FileAttachment(local.Path(key), filename)
+		return
+	}
+	c.File(local.Path(key))
+}
+
+// Download sends the file of key as an attachment named filename: straight from
+// disk for the local driver, through a signed link otherwise
+func (h *Handler) Download(c *gin.Context, key, filename string) {
+	if local, ok := h.files.(*Local); ok {
+		c.FileAttachment(local.Path(key), filename)
+		return
+	}
+	h.redirect(c, key, filename)
+}
+
+func (h *Handler) redirect(c *gin.Context, key, filename string) {
+	link, err := h.files.SignedURL(c.Request.Context(), key, h.ttl, filename)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "storage: signing link failed", "key", key, "error", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load file", nil)
+		return
+	}
+	c.Redirect(http.StatusTemporaryRedirect, link)
+}
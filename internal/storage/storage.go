@@ -0,0 +1,141 @@
+// Package storage keeps uploaded and generated files (product images, mission
+// submission receipts, report exports) on the local disk, in S3 or in Google
+// Cloud Storage behind one interface. Files are addressed by slash separated keys
+// such as "products/17_mug.png" and handed out through /uploads/<key>; private
+// files are only reachable with a signed, expiring link.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"wallet-point/internal/resilience"
+)
+
+// Key prefixes of the files the API stores
+const (
+	PrefixProducts    = "products"
+	PrefixSubmissions = "submissions"
+	PrefixExports     = "exports"
+	PrefixArchives    = "archives"
+)
+
+// privatePrefixes are never served without a signed link
+var privatePrefixes = []string{PrefixExports + "/", PrefixArchives + "/"}
+
+// Object describes a stored file
+type Object struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// Storage is a store of files addressed by key
+type Storage interface {
+	// Put stores size bytes of body under key, replacing any file already there
+	Put(ctx context.Context, key string, body io.ReadSeeker, size int64, contentType string) error
+	// Stat returns ErrFileNotFound when there is no file under key
+	Stat(ctx context.Context, key string) (*Object, error)
+	// Delete removes the file under key; a missing file is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns every file whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// SignedURL returns a link to key that works without credentials until ttl
+	// passes. A non-empty filename makes browsers download the file under that name.
+	SignedURL(ctx context.Context, key string, ttl time.Duration, filename string) (string, error)
+}
+
+// Config selects and configures the storage driver
+type Config struct {
+	Driver    string // local, s3 or gcs
+	LocalPath string // root directory of the local driver
+	Secret    string // signs the local driver's links
+
+	Bucket    string
+	Region    string
+	Endpoint  string // S3-compatible server; empty uses AWS or Google Cloud Storage
+	AccessKey string
+	SecretKey string
+	Policy    resilience.Policy // timeouts, retries and circuit breaker of s3 and gcs calls
+}
+
+// New returns the storage driver selected by cfg
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocal(cfg.LocalPath, cfg.Secret), nil
+	case "s3":
+		return NewBucket(cfg.Endpoint, cfg.Bucket, cfg.Region, cfg.AccessKey, cfg.SecretKey, cfg.Policy)
+	case "gcs":
+		// The XML API accepts S3 requests signed with a GCS HMAC key
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = "https://storage.googleapis.com"
+		}
+		return NewBucket(endpoint, cfg.Bucket, "auto", cfg.AccessKey, cfg.SecretKey, cfg.Policy)
+	}
+	return nil, fmt.Errorf("storage: unknown driver %q", cfg.Driver)
+}
+
+// NewKey returns a fresh key under prefix for an upload named filename, keeping
+// only its extension so user-chosen names never reach the storage path
+func NewKey(prefix, filename string) string {
+	name := fmt.Sprintf("%d_%d%s", time.Now().UnixNano(), rand.Intn(1000), strings.ToLower(filepath.Ext(filename)))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+// URL is the path a stored file is served from (see Handler.Serve)
+func URL(key string) string {
+	return "/uploads/" + key
+}
+
+// KeyFromURL returns the key of a URL returned by URL, and false for any other
+// URL (e.g. an external image link)
+func KeyFromURL(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, "/uploads/")
+	return key, ok && ValidKey(key)
+}
+
+// ValidKey reports whether key is a clean relative path that cannot escape the store
+func ValidKey(key string) bool {
+	return key != "" && path.Clean(key) == key && !path.IsAbs(key) && key != ".." && !strings.HasPrefix(key, "../")
+}
+
+// IsPrivate reports whether key is only served through a signed link
+func IsPrivate(key string) bool {
+	for _, prefix := range privatePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Cleanup deletes the files under prefix last written before cutoff and returns
+// how many were removed
+func Cleanup(ctx context.Context, s Storage, prefix string, cutoff time.Time) (int, error) {
+	objects, err := s.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, object := range objects {
+		if !object.ModifiedAt.Before(cutoff) {
+			continue
+		}
+		if err := s.Delete(ctx, object.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,13 @@
+package storefront
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrInvalidAPIKey   = utils.NewAppError("STOREFRONT_INVALID_API_KEY", http.StatusUnauthorized, "storefront API key is missing, invalid or revoked")
+	ErrRateLimited     = utils.NewAppError("STOREFRONT_RATE_LIMITED", http.StatusTooManyRequests, "storefront API key exceeded its request limit")
+	ErrKeyNotFound     = utils.NewAppError("STOREFRONT_KEY_NOT_FOUND", http.StatusNotFound, "storefront API key not found")
+	ErrProductNotFound = utils.NewAppError("STOREFRONT_PRODUCT_NOT_FOUND", http.StatusNotFound, "product not found")
+)
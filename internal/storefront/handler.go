@@ -0,0 +1,275 @@
+package storefront
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the storefront API key
+const APIKeyHeader = "X-API-Key"
+
+type StorefrontHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewStorefrontHandler(service *Service, auditService audit.Logger) *StorefrontHandler {
+	return &StorefrontHandler{service: service, auditService: auditService}
+}
+
+// RequireKey authenticates a site by its X-API-Key, applies the key's request
+// limit and scopes the request to the key's campus
+func (h *StorefrontHandler) RequireKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := h.service.Authenticate(c.Request.Context(), c.GetHeader(APIKeyHeader))
+		if err != nil {
+			utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+			c.Abort()
+			return
+		}
+		if ok, retryAfter := h.service.Allow(key); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			utils.ServiceErrorResponse(c, http.StatusTooManyRequests, ErrRateLimited)
+			c.Abort()
+			return
+		}
+
+		c.Set("tenant_id", key.TenantID)
+		c.Request = c.Request.WithContext(utils.WithTenant(c.Request.Context(), key.TenantID))
+		c.Next()
+	}
+}
+
+// GetProducts handles listing the public catalog
+// @Summary List storefront products
+// @Description Active products of the API key's campus, newest first, without stock counts or internal fields. Prices are included unless STOREFRONT_SHOW_PRICES is off. Responses are cached for the cache.storefront_products_seconds setting; product changes drop them.
+// @Tags Storefront
+// @Produce json
+// @Param X-API-Key header string true "Storefront API key"
+// @Param category_id query int false "Only products in this category"
+// @Param search query string false "Match product names"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=ProductListResponse}
+// @Header 200 {string} X-Cache "HIT when served from the response cache, MISS when it was enabled but missed"
+// @Failure 401 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Router /storefront/products [get]
+func (h *StorefrontHandler) GetProducts(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	categoryID, ok := utils.QueryInt(c, "category_id", 0, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetProducts(c.Request.Context(), ProductListParams{
+		CategoryID: uint(categoryID),
+		Search:     strings.TrimSpace(c.Query("search")),
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve products", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Products retrieved successfully", "products", response.Products, response.Pagination, nil)
+}
+
+// GetProduct handles showing one product of the public catalog
+// @Summary Get storefront product
+// @Tags Storefront
+// @Produce json
+// @Param X-API-Key header string true "Storefront API key"
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.Response{data=Product}
+// @Header 200 {string} X-Cache "HIT when served from the response cache, MISS when it was enabled but missed"
+// @Failure 404 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Router /storefront/products/{id} [get]
+func (h *StorefrontHandler) GetProduct(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	product, err := h.service.GetProduct(c.Request.Context(), uint(productID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product retrieved successfully", product)
+}
+
+// GetCategories handles listing the catalog categories
+// @Summary List storefront categories
+// @Description Every category with the number of active products of the API key's campus in it; parent_id builds the tree. Cached for the cache.storefront_categories_seconds setting.
+// @Tags Storefront
+// @Produce json
+// @Param X-API-Key header string true "Storefront API key"
+// @Success 200 {object} utils.Response{data=[]Category}
+// @Header 200 {string} X-Cache "HIT when served from the response cache, MISS when it was enabled but missed"
+// @Failure 429 {object} utils.Response
+// @Router /storefront/categories [get]
+func (h *StorefrontHandler) GetCategories(c *gin.Context) {
+	categories, err := h.service.GetCategories(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve categories", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", categories)
+}
+
+// GetKeys handles listing the storefront API keys (Admin)
+// @Summary List storefront API keys
+// @Tags Admin - Storefront
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]APIKey}
+// @Router /admin/storefront/keys [get]
+func (h *StorefrontHandler) GetKeys(c *gin.Context) {
+	keys, err := h.service.GetKeys(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve storefront API keys", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Storefront API keys retrieved successfully", keys)
+}
+
+// CreateKey handles issuing a storefront API key (Admin)
+// @Summary Create storefront API key
+// @Description The response holds the API key; it is not shown again. The key reads the catalog of the admin's campus, at most rate_limit requests per minute (0 uses STOREFRONT_RATE_LIMIT).
+// @Tags Admin - Storefront
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateKeyRequest true "API key"
+// @Success 201 {object} utils.Response{data=KeyWithSecret}
+// @Failure 400 {object} utils.Response
+// @Router /admin/storefront/keys [post]
+func (h *StorefrontHandler) CreateKey(c *gin.Context) {
+	var req CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	key, err := h.service.CreateKey(c.Request.Context(), req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Storefront API key created", key)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_STOREFRONT_KEY",
+		Entity:    "STOREFRONT_KEY",
+		EntityID:  key.ID,
+		Details:   fmt.Sprintf("Issued storefront API key %s (key %s...)", key.Name, key.KeyPrefix),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateKey handles changing a storefront API key (Admin)
+// @Summary Update storefront API key
+// @Description Rename it, change its request limit, or deactivate it (active=false) to refuse it
+// @Tags Admin - Storefront
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Key ID"
+// @Param request body UpdateKeyRequest true "Fields to change"
+// @Success 200 {object} utils.Response{data=APIKey}
+// @Failure 404 {object} utils.Response
+// @Router /admin/storefront/keys/{id} [put]
+func (h *StorefrontHandler) UpdateKey(c *gin.Context) {
+	keyID, ok := keyIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	key, err := h.service.UpdateKey(c.Request.Context(), keyID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Storefront API key updated", key)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_STOREFRONT_KEY",
+		Entity:    "STOREFRONT_KEY",
+		EntityID:  key.ID,
+		Details:   fmt.Sprintf("Updated storefront API key %s: rate_limit=%d, active=%t", key.Name, key.RateLimit, key.Active),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// RotateKey handles replacing a storefront API key (Admin)
+// @Summary Rotate storefront API key
+// @Description The old key stops working immediately; the response holds the new one
+// @Tags Admin - Storefront
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Key ID"
+// @Success 200 {object} utils.Response{data=KeyWithSecret}
+// @Failure 404 {object} utils.Response
+// @Router /admin/storefront/keys/{id}/rotate-key [post]
+func (h *StorefrontHandler) RotateKey(c *gin.Context) {
+	keyID, ok := keyIDParam(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.service.RotateKey(c.Request.Context(), keyID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Storefront API key rotated", key)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "ROTATE_STOREFRONT_KEY",
+		Entity:    "STOREFRONT_KEY",
+		EntityID:  key.ID,
+		Details:   fmt.Sprintf("Rotated storefront API key %s (now %s...)", key.Name, key.KeyPrefix),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+func keyIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid storefront API key ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,77 @@
+package storefront
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+// APIKey lets a site (e.g. the campus marketing site) read the catalog of its
+// campus. Only the SHA-256 hash of the key is stored.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	TenantID   uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	KeyPrefix  string     `json:"key_prefix" gorm:"size:20;not null"` // shown to tell keys apart
+	KeyHash    string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	RateLimit  int        `json:"rate_limit" gorm:"default:0;not null"` // requests per minute, 0 uses STOREFRONT_RATE_LIMIT
+	Active     bool       `json:"active" gorm:"default:true;not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedBy  uint       `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (APIKey) TableName() string {
+	return "storefront_api_keys"
+}
+
+type CreateKeyRequest struct {
+	Name      string `json:"name" binding:"required,max=100" example:"Website kampus"`
+	RateLimit int    `json:"rate_limit" binding:"min=0,max=10000"`
+}
+
+type UpdateKeyRequest struct {
+	Name      *string `json:"name" binding:"omitempty,max=100"`
+	RateLimit *int    `json:"rate_limit" binding:"omitempty,min=0,max=10000"`
+	Active    *bool   `json:"active"`
+}
+
+// KeyWithSecret is returned when a key is created or rotated, the only times it is shown
+type KeyWithSecret struct {
+	APIKey
+	Key string `json:"api_key"`
+}
+
+// Product is the public view of an active product: no stock count, owner,
+// thresholds or campus
+type Product struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Price       *int       `json:"price,omitempty"` // left out when STOREFRONT_SHOW_PRICES is off
+	ImageURL    string     `json:"image_url"`
+	Type        string     `json:"type"`
+	InStock     bool       `json:"in_stock"`
+	Categories  []Category `json:"categories"`
+}
+
+type Category struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	Description  string `json:"description"`
+	ParentID     *uint  `json:"parent_id"`
+	ProductCount int    `json:"product_count,omitempty"` // active products of the campus, in the category list
+}
+
+type ProductListParams struct {
+	CategoryID uint
+	Search     string
+	Page       int
+	Limit      int
+}
+
+type ProductListResponse struct {
+	Products []Product `json:"products"`
+	utils.Pagination
+}
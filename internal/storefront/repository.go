@@ -0,0 +1,107 @@
+package storefront
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-point/internal/marketplace"
+
+	"gorm.io/gorm"
+)
+
+// productColumns are the only product columns the storefront reads
+var productColumns = []string{"id", "name", "description", "price", "image_url", "type", "stock", "created_at"}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindActiveKeyByHash returns the active key hashing to keyHash, across campuses
+// (the request has no campus until the key is known)
+func (r *Repository) FindActiveKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	var key APIKey
+	err := r.db.WithContext(ctx).Where("key_hash = ? AND active = ?", keyHash, true).Take(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	return &key, err
+}
+
+func (r *Repository) TouchKey(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
+
+func (r *Repository) CreateKey(ctx context.Context, key *APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *Repository) FindKeys(ctx context.Context) ([]APIKey, error) {
+	var keys []APIKey
+	err := r.db.WithContext(ctx).Order("name").Find(&keys).Error
+	return keys, err
+}
+
+func (r *Repository) FindKeyByID(ctx context.Context, id uint) (*APIKey, error) {
+	var key APIKey
+	if err := r.db.WithContext(ctx).First(&key, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *Repository) UpdateKey(ctx context.Context, key *APIKey, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(key).Updates(updates).Error
+}
+
+// activeProducts lists the active products of the campus of ctx, newest first
+func (r *Repository) activeProducts(ctx context.Context, params ProductListParams) ([]marketplace.Product, int64, error) {
+	var products []marketplace.Product
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&marketplace.Product{}).Where("status = ?", "active")
+	if params.CategoryID != 0 {
+		query = query.Where("id IN (?)", r.db.Table("product_categories").Select("product_id").Where("category_id = ?", params.CategoryID))
+	}
+	if params.Search != "" {
+		query = query.Where("name LIKE ?", "%"+params.Search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Select(productColumns).
+		Order("created_at DESC").Order("id DESC").
+		Limit(params.Limit).Offset((params.Page - 1) * params.Limit).
+		Find(&products).Error
+	return products, total, err
+}
+
+func (r *Repository) activeProduct(ctx context.Context, id uint) (*marketplace.Product, error) {
+	var product marketplace.Product
+	err := r.db.WithContext(ctx).Select(productColumns).Where("status = ?", "active").First(&product, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	}
+	return &product, err
+}
+
+// categories lists every category of the campus with the number of active products the campus has in it
+func (r *Repository) categories(ctx context.Context, tenantID uint) ([]Category, error) {
+	var categories []Category
+	err := r.db.WithContext(ctx).Table("categories c").
+		Select("c.id, c.name, c.slug, c.description, c.parent_id, COUNT(p.id) AS product_count").
+		Joins("LEFT JOIN product_categories pc ON pc.category_id = c.id").
+		Joins("LEFT JOIN products p ON p.id = pc.product_id AND p.status = ? AND p.deleted_at IS NULL AND p.tenant_id = ?", "active", tenantID).
+		Where("c.tenant_id = ?", tenantID).
+		Group("c.id, c.name, c.slug, c.description, c.parent_id").
+		Order("c.name").
+		Scan(&categories).Error
+	return categories, err
+}
@@ -0,0 +1,243 @@
+package storefront
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+	"wallet-point/internal/marketplace"
+	"wallet-point/utils"
+
+	"golang.org/x/time/rate"
+)
+
+// touchInterval limits how often a key's last_used_at is written
+const touchInterval = time.Minute
+
+type Config struct {
+	RateLimit  int  // requests per minute of a key without its own limit
+	ShowPrices bool // include product prices in the public catalog
+}
+
+// Service serves the read-only public catalog to sites holding an API key, each
+// key throttled on its own
+type Service struct {
+	repo        *Repository
+	marketplace *marketplace.MarketplaceService
+	config      Config
+
+	mu       sync.Mutex
+	limiters map[uint]*keyLimiter
+}
+
+type keyLimiter struct {
+	perMinute int
+	limiter   *rate.Limiter
+}
+
+func NewService(repo *Repository, marketplaceService *marketplace.MarketplaceService, config Config) *Service {
+	return &Service{
+		repo:        repo,
+		marketplace: marketplaceService,
+		config:      config,
+		limiters:    make(map[uint]*keyLimiter),
+	}
+}
+
+// Authenticate returns the active key apiKey belongs to
+func (s *Service) Authenticate(ctx context.Context, apiKey string) (*APIKey, error) {
+	if apiKey == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.FindActiveKeyByHash(ctx, hashKey(apiKey))
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now(); key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > touchInterval {
+		if err := s.repo.TouchKey(ctx, key.ID, now); err != nil {
+			slog.WarnContext(ctx, "storefront: record key use failed", "key_id", key.ID, "error", err)
+		}
+	}
+	return key, nil
+}
+
+// Allow takes one request from the key's budget. When it is used up it returns
+// false and how long until the next request is allowed.
+func (s *Service) Allow(key *APIKey) (bool, time.Duration) {
+	perMinute := key.RateLimit
+	if perMinute <= 0 {
+		perMinute = s.config.RateLimit
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.limiters[key.ID]
+	if !ok || l.perMinute != perMinute {
+		// A full minute's worth of burst, refilled evenly
+		l = &keyLimiter{perMinute: perMinute, limiter: rate.NewLimiter(rate.Limit(float64(perMinute)/60), perMinute)}
+		s.limiters[key.ID] = l
+	}
+	reservation := l.limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		return false, delay
+	}
+	return true, 0
+}
+
+func (s *Service) GetKeys(ctx context.Context) ([]APIKey, error) {
+	return s.repo.FindKeys(ctx)
+}
+
+// CreateKey issues a key for the admin's campus; the key is only returned here and by RotateKey
+func (s *Service) CreateKey(ctx context.Context, req CreateKeyRequest, adminID uint) (*KeyWithSecret, error) {
+	secret, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key := &APIKey{
+		Name:      req.Name,
+		KeyPrefix: secret[:11],
+		KeyHash:   hashKey(secret),
+		RateLimit: req.RateLimit,
+		Active:    true,
+		CreatedBy: adminID,
+	}
+	if err := s.repo.CreateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return &KeyWithSecret{APIKey: *key, Key: secret}, nil
+}
+
+func (s *Service) UpdateKey(ctx context.Context, id uint, req UpdateKeyRequest) (*APIKey, error) {
+	key, err := s.repo.FindKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.RateLimit != nil {
+		updates["rate_limit"] = *req.RateLimit
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) == 0 {
+		return key, nil
+	}
+	if err := s.repo.UpdateKey(ctx, key, updates); err != nil {
+		return nil, err
+	}
+	return s.repo.FindKeyByID(ctx, id)
+}
+
+// RotateKey replaces a key's secret; the old one stops working at once
+func (s *Service) RotateKey(ctx context.Context, id uint) (*KeyWithSecret, error) {
+	key, err := s.repo.FindKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key.KeyPrefix = secret[:11]
+	key.KeyHash = hashKey(secret)
+	if err := s.repo.UpdateKey(ctx, key, map[string]interface{}{"key_prefix": key.KeyPrefix, "key_hash": key.KeyHash}); err != nil {
+		return nil, err
+	}
+	return &KeyWithSecret{APIKey: *key, Key: secret}, nil
+}
+
+// GetProducts lists the active products of the key's campus (ctx)
+func (s *Service) GetProducts(ctx context.Context, params ProductListParams) (*ProductListResponse, error) {
+	products, total, err := s.repo.activeProducts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	public, err := s.publish(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	return &ProductListResponse{
+		Products:   public,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+func (s *Service) GetProduct(ctx context.Context, id uint) (*Product, error) {
+	product, err := s.repo.activeProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	public, err := s.publish(ctx, []marketplace.Product{*product})
+	if err != nil {
+		return nil, err
+	}
+	return &public[0], nil
+}
+
+// GetCategories lists all categories with the number of active products of the key's campus in each
+func (s *Service) GetCategories(ctx context.Context) ([]Category, error) {
+	tenantID, _ := utils.TenantFromContext(ctx)
+	return s.repo.categories(ctx, tenantID)
+}
+
+// publish turns products into their public view, with their categories
+func (s *Service) publish(ctx context.Context, products []marketplace.Product) ([]Product, error) {
+	public := make([]Product, 0, len(products))
+	if len(products) == 0 {
+		return public, nil
+	}
+
+	ids := make([]uint, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	categories, err := s.marketplace.GetProductCategories(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range products {
+		product := Product{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			ImageURL:    p.ImageURL,
+			Type:        p.Type,
+			InStock:     p.Stock > 0,
+			Categories:  make([]Category, 0, len(categories[p.ID])),
+		}
+		if s.config.ShowPrices {
+			price := p.Price
+			product.Price = &price
+		}
+		for _, c := range categories[p.ID] {
+			product.Categories = append(product.Categories, Category{ID: c.ID, Name: c.Name, Slug: c.Slug, Description: c.Description, ParentID: c.ParentID})
+		}
+		public = append(public, product)
+	}
+	return public, nil
+}
+
+// newAPIKey returns a random storefront key, e.g. sf_3f9a...
+func newAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "sf_" + hex.EncodeToString(b), nil
+}
+
+func hashKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,116 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/resilience"
+)
+
+const (
+	botAPIBaseURL  = "https://api.telegram.org/bot"
+	maxMessageSize = 4096 // characters Telegram accepts in one message
+)
+
+// Client sends messages as the bot through the Telegram Bot API. It is also the
+// notification outbox sender of the telegram channel, whose recipients are chat IDs.
+type Client struct {
+	token  string
+	client *http.Client
+	policy resilience.Policy
+}
+
+func NewClient(token string, policy resilience.Policy) *Client {
+	if policy.Retryable == nil {
+		policy.Retryable = retryableBotError
+	}
+	return &Client{token: token, client: &http.Client{}, policy: policy}
+}
+
+// botStatusError is a non-2xx reply of the Bot API
+type botStatusError struct {
+	status      int
+	description string
+}
+
+func (e *botStatusError) Error() string {
+	return fmt.Sprintf("telegram returned status %d: %s", e.status, e.description)
+}
+
+// retryableBotError retries everything except a 4xx other than 429
+func retryableBotError(err error) bool {
+	statusErr, ok := err.(*botStatusError)
+	if !ok {
+		return true
+	}
+	return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+}
+
+// SendMessage sends plain text to a chat
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	if runes := []rune(text); len(runes) > maxMessageSize {
+		text = string(runes[:maxMessageSize-1]) + "…"
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.policy.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, botAPIBaseURL+c.token+"/sendMessage", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			// The URL holds the bot token; keep it out of the error
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return fmt.Errorf("telegram request failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			var reply struct {
+				Description string `json:"description"`
+			}
+			json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&reply)
+			return &botStatusError{status: resp.StatusCode, description: reply.Description}
+		}
+		return nil
+	})
+}
+
+// Send delivers an outbox message to the chat ID in to. A chat that is gone or
+// blocked the bot (400/403) fails permanently.
+func (c *Client) Send(to, subject, body string) error {
+	chatID, err := strconv.ParseInt(to, 10, 64)
+	if err != nil {
+		return notification.Permanent(fmt.Errorf("invalid chat ID %q", to))
+	}
+	text := body
+	if subject != "" {
+		text = subject + "\n\n" + body
+	}
+
+	err = c.SendMessage(context.Background(), chatID, text)
+	if statusErr, ok := err.(*botStatusError); ok && (statusErr.status == http.StatusBadRequest || statusErr.status == http.StatusForbidden) {
+		return notification.Permanent(err)
+	}
+	return err
+}
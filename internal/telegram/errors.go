@@ -0,0 +1,13 @@
+package telegram
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrNotConfigured   = utils.NewAppError("TELEGRAM_NOT_CONFIGURED", http.StatusNotFound, "the Telegram bot is not configured")
+	ErrInvalidSecret   = utils.NewAppError("TELEGRAM_INVALID_SECRET", http.StatusUnauthorized, "Telegram webhook secret is missing or invalid")
+	ErrNotLinked       = utils.NewAppError("TELEGRAM_NOT_LINKED", http.StatusNotFound, "no Telegram account is linked")
+	ErrLinkCodeInvalid = utils.NewAppError("TELEGRAM_LINK_CODE_INVALID", http.StatusBadRequest, "link code is invalid, expired or already used")
+)
@@ -0,0 +1,121 @@
+package telegram
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxUpdateBody = 1 << 20
+
+type TelegramHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewTelegramHandler(service *Service, auditService audit.Logger) *TelegramHandler {
+	return &TelegramHandler{service: service, auditService: auditService}
+}
+
+// Webhook handles an update Telegram posts for the bot. The answer to a
+// command is returned as a sendMessage call in the response body.
+// @Summary Telegram bot webhook
+// @Description Receives bot updates; verified by the X-Telegram-Bot-Api-Secret-Token header set with setWebhook
+// @Tags Integrations
+// @Accept json
+// @Produce json
+// @Param X-Telegram-Bot-Api-Secret-Token header string true "Webhook secret token"
+// @Success 200 {object} Reply
+// @Failure 401 {object} utils.Response
+// @Router /integrations/telegram/webhook [post]
+func (h *TelegramHandler) Webhook(c *gin.Context) {
+	if err := h.service.VerifySecret(c.GetHeader(SecretHeader)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	var update Update
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxUpdateBody))
+	if err != nil || json.Unmarshal(body, &update) != nil {
+		// Acknowledge anyway, Telegram would otherwise redeliver it forever
+		c.Status(http.StatusOK)
+		return
+	}
+
+	reply := h.service.HandleUpdate(c.Request.Context(), &update)
+	if reply == nil {
+		c.Status(http.StatusOK)
+		return
+	}
+	c.JSON(http.StatusOK, reply)
+}
+
+// IssueLinkCode handles a user requesting a code to link their Telegram chat
+// @Summary Get Telegram link code
+// @Description A single-use code to send to the bot as "/start CODE" (or open deep_link); it expires after TELEGRAM_LINK_CODE_SECONDS. Linking replaces an earlier linked chat.
+// @Tags Telegram
+// @Security BearerAuth
+// @Produce json
+// @Success 201 {object} utils.Response{data=LinkCodeResponse}
+// @Failure 404 {object} utils.Response
+// @Router /telegram/link-code [post]
+func (h *TelegramHandler) IssueLinkCode(c *gin.Context) {
+	code, err := h.service.IssueLinkCode(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Telegram link code issued", code)
+}
+
+// GetLink handles showing the user's linked Telegram account
+// @Summary Get linked Telegram account
+// @Tags Telegram
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=Link}
+// @Failure 404 {object} utils.Response
+// @Router /telegram/link [get]
+func (h *TelegramHandler) GetLink(c *gin.Context) {
+	link, err := h.service.GetLink(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Telegram account retrieved successfully", link)
+}
+
+// Unlink handles the user unlinking their Telegram chat
+// @Summary Unlink Telegram account
+// @Description The bot stops answering and notifying the chat
+// @Tags Telegram
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /telegram/link [delete]
+func (h *TelegramHandler) Unlink(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if err := h.service.Unlink(c.Request.Context(), userID); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Telegram account unlinked", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "UNLINK_TELEGRAM",
+		Entity:    "USER",
+		EntityID:  userID,
+		Details:   "Unlinked Telegram account",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,65 @@
+package telegram
+
+import "time"
+
+// Link ties a user to the private Telegram chat the bot answers them in. A user
+// has at most one chat and a chat at most one user.
+type Link struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex;not null"`
+	ChatID    int64     `json:"-" gorm:"uniqueIndex;not null"`
+	Username  string    `json:"username" gorm:"size:64"`
+	CreatedAt time.Time `json:"linked_at"`
+}
+
+func (Link) TableName() string {
+	return "telegram_links"
+}
+
+// LinkCode is the single-use code a user sends to the bot to link their chat
+type LinkCode struct {
+	ID        uint       `json:"-" gorm:"primaryKey"`
+	Code      string     `json:"code" gorm:"size:16;uniqueIndex;not null"`
+	UserID    uint       `json:"-" gorm:"not null;index"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+}
+
+func (LinkCode) TableName() string {
+	return "telegram_link_codes"
+}
+
+type LinkCodeResponse struct {
+	Code      string    `json:"code"`
+	Command   string    `json:"command"`             // to send to the bot by hand
+	DeepLink  string    `json:"deep_link,omitempty"` // opens the bot with the code filled in
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Update is the part of a Telegram Bot API update the bot reads
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+type Message struct {
+	MessageID int64 `json:"message_id"`
+	From      *struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"from"`
+	Chat struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+	} `json:"chat"`
+	Text string `json:"text"`
+}
+
+// Reply is a sendMessage call returned as the webhook response, which Telegram
+// performs itself
+type Reply struct {
+	Method string `json:"method"`
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
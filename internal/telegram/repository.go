@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateLinkCode(ctx context.Context, code *LinkCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+// consumeLinkCode marks an unexpired, unused code used and returns its user
+func (r *Repository) consumeLinkCode(tx *gorm.DB, code string, now time.Time) (uint, error) {
+	result := tx.Model(&LinkCode{}).
+		Where("code = ? AND used_at IS NULL AND expires_at > ?", code, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrLinkCodeInvalid
+	}
+
+	var linkCode LinkCode
+	if err := tx.Select("user_id").Where("code = ?", code).Take(&linkCode).Error; err != nil {
+		return 0, err
+	}
+	return linkCode.UserID, nil
+}
+
+// DeleteExpiredLinkCodes removes codes that expired before cutoff
+func (r *Repository) DeleteExpiredLinkCodes(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", cutoff).Delete(&LinkCode{})
+	return result.RowsAffected, result.Error
+}
+
+// replaceLink links the chat to the user, dropping earlier links of either
+func (r *Repository) replaceLink(tx *gorm.DB, link *Link) error {
+	if err := tx.Where("user_id = ? OR chat_id = ?", link.UserID, link.ChatID).Delete(&Link{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(link).Error
+}
+
+func (r *Repository) FindLinkByUserID(ctx context.Context, userID uint) (*Link, error) {
+	var link Link
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Take(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotLinked
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *Repository) FindLinkByChatID(ctx context.Context, chatID int64) (*Link, error) {
+	var link Link
+	if err := r.db.WithContext(ctx).Where("chat_id = ?", chatID).Take(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotLinked
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+// DeleteLink unlinks the user's chat; it reports whether there was one
+func (r *Repository) DeleteLink(ctx context.Context, userID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&Link{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteLinkByChatID unlinks a chat, e.g. after the user blocked the bot
+func (r *Repository) DeleteLinkByChatID(ctx context.Context, chatID int64) error {
+	return r.db.WithContext(ctx).Where("chat_id = ?", chatID).Delete(&Link{}).Error
+}
@@ -0,0 +1,303 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-point/internal/events"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Channel is the notification outbox channel of Telegram messages
+	Channel = "telegram"
+	// SecretHeader carries the webhook's secret_token on every update
+	SecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	historySize = 5
+	// codeAlphabet leaves out 0/O and 1/I, which are easily mixed up when typing a code
+	codeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+	codeLength   = 8
+)
+
+type Config struct {
+	BotUsername   string        // without @, for the t.me deep link
+	WebhookSecret string        // secret_token given to setWebhook, sent back in SecretHeader
+	LinkCodeTTL   time.Duration // how long a link code is valid
+}
+
+// Service is the Telegram bot: users link their chat with a one-time code from
+// the app, then ask the bot for their balance and recent transactions and get
+// their purchases reported there
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	wallets       *wallet.WalletService
+	notifications *notification.NotificationService
+	client        *Client
+	config        Config
+}
+
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService, notificationService *notification.NotificationService, config Config) *Service {
+	return &Service{
+		repo:          repo,
+		db:            db,
+		wallets:       walletService,
+		notifications: notificationService,
+		config:        config,
+	}
+}
+
+// SetClient enables the bot; client must also be registered as the outbox sender of Channel
+func (s *Service) SetClient(client *Client) {
+	s.client = client
+}
+
+// VerifySecret checks the secret token Telegram sends with every update
+func (s *Service) VerifySecret(token string) error {
+	if s.client == nil {
+		return ErrNotConfigured
+	}
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.WebhookSecret)) != 1 {
+		return ErrInvalidSecret
+	}
+	return nil
+}
+
+// IssueLinkCode gives a user a single-use code to send to the bot
+func (s *Service) IssueLinkCode(ctx context.Context, userID uint) (*LinkCodeResponse, error) {
+	if s.client == nil {
+		return nil, ErrNotConfigured
+	}
+	b := make([]byte, codeLength)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+
+	code := &LinkCode{
+		Code:      string(b),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.config.LinkCodeTTL),
+	}
+	if err := s.repo.CreateLinkCode(ctx, code); err != nil {
+		return nil, err
+	}
+
+	response := &LinkCodeResponse{
+		Code:      code.Code,
+		Command:   "/start " + code.Code,
+		ExpiresAt: code.ExpiresAt,
+	}
+	if s.config.BotUsername != "" {
+		response.DeepLink = fmt.Sprintf("https://t.me/%s?start=%s", s.config.BotUsername, code.Code)
+	}
+	return response, nil
+}
+
+func (s *Service) GetLink(ctx context.Context, userID uint) (*Link, error) {
+	return s.repo.FindLinkByUserID(ctx, userID)
+}
+
+// Unlink stops the bot from answering and notifying the user's chat
+func (s *Service) Unlink(ctx context.Context, userID uint) error {
+	deleted, err := s.repo.DeleteLink(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotLinked
+	}
+	return nil
+}
+
+// CleanupLinkCodes deletes link codes that expired over a day ago, for the scheduler
+func (s *Service) CleanupLinkCodes(ctx context.Context) (string, error) {
+	deleted, err := s.repo.DeleteExpiredLinkCodes(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("deleted %d expired link codes", deleted), nil
+}
+
+// HandleUpdate runs the command of an incoming message and returns the answer,
+// or nil when there is nothing to answer. Only private chats are served, so
+// balances never show up in a group.
+func (s *Service) HandleUpdate(ctx context.Context, update *Update) *Reply {
+	message := update.Message
+	if message == nil || message.Chat.Type != "private" || !strings.HasPrefix(message.Text, "/") {
+		return nil
+	}
+
+	fields := strings.Fields(message.Text)
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@") // "/saldo@WalletPointBot" in clients that add the bot name
+	args := fields[1:]
+
+	var text string
+	switch command {
+	case "/start", "/link":
+		if len(args) == 0 {
+			text = helpText
+		} else {
+			text = s.link(ctx, message, args[0])
+		}
+	case "/saldo", "/balance":
+		text = s.balance(ctx, message.Chat.ID)
+	case "/riwayat", "/history":
+		text = s.history(ctx, message.Chat.ID)
+	case "/putus", "/unlink":
+		text = s.unlinkChat(ctx, message.Chat.ID)
+	case "/bantuan", "/help":
+		text = helpText
+	default:
+		text = "Perintah tidak dikenal. Kirim /bantuan untuk daftar perintah."
+	}
+	return &Reply{Method: "sendMessage", ChatID: message.Chat.ID, Text: text}
+}
+
+const helpText = `Wallet Point bot
+
+/saldo - saldo poin Anda
+/riwayat - 5 transaksi terakhir
+/putus - putuskan akun dari chat ini
+
+Untuk menautkan akun, buka menu Telegram di aplikasi Wallet Point dan kirim kode yang muncul: /start KODE`
+
+func (s *Service) link(ctx context.Context, message *Message, code string) string {
+	link := &Link{ChatID: message.Chat.ID}
+	if message.From != nil {
+		link.Username = message.From.Username
+	}
+
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		userID, err := s.repo.consumeLinkCode(tx, strings.ToUpper(code), time.Now())
+		if err != nil {
+			return err
+		}
+		link.UserID = userID
+		return s.repo.replaceLink(tx, link)
+	})
+	if errors.Is(err, ErrLinkCodeInvalid) {
+		return "Kode tidak valid, sudah kedaluwarsa, atau sudah dipakai. Minta kode baru di aplikasi Wallet Point."
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "telegram: linking chat failed", "error", err)
+		return "Akun gagal ditautkan, coba lagi nanti."
+	}
+	return "Akun Wallet Point Anda sudah ditautkan. Kirim /saldo untuk melihat saldo; setiap pembelian akan diberitahukan di sini."
+}
+
+// linkedWallet resolves the wallet of a chat, or the answer to give when there is none
+func (s *Service) linkedWallet(ctx context.Context, chatID int64) (*wallet.Wallet, string) {
+	link, err := s.repo.FindLinkByChatID(ctx, chatID)
+	if errors.Is(err, ErrNotLinked) {
+		return nil, "Chat ini belum ditautkan ke akun Wallet Point. Kirim /bantuan untuk caranya."
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "telegram: link lookup failed", "chat_id", chatID, "error", err)
+		return nil, "Terjadi kesalahan, coba lagi nanti."
+	}
+	w, err := s.wallets.GetWalletByUserID(link.UserID)
+	if err != nil {
+		return nil, "Akun Anda belum memiliki dompet poin."
+	}
+	return w, ""
+}
+
+func (s *Service) balance(ctx context.Context, chatID int64) string {
+	w, answer := s.linkedWallet(ctx, chatID)
+	if w == nil {
+		return answer
+	}
+	return fmt.Sprintf("Saldo Anda: %s poin", points(w.Balance))
+}
+
+func (s *Service) history(ctx context.Context, chatID int64) string {
+	w, answer := s.linkedWallet(ctx, chatID)
+	if w == nil {
+		return answer
+	}
+	transactions, err := s.wallets.GetWalletTransactions(w.ID, historySize, nil)
+	if err != nil {
+		slog.ErrorContext(ctx, "telegram: transaction lookup failed", "wallet_id", w.ID, "error", err)
+		return "Terjadi kesalahan, coba lagi nanti."
+	}
+	if len(transactions) == 0 {
+		return "Belum ada transaksi."
+	}
+
+	var b strings.Builder
+	b.WriteString("Transaksi terakhir:\n")
+	for _, txn := range transactions {
+		sign := "+"
+		if txn.Direction == "debit" {
+			sign = "-"
+		}
+		fmt.Fprintf(&b, "\n%s  %s%s  %s", txn.CreatedAt.Format("02/01 15:04"), sign, points(txn.Amount), txn.Description)
+	}
+	fmt.Fprintf(&b, "\n\nSaldo: %s poin", points(w.Balance))
+	return b.String()
+}
+
+func (s *Service) unlinkChat(ctx context.Context, chatID int64) string {
+	if err := s.repo.DeleteLinkByChatID(ctx, chatID); err != nil {
+		slog.ErrorContext(ctx, "telegram: unlinking chat failed", "chat_id", chatID, "error", err)
+		return "Terjadi kesalahan, coba lagi nanti."
+	}
+	return "Chat ini tidak lagi tertaut ke akun Wallet Point."
+}
+
+// OnOrderPlaced is the Telegram subscriber of order.placed: it queues a
+// purchase report to the buyer's chat, if linked
+func (s *Service) OnOrderPlaced(ctx context.Context, event *events.DomainEvent) error {
+	if s.client == nil {
+		return nil
+	}
+	var placed events.OrderPlaced
+	if err := event.Decode(&placed); err != nil {
+		return err
+	}
+	link, err := s.repo.FindLinkByUserID(ctx, placed.UserID)
+	if errors.Is(err, ErrNotLinked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, order := range placed.Orders {
+		fmt.Fprintf(&b, "%dx %s: %s poin\n", order.Quantity, order.ProductName, points(order.TotalAmount))
+	}
+	fmt.Fprintf(&b, "\nTotal: %s poin", points(placed.Total))
+	if w, err := s.wallets.GetWalletByUserID(placed.UserID); err == nil {
+		fmt.Fprintf(&b, "\nSisa saldo: %s poin", points(w.Balance))
+	}
+
+	return s.notifications.Enqueue(placed.UserID, Channel, strconv.FormatInt(link.ChatID, 10), "Pembelian berhasil", b.String())
+}
+
+// points renders a number with thousands separators (12.500), as in the app
+func points(n int) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	digits := strconv.Itoa(n)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "." + digits[i:]
+	}
+	return sign + digits
+}
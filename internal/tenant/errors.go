@@ -0,0 +1,12 @@
+package tenant
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrTenantNotFound  = utils.NewAppError("TENANT_NOT_FOUND", http.StatusNotFound, "campus not found")
+	ErrTenantInactive  = utils.NewAppError("TENANT_INACTIVE", http.StatusBadRequest, "campus is inactive")
+	ErrTenantCodeTaken = utils.NewAppError("TENANT_CODE_TAKEN", http.StatusConflict, "campus code already in use")
+)
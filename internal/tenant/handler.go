@@ -0,0 +1,119 @@
+package tenant
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	service      *TenantService
+	auditService audit.Logger
+}
+
+func NewTenantHandler(service *TenantService, auditService audit.Logger) *TenantHandler {
+	return &TenantHandler{service: service, auditService: auditService}
+}
+
+// GetAll handles listing campuses (Super admin)
+// @Summary Get campuses
+// @Description List the campuses and faculties (tenants) served by this deployment
+// @Tags Super Admin - Tenants
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Tenant}
+// @Router /admin/tenants [get]
+func (h *TenantHandler) GetAll(c *gin.Context) {
+	tenants, err := h.service.GetTenants()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve tenants", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tenants retrieved successfully", tenants)
+}
+
+// Create handles adding a campus (Super admin)
+// @Summary Create campus
+// @Description Add a campus or faculty. Its admins are created with POST /admin/users and tenant_id; super admins pick the campus they act on with the X-Tenant-ID header.
+// @Tags Super Admin - Tenants
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateTenantRequest true "Campus details"
+// @Success 201 {object} utils.Response{data=Tenant}
+// @Failure 409 {object} utils.Response
+// @Router /admin/tenants [post]
+func (h *TenantHandler) Create(c *gin.Context) {
+	var req CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	tenant, err := h.service.CreateTenant(req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Tenant created successfully", tenant)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "CREATE_TENANT",
+		Entity:    "TENANT",
+		EntityID:  tenant.ID,
+		Details:   fmt.Sprintf("Created %s %s (%s)", tenant.Type, tenant.Name, tenant.Code),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Update handles renaming or (de)activating a campus (Super admin)
+// @Summary Update campus
+// @Description Rename a campus or deactivate it; users of an inactive campus can no longer log in
+// @Tags Super Admin - Tenants
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Tenant ID"
+// @Param request body UpdateTenantRequest true "Campus changes"
+// @Success 200 {object} utils.Response{data=Tenant}
+// @Failure 404 {object} utils.Response
+// @Router /admin/tenants/{id} [put]
+func (h *TenantHandler) Update(c *gin.Context) {
+	tenantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tenant ID", nil)
+		return
+	}
+
+	var req UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	tenant, err := h.service.UpdateTenant(uint(tenantID), req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tenant updated successfully", tenant)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_TENANT",
+		Entity:    "TENANT",
+		EntityID:  tenant.ID,
+		Details:   fmt.Sprintf("Updated %s %s (%s), active=%t", tenant.Type, tenant.Name, tenant.Code, tenant.Active),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,36 @@
+package tenant
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+// defaultTenant holds every user and product created before tenants existed
+var defaultTenant = Tenant{ID: utils.DefaultTenantID, Code: "default", Name: "Main Campus", Type: "campus", Active: true}
+
+// Tenant is a campus or faculty served by this deployment. Users and products
+// belong to one tenant; admins only see their own tenant's data.
+type Tenant struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Code      string    `json:"code" gorm:"size:50;uniqueIndex;not null"`
+	Name      string    `json:"name" gorm:"size:255;not null"`
+	Type      string    `json:"type" gorm:"type:enum('campus','faculty');default:'campus';not null"`
+	Active    bool      `json:"active" gorm:"default:true;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Tenant) TableName() string {
+	return "tenants"
+}
+
+type CreateTenantRequest struct {
+	Code string `json:"code" binding:"required,max=50,alphanum"`
+	Name string `json:"name" binding:"required,max=255"`
+	Type string `json:"type" binding:"omitempty,tenant_type" enums:"campus,faculty"`
+}
+
+type UpdateTenantRequest struct {
+	Name   *string `json:"name" binding:"omitempty,max=255"`
+	Active *bool   `json:"active"`
+}
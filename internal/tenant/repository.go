@@ -0,0 +1,60 @@
+package tenant
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type TenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+func (r *TenantRepository) FindAll() ([]Tenant, error) {
+	var tenants []Tenant
+	err := r.db.Order("id ASC").Find(&tenants).Error
+	return tenants, err
+}
+
+func (r *TenantRepository) FindByID(id uint) (*Tenant, error) {
+	return r.find("id = ?", id)
+}
+
+func (r *TenantRepository) FindByCode(code string) (*Tenant, error) {
+	return r.find("code = ?", code)
+}
+
+func (r *TenantRepository) find(query string, args ...interface{}) (*Tenant, error) {
+	var tenant Tenant
+	err := r.db.Where(query, args...).First(&tenant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *TenantRepository) CodeExists(code string) (bool, error) {
+	var count int64
+	err := r.db.Model(&Tenant{}).Where("code = ?", code).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateMissing inserts tenant unless a row with its ID exists, leaving an existing one untouched
+func (r *TenantRepository) CreateMissing(tenant *Tenant) error {
+	return r.db.Where(Tenant{ID: tenant.ID}).FirstOrCreate(tenant).Error
+}
+
+func (r *TenantRepository) Create(tenant *Tenant) error {
+	return r.db.Create(tenant).Error
+}
+
+func (r *TenantRepository) Update(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&Tenant{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,100 @@
+package tenant
+
+import (
+	"strings"
+)
+
+type TenantService struct {
+	repo *TenantRepository
+}
+
+func NewTenantService(repo *TenantRepository) *TenantService {
+	return &TenantService{repo: repo}
+}
+
+// EnsureDefault creates the default campus that existing users and products belong to
+func (s *TenantService) EnsureDefault() error {
+	tenant := defaultTenant
+	return s.repo.CreateMissing(&tenant)
+}
+
+// GetTenants lists all campuses (Super admin)
+func (s *TenantService) GetTenants() ([]Tenant, error) {
+	return s.repo.FindAll()
+}
+
+func (s *TenantService) GetTenant(id uint) (*Tenant, error) {
+	return s.repo.FindByID(id)
+}
+
+// ActiveTenant returns the tenant with the given ID, failing when it is deactivated
+func (s *TenantService) ActiveTenant(id uint) (*Tenant, error) {
+	tenant, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.Active {
+		return nil, ErrTenantInactive
+	}
+	return tenant, nil
+}
+
+// ActiveTenantByCode resolves a campus code, e.g. from the self registration form
+func (s *TenantService) ActiveTenantByCode(code string) (*Tenant, error) {
+	tenant, err := s.repo.FindByCode(strings.ToLower(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.Active {
+		return nil, ErrTenantInactive
+	}
+	return tenant, nil
+}
+
+// CreateTenant adds a campus or faculty (Super admin)
+func (s *TenantService) CreateTenant(req CreateTenantRequest) (*Tenant, error) {
+	code := strings.ToLower(req.Code)
+	exists, err := s.repo.CodeExists(code)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrTenantCodeTaken
+	}
+
+	tenant := &Tenant{
+		Code:   code,
+		Name:   strings.TrimSpace(req.Name),
+		Type:   req.Type,
+		Active: true,
+	}
+	if tenant.Type == "" {
+		tenant.Type = "campus"
+	}
+	if err := s.repo.Create(tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// UpdateTenant renames or (de)activates a campus (Super admin). Users of an
+// inactive campus can no longer log in or register.
+func (s *TenantService) UpdateTenant(id uint, req UpdateTenantRequest) (*Tenant, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) > 0 {
+		if err := s.repo.Update(id, updates); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindByID(id)
+}
@@ -0,0 +1,25 @@
+package ticketing
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrSessionNotFound    = utils.NewAppError("TICKET_SESSION_NOT_FOUND", http.StatusNotFound, "ticket session not found")
+	ErrSessionRequired    = utils.NewAppError("TICKET_SESSION_REQUIRED", http.StatusBadRequest, "choose a session_id to buy a ticket")
+	ErrSessionInvalid     = utils.NewAppError("TICKET_SESSION_INVALID", http.StatusBadRequest, "a session must end after it starts")
+	ErrSessionEnded       = utils.NewAppError("TICKET_SESSION_ENDED", http.StatusConflict, "the session has ended")
+	ErrSessionFull        = utils.NewAppError("TICKET_SESSION_FULL", http.StatusConflict, "not enough seats left in the session")
+	ErrCapacityBelowSold  = utils.NewAppError("TICKET_CAPACITY_BELOW_SOLD", http.StatusConflict, "capacity cannot be lower than the tickets sold")
+	ErrTicketLimit        = utils.NewAppError("TICKET_LIMIT_REACHED", http.StatusConflict, "ticket limit per student reached for the session")
+	ErrNotTicketProduct   = utils.NewAppError("PRODUCT_NOT_TICKET", http.StatusBadRequest, "sessions can only be added to ticket products")
+	ErrTicketNotFound     = utils.NewAppError("TICKET_NOT_FOUND", http.StatusNotFound, "ticket not found")
+	ErrTicketUsed         = utils.NewAppError("TICKET_ALREADY_USED", http.StatusConflict, "ticket has already been checked in")
+	ErrTicketCancelled    = utils.NewAppError("TICKET_CANCELLED", http.StatusConflict, "ticket has been cancelled")
+	ErrTicketWrongSession = utils.NewAppError("TICKET_WRONG_SESSION", http.StatusConflict, "ticket is for another session")
+	ErrCancelClosed       = utils.NewAppError("TICKET_CANCEL_CLOSED", http.StatusConflict, "tickets can only be cancelled before the session starts")
+	ErrSessionNotFull     = utils.NewAppError("TICKET_SESSION_NOT_FULL", http.StatusConflict, "the session has seats left; buy a ticket instead")
+	ErrAlreadyWaitlisted  = utils.NewAppError("TICKET_ALREADY_WAITLISTED", http.StatusConflict, "you are already on the waitlist of this session")
+	ErrNotWaitlisted      = utils.NewAppError("TICKET_NOT_WAITLISTED", http.StatusNotFound, "you are not on the waitlist of this session")
+)
@@ -0,0 +1,364 @@
+package ticketing
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewTicketHandler(service *Service, auditService audit.Logger) *TicketHandler {
+	return &TicketHandler{service: service, auditService: auditService}
+}
+
+// GetSessions handles listing the upcoming sessions of a ticket product
+// @Summary Get ticket sessions
+// @Description Sessions of a ticket product that have not ended, by start, with the seats available and the tickets each student may hold. Buy a ticket with a direct purchase of the product naming the session_id.
+// @Tags Mahasiswa - Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.Response{data=[]Session}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/products/{id}/sessions [get]
+func (h *TicketHandler) GetSessions(c *gin.Context) {
+	h.sessions(c, true)
+}
+
+// GetAllSessions handles listing every session of a ticket product (Admin)
+// @Summary List ticket sessions
+// @Description All sessions of a ticket product, past ones included, with the seats sold and available
+// @Tags Admin - Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.Response{data=[]Session}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/sessions [get]
+func (h *TicketHandler) GetAllSessions(c *gin.Context) {
+	h.sessions(c, false)
+}
+
+func (h *TicketHandler) sessions(c *gin.Context, upcoming bool) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	sessions, err := h.service.GetSessions(c.Request.Context(), uint(productID), upcoming)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket sessions retrieved successfully", sessions)
+}
+
+// CreateSession handles adding a session to a ticket product (Admin)
+// @Summary Create ticket session
+// @Description Add a session to a ticket product; its capacity adds to the stock of the product. max_per_user limits the tickets one student may hold for it (0 is no limit).
+// @Tags Admin - Tickets
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body CreateSessionRequest true "Session"
+// @Success 201 {object} utils.Response{data=Session}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/sessions [post]
+func (h *TicketHandler) CreateSession(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+	var req CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	session, err := h.service.CreateSession(c.Request.Context(), uint(productID), &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Ticket session created successfully", session)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "CREATE_TICKET_SESSION",
+		Entity:    "TICKET_SESSION",
+		EntityID:  session.ID,
+		Details:   fmt.Sprintf("Admin added session '%s' with %d seats to product %d", session.Name, session.Capacity, session.ProductID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateSession handles changing a ticket session (Admin)
+// @Summary Update ticket session
+// @Description Change a session; omitted fields are left unchanged. Capacity cannot drop below the tickets sold, and its change moves the stock of the product.
+// @Tags Admin - Tickets
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Session ID"
+// @Param request body UpdateSessionRequest true "Session changes"
+// @Success 200 {object} utils.Response{data=Session}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/ticket-sessions/{id} [put]
+func (h *TicketHandler) UpdateSession(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID", nil)
+		return
+	}
+	var req UpdateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	session, err := h.service.UpdateSession(c.Request.Context(), uint(sessionID), &req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket session updated successfully", session)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_TICKET_SESSION",
+		Entity:    "TICKET_SESSION",
+		EntityID:  session.ID,
+		Details:   fmt.Sprintf("Admin updated session '%s', now %d seats", session.Name, session.Capacity),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// CheckIn handles scanning a ticket at the entrance (Admin)
+// @Summary Check in ticket
+// @Description Validate the scanned ticket code and mark it used. A ticket is let in once: used, cancelled, ended or, with session_id, other-session tickets are refused. The holder's name and NIM are returned for an ID check.
+// @Tags Admin - Tickets
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CheckInRequest true "Scanned code"
+// @Success 200 {object} utils.Response{data=CheckInResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/tickets/check-in [post]
+func (h *TicketHandler) CheckIn(c *gin.Context) {
+	var req CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	staffID := c.GetUint("user_id")
+	response, err := h.service.CheckIn(c.Request.Context(), &req, staffID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket checked in successfully", response)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    staffID,
+		Action:    "CHECK_IN_TICKET",
+		Entity:    "TICKET",
+		EntityID:  response.Ticket.ID,
+		Details:   fmt.Sprintf("Checked in %s for session %d", response.HolderName, response.Ticket.SessionID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetMyTickets handles listing the student's tickets
+// @Summary Get my tickets
+// @Description Tickets issued to the student, latest session first, with their session
+// @Tags Mahasiswa - Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(valid, used, cancelled)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=TicketListResponse}
+// @Router /mahasiswa/tickets [get]
+func (h *TicketHandler) GetMyTickets(c *gin.Context) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "ticket_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetTickets(c.Request.Context(), TicketListParams{
+		UserID: c.GetUint("user_id"),
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve tickets", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Tickets retrieved successfully", "tickets", response.Tickets, response.Pagination, nil)
+}
+
+// GetMyTicket handles showing one of the student's tickets
+// @Summary Get my ticket
+// @Description A ticket with its session and its code as a PNG QR code to show at the entrance
+// @Tags Mahasiswa - Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Ticket ID"
+// @Success 200 {object} utils.Response{data=TicketResponse}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/tickets/{id} [get]
+func (h *TicketHandler) GetMyTicket(c *gin.Context) {
+	ticketID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ticket ID", nil)
+		return
+	}
+
+	ticket, err := h.service.GetTicket(c.Request.Context(), c.GetUint("user_id"), uint(ticketID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket retrieved successfully", ticket)
+}
+
+// CancelTicket handles cancelling one of the student's tickets
+// @Summary Cancel my ticket
+// @Description Cancel a valid ticket before its session starts and within the return window of the product's categories (return_policy on the product). The points paid for it go back to the wallet that paid (the buyer's, for gifts) and the seat is offered to the first student on the session's waitlist.
+// @Tags Mahasiswa - Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Ticket ID"
+// @Success 200 {object} utils.Response{data=CancelTicketResponse}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /mahasiswa/tickets/{id}/cancel [post]
+func (h *TicketHandler) CancelTicket(c *gin.Context) {
+	ticketID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ticket ID", nil)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	response, err := h.service.CancelTicket(c.Request.Context(), userID, uint(ticketID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket cancelled successfully", response)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CANCEL_TICKET",
+		Entity:    "TICKET",
+		EntityID:  response.Ticket.ID,
+		Details:   fmt.Sprintf("Student cancelled a ticket for session %d, %d points refunded", response.Ticket.SessionID, response.Refunded),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// JoinWaitlist handles joining the waitlist of a full session
+// @Summary Join session waitlist
+// @Description Queue for a seat of a sold-out session. When a seat frees up it is held for the first student in line, who is notified and can buy it with a direct purchase naming the session_id until offer_expires_at; after that it goes to the next.
+// @Tags Mahasiswa - Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 201 {object} utils.Response{data=WaitlistEntry}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /mahasiswa/ticket-sessions/{id}/waitlist [post]
+func (h *TicketHandler) JoinWaitlist(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID", nil)
+		return
+	}
+
+	entry, err := h.service.JoinWaitlist(c.Request.Context(), c.GetUint("user_id"), uint(sessionID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Joined the waitlist successfully", entry)
+}
+
+// LeaveWaitlist handles leaving the waitlist of a session
+// @Summary Leave session waitlist
+// @Description Leave the queue of a session; a seat held for you is offered to the next student in line
+// @Tags Mahasiswa - Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/ticket-sessions/{id}/waitlist [delete]
+func (h *TicketHandler) LeaveWaitlist(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID", nil)
+		return
+	}
+
+	if err := h.service.LeaveWaitlist(c.Request.Context(), c.GetUint("user_id"), uint(sessionID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Left the waitlist successfully", nil)
+}
+
+// GetMyWaitlist handles listing the student's waitlist entries
+// @Summary Get my waitlists
+// @Description The student's last 100 waitlist entries, newest first: position in the queue while waiting, and offer_expires_at while a seat is held for them
+// @Tags Mahasiswa - Tickets
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]WaitlistEntry}
+// @Router /mahasiswa/tickets/waitlist [get]
+func (h *TicketHandler) GetMyWaitlist(c *gin.Context) {
+	entries, err := h.service.GetMyWaitlist(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve waitlist", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Waitlist retrieved successfully", entries)
+}
@@ -0,0 +1,152 @@
+package ticketing
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	TicketValid     = "valid"
+	TicketUsed      = "used"
+	TicketCancelled = "cancelled"
+)
+
+const (
+	WaitlistWaiting = "waiting"
+	WaitlistOffered = "offered"
+	WaitlistClaimed = "claimed"
+	WaitlistExpired = "expired"
+	WaitlistLeft    = "left"
+)
+
+// Session is a slot of a ticket product, e.g. one screening of a seminar. The
+// stock of the product is the seats left across its sessions. Held seats are
+// offered to waitlisted students and can only be bought by them.
+type Session struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TenantID   uint      `json:"tenant_id" gorm:"not null;default:1;index"`
+	ProductID  uint      `json:"product_id" gorm:"not null;index"`
+	Name       string    `json:"name" gorm:"size:100;not null"`
+	Location   string    `json:"location,omitempty" gorm:"size:150"`
+	StartsAt   time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt     time.Time `json:"ends_at" gorm:"not null"`
+	Capacity   int       `json:"capacity" gorm:"not null"`
+	Sold       int       `json:"sold" gorm:"default:0;not null"`
+	Held       int       `json:"held" gorm:"default:0;not null"`
+	MaxPerUser int       `json:"max_per_user" gorm:"default:0;not null"` // 0 is no limit
+	Available  int       `json:"available" gorm:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (Session) TableName() string {
+	return "ticket_sessions"
+}
+
+// available is the number of seats anyone can buy
+func (s *Session) available() int {
+	return max(s.Capacity-s.Sold-s.Held, 0)
+}
+
+// Ticket admits one student to a session. Staff scan Code, shown as a QR code,
+// at the entrance.
+type Ticket struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TenantID    uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	Code        string     `json:"code" gorm:"size:64;uniqueIndex;not null"`
+	OrderID     uint       `json:"order_id" gorm:"not null;index"` // marketplace transaction
+	SessionID   uint       `json:"session_id" gorm:"not null;index"`
+	ProductID   uint       `json:"product_id" gorm:"not null"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Status      string     `json:"status" gorm:"type:enum('valid','used','cancelled');default:'valid';not null"`
+	CheckedInAt *time.Time `json:"checked_in_at"`
+	CheckedInBy *uint      `json:"checked_in_by,omitempty"`
+	Session     *Session   `json:"session,omitempty" gorm:"foreignKey:SessionID"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Ticket) TableName() string {
+	return "tickets"
+}
+
+// WaitlistEntry is a student waiting for a seat of a full session. When a seat
+// frees up the first waiting entry is offered it: the seat is held for the
+// student until OfferExpiresAt, then offered to the next.
+type WaitlistEntry struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	TenantID       uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	SessionID      uint       `json:"session_id" gorm:"not null;index:idx_ticket_waitlist_session_status"`
+	ProductID      uint       `json:"product_id" gorm:"not null"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	Status         string     `json:"status" gorm:"type:enum('waiting','offered','claimed','expired','left');default:'waiting';not null;index:idx_ticket_waitlist_session_status"`
+	OfferedAt      *time.Time `json:"offered_at"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"`
+	ClaimedAt      *time.Time `json:"claimed_at"`
+	Position       int        `json:"position,omitempty" gorm:"-"` // place in the queue while waiting
+	Session        *Session   `json:"session,omitempty" gorm:"foreignKey:SessionID"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (WaitlistEntry) TableName() string {
+	return "ticket_waitlist"
+}
+
+type CreateSessionRequest struct {
+	Name       string    `json:"name" binding:"required,max=100"`
+	Location   string    `json:"location" binding:"max=150"`
+	StartsAt   time.Time `json:"starts_at" binding:"required"`
+	EndsAt     time.Time `json:"ends_at" binding:"required"`
+	Capacity   int       `json:"capacity" binding:"required,gt=0"`
+	MaxPerUser int       `json:"max_per_user" binding:"gte=0"`
+}
+
+// UpdateSessionRequest changes a session; omitted fields are left unchanged.
+// Capacity cannot drop below the seats sold.
+type UpdateSessionRequest struct {
+	Name       string     `json:"name" binding:"max=100"`
+	Location   *string    `json:"location" binding:"omitempty,max=150"`
+	StartsAt   *time.Time `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at"`
+	Capacity   *int       `json:"capacity" binding:"omitempty,gt=0"`
+	MaxPerUser *int       `json:"max_per_user" binding:"omitempty,gte=0"`
+}
+
+type CheckInRequest struct {
+	Code string `json:"code" binding:"required,max=64"`
+	// SessionID, when given, refuses tickets of other sessions
+	SessionID uint `json:"session_id"`
+}
+
+// CheckInResponse is what staff see after scanning a ticket
+type CheckInResponse struct {
+	Ticket     Ticket `json:"ticket"`
+	HolderName string `json:"holder_name"`
+	HolderNIM  string `json:"holder_nim"`
+}
+
+// CancelTicketResponse is a cancelled ticket and the points returned to the
+// wallet that paid for it
+type CancelTicketResponse struct {
+	Ticket   Ticket `json:"ticket"`
+	Refunded int    `json:"refunded"`
+}
+
+// TicketResponse is a ticket with its code as a PNG QR code
+type TicketResponse struct {
+	Ticket
+	QRCodeBase64 string `json:"qr_code_base64"`
+}
+
+type TicketListParams struct {
+	UserID uint
+	Status string
+	Page   int
+	Limit  int
+}
+
+type TicketListResponse struct {
+	Tickets []Ticket `json:"tickets"`
+	utils.Pagination
+}
@@ -0,0 +1,232 @@
+package ticketing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateSession(tx *gorm.DB, session *Session) error {
+	return tx.Create(session).Error
+}
+
+func (r *Repository) FindSession(ctx context.Context, id uint) (*Session, error) {
+	var session Session
+	if err := r.db.WithContext(ctx).First(&session, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// LockSession loads a session for update within tx, so two purchases cannot
+// both take its last seats
+func (r *Repository) LockSession(tx *gorm.DB, id uint) (*Session, error) {
+	var session Session
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *Repository) UpdateSession(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	return tx.Model(&Session{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindSessions lists the sessions of a product by start; after, when set, skips
+// those ended before it
+func (r *Repository) FindSessions(ctx context.Context, productID uint, after *time.Time) ([]Session, error) {
+	var sessions []Session
+	query := r.db.WithContext(ctx).Where("product_id = ?", productID)
+	if after != nil {
+		query = query.Where("ends_at > ?", *after)
+	}
+	err := query.Order("starts_at ASC").Order("id ASC").Find(&sessions).Error
+	return sessions, err
+}
+
+// CountHeld counts the tickets of a session userID holds, used or not
+func (r *Repository) CountHeld(db *gorm.DB, sessionID, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&Ticket{}).
+		Where("session_id = ? AND user_id = ? AND status <> ?", sessionID, userID, TicketCancelled).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) CreateTickets(tx *gorm.DB, tickets []Ticket) error {
+	return tx.Create(&tickets).Error
+}
+
+func (r *Repository) FindTicket(ctx context.Context, id uint) (*Ticket, error) {
+	var ticket Ticket
+	if err := r.db.WithContext(ctx).Preload("Session").First(&ticket, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// LockTicketByCode loads a ticket for update within tx, so a code scanned at
+// two entrances is only let in once
+func (r *Repository) LockTicketByCode(tx *gorm.DB, code string) (*Ticket, error) {
+	var ticket Ticket
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&ticket).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// LockTicket loads a ticket for update within tx
+func (r *Repository) LockTicket(tx *gorm.DB, id uint) (*Ticket, error) {
+	var ticket Ticket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ticket, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// CountCancelled counts the cancelled tickets of a marketplace transaction
+func (r *Repository) CountCancelled(tx *gorm.DB, orderID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&Ticket{}).Where("order_id = ? AND status = ?", orderID, TicketCancelled).Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) UpdateTicket(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	return tx.Model(&Ticket{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindTickets lists tickets by session start, latest first
+func (r *Repository) FindTickets(ctx context.Context, params TicketListParams) ([]Ticket, int64, error) {
+	var tickets []Ticket
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Ticket{}).Where("tickets.user_id = ?", params.UserID)
+	if params.Status != "" {
+		query = query.Where("tickets.status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("Session").
+		Joins("JOIN ticket_sessions ON ticket_sessions.id = tickets.session_id").
+		Order("ticket_sessions.starts_at DESC").Order("tickets.id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&tickets).Error
+	return tickets, total, err
+}
+
+func (r *Repository) CreateEntry(tx *gorm.DB, entry *WaitlistEntry) error {
+	return tx.Create(entry).Error
+}
+
+func (r *Repository) UpdateEntry(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	return tx.Model(&WaitlistEntry{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindActiveEntry returns the waiting or offered entry of userID for a session,
+// nil when there is none
+func (r *Repository) FindActiveEntry(db *gorm.DB, sessionID, userID uint) (*WaitlistEntry, error) {
+	var entry WaitlistEntry
+	err := db.Where("session_id = ? AND user_id = ? AND status IN ?", sessionID, userID, []string{WaitlistWaiting, WaitlistOffered}).
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// NextWaiting returns the first waiting entry of a session, nil when the
+// waitlist is empty
+func (r *Repository) NextWaiting(tx *gorm.DB, sessionID uint) (*WaitlistEntry, error) {
+	var entry WaitlistEntry
+	err := tx.Where("session_id = ? AND status = ?", sessionID, WaitlistWaiting).Order("id ASC").First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// CountAhead counts the entries waiting before entry
+func (r *Repository) CountAhead(ctx context.Context, entry *WaitlistEntry) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&WaitlistEntry{}).
+		Where("session_id = ? AND status = ? AND id < ?", entry.SessionID, WaitlistWaiting, entry.ID).
+		Count(&count).Error
+	return count, err
+}
+
+// FindEntries lists the waitlist entries of a student, newest first
+func (r *Repository) FindEntries(ctx context.Context, userID uint) ([]WaitlistEntry, error) {
+	var entries []WaitlistEntry
+	err := r.db.WithContext(ctx).Preload("Session").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").Order("id DESC").
+		Limit(100).
+		Find(&entries).Error
+	return entries, err
+}
+
+// FindExpiredOfferSessions returns the sessions with offers whose claim
+// window passed before now
+func (r *Repository) FindExpiredOfferSessions(ctx context.Context, now time.Time) ([]uint, error) {
+	var sessionIDs []uint
+	err := r.db.WithContext(ctx).Model(&WaitlistEntry{}).
+		Where("status = ? AND offer_expires_at < ?", WaitlistOffered, now).
+		Distinct().Pluck("session_id", &sessionIDs).Error
+	return sessionIDs, err
+}
+
+// FindExpiredOffers returns the offers of a session whose claim window passed
+// before now, within tx
+func (r *Repository) FindExpiredOffers(tx *gorm.DB, sessionID uint, now time.Time) ([]WaitlistEntry, error) {
+	var entries []WaitlistEntry
+	err := tx.Where("session_id = ? AND status = ? AND offer_expires_at < ?", sessionID, WaitlistOffered, now).
+		Find(&entries).Error
+	return entries, err
+}
+
+// findHolder returns the name and NIM of a ticket holder
+func (r *Repository) findHolder(ctx context.Context, userID uint) (string, string, error) {
+	var holder struct {
+		FullName string
+		NimNip   string
+	}
+	err := r.db.WithContext(ctx).Table("users").Where("id = ?", userID).Select("full_name, nim_nip").Scan(&holder).Error
+	return holder.FullName, holder.NimNip, err
+}
@@ -0,0 +1,452 @@
+package ticketing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	marketplace   *marketplace.MarketplaceService
+	walletService *wallet.WalletService
+	notifications *notification.NotificationService
+	claimWindow   time.Duration
+}
+
+// NewService makes ticket products sellable: each purchase issues its tickets.
+// A seat offered to a waitlisted student is held for claimWindow.
+func NewService(repo *Repository, db *gorm.DB, marketplaceService *marketplace.MarketplaceService, walletService *wallet.WalletService, claimWindow time.Duration) *Service {
+	s := &Service{
+		repo:          repo,
+		db:            db,
+		marketplace:   marketplaceService,
+		walletService: walletService,
+		claimWindow:   claimWindow,
+	}
+	marketplaceService.RegisterFulfiller(marketplace.ProductTicket, s)
+	return s
+}
+
+// SetNotifications tells waitlisted students when a seat is offered to them
+func (s *Service) SetNotifications(notifications *notification.NotificationService) {
+	s.notifications = notifications
+}
+
+// CheckOrder refuses a ticket purchase the session cannot take before it is paid
+func (s *Service) CheckOrder(ctx context.Context, userID uint, product *marketplace.Product, quantity int, sessionID uint) error {
+	if sessionID == 0 {
+		return ErrSessionRequired
+	}
+	session, err := s.repo.FindSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	_, err = s.checkSeats(s.db.WithContext(ctx), session, userID, product.ID, quantity)
+	return err
+}
+
+// Fulfill takes the seats of a ticket order and issues one ticket per seat in
+// the purchase transaction
+func (s *Service) Fulfill(tx *gorm.DB, userID uint, product *marketplace.Product, order *marketplace.MarketplaceTransaction) error {
+	if order.SessionID == nil {
+		return ErrSessionRequired
+	}
+	session, err := s.repo.LockSession(tx, *order.SessionID)
+	if err != nil {
+		return err
+	}
+	offer, err := s.checkSeats(tx, session, userID, product.ID, order.Quantity)
+	if err != nil {
+		return err
+	}
+
+	tickets := make([]Ticket, order.Quantity)
+	for i := range tickets {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		tickets[i] = Ticket{
+			TenantID:  product.TenantID,
+			Code:      "WPT:" + hex.EncodeToString(b),
+			OrderID:   order.ID,
+			SessionID: session.ID,
+			ProductID: product.ID,
+			UserID:    userID,
+			Status:    TicketValid,
+		}
+	}
+	if err := s.repo.CreateTickets(tx, tickets); err != nil {
+		return err
+	}
+	updates := map[string]interface{}{"sold": gorm.Expr("sold + ?", order.Quantity)}
+	if offer != nil {
+		// The purchase takes the seat held for the student's waitlist offer
+		now := time.Now()
+		if err := s.repo.UpdateEntry(tx, offer.ID, map[string]interface{}{"status": WaitlistClaimed, "claimed_at": now}); err != nil {
+			return err
+		}
+		updates["held"] = gorm.Expr("held - 1")
+	}
+	return s.repo.UpdateSession(tx, session.ID, updates)
+}
+
+// checkSeats tells whether userID can get quantity tickets of session for
+// productID. Seats held for waitlist offers are left out, except the one held
+// for userID, whose offer is returned.
+func (s *Service) checkSeats(db *gorm.DB, session *Session, userID, productID uint, quantity int) (*WaitlistEntry, error) {
+	if session.ProductID != productID {
+		return nil, ErrSessionNotFound
+	}
+	if !time.Now().Before(session.EndsAt) {
+		return nil, ErrSessionEnded
+	}
+
+	var offer *WaitlistEntry
+	left := session.Capacity - session.Sold - session.Held
+	if session.Held > 0 {
+		entry, err := s.repo.FindActiveEntry(db, session.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil && entry.Status == WaitlistOffered && entry.OfferExpiresAt.After(time.Now()) {
+			offer = entry
+			left++
+		}
+	}
+	if quantity > left {
+		return nil, fmt.Errorf("%w: %d left", ErrSessionFull, max(left, 0))
+	}
+
+	if session.MaxPerUser > 0 {
+		held, err := s.repo.CountHeld(db, session.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if int(held)+quantity > session.MaxPerUser {
+			return nil, fmt.Errorf("%w: %d per student, %d already held", ErrTicketLimit, session.MaxPerUser, held)
+		}
+	}
+	return offer, nil
+}
+
+// GetSessions lists the sessions of a ticket product; upcoming limits them to
+// those not ended yet
+func (s *Service) GetSessions(ctx context.Context, productID uint, upcoming bool) ([]Session, error) {
+	if _, err := s.ticketProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	var after *time.Time
+	if upcoming {
+		now := time.Now()
+		after = &now
+	}
+	sessions, err := s.repo.FindSessions(ctx, productID, after)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Available = sessions[i].available()
+	}
+	return sessions, nil
+}
+
+// CreateSession adds a session to a ticket product; its seats add to the stock
+func (s *Service) CreateSession(ctx context.Context, productID uint, req *CreateSessionRequest) (*Session, error) {
+	product, err := s.ticketProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, ErrSessionInvalid
+	}
+
+	session := &Session{
+		TenantID:   product.TenantID,
+		ProductID:  product.ID,
+		Name:       strings.TrimSpace(req.Name),
+		Location:   strings.TrimSpace(req.Location),
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		Capacity:   req.Capacity,
+		MaxPerUser: req.MaxPerUser,
+	}
+	session.Available = session.available()
+	err = utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		if err := s.repo.CreateSession(tx, session); err != nil {
+			return err
+		}
+		return tx.Model(&marketplace.Product{}).Where("id = ?", product.ID).
+			Update("stock", gorm.Expr("stock + ?", session.Capacity)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.marketplace.ProductChanged(product.ID)
+	return session, nil
+}
+
+// UpdateSession changes a session; a new capacity moves the stock of its
+// product by the seats added or removed, and seats added are offered to the
+// waitlist
+func (s *Service) UpdateSession(ctx context.Context, sessionID uint, req *UpdateSessionRequest) (*Session, error) {
+	var session *Session
+	var offers []WaitlistEntry
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		var err error
+		session, err = s.repo.LockSession(tx, sessionID)
+		if err != nil {
+			return err
+		}
+
+		updates := make(map[string]interface{})
+		if name := strings.TrimSpace(req.Name); name != "" {
+			session.Name = name
+			updates["name"] = name
+		}
+		if req.Location != nil {
+			session.Location = strings.TrimSpace(*req.Location)
+			updates["location"] = session.Location
+		}
+		if req.StartsAt != nil {
+			session.StartsAt = *req.StartsAt
+			updates["starts_at"] = session.StartsAt
+		}
+		if req.EndsAt != nil {
+			session.EndsAt = *req.EndsAt
+			updates["ends_at"] = session.EndsAt
+		}
+		if !session.EndsAt.After(session.StartsAt) {
+			return ErrSessionInvalid
+		}
+		if req.MaxPerUser != nil {
+			session.MaxPerUser = *req.MaxPerUser
+			updates["max_per_user"] = session.MaxPerUser
+		}
+		delta := 0
+		if req.Capacity != nil {
+			if *req.Capacity < session.Sold+session.Held {
+				return fmt.Errorf("%w: %d sold, %d held for the waitlist", ErrCapacityBelowSold, session.Sold, session.Held)
+			}
+			delta = *req.Capacity - session.Capacity
+			session.Capacity = *req.Capacity
+			updates["capacity"] = session.Capacity
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := s.repo.UpdateSession(tx, session.ID, updates); err != nil {
+			return err
+		}
+		if delta == 0 {
+			return nil
+		}
+		err = tx.Model(&marketplace.Product{}).Where("id = ?", session.ProductID).
+			Update("stock", gorm.Expr("stock + ?", delta)).Error
+		if err != nil || delta < 0 {
+			return err
+		}
+		offers, err = s.offerSeats(tx, session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if req.Capacity != nil {
+		s.marketplace.ProductChanged(session.ProductID)
+	}
+	s.notifyOffers(session, offers)
+	session.Available = session.available()
+	return session, nil
+}
+
+// CancelTicket cancels a ticket of userID before its session starts and within
+// the return window of the product: the points paid for it go back to the
+// wallet that paid, and the seat goes to the waitlist, or back on sale when
+// nobody is waiting
+func (s *Service) CancelTicket(ctx context.Context, userID, ticketID uint) (*CancelTicketResponse, error) {
+	response := &CancelTicketResponse{}
+	var session *Session
+	var offers []WaitlistEntry
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		ticket, err := s.repo.LockTicket(tx, ticketID)
+		if err != nil {
+			return err
+		}
+		if ticket.UserID != userID {
+			return ErrTicketNotFound
+		}
+		switch ticket.Status {
+		case TicketUsed:
+			return ErrTicketUsed
+		case TicketCancelled:
+			return ErrTicketCancelled
+		}
+		if session, err = s.repo.LockSession(tx, ticket.SessionID); err != nil {
+			return err
+		}
+		if !time.Now().Before(session.StartsAt) {
+			return ErrCancelClosed
+		}
+
+		// Each ticket refunds its share of the order; the last one cancelled
+		// also gets what the division left over
+		var order marketplace.MarketplaceTransaction
+		if err := tx.First(&order, ticket.OrderID).Error; err != nil {
+			return err
+		}
+		if err := s.marketplace.CheckReturnWindow(ctx, order.ProductID, order.CreatedAt); err != nil {
+			return err
+		}
+		cancelled, err := s.repo.CountCancelled(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		response.Refunded = order.TotalAmount / order.Quantity
+		if int(cancelled)+1 == order.Quantity {
+			response.Refunded = order.TotalAmount - response.Refunded*int(cancelled)
+		}
+		if response.Refunded > 0 {
+			description := fmt.Sprintf("Refund: ticket %s for %s cancelled", ticket.Code, session.Name)
+			if err := s.walletService.RefundWithTransaction(tx, order.WalletID, order.DebitID, response.Refunded, "marketplace", description); err != nil {
+				return err
+			}
+		}
+
+		ticket.Status = TicketCancelled
+		if err := s.repo.UpdateTicket(tx, ticket.ID, map[string]interface{}{"status": ticket.Status}); err != nil {
+			return err
+		}
+		if err := s.repo.UpdateSession(tx, session.ID, map[string]interface{}{"sold": gorm.Expr("sold - 1")}); err != nil {
+			return err
+		}
+		if err := tx.Model(&marketplace.Product{}).Where("id = ?", session.ProductID).
+			Update("stock", gorm.Expr("stock + 1")).Error; err != nil {
+			return err
+		}
+		session.Sold--
+		ticket.Session = session
+		response.Ticket = *ticket
+
+		offers, err = s.offerSeats(tx, session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.marketplace.ProductChanged(session.ProductID)
+	s.notifyOffers(session, offers)
+	session.Available = session.available()
+	return response, nil
+}
+
+// CheckIn marks the ticket with code used, once. A sessionID refuses tickets
+// of other sessions, so staff at one door cannot let in the next slot.
+func (s *Service) CheckIn(ctx context.Context, req *CheckInRequest, staffID uint) (*CheckInResponse, error) {
+	var ticket *Ticket
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		var err error
+		ticket, err = s.repo.LockTicketByCode(tx, strings.TrimSpace(req.Code))
+		if err != nil {
+			return err
+		}
+		switch ticket.Status {
+		case TicketUsed:
+			return fmt.Errorf("%w: at %s", ErrTicketUsed, ticket.CheckedInAt.Format(time.RFC3339))
+		case TicketCancelled:
+			return ErrTicketCancelled
+		}
+		if req.SessionID != 0 && ticket.SessionID != req.SessionID {
+			return ErrTicketWrongSession
+		}
+		session, err := s.repo.FindSession(ctx, ticket.SessionID)
+		if err != nil {
+			return err
+		}
+		if !time.Now().Before(session.EndsAt) {
+			return ErrSessionEnded
+		}
+
+		now := time.Now()
+		ticket.Status = TicketUsed
+		ticket.CheckedInAt = &now
+		ticket.CheckedInBy = &staffID
+		ticket.Session = session
+		return s.repo.UpdateTicket(tx, ticket.ID, map[string]interface{}{
+			"status":        ticket.Status,
+			"checked_in_at": now,
+			"checked_in_by": staffID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CheckInResponse{Ticket: *ticket}
+	if response.HolderName, response.HolderNIM, err = s.repo.findHolder(ctx, ticket.UserID); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetTickets lists the tickets of a student
+func (s *Service) GetTickets(ctx context.Context, params TicketListParams) (*TicketListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	tickets, total, err := s.repo.FindTickets(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &TicketListResponse{
+		Tickets:    tickets,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// GetTicket returns a ticket of userID with its QR code
+func (s *Service) GetTicket(ctx context.Context, userID, ticketID uint) (*TicketResponse, error) {
+	ticket, err := s.repo.FindTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.UserID != userID {
+		return nil, ErrTicketNotFound
+	}
+
+	qrCode, err := qrcode.Encode(ticket.Code, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+	return &TicketResponse{Ticket: *ticket, QRCodeBase64: base64.StdEncoding.EncodeToString(qrCode)}, nil
+}
+
+func (s *Service) ticketProduct(ctx context.Context, productID uint) (*marketplace.Product, error) {
+	product, err := s.marketplace.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Type != marketplace.ProductTicket {
+		return nil, ErrNotTicketProduct
+	}
+	return product, nil
+}
@@ -0,0 +1,242 @@
+package ticketing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-point/internal/notification"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// JoinWaitlist puts userID in the queue of a full session
+func (s *Service) JoinWaitlist(ctx context.Context, userID, sessionID uint) (*WaitlistEntry, error) {
+	var entry *WaitlistEntry
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		session, err := s.repo.LockSession(tx, sessionID)
+		if err != nil {
+			return err
+		}
+		if !time.Now().Before(session.EndsAt) {
+			return ErrSessionEnded
+		}
+		if session.available() > 0 {
+			return ErrSessionNotFull
+		}
+		existing, err := s.repo.FindActiveEntry(tx, session.ID, userID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrAlreadyWaitlisted
+		}
+		if session.MaxPerUser > 0 {
+			held, err := s.repo.CountHeld(tx, session.ID, userID)
+			if err != nil {
+				return err
+			}
+			if int(held) >= session.MaxPerUser {
+				return fmt.Errorf("%w: %d per student", ErrTicketLimit, session.MaxPerUser)
+			}
+		}
+
+		entry = &WaitlistEntry{
+			TenantID:  session.TenantID,
+			SessionID: session.ID,
+			ProductID: session.ProductID,
+			UserID:    userID,
+			Status:    WaitlistWaiting,
+		}
+		if err := s.repo.CreateEntry(tx, entry); err != nil {
+			return err
+		}
+		entry.Session = session
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ahead, err := s.repo.CountAhead(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	entry.Position = int(ahead) + 1
+	entry.Session.Available = entry.Session.available()
+	return entry, nil
+}
+
+// LeaveWaitlist takes userID off the waitlist of a session; a seat offered to
+// them goes to the next in line
+func (s *Service) LeaveWaitlist(ctx context.Context, userID, sessionID uint) error {
+	var session *Session
+	var offers []WaitlistEntry
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		var err error
+		if session, err = s.repo.LockSession(tx, sessionID); err != nil {
+			return err
+		}
+		entry, err := s.repo.FindActiveEntry(tx, session.ID, userID)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return ErrNotWaitlisted
+		}
+		if err := s.repo.UpdateEntry(tx, entry.ID, map[string]interface{}{"status": WaitlistLeft}); err != nil {
+			return err
+		}
+		if entry.Status != WaitlistOffered {
+			return nil
+		}
+
+		session.Held--
+		if err := s.repo.UpdateSession(tx, session.ID, map[string]interface{}{"held": session.Held}); err != nil {
+			return err
+		}
+		offers, err = s.offerSeats(tx, session)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyOffers(session, offers)
+	return nil
+}
+
+// GetMyWaitlist lists the waitlist entries of a student with their place in
+// the queue while waiting
+func (s *Service) GetMyWaitlist(ctx context.Context, userID uint) ([]WaitlistEntry, error) {
+	entries, err := s.repo.FindEntries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].Session != nil {
+			entries[i].Session.Available = entries[i].Session.available()
+		}
+		if entries[i].Status != WaitlistWaiting {
+			continue
+		}
+		ahead, err := s.repo.CountAhead(ctx, &entries[i])
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Position = int(ahead) + 1
+	}
+	return entries, nil
+}
+
+// ExpireOffers ends the offers whose claim window has passed and offers their
+// seats to the next students in line
+func (s *Service) ExpireOffers(ctx context.Context) (string, error) {
+	now := time.Now()
+	sessionIDs, err := s.repo.FindExpiredOfferSessions(ctx, now)
+	if err != nil {
+		return "", err
+	}
+
+	expiredTotal, offeredTotal := 0, 0
+	for _, sessionID := range sessionIDs {
+		var session *Session
+		var expired, offers []WaitlistEntry
+		err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+			var err error
+			if session, err = s.repo.LockSession(tx, sessionID); err != nil {
+				return err
+			}
+			if expired, err = s.repo.FindExpiredOffers(tx, session.ID, now); err != nil || len(expired) == 0 {
+				return err
+			}
+			for _, entry := range expired {
+				if err := s.repo.UpdateEntry(tx, entry.ID, map[string]interface{}{"status": WaitlistExpired}); err != nil {
+					return err
+				}
+			}
+			session.Held = max(session.Held-len(expired), 0)
+			if err := s.repo.UpdateSession(tx, session.ID, map[string]interface{}{"held": session.Held}); err != nil {
+				return err
+			}
+			offers, err = s.offerSeats(tx, session)
+			return err
+		})
+		if err != nil {
+			return "", fmt.Errorf("session %d: %w", sessionID, err)
+		}
+
+		for _, entry := range expired {
+			s.notify(entry.UserID, "ticket_offer_expired", "Penawaran kursi tiket berakhir",
+				fmt.Sprintf("Kursi untuk %s yang disediakan untuk Anda tidak dibeli tepat waktu dan telah ditawarkan ke antrean berikutnya.", session.Name))
+		}
+		s.notifyOffers(session, offers)
+		expiredTotal += len(expired)
+		offeredTotal += len(offers)
+	}
+	return fmt.Sprintf("expired %d waitlist offers, offered %d seats", expiredTotal, offeredTotal), nil
+}
+
+// offerSeats holds the seats nobody can buy yet for the first waiting students
+// of the locked session, one each, and returns their offers
+func (s *Service) offerSeats(tx *gorm.DB, session *Session) ([]WaitlistEntry, error) {
+	var offers []WaitlistEntry
+	for session.available() > 0 {
+		entry, err := s.repo.NextWaiting(tx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+
+		now := time.Now()
+		expiresAt := now.Add(s.claimWindow)
+		if session.EndsAt.Before(expiresAt) {
+			expiresAt = session.EndsAt
+		}
+		entry.Status = WaitlistOffered
+		entry.OfferedAt = &now
+		entry.OfferExpiresAt = &expiresAt
+		err = s.repo.UpdateEntry(tx, entry.ID, map[string]interface{}{
+			"status":           entry.Status,
+			"offered_at":       now,
+			"offer_expires_at": expiresAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		session.Held++
+		offers = append(offers, *entry)
+	}
+
+	if len(offers) == 0 {
+		return nil, nil
+	}
+	return offers, s.repo.UpdateSession(tx, session.ID, map[string]interface{}{"held": session.Held})
+}
+
+func (s *Service) notifyOffers(session *Session, offers []WaitlistEntry) {
+	for _, offer := range offers {
+		s.notify(offer.UserID, "ticket_seat_offered", "Kursi tiket tersedia",
+			fmt.Sprintf("Satu kursi untuk %s disediakan untuk Anda hingga %s. Beli tiketnya sebelum itu, atau kursi ditawarkan ke antrean berikutnya.",
+				session.Name, offer.OfferExpiresAt.Format("02 Jan 2006 15:04")))
+	}
+}
+
+func (s *Service) notify(userID uint, notificationType, title, message string) {
+	if s.notifications == nil {
+		return
+	}
+
+	err := s.notifications.Notify(notification.NotifyParams{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Message: message,
+		Link:    "/tickets/waitlist",
+	})
+	if err != nil {
+		slog.Error("tickets: notify student failed", "user_id", userID, "error", err)
+	}
+}
@@ -0,0 +1,13 @@
+package topuprequest
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrRequestNotFound   = utils.NewAppError("TOPUP_REQUEST_NOT_FOUND", http.StatusNotFound, "top-up request not found")
+	ErrRequestPending    = utils.NewAppError("TOPUP_REQUEST_PENDING", http.StatusConflict, "you already have a top-up request waiting for review")
+	ErrRequestReviewed   = utils.NewAppError("TOPUP_REQUEST_REVIEWED", http.StatusConflict, "top-up request was already reviewed")
+	ErrRejectNoteMissing = utils.NewAppError("TOPUP_REQUEST_NOTE_REQUIRED", http.StatusBadRequest, "a rejection needs a note for the student")
+)
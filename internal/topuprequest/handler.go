@@ -0,0 +1,164 @@
+package topuprequest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TopUpRequestHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewTopUpRequestHandler(service *Service, auditService audit.Logger) *TopUpRequestHandler {
+	return &TopUpRequestHandler{service: service, auditService: auditService}
+}
+
+// Create handles requesting points (Mahasiswa)
+// @Summary Request a top-up
+// @Description Ask an admin for points, with the reason. The request waits for the decision without expiring; the points are credited only once it is approved. One request can be pending at a time.
+// @Tags Mahasiswa - Top-up Requests
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateRequest true "Points and reason"
+// @Success 201 {object} utils.Response{data=TopUpRequest}
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /mahasiswa/wallet/topup-requests [post]
+func (h *TopUpRequestHandler) Create(c *gin.Context) {
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	request, err := h.service.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CREATE_TOPUP_REQUEST",
+		Entity:    "TOPUP_REQUEST",
+		EntityID:  request.ID,
+		Details:   fmt.Sprintf("Requested %d points | Reason: %s", request.Points, request.Reason),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	utils.SuccessResponse(c, http.StatusCreated, "Top-up request submitted", request)
+}
+
+// GetMine handles listing the student's top-up requests (Mahasiswa)
+// @Summary List my top-up requests
+// @Tags Mahasiswa - Top-up Requests
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, approved, rejected)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ListResponse}
+// @Router /mahasiswa/wallet/topup-requests [get]
+func (h *TopUpRequestHandler) GetMine(c *gin.Context) {
+	h.list(c, c.GetUint("user_id"))
+}
+
+// GetAll handles listing the top-up requests of the campus (Admin)
+// @Summary List top-up requests
+// @Description The review queue is status=pending, oldest first
+// @Tags Admin - Top-up Requests
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, approved, rejected)
+// @Param user_id query int false "Filter by user"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ListResponse}
+// @Router /admin/topup-requests [get]
+func (h *TopUpRequestHandler) GetAll(c *gin.Context) {
+	userID, _ := strconv.ParseUint(c.Query("user_id"), 10, 32)
+	h.list(c, uint(userID))
+}
+
+// Review handles approving or rejecting a top-up request (Admin)
+// @Summary Review top-up request
+// @Description Approve a pending request, crediting its points, or reject it with a note shown to the student
+// @Tags Admin - Top-up Requests
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Top-up request ID"
+// @Param request body ReviewRequest true "Decision"
+// @Success 200 {object} utils.Response{data=TopUpRequest}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/topup-requests/{id} [patch]
+func (h *TopUpRequestHandler) Review(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid top-up request ID", nil)
+		return
+	}
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	request, err := h.service.Review(c.Request.Context(), uint(id), adminID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	action, details := "APPROVE_TOPUP_REQUEST", fmt.Sprintf("Approved %d points for user %d", request.Points, request.UserID)
+	if request.Status == StatusRejected {
+		action, details = "REJECT_TOPUP_REQUEST", fmt.Sprintf("Rejected %d points for user %d | Reason: %s", request.Points, request.UserID, request.ReviewNote)
+	}
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    action,
+		Entity:    "TOPUP_REQUEST",
+		EntityID:  request.ID,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "Top-up request reviewed", request)
+}
+
+func (h *TopUpRequestHandler) list(c *gin.Context, userID uint) {
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "topup_request_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetRequests(c.Request.Context(), ListParams{
+		UserID: userID,
+		Status: status,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve top-up requests", err.Error())
+		return
+	}
+
+	utils.ListResponse(c, "Top-up requests retrieved successfully", "requests", response.Requests, response.Pagination, nil)
+}
@@ -0,0 +1,65 @@
+package topuprequest
+
+import (
+	"time"
+	"wallet-point/utils"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// TopUpRequest is a student's request for points that an admin approves or
+// rejects. Unlike a paid top-up it never expires: it waits for the decision,
+// and the points are credited only on approval.
+type TopUpRequest struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	TenantID   uint       `json:"tenant_id" gorm:"not null;default:1;index"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	WalletID   uint       `json:"wallet_id" gorm:"not null"`
+	Points     int        `json:"points" gorm:"not null"`
+	Reason     string     `json:"reason" gorm:"size:500;not null"` // why the student needs the points
+	Status     string     `json:"status" gorm:"type:enum('pending','approved','rejected');default:'pending';not null;index"`
+	ReviewNote string     `json:"review_note,omitempty" gorm:"size:255"` // the admin's reason for the decision
+	ReviewedBy *uint      `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (TopUpRequest) TableName() string {
+	return "topup_requests"
+}
+
+// TopUpRequestWithUser is a request as listed to admins
+type TopUpRequestWithUser struct {
+	TopUpRequest
+	FullName string `json:"full_name"`
+	NimNip   string `json:"nim_nip"`
+}
+
+type CreateRequest struct {
+	Points int    `json:"points" binding:"required,points" example:"500"`
+	Reason string `json:"reason" binding:"required,max=500" example:"Poin untuk mencetak tugas akhir"`
+}
+
+// ReviewRequest decides a pending request; a rejection needs a note, which the
+// student is shown
+type ReviewRequest struct {
+	Status string `json:"status" binding:"required,review_status" enums:"approved,rejected"`
+	Note   string `json:"note" binding:"max=255"`
+}
+
+type ListParams struct {
+	UserID uint
+	Status string
+	Page   int
+	Limit  int
+}
+
+type ListResponse struct {
+	Requests []TopUpRequestWithUser `json:"requests"`
+	utils.Pagination
+}
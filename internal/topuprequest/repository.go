@@ -0,0 +1,77 @@
+package topuprequest
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, request *TopUpRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+// Lock loads a request for update within tx, so it is decided once
+func (r *Repository) Lock(tx *gorm.DB, id uint) (*TopUpRequest, error) {
+	var request TopUpRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *Repository) Update(tx *gorm.DB, request *TopUpRequest, updates map[string]interface{}) error {
+	return tx.Model(request).Updates(updates).Error
+}
+
+// hasPending reports whether a user has a request waiting for review
+func (r *Repository) hasPending(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&TopUpRequest{}).
+		Where("user_id = ? AND status = ?", userID, StatusPending).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// FindAll lists requests, oldest pending first for the review queue, newest first otherwise
+func (r *Repository) FindAll(ctx context.Context, params ListParams) ([]TopUpRequestWithUser, int64, error) {
+	var requests []TopUpRequestWithUser
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&TopUpRequest{})
+	if params.UserID != 0 {
+		query = query.Where("topup_requests.user_id = ?", params.UserID)
+	}
+	if params.Status != "" {
+		query = query.Where("topup_requests.status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "topup_requests.created_at DESC"
+	if params.Status == StatusPending {
+		order = "topup_requests.created_at ASC"
+	}
+	offset := (params.Page - 1) * params.Limit
+	err := query.
+		Select("topup_requests.*, users.full_name, users.nim_nip").
+		Joins("LEFT JOIN users ON users.id = topup_requests.user_id").
+		Order(order).Order("topup_requests.id").
+		Limit(params.Limit).Offset(offset).
+		Find(&requests).Error
+	return requests, total, err
+}
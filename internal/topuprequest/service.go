@@ -0,0 +1,151 @@
+package topuprequest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/wallet"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// Service lets students ask for points and admins approve or reject the requests
+type Service struct {
+	repo          *Repository
+	db            *gorm.DB
+	walletService *wallet.WalletService
+	notifications *notification.NotificationService
+}
+
+func NewService(repo *Repository, db *gorm.DB, walletService *wallet.WalletService) *Service {
+	return &Service{repo: repo, db: db, walletService: walletService}
+}
+
+// SetNotifications tells students when their request is decided
+func (s *Service) SetNotifications(notifications *notification.NotificationService) {
+	s.notifications = notifications
+}
+
+// Create records a pending request of userID; a student has one pending request at a time
+func (s *Service) Create(ctx context.Context, userID uint, req CreateRequest) (*TopUpRequest, error) {
+	userWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.repo.hasPending(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrRequestPending
+	}
+
+	request := &TopUpRequest{
+		UserID:   userID,
+		WalletID: userWallet.ID,
+		Points:   req.Points,
+		Reason:   strings.TrimSpace(req.Reason),
+		Status:   StatusPending,
+	}
+	if err := s.repo.Create(ctx, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// Review approves or rejects a pending request. Approving credits the points
+// in the same transaction that records the decision.
+func (s *Service) Review(ctx context.Context, id, adminID uint, req ReviewRequest) (*TopUpRequest, error) {
+	note := strings.TrimSpace(req.Note)
+	if req.Status == StatusRejected && note == "" {
+		return nil, ErrRejectNoteMissing
+	}
+
+	var reviewed *TopUpRequest
+	err := utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		request, err := s.repo.Lock(tx, id)
+		if err != nil {
+			return err
+		}
+		if request.Status != StatusPending {
+			return ErrRequestReviewed
+		}
+
+		if req.Status == StatusApproved {
+			description := fmt.Sprintf("Top-up request #%d approved", request.ID)
+			if err := s.walletService.CreditWithTransaction(tx, request.WalletID, request.Points, "topup", description); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if err := s.repo.Update(tx, request, map[string]interface{}{
+			"status":      req.Status,
+			"review_note": note,
+			"reviewed_by": adminID,
+			"reviewed_at": now,
+		}); err != nil {
+			return err
+		}
+		request.Status = req.Status
+		request.ReviewNote = note
+		request.ReviewedBy = &adminID
+		request.ReviewedAt = &now
+		reviewed = request
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(reviewed)
+	return reviewed, nil
+}
+
+// GetRequests lists the requests of the campus, or of params.UserID
+func (s *Service) GetRequests(ctx context.Context, params ListParams) (*ListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	requests, total, err := s.repo.FindAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if requests == nil {
+		requests = []TopUpRequestWithUser{}
+	}
+	return &ListResponse{
+		Requests:   requests,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+func (s *Service) notify(request *TopUpRequest) {
+	if s.notifications == nil {
+		return
+	}
+
+	params := notification.NotifyParams{
+		UserID: request.UserID,
+		Type:   "topup_request_" + request.Status,
+		Link:   "/wallet/topup-requests",
+	}
+	if request.Status == StatusApproved {
+		params.Title = "Permintaan top-up disetujui"
+		params.Message = fmt.Sprintf("%d poin sudah ditambahkan ke dompet Anda.", request.Points)
+	} else {
+		params.Title = "Permintaan top-up ditolak"
+		params.Message = fmt.Sprintf("Permintaan %d poin Anda ditolak: %s", request.Points, request.ReviewNote)
+	}
+	if err := s.notifications.Notify(params); err != nil {
+		slog.Error("topup request: notify user failed", "request_id", request.ID, "error", err)
+	}
+}
@@ -0,0 +1,14 @@
+package transfer
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrSelfTransfer         = utils.NewAppError("TRANSFER_TO_SELF", http.StatusBadRequest, "cannot transfer points to yourself")
+	ErrSenderWalletNotFound = utils.NewAppError("TRANSFER_SENDER_WALLET_NOT_FOUND", http.StatusNotFound, "sender wallet not found")
+	ErrRecipientNotFound    = utils.NewAppError("TRANSFER_RECIPIENT_NOT_FOUND", http.StatusNotFound, "recipient not found or has no wallet")
+	ErrAmountOverLimit      = utils.NewAppError("TRANSFER_AMOUNT_OVER_LIMIT", http.StatusBadRequest, "transfer amount is over the limit per transfer")
+	ErrDailyLimitReached    = utils.NewAppError("TRANSFER_DAILY_LIMIT_REACHED", http.StatusBadRequest, "transfer would exceed your daily transfer limit")
+)
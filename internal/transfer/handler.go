@@ -13,15 +13,25 @@ import (
 // Handler handles HTTP requests for transfers
 type Handler struct {
 	service      *Service
-	auditService *audit.AuditService
+	auditService audit.Logger
 }
 
 // NewHandler creates a new transfer handler
-func NewHandler(service *Service, auditService *audit.AuditService) *Handler {
+func NewHandler(service *Service, auditService audit.Logger) *Handler {
 	return &Handler{service: service, auditService: auditService}
 }
 
 // CreateTransfer handles POST /transfer
+// @Summary Transfer points
+// @Description Transfer points to another user; large transfers may require confirmation (Mahasiswa only)
+// @Tags Transfer
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body TransferRequest true "Transfer details"
+// @Success 200 {object} utils.Response{data=object{transfer=TransferInfo}}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/transfer [post]
 func (h *Handler) CreateTransfer(c *gin.Context) {
 	senderUserID, exists := c.Get("user_id")
 	if !exists {
@@ -31,13 +41,13 @@ func (h *Handler) CreateTransfer(c *gin.Context) {
 
 	var req TransferRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	transfer, err := h.service.CreateTransfer(senderUserID.(uint), req.ReceiverUserID, req.Amount, req.Description, req.PIN)
+	transfer, err := h.service.CreateTransfer(c.Request.Context(), senderUserID.(uint), req.ReceiverUserID, req.Amount, req.Description, req.PIN)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -56,30 +66,45 @@ func (h *Handler) CreateTransfer(c *gin.Context) {
 }
 
 // GetMyTransfers handles GET /transfer/history
+// @Summary Get my transfers
+// @Tags Transfer
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=object{transfers=[]wallet.TransactionWithDetails,total=int,limit=int,page=int}}
+// @Router /mahasiswa/transfer/history [get]
 func (h *Handler) GetMyTransfers(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
 	if page < 1 {
 		page = 1
 	}
 
 	transfers, total, err := h.service.GetUserTransfers(userID.(uint), limit, page)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Transfer history retrieved successfully", gin.H{
-		"transfers": transfers,
-		"total":     total,
-		"limit":     limit,
-		"page":      page,
-	})
+	utils.ListResponse(c, "Transfer history retrieved successfully", "transfers", transfers,
+		utils.NewPagination(page, limit, total, ""), nil)
 }
 
 // GetRecipientInfo handles GET /transfer/recipient/:id
+// @Summary Get transfer recipient
+// @Description Look up a recipient before transferring
+// @Tags Transfer
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Recipient user ID"
+// @Success 200 {object} utils.Response{data=RecipientSummary}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/transfer/recipient/{id} [get]
 func (h *Handler) GetRecipientInfo(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -90,7 +115,7 @@ func (h *Handler) GetRecipientInfo(c *gin.Context) {
 
 	recipient, err := h.service.FindRecipient(uint(id))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusNotFound, err)
 		return
 	}
 
@@ -98,20 +123,27 @@ func (h *Handler) GetRecipientInfo(c *gin.Context) {
 }
 
 // GetAllTransfers handles GET /admin/transfers
+// @Summary Get all transfers
+// @Description Get all point transfers (Admin only)
+// @Tags Admin - Transactions
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=object{transfers=[]TransferInfo,total=int,limit=int,page=int}}
+// @Router /admin/transfers [get]
 func (h *Handler) GetAllTransfers(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
 
-	transfers, total, err := h.service.GetAllTransfers(limit, page)
+	transfers, total, err := h.service.GetAllTransfers(c.Request.Context(), limit, page)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "All transfers retrieved", gin.H{
-		"transfers": transfers,
-		"total":     total,
-		"limit":     limit,
-		"page":      page,
-	})
+	utils.ListResponse(c, "All transfers retrieved", "transfers", transfers,
+		utils.NewPagination(page, limit, total, ""), nil)
 }
@@ -23,7 +23,7 @@ type TransferInfo struct {
 // TransferRequest represents the request body for creating a transfer
 type TransferRequest struct {
 	ReceiverUserID uint   `json:"receiver_user_id" binding:"required"`
-	Amount         int    `json:"amount" binding:"required,gt=0"`
+	Amount         int    `json:"amount" binding:"required,points"`
 	Description    string `json:"description" binding:"max=255"`
 	PIN            string `json:"pin"`
 }
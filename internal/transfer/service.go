@@ -1,11 +1,18 @@
 package transfer
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"time"
 	"wallet-point/internal/auth"
+	"wallet-point/internal/feature"
+	"wallet-point/internal/metrics"
+	"wallet-point/internal/settings"
+	"wallet-point/internal/tracing"
 	"wallet-point/internal/wallet"
+	"wallet-point/utils"
 
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
@@ -13,6 +20,8 @@ type Service struct {
 	walletRepo    *wallet.WalletRepository
 	walletService *wallet.WalletService
 	authService   *auth.AuthService
+	flags         *feature.FlagService
+	settings      *settings.Service
 	db            *gorm.DB
 }
 
@@ -25,31 +34,64 @@ func NewService(walletRepo *wallet.WalletRepository, walletService *wallet.Walle
 	}
 }
 
-func (s *Service) CreateTransfer(senderUserID, receiverUserID uint, amount int, description string, pin string) (*TransferInfo, error) {
+// SetFeatureFlags injects the flag service so transfers can be switched off without a redeploy
+func (s *Service) SetFeatureFlags(flags *feature.FlagService) {
+	s.flags = flags
+}
+
+// SetSettings enforces the transfer.max_amount and transfer.daily_limit settings
+func (s *Service) SetSettings(settingsService *settings.Service) {
+	s.settings = settingsService
+}
+
+func (s *Service) CreateTransfer(ctx context.Context, senderUserID, receiverUserID uint, amount int, description string, pin string) (_ *TransferInfo, err error) {
+	ctx, span := tracing.Start(ctx, "transfer.CreateTransfer", attribute.Int("user.id", int(senderUserID)), attribute.Int("amount", amount))
+	defer func() {
+		tracing.End(span, err)
+		metrics.ObserveWalletOperation("transfer", err)
+	}()
+
+	if s.flags != nil && !s.flags.Enabled(feature.Transfers) {
+		return nil, feature.ErrFeatureDisabled
+	}
+
 	// 1. Verify PIN
 	if err := s.authService.VerifyPIN(senderUserID, pin); err != nil {
 		return nil, err
 	}
 
 	if senderUserID == receiverUserID {
-		return nil, errors.New("cannot transfer points to yourself")
+		return nil, ErrSelfTransfer
 	}
 
 	senderWallet, err := s.walletService.GetWalletByUserID(senderUserID)
 	if err != nil {
-		return nil, errors.New("sender wallet not found")
+		return nil, ErrSenderWalletNotFound
 	}
 
 	receiverWallet, err := s.walletService.GetWalletByUserID(receiverUserID)
 	if err != nil {
-		return nil, errors.New("receiver wallet not found: check if user exists and has a wallet")
+		return nil, ErrRecipientNotFound
+	}
+	// Points stay within the sender's campus
+	if err := s.walletService.CheckWalletTenant(ctx, receiverWallet.ID); err != nil {
+		return nil, ErrRecipientNotFound
 	}
 
 	if senderWallet.Balance < amount {
-		return nil, errors.New("insufficient balance")
+		return nil, wallet.ErrInsufficientBalance
 	}
+	if s.settings != nil {
+		if limit := s.settings.Int(settings.TransferMaxAmount); limit > 0 && amount > limit {
+			return nil, fmt.Errorf("%w (%d points)", ErrAmountOverLimit, limit)
+		}
+	}
+
+	err = utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		if err := s.CheckDailyLimit(tx, senderWallet.ID, amount); err != nil {
+			return err
+		}
 
-	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Deduct from sender
 		if err := s.walletService.DebitWithTransaction(tx, senderWallet.ID, amount, "transfer_out", fmt.Sprintf("Transfer to user %d: %s", receiverUserID, description)); err != nil {
 			return err
@@ -76,6 +118,61 @@ func (s *Service) CreateTransfer(senderUserID, receiverUserID uint, amount int,
 	}, nil
 }
 
+// CheckDailyLimit locks the sender's wallet so concurrent transfers are summed
+// one after the other, then compares today's transfers plus amount with the
+// limit. Points gifts count as transfers too.
+func (s *Service) CheckDailyLimit(tx *gorm.DB, walletID uint, amount int) error {
+	if s.settings == nil {
+		return nil
+	}
+	limit := s.settings.Int(settings.TransferDailyLimit)
+	if limit <= 0 {
+		return nil
+	}
+
+	if err := tx.Exec("SELECT id FROM wallets WHERE id = ? FOR UPDATE", walletID).Error; err != nil {
+		return err
+	}
+	start, _ := utils.Today()
+	sent, err := sentSince(tx, walletID, start)
+	if err != nil {
+		return err
+	}
+	if sent+amount > limit {
+		return fmt.Errorf("%w: %d of %d points left today", ErrDailyLimitReached, max(limit-sent, 0), limit)
+	}
+	return nil
+}
+
+// sentSince sums the successful outgoing transfers of a wallet since the given time
+func sentSince(tx *gorm.DB, walletID uint, since time.Time) (int, error) {
+	var sent int
+	err := tx.Table("wallet_transactions").
+		Where("wallet_id = ? AND type = ? AND status = ? AND created_at >= ?", walletID, "transfer_out", "success", since).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&sent).Error
+	return sent, err
+}
+
+// Limits returns the transfer.max_amount setting (0 without a limit) and what
+// the owner of walletID may still transfer today, nil without a daily limit
+func (s *Service) Limits(ctx context.Context, walletID uint) (int, *utils.Allowance, error) {
+	if s.settings == nil {
+		return 0, nil, nil
+	}
+	maxAmount := s.settings.Int(settings.TransferMaxAmount)
+	limit := s.settings.Int(settings.TransferDailyLimit)
+	if limit <= 0 {
+		return maxAmount, nil, nil
+	}
+	start, end := utils.Today()
+	sent, err := sentSince(s.db.WithContext(ctx), walletID, start)
+	if err != nil {
+		return 0, nil, err
+	}
+	return maxAmount, utils.NewAllowance(limit, sent, &end), nil
+}
+
 func (s *Service) GetUserTransfers(userID uint, limit, page int) ([]wallet.TransactionWithDetails, int64, error) {
 	walletData, err := s.walletService.GetWalletByUserID(userID)
 	if err != nil {
@@ -90,14 +187,14 @@ func (s *Service) GetUserTransfers(userID uint, limit, page int) ([]wallet.Trans
 	var total int64
 
 	baseQuery := s.db.Table("wallet_transactions").
-		Select("wallet_transactions.*, users.email as user_email, users.full_name as user_name, users.nim_nip").
+		Select(wallet.TransactionDetailColumns).
 		Joins("INNER JOIN wallets ON wallet_transactions.wallet_id = wallets.id").
 		Joins("INNER JOIN users ON wallets.user_id = users.id").
 		Where("wallet_transactions.wallet_id = ? AND wallet_transactions.type IN ('transfer_in', 'transfer_out', 'marketplace')", walletData.ID)
 
 	baseQuery.Count(&total)
 
-	err = baseQuery.Order("created_at DESC").Limit(limit).Offset((page - 1) * limit).Scan(&txs).Error
+	err = baseQuery.Order("wallet_transactions.created_at DESC").Limit(limit).Offset((page - 1) * limit).Scan(&txs).Error
 	return txs, total, err
 }
 
@@ -105,26 +202,26 @@ func (s *Service) FindRecipient(userID uint) (*RecipientSummary, error) {
 	// Check if user has a wallet
 	w, err := s.walletService.GetWalletByUserID(userID)
 	if err != nil {
-		return nil, errors.New("user not found or has no wallet")
+		return nil, ErrRecipientNotFound
 	}
 
 	var recipient RecipientSummary
 	err = s.db.Table("users").
 		Select("id, full_name, role, nim_nip as nim").
-		Where("id = ?", w.UserID).
+		Where("id = ? AND deleted_at IS NULL", w.UserID).
 		Scan(&recipient).Error
 
 	if err != nil {
 		return nil, err
 	}
 	if recipient.ID == 0 {
-		return nil, errors.New("user not found")
+		return nil, ErrRecipientNotFound
 	}
 
 	return &recipient, nil
 }
 
-func (s *Service) GetAllTransfers(limit, page int) ([]TransferInfo, int64, error) {
+func (s *Service) GetAllTransfers(ctx context.Context, limit, page int) ([]TransferInfo, int64, error) {
 	params := wallet.TransactionListParams{
 		Page:  page,
 		Limit: limit,
@@ -133,7 +230,7 @@ func (s *Service) GetAllTransfers(limit, page int) ([]TransferInfo, int64, error
 	// Fetch all transactions of type transfer_out (the sender's perspective) to list unique transfers
 	params.Type = "transfer_out"
 
-	txns, total, err := s.walletService.GetTransactions(params)
+	txns, total, err := s.walletService.GetTransactions(ctx, params)
 	if err != nil {
 		return nil, 0, err
 	}
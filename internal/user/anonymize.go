@@ -0,0 +1,130 @@
+package user
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet-point/internal/jobs"
+	"wallet-point/utils"
+)
+
+const (
+	JobAnonymizeGraduates = "user.anonymize_graduates"
+
+	// MaxGraduateRows caps the rows of one uploaded graduation list
+	MaxGraduateRows = 20000
+
+	graduateDateLayout = "2006-01-02"
+)
+
+// anonymizeJob is the payload of JobAnonymizeGraduates; TenantID 0 covers
+// every campus
+type anonymizeJob struct {
+	TenantID   uint `json:"tenant_id"`
+	AfterYears int  `json:"after_years"`
+}
+
+// SetJobQueue registers the graduate anonymization job on queue; students
+// are anonymized afterYears after graduating unless an upload says otherwise
+func (s *UserService) SetJobQueue(queue *jobs.Queue, afterYears int) {
+	s.jobQueue = queue
+	s.anonymizeAfterYears = afterYears
+	queue.Register(JobAnonymizeGraduates, jobs.QueueDefault, s.anonymizeGraduates)
+}
+
+// ParseGraduates reads a graduation list: CSV rows of NIM and graduation date
+// (YYYY-MM-DD), with an optional header row
+func ParseGraduates(r io.Reader) ([]Graduate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var graduates []Graduate
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrGraduateListInvalid, err)
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("%w: line %d has %d columns", ErrGraduateListInvalid, line, len(record))
+		}
+		nim := strings.TrimSpace(record[0])
+		if line == 1 && strings.EqualFold(strings.TrimPrefix(nim, "\ufeff"), "nim_nip") {
+			continue
+		}
+		graduatedOn, err := time.ParseInLocation(graduateDateLayout, strings.TrimSpace(record[1]), time.Local)
+		if nim == "" || err != nil {
+			return nil, fmt.Errorf("%w: line %d", ErrGraduateListInvalid, line)
+		}
+		if len(graduates) == MaxGraduateRows {
+			return nil, fmt.Errorf("%w: at most %d", ErrGraduateListTooLong, MaxGraduateRows)
+		}
+		graduates = append(graduates, Graduate{NimNip: nim, GraduatedOn: graduatedOn})
+	}
+	if len(graduates) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrGraduateListInvalid)
+	}
+	return graduates, nil
+}
+
+// UploadGraduates flags the students of the campus on the graduation list as
+// graduated and queues the anonymization of every flagged student who
+// graduated more than afterYears ago (0 for the configured default)
+func (s *UserService) UploadGraduates(ctx context.Context, graduates []Graduate, afterYears int, adminID uint) (*GraduateUploadResponse, error) {
+	if afterYears == 0 {
+		afterYears = s.anonymizeAfterYears
+	}
+	flagged, unknown, err := s.repo.FlagGraduates(ctx, graduates)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := anonymizeJob{AfterYears: afterYears}
+	payload.TenantID, _ = utils.TenantFromContext(ctx)
+	job, err := s.jobQueue.Enqueue(JobAnonymizeGraduates, payload, jobs.EnqueueOptions{CreatedBy: &adminID})
+	if err != nil {
+		return nil, err
+	}
+	if unknown == nil {
+		unknown = []string{}
+	}
+	return &GraduateUploadResponse{Flagged: flagged, Unknown: unknown, AfterYears: afterYears, Job: job}, nil
+}
+
+// anonymizeGraduates replaces the personal data of the graduates flagged long
+// enough ago. Wallets, their ledger and orders keep their amounts, so the
+// financial reports and aggregates do not change.
+func (s *UserService) anonymizeGraduates(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	var payload anonymizeJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	if payload.TenantID != 0 {
+		ctx = utils.WithTenant(ctx, payload.TenantID)
+	}
+
+	before := time.Now().AddDate(-payload.AfterYears, 0, 0)
+	users, err := s.repo.FindGraduatesToAnonymize(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	result := AnonymizationResult{Before: before.Format(graduateDateLayout), Anonymized: []uint{}}
+	for i := range users {
+		// Anonymized users are skipped when the job is retried
+		if err := s.repo.Anonymize(ctx, &users[i]); err != nil {
+			return nil, fmt.Errorf("anonymizing user %d: %w", users[i].ID, err)
+		}
+		result.Anonymized = append(result.Anonymized, users[i].ID)
+	}
+	slog.InfoContext(ctx, "graduates anonymized", "job_id", job.ID, "users", len(result.Anonymized))
+	return result, nil
+}
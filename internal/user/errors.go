@@ -0,0 +1,17 @@
+package user
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrUserNotFound   = utils.NewAppError("USER_NOT_FOUND", http.StatusNotFound, "user not found")
+	ErrEmailTaken     = utils.NewAppError("USER_EMAIL_TAKEN", http.StatusConflict, "email already exists")
+	ErrHashFailed     = utils.NewAppError("AUTH_HASH_FAILED", http.StatusInternalServerError, "failed to secure new password")
+	ErrUserNotDeleted = utils.NewAppError("USER_NOT_DELETED", http.StatusConflict, "user is not deleted")
+	ErrRoleNotAllowed = utils.NewAppError("USER_ROLE_NOT_ALLOWED", http.StatusForbidden, "only super admins can grant this role")
+
+	ErrGraduateListInvalid = utils.NewAppError("GRADUATE_LIST_INVALID", http.StatusBadRequest, "the graduation list must be a CSV of nim_nip,graduated_on (YYYY-MM-DD) rows")
+	ErrGraduateListTooLong = utils.NewAppError("GRADUATE_LIST_TOO_LONG", http.StatusBadRequest, "the graduation list has too many rows")
+)
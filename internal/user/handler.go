@@ -1,6 +1,7 @@
 package user
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"wallet-point/internal/audit"
@@ -11,10 +12,10 @@ import (
 
 type UserHandler struct {
 	service      *UserService
-	auditService *audit.AuditService
+	auditService audit.Logger
 }
 
-func NewUserHandler(service *UserService, auditService *audit.AuditService) *UserHandler {
+func NewUserHandler(service *UserService, auditService audit.Logger) *UserHandler {
 	return &UserHandler{service: service, auditService: auditService}
 }
 
@@ -26,33 +27,45 @@ func NewUserHandler(service *UserService, auditService *audit.AuditService) *Use
 // @Produce json
 // @Param role query string false "Filter by role" Enums(admin, dosen, mahasiswa)
 // @Param status query string false "Filter by status" Enums(active, inactive, suspended)
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(20)
+// @Param include_deleted query bool false "Also list soft-deleted users"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
 // @Success 200 {object} utils.Response{data=UserListResponse}
 // @Failure 401 {object} utils.Response
 // @Failure 403 {object} utils.Response
 // @Router /admin/users [get]
 func (h *UserHandler) GetAll(c *gin.Context) {
 	// Parse query parameters
-	role := c.Query("role")
-	status := c.Query("status")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	role, ok := utils.QueryEnum(c, "role", "user_role")
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "user_status")
+	if !ok {
+		return
+	}
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
 
 	params := UserListParams{
-		Role:   role,
-		Status: status,
-		Page:   page,
-		Limit:  limit,
+		Role:           role,
+		Status:         status,
+		IncludeDeleted: includeDeleted,
+		Page:           page,
+		Limit:          limit,
 	}
 
-	response, err := h.service.GetAllUsers(params)
+	response, err := h.service.GetAllUsers(c.Request.Context(), params)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve users", err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Users retrieved successfully", response)
+	utils.ListResponse(c, "Users retrieved successfully", "users", response.Users, response.Pagination, nil)
 }
 
 // GetByID handles getting user by ID
@@ -72,9 +85,9 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.GetUserByID(uint(userID))
+	user, err := h.service.GetUserByID(c.Request.Context(), uint(userID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusNotFound, err)
 		return
 	}
 
@@ -92,6 +105,7 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 // @Param request body UpdateUserRequest true "Update data"
 // @Success 200 {object} utils.Response{data=User}
 // @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 404 {object} utils.Response
 // @Router /admin/users/{id} [put]
 func (h *UserHandler) Update(c *gin.Context) {
@@ -103,17 +117,18 @@ func (h *UserHandler) Update(c *gin.Context) {
 
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	user, err := h.service.UpdateUser(uint(userID), &req)
+	if req.Role == "superadmin" && c.GetString("role") != "superadmin" {
+		utils.ServiceErrorResponse(c, http.StatusForbidden, ErrRoleNotAllowed)
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.Request.Context(), uint(userID), &req)
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "user not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -132,9 +147,9 @@ func (h *UserHandler) Update(c *gin.Context) {
 	})
 }
 
-// Deactivate handles deactivating user
-// @Summary Deactivate user
-// @Description Deactivate user account (Admin only)
+// Delete handles deleting user
+// @Summary Delete user
+// @Description Soft delete a user account; it can no longer log in and can be restored later (Admin only)
 // @Tags Admin - Users
 // @Security BearerAuth
 // @Produce json
@@ -142,32 +157,67 @@ func (h *UserHandler) Update(c *gin.Context) {
 // @Success 200 {object} utils.Response
 // @Failure 404 {object} utils.Response
 // @Router /admin/users/{id} [delete]
-func (h *UserHandler) Deactivate(c *gin.Context) {
+func (h *UserHandler) Delete(c *gin.Context) {
 	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
 		return
 	}
 
-	if err := h.service.DeactivateUser(uint(userID)); err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "user not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+	if err := h.service.DeleteUser(c.Request.Context(), uint(userID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "User deactivated successfully", nil)
+	utils.SuccessResponse(c, http.StatusOK, "User deleted successfully", nil)
 
 	// Log activity
 	adminID := c.GetUint("user_id")
 	h.auditService.LogActivity(audit.CreateAuditParams{
 		UserID:    adminID,
-		Action:    "DEACTIVATE_USER",
+		Action:    "DELETE_USER",
 		Entity:    "USER",
 		EntityID:  uint(userID),
-		Details:   "Admin deactivated user account",
+		Details:   "Admin deleted user account",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Restore handles restoring a deleted user
+// @Summary Restore user
+// @Description Restore a soft-deleted user account (Admin only)
+// @Tags Admin - Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response{data=User}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/users/{id}/restore [post]
+func (h *UserHandler) Restore(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	user, err := h.service.RestoreUser(c.Request.Context(), uint(userID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User restored successfully", user)
+
+	// Log activity
+	adminID := c.GetUint("user_id")
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "RESTORE_USER",
+		Entity:    "USER",
+		EntityID:  user.ID,
+		Details:   "Admin restored user account: " + user.Email,
 		IPAddress: c.ClientIP(),
 		UserAgent: c.Request.UserAgent(),
 	})
@@ -195,16 +245,12 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	if err := h.service.ChangeUserPassword(uint(userID), req.NewPassword); err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "user not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+	if err := h.service.ChangeUserPassword(c.Request.Context(), uint(userID), req.NewPassword); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -246,7 +292,7 @@ func (h *UserHandler) LookupUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.GetUserByID(uint(userID))
+	user, err := h.service.GetUserByID(c.Request.Context(), uint(userID))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "User not found", nil)
 		return
@@ -259,3 +305,64 @@ func (h *UserHandler) LookupUser(c *gin.Context) {
 		"role":      user.Role,
 	})
 }
+
+// UploadGraduates handles the campus graduation list
+// @Summary Upload graduation list
+// @Description Flag the students on the campus graduation list (CSV of nim_nip,graduated_on with YYYY-MM-DD dates; a header row is optional) as graduated, then queue a job that anonymizes every flagged student who graduated more than after_years ago. Names, emails, NIMs, credentials, phone numbers, chat links, message recipients the details, IP and browser of their audit entries and the details of admin audit entries about them are replaced or removed; wallets, their ledger and orders keep their amounts. Poll GET /admin/jobs/{id} for the anonymized users (Admin only).
+// @Tags Admin - Users
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Graduation list (CSV)"
+// @Param after_years formData int false "Years after graduation, defaults to ANONYMIZE_AFTER_YEARS" minimum(1) maximum(50)
+// @Success 202 {object} utils.Response{data=GraduateUploadResponse}
+// @Failure 400 {object} utils.Response
+// @Router /admin/users/graduates [post]
+func (h *UserHandler) UploadGraduates(c *gin.Context) {
+	afterYears := 0
+	if raw := c.PostForm("after_years"); raw != "" {
+		years, err := strconv.Atoi(raw)
+		if err != nil || years < 1 || years > 50 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "after_years must be between 1 and 50", nil)
+			return
+		}
+		afterYears = years
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Graduation list file is required", nil)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read graduation list", nil)
+		return
+	}
+	defer file.Close()
+
+	graduates, err := ParseGraduates(file)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	response, err := h.service.UploadGraduates(c.Request.Context(), graduates, afterYears, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "ANONYMIZE_GRADUATES",
+		Entity:    "JOB",
+		EntityID:  response.Job.ID,
+		Details:   fmt.Sprintf("Admin flagged %d graduates and queued the anonymization of those graduated over %d years ago (job #%d)", response.Flagged, response.AfterYears, response.Job.ID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Graduation list uploaded, anonymization queued", response)
+}
@@ -2,19 +2,27 @@ package user
 
 import (
 	"time"
+	"wallet-point/internal/jobs"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	Email        string    `json:"email" gorm:"uniqueIndex;not null"`
-	PasswordHash string    `json:"-" gorm:"column:password_hash;not null"`
-	FullName     string    `json:"full_name" gorm:"not null"`
-	NimNip       string    `json:"nim_nip" gorm:"uniqueIndex;not null"`
-	Role         string    `json:"role" gorm:"type:enum('admin','dosen','mahasiswa');not null"`
-	Status       string    `json:"status" gorm:"type:enum('active','inactive','suspended');default:'active'"`
-	PinHash      string    `json:"-" gorm:"column:pin_hash"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Email        string         `json:"email" gorm:"uniqueIndex;not null"`
+	PasswordHash string         `json:"-" gorm:"column:password_hash;not null"`
+	FullName     string         `json:"full_name" gorm:"not null"`
+	NimNip       string         `json:"nim_nip" gorm:"uniqueIndex;not null"`
+	Role         string         `json:"role" gorm:"type:enum('superadmin','admin','dosen','mahasiswa');not null"`
+	Status       string         `json:"status" gorm:"type:enum('active','inactive','suspended');default:'active'"`
+	TenantID     uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	PinHash      string         `json:"-" gorm:"column:pin_hash;size:255;serializer:pii"`
+	GraduatedOn  *time.Time     `json:"graduated_on,omitempty" gorm:"type:date;index"` // from the campus graduation list
+	AnonymizedAt *time.Time     `json:"anonymized_at,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string"`
 }
 
 func (User) TableName() string {
@@ -42,17 +50,19 @@ type UserWithWallet struct {
 	NimNip     string     `json:"nim_nip"`
 	Role       string     `json:"role"`
 	Status     string     `json:"status"`
+	TenantID   uint       `json:"tenant_id"`
 	Balance    int        `json:"balance"`
 	LastSyncAt *time.Time `json:"last_sync_at,omitempty"`
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
 
 type UpdateUserRequest struct {
 	FullName string `json:"full_name,omitempty"`
 	Email    string `json:"email,omitempty" binding:"omitempty,email"`
-	Status   string `json:"status,omitempty" binding:"omitempty,oneof=active inactive suspended"`
-	Role     string `json:"role,omitempty" binding:"omitempty,oneof=admin dosen mahasiswa"`
+	Status   string `json:"status,omitempty" binding:"omitempty,user_status" enums:"active,inactive,suspended"`
+	Role     string `json:"role,omitempty" binding:"omitempty,user_role" enums:"superadmin,admin,dosen,mahasiswa"`
 }
 
 type ChangePasswordRequest struct {
@@ -60,16 +70,35 @@ type ChangePasswordRequest struct {
 }
 
 type UserListParams struct {
-	Role   string
-	Status string
-	Page   int
-	Limit  int
+	Role           string
+	Status         string
+	IncludeDeleted bool
+	Page           int
+	Limit          int
 }
 
 type UserListResponse struct {
-	Users      []UserWithWallet `json:"users"`
-	Total      int64            `json:"total"`
-	Page       int              `json:"page"`
-	Limit      int              `json:"limit"`
-	TotalPages int              `json:"total_pages"`
+	Users []UserWithWallet `json:"users"`
+	utils.Pagination
+}
+
+// Graduate is one row of the campus graduation list
+type Graduate struct {
+	NimNip      string
+	GraduatedOn time.Time
+}
+
+// GraduateUploadResponse tells which students of an uploaded graduation list
+// were flagged, and the job anonymizing those who graduated long enough ago
+type GraduateUploadResponse struct {
+	Flagged    int       `json:"flagged"`
+	Unknown    []string  `json:"unknown"` // NIMs without a student account on the campus
+	AfterYears int       `json:"after_years"`
+	Job        *jobs.Job `json:"job"`
+}
+
+// AnonymizationResult is the result of a graduate anonymization job
+type AnonymizationResult struct {
+	Before     string `json:"graduated_before"`
+	Anonymized []uint `json:"anonymized"` // user IDs
 }
@@ -1,35 +1,44 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
+	"wallet-point/utils"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	replica *gorm.DB // admin user listing
 }
 
 func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{db: db}
+	return &UserRepository{db: db, replica: utils.ReadReplica(db)}
 }
 
 // GetAllWithWallets gets all users with their wallet information
-func (r *UserRepository) GetAllWithWallets(params UserListParams) ([]UserWithWallet, int64, error) {
+func (r *UserRepository) GetAllWithWallets(ctx context.Context, params UserListParams) ([]UserWithWallet, int64, error) {
 	var users []UserWithWallet
 	var total int64
 
-	query := r.db.Table("users").
+	query := r.replica.WithContext(ctx).Table("users").
 		Select("users.*, wallets.id as wallet_id, COALESCE(wallets.balance, 0) as balance, wallets.last_sync_at").
 		Joins("LEFT JOIN wallets ON users.id = wallets.user_id")
 
 	// Apply filters
+	if !params.IncludeDeleted {
+		query = query.Where("users.deleted_at IS NULL")
+	}
 	if params.Role != "" {
 		query = query.Where("users.role = ?", params.Role)
 	}
 	if params.Status != "" {
 		query = query.Where("users.status = ?", params.Status)
 	}
+	query = query.Scopes(utils.ScopeTenant(ctx, "users.tenant_id"))
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
@@ -48,12 +57,12 @@ func (r *UserRepository) GetAllWithWallets(params UserListParams) ([]UserWithWal
 }
 
 // FindByID finds user by ID
-func (r *UserRepository) FindByID(userID uint) (*User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, userID uint) (*User, error) {
 	var user User
-	err := r.db.First(&user, userID).Error
+	err := r.db.WithContext(ctx).First(&user, userID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -61,45 +70,189 @@ func (r *UserRepository) FindByID(userID uint) (*User, error) {
 }
 
 // FindByIDWithWallet finds user by ID with wallet
-func (r *UserRepository) FindByIDWithWallet(userID uint) (*UserWithWallet, error) {
+func (r *UserRepository) FindByIDWithWallet(ctx context.Context, userID uint) (*UserWithWallet, error) {
 	var user UserWithWallet
-	err := r.db.Table("users").
+	err := r.db.WithContext(ctx).Table("users").
 		Select("users.*, wallets.id as wallet_id, COALESCE(wallets.balance, 0) as balance, wallets.last_sync_at").
 		Joins("LEFT JOIN wallets ON users.id = wallets.user_id").
-		Where("users.id = ?", userID).
+		Where("users.id = ? AND users.deleted_at IS NULL", userID).
+		Scopes(utils.ScopeTenant(ctx, "users.tenant_id")).
 		Scan(&user).Error
 
 	if err != nil {
 		return nil, err
 	}
 	if user.ID == 0 {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	return &user, nil
 }
 
 // Update updates user information
-func (r *UserRepository) Update(userID uint, updates map[string]interface{}) error {
-	return r.db.Model(&User{}).Where("id = ?", userID).Updates(updates).Error
+func (r *UserRepository) Update(ctx context.Context, userID uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Updates(updates).Error
+}
+
+// Delete soft deletes user; the row is kept for history and can be restored
+func (r *UserRepository) Delete(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Delete(&User{}, userID).Error
 }
 
-// Delete soft deletes user by setting status to inactive
-func (r *UserRepository) Delete(userID uint) error {
-	return r.db.Model(&User{}).Where("id = ?", userID).Update("status", "inactive").Error
+// FindByIDUnscoped finds user by ID, including soft-deleted users
+func (r *UserRepository) FindByIDUnscoped(ctx context.Context, userID uint) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).Unscoped().First(&user, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Restore clears the deletion mark of a soft-deleted user
+func (r *UserRepository) Restore(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&User{}).Where("id = ?", userID).Update("deleted_at", nil).Error
 }
 
 // UpdatePassword updates user password
-func (r *UserRepository) UpdatePassword(userID uint, hashedPassword string) error {
-	return r.db.Model(&User{}).Where("id = ?", userID).Update("password_hash", hashedPassword).Error
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uint, hashedPassword string) error {
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("password_hash", hashedPassword).Error
 }
 
-// CheckEmailExists checks if email exists (excluding current user)
+// CheckEmailExists checks if email exists (excluding current user), including on deleted accounts
 func (r *UserRepository) CheckEmailExists(email string, excludeUserID uint) (bool, error) {
 	var count int64
-	query := r.db.Model(&User{}).Where("email = ?", email)
+	query := r.db.Unscoped().Model(&User{}).Where("email = ?", email)
 	if excludeUserID > 0 {
 		query = query.Where("id != ?", excludeUserID)
 	}
 	err := query.Count(&count).Error
 	return count > 0, err
 }
+
+// FlagGraduates sets the graduation date of the students on the list, deleted
+// accounts included, and returns how many were found and the NIMs that were not
+func (r *UserRepository) FlagGraduates(ctx context.Context, graduates []Graduate) (int, []string, error) {
+	dates := make(map[string]time.Time, len(graduates))
+	nims := make([]string, 0, len(graduates))
+	for _, graduate := range graduates {
+		if _, ok := dates[graduate.NimNip]; !ok {
+			nims = append(nims, graduate.NimNip)
+		}
+		dates[graduate.NimNip] = graduate.GraduatedOn
+	}
+
+	found := make(map[string]bool, len(nims))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(nims); start += 1000 {
+			end := min(start+1000, len(nims))
+			var students []User
+			err := tx.Unscoped().Select("id, nim_nip").
+				Where("role = ? AND nim_nip IN ?", "mahasiswa", nims[start:end]).
+				Find(&students).Error
+			if err != nil {
+				return err
+			}
+
+			// One update per graduation date; a list has few of them
+			byDate := make(map[time.Time][]uint)
+			for _, student := range students {
+				found[student.NimNip] = true
+				date := dates[student.NimNip]
+				byDate[date] = append(byDate[date], student.ID)
+			}
+			for date, ids := range byDate {
+				if err := tx.Unscoped().Model(&User{}).Where("id IN ?", ids).Update("graduated_on", date).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var unknown []string
+	for _, nim := range nims {
+		if !found[nim] {
+			unknown = append(unknown, nim)
+		}
+	}
+	return len(found), unknown, nil
+}
+
+// FindGraduatesToAnonymize lists the students, deleted accounts included, who
+// graduated before the given date and still have their personal data
+func (r *UserRepository) FindGraduatesToAnonymize(ctx context.Context, before time.Time) ([]User, error) {
+	var users []User
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("role = ? AND graduated_on < ? AND anonymized_at IS NULL", "mahasiswa", before).
+		Order("id").
+		Find(&users).Error
+	return users, err
+}
+
+// Anonymize replaces the name, email, NIM and credentials of a user, also on the
+// orders they bought or received, and drops their phone numbers, chat links and
+// message recipients, the details, IP and browser of their audit entries and
+// the details of the entries admins wrote about them
+func (r *UserRepository) Anonymize(ctx context.Context, user *User) error {
+	now := time.Now()
+	user.FullName = fmt.Sprintf("Anonymized user #%d", user.ID)
+	user.Email = fmt.Sprintf("anonymized-%d@anonymized.invalid", user.ID)
+	user.NimNip = fmt.Sprintf("ANON-%d", user.ID)
+	user.Status = "inactive"
+	user.AnonymizedAt = &now
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"full_name":     user.FullName,
+			"email":         user.Email,
+			"nim_nip":       user.NimNip,
+			"password_hash": "!", // matches no password
+			"pin_hash":      "",
+			"status":        user.Status,
+			"anonymized_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// Major and batch stay for the cohort reports
+		err = tx.Table("marketplace_transactions").
+			Where("wallet_id IN (SELECT id FROM wallets WHERE user_id = ?) OR recipient_user_id = ?", user.ID, user.ID).
+			Updates(map[string]interface{}{"student_name": user.FullName, "student_npm": user.NimNip}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Table("message_logs").Where("user_id = ?", user.ID).Update("phone_number", "").Error; err != nil {
+			return err
+		}
+		for _, table := range []string{"outbound_messages", "notification_dead_letters"} {
+			if err := tx.Table(table).Where("user_id = ?", user.ID).Update("recipient", "").Error; err != nil {
+				return err
+			}
+		}
+		// The action and entity stay so the trail still shows what happened
+		err = tx.Table("audit_logs").Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"details": "", "ip_address": "", "user_agent": ""}).Error
+		if err != nil {
+			return err
+		}
+		// Entries admins wrote about the user name them in the details; the
+		// admin's own IP and browser stay
+		err = tx.Table("audit_logs").Where("entity = ? AND entity_id = ?", "USER", user.ID).Update("details", "").Error
+		if err != nil {
+			return err
+		}
+		for _, table := range []string{"user_phones", "otp_codes", "telegram_links", "telegram_link_codes"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE user_id = ?", user.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,13 +1,16 @@
 package user
 
 import (
-	"errors"
-	"math"
+	"context"
+	"wallet-point/internal/jobs"
 	"wallet-point/utils"
 )
 
 type UserService struct {
 	repo *UserRepository
+
+	jobQueue            *jobs.Queue
+	anonymizeAfterYears int
 }
 
 func NewUserService(repo *UserRepository) *UserService {
@@ -15,7 +18,7 @@ func NewUserService(repo *UserRepository) *UserService {
 }
 
 // GetAllUsers gets all users with pagination and filters
-func (s *UserService) GetAllUsers(params UserListParams) (*UserListResponse, error) {
+func (s *UserService) GetAllUsers(ctx context.Context, params UserListParams) (*UserListResponse, error) {
 	// Default pagination
 	if params.Page < 1 {
 		params.Page = 1
@@ -24,31 +27,39 @@ func (s *UserService) GetAllUsers(params UserListParams) (*UserListResponse, err
 		params.Limit = 20
 	}
 
-	users, total, err := s.repo.GetAllWithWallets(params)
+	users, total, err := s.repo.GetAllWithWallets(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
-
 	return &UserListResponse{
 		Users:      users,
-		Total:      total,
-		Page:       params.Page,
-		Limit:      params.Limit,
-		TotalPages: totalPages,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
 	}, nil
 }
 
 // GetUserByID gets user by ID with wallet
-func (s *UserService) GetUserByID(userID uint) (*UserWithWallet, error) {
-	return s.repo.FindByIDWithWallet(userID)
+func (s *UserService) GetUserByID(ctx context.Context, userID uint) (*UserWithWallet, error) {
+	return s.repo.FindByIDWithWallet(ctx, userID)
+}
+
+// findManaged finds a user the caller may change: campus admins only manage their own
+// campus (the tenant of ctx) and never a super admin account
+func (s *UserService) findManaged(ctx context.Context, userID uint, find func(context.Context, uint) (*User, error)) (*User, error) {
+	user, err := find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, scoped := utils.TenantFromContext(ctx); scoped && user.Role == "superadmin" {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
 }
 
 // UpdateUser updates user information
-func (s *UserService) UpdateUser(userID uint, req *UpdateUserRequest) (*User, error) {
+func (s *UserService) UpdateUser(ctx context.Context, userID uint, req *UpdateUserRequest) (*User, error) {
 	// Check if user exists
-	_, err := s.repo.FindByID(userID)
+	_, err := s.findManaged(ctx, userID, s.repo.FindByID)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +77,7 @@ func (s *UserService) UpdateUser(userID uint, req *UpdateUserRequest) (*User, er
 			return nil, err
 		}
 		if exists {
-			return nil, errors.New("email already exists")
+			return nil, ErrEmailTaken
 		}
 		updates["email"] = req.Email
 	}
@@ -79,30 +90,46 @@ func (s *UserService) UpdateUser(userID uint, req *UpdateUserRequest) (*User, er
 
 	// Update user
 	if len(updates) > 0 {
-		if err := s.repo.Update(userID, updates); err != nil {
+		if err := s.repo.Update(ctx, userID, updates); err != nil {
 			return nil, err
 		}
 	}
 
 	// Return updated user
-	return s.repo.FindByID(userID)
+	return s.repo.FindByID(ctx, userID)
 }
 
-// DeactivateUser deactivates user account
-func (s *UserService) DeactivateUser(userID uint) error {
+// DeleteUser soft deletes user account
+func (s *UserService) DeleteUser(ctx context.Context, userID uint) error {
 	// Check if user exists
-	_, err := s.repo.FindByID(userID)
+	_, err := s.findManaged(ctx, userID, s.repo.FindByID)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.Delete(userID)
+	return s.repo.Delete(ctx, userID)
+}
+
+// RestoreUser brings back a soft-deleted user account
+func (s *UserService) RestoreUser(ctx context.Context, userID uint) (*User, error) {
+	user, err := s.findManaged(ctx, userID, s.repo.FindByIDUnscoped)
+	if err != nil {
+		return nil, err
+	}
+	if !user.DeletedAt.Valid {
+		return nil, ErrUserNotDeleted
+	}
+
+	if err := s.repo.Restore(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, userID)
 }
 
 // ChangeUserPassword changes user password (admin function)
-func (s *UserService) ChangeUserPassword(userID uint, newPassword string) error {
+func (s *UserService) ChangeUserPassword(ctx context.Context, userID uint, newPassword string) error {
 	// Check if user exists
-	_, err := s.repo.FindByID(userID)
+	_, err := s.findManaged(ctx, userID, s.repo.FindByID)
 	if err != nil {
 		return err
 	}
@@ -110,8 +137,8 @@ func (s *UserService) ChangeUserPassword(userID uint, newPassword string) error
 	// Hash password
 	hashedPassword, err := utils.HashPassword(newPassword)
 	if err != nil {
-		return errors.New("failed to secure new password")
+		return ErrHashFailed
 	}
 
-	return s.repo.UpdatePassword(userID, hashedPassword)
+	return s.repo.UpdatePassword(ctx, userID, hashedPassword)
 }
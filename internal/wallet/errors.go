@@ -0,0 +1,21 @@
+package wallet
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrWalletNotFound        = utils.NewAppError("WALLET_NOT_FOUND", http.StatusNotFound, "wallet not found")
+	ErrInsufficientBalance   = utils.NewAppError("WALLET_INSUFFICIENT_BALANCE", http.StatusBadRequest, "insufficient balance")
+	ErrPaymentTokenNotFound  = utils.NewAppError("PAYMENT_TOKEN_NOT_FOUND", http.StatusNotFound, "payment token not found")
+	ErrPaymentTokenInvalid   = utils.NewAppError("PAYMENT_TOKEN_INVALID", http.StatusBadRequest, "invalid or expired QR token")
+	ErrPaymentTokenExpired   = utils.NewAppError("PAYMENT_TOKEN_EXPIRED", http.StatusBadRequest, "QR token has expired")
+	ErrPaymentTokenNotOwned  = utils.NewAppError("PAYMENT_TOKEN_NOT_OWNED", http.StatusForbidden, "token does not belong to this user")
+	ErrPaymentTokenMismatch  = utils.NewAppError("PAYMENT_TOKEN_AMOUNT_MISMATCH", http.StatusBadRequest, "token amount mismatch")
+	ErrPaymentRecipientSetup = utils.NewAppError("PAYMENT_RECIPIENT_UNAVAILABLE", http.StatusInternalServerError, "payment recipient is unavailable")
+	ErrDebitNotFound         = utils.NewAppError("WALLET_DEBIT_NOT_FOUND", http.StatusNotFound, "debit to refund not found")
+	ErrRefundExceedsDebit    = utils.NewAppError("WALLET_REFUND_EXCEEDS_DEBIT", http.StatusConflict, "refund is larger than what is left of the debit")
+
+	ErrExternalReferenceReused = utils.NewAppError("EXTERNAL_REFERENCE_REUSED", http.StatusConflict, "reference was already used for a different operation")
+)
@@ -0,0 +1,40 @@
+package wallet
+
+import (
+	"wallet-point/internal/events"
+
+	"gorm.io/gorm"
+)
+
+// SetOutbox publishes points.debited / points.credited for every ledger entry
+func (s *WalletService) SetOutbox(outbox *events.Outbox) {
+	s.outbox = outbox
+}
+
+// recordTransaction writes txn and publishes its domain event in the same
+// transaction, so subscribers never hear about a rolled back movement
+func (s *WalletService) recordTransaction(tx *gorm.DB, txn *WalletTransaction) error {
+	if err := s.repo.CreateTransaction(tx, txn); err != nil {
+		return err
+	}
+	if s.outbox == nil {
+		return nil
+	}
+
+	eventType := events.EventPointsCredited
+	if txn.Direction == "debit" {
+		eventType = events.EventPointsDebited
+	}
+	return s.outbox.Publish(tx, eventType, events.LedgerEntry{
+		ID:          txn.ID,
+		WalletID:    txn.WalletID,
+		Type:        txn.Type,
+		Amount:      txn.Amount,
+		Direction:   txn.Direction,
+		ReferenceID: txn.ReferenceID,
+		Status:      txn.Status,
+		Description: txn.Description,
+		CreatedBy:   txn.CreatedBy,
+		CreatedAt:   txn.CreatedAt,
+	})
+}
@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetWalletByNimNip returns the wallet of an active user, looked up by NIM/NIP
+func (s *WalletService) GetWalletByNimNip(nimNip string) (*WalletWithUser, error) {
+	return s.repo.FindByNimNip(nimNip)
+}
+
+// ApplyExternalOperation credits or debits a wallet for a campus system. The first
+// request with a (client, reference) pair moves the points; later ones return the
+// recorded operation with replayed set, or ErrExternalReferenceReused when they differ.
+func (s *WalletService) ApplyExternalOperation(ctx context.Context, req ExternalOperationRequest) (op *ExternalOperation, replayed bool, err error) {
+	wallet, err := s.repo.FindByNimNip(req.NimNip)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Canteen sales are spending like marketplace purchases; credits are issuance like top-ups
+	txnType := "topup"
+	if req.Direction == "debit" {
+		txnType = "marketplace"
+	}
+	description := fmt.Sprintf("%s (via %s)", req.Description, req.Client)
+
+	err = utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		// Operations on a wallet run one at a time: a debit checks the balance
+		// on the locked row, and balance_after is the balance this one left
+		if _, err := s.repo.LockByID(tx, wallet.WalletID); err != nil {
+			return err
+		}
+
+		op = &ExternalOperation{
+			Client:      req.Client,
+			Reference:   req.Reference,
+			WalletID:    wallet.WalletID,
+			Direction:   req.Direction,
+			Amount:      req.Amount,
+			Description: req.Description,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(op)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			replayed = true
+			if err := tx.Where("client = ? AND reference = ?", req.Client, req.Reference).First(op).Error; err != nil {
+				return err
+			}
+			if op.WalletID != wallet.WalletID || op.Direction != req.Direction || op.Amount != req.Amount {
+				return ErrExternalReferenceReused
+			}
+			return nil
+		}
+		replayed = false
+
+		apply := s.CreditWithTransaction
+		if req.Direction == "debit" {
+			apply = s.DebitWithTransaction
+		}
+		if err := apply(tx, wallet.WalletID, req.Amount, txnType, description); err != nil {
+			return err
+		}
+
+		var updated Wallet
+		if err := tx.Select("balance").First(&updated, wallet.WalletID).Error; err != nil {
+			return err
+		}
+		op.BalanceAfter = updated.Balance
+		return tx.Model(op).Update("balance_after", updated.Balance).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return op, replayed, nil
+}
@@ -1,9 +1,10 @@
 package wallet
 
 import (
-	"math"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 	"wallet-point/internal/audit"
 	"wallet-point/utils"
 
@@ -12,10 +13,10 @@ import (
 
 type WalletHandler struct {
 	service      *WalletService
-	auditService *audit.AuditService
+	auditService audit.Logger
 }
 
-func NewWalletHandler(service *WalletService, auditService *audit.AuditService) *WalletHandler {
+func NewWalletHandler(service *WalletService, auditService audit.Logger) *WalletHandler {
 	return &WalletHandler{service: service, auditService: auditService}
 }
 
@@ -30,7 +31,7 @@ func NewWalletHandler(service *WalletService, auditService *audit.AuditService)
 // @Failure 403 {object} utils.Response
 // @Router /admin/wallets [get]
 func (h *WalletHandler) GetAllWallets(c *gin.Context) {
-	wallets, err := h.service.GetAllWallets()
+	wallets, err := h.service.GetAllWallets(c.Request.Context())
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve wallets", err.Error())
 		return
@@ -56,9 +57,14 @@ func (h *WalletHandler) GetWalletByID(c *gin.Context) {
 		return
 	}
 
+	if err := h.service.CheckWalletTenant(c.Request.Context(), uint(walletID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusNotFound, err)
+		return
+	}
+
 	wallet, err := h.service.GetWalletByID(uint(walletID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusNotFound, err)
 		return
 	}
 
@@ -81,16 +87,17 @@ func (h *WalletHandler) AdjustPoints(c *gin.Context) {
 
 	var req AdjustmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.service.CheckWalletTenant(c.Request.Context(), req.WalletID); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := h.service.AdjustPoints(&req, adminID); err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "wallet not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -117,23 +124,24 @@ func (h *WalletHandler) AdjustPoints(c *gin.Context) {
 // @Produce json
 // @Param request body ResetWalletRequest true "Reset details"
 // @Success 200 {object} utils.Response
-// @Failure 400 {object} utils. Response
+// @Failure 400 {object} utils.Response
 // @Router /admin/wallet/reset [post]
 func (h *WalletHandler) ResetWallet(c *gin.Context) {
 	adminID := c.GetUint("user_id")
 
 	var req ResetWalletRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.service.CheckWalletTenant(c.Request.Context(), req.WalletID); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := h.service.ResetWallet(&req, adminID); err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "wallet not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -151,6 +159,36 @@ func (h *WalletHandler) ResetWallet(c *gin.Context) {
 	})
 }
 
+// ReconcileBalances handles starting a balance reconciliation
+// @Summary Reconcile wallet balances
+// @Description Queue a background job that compares every wallet balance with the sum of its ledger. Poll GET /admin/jobs/{id} for the mismatches (Admin only).
+// @Tags Admin - Wallets
+// @Security BearerAuth
+// @Produce json
+// @Success 202 {object} utils.Response{data=jobs.Job}
+// @Router /admin/wallet/reconcile [post]
+func (h *WalletHandler) ReconcileBalances(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	job, err := h.service.StartReconciliation(adminID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to queue reconciliation", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Reconciliation queued", job)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "RECONCILE_WALLETS",
+		Entity:    "JOB",
+		EntityID:  job.ID,
+		Details:   fmt.Sprintf("Admin queued wallet balance reconciliation job #%d", job.ID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
 // GetAllTransactions handles getting all transactions
 // @Summary Get all transactions
 // @Description Get list of all transactions with filters (Admin only)
@@ -162,42 +200,60 @@ func (h *WalletHandler) ResetWallet(c *gin.Context) {
 // @Param direction query string false "Filter by direction"
 // @Param from_date query string false "Filter from date (YYYY-MM-DD)"
 // @Param to_date query string false "Filter to date (YYYY-MM-DD)"
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(20)
+// @Param fields query string false "Comma-separated transaction fields to return, e.g. id,type,amount,direction,created_at (default all)"
+// @Param cursor query string false "Opaque cursor from next_cursor; takes precedence over page"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
 // @Success 200 {object} utils.Response{data=TransactionListResponse}
 // @Failure 401 {object} utils.Response
 // @Router /admin/transactions [get]
 func (h *WalletHandler) GetAllTransactions(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "transaction_status")
+	if !ok {
+		return
+	}
+	direction, ok := utils.QueryEnum(c, "direction", "direction")
+	if !ok {
+		return
+	}
+	fields, ok := utils.FieldsQuery(c, TransactionFields)
+	if !ok {
+		return
+	}
+	cursor, err := utils.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cursor", nil)
+		return
+	}
 
 	params := TransactionListParams{
 		Type:      c.Query("type"),
-		Status:    c.Query("status"),
-		Direction: c.Query("direction"),
+		Status:    status,
+		Direction: direction,
 		FromDate:  c.Query("from_date"),
 		ToDate:    c.Query("to_date"),
+		Fields:    fields,
+		Cursor:    cursor,
 		Page:      page,
 		Limit:     limit,
 	}
 
-	transactions, total, err := h.service.GetAllTransactions(params)
+	transactions, total, err := h.service.GetAllTransactions(c.Request.Context(), params)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve transactions", err.Error())
 		return
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(limit)))
-
-	response := TransactionListResponse{
-		Transactions: transactions,
-		Total:        total,
-		Page:         page,
-		Limit:        limit,
-		TotalPages:   totalPages,
-	}
+	nextCursor := utils.NextCursor(transactions, limit, func(t TransactionWithDetails) (time.Time, uint) {
+		return t.CreatedAt, t.ID
+	})
 
-	utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", response)
+	utils.ListResponse(c, "Transactions retrieved successfully", "transactions", fields.Filter(transactions),
+		utils.NewPagination(page, limit, total, nextCursor), nil)
 }
 
 // GetWalletTransactions handles getting transactions for specific wallet
@@ -207,7 +263,8 @@ func (h *WalletHandler) GetAllTransactions(c *gin.Context) {
 // @Security BearerAuth
 // @Produce json
 // @Param id path int true "Wallet ID"
-// @Param limit query int false "Number of transactions" default(50)
+// @Param limit query int false "Number of transactions" default(50) minimum(1) maximum(100)
+// @Param fields query string false "Comma-separated transaction fields to return, e.g. id,type,amount,created_at (default all)"
 // @Success 200 {object} utils.Response{data=[]WalletTransaction}
 // @Failure 404 {object} utils.Response
 // @Router /admin/wallets/{id}/transactions [get]
@@ -218,19 +275,27 @@ func (h *WalletHandler) GetWalletTransactions(c *gin.Context) {
 		return
 	}
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	limit, ok := utils.LimitQuery(c, 50)
+	if !ok {
+		return
+	}
+	fields, ok := utils.FieldsQuery(c, WalletTransactionFields)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CheckWalletTenant(c.Request.Context(), uint(walletID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusNotFound, err)
+		return
+	}
 
-	transactions, err := h.service.GetWalletTransactions(uint(walletID), limit)
+	transactions, err := h.service.GetWalletTransactions(uint(walletID), limit, fields)
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "wallet not found" {
-			statusCode = http.StatusNotFound
-		}
-		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions)
+	utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", fields.Filter(transactions))
 }
 
 // GetLeaderboard handles getting leaderboard
@@ -239,13 +304,16 @@ func (h *WalletHandler) GetWalletTransactions(c *gin.Context) {
 // @Tags Wallet
 // @Security BearerAuth
 // @Produce json
-// @Param limit query int false "Limit" default(10)
+// @Param limit query int false "Limit" default(10) minimum(1) maximum(100)
 // @Success 200 {object} utils.Response{data=[]WalletWithUser}
 // @Router /wallets/leaderboard [get]
 func (h *WalletHandler) GetLeaderboard(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	limit, ok := utils.LimitQuery(c, 10)
+	if !ok {
+		return
+	}
 
-	leaderboard, err := h.service.GetLeaderboard(limit)
+	leaderboard, err := h.service.GetLeaderboard(c.Request.Context(), limit)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve leaderboard", err.Error())
 		return
@@ -280,12 +348,20 @@ func (h *WalletHandler) GetMyWallet(c *gin.Context) {
 // @Tags Wallet
 // @Security BearerAuth
 // @Produce json
-// @Param limit query int false "Limit" default(50)
+// @Param limit query int false "Limit" default(50) minimum(1) maximum(100)
+// @Param fields query string false "Comma-separated transaction fields to return, e.g. id,type,amount,direction,created_at (default all)"
 // @Success 200 {object} utils.Response{data=[]WalletTransaction}
 // @Router /mahasiswa/transactions [get]
 func (h *WalletHandler) GetMyTransactions(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	limit, ok := utils.LimitQuery(c, 50)
+	if !ok {
+		return
+	}
+	fields, ok := utils.FieldsQuery(c, WalletTransactionFields)
+	if !ok {
+		return
+	}
 
 	// Find wallet first
 	wallet, err := h.service.GetWalletByUserID(userID)
@@ -294,14 +370,14 @@ func (h *WalletHandler) GetMyTransactions(c *gin.Context) {
 		return
 	}
 
-	transactions, err := h.service.GetWalletTransactions(wallet.ID, limit)
+	transactions, err := h.service.GetWalletTransactions(wallet.ID, limit, fields)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve transactions", err.Error())
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", map[string]interface{}{
-		"transactions": transactions,
+		"transactions": fields.Filter(transactions),
 	})
 }
 
@@ -320,13 +396,13 @@ func (h *WalletHandler) GeneratePaymentToken(c *gin.Context) {
 
 	var req PaymentTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	token, err := h.service.GeneratePaymentToken(req, userID, req.RecipientID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -334,12 +410,20 @@ func (h *WalletHandler) GeneratePaymentToken(c *gin.Context) {
 }
 
 // CheckTokenStatus returns payment details for a token
+// @Summary Check payment token
+// @Description Get payment details of a QR token before paying (Public)
+// @Tags Wallet
+// @Produce json
+// @Param token path string true "Payment token"
+// @Success 200 {object} utils.Response{data=PaymentToken}
+// @Failure 404 {object} utils.Response
+// @Router /payment/status/{token} [get]
 func (h *WalletHandler) CheckTokenStatus(c *gin.Context) {
 	tokenCode := c.Param("token")
 
 	token, err := h.service.GetTokenDetails(tokenCode)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Token tidak valid atau sudah kadaluarsa", err.Error())
+		utils.ErrorResponse(c, http.StatusNotFound, "Invalid or expired payment token", err.Error())
 		return
 	}
 
@@ -347,30 +431,42 @@ func (h *WalletHandler) CheckTokenStatus(c *gin.Context) {
 }
 
 // ExecuteStudentPayment allows a student to pay for a scanned token
+// @Summary Pay payment token
+// @Description Pay a scanned QR token with wallet points (Mahasiswa only)
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PaymentExecuteRequest true "Token and PIN"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/payment/execute [post]
 func (h *WalletHandler) ExecuteStudentPayment(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	var req struct {
-		Token string `json:"token" binding:"required"`
-		PIN   string `json:"pin" binding:"required"`
-	}
-
+	var req PaymentExecuteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	err := h.service.StudentPayToken(req.Token, userID, req.PIN)
+	err := h.service.StudentPayToken(c.Request.Context(), req.Token, userID, req.PIN)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		utils.ServiceErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Pembayaran berhasil!", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Payment successful", nil)
 }
 
 // GetAdminStats handles retrieving administrative dashboard statistics
+// @Summary Get admin dashboard stats
+// @Tags Admin - Monitoring
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=AdminStats}
+// @Router /admin/stats [get]
 func (h *WalletHandler) GetAdminStats(c *gin.Context) {
-	stats, err := h.service.GetAdminStats()
+	stats, err := h.service.GetAdminStats(c.Request.Context())
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Error fetching admin stats", err.Error())
 		return
@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"time"
+	"wallet-point/utils"
 )
 
 type Wallet struct {
@@ -17,16 +18,19 @@ func (Wallet) TableName() string {
 }
 
 type WalletTransaction struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	WalletID    uint      `json:"wallet_id" gorm:"not null"`
-	Type        string    `json:"type" gorm:"type:enum('mission','transfer_in','transfer_out','marketplace','adjustment','topup');not null"`
-	Amount      int       `json:"amount" gorm:"not null"`
-	Direction   string    `json:"direction" gorm:"type:enum('credit','debit');not null"`
-	ReferenceID *uint     `json:"reference_id"`
-	Status      string    `json:"status" gorm:"type:enum('success','failed','pending');default:'success'"`
-	Description string    `json:"description" gorm:"size:500"`
-	CreatedBy   string    `json:"created_by" gorm:"type:enum('system','admin','dosen');default:'system'"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	WalletID    uint   `json:"wallet_id" gorm:"not null"`
+	Type        string `json:"type" gorm:"type:enum('mission','transfer_in','transfer_out','marketplace','adjustment','topup');not null"`
+	Amount      int    `json:"amount" gorm:"not null"`
+	Direction   string `json:"direction" gorm:"type:enum('credit','debit');not null"`
+	ReferenceID *uint  `json:"reference_id"`
+	Status      string `json:"status" gorm:"type:enum('success','failed','pending');default:'success'"`
+	Description string `json:"description" gorm:"size:500"`
+	CreatedBy   string `json:"created_by" gorm:"type:enum('system','admin','dosen');default:'system'"`
+	// RefundOf is the debit a refund credit gives points back of; point expiry
+	// counts those points as never spent, see RefundWithTransaction
+	RefundOf  *uint     `json:"refund_of,omitempty" gorm:"index"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"` // partitioning column, see internal/partition
 }
 
 func (WalletTransaction) TableName() string {
@@ -43,6 +47,14 @@ type WalletWithUser struct {
 	Balance  int    `json:"balance"`
 }
 
+// Student is an active student and their wallet, as looked up by the NIM a
+// campus system sends
+type Student struct {
+	UserID   uint
+	TenantID uint
+	WalletID uint
+}
+
 type TransactionWithDetails struct {
 	ID          uint      `json:"id"`
 	WalletID    uint      `json:"wallet_id"`
@@ -56,13 +68,14 @@ type TransactionWithDetails struct {
 	Status      string    `json:"status"`
 	Description string    `json:"description"`
 	CreatedBy   string    `json:"created_by"`
+	RefundOf    *uint     `json:"refund_of,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
 type AdjustmentRequest struct {
 	WalletID    uint   `json:"wallet_id" binding:"required"`
-	Amount      int    `json:"amount" binding:"required,gt=0"`
-	Direction   string `json:"direction" binding:"required,oneof=credit debit"`
+	Amount      int    `json:"amount" binding:"required,points"`
+	Direction   string `json:"direction" binding:"required,direction" enums:"credit,debit"`
 	Description string `json:"description" binding:"required"`
 }
 
@@ -78,16 +91,15 @@ type TransactionListParams struct {
 	Direction string
 	FromDate  string
 	ToDate    string
+	Fields    utils.Fields
+	Cursor    *utils.Cursor
 	Page      int
 	Limit     int
 }
 
 type TransactionListResponse struct {
 	Transactions []TransactionWithDetails `json:"transactions"`
-	Total        int64                    `json:"total"`
-	Page         int                      `json:"page"`
-	Limit        int                      `json:"limit"`
-	TotalPages   int                      `json:"total_pages"`
+	utils.Pagination
 }
 
 type AdminStats struct {
@@ -103,3 +115,82 @@ type AdminStats struct {
 	TotalMissions     int64 `json:"total_missions"`
 	PendingSubmission int64 `json:"pending_submissions"`
 }
+
+// BalanceMismatch is a wallet whose stored balance differs from the sum of its ledger
+type BalanceMismatch struct {
+	WalletID      uint  `json:"wallet_id"`
+	UserID        uint  `json:"user_id"`
+	Balance       int64 `json:"balance"`
+	LedgerBalance int64 `json:"ledger_balance"`
+	Difference    int64 `json:"difference"`
+}
+
+// ReconciliationResult is stored as the result of a balance reconciliation job
+type ReconciliationResult struct {
+	WalletsChecked int64             `json:"wallets_checked"`
+	Mismatches     []BalanceMismatch `json:"mismatches"`
+}
+
+// BalanceSnapshot is a wallet's balance at the end of a day, kept for historical reporting
+type BalanceSnapshot struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	SnapshotDate time.Time `json:"snapshot_date" gorm:"type:date;not null;uniqueIndex:idx_balance_snapshots_day"`
+	WalletID     uint      `json:"wallet_id" gorm:"not null;uniqueIndex:idx_balance_snapshots_day"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	Balance      int       `json:"balance" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (BalanceSnapshot) TableName() string {
+	return "wallet_balance_snapshots"
+}
+
+// PointTotals sums the ledger of a wallet for point expiry. Refunds are not
+// earned points: they take back that much of the debits they are linked to.
+type PointTotals struct {
+	WalletID     uint
+	Balance      int
+	EarnedBefore int // credited before the cutoff, refunds excluded
+	Debited      int
+	Refunded     int
+}
+
+// Expirable is what debits, using up the oldest points first, left of the
+// points earned before the cutoff, capped at the balance
+func (p PointTotals) Expirable() int {
+	return max(min(p.Balance, p.EarnedBefore-(p.Debited-p.Refunded)), 0)
+}
+
+// ExpirablePoints is the part of a wallet's balance earned before the expiry cutoff and not yet spent
+type ExpirablePoints struct {
+	WalletID uint
+	Amount   int
+}
+
+// ExternalOperation records a credit or debit requested by a campus system over gRPC.
+// (Client, Reference) is unique so a retried request is replayed instead of applied twice.
+type ExternalOperation struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Client       string    `json:"client" gorm:"size:100;not null;uniqueIndex:idx_external_operations_ref"`
+	Reference    string    `json:"reference" gorm:"size:100;not null;uniqueIndex:idx_external_operations_ref"`
+	WalletID     uint      `json:"wallet_id" gorm:"not null;index"`
+	Direction    string    `json:"direction" gorm:"type:enum('credit','debit');not null"`
+	Amount       int       `json:"amount" gorm:"not null"`
+	BalanceAfter int       `json:"balance_after" gorm:"not null"`
+	Description  string    `json:"description" gorm:"size:500"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (ExternalOperation) TableName() string {
+	return "wallet_external_operations"
+}
+
+// ExternalOperationRequest is a credit or debit of the wallet of NimNip on behalf of Client
+type ExternalOperationRequest struct {
+	Client      string
+	NimNip      string
+	Reference   string
+	Direction   string
+	Amount      int
+	Description string
+}
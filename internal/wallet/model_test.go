@@ -0,0 +1,112 @@
+package wallet
+
+import "testing"
+
+func TestPointTotalsExpirable(t *testing.T) {
+	// A ledger entry as FindExpirablePoints sums it; refundOf is the index of
+	// the debit a refund links to, -1 for none
+	type entry struct {
+		direction string
+		amount    int
+		before    bool // created before the cutoff
+		refundOf  int
+	}
+	credit := func(amount int, before bool) entry { return entry{"credit", amount, before, -1} }
+	debit := func(amount int) entry { return entry{"debit", amount, false, -1} }
+	refund := func(amount, debit int) entry { return entry{"credit", amount, false, debit} }
+
+	tests := []struct {
+		name    string
+		ledger  []entry
+		balance int // when set, the balance after a change outside the ledger
+		want    int
+	}{
+		{
+			name:   "nothing spent",
+			ledger: []entry{credit(100, true), credit(100, false)},
+			want:   100,
+		},
+		{
+			name:   "debits use up the oldest points first",
+			ledger: []entry{credit(100, true), credit(100, false), debit(150)},
+			want:   0,
+		},
+		{
+			name:   "refund of the whole debit gives the old points back",
+			ledger: []entry{credit(100, true), credit(100, false), debit(150), refund(150, 2)},
+			want:   100,
+		},
+		{
+			name:   "partial refund",
+			ledger: []entry{credit(100, true), credit(100, false), debit(150), refund(60, 2)},
+			want:   10,
+		},
+		{
+			name: "void of an earlier debit after later purchases",
+			ledger: []entry{
+				credit(100, true), credit(100, false),
+				debit(50),  // POS sale, later voided
+				debit(100), // purchase
+				refund(50, 2),
+			},
+			want: 0,
+		},
+		{
+			name: "void of an earlier debit with old points left",
+			ledger: []entry{
+				credit(100, true), credit(100, false),
+				debit(80), // POS sale, later voided
+				debit(30),
+				refund(80, 2),
+			},
+			want: 70,
+		},
+		{
+			name:   "unlinked refund counts as newly earned",
+			ledger: []entry{credit(100, true), debit(100), credit(100, false)},
+			want:   0,
+		},
+		{
+			name:   "expiry debits count as spending",
+			ledger: []entry{credit(100, true), debit(100)},
+			want:   0,
+		},
+		{
+			name:    "capped at the balance",
+			ledger:  []entry{credit(100, true)},
+			balance: 40,
+			want:    40,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totals := PointTotals{}
+			for _, e := range tt.ledger {
+				switch {
+				case e.direction == "debit":
+					totals.Debited += e.amount
+					totals.Balance -= e.amount
+				case e.refundOf >= 0:
+					if tt.ledger[e.refundOf].direction != "debit" {
+						t.Fatalf("refund links to a credit")
+					}
+					totals.Refunded += e.amount
+					totals.Balance += e.amount
+				default:
+					if e.before {
+						totals.EarnedBefore += e.amount
+					}
+					totals.Balance += e.amount
+				}
+			}
+			if tt.balance != 0 {
+				totals.Balance = tt.balance
+			}
+
+			if got := totals.Expirable(); got != tt.want {
+				t.Errorf("Expirable() = %d, want %d (totals %+v)", got, tt.want, totals)
+			}
+		})
+	}
+}
@@ -22,9 +22,14 @@ func (PaymentToken) TableName() string {
 }
 
 type PaymentTokenRequest struct {
-	Amount      int    `json:"amount" binding:"required,gt=0"`
+	Amount      int    `json:"amount" binding:"required,points"`
 	Merchant    string `json:"merchant"` // Optional display name
 	Type        string `json:"type" binding:"required,oneof=purchase transfer"`
 	RecipientID uint   `json:"recipient_id"`
 	ProductID   uint   `json:"product_id"`
 }
+
+type PaymentExecuteRequest struct {
+	Token string `json:"token" binding:"required"`
+	PIN   string `json:"pin" binding:"required"`
+}
@@ -0,0 +1,60 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"wallet-point/internal/alert"
+	"wallet-point/internal/jobs"
+)
+
+const JobReconcileBalances = "wallet.reconcile_balances"
+
+// SetJobQueue registers the wallet background jobs on queue
+func (s *WalletService) SetJobQueue(queue *jobs.Queue) {
+	s.jobQueue = queue
+	queue.Register(JobReconcileBalances, jobs.QueueReconciliation, s.reconcileBalances)
+}
+
+// SetAlerts reports reconciliation mismatches to the operations channel
+func (s *WalletService) SetAlerts(alerts *alert.Service) {
+	s.alerts = alerts
+}
+
+// StartReconciliation queues a check of every wallet balance against its ledger (Admin)
+func (s *WalletService) StartReconciliation(adminID uint) (*jobs.Job, error) {
+	return s.jobQueue.Enqueue(JobReconcileBalances, struct{}{}, jobs.EnqueueOptions{CreatedBy: &adminID})
+}
+
+// reconcileBalances only reports mismatches; correcting a balance stays a
+// deliberate admin adjustment
+func (s *WalletService) reconcileBalances(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	mismatches, total, err := s.repo.FindBalanceMismatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mismatches) > 0 {
+		slog.WarnContext(ctx, "wallet reconciliation found mismatches", "job_id", job.ID, "wallets", total, "mismatches", len(mismatches))
+		if s.alerts != nil {
+			var difference int64
+			for _, mismatch := range mismatches {
+				difference += mismatch.Difference
+			}
+			s.alerts.Send(alert.Alert{
+				Key:      "wallet.reconciliation",
+				Severity: alert.SeverityCritical,
+				Title:    "Wallet balances do not match their ledger",
+				Text:     fmt.Sprintf("%d of %d wallets differ; see the result of job #%d", len(mismatches), total, job.ID),
+				Fields: map[string]string{
+					"first wallet":     fmt.Sprintf("#%d (user %d)", mismatches[0].WalletID, mismatches[0].UserID),
+					"total difference": fmt.Sprintf("%d points", difference),
+				},
+			})
+		}
+	}
+	if mismatches == nil {
+		mismatches = []BalanceMismatch{}
+	}
+	return ReconciliationResult{WalletsChecked: total, Mismatches: mismatches}, nil
+}
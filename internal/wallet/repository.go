@@ -1,17 +1,86 @@
 package wallet
 
 import (
+	"context"
 	"errors"
+	"time"
+	"wallet-point/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Repository is the wallet and ledger storage WalletService depends on.
+// WalletRepository is the GORM implementation; tests and decorators can provide their own.
+type Repository interface {
+	FindByID(walletID uint) (*Wallet, error)
+	LockByID(tx *gorm.DB, walletID uint) (*Wallet, error)
+	FindByUserID(userID uint) (*Wallet, error)
+	FindByNimNip(nimNip string) (*WalletWithUser, error)
+	GetAllWithUsers(ctx context.Context) ([]WalletWithUser, error)
+	GetLeaderboard(ctx context.Context, limit int) ([]WalletWithUser, error)
+
+	CreateTransaction(tx *gorm.DB, transaction *WalletTransaction) error
+	UpdateBalance(tx *gorm.DB, walletID uint, delta int) error
+	SetBalance(tx *gorm.DB, walletID uint, newBalance int) error
+	GetTransactions(ctx context.Context, params TransactionListParams) ([]TransactionWithDetails, int64, error)
+	GetWalletTransactions(walletID uint, limit int, fields utils.Fields) ([]WalletTransaction, error)
+
+	FindBalanceMismatches(ctx context.Context) ([]BalanceMismatch, int64, error)
+	FindExpirablePoints(ctx context.Context, cutoff time.Time) ([]ExpirablePoints, error)
+	FindDebit(tx *gorm.DB, walletID, debitID uint) (*WalletTransaction, int, error)
+	SnapshotBalances(ctx context.Context, day time.Time) error
+}
+
+var _ Repository = (*WalletRepository)(nil)
+
+// TransactionDetailColumns selects a TransactionWithDetails from wallet_transactions
+// joined with wallets and users
+var TransactionDetailColumns = []string{
+	"wallet_transactions.id", "wallet_transactions.wallet_id", "wallet_transactions.type",
+	"wallet_transactions.amount", "wallet_transactions.direction", "wallet_transactions.reference_id",
+	"wallet_transactions.status", "wallet_transactions.description", "wallet_transactions.created_by",
+	"wallet_transactions.refund_of", "wallet_transactions.created_at",
+	"users.email AS user_email", "users.full_name AS user_name", "users.nim_nip",
+}
+
+// TransactionFields are the fields a transaction listing can be narrowed to with fields=
+var TransactionFields = utils.FieldColumns{
+	"id": "wallet_transactions.id", "wallet_id": "wallet_transactions.wallet_id", "type": "wallet_transactions.type",
+	"amount": "wallet_transactions.amount", "direction": "wallet_transactions.direction",
+	"reference_id": "wallet_transactions.reference_id", "status": "wallet_transactions.status",
+	"description": "wallet_transactions.description", "created_by": "wallet_transactions.created_by",
+	"refund_of": "wallet_transactions.refund_of", "created_at": "wallet_transactions.created_at",
+	"user_email": "users.email AS user_email", "user_name": "users.full_name AS user_name", "nim_nip": "users.nim_nip",
+}
+
+// WalletTransactionFields are the fields of a wallet's own transaction history selectable with fields=
+var WalletTransactionFields = utils.FieldColumns{
+	"id": "id", "wallet_id": "wallet_id", "type": "type", "amount": "amount", "direction": "direction",
+	"reference_id": "reference_id", "status": "status", "description": "description",
+	"created_by": "created_by", "refund_of": "refund_of", "created_at": "created_at",
+}
+
+// ScopeTenantWallets restricts a query to rows whose column (e.g. "wallet_id")
+// references the wallet of a user of the campus of ctx
+func ScopeTenantWallets(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return utils.ScopeTenantOwner(ctx, column, "(SELECT wallets.id, users.tenant_id FROM wallets JOIN users ON users.id = wallets.user_id) AS tenant_wallets")
+}
+
 type WalletRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	replica *gorm.DB // admin wallet listing and leaderboard
+	counts  *utils.CountCache
 }
 
 func NewWalletRepository(db *gorm.DB) *WalletRepository {
-	return &WalletRepository{db: db}
+	return &WalletRepository{db: db, replica: utils.ReadReplica(db)}
+}
+
+// SetCountCache makes the transaction listing reuse recent totals instead of
+// counting the ledger on every page load; without it totals are exact
+func (r *WalletRepository) SetCountCache(counts *utils.CountCache) {
+	r.counts = counts
 }
 
 // FindByID finds wallet by ID
@@ -20,7 +89,21 @@ func (r *WalletRepository) FindByID(walletID uint) (*Wallet, error) {
 	err := r.db.First(&wallet, walletID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("wallet not found")
+			return nil, ErrWalletNotFound
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// LockByID loads a wallet for update within tx, so balance checks and the
+// debits that follow them run one at a time per wallet
+func (r *WalletRepository) LockByID(tx *gorm.DB, walletID uint) (*Wallet, error) {
+	var wallet Wallet
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&wallet, walletID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWalletNotFound
 		}
 		return nil, err
 	}
@@ -33,19 +116,53 @@ func (r *WalletRepository) FindByUserID(userID uint) (*Wallet, error) {
 	err := r.db.Where("user_id = ?", userID).First(&wallet).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("wallet not found")
+			return nil, ErrWalletNotFound
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// FindByNimNip finds the wallet of the active user with the given NIM/NIP
+func (r *WalletRepository) FindByNimNip(nimNip string) (*WalletWithUser, error) {
+	var wallet WalletWithUser
+	err := r.db.Table("wallets").
+		Select("wallets.id as wallet_id, users.id as user_id, users.email, users.full_name, users.nim_nip, users.role, wallets.balance").
+		Joins("INNER JOIN users ON wallets.user_id = users.id").
+		Where("users.nim_nip = ? AND users.status = ? AND users.deleted_at IS NULL", nimNip, "active").
+		Take(&wallet).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWalletNotFound
 		}
 		return nil, err
 	}
 	return &wallet, nil
 }
 
+// FindStudent looks up the active student with nim and their wallet within db,
+// or nil
+func FindStudent(db *gorm.DB, nim string) (*Student, error) {
+	var found Student
+	err := db.Table("users").
+		Select("users.id AS user_id, users.tenant_id, wallets.id AS wallet_id").
+		Joins("INNER JOIN wallets ON wallets.user_id = users.id").
+		Where("users.nim_nip = ? AND users.role = ? AND users.status = ? AND users.deleted_at IS NULL", nim, "mahasiswa", "active").
+		Take(&found).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &found, err
+}
+
 // GetAllWithUsers gets all wallets with user information
-func (r *WalletRepository) GetAllWithUsers() ([]WalletWithUser, error) {
+func (r *WalletRepository) GetAllWithUsers(ctx context.Context) ([]WalletWithUser, error) {
 	var wallets []WalletWithUser
-	err := r.db.Table("wallets").
+	err := r.replica.WithContext(ctx).Table("wallets").
 		Select("wallets.id as wallet_id, users.id as user_id, users.email, users.full_name, users.nim_nip, users.role, wallets.balance, wallets.last_sync_at").
 		Joins("INNER JOIN users ON wallets.user_id = users.id").
+		Where("users.deleted_at IS NULL").
+		Scopes(utils.ScopeTenant(ctx, "users.tenant_id")).
 		Order("wallets.balance DESC").
 		Scan(&wallets).Error
 	return wallets, err
@@ -82,14 +199,18 @@ func (r *WalletRepository) SetBalance(tx *gorm.DB, walletID uint, newBalance int
 }
 
 // GetTransactions gets transactions with filters and pagination
-func (r *WalletRepository) GetTransactions(params TransactionListParams) ([]TransactionWithDetails, int64, error) {
+func (r *WalletRepository) GetTransactions(ctx context.Context, params TransactionListParams) ([]TransactionWithDetails, int64, error) {
 	var transactions []TransactionWithDetails
-	var total int64
 
-	query := r.db.Table("wallet_transactions").
-		Select("wallet_transactions.*, users.email as user_email, users.full_name as user_name, users.nim_nip").
+	columns := TransactionDetailColumns
+	if selected := params.Fields.Select(TransactionFields, "id", "created_at"); selected != nil {
+		columns = selected
+	}
+	query := r.db.WithContext(ctx).Table("wallet_transactions").
+		Select(columns).
 		Joins("INNER JOIN wallets ON wallet_transactions.wallet_id = wallets.id").
-		Joins("INNER JOIN users ON wallets.user_id = users.id")
+		Joins("INNER JOIN users ON wallets.user_id = users.id").
+		Scopes(utils.ScopeTenant(ctx, "users.tenant_id"))
 
 	// Apply filters
 	if params.Type != "" {
@@ -109,13 +230,13 @@ func (r *WalletRepository) GetTransactions(params TransactionListParams) ([]Tran
 	}
 
 	// Count total
-	if err := query.Count(&total).Error; err != nil {
+	total, err := r.counts.Count(ctx, query)
+	if err != nil {
 		return nil, 0, err
 	}
 
 	// Apply pagination
-	offset := (params.Page - 1) * params.Limit
-	query = query.Limit(params.Limit).Offset(offset).Order("wallet_transactions.created_at DESC")
+	query = utils.Paginate(query, params.Cursor, "wallet_transactions.created_at", "wallet_transactions.id", params.Page, params.Limit)
 
 	if err := query.Scan(&transactions).Error; err != nil {
 		return nil, 0, err
@@ -124,26 +245,110 @@ func (r *WalletRepository) GetTransactions(params TransactionListParams) ([]Tran
 	return transactions, total, nil
 }
 
-// GetWalletTransactions gets transactions for specific wallet
-func (r *WalletRepository) GetWalletTransactions(walletID uint, limit int) ([]WalletTransaction, error) {
+// GetWalletTransactions gets transactions for specific wallet, all fields when fields is nil
+func (r *WalletRepository) GetWalletTransactions(walletID uint, limit int, fields utils.Fields) ([]WalletTransaction, error) {
 	var transactions []WalletTransaction
-	err := r.db.Where("wallet_id = ?", walletID).
+	query := r.db
+	if columns := fields.Select(WalletTransactionFields); columns != nil {
+		query = query.Select(columns)
+	}
+	err := query.Where("wallet_id = ?", walletID).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&transactions).Error
 	return transactions, err
 }
 
-// GetLeaderboard retrieves top wallets by balance
-func (r *WalletRepository) GetLeaderboard(limit int) ([]WalletWithUser, error) {
+// GetLeaderboard retrieves top wallets by balance, within the campus of ctx
+func (r *WalletRepository) GetLeaderboard(ctx context.Context, limit int) ([]WalletWithUser, error) {
 	var results []WalletWithUser
 	// Only fetch mahasiswa role for leaderboard
-	err := r.db.Table("wallets").
+	err := r.replica.WithContext(ctx).Table("wallets").
 		Select("users.full_name, users.nim_nip, wallets.balance").
 		Joins("INNER JOIN users ON wallets.user_id = users.id").
-		Where("users.role = 'mahasiswa'").
+		Where("users.role = 'mahasiswa' AND users.deleted_at IS NULL").
+		Scopes(utils.ScopeTenant(ctx, "users.tenant_id")).
 		Order("wallets.balance DESC").
 		Limit(limit).
 		Scan(&results).Error
 	return results, err
 }
+
+// FindBalanceMismatches compares every wallet balance with the sum of its successful
+// credits minus debits and returns the wallets where they differ
+func (r *WalletRepository) FindBalanceMismatches(ctx context.Context) ([]BalanceMismatch, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&Wallet{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var mismatches []BalanceMismatch
+	err := r.db.WithContext(ctx).Table("wallets").
+		Select(`wallets.id AS wallet_id, wallets.user_id, wallets.balance,
+			COALESCE(SUM(CASE WHEN wallet_transactions.direction = 'credit' THEN wallet_transactions.amount ELSE -wallet_transactions.amount END), 0) AS ledger_balance`).
+		Joins("LEFT JOIN wallet_transactions ON wallet_transactions.wallet_id = wallets.id AND wallet_transactions.status = 'success'").
+		Group("wallets.id, wallets.user_id, wallets.balance").
+		Having("wallets.balance <> ledger_balance").
+		Order("wallets.id").
+		Scan(&mismatches).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range mismatches {
+		mismatches[i].Difference = mismatches[i].Balance - mismatches[i].LedgerBalance
+	}
+	return mismatches, total, nil
+}
+
+// FindExpirablePoints returns, per wallet, the points earned before cutoff that later
+// debits have not used up yet, see PointTotals
+func (r *WalletRepository) FindExpirablePoints(ctx context.Context, cutoff time.Time) ([]ExpirablePoints, error) {
+	var totals []PointTotals
+	err := r.db.WithContext(ctx).Table("wallets").
+		Select(`wallets.id AS wallet_id, wallets.balance,
+			COALESCE(SUM(CASE WHEN wallet_transactions.direction = 'credit' AND wallet_transactions.refund_of IS NULL AND wallet_transactions.created_at < ? THEN wallet_transactions.amount ELSE 0 END), 0) AS earned_before,
+			COALESCE(SUM(CASE WHEN wallet_transactions.direction = 'debit' THEN wallet_transactions.amount ELSE 0 END), 0) AS debited,
+			COALESCE(SUM(CASE WHEN wallet_transactions.direction = 'credit' AND wallet_transactions.refund_of IS NOT NULL THEN wallet_transactions.amount ELSE 0 END), 0) AS refunded`, cutoff).
+		Joins("JOIN wallet_transactions ON wallet_transactions.wallet_id = wallets.id AND wallet_transactions.status = 'success'").
+		Group("wallets.id, wallets.balance").
+		Order("wallets.id").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var points []ExpirablePoints
+	for _, total := range totals {
+		if amount := total.Expirable(); amount > 0 {
+			points = append(points, ExpirablePoints{WalletID: total.WalletID, Amount: amount})
+		}
+	}
+	return points, nil
+}
+
+// FindDebit returns a successful debit of a wallet with the points refunded of it so far
+func (r *WalletRepository) FindDebit(tx *gorm.DB, walletID, debitID uint) (*WalletTransaction, int, error) {
+	var debit WalletTransaction
+	err := tx.Where("id = ? AND wallet_id = ? AND direction = ? AND status = ?", debitID, walletID, "debit", "success").First(&debit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, ErrDebitNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var refunded int
+	err = tx.Model(&WalletTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("refund_of = ? AND status = ?", debitID, "success").
+		Scan(&refunded).Error
+	return &debit, refunded, err
+}
+
+// SnapshotBalances records every wallet's current balance for day, overwriting an earlier snapshot of the same day
+func (r *WalletRepository) SnapshotBalances(ctx context.Context, day time.Time) error {
+	return r.db.WithContext(ctx).Exec(`INSERT INTO wallet_balance_snapshots (snapshot_date, wallet_id, user_id, balance, created_at)
+		SELECT ?, id, user_id, balance, NOW() FROM wallets
+		ON DUPLICATE KEY UPDATE balance = VALUES(balance), created_at = VALUES(created_at)`, day.Format("2006-01-02")).Error
+}
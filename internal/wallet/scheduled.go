@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExpirePoints debits the points earned more than months ago that were never spent.
+// Earlier expiry debits count as spending, so running it again expires nothing twice.
+func (s *WalletService) ExpirePoints(ctx context.Context, months int) (string, error) {
+	cutoff := time.Now().AddDate(0, -months, 0)
+
+	points, err := s.repo.FindExpirablePoints(ctx, cutoff)
+	if err != nil {
+		return "", err
+	}
+
+	expired, failed := 0, 0
+	for _, p := range points {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		description := fmt.Sprintf("Point expiry: points earned before %s", cutoff.Format("2006-01-02"))
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return s.DebitWithTransaction(tx, p.WalletID, p.Amount, "adjustment", description)
+		})
+		if err != nil {
+			failed++
+			slog.ErrorContext(ctx, "point expiry failed", "wallet_id", p.WalletID, "amount", p.Amount, "error", err)
+			continue
+		}
+		expired += p.Amount
+	}
+
+	output := fmt.Sprintf("expired %d points from %d wallets", expired, len(points)-failed)
+	if failed > 0 {
+		return output, fmt.Errorf("%d wallets could not be expired", failed)
+	}
+	return output, nil
+}
+
+// SnapshotBalances stores today's balance of every wallet
+func (s *WalletService) SnapshotBalances(ctx context.Context) (string, error) {
+	today := time.Now()
+	if err := s.repo.SnapshotBalances(ctx, today); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("balances snapshotted for %s", today.Format("2006-01-02")), nil
+}
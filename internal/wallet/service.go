@@ -1,32 +1,49 @@
 package wallet
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
-	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"time"
 
+	"wallet-point/internal/alert"
 	"wallet-point/internal/auth"
+	"wallet-point/internal/events"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/metrics"
+	"wallet-point/internal/tracing"
+	"wallet-point/utils"
 
 	"github.com/skip2/go-qrcode"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
 type WalletService struct {
-	repo        *WalletRepository
-	db          *gorm.DB
-	authService *auth.AuthService
+	repo          Repository
+	db            *gorm.DB
+	authService   *auth.AuthService
+	onStockChange func(productID uint)
+	jobQueue      *jobs.Queue
+	outbox        *events.Outbox
+	alerts        *alert.Service
 }
 
 func (s *WalletService) SetAuthService(authService *auth.AuthService) {
 	s.authService = authService
 }
 
-func NewWalletService(repo *WalletRepository, db *gorm.DB) *WalletService {
+// OnProductStockChange registers a callback run after a QR purchase changed a product's stock
+// (the marketplace uses it to invalidate its product cache)
+func (s *WalletService) OnProductStockChange(callback func(productID uint)) {
+	s.onStockChange = callback
+}
+
+func NewWalletService(repo Repository, db *gorm.DB) *WalletService {
 	return &WalletService{
 		repo: repo,
 		db:   db,
@@ -38,13 +55,43 @@ func (s *WalletService) GetWalletByUserID(userID uint) (*Wallet, error) {
 	return s.repo.FindByUserID(userID)
 }
 
+// CheckWalletTenant fails with ErrWalletNotFound unless the wallet belongs to a user
+// of the campus of ctx. Admin handlers call it before working on a wallet by ID.
+func (s *WalletService) CheckWalletTenant(ctx context.Context, walletID uint) error {
+	if _, scoped := utils.TenantFromContext(ctx); !scoped {
+		return nil
+	}
+
+	var count int64
+	err := s.db.WithContext(ctx).Table("wallets").
+		Joins("JOIN users ON users.id = wallets.user_id").
+		Where("wallets.id = ?", walletID).
+		Scopes(utils.ScopeTenant(ctx, "users.tenant_id")).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrWalletNotFound
+	}
+	return nil
+}
+
 // GetWalletByID finds wallet by ID
 func (s *WalletService) GetWalletByID(walletID uint) (*Wallet, error) {
 	return s.repo.FindByID(walletID)
 }
 
+// LockWallet loads a wallet for update within tx, for checks that must hold
+// until the transaction commits
+func (s *WalletService) LockWallet(tx *gorm.DB, walletID uint) (*Wallet, error) {
+	return s.repo.LockByID(tx, walletID)
+}
+
 // AdjustPoints adds or subtracts points from a wallet
-func (s *WalletService) AdjustPoints(req *AdjustmentRequest, adminID uint) error {
+func (s *WalletService) AdjustPoints(req *AdjustmentRequest, adminID uint) (err error) {
+	defer func() { metrics.ObserveWalletOperation("adjust", err) }()
+
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		delta := req.Amount
 		if req.Direction == "debit" {
@@ -58,7 +105,7 @@ func (s *WalletService) AdjustPoints(req *AdjustmentRequest, adminID uint) error
 				return err
 			}
 			if wallet.Balance < req.Amount {
-				return errors.New("insufficient balance")
+				return ErrInsufficientBalance
 			}
 		}
 
@@ -77,12 +124,14 @@ func (s *WalletService) AdjustPoints(req *AdjustmentRequest, adminID uint) error
 			CreatedBy:   "admin",
 		}
 
-		return s.repo.CreateTransaction(tx, txn)
+		return s.recordTransaction(tx, txn)
 	})
 }
 
 // ResetWallet resets a wallet to a specific balance
-func (s *WalletService) ResetWallet(req *ResetWalletRequest, adminID uint) error {
+func (s *WalletService) ResetWallet(req *ResetWalletRequest, adminID uint) (err error) {
+	defer func() { metrics.ObserveWalletOperation("reset", err) }()
+
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		wallet, err := s.repo.FindByID(req.WalletID)
 		if err != nil {
@@ -107,29 +156,29 @@ func (s *WalletService) ResetWallet(req *ResetWalletRequest, adminID uint) error
 			txn.Direction = "credit"
 		}
 
-		return s.repo.CreateTransaction(tx, txn)
+		return s.recordTransaction(tx, txn)
 	})
 }
 
-func (s *WalletService) GetTransactions(params TransactionListParams) ([]TransactionWithDetails, int64, error) {
-	return s.repo.GetTransactions(params)
+func (s *WalletService) GetTransactions(ctx context.Context, params TransactionListParams) ([]TransactionWithDetails, int64, error) {
+	return s.repo.GetTransactions(ctx, params)
 }
 
 // GetAllTransactions is an alias for GetTransactions with default params or specifically for admin
-func (s *WalletService) GetAllTransactions(params TransactionListParams) ([]TransactionWithDetails, int64, error) {
-	return s.repo.GetTransactions(params)
+func (s *WalletService) GetAllTransactions(ctx context.Context, params TransactionListParams) ([]TransactionWithDetails, int64, error) {
+	return s.repo.GetTransactions(ctx, params)
 }
 
-func (s *WalletService) GetWalletTransactions(walletID uint, limit int) ([]WalletTransaction, error) {
-	return s.repo.GetWalletTransactions(walletID, limit)
+func (s *WalletService) GetWalletTransactions(walletID uint, limit int, fields utils.Fields) ([]WalletTransaction, error) {
+	return s.repo.GetWalletTransactions(walletID, limit, fields)
 }
 
-func (s *WalletService) GetLeaderboard(limit int) ([]WalletWithUser, error) {
-	return s.repo.GetLeaderboard(limit)
+func (s *WalletService) GetLeaderboard(ctx context.Context, limit int) ([]WalletWithUser, error) {
+	return s.repo.GetLeaderboard(ctx, limit)
 }
 
-func (s *WalletService) GetAllWallets() ([]WalletWithUser, error) {
-	return s.repo.GetAllWithUsers()
+func (s *WalletService) GetAllWallets(ctx context.Context) ([]WalletWithUser, error) {
+	return s.repo.GetAllWithUsers(ctx)
 }
 
 // GeneratePaymentToken creates a secure token for QR payment
@@ -137,7 +186,7 @@ func (s *WalletService) GeneratePaymentToken(req PaymentTokenRequest, userID uin
 	// 1. Get creator's wallet
 	wallet, err := s.repo.FindByUserID(userID)
 	if err != nil {
-		return nil, errors.New("wallet not found")
+		return nil, ErrWalletNotFound
 	}
 
 	// Note: We don't check balance here because in most flows (Bill QR),
@@ -184,21 +233,21 @@ func (s *WalletService) ValidateAndConsumeToken(tokenCode string, userID uint, a
 	var token PaymentToken
 	err := s.db.Where("token = ? AND status = ?", tokenCode, "active").First(&token).Error
 	if err != nil {
-		return errors.New("invalid or expired QR token")
+		return ErrPaymentTokenInvalid
 	}
 
 	if time.Now().After(token.Expiry) {
 		s.db.Model(&token).Update("status", "expired")
-		return errors.New("QR token has expired")
+		return ErrPaymentTokenExpired
 	}
 
 	wallet, err := s.repo.FindByUserID(userID)
 	if err != nil || wallet.ID != token.WalletID {
-		return errors.New("token does not belong to this user")
+		return ErrPaymentTokenNotOwned
 	}
 
 	if token.Amount != amount {
-		return fmt.Errorf("token amount mismatch. Expected: %d, Found: %d", token.Amount, amount)
+		return fmt.Errorf("%w. Expected: %d, Found: %d", ErrPaymentTokenMismatch, token.Amount, amount)
 	}
 
 	return s.db.Model(&token).Update("status", "consumed").Error
@@ -209,7 +258,7 @@ func (s *WalletService) GetTokenDetails(tokenCode string) (*PaymentToken, error)
 	var token PaymentToken
 	err := s.db.Where("token = ?", tokenCode).First(&token).Error
 	if err != nil {
-		return nil, errors.New("token tidak ditemukan")
+		return nil, ErrPaymentTokenNotFound
 	}
 
 	// Dynamic check for expiry if still marked as active
@@ -222,7 +271,13 @@ func (s *WalletService) GetTokenDetails(tokenCode string) (*PaymentToken, error)
 }
 
 // StudentPayToken executes a payment from a student scanning a bill
-func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint, pin string) error {
+func (s *WalletService) StudentPayToken(ctx context.Context, tokenCode string, scannerUserID uint, pin string) (err error) {
+	ctx, span := tracing.Start(ctx, "wallet.StudentPayToken", attribute.Int("user.id", int(scannerUserID)))
+	defer func() {
+		tracing.End(span, err)
+		metrics.ObserveWalletOperation("qr_payment", err)
+	}()
+
 	// 1. Verify PIN
 	if err := s.authService.VerifyPIN(scannerUserID, pin); err != nil {
 		return err
@@ -230,20 +285,20 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint, pi
 
 	var token PaymentToken
 	if err := s.db.Where("token = ? AND status = ?", tokenCode, "active").First(&token).Error; err != nil {
-		return errors.New("token tidak valid")
+		return ErrPaymentTokenInvalid
 	}
 
 	if time.Now().After(token.Expiry) {
-		return errors.New("token kadaluarsa")
+		return ErrPaymentTokenExpired
 	}
 
 	scannerWallet, err := s.repo.FindByUserID(scannerUserID)
 	if err != nil {
-		return errors.New("wallet pembayar tidak ditemukan")
+		return ErrWalletNotFound
 	}
 
 	if scannerWallet.Balance < token.Amount {
-		return errors.New("saldo tidak mencukupi")
+		return ErrInsufficientBalance
 	}
 
 	// Recipient logic
@@ -254,10 +309,10 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint, pi
 			ID       uint
 			FullName string
 		}
-		err := s.db.Table("users").Where("role = ?", "admin").Select("id, full_name").Order("id asc").First(&adminUser).Error
+		err := s.db.Table("users").Where("role = ? AND deleted_at IS NULL", "admin").Select("id, full_name").Order("id asc").First(&adminUser).Error
 		if err != nil {
-			log.Printf("[StudentPayToken] No admin user found in database: %v", err)
-			return errors.New("sistem gagal menemukan admin sebagai penerima")
+			slog.ErrorContext(ctx, "qr payment: no admin user to receive payment", "error", err)
+			return ErrPaymentRecipientSetup
 		}
 		recipientID = adminUser.ID
 	}
@@ -265,18 +320,18 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint, pi
 	recipientWallet, err := s.repo.FindByUserID(recipientID)
 	if err != nil {
 		// If wallet doesn't exist, create it (every user should have one)
-		log.Printf("[StudentPayToken] Recipient (User %d) has no wallet, creating one...", recipientID)
+		slog.WarnContext(ctx, "qr payment: recipient has no wallet, creating one", "recipient_id", recipientID)
 		newWallet := &Wallet{
 			UserID:  recipientID,
 			Balance: 0,
 		}
 		if err := s.db.Create(newWallet).Error; err != nil {
-			return errors.New("gagal menyiapkan wallet penerima")
+			return ErrPaymentRecipientSetup
 		}
 		recipientWallet = newWallet
 	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err = utils.WithTx(ctx, s.db, func(tx *gorm.DB) error {
 		// 1. Deduct from scanner
 		if err := s.repo.UpdateBalance(tx, scannerWallet.ID, -token.Amount); err != nil {
 			return err
@@ -315,7 +370,7 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint, pi
 
 				// Reduce Stock
 				if err := tx.Table("products").Where("id = ?", token.ProductID).Update("stock", gorm.Expr("stock - ?", 1)).Error; err != nil {
-					return fmt.Errorf("gagal memperbarui stok: %v", err)
+					return fmt.Errorf("failed to update stock: %v", err)
 				}
 			}
 		}
@@ -329,7 +384,7 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint, pi
 			recipientType = "transfer_in"
 		}
 
-		tx.Create(&WalletTransaction{
+		if err := s.recordTransaction(tx, &WalletTransaction{
 			WalletID:    scannerWallet.ID,
 			Type:        payerType,
 			Amount:      token.Amount,
@@ -337,9 +392,11 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint, pi
 			Status:      "success",
 			Description: payerDesc,
 			ReferenceID: refID,
-		})
+		}); err != nil {
+			return err
+		}
 
-		tx.Create(&WalletTransaction{
+		if err := s.recordTransaction(tx, &WalletTransaction{
 			WalletID:    recipientWallet.ID,
 			Type:        recipientType,
 			Amount:      token.Amount,
@@ -347,26 +404,44 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint, pi
 			Status:      "success",
 			Description: recipientDesc,
 			ReferenceID: refID,
-		})
+		}); err != nil {
+			return err
+		}
 
 		return nil
 	})
+	if err == nil && token.Type == "purchase" && token.ProductID != 0 && s.onStockChange != nil {
+		s.onStockChange(token.ProductID)
+	}
+
+	return err
 }
 
 // DebitWithTransaction handles point deduction within an existing transaction
 func (s *WalletService) DebitWithTransaction(tx *gorm.DB, walletID uint, amount int, txnType string, description string) error {
-	// 1. Check balance
-	wallet, err := s.repo.FindByID(walletID)
+	_, err := s.DebitWithTransactionID(tx, walletID, amount, txnType, description)
+	return err
+}
+
+// DebitWithTransactionID is DebitWithTransaction returning the ID of the debit,
+// for a later RefundWithTransaction to link to
+func (s *WalletService) DebitWithTransactionID(tx *gorm.DB, walletID uint, amount int, txnType string, description string) (_ uint, err error) {
+	ctx, span := tracing.Start(tx.Statement.Context, "wallet.Debit", attribute.Int("wallet.id", int(walletID)), attribute.Int("amount", amount))
+	defer func() { tracing.End(span, err) }()
+	tx = tx.WithContext(ctx)
+
+	// 1. Check balance on the locked row, so concurrent debits cannot both pass it
+	wallet, err := s.repo.LockByID(tx, walletID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if wallet.Balance < amount {
-		return errors.New("insufficient balance")
+		return 0, ErrInsufficientBalance
 	}
 
 	// 2. Update balance
 	if err := s.repo.UpdateBalance(tx, walletID, -amount); err != nil {
-		return err
+		return 0, err
 	}
 
 	// 3. Create transaction record
@@ -379,11 +454,18 @@ func (s *WalletService) DebitWithTransaction(tx *gorm.DB, walletID uint, amount
 		Description: description,
 	}
 
-	return s.repo.CreateTransaction(tx, txn)
+	if err := s.recordTransaction(tx, txn); err != nil {
+		return 0, err
+	}
+	return txn.ID, nil
 }
 
 // CreditWithTransaction handles point addition within an existing transaction
-func (s *WalletService) CreditWithTransaction(tx *gorm.DB, walletID uint, amount int, txnType string, description string) error {
+func (s *WalletService) CreditWithTransaction(tx *gorm.DB, walletID uint, amount int, txnType string, description string) (err error) {
+	ctx, span := tracing.Start(tx.Statement.Context, "wallet.Credit", attribute.Int("wallet.id", int(walletID)), attribute.Int("amount", amount))
+	defer func() { tracing.End(span, err) }()
+	tx = tx.WithContext(ctx)
+
 	// 1. Update balance
 	if err := s.repo.UpdateBalance(tx, walletID, amount); err != nil {
 		return err
@@ -399,7 +481,43 @@ func (s *WalletService) CreditWithTransaction(tx *gorm.DB, walletID uint, amount
 		Description: description,
 	}
 
-	return s.repo.CreateTransaction(tx, txn)
+	return s.recordTransaction(tx, txn)
+}
+
+// RefundWithTransaction gives back amount points of the debit debitID within
+// an existing transaction. The refund credit links to the debit, so point
+// expiry counts the points as never spent and they expire with the points the
+// debit used up, not as newly earned ones. A debitID of 0, for debits made
+// before they were linked, credits the points as newly earned.
+func (s *WalletService) RefundWithTransaction(tx *gorm.DB, walletID, debitID uint, amount int, txnType string, description string) (err error) {
+	ctx, span := tracing.Start(tx.Statement.Context, "wallet.Refund", attribute.Int("wallet.id", int(walletID)), attribute.Int("amount", amount))
+	defer func() { tracing.End(span, err) }()
+	tx = tx.WithContext(ctx)
+
+	var refundOf *uint
+	if debitID != 0 {
+		debit, refunded, err := s.repo.FindDebit(tx, walletID, debitID)
+		if err != nil {
+			return err
+		}
+		if refunded+amount > debit.Amount {
+			return ErrRefundExceedsDebit
+		}
+		refundOf = &debitID
+	}
+
+	if err := s.repo.UpdateBalance(tx, walletID, amount); err != nil {
+		return err
+	}
+	return s.recordTransaction(tx, &WalletTransaction{
+		WalletID:    walletID,
+		Type:        txnType,
+		Amount:      amount,
+		Direction:   "credit",
+		Status:      "success",
+		Description: description,
+		RefundOf:    refundOf,
+	})
 }
 
 // ProcessMissionRewardWithTx handles mission rewards within a transaction
@@ -426,18 +544,22 @@ func (s *WalletService) ProcessMissionRewardWithTx(tx *gorm.DB, userID uint, amo
 		return err
 	}
 
-	return s.repo.CreateTransaction(tx, txn)
+	return s.recordTransaction(tx, txn)
 }
 
-func (s *WalletService) GetAdminStats() (*AdminStats, error) {
+// GetAdminStats summarizes the campus of ctx, or the whole deployment for unscoped super admins
+func (s *WalletService) GetAdminStats(ctx context.Context) (*AdminStats, error) {
 	var stats AdminStats
+	db := s.db.WithContext(ctx)
+	tenant := utils.ScopeTenant(ctx, "tenant_id")
+	wallets := ScopeTenantWallets(ctx, "wallet_id")
 
 	// 1. User Stats
-	s.db.Table("users").Count(&stats.TotalUsers)
-	s.db.Table("users").Where("status = ?", "active").Count(&stats.ActiveUsers)
+	db.Table("users").Where("deleted_at IS NULL").Scopes(tenant).Count(&stats.TotalUsers)
+	db.Table("users").Where("status = ? AND deleted_at IS NULL", "active").Scopes(tenant).Count(&stats.ActiveUsers)
 
 	// 2. Circulation Points
-	s.db.Table("wallets").Select("COALESCE(SUM(balance), 0)").Scan(&stats.CirculationPoints)
+	db.Table("wallets").Select("COALESCE(SUM(balance), 0)").Scopes(utils.ScopeTenantOwner(ctx, "user_id", "users")).Scan(&stats.CirculationPoints)
 
 	// 3. Today Stats
 	location, err := time.LoadLocation("Asia/Jakarta")
@@ -447,22 +569,24 @@ func (s *WalletService) GetAdminStats() (*AdminStats, error) {
 	now := time.Now().In(location)
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
 
-	s.db.Model(&WalletTransaction{}).Where("created_at >= ?", startOfDay).Count(&stats.TodayTransactions)
+	db.Model(&WalletTransaction{}).Where("created_at >= ?", startOfDay).Scopes(wallets).Count(&stats.TodayTransactions)
 
-	s.db.Model(&WalletTransaction{}).
+	db.Model(&WalletTransaction{}).
 		Where("created_at >= ? AND direction = ?", startOfDay, "credit").
+		Scopes(wallets).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&stats.TodayCredits)
 
-	s.db.Model(&WalletTransaction{}).
+	db.Model(&WalletTransaction{}).
 		Where("created_at >= ? AND direction = ?", startOfDay, "debit").
+		Scopes(wallets).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&stats.TodayDebits)
 
 	// 4. Additional System Monitoring
-	s.db.Table("products").Where("status = ?", "active").Count(&stats.TotalProducts)
-	s.db.Table("missions").Count(&stats.TotalMissions)
-	s.db.Table("mission_submissions").Where("status = ?", "pending").Count(&stats.PendingSubmission)
+	db.Table("products").Where("status = ? AND deleted_at IS NULL", "active").Scopes(tenant).Count(&stats.TotalProducts)
+	db.Table("missions").Scopes(utils.ScopeTenantOwner(ctx, "creator_id", "users")).Count(&stats.TotalMissions)
+	db.Table("mission_submissions").Where("status = ?", "pending").Scopes(utils.ScopeTenantOwner(ctx, "student_id", "users")).Count(&stats.PendingSubmission)
 
 	return &stats, nil
 }
@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/resilience"
+)
+
+// Request headers of a delivery. Receivers verify SignatureHeader, which has the form
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed by the endpoint secret>",
+// and should reject timestamps more than a few minutes old to stop replays.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventIDHeader   = "X-Webhook-Event-ID"
+	EventTypeHeader = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// maxStoredResponse caps how much of the receiver's response body is kept in the log
+const maxStoredResponse = 4096
+
+type deliveryJob struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+// Sign returns the SignatureHeader value for body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Verify checks a SignatureHeader value signed the same way by a sender of
+// inbound events; timestamps more than tolerance away from now are refused
+func Verify(secret, header string, body []byte, tolerance time.Duration) bool {
+	var timestamp int64
+	for _, part := range strings.Split(header, ",") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(part), "t="); ok {
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	if timestamp == 0 || time.Since(time.Unix(timestamp, 0)).Abs() > tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(strings.TrimSpace(header)))
+}
+
+// InboundTolerance is how far the timestamp of a signed inbound request may be off
+const InboundTolerance = 5 * time.Minute
+
+// InboundVerifier checks the signature header of requests from a campus system
+// that signs like our outbound webhooks, "t=<unix>,v1=<hex HMAC-SHA256 of
+// "<t>.<body>">". NotConfigured and Invalid are the errors of the receiving
+// package.
+type InboundVerifier struct {
+	Secret        string
+	NotConfigured error
+	Invalid       error
+}
+
+// Verify fails with NotConfigured without a secret, and with Invalid when the
+// signature does not match or its timestamp is more than InboundTolerance off
+func (v InboundVerifier) Verify(header string, body []byte) error {
+	if v.Secret == "" {
+		return v.NotConfigured
+	}
+	if !Verify(v.Secret, header, body, InboundTolerance) {
+		return v.Invalid
+	}
+	return nil
+}
+
+// deliver is the JobDeliver handler: it posts the delivery once and records the
+// outcome; a returned error makes the job queue retry it with backoff
+func (s *Service) deliver(ctx context.Context, job *jobs.Job) (interface{}, error) {
+	var payload deliveryJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+
+	delivery, err := s.repo.FindDeliveryByID(payload.DeliveryID)
+	if err != nil {
+		if errors.Is(err, ErrDeliveryNotFound) {
+			// The endpoint was deleted together with its deliveries
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	if delivery.Status == DeliverySucceeded {
+		return nil, nil
+	}
+
+	endpoint, err := s.repo.FindEndpointByID(delivery.EndpointID)
+	if err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	if !endpoint.Active {
+		s.recordAttempt(delivery, job, attempt{err: ErrEndpointNotActive}, true)
+		return nil, jobs.Permanent(ErrEndpointNotActive)
+	}
+
+	// Attempts are paced by the job queue, so the policy makes a single one
+	var result attempt
+	policy := resilience.Policy{Name: "webhook", MaxAttempts: 1, Breaker: s.breaker(endpoint.ID)}
+	if err := policy.Do(ctx, func(ctx context.Context) error {
+		result = s.send(ctx, endpoint, delivery)
+		return result.err
+	}); errors.Is(err, resilience.ErrOpen) {
+		result = attempt{err: err}
+	}
+	// A 410 Gone means the receiver has been removed; retrying will not help
+	permanent := result.status == http.StatusGone
+	s.recordAttempt(delivery, job, result, permanent || job.Attempts >= job.MaxAttempts)
+
+	if result.err != nil {
+		if permanent {
+			return nil, jobs.Permanent(result.err)
+		}
+		return nil, result.err
+	}
+	return map[string]interface{}{"delivery_id": delivery.ID, "response_status": result.status}, nil
+}
+
+type attempt struct {
+	status   int
+	body     string
+	duration time.Duration
+	err      error
+}
+
+func (s *Service) send(ctx context.Context, endpoint *Endpoint, delivery *Delivery) attempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return attempt{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WalletPoint-Webhooks/1.0")
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, time.Now().Unix(), delivery.Payload))
+	req.Header.Set(EventIDHeader, delivery.EventID)
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return attempt{duration: time.Since(start), err: err}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxStoredResponse))
+	result := attempt{status: resp.StatusCode, body: string(body), duration: time.Since(start)}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.err = fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	}
+	return result
+}
+
+// recordAttempt stores the outcome of an attempt; final marks a failure as the last try
+func (s *Service) recordAttempt(delivery *Delivery, job *jobs.Job, result attempt, final bool) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts + 1,
+		"response_status": result.status,
+		"response_body":   result.body,
+		"duration_ms":     result.duration.Milliseconds(),
+		"last_attempt_at": now,
+		"error":           "",
+	}
+	switch {
+	case result.err == nil:
+		updates["status"] = DeliverySucceeded
+		updates["delivered_at"] = now
+	case final:
+		updates["status"] = DeliveryFailed
+		updates["error"] = result.err.Error()
+	default:
+		updates["error"] = result.err.Error()
+	}
+
+	if err := s.repo.UpdateDelivery(delivery.ID, updates); err != nil {
+		slog.Error("webhooks: record delivery attempt failed", "delivery_id", delivery.ID, "job_id", job.ID, "error", err)
+	}
+}
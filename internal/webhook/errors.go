@@ -0,0 +1,15 @@
+package webhook
+
+import (
+	"net/http"
+	"wallet-point/utils"
+)
+
+var (
+	ErrEndpointNotFound  = utils.NewAppError("WEBHOOK_ENDPOINT_NOT_FOUND", http.StatusNotFound, "webhook endpoint not found")
+	ErrDeliveryNotFound  = utils.NewAppError("WEBHOOK_DELIVERY_NOT_FOUND", http.StatusNotFound, "webhook delivery not found")
+	ErrUnknownEventType  = utils.NewAppError("WEBHOOK_UNKNOWN_EVENT_TYPE", http.StatusBadRequest, "unknown webhook event type")
+	ErrInvalidURL        = utils.NewAppError("WEBHOOK_INVALID_URL", http.StatusBadRequest, "webhook URL must be an absolute http(s) URL")
+	ErrDeliveryPending   = utils.NewAppError("WEBHOOK_DELIVERY_PENDING", http.StatusConflict, "webhook delivery is still being attempted")
+	ErrEndpointNotActive = utils.NewAppError("WEBHOOK_ENDPOINT_INACTIVE", http.StatusConflict, "webhook endpoint is disabled")
+)
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"context"
+	"wallet-point/internal/events"
+
+	"gorm.io/gorm"
+)
+
+// OnOrderPlaced is the webhook subscriber of order.placed: one order.created per order
+func (s *Service) OnOrderPlaced(ctx context.Context, event *events.DomainEvent) error {
+	var placed events.OrderPlaced
+	if err := event.Decode(&placed); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, order := range placed.Orders {
+			if err := s.Publish(tx, EventOrderCreated, order); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// OnPointsMoved is the webhook subscriber of points.debited and points.credited,
+// sent as wallet.debited and wallet.credited
+func (s *Service) OnPointsMoved(ctx context.Context, event *events.DomainEvent) error {
+	var entry events.LedgerEntry
+	if err := event.Decode(&entry); err != nil {
+		return err
+	}
+	eventType := EventWalletCredited
+	if event.Type == events.EventPointsDebited {
+		eventType = EventWalletDebited
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.Publish(tx, eventType, entry)
+	})
+}
@@ -0,0 +1,359 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	service      *Service
+	auditService audit.Logger
+}
+
+func NewWebhookHandler(service *Service, auditService audit.Logger) *WebhookHandler {
+	return &WebhookHandler{service: service, auditService: auditService}
+}
+
+// GetEventTypes handles listing the events endpoints can subscribe to (Admin)
+// @Summary Get webhook event types
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]string}
+// @Router /admin/webhooks/events [get]
+func (h *WebhookHandler) GetEventTypes(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Webhook events retrieved successfully", EventTypes)
+}
+
+// GetAll handles listing webhook endpoints (Admin)
+// @Summary Get webhook endpoints
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Endpoint}
+// @Router /admin/webhooks [get]
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	endpoints, err := h.service.GetEndpoints()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve webhook endpoints", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook endpoints retrieved successfully", endpoints)
+}
+
+// GetByID handles getting a webhook endpoint (Admin)
+// @Summary Get webhook endpoint
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Success 200 {object} utils.Response{data=Endpoint}
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhooks/{id} [get]
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	endpointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook endpoint ID", nil)
+		return
+	}
+
+	endpoint, err := h.service.GetEndpoint(uint(endpointID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook endpoint retrieved successfully", endpoint)
+}
+
+// Create handles registering a webhook endpoint (Admin)
+// @Summary Create webhook endpoint
+// @Description Register a URL to receive events ("*" subscribes to all). Each delivery is a JSON POST {id, type, created_at, data} signed in the X-Webhook-Signature header as "t=<unix>,v1=<hex HMAC-SHA256 of t + "." + body>" with the endpoint secret, which is only shown in this response. Failed deliveries are retried with exponential backoff.
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateEndpointRequest true "Endpoint details"
+// @Success 201 {object} utils.Response{data=EndpointWithSecret}
+// @Failure 400 {object} utils.Response
+// @Router /admin/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req CreateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	endpoint, err := h.service.CreateEndpoint(req, adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Webhook endpoint created", endpoint)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_WEBHOOK",
+		Entity:    "WEBHOOK",
+		EntityID:  endpoint.ID,
+		Details:   fmt.Sprintf("Registered webhook %s for events %s", endpoint.URL, endpoint.EventTypes),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Update handles changing a webhook endpoint (Admin)
+// @Summary Update webhook endpoint
+// @Description Change the URL, events or description, or disable the endpoint (active=false) to pause deliveries
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Param request body UpdateEndpointRequest true "Fields to change"
+// @Success 200 {object} utils.Response{data=Endpoint}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	endpointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook endpoint ID", nil)
+		return
+	}
+
+	var req UpdateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	endpoint, err := h.service.UpdateEndpoint(uint(endpointID), req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook endpoint updated", endpoint)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPDATE_WEBHOOK",
+		Entity:    "WEBHOOK",
+		EntityID:  endpoint.ID,
+		Details:   fmt.Sprintf("Updated webhook %s (events %s, active=%t)", endpoint.URL, endpoint.EventTypes, endpoint.Active),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Delete handles removing a webhook endpoint and its delivery log (Admin)
+// @Summary Delete webhook endpoint
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	endpointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook endpoint ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteEndpoint(uint(endpointID)); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook endpoint deleted", nil)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "DELETE_WEBHOOK",
+		Entity:    "WEBHOOK",
+		EntityID:  uint(endpointID),
+		Details:   fmt.Sprintf("Deleted webhook endpoint #%d", endpointID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// RotateSecret handles replacing a webhook endpoint's signing secret (Admin)
+// @Summary Rotate webhook secret
+// @Description Generate a new signing secret, shown only in this response. Deliveries sent from now on, including retries, use it.
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Success 200 {object} utils.Response{data=EndpointWithSecret}
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	endpointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook endpoint ID", nil)
+		return
+	}
+
+	endpoint, err := h.service.RotateSecret(uint(endpointID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook secret rotated", endpoint)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "ROTATE_WEBHOOK_SECRET",
+		Entity:    "WEBHOOK",
+		EntityID:  endpoint.ID,
+		Details:   fmt.Sprintf("Rotated the signing secret of webhook %s", endpoint.URL),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// SendTest handles queueing a test event to a webhook endpoint (Admin)
+// @Summary Send test webhook
+// @Description Queue a webhook.test event to the endpoint; check the delivery log for the result
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Success 202 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/webhooks/{id}/test [post]
+func (h *WebhookHandler) SendTest(c *gin.Context) {
+	endpointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook endpoint ID", nil)
+		return
+	}
+
+	if err := h.service.SendTest(uint(endpointID), c.GetUint("user_id")); err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Test event queued", nil)
+}
+
+// GetDeliveries handles listing the delivery log of a webhook endpoint (Admin)
+// @Summary Get webhook deliveries
+// @Description Deliveries newest first, without payloads; open one for its payload and the receiver's response
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Param status query string false "Filter by status" Enums(pending, succeeded, failed)
+// @Param event_type query string false "Filter by event type"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} utils.Response{data=DeliveryListResponse}
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	endpointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook endpoint ID", nil)
+		return
+	}
+	page, limit, ok := utils.PageQuery(c, 20)
+	if !ok {
+		return
+	}
+	status, ok := utils.QueryEnum(c, "status", "webhook_delivery_status")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetDeliveries(DeliveryListParams{
+		EndpointID: uint(endpointID),
+		Status:     status,
+		EventType:  c.Query("event_type"),
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.ListResponse(c, "Webhook deliveries retrieved successfully", "deliveries", response.Deliveries, response.Pagination, nil)
+}
+
+// GetDelivery handles getting one webhook delivery with its payload and response (Admin)
+// @Summary Get webhook delivery
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Delivery ID"
+// @Success 200 {object} utils.Response{data=Delivery}
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhook-deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	deliveryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook delivery ID", nil)
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(uint(deliveryID))
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook delivery retrieved successfully", delivery)
+}
+
+// Redeliver handles sending a finished webhook delivery again (Admin)
+// @Summary Redeliver webhook
+// @Description Send the same event (same ID and body) again with a fresh set of attempts
+// @Tags Admin - Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Delivery ID"
+// @Success 202 {object} utils.Response{data=Delivery}
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/webhook-deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	deliveryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook delivery ID", nil)
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	delivery, err := h.service.Redeliver(uint(deliveryID), adminID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Webhook delivery queued", delivery)
+
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "REDELIVER_WEBHOOK",
+		Entity:    "WEBHOOK",
+		EntityID:  delivery.EndpointID,
+		Details:   fmt.Sprintf("Redelivered %s event %s (delivery #%d)", delivery.EventType, delivery.EventID, delivery.ID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+	"wallet-point/utils"
+)
+
+// Events endpoints can subscribe to. Event names are part of the API contract:
+// never rename one, add a new event instead.
+const (
+	EventOrderCreated    = "order.created"
+	EventWalletCredited  = "wallet.credited"
+	EventWalletDebited   = "wallet.debited"
+	EventProductCreated  = "product.created"
+	EventProductUpdated  = "product.updated"
+	EventProductDeleted  = "product.deleted"
+	EventProductRestored = "product.restored"
+
+	// EventTest is only sent by the test endpoint and cannot be subscribed to
+	EventTest = "webhook.test"
+
+	// AllEvents subscribes an endpoint to every event, including ones added later
+	AllEvents = "*"
+)
+
+var EventTypes = []string{
+	EventOrderCreated,
+	EventWalletCredited,
+	EventWalletDebited,
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventProductRestored,
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Endpoint is a URL receiving signed event deliveries. EventTypes is a
+// comma-separated list of events, or "*" for all of them.
+type Endpoint struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	URL         string    `json:"url" gorm:"size:500;not null"`
+	Secret      string    `json:"-" gorm:"size:100;not null"`
+	EventTypes  string    `json:"event_types" gorm:"size:500;not null"`
+	Description string    `json:"description" gorm:"size:255"`
+	Active      bool      `json:"active" gorm:"default:true;not null"`
+	CreatedBy   uint      `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Endpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// Delivery is one event sent to one endpoint, with the outcome of its latest attempt.
+// Payload is the exact request body, so a redelivery is byte-for-byte identical.
+type Delivery struct {
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	EndpointID     uint            `json:"endpoint_id" gorm:"not null;index:idx_webhook_deliveries_endpoint,priority:1"`
+	EventID        string          `json:"event_id" gorm:"size:40;not null;index"`
+	EventType      string          `json:"event_type" gorm:"size:100;not null"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:mediumtext" swaggertype:"object"`
+	Status         string          `json:"status" gorm:"type:enum('pending','succeeded','failed');default:'pending';not null"`
+	Attempts       int             `json:"attempts" gorm:"default:0;not null"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty" gorm:"type:text"`
+	Error          string          `json:"error,omitempty" gorm:"type:text"`
+	DurationMs     int64           `json:"duration_ms"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"index:idx_webhook_deliveries_endpoint,priority:2"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Event is the JSON body of every delivery
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type CreateEndpointRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=500"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	Description string   `json:"description" binding:"max=255"`
+}
+
+type UpdateEndpointRequest struct {
+	URL         *string   `json:"url" binding:"omitempty,url,max=500"`
+	EventTypes  *[]string `json:"event_types" binding:"omitempty,min=1"`
+	Description *string   `json:"description" binding:"omitempty,max=255"`
+	Active      *bool     `json:"active"`
+}
+
+// EndpointWithSecret is returned when an endpoint is created or its secret rotated,
+// the only times the signing secret is shown
+type EndpointWithSecret struct {
+	Endpoint
+	Secret string `json:"secret"`
+}
+
+type DeliveryListParams struct {
+	EndpointID uint
+	Status     string
+	EventType  string
+	Page       int
+	Limit      int
+}
+
+type DeliveryListResponse struct {
+	Deliveries []Delivery `json:"deliveries"`
+	utils.Pagination
+}
@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateEndpoint(endpoint *Endpoint) error {
+	return r.db.Create(endpoint).Error
+}
+
+func (r *Repository) FindEndpoints() ([]Endpoint, error) {
+	var endpoints []Endpoint
+	err := r.db.Order("id ASC").Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *Repository) FindEndpointByID(id uint) (*Endpoint, error) {
+	var endpoint Endpoint
+	err := r.db.First(&endpoint, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEndpointNotFound
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// FindSubscribed returns the active endpoints subscribed to eventType
+func (r *Repository) FindSubscribed(tx *gorm.DB, eventType string) ([]Endpoint, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var endpoints []Endpoint
+	err := tx.Where("active = ? AND (event_types = ? OR FIND_IN_SET(?, event_types) > 0)", true, AllEvents, eventType).
+		Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *Repository) UpdateEndpoint(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&Endpoint{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteEndpoint removes an endpoint together with its delivery log
+func (r *Repository) DeleteEndpoint(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("endpoint_id = ?", id).Delete(&Delivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Endpoint{}, id).Error
+	})
+}
+
+func (r *Repository) CreateDelivery(tx *gorm.DB, delivery *Delivery) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(delivery).Error
+}
+
+func (r *Repository) FindDeliveryByID(id uint) (*Delivery, error) {
+	var delivery Delivery
+	err := r.db.First(&delivery, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *Repository) UpdateDelivery(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&Delivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindDeliveries lists deliveries newest first, without their payloads
+func (r *Repository) FindDeliveries(params DeliveryListParams) ([]Delivery, int64, error) {
+	var deliveries []Delivery
+	var total int64
+
+	query := r.db.Model(&Delivery{}).Where("endpoint_id = ?", params.EndpointID)
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.EventType != "" {
+		query = query.Where("event_type = ?", params.EventType)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Omit("payload", "response_body").
+		Order("created_at DESC").Order("id DESC").
+		Limit(params.Limit).Offset(offset).
+		Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// FindFailedDeliveryIDs returns the failed deliveries created since since, oldest
+// first, of one endpoint or of all endpoints when endpointID is 0
+func (r *Repository) FindFailedDeliveryIDs(endpointID uint, since time.Time) ([]uint, error) {
+	var ids []uint
+	query := r.db.Model(&Delivery{}).Where("status = ? AND created_at >= ?", DeliveryFailed, since)
+	if endpointID != 0 {
+		query = query.Where("endpoint_id = ?", endpointID)
+	}
+	err := query.Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
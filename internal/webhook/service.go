@@ -0,0 +1,340 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/resilience"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// JobDeliver is the job type sending one delivery; it runs on the webhooks queue
+// so the job queue's backoff paces the retries
+const JobDeliver = "webhook.deliver"
+
+type Service struct {
+	repo        *Repository
+	db          *gorm.DB
+	queue       *jobs.Queue
+	client      *http.Client
+	maxAttempts int
+
+	// One circuit breaker per endpoint, so a dead receiver does not tie up webhook
+	// workers with timeouts while the other endpoints keep being served
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	breakersMu       sync.Mutex
+	breakers         map[uint]*resilience.Breaker
+}
+
+// NewService sends deliveries with the given request timeout, giving each up to
+// maxAttempts tries before it is marked failed
+func NewService(repo *Repository, db *gorm.DB, queue *jobs.Queue, timeout time.Duration, maxAttempts int) *Service {
+	s := &Service{
+		repo:  repo,
+		db:    db,
+		queue: queue,
+		client: &http.Client{
+			Timeout: timeout,
+			// A redirect could point the signed payload at another host; report it instead
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		maxAttempts: maxAttempts,
+		breakers:    make(map[uint]*resilience.Breaker),
+	}
+	queue.Register(JobDeliver, jobs.QueueWebhooks, s.deliver)
+	return s
+}
+
+// SetCircuitBreaker suspends deliveries to an endpoint for cooldown once threshold
+// deliveries in a row have failed; the suspended deliveries are retried by the job
+// queue like any other failure. A threshold of 0 disables it.
+func (s *Service) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	s.breakerThreshold = threshold
+	s.breakerCooldown = cooldown
+}
+
+func (s *Service) breaker(endpointID uint) *resilience.Breaker {
+	if s.breakerThreshold <= 0 {
+		return nil
+	}
+
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+	breaker, ok := s.breakers[endpointID]
+	if !ok {
+		breaker = resilience.NewBreaker(fmt.Sprintf("webhook_%d", endpointID), s.breakerThreshold, s.breakerCooldown)
+		s.breakers[endpointID] = breaker
+	}
+	return breaker
+}
+
+// Publish queues eventType with data for every endpoint subscribed to it. Pass the
+// transaction that made the change so the deliveries only exist if it commits;
+// with a nil tx the deliveries are written in a transaction of their own.
+func (s *Service) Publish(tx *gorm.DB, eventType string, data interface{}) error {
+	if tx == nil {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			return s.Publish(tx, eventType, data)
+		})
+	}
+
+	endpoints, err := s.repo.FindSubscribed(tx, eventType)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+	return s.enqueue(tx, endpoints, eventType, data)
+}
+
+func (s *Service) enqueue(tx *gorm.DB, endpoints []Endpoint, eventType string, data interface{}) error {
+	event := Event{ID: newEventID(), Type: eventType, CreatedAt: time.Now().UTC(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+		delivery := &Delivery{
+			EndpointID: endpoint.ID,
+			EventID:    event.ID,
+			EventType:  eventType,
+			Payload:    payload,
+			Status:     DeliveryPending,
+		}
+		if err := s.repo.CreateDelivery(tx, delivery); err != nil {
+			return err
+		}
+		if _, err := s.queue.EnqueueTx(tx, JobDeliver, deliveryJob{DeliveryID: delivery.ID}, jobs.EnqueueOptions{MaxAttempts: s.maxAttempts}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEndpoints lists all endpoints (Admin)
+func (s *Service) GetEndpoints() ([]Endpoint, error) {
+	return s.repo.FindEndpoints()
+}
+
+// GetEndpoint returns an endpoint (Admin)
+func (s *Service) GetEndpoint(id uint) (*Endpoint, error) {
+	return s.repo.FindEndpointByID(id)
+}
+
+// CreateEndpoint registers an endpoint with a new signing secret (Admin)
+func (s *Service) CreateEndpoint(req CreateEndpointRequest, adminID uint) (*EndpointWithSecret, error) {
+	if err := validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &Endpoint{
+		URL:         req.URL,
+		Secret:      newSecret(),
+		EventTypes:  eventTypes,
+		Description: strings.TrimSpace(req.Description),
+		Active:      true,
+		CreatedBy:   adminID,
+	}
+	if err := s.repo.CreateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	return &EndpointWithSecret{Endpoint: *endpoint, Secret: endpoint.Secret}, nil
+}
+
+// UpdateEndpoint changes an endpoint's URL, events, description or active state (Admin)
+func (s *Service) UpdateEndpoint(id uint, req UpdateEndpointRequest) (*Endpoint, error) {
+	if _, err := s.repo.FindEndpointByID(id); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.URL != nil {
+		if err := validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		updates["url"] = *req.URL
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(*req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		updates["event_types"] = eventTypes
+	}
+	if req.Description != nil {
+		updates["description"] = strings.TrimSpace(*req.Description)
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+
+	if len(updates) > 0 {
+		if err := s.repo.UpdateEndpoint(id, updates); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindEndpointByID(id)
+}
+
+// DeleteEndpoint removes an endpoint and its delivery log (Admin)
+func (s *Service) DeleteEndpoint(id uint) error {
+	if _, err := s.repo.FindEndpointByID(id); err != nil {
+		return err
+	}
+	return s.repo.DeleteEndpoint(id)
+}
+
+// RotateSecret replaces an endpoint's signing secret; deliveries already queued
+// are signed with the new one when they are sent (Admin)
+func (s *Service) RotateSecret(id uint) (*EndpointWithSecret, error) {
+	if _, err := s.repo.FindEndpointByID(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateEndpoint(id, map[string]interface{}{"secret": newSecret()}); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.repo.FindEndpointByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &EndpointWithSecret{Endpoint: *endpoint, Secret: endpoint.Secret}, nil
+}
+
+// SendTest queues a webhook.test event to one endpoint (Admin)
+func (s *Service) SendTest(id uint, adminID uint) error {
+	endpoint, err := s.repo.FindEndpointByID(id)
+	if err != nil {
+		return err
+	}
+	if !endpoint.Active {
+		return ErrEndpointNotActive
+	}
+
+	data := map[string]interface{}{"endpoint_id": endpoint.ID, "requested_by": adminID}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.enqueue(tx, []Endpoint{*endpoint}, EventTest, data)
+	})
+}
+
+// GetDeliveries lists the delivery log of an endpoint (Admin)
+func (s *Service) GetDeliveries(params DeliveryListParams) (*DeliveryListResponse, error) {
+	if _, err := s.repo.FindEndpointByID(params.EndpointID); err != nil {
+		return nil, err
+	}
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	deliveries, total, err := s.repo.FindDeliveries(params)
+	if err != nil {
+		return nil, err
+	}
+	return &DeliveryListResponse{
+		Deliveries: deliveries,
+		Pagination: utils.NewPagination(params.Page, params.Limit, total, ""),
+	}, nil
+}
+
+// GetDelivery returns a delivery with its payload and last response (Admin)
+func (s *Service) GetDelivery(id uint) (*Delivery, error) {
+	return s.repo.FindDeliveryByID(id)
+}
+
+// Redeliver sends a finished delivery again with the same event ID and body, so
+// receivers that deduplicate by event ID stay consistent (Admin)
+func (s *Service) Redeliver(id uint, adminID uint) (*Delivery, error) {
+	delivery, err := s.repo.FindDeliveryByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == DeliveryPending {
+		return nil, ErrDeliveryPending
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Delivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":   DeliveryPending,
+			"attempts": 0,
+		}).Error; err != nil {
+			return err
+		}
+		_, err := s.queue.EnqueueTx(tx, JobDeliver, deliveryJob{DeliveryID: id}, jobs.EnqueueOptions{MaxAttempts: s.maxAttempts, CreatedBy: &adminID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindDeliveryByID(id)
+}
+
+// RedeliverFailed redelivers the failed deliveries created since since, of one
+// endpoint or of all when endpointID is 0, e.g. after a receiver's outage (walletctl)
+func (s *Service) RedeliverFailed(endpointID uint, since time.Time, adminID uint) (int, error) {
+	ids, err := s.repo.FindFailedDeliveryIDs(endpointID, since)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if _, err := s.Redeliver(id, adminID); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+func validateURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// normalizeEventTypes checks the requested events and stores them comma-separated
+func normalizeEventTypes(eventTypes []string) (string, error) {
+	var normalized []string
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if eventType == AllEvents {
+			return AllEvents, nil
+		}
+		if !slices.Contains(EventTypes, eventType) {
+			return "", fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+		}
+		if !slices.Contains(normalized, eventType) {
+			normalized = append(normalized, eventType)
+		}
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
@@ -40,6 +40,10 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		if !scopeTenant(c, claims) {
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -1,40 +1,114 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// CORS middleware handles Cross-Origin Resource Sharing
-func CORS(allowedOrigins string) gin.HandlerFunc {
+// CORSConfig lists what cross-origin callers (the web frontend) may do
+type CORSConfig struct {
+	// AllowedOrigins holds exact origins, "*" for any origin, or subdomain
+	// wildcards such as "https://*.xeroon.my.id"
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders empty means echo whatever the preflight asks for
+	AllowedHeaders []string
+	ExposedHeaders []string
+	MaxAge         time.Duration
+}
+
+// NewCORSConfig builds a CORSConfig from comma-separated settings
+func NewCORSConfig(allowedOrigins, allowedHeaders, exposedHeaders string, maxAge time.Duration) CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: splitList(allowedOrigins),
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: splitList(allowedHeaders),
+		ExposedHeaders: splitList(exposedHeaders),
+		MaxAge:         maxAge,
+	}
+}
+
+// CORS middleware handles Cross-Origin Resource Sharing, answering preflight
+// requests itself so they never reach the handlers
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
 
-		// Check if origin is allowed
-		if allowedOrigins == "*" || isOriginAllowed(origin, allowedOrigins) {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		preflight := c.Request.Method == http.MethodOptions && c.Request.Header.Get("Access-Control-Request-Method") != ""
+		if !isOriginAllowed(origin, cfg.AllowedOrigins) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// Served without CORS headers, so the browser withholds the response
+			c.Next()
+			return
 		}
 
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		// Credentials (the Authorization header) rule out a literal "*"
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if !preflight {
+			if exposedHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", exposedHeaders)
+			}
+			c.Next()
 			return
 		}
 
-		c.Next()
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", allowedMethods)
+		if allowedHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", allowedHeaders)
+		} else if requested := c.Request.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+		if cfg.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
 	}
 }
 
-func isOriginAllowed(origin, allowedOrigins string) bool {
-	origins := strings.Split(allowedOrigins, ",")
-	for _, allowedOrigin := range origins {
-		if strings.TrimSpace(allowedOrigin) == origin {
+func isOriginAllowed(origin string, allowedOrigins []string) bool {
+	for _, allowedOrigin := range allowedOrigins {
+		if allowedOrigin == "*" || allowedOrigin == origin {
 			return true
 		}
+		// "https://*.example.com" matches any subdomain of example.com over https
+		if scheme, domain, ok := strings.Cut(allowedOrigin, "://*."); ok {
+			if strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+domain) {
+				return true
+			}
+		}
 	}
 	return false
 }
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"wallet-point/internal/feature"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlag rejects requests while key is off for this environment or the caller's role.
+// Place it after AuthMiddleware so role-targeted flags see the role.
+func FeatureFlag(flags *feature.FlagService, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.IsEnabled(key, c.GetString("role")) {
+			utils.ServiceErrorResponse(c, http.StatusForbidden, feature.ErrFeatureDisabled)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
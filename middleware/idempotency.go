@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"wallet-point/internal/idempotency"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyWriter keeps a copy of the response body for replaying
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes mutating requests that carry an Idempotency-Key header safe to
+// retry: the first request is processed and its response stored, retries with the
+// same key and body get that response back ("Idempotent-Replayed: true") instead of
+// running again. Keys are scoped per user, so place it after AuthMiddleware.
+// Requests without the header, and 5xx responses, are not recorded.
+func Idempotency(service *idempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read request body", nil)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		path := c.Request.URL.Path
+		record, replay, err := service.Begin(c.GetUint("user_id"), key, c.Request.Method, path, idempotency.Hash(c.Request.Method, path, body))
+		if err != nil {
+			utils.ServiceErrorResponse(c, http.StatusInternalServerError, err)
+			c.Abort()
+			return
+		}
+
+		if replay {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.ResponseStatus, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// Release the key if the handler fails or panics so the client can retry
+		completed := false
+		defer func() {
+			if !completed {
+				service.Release(record)
+			}
+		}()
+
+		c.Next()
+
+		if writer.Status() < http.StatusInternalServerError {
+			service.Complete(record, writer.Status(), writer.body.Bytes(), writer.Header().Get("Content-Type"))
+			completed = true
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
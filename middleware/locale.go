@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale resolves the response language from Accept-Language once per request
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := utils.ResolveLanguage(c.GetHeader("Accept-Language"))
+		utils.SetLanguage(c, lang)
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -1,34 +1,65 @@
 package middleware
 
 import (
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
 	"time"
+	"wallet-point/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Logger middleware logs request details
+// Logger middleware tags the request context with a request ID and logs one
+// structured line per request; the level follows the response status
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
-		method := c.Request.Method
+
+		requestID := c.GetHeader("X-Request-ID")
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		c.Header("X-Request-ID", requestID)
+
+		ctx := utils.WithLogAttrs(c.Request.Context(),
+			slog.String("request_id", requestID),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("ip", c.ClientIP()),
+		)
+		ctx = utils.WithOrigin(ctx, utils.Origin{RequestID: requestID, IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+		c.Request = c.Request.WithContext(ctx)
 
 		// Process request
 		c.Next()
 
-		// Calculate latency
-		latency := time.Since(start)
 		statusCode := c.Writer.Status()
-		clientIP := c.ClientIP()
-
-		log.Printf("[%s] %s %s | Status: %d | Latency: %v | IP: %s",
-			method,
-			path,
-			c.Request.Proto,
-			statusCode,
-			latency,
-			clientIP,
-		)
+		level := slog.LevelInfo
+		switch {
+		case statusCode >= 500:
+			level = slog.LevelError
+		case statusCode >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.Int("status", statusCode),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			slog.Int("size", c.Writer.Size()),
+		}
+		if userID := c.GetUint("user_id"); userID != 0 {
+			attrs = append(attrs, slog.Uint64("user_id", uint64(userID)))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		slog.LogAttrs(ctx, level, "request", attrs...)
 	}
 }
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
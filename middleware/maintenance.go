@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"wallet-point/internal/feature"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// maintenanceRetryAfter is the Retry-After hint (seconds) of requests refused
+// during maintenance
+const maintenanceRetryAfter = "120"
+
+// MaintenanceConfig tunes the Maintenance middleware
+type MaintenanceConfig struct {
+	Forced       bool     // on regardless of the maintenance_mode flag (MAINTENANCE_MODE)
+	AllowedPaths []string // route prefixes that keep accepting writes, e.g. "/api/v1/auth/login"
+	AllowedRoles []string // roles whose writes are let through, e.g. "superadmin"
+}
+
+// Maintenance answers mutating requests with 503 while the maintenance_mode flag
+// (or MAINTENANCE_MODE) is on, so migrations can run while users keep reading.
+// GraphQL queries sent by POST count as reads; only mutations are refused.
+func Maintenance(flags *feature.FlagService, cfg MaintenanceConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutating(c.Request.Method) || !(cfg.Forced || flags.Enabled(feature.MaintenanceMode)) {
+			c.Next()
+			return
+		}
+		if maintenanceAllowed(c, cfg) || (strings.HasSuffix(c.FullPath(), "/graphql") && !graphQLMutation(c.Request)) {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", maintenanceRetryAfter)
+		utils.ServiceErrorResponse(c, http.StatusServiceUnavailable, feature.ErrMaintenance)
+		c.Abort()
+	}
+}
+
+func maintenanceAllowed(c *gin.Context, cfg MaintenanceConfig) bool {
+	for _, prefix := range cfg.AllowedPaths {
+		if c.FullPath() != "" && strings.HasPrefix(c.FullPath(), prefix) {
+			return true
+		}
+	}
+	if len(cfg.AllowedRoles) == 0 {
+		return false
+	}
+
+	// Runs before AuthMiddleware, so the role comes from the token itself
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	claims, err := utils.ValidateJWT(token)
+	if err != nil {
+		return false
+	}
+	for _, role := range cfg.AllowedRoles {
+		if claims.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLMutation reports whether a GraphQL POST body contains a mutation. The
+// body is put back for the handler; a body that does not parse counts as one.
+func graphQLMutation(r *http.Request) bool {
+	if r.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return true
+	}
+
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return true
+	}
+	doc, err := parser.ParseQuery(&ast.Source{Input: payload.Query})
+	if err != nil {
+		return true
+	}
+	for _, operation := range doc.Operations {
+		if operation.Operation == ast.Mutation {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"sync"
 	"time"
+	"wallet-point/utils"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
@@ -46,7 +47,7 @@ func RateLimiter(r rate.Limit, b int) gin.HandlerFunc {
 			mu.Unlock()
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"status":  "error",
-				"message": "Too many requests. Please try again later.",
+				"message": utils.Translate(c, "Too many requests. Please try again later."),
 			})
 			c.Abort()
 			return
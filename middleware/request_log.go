@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Field names whose values never reach the request log. Keys are compared in lower
+// case; the first list matches anywhere in the key ("new_password", "refreshToken"),
+// the second only as a whole snake_case word ("pin", "new_pin" but not "shipping").
+var (
+	redactedSubstrings = []string{"password", "secret", "token", "apikey", "api_key", "authorization", "signature", "private_key"}
+	redactedWords      = []string{"pin", "otp", "cvv"}
+)
+
+const redacted = "[REDACTED]"
+
+// OpenRequestLog returns the writer for target: "stdout", or a file path that is
+// created if needed and appended to
+func OpenRequestLog(target string) (io.Writer, error) {
+	if target == "stdout" {
+		return os.Stdout, nil
+	}
+	return os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+}
+
+// RequestLog writes one JSON line per request to out with the method, path, status,
+// latency and user. Mutating requests also get their request and response bodies
+// (JSON or form encoded, up to maxBodyBytes each; 0 logs no bodies) with passwords,
+// PINs, tokens and other secrets redacted. Register it after Logger so the line
+// carries the request ID.
+func RequestLog(out io.Writer, maxBodyBytes int) gin.HandlerFunc {
+	logger := slog.New(slog.NewJSONHandler(out, nil))
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		logBodies := maxBodyBytes > 0 && isMutating(c.Request.Method)
+
+		var requestBody slog.Attr
+		var recorder *bodyRecorder
+		if logBodies {
+			requestBody = slog.Any("request_body", captureRequestBody(c.Request, maxBodyBytes))
+			recorder = &bodyRecorder{ResponseWriter: c.Writer, limit: maxBodyBytes}
+			c.Writer = recorder
+		}
+
+		c.Next()
+
+		attrs := []slog.Attr{
+			slog.String("request_id", c.Writer.Header().Get("X-Request-ID")),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			slog.String("ip", c.ClientIP()),
+		}
+		if c.Request.URL.RawQuery != "" {
+			attrs = append(attrs, slog.String("query", redactValues(c.Request.URL.Query()).Encode()))
+		}
+		if userID := c.GetUint("user_id"); userID != 0 {
+			attrs = append(attrs, slog.Uint64("user_id", uint64(userID)))
+		}
+		if logBodies {
+			attrs = append(attrs, requestBody, slog.Any("response_body", recorder.logValue()))
+		}
+		logger.LogAttrs(c.Request.Context(), slog.LevelInfo, "request", attrs...)
+	}
+}
+
+// captureRequestBody reads up to limit bytes of a JSON or form body for the log and
+// puts them back in front of the rest, so handlers still see the whole body
+func captureRequestBody(r *http.Request, limit int) interface{} {
+	if r.Body == nil || r.ContentLength == 0 {
+		return nil
+	}
+	contentType := r.Header.Get("Content-Type")
+	if !loggableBody(contentType) {
+		return describeBody(contentType, r.ContentLength)
+	}
+
+	head, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil {
+		return fmt.Sprintf("[unreadable body: %v]", err)
+	}
+	if len(head) > limit {
+		return fmt.Sprintf("[%s body over %d bytes]", mediaType(contentType), limit)
+	}
+	return redactBody(contentType, head)
+}
+
+// bodyRecorder keeps the first limit bytes of the response for the log
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyRecorder) capture(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > w.limit {
+		w.overflow = true
+		return
+	}
+	w.body.Write(data)
+}
+
+func (w *bodyRecorder) logValue() interface{} {
+	header := w.Header()
+	contentType := header.Get("Content-Type")
+	switch {
+	case w.Size() <= 0:
+		return nil
+	case header.Get("Content-Encoding") != "":
+		return fmt.Sprintf("[%s encoded %s body, %d bytes]", header.Get("Content-Encoding"), mediaType(contentType), w.Size())
+	case !loggableBody(contentType):
+		return describeBody(contentType, int64(w.Size()))
+	case w.overflow:
+		return fmt.Sprintf("[%s body over %d bytes]", mediaType(contentType), w.limit)
+	}
+	return redactBody(contentType, w.body.Bytes())
+}
+
+func loggableBody(contentType string) bool {
+	switch mediaType(contentType) {
+	case "application/json", "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
+
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "unknown"
+	}
+	return parsed
+}
+
+func describeBody(contentType string, size int64) string {
+	return fmt.Sprintf("[%s body, %d bytes]", mediaType(contentType), size)
+}
+
+// redactBody returns a JSON body as raw JSON (so it nests in the log line) and a
+// form body as an encoded string, with sensitive fields replaced. A body that does
+// not parse is not logged at all: it might hold an unredacted secret.
+func redactBody(contentType string, body []byte) interface{} {
+	if mediaType(contentType) == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return fmt.Sprintf("[invalid form body, %d bytes]", len(body))
+		}
+		return redactValues(values).Encode()
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Sprintf("[invalid JSON body, %d bytes]", len(body))
+	}
+	clean, err := json.Marshal(redactJSON(value))
+	if err != nil {
+		return fmt.Sprintf("[invalid JSON body, %d bytes]", len(body))
+	}
+	return json.RawMessage(clean)
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if sensitiveField(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(nested)
+			}
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = redactJSON(nested)
+		}
+	}
+	return value
+}
+
+func redactValues(values url.Values) url.Values {
+	for key := range values {
+		if sensitiveField(key) {
+			values[key] = []string{redacted}
+		}
+	}
+	return values
+}
+
+func sensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range redactedSubstrings {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	for _, word := range strings.FieldsFunc(key, func(r rune) bool { return r == '_' || r == '-' }) {
+		for _, sensitive := range redactedWords {
+			if word == sensitive {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	"wallet-point/internal/cache"
+	"wallet-point/internal/settings"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseCacheName labels response cache lookups in the cache metrics
+const responseCacheName = "responses"
+
+// cachedResponse is a 200 response stored by ResponseCache
+type cachedResponse struct {
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// responseCacheWriter keeps a copy of the response body for storing
+type responseCacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseCacheWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// ResponseCache serves GET responses of a read-heavy public route from Redis.
+// The TTL is the int setting ttlKey in seconds, read per request so admins can
+// tune or disable (0) it at runtime. Entries are keyed by the group's
+// generation, campus, language, API version and URL, so cache.InvalidateResponses
+// of the group drops them all. Only 200 responses are stored; the cache being
+// off or down just runs the handler ("X-Cache" tells which happened). Place it
+// after the middleware that scopes the request to a campus.
+func ResponseCache(c *cache.Cache, settingsService *settings.Service, group, ttlKey string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ttl := time.Duration(settingsService.Int(ttlKey)) * time.Second
+		if ctx.Request.Method != http.MethodGet || ttl <= 0 || !c.Enabled() {
+			ctx.Next()
+			return
+		}
+
+		generation, err := c.Generation(ctx.Request.Context(), cache.ResponseGenerationKey(group))
+		if err != nil {
+			slog.WarnContext(ctx.Request.Context(), "response cache: read generation failed", "group", group, "error", err)
+			ctx.Next()
+			return
+		}
+		tenantID, _ := utils.TenantFromContext(ctx.Request.Context())
+		key := fmt.Sprintf("responses:%s:%d:%d:%s:%s:%s", group, generation, tenantID, utils.Language(ctx), utils.APIVersion(ctx), ctx.Request.URL.RequestURI())
+
+		var cached cachedResponse
+		if c.GetJSON(ctx.Request.Context(), responseCacheName, key, &cached) {
+			ctx.Header("X-Cache", "HIT")
+			ctx.Data(http.StatusOK, cached.ContentType, cached.Body)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Header("X-Cache", "MISS")
+		writer := &responseCacheWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+		ctx.Writer = writer.ResponseWriter
+
+		if writer.Status() == http.StatusOK {
+			c.SetJSON(ctx.Request.Context(), key, cachedResponse{
+				ContentType: writer.Header().Get("Content-Type"),
+				Body:        writer.body.Bytes(),
+			}, ttl)
+		}
+	}
+}
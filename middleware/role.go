@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"wallet-point/utils"
 
@@ -19,11 +19,11 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		}
 
 		userRole := role.(string)
-		log.Printf("DEBUG ROLE: '%s' checking against %v", userRole, allowedRoles)
+		slog.DebugContext(c.Request.Context(), "role check", "role", userRole, "allowed", allowedRoles)
 
-		// Check if user role is in allowed roles
+		// Check if user role is in allowed roles; super admins may do anything an admin can
 		for _, allowedRole := range allowedRoles {
-			if userRole == allowedRole {
+			if userRole == allowedRole || (userRole == "superadmin" && allowedRole == "admin") {
 				c.Next()
 				return
 			}
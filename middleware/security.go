@@ -1,18 +1,32 @@
 package middleware
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 )
 
+// SecurityConfig holds the configurable security headers
+type SecurityConfig struct {
+	ContentSecurityPolicy string
+	FrameOptions          string
+	// HSTSMaxAge of 0 disables Strict-Transport-Security
+	HSTSMaxAge int
+}
+
 // SecurityHeaders adds common security headers to the response
-func SecurityHeaders() gin.HandlerFunc {
+func SecurityHeaders(cfg SecurityConfig) gin.HandlerFunc {
+	hsts := "max-age=" + strconv.Itoa(cfg.HSTSMaxAge) + "; includeSubDomains"
+
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("X-Content-Type-Options", "nosniff")
-		c.Writer.Header().Set("X-Frame-Options", "DENY")
+		c.Writer.Header().Set("X-Frame-Options", cfg.FrameOptions)
 		c.Writer.Header().Set("X-XSS-Protection", "1; mode=block")
-		c.Writer.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; font-src 'self';")
+		c.Writer.Header().Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
 		c.Writer.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-		c.Writer.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		if cfg.HSTSMaxAge > 0 {
+			c.Writer.Header().Set("Strict-Transport-Security", hsts)
+		}
 
 		c.Next()
 	}
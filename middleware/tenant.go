@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scopeTenant scopes the request to the campus of the token: handlers read it as
+// c.GetUint("tenant_id") and the database queries run with the request context only
+// see that campus's users, wallets and products. Super admins are unscoped unless
+// they pick a campus with the X-Tenant-ID header. It reports false after answering
+// a bad header.
+func scopeTenant(c *gin.Context, claims *utils.JWTClaims) bool {
+	tenantID := claims.TenantID
+	if tenantID == 0 {
+		tenantID = utils.DefaultTenantID
+	}
+
+	if claims.Role == "superadmin" {
+		tenantID = 0
+		if header := c.GetHeader("X-Tenant-ID"); header != "" {
+			id, err := strconv.ParseUint(header, 10, 32)
+			if err != nil || id == 0 {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid X-Tenant-ID header", nil)
+				return false
+			}
+			tenantID = uint(id)
+		}
+	}
+
+	c.Set("tenant_id", tenantID)
+	if tenantID != 0 {
+		c.Request = c.Request.WithContext(utils.WithTenant(c.Request.Context(), tenantID))
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout cancels the request context after def, or after the duration of the
+// longest route prefix in routes that matches the request's route pattern; 0 means
+// no deadline. Repositories pass the context to GORM, so a timed out or abandoned
+// request (the client disconnecting cancels it too) stops its queries instead of
+// holding a goroutine and a connection until they finish.
+func Timeout(def time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := def
+		matched := -1
+		for prefix, d := range routes {
+			if len(prefix) > matched && strings.HasPrefix(c.FullPath(), prefix) {
+				timeout, matched = d, len(prefix)
+			}
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion tags requests of a versioned route group. A version already recorded
+// on the request (a /api/v2 call served by a v1 handler) takes precedence.
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if utils.RequestedAPIVersion(c.Request) == "" {
+			c.Request = utils.WithAPIVersion(c.Request, version)
+		}
+		c.Header("X-API-Version", utils.APIVersion(c))
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"wallet-point/docs"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// openAPISpec converts the swag-generated Swagger 2.0 document into OpenAPI 3
+func openAPISpec() ([]byte, error) {
+	var doc2 openapi2.T
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &doc2); err != nil {
+		return nil, err
+	}
+
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative server so "Try it out" targets whichever host serves the docs
+	doc3.Servers = openapi3.Servers{{URL: docs.SwaggerInfo.BasePath}}
+
+	return json.Marshal(doc3)
+}
+
+// registerDocs serves Swagger UI at /docs, the OpenAPI 3 spec at /docs/openapi.json
+// and the original Swagger 2.0 spec at /docs/doc.json
+func registerDocs(r *gin.Engine) {
+	spec, err := openAPISpec()
+	if err != nil {
+		slog.Error("docs: OpenAPI 3 conversion failed", "error", err)
+	}
+
+	ui := ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("/docs/openapi.json"))
+
+	r.GET("/docs/*any", func(c *gin.Context) {
+		switch c.Param("any") {
+		case "/":
+			c.Redirect(http.StatusMovedPermanently, "/docs/index.html")
+		case "/openapi.json":
+			if spec == nil {
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+		default:
+			ui(c)
+		}
+	})
+
+	// Old Swagger UI location
+	r.GET("/swagger/*any", func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, "/docs"+c.Param("any"))
+	})
+}
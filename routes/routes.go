@@ -1,39 +1,125 @@
 package routes
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+	"wallet-point/config"
+	"wallet-point/internal/adminwatch"
+	"wallet-point/internal/alert"
+	"wallet-point/internal/anomaly"
+	"wallet-point/internal/attendance"
 	"wallet-point/internal/audit"
 	"wallet-point/internal/auth"
+	"wallet-point/internal/batch"
+	"wallet-point/internal/cache"
+	"wallet-point/internal/campusrpc"
+	"wallet-point/internal/catalogsync"
+	"wallet-point/internal/events"
+	"wallet-point/internal/faculty"
+	"wallet-point/internal/feature"
+	"wallet-point/internal/fulfillment"
+	"wallet-point/internal/graph"
+	"wallet-point/internal/health"
+	"wallet-point/internal/idempotency"
+	"wallet-point/internal/jobs"
+	"wallet-point/internal/kiosk"
+	"wallet-point/internal/library"
+	"wallet-point/internal/lms"
 	"wallet-point/internal/marketplace"
+	"wallet-point/internal/messaging"
+	"wallet-point/internal/metrics"
 	"wallet-point/internal/mission"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/orders"
+	"wallet-point/internal/partition"
+	"wallet-point/internal/payment"
+	"wallet-point/internal/pointgift"
+	"wallet-point/internal/pos"
+	"wallet-point/internal/printquota"
+	"wallet-point/internal/promotion"
+	"wallet-point/internal/quota"
+	"wallet-point/internal/report"
+	"wallet-point/internal/resilience"
+	"wallet-point/internal/saga"
+	"wallet-point/internal/scheduler"
+	"wallet-point/internal/settings"
+	"wallet-point/internal/storage"
+	"wallet-point/internal/storefront"
+	"wallet-point/internal/telegram"
+	"wallet-point/internal/tenant"
+	"wallet-point/internal/ticketing"
+	"wallet-point/internal/topuprequest"
 	"wallet-point/internal/transfer"
 	"wallet-point/internal/user"
 	"wallet-point/internal/wallet"
+	"wallet-point/internal/webhook"
 	"wallet-point/middleware"
 	"wallet-point/utils"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
-
-	// Swagger imports
-	_ "wallet-point/docs"
-
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry int) {
+// SetupRoutes registers the HTTP API on r and returns the campus gRPC server,
+// or nil when GRPC_PORT is not set
+func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg *config.Config) *campusrpc.Server {
 	// Apply global middleware
-	r.Use(middleware.CORS(allowedOrigins))
+	r.Use(middleware.Metrics())
+	r.Use(otelgin.Middleware(cfg.OTelServiceName))
+	r.Use(middleware.CORS(middleware.NewCORSConfig(cfg.AllowedOrigins, cfg.CORSAllowedHeaders, cfg.CORSExposedHeaders, cfg.CORSMaxAge)))
 	r.Use(middleware.Logger())
-	r.Use(middleware.SecurityHeaders())
+	if cfg.AccessLog != "" {
+		accessLog, err := middleware.OpenRequestLog(cfg.AccessLog)
+		if err != nil {
+			log.Fatal("❌ Failed to open access log:", err)
+		}
+		r.Use(middleware.RequestLog(accessLog, cfg.AccessLogMaxBodyBytes))
+	}
+	r.Use(middleware.Timeout(cfg.RequestTimeout, map[string]time.Duration{
+		"/api/v1/admin/reports":      cfg.ReportTimeout,
+		"/api/v1/users/me/analytics": cfg.ReportTimeout,
+		"/api/v1/marketplace/live":   0, // long-lived event stream
+		"/api/v1/jobs/:id/download":  0, // export files can be large
+		"/uploads/*key":              0,
+	}))
+	r.Use(middleware.SecurityHeaders(middleware.SecurityConfig{
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+		FrameOptions:          cfg.FrameOptions,
+		HSTSMaxAge:            cfg.HSTSMaxAge,
+	}))
+	r.Use(middleware.Locale())
 	r.Use(middleware.IPBasedRateLimiter())
 
-	r.Static("/uploads", "./public/uploads")
-
-	api := r.Group("/api/v1")
-
-	// Global Upload Endpoint
-	api.POST("/upload", middleware.AuthMiddleware(), utils.HandleFileUpload)
+	// Uploads and generated files (STORAGE_DRIVER); bucket drivers redirect
+	// /uploads/<key> to a signed link
+	files, err := storage.New(storage.Config{
+		Driver:    cfg.StorageDriver,
+		LocalPath: cfg.UploadPath,
+		Secret:    cfg.JWTSecret,
+		Bucket:    cfg.StorageBucket,
+		Region:    cfg.StorageRegion,
+		Endpoint:  cfg.StorageEndpoint,
+		AccessKey: cfg.StorageAccessKey,
+		SecretKey: cfg.StorageSecretKey,
+		Policy: resilience.Policy{
+			Name:        "storage",
+			Timeout:     time.Minute,
+			MaxAttempts: 3,
+			BaseDelay:   200 * time.Millisecond,
+			MaxDelay:    2 * time.Second,
+			Breaker:     resilience.NewBreaker("storage", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+		},
+	})
+	if err != nil {
+		log.Fatal("❌ Invalid storage configuration:", err)
+	}
+	fileHandler := storage.NewHandler(files, cfg.StorageURLTTL)
+	r.GET("/uploads/*key", fileHandler.Serve)
+	r.HEAD("/uploads/*key", fileHandler.Serve)
 
 	// Initialize repositories
 	authRepo := auth.NewAuthRepository(db)
@@ -42,26 +128,403 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 	marketplaceRepo := marketplace.NewMarketplaceRepository(db)
 	auditRepo := audit.NewAuditRepository(db)
 	missionRepo := mission.NewMissionRepository(db)
+	messagingRepo := messaging.NewMessagingRepository(db)
+	notificationRepo := notification.NewNotificationRepository(db)
+	reportRepo := report.NewReportRepository(db)
+	flagRepo := feature.NewFlagRepository(db)
+	tenantRepo := tenant.NewTenantRepository(db)
+	idempotencyRepo := idempotency.NewRepository(db)
+	jobRepo := jobs.NewRepository(db)
+	schedulerRepo := scheduler.NewRepository(db)
+
+	// Totals of the large admin listings are counted at most once per LIST_COUNT_TTL_SECONDS
+	listCounts := utils.NewCountCache(cfg.ListCountTTL)
+	walletRepo.SetCountCache(listCounts)
+	auditRepo.SetCountCache(listCounts)
+	webhookRepo := webhook.NewRepository(db)
+
+	// Product catalog cache (disabled when REDIS_URL is empty)
+	productCache, err := cache.New(cfg.RedisURL, "walletpoint:")
+	if err != nil {
+		log.Fatal("❌ Invalid Redis configuration:", err)
+	}
 
 	// Initialize services
-	authService := auth.NewAuthService(authRepo, jwtExpiry)
+	eventBus := events.NewBus()
+	// Operational alerts to Slack/Discord; nil (off) while ALERT_WEBHOOK_URL is empty
+	var alerts *alert.Service
+	if cfg.AlertWebhookURL != "" {
+		alerts = alert.NewService(cfg.AlertWebhookURL, cfg.AppEnv, cfg.AlertCooldown, cfg.AlertMaxPerHour, resilience.Policy{
+			Name:        "alerts",
+			Timeout:     10 * time.Second,
+			MaxAttempts: 3,
+			BaseDelay:   time.Second,
+			MaxDelay:    10 * time.Second,
+			Breaker:     resilience.NewBreaker("alerts", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+		})
+	}
+	jobQueue := jobs.NewQueue(jobRepo, cfg.JobPollInterval, cfg.JobTimeout)
+	jobQueue.SetStorage(files)
+	jobQueue.SetAlerts(alerts)
+	outbox := events.NewOutbox(db, jobQueue)
+	flagService := feature.NewFlagService(flagRepo, cfg.AppEnv, 30*time.Second)
+	// The environment keeps configuring the defaults of settings that used to be env-only
+	settingsService := settings.NewService(settings.NewRepository(db), 30*time.Second)
+	settingsService.SetDefault(settings.LowStockThreshold, cfg.LowStockThreshold)
+	settingsService.SetDefault(settings.CartItemTTLDays, cfg.CartItemTTLDays)
+	if err := flagService.EnsureDefaults(); err != nil {
+		slog.Error("feature flags: seeding defaults failed", "error", err)
+	}
+	tenantService := tenant.NewTenantService(tenantRepo)
+	if err := tenantService.EnsureDefault(); err != nil {
+		slog.Error("tenants: creating the default campus failed", "error", err)
+	}
+	authService := auth.NewAuthService(authRepo, cfg.JWTExpiryHours)
+	authService.SetTenants(tenantService)
+	if cfg.LDAPURL != "" {
+		authService.SetDirectory(auth.NewLDAPDirectory(auth.LDAPConfig{
+			URL:           cfg.LDAPURL,
+			StartTLS:      cfg.LDAPStartTLS,
+			BindDN:        cfg.LDAPBindDN,
+			BindPassword:  cfg.LDAPBindPassword,
+			BaseDN:        cfg.LDAPBaseDN,
+			UserFilter:    cfg.LDAPUserFilter,
+			NIPAttribute:  cfg.LDAPNIPAttribute,
+			GroupRoles:    cfg.LDAPGroupRoles,
+			Timeout:       cfg.LDAPTimeout,
+			FallbackLocal: cfg.LDAPFallbackLocal,
+		}))
+	}
 	userService := user.NewUserService(userRepo)
+	userService.SetJobQueue(jobQueue, cfg.AnonymizeAfterYears)
 	walletService := wallet.NewWalletService(walletRepo, db)
 	walletService.SetAuthService(authService) // Inject for PIN verification
+	walletService.SetJobQueue(jobQueue)
+	webhookService := webhook.NewService(webhookRepo, db, jobQueue, cfg.WebhookTimeout, cfg.WebhookMaxAttempts)
+	webhookService.SetCircuitBreaker(cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown)
+	walletService.SetOutbox(outbox)
+	walletService.SetAlerts(alerts)
+
+	notificationService := notification.NewNotificationService(
+		notificationRepo,
+		notification.WithPolicy(
+			notification.NewEmailSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, cfg.SMTPTimeout),
+			// The outbox retries with backoff, so one attempt per dispatch
+			resilience.Policy{
+				Name:        "smtp",
+				MaxAttempts: 1,
+				Breaker:     resilience.NewBreaker("smtp", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+			},
+		),
+	)
+	notificationService.StartDispatcher(30 * time.Second)
 
 	marketplaceService := marketplace.NewMarketplaceService(marketplaceRepo, walletService, authService, db)
+	marketplaceService.SetOutbox(outbox)
+	// A purchase step that made no progress for 5 minutes was abandoned by a dead instance
+	sagaCoordinator := saga.NewCoordinator(saga.NewRepository(db), db, 5*time.Minute)
+	marketplaceService.SetSagas(sagaCoordinator)
+	marketplaceService.SetSettings(settingsService)
+	marketplaceService.StartStockAlertWatcher(15 * time.Minute)
+	marketplaceService.SetCache(productCache, cfg.ProductCacheTTL)
+	marketplaceService.SetWebhooks(webhookService)
+	promotionService := promotion.NewService(promotion.NewRepository(db), db)
+	marketplaceService.SetPromotions(promotionService)
+	orderService := orders.NewService(orders.NewRepository(db))
+	marketplaceService.SetOrders(orderService)
+	if cfg.LiveUpdatesMaxClients > 0 {
+		marketplaceService.SetEventBus(eventBus, cfg.LiveUpdatesMaxClients)
+	}
+	walletService.OnProductStockChange(marketplaceService.ProductChanged)
 	auditService := audit.NewAuditService(auditRepo)
 	missionService := mission.NewMissionService(missionRepo, walletService, db)
 	transferService := transfer.NewService(walletRepo, walletService, authService, db)
+	messagingService := messaging.NewMessagingService(
+		messagingRepo,
+		messaging.NewProviders(cfg.MessagingProvider, cfg.MessagingAPIURL, cfg.MessagingAPIKey, cfg.MessagingSender, resilience.Policy{
+			Name:        "messaging",
+			Timeout:     cfg.MessagingTimeout,
+			MaxAttempts: cfg.MessagingMaxAttempts,
+			BaseDelay:   500 * time.Millisecond,
+			MaxDelay:    5 * time.Second,
+			Breaker:     resilience.NewBreaker("messaging", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+		}),
+		authService,
+		messaging.Config{
+			CostPerMessage:            cfg.MessagingCostPerMessage,
+			DailyBudget:               cfg.MessagingDailyBudget,
+			UserDailyLimit:            cfg.MessagingUserDailyLimit,
+			LargeTransactionThreshold: cfg.LargeTransactionThreshold,
+		},
+	)
+	transferService.SetFeatureFlags(flagService)
+	transferService.SetSettings(settingsService)
+	pointGiftService := pointgift.NewService(pointgift.NewRepository(db), db, walletService, authService, transferService, settingsService)
+	pointGiftService.SetFeatureFlags(flagService)
+	pointGiftService.SetNotifications(notificationService)
+	reportService := report.NewReportService(reportRepo)
+	reportService.StartAggregator()
+	reportService.EnableSubscriptions(notificationService, cfg.LargeTransactionThreshold)
+	reportService.StartSubscriptionDispatcher(15 * time.Minute)
+	reportService.SetJobQueue(jobQueue, files)
+	reportService.EnableJournal(report.JournalConfig{Accounts: cfg.AccountingAccounts, PointValue: cfg.AccountingPointValue})
+	reportService.SetAlerts(alerts)
+	idempotencyService := idempotency.NewService(idempotencyRepo, cfg.IdempotencyTTL)
+	idempotencyService.StartCleanup(time.Hour)
+	batchService := batch.NewService()
+	paymentService := payment.NewService(payment.NewRepository(db), db, walletService, payment.Config{
+		PointPrice: cfg.TopUpPointPrice,
+		MinPoints:  cfg.TopUpMinPoints,
+		MaxPoints:  cfg.TopUpMaxPoints,
+		Expiry:     cfg.TopUpExpiry,
+	})
+	paymentService.SetNotifications(notificationService)
+	paymentService.SetAlerts(alerts)
+	for _, provider := range cfg.PaymentProviders {
+		switch provider {
+		case "midtrans":
+			paymentService.RegisterProvider(payment.NewMidtrans(cfg.MidtransServerKey, cfg.MidtransProduction, resilience.Policy{
+				Name:        "midtrans",
+				Timeout:     cfg.PaymentTimeout,
+				MaxAttempts: 3,
+				BaseDelay:   500 * time.Millisecond,
+				MaxDelay:    5 * time.Second,
+				Breaker:     resilience.NewBreaker("midtrans", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+			}))
+		case "manual":
+			paymentService.RegisterProvider(&payment.ManualTransfer{
+				Bank:    cfg.BankTransferBank,
+				Account: cfg.BankTransferAccount,
+				Holder:  cfg.BankTransferHolder,
+			})
+		}
+	}
+	topUpRequestService := topuprequest.NewService(topuprequest.NewRepository(db), db, walletService)
+	topUpRequestService.SetNotifications(notificationService)
+	lmsService := lms.NewService(lms.NewRepository(db), db, walletService, cfg.LMSWebhookSecret)
+	if cfg.LMSFeedURL != "" {
+		lmsService.SetClient(lms.NewClient(cfg.LMSFeedURL, cfg.LMSFeedToken, resilience.Policy{
+			Name:        "lms",
+			Timeout:     cfg.LMSTimeout,
+			MaxAttempts: 3,
+			BaseDelay:   time.Second,
+			MaxDelay:    10 * time.Second,
+			Breaker:     resilience.NewBreaker("lms", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+		}))
+	}
+	attendanceService := attendance.NewService(attendance.NewRepository(db), db, walletService, cfg.AttendanceWebhookSecret, cfg.AttendancePoints)
+	libraryService := library.NewService(library.NewRepository(db), db, walletService, authService, jobQueue, cfg.LibrarySecret, cfg.LibraryCallbackURL, resilience.Policy{
+		Name:    "library",
+		Timeout: cfg.LibraryTimeout,
+		Breaker: resilience.NewBreaker("library", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+	})
+	libraryService.SetNotifications(notificationService)
+	posService := pos.NewService(pos.NewRepository(db), db, walletService, pos.Config{
+		MaxCharge:  cfg.POSMaxCharge,
+		DailyLimit: cfg.POSDailyLimit,
+		VoidWindow: cfg.POSVoidWindow,
+		PayCodeTTL: cfg.POSPayCodeTTL,
+	})
+	posService.SetNotifications(notificationService)
+	kioskService := kiosk.NewService(kiosk.NewRepository(db), marketplaceService, cfg.KioskOrderTTL)
+	printQuotaService := printquota.NewService(printquota.NewRepository(db), db, walletService, marketplaceService, jobQueue, cfg.PrintQuotaMaxAttempts)
+	printQuotaService.SetNotifications(notificationService)
+	ticketService := ticketing.NewService(ticketing.NewRepository(db), db, marketplaceService, walletService, cfg.TicketClaimWindow)
+	ticketService.SetNotifications(notificationService)
+	facultyService := faculty.NewService(faculty.NewRepository(db), marketplaceService)
+	fulfillmentService := fulfillment.NewService(fulfillment.NewRepository(db), db, marketplaceService)
+	anomalyService := anomaly.NewService(anomaly.NewRepository(db), db, settingsService)
+	anomalyService.SetAlerts(alerts)
+	adminWatchService := adminwatch.NewService(adminwatch.NewRepository(db), settingsService, notificationService)
+	if cfg.PrintServerURL != "" {
+		// Attempts are paced by the job queue, so the policy makes a single one
+		printQuotaService.SetClient(printquota.NewClient(cfg.PrintServerURL, cfg.PrintServerToken, resilience.Policy{
+			Name:        "print_server",
+			Timeout:     cfg.PrintServerTimeout,
+			MaxAttempts: 1,
+			Breaker:     resilience.NewBreaker("print_server", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+		}))
+	}
+	catalogSyncService := catalogsync.NewService(catalogsync.NewRepository(db), db, marketplaceService)
+	if cfg.CatalogSheetID != "" {
+		client, err := catalogsync.NewClient(cfg.GoogleServiceAccountFile, cfg.CatalogSheetID, resilience.Policy{
+			Name:        "google_sheets",
+			Timeout:     cfg.CatalogSheetTimeout,
+			MaxAttempts: 3,
+			BaseDelay:   time.Second,
+			MaxDelay:    10 * time.Second,
+			Breaker:     resilience.NewBreaker("google_sheets", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+		})
+		if err != nil {
+			slog.Error("catalog sync: loading the service account failed, sync disabled", "error", err)
+		} else {
+			catalogSyncService.SetClient(client, cfg.CatalogSheetTab)
+		}
+	}
+	telegramService := telegram.NewService(telegram.NewRepository(db), db, walletService, notificationService, telegram.Config{
+		BotUsername:   cfg.TelegramBotUsername,
+		WebhookSecret: cfg.TelegramWebhookSecret,
+		LinkCodeTTL:   cfg.TelegramLinkCodeTTL,
+	})
+	if cfg.TelegramBotToken != "" {
+		telegramClient := telegram.NewClient(cfg.TelegramBotToken, resilience.Policy{
+			Name:    "telegram",
+			Timeout: cfg.TelegramTimeout,
+			// The outbox retries with backoff, so one attempt per dispatch
+			MaxAttempts: 1,
+			Breaker:     resilience.NewBreaker("telegram", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+		})
+		telegramService.SetClient(telegramClient)
+		notificationService.RegisterSender(telegram.Channel, telegramClient)
+	}
+
+	// Follow-ups of orders, ledger entries and low stock run as domain event
+	// subscribers, each retried on its own
+	outbox.Subscribe(events.EventOrderPlaced, "audit", marketplace.AuditOrderPlaced(auditService))
+	outbox.Subscribe(events.EventOrderPlaced, "webhooks", webhookService.OnOrderPlaced)
+	outbox.Subscribe(events.EventOrderPlaced, "telegram", telegramService.OnOrderPlaced)
+	outbox.Subscribe(events.EventOrderPlaced, "notifications", notificationService.OnOrderPlaced)
+	outbox.Subscribe(events.EventPointsDebited, "webhooks", webhookService.OnPointsMoved)
+	outbox.Subscribe(events.EventPointsCredited, "webhooks", webhookService.OnPointsMoved)
+	outbox.Subscribe(events.EventPointsDebited, "messaging", messagingService.OnPointsDebited)
+	outbox.Subscribe(events.EventStockLow, "notifications", notificationService.OnStockLow)
+	outbox.Subscribe(events.EventBackInStock, "notifications", notificationService.OnBackInStock)
+	outbox.Subscribe(events.EventQuestionAnswered, "notifications", notificationService.OnQuestionAnswered)
+	events.SubscribeAnalytics(outbox)
+
+	storefrontService := storefront.NewService(storefront.NewRepository(db), marketplaceService, storefront.Config{
+		RateLimit:  cfg.StorefrontRateLimit,
+		ShowPrices: cfg.StorefrontShowPrices,
+	})
+	partitions := partition.NewManager(db, cfg.PartitionMonthsAhead, cfg.PartitionRetention)
+	partitions.OnPrune("audit_logs", func(ctx context.Context, from, to time.Time) error {
+		_, err := auditService.Archive(ctx, files, from, to)
+		return err
+	})
+
+	// Job handlers are registered above by the services that own them
+	jobQueue.Start(cfg.JobWorkers)
+
+	// Cron jobs - listed on /admin/scheduler/jobs, switched off with SCHEDULER_DISABLED_JOBS
+	cronScheduler := scheduler.NewScheduler(schedulerRepo, cfg.SchedulerDisabledJobs, cfg.JobTimeout)
+	cronJobs := []struct {
+		name, schedule, description string
+		run                         scheduler.JobFunc
+	}{
+		{"cart_cleanup", "0 3 * * *", "Purge stale and removed cart items and items of inactive products", func(ctx context.Context) (string, error) {
+			return marketplaceService.CleanupCarts(ctx, settingsService.Int(settings.CartItemTTLDays))
+		}},
+		{"point_expiry", "30 0 1 * *", "Expire unspent points older than POINT_EXPIRY_MONTHS", func(ctx context.Context) (string, error) {
+			return walletService.ExpirePoints(ctx, cfg.PointExpiryMonths)
+		}},
+		{"balance_snapshots", "55 23 * * *", "Store the daily balance of every wallet", walletService.SnapshotBalances},
+		{"notification_digest", "0 7 * * *", "Email users their unread notifications of the last day", func(ctx context.Context) (string, error) {
+			return notificationService.SendUnreadDigests(ctx, time.Now().Add(-24*time.Hour))
+		}},
+		{"storage_cleanup", "15 4 * * *", "Delete export files older than EXPORT_RETENTION_DAYS", func(ctx context.Context) (string, error) {
+			deleted, err := storage.Cleanup(ctx, files, storage.PrefixExports+"/", time.Now().AddDate(0, 0, -cfg.ExportRetentionDays))
+			return fmt.Sprintf("deleted %d export files", deleted), err
+		}},
+		{"topup_expiry", "*/15 * * * *", "Expire top-ups whose payment did not arrive within TOPUP_EXPIRY_HOURS", paymentService.ExpirePending},
+		{"lms_sync", "*/10 * * * *", "Credit course and quiz completions read from LMS_FEED_URL", lmsService.Poll},
+		{"pos_pay_code_cleanup", "45 3 * * *", "Delete canteen pay codes that expired over a day ago", posService.CleanupPayCodes},
+		{"point_gifts", "*/5 * * * *", "Announce scheduled points gifts on their delivery date and return gifts left unclaimed to their senders", pointGiftService.RunScheduled},
+		{"kiosk_order_expiry", "*/10 * * * *", "Close kiosk orders nobody confirmed within KIOSK_ORDER_TTL_SECONDS", kioskService.ExpireOrders},
+		{"ticket_waitlist_offers", "*/5 * * * *", "Offer seats of waitlist offers not claimed within TICKET_CLAIM_WINDOW_MINUTES to the next students in line", ticketService.ExpireOffers},
+		{"saga_recovery", "*/5 * * * *", "Roll back purchases abandoned mid-way, finish delivered ones and retry failed rollbacks", sagaCoordinator.Recover},
+		{"domain_event_cleanup", "20 4 * * *", "Delete domain events older than EVENT_RETENTION_DAYS", func(ctx context.Context) (string, error) {
+			return outbox.Cleanup(ctx, cfg.EventRetentionDays)
+		}},
+		{"accounting_journal", "0 2 1 * *", "Queue last month's accounting journal export of every active campus", func(ctx context.Context) (string, error) {
+			tenants, err := tenantService.GetTenants()
+			if err != nil {
+				return "", err
+			}
+			var tenantIDs []uint
+			for _, t := range tenants {
+				if t.Active {
+					tenantIDs = append(tenantIDs, t.ID)
+				}
+			}
+			queued, err := reportService.QueueMonthlyJournals(tenantIDs, time.Now())
+			return fmt.Sprintf("queued %d journal exports", queued), err
+		}},
+		{"flagged_transaction_alerts", "*/5 * * * *", "Alert the operations channel of failed and large wallet transactions of the last 5 minutes", reportService.AlertFlaggedTransactions},
+		{"admin_activity_watch", "*/5 * * * *", "Alert super admins of admins taking an unusual number of price changes, manual credits or wallet resets", adminWatchService.Watch},
+		{"anomaly_detection", "5 * * * *", "Flag spending far above each student's norm and night wallet activity from new IP addresses of the last hour for review", anomalyService.Detect},
+		{"catalog_sheet_sync", "*/15 * * * *", "Two-way sync of product names, prices and status with the catalog Google Sheet", catalogSyncService.RunScheduled},
+		{"telegram_link_code_cleanup", "50 3 * * *", "Delete Telegram link codes that expired over a day ago", telegramService.CleanupLinkCodes},
+		{"partition_maintenance", "30 1 * * *", "Create upcoming monthly partitions and drop those past PARTITION_RETENTION", partitions.Maintain},
+	}
+	for _, job := range cronJobs {
+		if err := cronScheduler.Register(job.name, job.schedule, job.description, job.run); err != nil {
+			log.Fatal("❌ Invalid scheduled job:", err)
+		}
+	}
+	cronScheduler.Start()
+
+	// Breaking response-shape changes ship as a /api/v2 group registering only the
+	// changed endpoints; VersionFallback serves the rest of /api/v2 from v1.
+	// During maintenance writes are refused, apart from the allow-listed ones.
+	api := r.Group("/api/v1", middleware.APIVersion("v1"), middleware.Maintenance(flagService, middleware.MaintenanceConfig{
+		Forced:       cfg.MaintenanceMode,
+		AllowedPaths: cfg.MaintenanceAllowedPaths,
+		AllowedRoles: cfg.MaintenanceAllowedRoles,
+	}))
+
+	// Global Upload Endpoint
+	api.POST("/upload", middleware.AuthMiddleware(), fileHandler.Upload)
+
+	// Mutating requests with an Idempotency-Key header are safe to retry
+	idempotent := middleware.Idempotency(idempotencyService)
+
+	// Large JSON and export responses are compressed; added per group so the small
+	// auth responses and the live event stream are left alone
+	compress := func(c *gin.Context) { c.Next() }
+	if cfg.CompressionMinBytes > 0 {
+		compress = middleware.Compress(cfg.CompressionMinBytes)
+	}
 
 	// Initialize handlers
 	authHandler := auth.NewAuthHandler(authService, auditService)
 	userHandler := user.NewUserHandler(userService, auditService)
 	walletHandler := wallet.NewWalletHandler(walletService, auditService)
-	marketplaceHandler := marketplace.NewMarketplaceHandler(marketplaceService, auditService)
+	marketplaceHandler := marketplace.NewMarketplaceHandler(marketplaceService, files, auditService)
 	auditHandler := audit.NewAuditHandler(auditService)
-	missionHandler := mission.NewMissionHandler(missionService, auditService)
+	missionHandler := mission.NewMissionHandler(missionService, files, auditService)
 	transferHandler := transfer.NewHandler(transferService, auditService)
+	messagingHandler := messaging.NewMessagingHandler(messagingService, auditService)
+	notificationHandler := notification.NewNotificationHandler(notificationService, auditService)
+	reportHandler := report.NewReportHandler(reportService, auditService)
+	flagHandler := feature.NewFlagHandler(flagService, auditService)
+	settingsHandler := settings.NewSettingsHandler(settingsService, auditService)
+	promotionHandler := promotion.NewPromotionHandler(promotionService, auditService)
+	orderHandler := orders.NewOrderHandler(orderService)
+	pointGiftHandler := pointgift.NewPointGiftHandler(pointGiftService, auditService)
+	quotaHandler := quota.NewQuotaHandler(quota.NewService(walletService, marketplaceService, posService, transferService))
+	tenantHandler := tenant.NewTenantHandler(tenantService, auditService)
+	catalogSyncHandler := catalogsync.NewCatalogSyncHandler(catalogSyncService, auditService)
+	telegramHandler := telegram.NewTelegramHandler(telegramService, auditService)
+	jobHandler := jobs.NewJobHandler(jobQueue, fileHandler, auditService)
+	sagaHandler := saga.NewSagaHandler(sagaCoordinator)
+	webhookHandler := webhook.NewWebhookHandler(webhookService, auditService)
+	schedulerHandler := scheduler.NewSchedulerHandler(cronScheduler, auditService)
+	graphqlHandler := graph.NewGraphQLHandler(marketplaceService, walletService)
+	batchHandler := batch.NewBatchHandler(batchService)
+	paymentHandler := payment.NewPaymentHandler(paymentService, auditService)
+	topUpRequestHandler := topuprequest.NewTopUpRequestHandler(topUpRequestService, auditService)
+	lmsHandler := lms.NewLMSHandler(lmsService, auditService)
+	attendanceHandler := attendance.NewAttendanceHandler(attendanceService)
+	libraryHandler := library.NewLibraryHandler(libraryService, auditService)
+	posHandler := pos.NewPOSHandler(posService, auditService)
+	kioskHandler := kiosk.NewKioskHandler(kioskService, auditService)
+	storefrontHandler := storefront.NewStorefrontHandler(storefrontService, auditService)
+	printQuotaHandler := printquota.NewPrintQuotaHandler(printQuotaService)
+	ticketHandler := ticketing.NewTicketHandler(ticketService, auditService)
+	facultyHandler := faculty.NewStoreHandler(facultyService, auditService)
+	fulfillmentHandler := fulfillment.NewFulfillmentHandler(fulfillmentService, auditService)
+	anomalyHandler := anomaly.NewAnomalyHandler(anomalyService, auditService)
+	adminWatchHandler := adminwatch.NewAdminWatchHandler(adminWatchService)
 
 	// ========================================
 	// PUBLIC ROUTES
@@ -74,6 +537,59 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 		authGroup.PUT("/profile", middleware.AuthMiddleware(), authHandler.UpdateProfile)
 		authGroup.PUT("/password", middleware.AuthMiddleware(), authHandler.UpdatePassword)
 		authGroup.PUT("/pin", middleware.AuthMiddleware(), authHandler.UpdatePin)
+
+		// Phone verification & critical messages (SMS/WhatsApp)
+		authGroup.GET("/phone", middleware.AuthMiddleware(), messagingHandler.GetPhone)
+		authGroup.POST("/phone", middleware.AuthMiddleware(), middleware.AuthRateLimiter(), messagingHandler.RegisterPhone)
+		authGroup.POST("/phone/verify", middleware.AuthMiddleware(), messagingHandler.VerifyPhone)
+		authGroup.POST("/pin/reset", middleware.AuthMiddleware(), middleware.AuthRateLimiter(), messagingHandler.RequestPINReset)
+		authGroup.POST("/pin/reset/confirm", middleware.AuthMiddleware(), messagingHandler.ConfirmPINReset)
+	}
+
+	// ========================================
+	// NOTIFICATION ROUTES (all roles)
+	// ========================================
+	notificationGroup := api.Group("/notifications")
+	notificationGroup.Use(middleware.AuthMiddleware(), compress, idempotent)
+	{
+		notificationGroup.GET("", notificationHandler.GetAll)
+		notificationGroup.PATCH("/:id/read", notificationHandler.MarkRead)
+		notificationGroup.POST("/read-all", notificationHandler.MarkAllRead)
+	}
+
+	// ========================================
+	// TELEGRAM ACCOUNT LINK (all roles)
+	// ========================================
+	telegramGroup := api.Group("/telegram")
+	telegramGroup.Use(middleware.AuthMiddleware(), idempotent)
+	{
+		telegramGroup.POST("/link-code", telegramHandler.IssueLinkCode)
+		telegramGroup.GET("/link", telegramHandler.GetLink)
+		telegramGroup.DELETE("/link", telegramHandler.Unlink)
+	}
+
+	// ========================================
+	// CURRENT USER ROUTES (all roles)
+	// ========================================
+	usersGroup := api.Group("/users")
+	usersGroup.Use(middleware.AuthMiddleware(), compress)
+	{
+		usersGroup.GET("/me/analytics", reportHandler.GetMyAnalytics)
+		usersGroup.GET("/me/quotas", quotaHandler.GetMyQuotas)
+	}
+	api.GET("/features", middleware.AuthMiddleware(), flagHandler.GetMyFeatures)
+
+	// Several GET requests in one round trip (mobile home screen)
+	api.POST("/batch", middleware.AuthMiddleware(), batchHandler.Execute)
+
+	// ========================================
+	// BACKGROUND JOB STATUS (own jobs; admins see all)
+	// ========================================
+	jobsGroup := api.Group("/jobs")
+	jobsGroup.Use(middleware.AuthMiddleware(), compress)
+	{
+		jobsGroup.GET("/:id", jobHandler.GetByID)
+		jobsGroup.GET("/:id/download", jobHandler.Download)
 	}
 
 	// ========================================
@@ -82,13 +598,17 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 	adminGroup := api.Group("/admin")
 	adminGroup.Use(middleware.AuthMiddleware())
 	adminGroup.Use(middleware.RoleMiddleware("admin"))
+	adminGroup.Use(compress)
+	adminGroup.Use(idempotent)
 	{
 		// User Management
 		adminGroup.POST("/users", authHandler.Register)
 		adminGroup.GET("/users", userHandler.GetAll)
+		adminGroup.POST("/users/graduates", userHandler.UploadGraduates)
 		adminGroup.GET("/users/:id", userHandler.GetByID)
 		adminGroup.PUT("/users/:id", userHandler.Update)
-		adminGroup.DELETE("/users/:id", userHandler.Deactivate)
+		adminGroup.DELETE("/users/:id", userHandler.Delete)
+		adminGroup.POST("/users/:id/restore", userHandler.Restore)
 		adminGroup.PUT("/users/:id/password", userHandler.ChangePassword)
 
 		// Wallet Management
@@ -102,19 +622,190 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 		adminGroup.GET("/transactions", walletHandler.GetAllTransactions)
 		adminGroup.GET("/transfers", transferHandler.GetAllTransfers)
 
+		// Top-ups (bank transfers are confirmed here)
+		adminGroup.GET("/topups", paymentHandler.GetTopUps)
+		adminGroup.GET("/topups/:id", paymentHandler.GetTopUp)
+		adminGroup.POST("/topups/:id/confirm", paymentHandler.ConfirmTopUp)
+		adminGroup.POST("/topups/:id/reject", paymentHandler.RejectTopUp)
+		adminGroup.GET("/topup-requests", topUpRequestHandler.GetAll)
+		adminGroup.PATCH("/topup-requests/:id", topUpRequestHandler.Review)
+
 		// Marketplace Management
 		adminGroup.GET("/marketplace/transactions", marketplaceHandler.GetTransactions)
-		adminGroup.GET("/products", marketplaceHandler.GetAll)
+		adminGroup.GET("/marketplace/analytics", reportHandler.GetMarketplaceAnalytics)
+		adminGroup.GET("/products", middleware.ETag(), marketplaceHandler.GetAll)
 		adminGroup.POST("/products", marketplaceHandler.Create)
-		adminGroup.GET("/products/:id", marketplaceHandler.GetByID)
+		adminGroup.POST("/products/restock", marketplaceHandler.Restock)
+		adminGroup.GET("/products/:id", middleware.ETag(), marketplaceHandler.GetByID)
 		adminGroup.PUT("/products/:id", marketplaceHandler.Update)
 		adminGroup.DELETE("/products/:id", marketplaceHandler.Delete)
+		adminGroup.POST("/products/:id/restore", marketplaceHandler.Restore)
+
+		// Low-stock Alerts
+		adminGroup.GET("/stock-alerts", marketplaceHandler.GetStockAlerts)
+		adminGroup.POST("/stock-alerts/:id/acknowledge", marketplaceHandler.AcknowledgeStockAlert)
+		adminGroup.POST("/stock-alerts/:id/snooze", marketplaceHandler.SnoozeStockAlert)
+
+		// Product Q&A moderation
+		adminGroup.GET("/product-questions", marketplaceHandler.GetQuestions)
+		adminGroup.GET("/store-hours", marketplaceHandler.GetStoreHours)
+		adminGroup.PUT("/store-hours", marketplaceHandler.SetStoreHours)
+		adminGroup.POST("/product-questions/:id/answer", marketplaceHandler.AnswerQuestion)
+		adminGroup.POST("/product-questions/:id/reject", marketplaceHandler.RejectQuestion)
+
+		// Promotions
+		adminGroup.GET("/promotions", promotionHandler.GetAll)
+		adminGroup.POST("/promotions", promotionHandler.Create)
+		adminGroup.GET("/promotions/usage", promotionHandler.GetUsage)
+		adminGroup.PUT("/promotions/:id", promotionHandler.Update)
+		adminGroup.DELETE("/promotions/:id", promotionHandler.Delete)
+
+		// LMS course rewards
+		adminGroup.GET("/lms/courses", lmsHandler.GetRules)
+		adminGroup.POST("/lms/courses", lmsHandler.CreateRule)
+		adminGroup.PUT("/lms/courses/:id", lmsHandler.UpdateRule)
+		adminGroup.DELETE("/lms/courses/:id", lmsHandler.DeleteRule)
+		adminGroup.GET("/lms/events", lmsHandler.GetEvents)
+
+		// Attendance rewards
+		adminGroup.GET("/attendance/report", attendanceHandler.GetReport)
+
+		// Library fines
+		adminGroup.GET("/library/fines", libraryHandler.GetAll)
+
+		// Canteen POS
+		adminGroup.GET("/pos/terminals", posHandler.GetTerminals)
+		adminGroup.POST("/pos/terminals", posHandler.CreateTerminal)
+		adminGroup.PUT("/pos/terminals/:id", posHandler.UpdateTerminal)
+		adminGroup.POST("/pos/terminals/:id/rotate-key", posHandler.RotateKey)
+		adminGroup.GET("/pos/charges", posHandler.GetCharges)
+
+		// Kiosks
+		adminGroup.GET("/kiosks", kioskHandler.GetKiosks)
+		adminGroup.POST("/kiosks", kioskHandler.RegisterKiosk)
+		adminGroup.PUT("/kiosks/:id", kioskHandler.UpdateKiosk)
+		adminGroup.POST("/kiosks/:id/rotate-key", kioskHandler.RotateKey)
+		adminGroup.POST("/kiosks/:id/disable", kioskHandler.DisableKiosk)
+		adminGroup.POST("/kiosks/:id/enable", kioskHandler.EnableKiosk)
+
+		// Storefront API keys
+		adminGroup.GET("/storefront/keys", storefrontHandler.GetKeys)
+		adminGroup.POST("/storefront/keys", storefrontHandler.CreateKey)
+		adminGroup.PUT("/storefront/keys/:id", storefrontHandler.UpdateKey)
+		adminGroup.POST("/storefront/keys/:id/rotate-key", storefrontHandler.RotateKey)
+
+		// Print quota
+		adminGroup.GET("/print-quota/topups", printQuotaHandler.GetAll)
+
+		// Ticket sessions and check-in at the entrance
+		adminGroup.GET("/products/:id/sessions", ticketHandler.GetAllSessions)
+		adminGroup.POST("/products/:id/sessions", ticketHandler.CreateSession)
+		adminGroup.PUT("/ticket-sessions/:id", ticketHandler.UpdateSession)
+		adminGroup.POST("/tickets/check-in", ticketHandler.CheckIn)
+
+		// Faculty stores: branded sub-stores selling part of the catalog
+		adminGroup.GET("/faculty-stores", facultyHandler.GetAll)
+		adminGroup.POST("/faculty-stores", facultyHandler.Create)
+		adminGroup.PUT("/faculty-stores/:id", facultyHandler.Update)
+		adminGroup.PUT("/faculty-stores/:id/admins", facultyHandler.SetAdmins)
+
+		// Fulfillment queue of the store staff
+		adminGroup.GET("/fulfillment/queue", fulfillmentHandler.GetQueue)
+		adminGroup.POST("/fulfillment/:id/claim", fulfillmentHandler.Claim)
+		adminGroup.POST("/fulfillment/:id/release", fulfillmentHandler.Release)
+		adminGroup.POST("/fulfillment/:id/complete", fulfillmentHandler.Complete)
+
+		// Suspicious activity review queue
+		adminGroup.GET("/suspicious-activity", anomalyHandler.GetAll)
+		adminGroup.POST("/suspicious-activity/:id/review", anomalyHandler.Review)
 
 		// Audit Logs
 		adminGroup.GET("/audit-logs", auditHandler.GetAll)
 
 		// Admin Dashboard Stats
 		adminGroup.GET("/stats", walletHandler.GetAdminStats)
+
+		// Reports
+		adminGroup.GET("/reports/sales", reportHandler.GetSales)
+		adminGroup.GET("/reports/top-products", reportHandler.GetTopProducts)
+		adminGroup.GET("/reports/top-buyers", reportHandler.GetTopBuyers)
+		adminGroup.GET("/reports/categories", reportHandler.GetCategoryBreakdown)
+		adminGroup.GET("/reports/breakage", reportHandler.GetBreakage)
+		adminGroup.GET("/reports/retention", reportHandler.GetCohortRetention)
+		adminGroup.GET("/reports/transactions/volume", reportHandler.GetTransactionVolume)
+		adminGroup.POST("/reports/query", reportHandler.RunQuery)
+		adminGroup.GET("/reports/subscriptions", reportHandler.GetSubscriptions)
+		adminGroup.POST("/reports/subscriptions", reportHandler.CreateSubscription)
+		adminGroup.DELETE("/reports/subscriptions/:id", reportHandler.DeleteSubscription)
+		adminGroup.POST("/reports/exports", reportHandler.CreateExport)
+		adminGroup.POST("/reports/journal", reportHandler.CreateJournal)
+	}
+
+	// Deployment-wide settings and queues, shared by every campus: super admins only
+	platformGroup := adminGroup.Group("")
+	platformGroup.Use(middleware.RoleMiddleware("superadmin"))
+	{
+		// Campuses (tenants)
+		platformGroup.GET("/tenants", tenantHandler.GetAll)
+		platformGroup.POST("/tenants", tenantHandler.Create)
+		platformGroup.PUT("/tenants/:id", tenantHandler.Update)
+
+		platformGroup.POST("/wallet/reconcile", walletHandler.ReconcileBalances)
+
+		// Messaging (SMS/WhatsApp) spend monitoring
+		platformGroup.GET("/messaging/usage", messagingHandler.GetUsage)
+
+		// Notification Delivery Queue
+		platformGroup.GET("/notifications/outbox", notificationHandler.GetOutbox)
+		platformGroup.GET("/notifications/dead-letters", notificationHandler.GetDeadLetters)
+		platformGroup.POST("/notifications/dead-letters/:id/requeue", notificationHandler.RequeueDeadLetter)
+
+		// Feature Flags
+		platformGroup.GET("/feature-flags", flagHandler.GetAll)
+		platformGroup.PUT("/feature-flags/:key", flagHandler.Update)
+
+		// Runtime settings (limits and thresholds)
+		platformGroup.GET("/settings", settingsHandler.GetAll)
+		platformGroup.PUT("/settings/:key", settingsHandler.Update)
+		platformGroup.DELETE("/settings/:key", settingsHandler.Reset)
+
+		// Background Jobs
+		platformGroup.GET("/jobs", jobHandler.GetAll)
+		platformGroup.GET("/jobs/:id", jobHandler.GetByID)
+		platformGroup.POST("/jobs/:id/retry", jobHandler.Retry)
+
+		// Multi-step purchases and their rollbacks
+		platformGroup.GET("/sagas", sagaHandler.GetAll)
+
+		// Unusual volumes of sensitive admin actions
+		platformGroup.GET("/admin-activity-alerts", adminWatchHandler.GetAll)
+
+		// Outbound webhooks
+		platformGroup.GET("/webhooks", webhookHandler.GetAll)
+		platformGroup.GET("/webhooks/events", webhookHandler.GetEventTypes)
+		platformGroup.POST("/webhooks", webhookHandler.Create)
+		platformGroup.GET("/webhooks/:id", webhookHandler.GetByID)
+		platformGroup.PUT("/webhooks/:id", webhookHandler.Update)
+		platformGroup.DELETE("/webhooks/:id", webhookHandler.Delete)
+		platformGroup.POST("/webhooks/:id/rotate-secret", webhookHandler.RotateSecret)
+		platformGroup.POST("/webhooks/:id/test", webhookHandler.SendTest)
+		platformGroup.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries)
+		platformGroup.GET("/webhook-deliveries/:id", webhookHandler.GetDelivery)
+		platformGroup.POST("/webhook-deliveries/:id/redeliver", webhookHandler.Redeliver)
+
+		// Google Sheets catalog sync (the sheet spans all campuses)
+		platformGroup.POST("/catalog-sync/run", catalogSyncHandler.Run)
+		platformGroup.GET("/catalog-sync/conflicts", catalogSyncHandler.GetConflicts)
+		platformGroup.POST("/catalog-sync/conflicts/:id/resolve", catalogSyncHandler.ResolveConflict)
+
+		// Scheduled (cron) jobs
+		platformGroup.GET("/scheduler/jobs", schedulerHandler.GetJobs)
+		platformGroup.GET("/scheduler/runs", schedulerHandler.GetRuns)
+		platformGroup.POST("/scheduler/jobs/:name/run", schedulerHandler.RunNow)
+
+		// Runtime log level of this instance
+		platformGroup.GET("/log-level", utils.GetLogLevel)
+		platformGroup.PUT("/log-level", utils.UpdateLogLevel)
 	}
 
 	// ========================================
@@ -123,6 +814,8 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 	dosenGroup := api.Group("/dosen")
 	dosenGroup.Use(middleware.AuthMiddleware())
 	dosenGroup.Use(middleware.RoleMiddleware("dosen", "admin"))
+	dosenGroup.Use(compress)
+	dosenGroup.Use(idempotent)
 	{
 		// Mission & Task Management
 		dosenGroup.POST("/missions", missionHandler.CreateMission)
@@ -149,6 +842,8 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 	mahasiswaGroup := api.Group("/mahasiswa")
 	mahasiswaGroup.Use(middleware.AuthMiddleware())
 	mahasiswaGroup.Use(middleware.RoleMiddleware("mahasiswa"))
+	mahasiswaGroup.Use(compress)
+	mahasiswaGroup.Use(idempotent)
 	{
 		// Mission & Task Submission
 		mahasiswaGroup.GET("/missions", missionHandler.GetAllMissions)
@@ -157,19 +852,29 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 		mahasiswaGroup.GET("/submissions", missionHandler.GetAllSubmissions)
 
 		// Transfer Points
-		mahasiswaGroup.POST("/transfer", transferHandler.CreateTransfer)
+		mahasiswaGroup.POST("/transfer", middleware.FeatureFlag(flagService, feature.Transfers), transferHandler.CreateTransfer)
 		mahasiswaGroup.GET("/transfer/history", transferHandler.GetMyTransfers)
 		mahasiswaGroup.GET("/transfer/recipient/:id", transferHandler.GetRecipientInfo)
+		mahasiswaGroup.POST("/point-gifts", middleware.FeatureFlag(flagService, feature.Transfers), pointGiftHandler.Send)
+		mahasiswaGroup.GET("/point-gifts", pointGiftHandler.GetAll)
+		mahasiswaGroup.POST("/point-gifts/:id/claim", pointGiftHandler.Claim)
 		mahasiswaGroup.GET("/users/lookup", userHandler.LookupUser)
 
 		// Marketplace & Cart
-		mahasiswaGroup.GET("/marketplace/products", marketplaceHandler.GetAll)
-		mahasiswaGroup.GET("/marketplace/products/:id", marketplaceHandler.GetByID)
+		mahasiswaGroup.GET("/marketplace/products", middleware.ETag(), marketplaceHandler.GetAll)
+		mahasiswaGroup.GET("/marketplace/products/:id", middleware.ETag(), marketplaceHandler.GetByID)
+		mahasiswaGroup.GET("/marketplace/products/:id/questions", marketplaceHandler.GetProductQuestions)
+		mahasiswaGroup.POST("/marketplace/products/:id/questions", marketplaceHandler.AskQuestion)
+		mahasiswaGroup.POST("/marketplace/questions/:id/helpful", marketplaceHandler.MarkQuestionHelpful)
 		mahasiswaGroup.POST("/marketplace/purchase", marketplaceHandler.Purchase)
+		mahasiswaGroup.GET("/marketplace/gifts", marketplaceHandler.GetGifts)
+		mahasiswaGroup.GET("/marketplace/orders", orderHandler.GetAll)
+		mahasiswaGroup.GET("/marketplace/orders/:id", orderHandler.GetByID)
 		mahasiswaGroup.GET("/marketplace/cart", marketplaceHandler.GetCart)
 		mahasiswaGroup.POST("/marketplace/cart", marketplaceHandler.AddToCart)
 		mahasiswaGroup.PUT("/marketplace/cart/:id", marketplaceHandler.UpdateCartItem)
 		mahasiswaGroup.DELETE("/marketplace/cart/:id", marketplaceHandler.RemoveFromCart)
+		mahasiswaGroup.POST("/marketplace/cart/quote", marketplaceHandler.QuoteCheckout)
 		mahasiswaGroup.POST("/marketplace/cart/checkout", marketplaceHandler.Checkout)
 
 		// Gamification
@@ -180,10 +885,142 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 		mahasiswaGroup.GET("/transactions", walletHandler.GetMyTransactions)
 		mahasiswaGroup.POST("/payment/token", walletHandler.GeneratePaymentToken)
 		mahasiswaGroup.POST("/payment/execute", walletHandler.ExecuteStudentPayment)
+
+		// Attendance rewards
+		mahasiswaGroup.GET("/attendance", attendanceHandler.GetMySummary)
+
+		// Library fines
+		mahasiswaGroup.GET("/library/fines", libraryHandler.GetMyFines)
+		mahasiswaGroup.POST("/library/fines/:id/pay", libraryHandler.PayFine)
+
+		// Canteen POS
+		mahasiswaGroup.POST("/pos/pay-code", posHandler.IssuePayCode)
+
+		// Kiosk orders (scanned QR codes)
+		mahasiswaGroup.GET("/kiosk/orders/:code", kioskHandler.PreviewOrder)
+		mahasiswaGroup.POST("/kiosk/orders/confirm", kioskHandler.ConfirmOrder)
+
+		// Print quota
+		mahasiswaGroup.GET("/print-quota/topups", printQuotaHandler.GetMyTopUps)
+
+		// Tickets
+		mahasiswaGroup.GET("/marketplace/products/:id/sessions", ticketHandler.GetSessions)
+		mahasiswaGroup.GET("/tickets", ticketHandler.GetMyTickets)
+		mahasiswaGroup.GET("/tickets/:id", ticketHandler.GetMyTicket)
+		mahasiswaGroup.POST("/tickets/:id/cancel", ticketHandler.CancelTicket)
+		mahasiswaGroup.GET("/tickets/waitlist", ticketHandler.GetMyWaitlist)
+		mahasiswaGroup.POST("/ticket-sessions/:id/waitlist", ticketHandler.JoinWaitlist)
+		mahasiswaGroup.DELETE("/ticket-sessions/:id/waitlist", ticketHandler.LeaveWaitlist)
+
+		// Top-ups (buying points)
+		mahasiswaGroup.GET("/topups/options", paymentHandler.GetOptions)
+		mahasiswaGroup.GET("/topups", paymentHandler.GetMyTopUps)
+		mahasiswaGroup.POST("/topups", paymentHandler.CreateTopUp)
+		mahasiswaGroup.GET("/topups/:id", paymentHandler.GetMyTopUp)
+		mahasiswaGroup.GET("/wallet/topup-requests", topUpRequestHandler.GetMine)
+		mahasiswaGroup.POST("/wallet/topup-requests", topUpRequestHandler.Create)
+
+		// GraphQL (products, cart, orders and wallet in one round trip)
+		mahasiswaGroup.GET("/graphql", graphqlHandler.Serve)
+		mahasiswaGroup.POST("/graphql", graphqlHandler.Serve)
 	}
 	// Global QR Status Check
 	api.GET("/payment/status/:token", walletHandler.CheckTokenStatus)
 
+	// Payment provider notifications (public: verified by the provider's signature)
+	api.POST("/payment/webhooks/:provider", paymentHandler.Webhook)
+
+	// Campus LMS completion events (public: verified by X-LMS-Signature)
+	api.POST("/integrations/lms/events", lmsHandler.ReceiveEvent)
+
+	// Campus attendance events (public: verified by X-Attendance-Signature)
+	api.POST("/integrations/attendance/events", attendanceHandler.ReceiveEvent)
+
+	// Telegram bot updates (public: verified by X-Telegram-Bot-Api-Secret-Token)
+	api.POST("/integrations/telegram/webhook", telegramHandler.Webhook)
+
+	// Library fines (public: verified by X-Library-Signature)
+	api.POST("/integrations/library/fines", libraryHandler.CreateFine)
+
+	// Canteen tills (authenticated by terminal API key)
+	posGroup := api.Group("/pos", posHandler.RequireTerminal())
+	{
+		posGroup.POST("/charges", posHandler.Charge)
+		posGroup.GET("/charges", posHandler.GetTerminalCharges)
+		posGroup.POST("/charges/:reference/void", posHandler.VoidCharge)
+	}
+
+	// Self-service kiosks (authenticated by kiosk API key and device ID)
+	kioskGroup := api.Group("/kiosk", kioskHandler.RequireKiosk())
+	{
+		kioskGroup.GET("/me", kioskHandler.GetSelf)
+		kioskGroup.GET("/products", kioskHandler.GetProducts)
+		kioskGroup.GET("/products/:id", kioskHandler.GetProduct)
+		kioskGroup.POST("/orders", kioskHandler.CreateOrder)
+		kioskGroup.GET("/orders/:id", kioskHandler.GetOrder)
+		kioskGroup.POST("/orders/:id/cancel", kioskHandler.CancelOrder)
+	}
+
+	// Public catalog for the marketing site (authenticated and throttled by storefront API key)
+	storefrontGroup := api.Group("/storefront", storefrontHandler.RequireKey(), middleware.ETag())
+	{
+		cacheProducts := middleware.ResponseCache(productCache, settingsService, cache.ResponseCatalog, settings.StorefrontProductsCacheSeconds)
+		storefrontGroup.GET("/products", cacheProducts, storefrontHandler.GetProducts)
+		storefrontGroup.GET("/products/:id", cacheProducts, storefrontHandler.GetProduct)
+		storefrontGroup.GET("/categories", middleware.ResponseCache(productCache, settingsService, cache.ResponseCatalog, settings.StorefrontCategoriesCacheSeconds), storefrontHandler.GetCategories)
+	}
+
+	// ========================================
+	// PRODUCT CATEGORIES (all roles read; admins manage)
+	// ========================================
+	categoryGroup := api.Group("/marketplace/categories")
+	categoryGroup.Use(middleware.AuthMiddleware(), compress)
+	{
+		categoryGroup.GET("", marketplaceHandler.GetCategories)
+		categoryGroup.GET("/:id", marketplaceHandler.GetCategory)
+		categoryGroup.POST("", middleware.RoleMiddleware("admin"), idempotent, marketplaceHandler.CreateCategory)
+		categoryGroup.PUT("/:id", middleware.RoleMiddleware("admin"), marketplaceHandler.UpdateCategory)
+		categoryGroup.DELETE("/:id", middleware.RoleMiddleware("admin"), marketplaceHandler.DeleteCategory)
+	}
+
+	// ========================================
+	// FACULTY STORES (any signed-in user; /manage for the store's admins)
+	// ========================================
+	storeGroup := api.Group("/stores")
+	storeGroup.Use(middleware.AuthMiddleware(), compress)
+	{
+		storeGroup.GET("", facultyHandler.GetStores)
+		storeGroup.GET("/:store", facultyHandler.GetStore)
+		storeGroup.GET("/:store/products", middleware.ETag(), marketplaceHandler.GetStoreProducts)
+		storeGroup.PUT("/:store/manage/branding", facultyHandler.UpdateBranding)
+		storeGroup.PUT("/:store/manage/products", facultyHandler.SetCatalog)
+		storeGroup.GET("/:store/manage/banners", facultyHandler.GetBanners)
+		storeGroup.POST("/:store/manage/banners", idempotent, facultyHandler.CreateBanner)
+		storeGroup.PUT("/:store/manage/banners/:id", facultyHandler.UpdateBanner)
+		storeGroup.DELETE("/:store/manage/banners/:id", facultyHandler.DeleteBanner)
+	}
+
+	// Live stock/price updates for kiosks and the web store (public: EventSource cannot send a token)
+	api.GET("/marketplace/live", marketplaceHandler.Stream)
+
+	// Kubernetes probes: /healthz for liveness, /readyz for readiness
+	healthService := health.NewHealthService(5 * time.Second)
+	healthService.Register("database", health.DatabaseCheck(db))
+	healthService.Register("job_queue", jobQueue.Check)
+	healthService.Register("notification_outbox", notificationService.CheckDispatcher)
+	if productCache.Enabled() {
+		healthService.RegisterOptional("cache", productCache.Check)
+	}
+	if len(cfg.DBReplicaHosts) > 0 {
+		healthService.RegisterOptional("database_replica", health.ReplicaCheck(db))
+	}
+	healthHandler := health.NewHealthHandler(healthService)
+	r.GET("/healthz", healthHandler.Healthz)
+	r.GET("/readyz", healthHandler.Readyz)
+
+	// Prometheus scrape endpoint
+	r.GET("/metrics", middleware.MetricsAuth(cfg.MetricsToken), gin.WrapH(metrics.Handler()))
+
 	// Health check
 	api.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -192,6 +1029,25 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 		})
 	})
 
-	// Swagger documentation
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// API documentation
+	registerDocs(r)
+
+	// Batch sub-requests are served by the complete router, so this stays after every route
+	batchService.SetTarget(VersionFallback(r))
+
+	// gRPC API for campus systems (canteen POS, attendance), served by main on its own port
+	if cfg.GRPCPort == "" {
+		return nil
+	}
+	campusServer, err := campusrpc.NewServer(campusrpc.Config{
+		Port:           cfg.GRPCPort,
+		CertFile:       cfg.GRPCCertFile,
+		KeyFile:        cfg.GRPCKeyFile,
+		ClientCAFile:   cfg.GRPCClientCAFile,
+		AllowedClients: cfg.GRPCAllowedClients,
+	}, walletService, marketplaceService, auditService)
+	if err != nil {
+		log.Fatal("❌ Invalid gRPC configuration:", err)
+	}
+	return campusServer
 }
@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Each version inherits the routes of the one before it. Only endpoints whose
+// response shape changes are registered under the newer prefix; every other
+// request is served by the previous version's handler.
+var apiVersions = []string{"v1", "v2"}
+
+type routePattern struct {
+	method   string
+	segments []string
+}
+
+// VersionFallback wraps the engine so a request to a versioned path without a
+// route of its own is rewritten to the closest older version before routing.
+// Rewriting ahead of gin keeps global middleware (rate limiting, logging) from
+// running twice, which re-dispatching from NoRoute would do.
+func VersionFallback(engine *gin.Engine) http.Handler {
+	registered := make(map[string][]routePattern)
+	for _, route := range engine.Routes() {
+		for _, version := range apiVersions {
+			prefix := "/api/" + version + "/"
+			if strings.HasPrefix(route.Path, prefix) {
+				registered[version] = append(registered[version], routePattern{
+					method:   route.Method,
+					segments: strings.Split(strings.TrimPrefix(route.Path, prefix), "/"),
+				})
+			}
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := len(apiVersions) - 1; i > 0; i-- {
+			prefix := "/api/" + apiVersions[i] + "/"
+			if !strings.HasPrefix(r.URL.Path, prefix) {
+				continue
+			}
+
+			rest := strings.TrimPrefix(r.URL.Path, prefix)
+			r = utils.WithAPIVersion(r, apiVersions[i])
+			for j := i; j > 0 && !hasRoute(registered[apiVersions[j]], r.Method, rest); j-- {
+				r.URL.Path = "/api/" + apiVersions[j-1] + "/" + rest
+				r.URL.RawPath = ""
+			}
+			break
+		}
+		engine.ServeHTTP(w, r)
+	})
+}
+
+func hasRoute(patterns []routePattern, method, path string) bool {
+	segments := strings.Split(path, "/")
+	for _, pattern := range patterns {
+		if pattern.method == method && pattern.matches(segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p routePattern) matches(segments []string) bool {
+	for i, segment := range p.segments {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != segments[i] {
+			return false
+		}
+	}
+	return len(p.segments) == len(segments)
+}
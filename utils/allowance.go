@@ -0,0 +1,23 @@
+package utils
+
+import "time"
+
+// Allowance is how much of a limit a user has used and has left. ResetsAt is
+// when a periodic limit starts over; nil for a limit that never does.
+type Allowance struct {
+	Limit     int        `json:"limit"`
+	Used      int        `json:"used"`
+	Remaining int        `json:"remaining"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"`
+}
+
+func NewAllowance(limit, used int, resetsAt *time.Time) *Allowance {
+	return &Allowance{Limit: limit, Used: used, Remaining: max(limit-used, 0), ResetsAt: resetsAt}
+}
+
+// Today returns the start of the current day and of the next, the window of daily limits
+func Today() (time.Time, time.Time) {
+	year, month, day := time.Now().Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Background work (dispatchers, watchers, fire-and-forget follow-ups) is tracked
+// here so a shutdown can let it finish instead of killing it mid-write.
+var (
+	background   sync.WaitGroup
+	stopping     = make(chan struct{})
+	stopOnce     sync.Once
+	shuttingDown atomic.Bool
+	draining     = make(chan struct{})
+	drainOnce    sync.Once
+)
+
+// Go runs fn in a goroutine that shutdown waits for
+func Go(fn func()) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		fn()
+	}()
+}
+
+// Sleep waits for d, returning false early once background work is being stopped.
+// Loops use it in place of time.Sleep: for { work(); if !utils.Sleep(d) { return } }
+func Sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stopping:
+		return false
+	}
+}
+
+// BeginShutdown marks the instance as draining, so readiness fails and the load
+// balancer stops sending new requests
+func BeginShutdown() {
+	shuttingDown.Store(true)
+	drainOnce.Do(func() { close(draining) })
+}
+
+func ShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// Draining is closed by BeginShutdown. Long-lived responses (event streams) end
+// on it, otherwise the HTTP server would wait on them until its drain timeout.
+func Draining() <-chan struct{} {
+	return draining
+}
+
+// StopBackground wakes every background loop and waits for them and all tracked
+// goroutines to return, or for ctx to expire
+func StopBackground(ctx context.Context) error {
+	stopOnce.Do(func() { close(stopping) })
+
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
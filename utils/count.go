@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// countCacheMaxEntries bounds the number of distinct filter combinations remembered
+const countCacheMaxEntries = 1000
+
+// CountCache remembers the totals of list queries for ttl so large listings do not
+// run an exact COUNT(*) on every page load. Totals are keyed by the COUNT statement,
+// so every filter combination gets its own. Once a total is older than ttl the stale
+// value is still served while one background query refreshes it; only the first
+// load of a filter combination waits for the count. A nil cache or a ttl of 0
+// counts exactly every time.
+type CountCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]countEntry
+	group   singleflight.Group
+}
+
+type countEntry struct {
+	total     int64
+	countedAt time.Time
+}
+
+func NewCountCache(ttl time.Duration) *CountCache {
+	return &CountCache{ttl: ttl, entries: make(map[string]countEntry)}
+}
+
+// Count returns the number of rows query matches, at most about ttl old. Build
+// query without ordering or pagination, as for gorm's Count.
+func (c *CountCache) Count(ctx context.Context, query *gorm.DB) (int64, error) {
+	if c == nil || c.ttl <= 0 {
+		var total int64
+		err := query.Count(&total).Error
+		return total, err
+	}
+
+	key := query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var total int64
+		return tx.Count(&total)
+	})
+
+	c.mu.Lock()
+	entry, found := c.entries[key]
+	c.mu.Unlock()
+
+	if !found {
+		total, err, _ := c.group.Do(key, func() (interface{}, error) {
+			return c.refresh(key, query)
+		})
+		if err != nil {
+			return 0, err
+		}
+		return total.(int64), nil
+	}
+
+	if time.Since(entry.countedAt) > c.ttl {
+		// The request may finish (and cancel its context) before the count does
+		background := query.WithContext(context.WithoutCancel(ctx))
+		Go(func() {
+			c.group.Do(key, func() (interface{}, error) {
+				total, err := c.refresh(key, background)
+				if err != nil {
+					slog.WarnContext(ctx, "count cache: refresh failed", "error", err)
+				}
+				return total, err
+			})
+		})
+	}
+	return entry.total, nil
+}
+
+func (c *CountCache) refresh(key string, query *gorm.DB) (int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= countCacheMaxEntries {
+		for k, entry := range c.entries {
+			if time.Since(entry.countedAt) > c.ttl {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= countCacheMaxEntries {
+			c.entries = make(map[string]countEntry)
+		}
+	}
+	c.entries[key] = countEntry{total: total, countedAt: time.Now()}
+	return total, nil
+}
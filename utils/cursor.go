@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the last row of a page for keyset pagination on (created_at, id)
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// EncodeCursor returns an opaque cursor clients pass back to get the next page
+func EncodeCursor(createdAt time.Time, id uint) string {
+	raw := fmt.Sprintf("%d:%d", createdAt.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor made by EncodeCursor; an empty value means no cursor
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var nanos int64
+	var id uint
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil || id == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{CreatedAt: time.Unix(0, nanos), ID: id}, nil
+}
+
+// Paginate orders newest first with id as tie-breaker, then continues after the
+// cursor when one is given (keyset) or skips to the page otherwise (OFFSET)
+func Paginate(query *gorm.DB, cursor *Cursor, createdAtColumn, idColumn string, page, limit int) *gorm.DB {
+	query = query.Order(createdAtColumn + " DESC").Order(idColumn + " DESC").Limit(limit)
+	if cursor == nil {
+		return query.Offset((page - 1) * limit)
+	}
+	return query.Where(
+		fmt.Sprintf("(%s < ? OR (%s = ? AND %s < ?))", createdAtColumn, createdAtColumn, idColumn),
+		cursor.CreatedAt, cursor.CreatedAt, cursor.ID,
+	)
+}
+
+// NextCursor returns the cursor after the last row, or "" when a short page shows there is no more
+func NextCursor[T any](rows []T, limit int, key func(T) (time.Time, uint)) string {
+	if limit < 1 || len(rows) < limit {
+		return ""
+	}
+	createdAt, id := key(rows[len(rows)-1])
+	return EncodeCursor(createdAt, id)
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Generic error codes, used when an error has no domain-specific code
+const (
+	CodeBadRequest       = "BAD_REQUEST"
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeForbidden        = "FORBIDDEN"
+	CodeNotFound         = "NOT_FOUND"
+	CodeConflict         = "CONFLICT"
+	CodeTooManyRequests  = "TOO_MANY_REQUESTS"
+	CodeInternal         = "INTERNAL_ERROR"
+	CodeUnavailable      = "SERVICE_UNAVAILABLE"
+	CodeRequestTimeout   = "REQUEST_TIMEOUT"
+)
+
+// ErrRequestTimeout replaces the error of a request whose context deadline passed
+var ErrRequestTimeout = NewAppError(CodeRequestTimeout, http.StatusGatewayTimeout, "the request took too long and was cancelled")
+
+// AppError is a service error with a stable, machine-readable code. Codes are
+// part of the API contract: never rename one, add a new code instead.
+type AppError struct {
+	Code    string
+	Status  int
+	Message string
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+// NewAppError declares a typed service error, usually as a package-level sentinel
+func NewAppError(code string, status int, message string) *AppError {
+	return &AppError{Code: code, Status: status, Message: message}
+}
+
+// AsAppError finds an AppError in err's chain
+func AsAppError(err error) (*AppError, bool) {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// CodeForStatus returns the generic code of an HTTP status
+func CodeForStatus(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return CodeUnauthorized
+	case status == http.StatusForbidden:
+		return CodeForbidden
+	case status == http.StatusNotFound:
+		return CodeNotFound
+	case status == http.StatusConflict:
+		return CodeConflict
+	case status == http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case status == http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case status >= 500:
+		return CodeInternal
+	default:
+		return CodeBadRequest
+	}
+}
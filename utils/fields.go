@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldColumns maps the item fields a list endpoint lets clients pick with
+// fields= (by JSON name) to the SQL expression each is selected with, e.g.
+// "product_name": "p.name AS product_name"
+type FieldColumns map[string]string
+
+// Fields is a parsed fields= query parameter: the JSON names of the item fields
+// to return, in request order. Nil means all fields.
+type Fields []string
+
+// FieldsQuery reads the optional fields query parameter, a comma-separated list
+// of JSON names (e.g. fields=id,name,price) out of columns. An unknown name is
+// answered with a 400 validation error and ok is false; the handler should just return.
+func FieldsQuery(c *gin.Context, columns FieldColumns) (fields Fields, ok bool) {
+	for _, name := range strings.Split(c.Query("fields"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || fields.Has(name) {
+			continue
+		}
+		if _, known := columns[name]; !known {
+			names := make([]string, 0, len(columns))
+			for name := range columns {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			queryError(c, "fields", "fields", formatRule(c, "must be a comma-separated list of: {param}", strings.Join(names, ", ")))
+			return nil, false
+		}
+		fields = append(fields, name)
+	}
+	return fields, true
+}
+
+func (f Fields) Has(name string) bool {
+	for _, field := range f {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the SQL expressions of the requested fields plus those of
+// always, the fields the query needs itself (e.g. the cursor keys), or nil when
+// all fields are requested
+func (f Fields) Select(columns FieldColumns, always ...string) []string {
+	if f == nil {
+		return nil
+	}
+	selected := make([]string, 0, len(f)+len(always))
+	for _, name := range always {
+		if !f.Has(name) {
+			selected = append(selected, columns[name])
+		}
+	}
+	for _, name := range f {
+		selected = append(selected, columns[name])
+	}
+	return selected
+}
+
+// Filter returns items (a slice of structs) as JSON objects holding only the
+// requested fields, or items unchanged when all fields are requested
+func (f Fields) Filter(items interface{}) interface{} {
+	if f == nil {
+		return items
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return items
+	}
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &objects); err != nil {
+		return items
+	}
+	for _, object := range objects {
+		for name := range object {
+			if !f.Has(name) {
+				delete(object, name)
+			}
+		}
+	}
+	return objects
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Supported response languages. Messages are written in English in the code and
+// translated on the way out; a language without a catalog gets them as written.
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
+const languageKey = "language"
+
+var languageMatcher = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+
+// catalog holds the translations of one language: messages keyed by their English
+// text, and AppError messages keyed by error code
+type catalog struct {
+	messages map[string]string
+	errors   map[string]string
+}
+
+var catalogs = map[string]catalog{
+	LanguageIndonesian: indonesianCatalog,
+}
+
+// ResolveLanguage picks the best supported language for an Accept-Language header,
+// falling back to English
+func ResolveLanguage(acceptLanguage string) string {
+	tag, _ := language.MatchStrings(languageMatcher, acceptLanguage)
+	if base, _ := tag.Base(); base.String() == LanguageIndonesian {
+		return LanguageIndonesian
+	}
+	return LanguageEnglish
+}
+
+// SetLanguage records the response language of the request
+func SetLanguage(c *gin.Context, lang string) {
+	c.Set(languageKey, lang)
+}
+
+// Language returns the response language of the request
+func Language(c *gin.Context) string {
+	if lang := c.GetString(languageKey); lang != "" {
+		return lang
+	}
+	return ResolveLanguage(c.GetHeader("Accept-Language"))
+}
+
+// Translate returns message in the request language, or unchanged when it has no translation
+func Translate(c *gin.Context, message string) string {
+	if translated, ok := catalogs[Language(c)].messages[message]; ok {
+		return translated
+	}
+	return message
+}
+
+// translateError translates the AppError part of message (which may carry extra
+// detail after it) by error code
+func translateError(c *gin.Context, appErr *AppError, message string) string {
+	translated, ok := catalogs[Language(c)].errors[appErr.Code]
+	if !ok {
+		return message
+	}
+	return strings.Replace(message, appErr.Message, translated, 1)
+}
+
+// TranslateError returns the message of err in the request language, translating
+// the AppError it wraps by code
+func TranslateError(c *gin.Context, err error) string {
+	if appErr, ok := AsAppError(err); ok {
+		return translateError(c, appErr, err.Error())
+	}
+	return Translate(c, err.Error())
+}
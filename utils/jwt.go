@@ -11,6 +11,9 @@ type JWTClaims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+
+	// Campus the user belongs to; 0 in tokens issued before campuses existed
+	TenantID uint `json:"tenant_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -22,11 +25,12 @@ func InitJWT(secret string) {
 }
 
 // GenerateJWT generates a new JWT token
-func GenerateJWT(userID uint, email, role string, expiryHours int) (string, error) {
+func GenerateJWT(userID uint, email, role string, tenantID uint, expiryHours int) (string, error) {
 	claims := &JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:   userID,
+		Email:    email,
+		Role:     role,
+		TenantID: tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expiryHours))),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var logLevel = new(slog.LevelVar)
+
+type logAttrsKey struct{}
+
+// InitLogger installs the process-wide slog logger; the standard log package is
+// routed through it too. format is "json" (production log shipping) or "text".
+func InitLogger(format, level string) {
+	if err := SetLogLevel(level); err != nil {
+		logLevel.Set(slog.LevelInfo)
+	}
+
+	options := &slog.HandlerOptions{Level: logLevel}
+	var handler slog.Handler
+	if format == "json" {
+		handler = slog.NewJSONHandler(os.Stdout, options)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, options)
+	}
+	slog.SetDefault(slog.New(contextHandler{handler}))
+}
+
+// SetLogLevel changes the minimum level at runtime (debug, info, warn, error)
+func SetLogLevel(level string) error {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	logLevel.Set(parsed)
+	return nil
+}
+
+func LogLevel() string {
+	return strings.ToLower(logLevel.Level().String())
+}
+
+// WithLogAttrs returns a ctx whose log records (slog.*Context) all carry attrs
+func WithLogAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(logAttrsKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(append(merged, existing...), attrs...)
+	return context.WithValue(ctx, logAttrsKey{}, merged)
+}
+
+// contextHandler adds the request-scoped attrs and the trace ID of ctx to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(logAttrsKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required,oneof=debug info warn error"`
+}
+
+// GetLogLevel handles reading the current log level (Admin)
+// @Summary Get log level
+// @Tags Admin - Monitoring
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} Response
+// @Router /admin/log-level [get]
+func GetLogLevel(c *gin.Context) {
+	SuccessResponse(c, http.StatusOK, "Log level retrieved", gin.H{"level": LogLevel()})
+}
+
+// UpdateLogLevel handles changing the log level without a restart (Admin)
+// @Summary Update log level
+// @Description Change the minimum log level of this instance at runtime; resets to LOG_LEVEL on restart
+// @Tags Admin - Monitoring
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body LogLevelRequest true "New level"
+// @Success 200 {object} Response
+// @Router /admin/log-level [put]
+func UpdateLogLevel(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	previous := LogLevel()
+	if err := SetLogLevel(req.Level); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	slog.WarnContext(c.Request.Context(), "log level changed", "from", previous, "to", req.Level, "user_id", c.GetUint("user_id"))
+
+	SuccessResponse(c, http.StatusOK, "Log level updated", gin.H{"level": LogLevel()})
+}
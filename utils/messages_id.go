@@ -0,0 +1,693 @@
+package utils
+
+// indonesianCatalog translates API messages to Bahasa Indonesia. Add an entry here
+// whenever a handler introduces a new message.
+var indonesianCatalog = catalog{
+	messages: map[string]string{
+		// Generic
+		"Validation error":                           "Validasi gagal",
+		"Too many requests. Please try again later.": "Terlalu banyak permintaan. Silakan coba lagi nanti.",
+		"Invalid cursor":                             "Cursor tidak valid",
+		"Invalid ID format":                          "Format ID tidak valid",
+		"Failed to parse form data":                  "Gagal membaca data formulir",
+		"Failed to read request body":                "Gagal membaca isi permintaan",
+		"Log level retrieved":                        "Level log berhasil diambil",
+		"Log level updated":                          "Level log berhasil diperbarui",
+		"Internal server error":                      "Terjadi kesalahan pada server",
+		"Batch completed":                            "Batch selesai diproses",
+
+		// Request validation (see validation.go); {param} is filled in after translation
+		"is required":                                "wajib diisi",
+		"is invalid":                                 "tidak valid",
+		"must be a valid email address":              "harus berupa alamat email yang valid",
+		"must contain only digits":                   "hanya boleh berisi angka",
+		"must be a phone number like +6281234567890": "harus berupa nomor telepon seperti +6281234567890",
+		"must be a NIM/NIP of 8 to 20 digits":        "harus berupa NIM/NIP 8 sampai 20 digit",
+		"must be between 1 and {param} points":       "harus antara 1 dan {param} poin",
+		"must be one of: {param}":                    "harus salah satu dari: {param}",
+		"must be a comma-separated list of: {param}": "harus berupa daftar yang dipisahkan koma dari: {param}",
+		"must be exactly {param} characters":         "harus tepat {param} karakter",
+		"must have exactly {param} items":            "harus berisi tepat {param} item",
+		"must be {param}":                            "harus {param}",
+		"must be at least {param} characters":        "minimal {param} karakter",
+		"must have at least {param} items":           "minimal berisi {param} item",
+		"must be at least {param}":                   "minimal {param}",
+		"must be at most {param} characters":         "maksimal {param} karakter",
+		"must have at most {param} items":            "maksimal berisi {param} item",
+		"must be at most {param}":                    "maksimal {param}",
+		"must be greater than {param}":               "harus lebih dari {param}",
+		"must be less than {param}":                  "harus kurang dari {param}",
+		"text":                                       "teks",
+		"true or false":                              "true atau false",
+		"a whole number":                             "bilangan bulat",
+		"a number":                                   "angka",
+		"a list":                                     "daftar",
+		"an object":                                  "objek",
+		"request body is not valid JSON":             "isi permintaan bukan JSON yang valid",
+		"request body is empty":                      "isi permintaan kosong",
+
+		// Auth
+		"Authorization header required":                "Header Authorization wajib diisi",
+		"Invalid authorization header format":          "Format header Authorization tidak valid",
+		"Invalid or expired token":                     "Token tidak valid atau sudah kedaluwarsa",
+		"Insufficient permissions":                     "Akses tidak diizinkan",
+		"User not authenticated":                       "Pengguna belum terautentikasi",
+		"User role not found in context":               "Peran pengguna tidak ditemukan",
+		"Login successful":                             "Login berhasil",
+		"User registered successfully":                 "Pengguna berhasil didaftarkan",
+		"Account created successfully. Please log in.": "Rekening berhasil dibuat. Silakan login.",
+		"Profile updated successfully":                 "Profil berhasil diperbarui",
+		"Failed to update profile":                     "Gagal memperbarui profil",
+		"Password updated successfully":                "Kata sandi berhasil diperbarui",
+		"Password changed successfully":                "Kata sandi berhasil diubah",
+		"PIN updated successfully":                     "PIN berhasil diperbarui",
+		"PIN reset code sent":                          "Kode reset PIN telah dikirim",
+		"PIN reset successfully":                       "PIN berhasil direset",
+		"Verification code sent":                       "Kode verifikasi telah dikirim",
+		"Phone number retrieved successfully":          "Nomor telepon berhasil diambil",
+		"Phone number verified successfully":           "Nomor telepon berhasil diverifikasi",
+		"Messaging usage retrieved":                    "Penggunaan pesan berhasil diambil",
+		"Failed to retrieve messaging usage":           "Gagal mengambil penggunaan pesan",
+
+		// Users
+		"Invalid user ID":                                "ID pengguna tidak valid",
+		"after_years must be between 1 and 50":           "after_years harus antara 1 dan 50",
+		"Graduation list file is required":               "File daftar lulusan wajib diunggah",
+		"Failed to read graduation list":                 "Gagal membaca daftar lulusan",
+		"Graduation list uploaded, anonymization queued": "Daftar lulusan diunggah, anonimisasi dijadwalkan",
+		"User ID is required":                            "ID pengguna wajib diisi",
+		"User found":                                     "Pengguna ditemukan",
+		"User not found":                                 "Pengguna tidak ditemukan",
+		"User retrieved successfully":                    "Pengguna berhasil diambil",
+		"Users retrieved successfully":                   "Daftar pengguna berhasil diambil",
+		"Failed to retrieve users":                       "Gagal mengambil daftar pengguna",
+		"User updated successfully":                      "Pengguna berhasil diperbarui",
+		"User deleted successfully":                      "Pengguna berhasil dihapus",
+		"User restored successfully":                     "Pengguna berhasil dipulihkan",
+
+		// Wallet
+		"Invalid wallet ID":                    "ID dompet tidak valid",
+		"Wallet not found":                     "Dompet tidak ditemukan",
+		"Wallet retrieved successfully":        "Dompet berhasil diambil",
+		"Wallets retrieved successfully":       "Daftar dompet berhasil diambil",
+		"Failed to retrieve wallets":           "Gagal mengambil daftar dompet",
+		"Points adjusted successfully":         "Poin berhasil disesuaikan",
+		"Wallet reset successfully":            "Dompet berhasil direset",
+		"Transactions retrieved successfully":  "Transaksi berhasil diambil",
+		"Failed to retrieve transactions":      "Gagal mengambil transaksi",
+		"Payment token generated successfully": "Token pembayaran berhasil dibuat",
+		"Token info retrieved":                 "Informasi token berhasil diambil",
+		"Invalid or expired payment token":     "Token tidak valid atau sudah kedaluwarsa",
+		"Payment successful":                   "Pembayaran berhasil!",
+		"Admin stats retrieved":                "Statistik admin berhasil diambil",
+		"Error fetching admin stats":           "Gagal mengambil statistik admin",
+		"Stats retrieved successfully":         "Statistik berhasil diambil",
+		"Failed to get stats":                  "Gagal mengambil statistik",
+
+		// Top-ups
+		"Top-up options retrieved successfully":  "Opsi top-up berhasil diambil",
+		"Top-up created":                         "Top-up berhasil dibuat",
+		"Top-ups retrieved successfully":         "Daftar top-up berhasil diambil",
+		"Failed to retrieve top-ups":             "Gagal mengambil daftar top-up",
+		"Top-up retrieved successfully":          "Top-up berhasil diambil",
+		"Invalid top-up ID":                      "ID top-up tidak valid",
+		"Top-up confirmed":                       "Top-up berhasil dikonfirmasi",
+		"Top-up rejected":                        "Top-up ditolak",
+		"Notification processed":                 "Notifikasi berhasil diproses",
+		"Top-up request submitted":               "Permintaan top-up berhasil dikirim",
+		"Top-up requests retrieved successfully": "Daftar permintaan top-up berhasil diambil",
+		"Failed to retrieve top-up requests":     "Gagal mengambil daftar permintaan top-up",
+		"Invalid top-up request ID":              "ID permintaan top-up tidak valid",
+		"Top-up request reviewed":                "Permintaan top-up berhasil ditinjau",
+
+		// LMS integration
+		"LMS event processed":                "Event LMS berhasil diproses",
+		"LMS courses retrieved successfully": "Daftar mata kuliah LMS berhasil diambil",
+		"Failed to retrieve LMS courses":     "Gagal mengambil daftar mata kuliah LMS",
+		"LMS course mapped":                  "Mata kuliah LMS berhasil dipetakan",
+		"LMS course mapping updated":         "Pemetaan mata kuliah LMS berhasil diperbarui",
+		"LMS course mapping deleted":         "Pemetaan mata kuliah LMS berhasil dihapus",
+		"Invalid LMS course mapping ID":      "ID pemetaan mata kuliah LMS tidak valid",
+		"LMS events retrieved successfully":  "Daftar event LMS berhasil diambil",
+		"Failed to retrieve LMS events":      "Gagal mengambil daftar event LMS",
+
+		// Attendance integration
+		"Attendance event processed":                "Event presensi berhasil diproses",
+		"Attendance report retrieved successfully":  "Laporan presensi berhasil diambil",
+		"Attendance summary retrieved successfully": "Ringkasan presensi berhasil diambil",
+		"Failed to retrieve attendance summary":     "Gagal mengambil ringkasan presensi",
+
+		// Library fines
+		"Library fine recorded":                "Denda perpustakaan berhasil dicatat",
+		"Library fines retrieved successfully": "Daftar denda perpustakaan berhasil diambil",
+		"Failed to retrieve library fines":     "Gagal mengambil daftar denda perpustakaan",
+		"Library fine paid":                    "Denda perpustakaan berhasil dibayar",
+		"Invalid library fine ID":              "ID denda perpustakaan tidak valid",
+
+		// Canteen POS
+		"POS charge completed":                 "Pembayaran kantin berhasil",
+		"POS charge voided":                    "Pembayaran kantin dibatalkan",
+		"POS charges retrieved successfully":   "Daftar pembayaran kantin berhasil diambil",
+		"Failed to retrieve POS charges":       "Gagal mengambil daftar pembayaran kantin",
+		"Pay code issued":                      "Kode bayar berhasil dibuat",
+		"Failed to issue pay code":             "Gagal membuat kode bayar",
+		"POS terminals retrieved successfully": "Daftar terminal kasir berhasil diambil",
+		"Failed to retrieve POS terminals":     "Gagal mengambil daftar terminal kasir",
+		"POS terminal created":                 "Terminal kasir berhasil dibuat",
+		"POS terminal updated":                 "Terminal kasir berhasil diperbarui",
+		"POS terminal key rotated":             "Kunci API terminal kasir berhasil diganti",
+		"Invalid POS terminal ID":              "ID terminal kasir tidak valid",
+
+		// Print quota
+		"Print quota top-ups retrieved successfully": "Daftar penambahan kuota cetak berhasil diambil",
+
+		// Tickets
+		"Ticket sessions retrieved successfully": "Sesi tiket berhasil diambil",
+		"Ticket session created successfully":    "Sesi tiket berhasil dibuat",
+		"Ticket session updated successfully":    "Sesi tiket berhasil diperbarui",
+		"Ticket checked in successfully":         "Tiket berhasil divalidasi, silakan masuk",
+		"Tickets retrieved successfully":         "Tiket berhasil diambil",
+		"Failed to retrieve tickets":             "Gagal mengambil tiket",
+		"Ticket retrieved successfully":          "Tiket berhasil diambil",
+		"Invalid session ID":                     "ID sesi tidak valid",
+		"Invalid ticket ID":                      "ID tiket tidak valid",
+		"Ticket cancelled successfully":          "Tiket berhasil dibatalkan",
+		"Joined the waitlist successfully":       "Berhasil masuk daftar tunggu",
+		"Left the waitlist successfully":         "Berhasil keluar dari daftar tunggu",
+		"Waitlist retrieved successfully":        "Daftar tunggu berhasil diambil",
+		"Failed to retrieve waitlist":            "Gagal mengambil daftar tunggu",
+
+		// Faculty stores
+		"Faculty stores retrieved successfully":      "Daftar toko fakultas berhasil diambil",
+		"Failed to retrieve faculty stores":          "Gagal mengambil daftar toko fakultas",
+		"Faculty store retrieved successfully":       "Toko fakultas berhasil diambil",
+		"Faculty store created successfully":         "Toko fakultas berhasil dibuat",
+		"Faculty store updated successfully":         "Toko fakultas berhasil diperbarui",
+		"Faculty store admins updated successfully":  "Admin toko fakultas berhasil diperbarui",
+		"Faculty store catalog updated successfully": "Katalog toko fakultas berhasil diperbarui",
+		"Invalid store ID":                           "ID toko tidak valid",
+		"Invalid banner ID":                          "ID banner tidak valid",
+		"Banners retrieved successfully":             "Banner berhasil diambil",
+		"Failed to retrieve banners":                 "Gagal mengambil banner",
+		"Banner created successfully":                "Banner berhasil dibuat",
+		"Banner updated successfully":                "Banner berhasil diperbarui",
+		"Banner deleted successfully":                "Banner berhasil dihapus",
+		"Failed to retrieve print quota top-ups":     "Gagal mengambil daftar penambahan kuota cetak",
+
+		// Fulfillment queue
+		"Fulfillment queue retrieved successfully": "Antrean pesanan berhasil diambil",
+		"Failed to retrieve fulfillment queue":     "Gagal mengambil antrean pesanan",
+		"Invalid fulfillment ID":                   "ID pesanan antrean tidak valid",
+		"Order claimed":                            "Pesanan berhasil diambil untuk disiapkan",
+		"Order released":                           "Pesanan dikembalikan ke antrean",
+		"Order handed over":                        "Pesanan telah diserahkan",
+
+		// Suspicious activity review
+		"Suspicious activity retrieved successfully": "Daftar aktivitas mencurigakan berhasil diambil",
+		"Failed to retrieve suspicious activity":     "Gagal mengambil daftar aktivitas mencurigakan",
+		"Invalid flag ID":                            "ID aktivitas mencurigakan tidak valid",
+		"Suspicious activity reviewed":               "Aktivitas mencurigakan berhasil ditinjau",
+
+		// Product Q&A
+		"Product questions retrieved successfully": "Daftar pertanyaan produk berhasil diambil",
+		"Question submitted":                       "Pertanyaan berhasil dikirim dan akan tampil setelah dijawab admin",
+		"Question marked helpful":                  "Pertanyaan ditandai membantu",
+		"Question answered":                        "Pertanyaan berhasil dijawab",
+		"Question rejected":                        "Pertanyaan berhasil ditolak",
+		"Invalid question ID":                      "ID pertanyaan tidak valid",
+
+		// Product categories
+		"Category retrieved successfully":                             "Kategori berhasil diambil",
+		"Category created successfully":                               "Kategori berhasil dibuat",
+		"Category updated successfully":                               "Kategori berhasil diperbarui",
+		"Category deleted successfully":                               "Kategori berhasil dihapus",
+		"Invalid category ID":                                         "ID kategori tidak valid",
+		"Store hours retrieved successfully":                          "Jam operasional toko berhasil diambil",
+		"Store hours updated successfully":                            "Jam operasional toko berhasil diperbarui",
+		"category_ids must be a comma-separated list of category IDs": "category_ids harus berupa daftar ID kategori yang dipisahkan koma",
+
+		// Admin activity alerts
+		"Admin activity alerts retrieved successfully": "Daftar peringatan aktivitas admin berhasil diambil",
+		"Failed to retrieve admin activity alerts":     "Gagal mengambil daftar peringatan aktivitas admin",
+
+		// Catalog sheet sync
+		"Catalog sheet synced":                          "Katalog berhasil disinkronkan dengan spreadsheet",
+		"Catalog sync conflicts retrieved successfully": "Daftar konflik sinkronisasi katalog berhasil diambil",
+		"Failed to retrieve catalog sync conflicts":     "Gagal mengambil daftar konflik sinkronisasi katalog",
+		"Catalog sync conflict resolved":                "Konflik sinkronisasi katalog berhasil diselesaikan",
+		"Invalid conflict ID":                           "ID konflik tidak valid",
+
+		// Telegram bot
+		"Telegram link code issued":               "Kode tautan Telegram berhasil dibuat",
+		"Telegram account retrieved successfully": "Akun Telegram berhasil diambil",
+		"Telegram account unlinked":               "Akun Telegram berhasil dilepas",
+
+		// Public storefront
+		"Categories retrieved successfully":          "Daftar kategori berhasil diambil",
+		"Failed to retrieve categories":              "Gagal mengambil daftar kategori",
+		"Storefront API keys retrieved successfully": "Daftar API key storefront berhasil diambil",
+		"Failed to retrieve storefront API keys":     "Gagal mengambil daftar API key storefront",
+		"Storefront API key created":                 "API key storefront berhasil dibuat",
+		"Storefront API key updated":                 "API key storefront berhasil diperbarui",
+		"Storefront API key rotated":                 "API key storefront berhasil diganti",
+		"Invalid storefront API key ID":              "ID API key storefront tidak valid",
+
+		// Self-service kiosks
+		"Kiosks retrieved successfully":      "Daftar kiosk berhasil diambil",
+		"Failed to retrieve kiosks":          "Gagal mengambil daftar kiosk",
+		"Kiosk retrieved successfully":       "Kiosk berhasil diambil",
+		"Kiosk registered":                   "Kiosk berhasil didaftarkan",
+		"Kiosk updated":                      "Kiosk berhasil diperbarui",
+		"Kiosk API key rotated":              "API key kiosk berhasil diganti",
+		"Kiosk disabled":                     "Kiosk berhasil dinonaktifkan",
+		"Kiosk enabled":                      "Kiosk berhasil diaktifkan",
+		"Invalid kiosk ID":                   "ID kiosk tidak valid",
+		"Kiosk order created":                "Pesanan kiosk berhasil dibuat",
+		"Kiosk order retrieved successfully": "Pesanan kiosk berhasil diambil",
+		"Kiosk order cancelled":              "Pesanan kiosk dibatalkan",
+		"Kiosk order paid":                   "Pesanan kiosk berhasil dibayar",
+		"Invalid order ID":                   "ID pesanan tidak valid",
+
+		// Transfers
+		"Recipient found":                         "Penerima ditemukan",
+		"Transfer completed successfully":         "Transfer berhasil",
+		"Transfer history retrieved successfully": "Riwayat transfer berhasil diambil",
+		"All transfers retrieved":                 "Semua transfer berhasil diambil",
+
+		// Point gifts
+		"Points gift sent":                    "Hadiah poin berhasil dikirim",
+		"Points gift claimed":                 "Hadiah poin berhasil diklaim",
+		"Points gifts retrieved successfully": "Hadiah poin berhasil diambil",
+		"Failed to retrieve points gifts":     "Gagal mengambil hadiah poin",
+		"Invalid gift ID":                     "ID hadiah tidak valid",
+
+		// Marketplace
+		"Invalid product ID":                                "ID produk tidak valid",
+		"Products retrieved successfully":                   "Produk berhasil diambil",
+		"Failed to retrieve products":                       "Gagal mengambil produk",
+		"Product retrieved successfully":                    "Produk berhasil diambil",
+		"Product created successfully":                      "Produk berhasil dibuat",
+		"Product updated successfully":                      "Produk berhasil diperbarui",
+		"Product deleted successfully":                      "Produk berhasil dihapus",
+		"Product restored successfully":                     "Produk berhasil dipulihkan",
+		"Products restocked successfully":                   "Stok produk berhasil ditambahkan",
+		"Name and valid Price are required":                 "Nama dan harga yang valid wajib diisi",
+		"Purchase successful":                               "Pembelian berhasil",
+		"Marketplace transactions retrieved":                "Transaksi marketplace berhasil diambil",
+		"Sales analytics retrieved successfully":            "Analitik penjualan berhasil diambil",
+		"Cart retrieved successfully":                       "Keranjang berhasil diambil",
+		"Product added to cart":                             "Produk berhasil ditambahkan ke keranjang",
+		"Cart updated successfully":                         "Keranjang berhasil diperbarui",
+		"Product removed from cart":                         "Produk berhasil dihapus dari keranjang",
+		"Checkout successful":                               "Checkout berhasil!",
+		"Orders retrieved successfully":                     "Riwayat pesanan berhasil diambil",
+		"Failed to retrieve orders":                         "Gagal mengambil riwayat pesanan",
+		"Order retrieved successfully":                      "Pesanan berhasil diambil",
+		"Checkout quoted":                                   "Rincian checkout berhasil dihitung",
+		"Gifts retrieved successfully":                      "Hadiah berhasil diambil",
+		"Failed to retrieve gifts":                          "Gagal mengambil hadiah",
+		"Invalid alert ID":                                  "ID peringatan tidak valid",
+		"Stock alerts retrieved successfully":               "Peringatan stok berhasil diambil",
+		"Failed to retrieve stock alerts":                   "Gagal mengambil peringatan stok",
+		"Stock alert acknowledged":                          "Peringatan stok telah dikonfirmasi",
+		"Stock alert snoozed":                               "Peringatan stok ditunda",
+		"low_stock_threshold must be a non-negative number": "low_stock_threshold harus berupa angka non-negatif",
+		"purchase_limit must be a non-negative number":      "purchase_limit harus berupa angka non-negatif",
+		"pickup_location must be at most 100 characters":    "pickup_location maksimal 100 karakter",
+
+		// Missions
+		"Invalid mission ID":                 "ID misi tidak valid",
+		"Invalid submission ID":              "ID pengumpulan tidak valid",
+		"Mission created successfully":       "Misi berhasil dibuat",
+		"Mission retrieved successfully":     "Misi berhasil diambil",
+		"Missions retrieved successfully":    "Daftar misi berhasil diambil",
+		"Failed to retrieve missions":        "Gagal mengambil daftar misi",
+		"Mission updated successfully":       "Misi berhasil diperbarui",
+		"Mission deleted successfully":       "Misi berhasil dihapus",
+		"Mission submitted successfully":     "Misi berhasil dikumpulkan",
+		"Submissions retrieved successfully": "Daftar pengumpulan berhasil diambil",
+		"Failed to retrieve submissions":     "Gagal mengambil daftar pengumpulan",
+		"Submission reviewed successfully":   "Pengumpulan berhasil dinilai",
+		"Leaderboard retrieved":              "Papan peringkat berhasil diambil",
+		"Failed to retrieve leaderboard":     "Gagal mengambil papan peringkat",
+
+		// Notifications
+		"Invalid notification ID":              "ID notifikasi tidak valid",
+		"Invalid dead letter ID":               "ID dead letter tidak valid",
+		"Notifications retrieved successfully": "Notifikasi berhasil diambil",
+		"Failed to retrieve notifications":     "Gagal mengambil notifikasi",
+		"Notification marked as read":          "Notifikasi ditandai sudah dibaca",
+		"All notifications marked as read":     "Semua notifikasi ditandai sudah dibaca",
+		"Failed to update notifications":       "Gagal memperbarui notifikasi",
+		"Outbox retrieved successfully":        "Outbox berhasil diambil",
+		"Failed to retrieve outbox":            "Gagal mengambil outbox",
+		"Dead letters retrieved successfully":  "Dead letter berhasil diambil",
+		"Failed to retrieve dead letters":      "Gagal mengambil dead letter",
+		"Notification requeued":                "Notifikasi dijadwalkan ulang",
+
+		// Audit and reports
+		"Audit logs retrieved successfully":         "Log audit berhasil diambil",
+		"Failed to retrieve audit logs":             "Gagal mengambil log audit",
+		"Report generated successfully":             "Laporan berhasil dibuat",
+		"Failed to generate report":                 "Gagal membuat laporan",
+		"Failed to run report":                      "Gagal menjalankan laporan",
+		"Failed to export report":                   "Gagal mengekspor laporan",
+		"Sales report generated successfully":       "Laporan penjualan berhasil dibuat",
+		"Failed to generate sales report":           "Gagal membuat laporan penjualan",
+		"Failed to retrieve sales analytics":        "Gagal mengambil analitik penjualan",
+		"Breakage report generated successfully":    "Laporan breakage berhasil dibuat",
+		"Failed to generate breakage report":        "Gagal membuat laporan breakage",
+		"Category breakdown generated successfully": "Rincian kategori berhasil dibuat",
+		"Failed to generate category breakdown":     "Gagal membuat rincian kategori",
+		"Retention report generated successfully":   "Laporan retensi berhasil dibuat",
+		"Failed to generate retention report":       "Gagal membuat laporan retensi",
+		"Top buyers retrieved successfully":         "Pembeli teratas berhasil diambil",
+		"Top products retrieved successfully":       "Produk teratas berhasil diambil",
+		"Transaction volume retrieved successfully": "Volume transaksi berhasil diambil",
+		"Failed to retrieve transaction volume":     "Gagal mengambil volume transaksi",
+		"Analytics retrieved successfully":          "Analitik berhasil diambil",
+		"Failed to compute analytics":               "Gagal menghitung analitik",
+		"Quotas retrieved successfully":             "Kuota berhasil diambil",
+		"Subscribed to report":                      "Berlangganan laporan berhasil",
+		"Unsubscribed from report":                  "Berhenti berlangganan laporan berhasil",
+		"Subscriptions retrieved successfully":      "Daftar langganan berhasil diambil",
+		"Failed to retrieve subscriptions":          "Gagal mengambil daftar langganan",
+		"Invalid subscription ID":                   "ID langganan tidak valid",
+
+		// Feature flags
+		"Features retrieved successfully":      "Fitur berhasil diambil",
+		"Feature flags retrieved successfully": "Daftar feature flag berhasil diambil",
+		"Failed to retrieve feature flags":     "Gagal mengambil daftar feature flag",
+		"Feature flag updated":                 "Feature flag berhasil diperbarui",
+
+		// Runtime settings
+		"Settings retrieved successfully": "Daftar pengaturan berhasil diambil",
+		"Failed to retrieve settings":     "Gagal mengambil daftar pengaturan",
+		"Setting updated":                 "Pengaturan berhasil diperbarui",
+		"Setting reset to its default":    "Pengaturan dikembalikan ke nilai bawaan",
+
+		// Promotions
+		"Promotions retrieved successfully":      "Daftar promosi berhasil diambil",
+		"Failed to retrieve promotions":          "Gagal mengambil daftar promosi",
+		"Promotion created successfully":         "Promosi berhasil dibuat",
+		"Promotion updated successfully":         "Promosi berhasil diperbarui",
+		"Promotion deleted successfully":         "Promosi berhasil dihapus",
+		"Promotion usage retrieved successfully": "Penggunaan promosi berhasil diambil",
+		"Failed to retrieve promotion usage":     "Gagal mengambil penggunaan promosi",
+		"Invalid promotion ID":                   "ID promosi tidak valid",
+
+		// Tenants (campuses)
+		"Tenants retrieved successfully": "Daftar kampus berhasil diambil",
+		"Failed to retrieve tenants":     "Gagal mengambil daftar kampus",
+		"Tenant created successfully":    "Kampus berhasil dibuat",
+		"Tenant updated successfully":    "Kampus berhasil diperbarui",
+		"Invalid tenant ID":              "ID kampus tidak valid",
+		"Invalid X-Tenant-ID header":     "Header X-Tenant-ID tidak valid",
+
+		// Background jobs
+		"Jobs retrieved successfully":    "Daftar job berhasil diambil",
+		"Failed to retrieve jobs":        "Gagal mengambil daftar job",
+		"Job retrieved successfully":     "Job berhasil diambil",
+		"Invalid job ID":                 "ID job tidak valid",
+		"Job requeued":                   "Job dijadwalkan ulang",
+		"Sagas retrieved successfully":   "Daftar saga berhasil diambil",
+		"Failed to retrieve sagas":       "Gagal mengambil daftar saga",
+		"Reconciliation queued":          "Rekonsiliasi dijadwalkan",
+		"Failed to queue reconciliation": "Gagal menjadwalkan rekonsiliasi",
+		"Export queued":                  "Ekspor dijadwalkan",
+		"Failed to queue export":         "Gagal menjadwalkan ekspor",
+
+		// Scheduled jobs
+		"Scheduled jobs retrieved successfully": "Daftar job terjadwal berhasil diambil",
+		"Failed to retrieve scheduled jobs":     "Gagal mengambil daftar job terjadwal",
+		"Job runs retrieved successfully":       "Riwayat job berhasil diambil",
+		"Failed to retrieve job runs":           "Gagal mengambil riwayat job",
+		"Job started":                           "Job dijalankan",
+
+		// Webhooks
+		"Webhook events retrieved successfully":     "Daftar event webhook berhasil diambil",
+		"Webhook endpoints retrieved successfully":  "Daftar endpoint webhook berhasil diambil",
+		"Failed to retrieve webhook endpoints":      "Gagal mengambil daftar endpoint webhook",
+		"Webhook endpoint retrieved successfully":   "Endpoint webhook berhasil diambil",
+		"Invalid webhook endpoint ID":               "ID endpoint webhook tidak valid",
+		"Webhook endpoint created":                  "Endpoint webhook berhasil dibuat",
+		"Webhook endpoint updated":                  "Endpoint webhook berhasil diperbarui",
+		"Webhook endpoint deleted":                  "Endpoint webhook berhasil dihapus",
+		"Webhook secret rotated":                    "Secret webhook berhasil diganti",
+		"Test event queued":                         "Event uji dijadwalkan",
+		"Webhook deliveries retrieved successfully": "Riwayat pengiriman webhook berhasil diambil",
+		"Webhook delivery retrieved successfully":   "Pengiriman webhook berhasil diambil",
+		"Invalid webhook delivery ID":               "ID pengiriman webhook tidak valid",
+		"Webhook delivery queued":                   "Pengiriman webhook dijadwalkan ulang",
+
+		// Uploads
+		"No file uploaded":               "Tidak ada file yang diunggah",
+		"File size exceeds limit (10MB)": "Ukuran file melebihi batas (10MB)",
+		"File uploaded successfully":     "File berhasil diunggah",
+		"Failed to save file":            "Gagal menyimpan file",
+		"Failed to save image":           "Gagal menyimpan gambar",
+		"Failed to load file":            "Gagal memuat file",
+	},
+	errors: map[string]string{
+		"REQUEST_TIMEOUT": "permintaan terlalu lama dan dibatalkan",
+
+		"USER_NOT_FOUND":           "pengguna tidak ditemukan",
+		"USER_NOT_DELETED":         "pengguna tidak dalam keadaan terhapus",
+		"USER_EMAIL_TAKEN":         "email sudah terdaftar",
+		"USER_NIM_NIP_TAKEN":       "NIM/NIP sudah terdaftar",
+		"USER_CREATE_FAILED":       "gagal membuat pengguna",
+		"USER_ROLE_NOT_ALLOWED":    "hanya super admin yang dapat memberikan peran ini",
+		"GRADUATE_LIST_INVALID":    "daftar lulusan harus berupa CSV dengan baris nim_nip,graduated_on (YYYY-MM-DD)",
+		"GRADUATE_LIST_TOO_LONG":   "daftar lulusan memiliki terlalu banyak baris",
+		"AUTH_INVALID_CREDENTIALS": "email atau kata sandi salah",
+		"AUTH_ACCOUNT_INACTIVE":    "akun tidak aktif atau ditangguhkan",
+		"AUTH_PASSWORD_INCORRECT":  "kata sandi saat ini salah",
+		"AUTH_TOKEN_FAILED":        "gagal membuat token",
+		"AUTH_HASH_FAILED":         "gagal mengamankan kredensial",
+		"PIN_INCORRECT":            "PIN saat ini salah",
+		"PIN_REQUIRED":             "PIN saat ini wajib diisi untuk menggantinya",
+		"PIN_NOT_SET":              "PIN transaksi belum diatur. Silakan atur PIN di pengaturan Keamanan terlebih dahulu.",
+		"PIN_INVALID":              "PIN transaksi salah",
+
+		"WALLET_NOT_FOUND":              "dompet tidak ditemukan",
+		"WALLET_INSUFFICIENT_BALANCE":   "saldo tidak mencukupi",
+		"WALLET_DEBIT_NOT_FOUND":        "debit yang akan dikembalikan tidak ditemukan",
+		"WALLET_REFUND_EXCEEDS_DEBIT":   "pengembalian melebihi sisa debit",
+		"PAYMENT_TOKEN_NOT_FOUND":       "token pembayaran tidak ditemukan",
+		"PAYMENT_TOKEN_INVALID":         "token QR tidak valid atau sudah kedaluwarsa",
+		"PAYMENT_TOKEN_EXPIRED":         "token QR sudah kedaluwarsa",
+		"PAYMENT_TOKEN_NOT_OWNED":       "token bukan milik pengguna ini",
+		"PAYMENT_TOKEN_AMOUNT_MISMATCH": "jumlah token tidak sesuai",
+		"PAYMENT_RECIPIENT_UNAVAILABLE": "penerima pembayaran tidak tersedia",
+
+		"TOPUP_NOT_FOUND":              "top-up tidak ditemukan",
+		"TOPUP_NOT_PENDING":            "top-up tidak lagi menunggu pembayaran",
+		"TOPUP_AMOUNT_MISMATCH":        "jumlah pembayaran tidak sesuai dengan top-up",
+		"TOPUP_POINTS_OUT_OF_RANGE":    "jumlah poin top-up di luar batas yang diizinkan",
+		"TOPUP_NOT_MANUAL":             "hanya top-up transfer bank yang dikonfirmasi admin",
+		"TOPUP_REQUEST_NOT_FOUND":      "permintaan top-up tidak ditemukan",
+		"TOPUP_REQUEST_PENDING":        "Anda masih memiliki permintaan top-up yang menunggu peninjauan",
+		"TOPUP_REQUEST_REVIEWED":       "permintaan top-up sudah ditinjau",
+		"TOPUP_REQUEST_NOTE_REQUIRED":  "penolakan memerlukan catatan untuk mahasiswa",
+		"PAYMENT_PROVIDER_UNAVAILABLE": "penyedia pembayaran tidak tersedia",
+		"PAYMENT_PROVIDER_FAILED":      "penyedia pembayaran gagal memulai pembayaran",
+		"PAYMENT_NOTIFICATION_INVALID": "notifikasi pembayaran tidak dapat diverifikasi",
+
+		"LMS_NOT_CONFIGURED":        "integrasi LMS belum dikonfigurasi",
+		"LMS_INVALID_SIGNATURE":     "tanda tangan event LMS tidak ada, tidak valid, atau kedaluwarsa",
+		"LMS_INVALID_EVENT":         "event LMS tidak lengkap",
+		"LMS_COURSE_RULE_NOT_FOUND": "pemetaan mata kuliah LMS tidak ditemukan",
+		"LMS_COURSE_RULE_EXISTS":    "mata kuliah LMS sudah dipetakan",
+
+		"ATTENDANCE_NOT_CONFIGURED":    "integrasi presensi belum dikonfigurasi",
+		"ATTENDANCE_INVALID_SIGNATURE": "tanda tangan event presensi tidak ada, tidak valid, atau kedaluwarsa",
+		"ATTENDANCE_INVALID_EVENT":     "event presensi tidak lengkap",
+		"LIBRARY_NOT_CONFIGURED":       "integrasi perpustakaan belum dikonfigurasi",
+		"LIBRARY_INVALID_SIGNATURE":    "tanda tangan permintaan perpustakaan tidak ada, tidak valid, atau kedaluwarsa",
+		"LIBRARY_INVALID_FINE":         "data denda perpustakaan tidak lengkap",
+		"LIBRARY_STUDENT_NOT_FOUND":    "tidak ada mahasiswa aktif dengan NIM ini",
+		"LIBRARY_FINE_NOT_FOUND":       "denda perpustakaan tidak ditemukan",
+		"LIBRARY_FINE_ALREADY_PAID":    "denda perpustakaan sudah dibayar",
+
+		"POS_INVALID_API_KEY":       "kunci API kasir tidak ada, tidak valid, atau sudah dicabut",
+		"POS_TERMINAL_NOT_FOUND":    "terminal kasir tidak ditemukan",
+		"POS_PAY_CODE_INVALID":      "kode bayar tidak valid, kedaluwarsa, atau sudah digunakan",
+		"POS_CHARGE_TOO_LARGE":      "jumlah melebihi batas terminal ini",
+		"POS_DAILY_LIMIT_REACHED":   "pembayaran melebihi batas harian kantin mahasiswa",
+		"POS_REFERENCE_REUSED":      "referensi sudah dipakai untuk pembayaran lain",
+		"POS_CHARGE_NOT_FOUND":      "pembayaran kantin tidak ditemukan",
+		"POS_CHARGE_ALREADY_VOIDED": "pembayaran kantin sudah dibatalkan",
+		"POS_VOID_WINDOW_PASSED":    "pembayaran kantin tidak dapat dibatalkan lagi",
+
+		"PRINT_QUOTA_TOPUP_NOT_FOUND":  "penambahan kuota cetak tidak ditemukan",
+		"PRINT_SERVER_NOT_CONFIGURED":  "server cetak belum dikonfigurasi",
+		"PRODUCT_PRINT_PAGES_REQUIRED": "produk kuota cetak memerlukan print_pages",
+		"PRODUCT_UNAVAILABLE":          "jenis produk ini tidak dapat dikirim saat ini",
+
+		"FULFILLMENT_NOT_FOUND":       "pesanan tidak ditemukan di antrean",
+		"FULFILLMENT_ALREADY_CLAIMED": "pesanan sudah diambil oleh petugas lain",
+		"FULFILLMENT_NOT_CLAIMED":     "ambil pesanan terlebih dahulu sebelum menyelesaikan atau mengembalikannya",
+		"FULFILLMENT_COMPLETED":       "pesanan sudah diserahkan",
+
+		"PRODUCT_QUESTION_NOT_FOUND": "pertanyaan produk tidak ditemukan",
+		"PRODUCT_QUESTION_REJECTED":  "pertanyaan produk sudah ditolak",
+
+		"CATEGORY_NOT_FOUND":     "kategori tidak ditemukan",
+		"CATEGORY_SLUG_TAKEN":    "slug sudah dipakai kategori lain di kampus ini",
+		"CATEGORY_SLUG_INVALID":  "slug kategori harus berisi huruf atau angka",
+		"CATEGORY_OWNER_INVALID": "pemilik kategori harus admin aktif di kampus ini",
+		"CATEGORY_PARENT_CYCLE":  "kategori tidak dapat ditempatkan di bawah dirinya sendiri atau subkategorinya",
+		"CATEGORY_HAS_CHILDREN":  "pindahkan atau hapus subkategori terlebih dahulu",
+
+		"STORE_CLOSED":         "toko sedang tutup",
+		"RETURN_WINDOW_CLOSED": "batas waktu pembatalan atau pengembalian pesanan sudah lewat",
+		"STORE_HOURS_INVALID":  "jam operasional harus berformat HH:MM dengan jam tutup setelah jam buka, satu per hari",
+
+		"SUSPICIOUS_ACTIVITY_NOT_FOUND": "aktivitas mencurigakan tidak ditemukan",
+		"SUSPICIOUS_ACTIVITY_REVIEWED":  "aktivitas mencurigakan sudah ditinjau",
+
+		"AUTH_DIRECTORY_UNAVAILABLE":         "direktori universitas sedang tidak tersedia, coba lagi nanti",
+		"AUTH_DIRECTORY_ROLE_MISSING":        "akun direktori Anda tidak termasuk grup yang memiliki akses ke Wallet Point",
+		"AUTH_DIRECTORY_NIP_MISSING":         "akun direktori Anda tidak memiliki NIP",
+		"AUTH_PASSWORD_MANAGED_BY_DIRECTORY": "kata sandi akun ini dikelola di direktori universitas",
+
+		"TELEGRAM_NOT_CONFIGURED":    "bot Telegram belum dikonfigurasi",
+		"TELEGRAM_INVALID_SECRET":    "secret token webhook Telegram tidak ada atau tidak valid",
+		"TELEGRAM_NOT_LINKED":        "belum ada akun Telegram yang ditautkan",
+		"TELEGRAM_LINK_CODE_INVALID": "kode tautan tidak valid, kedaluwarsa, atau sudah dipakai",
+
+		"KIOSK_INVALID_API_KEY":           "API key kiosk tidak ada atau tidak valid",
+		"KIOSK_DEVICE_MISMATCH":           "API key kiosk tidak terikat pada perangkat ini",
+		"KIOSK_DISABLED":                  "Kiosk telah dinonaktifkan",
+		"KIOSK_NOT_FOUND":                 "Kiosk tidak ditemukan",
+		"KIOSK_DEVICE_TAKEN":              "Perangkat sudah terdaftar sebagai kiosk lain",
+		"KIOSK_PRODUCT_UNAVAILABLE":       "Produk tidak aktif atau stok tidak mencukupi",
+		"KIOSK_ORDER_NOT_FOUND":           "Pesanan kiosk tidak ditemukan",
+		"KIOSK_ORDER_INVALID":             "Kode pesanan kiosk tidak valid, kedaluwarsa, atau sudah digunakan",
+		"KIOSK_ORDER_CLOSED":              "Pesanan kiosk sudah tidak menunggu pembayaran",
+		"KIOSK_PRICE_CHANGED":             "Harga produk berubah sejak pesanan dibuat, buat pesanan baru di kiosk",
+		"STOREFRONT_INVALID_API_KEY":      "API key storefront tidak valid atau tidak aktif",
+		"STOREFRONT_RATE_LIMITED":         "Batas permintaan API key storefront terlampaui, coba lagi nanti",
+		"STOREFRONT_KEY_NOT_FOUND":        "API key storefront tidak ditemukan",
+		"STOREFRONT_PRODUCT_NOT_FOUND":    "Produk tidak ditemukan",
+		"CATALOG_SYNC_NOT_CONFIGURED":     "sinkronisasi katalog dengan spreadsheet belum dikonfigurasi",
+		"CATALOG_SYNC_RUNNING":            "sinkronisasi katalog sedang berjalan",
+		"CATALOG_SYNC_CONFLICT_NOT_FOUND": "konflik sinkronisasi katalog tidak ditemukan",
+		"CATALOG_SYNC_CONFLICT_RESOLVED":  "konflik sinkronisasi katalog sudah diselesaikan",
+
+		"ATTENDANCE_INVALID_RANGE":  "from dan to harus tanggal YYYY-MM-DD dengan from tidak setelah to",
+		"EXTERNAL_REFERENCE_REUSED": "referensi sudah digunakan untuk operasi lain",
+
+		"TRANSFER_TO_SELF":                 "tidak dapat mentransfer poin ke diri sendiri",
+		"TRANSFER_SENDER_WALLET_NOT_FOUND": "dompet pengirim tidak ditemukan",
+		"TRANSFER_RECIPIENT_NOT_FOUND":     "penerima tidak ditemukan atau tidak memiliki dompet",
+		"TRANSFER_AMOUNT_OVER_LIMIT":       "jumlah transfer melebihi batas per transfer",
+		"TRANSFER_DAILY_LIMIT_REACHED":     "transfer akan melebihi batas transfer harian Anda",
+
+		"POINT_GIFT_NOT_FOUND":           "hadiah poin tidak ditemukan",
+		"POINT_GIFT_TO_SELF":             "tidak dapat mengirim hadiah poin ke diri sendiri",
+		"POINT_GIFT_RECIPIENT_NOT_FOUND": "penerima tidak ditemukan atau tidak memiliki dompet",
+		"POINT_GIFT_INVALID_DELIVER_AT":  "deliver_at harus antara sekarang dan satu tahun ke depan",
+		"POINT_GIFT_NOT_DELIVERED":       "hadiah poin belum dapat diklaim sebelum tanggal pengirimannya",
+		"POINT_GIFT_CLOSED":              "hadiah poin sudah diklaim atau kedaluwarsa",
+
+		"PRODUCT_NOT_FOUND":                     "produk tidak ditemukan",
+		"PRODUCT_NOT_DELETED":                   "produk tidak dalam keadaan terhapus",
+		"PRODUCT_INACTIVE":                      "produk tidak aktif",
+		"PRODUCT_OUT_OF_STOCK":                  "stok produk habis",
+		"PRODUCT_INSUFFICIENT_STOCK":            "stok tidak mencukupi",
+		"PRODUCT_CREATE_FAILED":                 "gagal membuat produk",
+		"PRODUCT_UPDATE_FAILED":                 "gagal memperbarui produk",
+		"MARKETPLACE_DAILY_SPEND_LIMIT_REACHED": "pesanan melebihi batas belanja marketplace harian Anda",
+		"PRODUCT_PURCHASE_LIMIT_REACHED":        "pesanan melebihi batas pembelian produk ini",
+		"CART_EMPTY":                            "keranjang kosong",
+		"GIFT_TO_SELF":                          "Anda tidak dapat memberi hadiah kepada diri sendiri",
+		"GIFT_RECIPIENT_NOT_FOUND":              "penerima hadiah bukan mahasiswa aktif di kampus ini",
+		"STOCK_ALERT_NOT_FOUND":                 "peringatan stok tidak ditemukan",
+		"STOCK_ALERT_RESOLVED":                  "peringatan stok sudah diselesaikan",
+		"RESTOCK_DUPLICATE_PRODUCT":             "setiap produk hanya boleh muncul sekali dalam satu restock",
+
+		"MISSION_NOT_FOUND":           "misi tidak ditemukan",
+		"MISSION_DEADLINE_PASSED":     "batas waktu misi sudah lewat",
+		"MISSION_ALREADY_SUBMITTED":   "Anda sudah mengumpulkan misi ini",
+		"SUBMISSION_NOT_FOUND":        "pengumpulan tidak ditemukan",
+		"SUBMISSION_ALREADY_REVIEWED": "pengumpulan sudah dinilai",
+
+		"NOTIFICATION_NOT_FOUND":       "notifikasi tidak ditemukan",
+		"DEAD_LETTER_NOT_FOUND":        "dead letter tidak ditemukan",
+		"DEAD_LETTER_ALREADY_REQUEUED": "dead letter sudah dijadwalkan ulang",
+
+		"FEATURE_FLAG_NOT_FOUND":        "feature flag tidak ditemukan",
+		"FEATURE_DISABLED":              "fitur ini sedang dinonaktifkan",
+		"SETTING_NOT_FOUND":             "pengaturan tidak ditemukan",
+		"SETTING_INVALID_VALUE":         "nilai pengaturan tidak valid",
+		"PROMOTION_NOT_FOUND":           "promosi tidak ditemukan",
+		"PROMOTION_INVALID":             "promosi belum lengkap untuk jenisnya",
+		"PROMOTION_FIRST_PURCHASE_USED": "potongan pembelian pertama hanya berlaku untuk pesanan pertama Anda, hitung ulang pesanan",
+
+		"ORDER_NOT_FOUND": "pesanan tidak ditemukan",
+
+		"TICKET_NOT_IN_CART":         "tiket dibeli untuk satu sesi melalui pembelian langsung, bukan melalui keranjang",
+		"TICKET_SESSION_NOT_FOUND":   "sesi tiket tidak ditemukan",
+		"TICKET_SESSION_REQUIRED":    "pilih session_id untuk membeli tiket",
+		"TICKET_SESSION_INVALID":     "sesi harus berakhir setelah dimulai",
+		"TICKET_SESSION_ENDED":       "sesi sudah berakhir",
+		"TICKET_SESSION_FULL":        "kursi yang tersisa di sesi ini tidak mencukupi",
+		"TICKET_CAPACITY_BELOW_SOLD": "kapasitas tidak boleh lebih kecil dari tiket yang terjual",
+		"TICKET_LIMIT_REACHED":       "batas tiket per mahasiswa untuk sesi ini tercapai",
+		"PRODUCT_NOT_TICKET":         "sesi hanya dapat ditambahkan ke produk tiket",
+		"TICKET_NOT_FOUND":           "tiket tidak ditemukan",
+		"TICKET_ALREADY_USED":        "tiket sudah digunakan",
+		"TICKET_CANCELLED":           "tiket sudah dibatalkan",
+		"TICKET_WRONG_SESSION":       "tiket untuk sesi lain",
+		"TICKET_CANCEL_CLOSED":       "tiket hanya dapat dibatalkan sebelum sesi dimulai",
+		"TICKET_SESSION_NOT_FULL":    "sesi masih memiliki kursi; silakan beli tiket",
+		"TICKET_ALREADY_WAITLISTED":  "Anda sudah berada di daftar tunggu sesi ini",
+		"TICKET_NOT_WAITLISTED":      "Anda tidak berada di daftar tunggu sesi ini",
+
+		"FACULTY_STORE_NOT_FOUND":         "toko fakultas tidak ditemukan",
+		"FACULTY_STORE_CODE_INVALID":      "kode toko harus 2 sampai 50 huruf kecil, angka atau tanda hubung",
+		"FACULTY_STORE_CODE_TAKEN":        "kode toko sudah digunakan",
+		"FACULTY_STORE_FORBIDDEN":         "Anda bukan admin toko ini",
+		"FACULTY_STORE_ADMIN_INVALID":     "admin toko harus akun admin atau dosen aktif di kampus ini",
+		"FACULTY_STORE_PRODUCT_NOT_FOUND": "produk katalog harus produk kampus ini",
+		"FACULTY_STORE_BANNER_NOT_FOUND":  "banner tidak ditemukan",
+		"FACULTY_STORE_BANNER_INVALID":    "banner harus berakhir setelah dimulai",
+
+		"MAINTENANCE": "Wallet Point sedang dalam pemeliharaan; perubahan dihentikan sementara selama beberapa menit, silakan coba lagi nanti",
+
+		"TENANT_NOT_FOUND":  "kampus tidak ditemukan",
+		"TENANT_INACTIVE":   "kampus tidak aktif",
+		"TENANT_CODE_TAKEN": "kode kampus sudah digunakan",
+
+		"FILE_NOT_FOUND":    "file tidak ditemukan",
+		"FILE_LINK_EXPIRED": "tautan unduhan tidak valid atau sudah kedaluwarsa",
+
+		"JOB_NOT_FOUND":    "job tidak ditemukan",
+		"JOB_NOT_DEAD":     "hanya job yang gagal permanen yang dapat diulang",
+		"JOB_NO_FILE":      "job tidak memiliki file untuk diunduh",
+		"JOB_UNKNOWN_TYPE": "jenis job tidak dikenal",
+
+		"SCHEDULED_JOB_NOT_FOUND": "job terjadwal tidak ditemukan",
+		"SCHEDULED_JOB_DISABLED":  "job terjadwal ini dinonaktifkan",
+
+		"GRAPHQL_INVALID_QUANTITY": "jumlah harus lebih dari 0",
+
+		"BATCH_INVALID_PATH":   "path sub-permintaan harus berupa path API selain /batch",
+		"BATCH_NOT_CONFIGURED": "permintaan batch tidak tersedia",
+
+		"LIVE_UPDATES_DISABLED": "pembaruan langsung tidak diaktifkan",
+		"LIVE_UPDATES_FULL":     "terlalu banyak koneksi pembaruan langsung, coba lagi nanti",
+
+		"WEBHOOK_ENDPOINT_NOT_FOUND": "endpoint webhook tidak ditemukan",
+		"WEBHOOK_DELIVERY_NOT_FOUND": "pengiriman webhook tidak ditemukan",
+		"WEBHOOK_UNKNOWN_EVENT_TYPE": "jenis event webhook tidak dikenal",
+		"WEBHOOK_INVALID_URL":        "URL webhook harus berupa URL http(s) absolut",
+		"WEBHOOK_DELIVERY_PENDING":   "pengiriman webhook masih dalam proses",
+		"WEBHOOK_ENDPOINT_INACTIVE":  "endpoint webhook sedang dinonaktifkan",
+
+		"IDEMPOTENCY_KEY_INVALID":         "Idempotency-Key harus 1 sampai 255 karakter",
+		"IDEMPOTENCY_KEY_REUSED":          "Idempotency-Key sudah digunakan untuk permintaan lain",
+		"IDEMPOTENCY_REQUEST_IN_PROGRESS": "permintaan dengan Idempotency-Key ini masih diproses",
+	},
+}
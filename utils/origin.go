@@ -0,0 +1,23 @@
+package utils
+
+import "context"
+
+// Origin identifies the API request work was done for, so records written
+// later on its behalf (domain events, their audit entries) can point back to it
+type Origin struct {
+	RequestID string
+	IPAddress string
+	UserAgent string
+}
+
+type originKey struct{}
+
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFromContext returns the request origin of ctx; background work has none
+func OriginFromContext(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	return origin
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pagination is the metadata shared by every paginated list response
+type Pagination struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewPagination builds list metadata; nextCursor is "" for listings without keyset support
+func NewPagination(page, limit int, total int64, nextCursor string) Pagination {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(limit)))
+	}
+	return Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		NextCursor: nextCursor,
+	}
+}
+
+// ListEnvelope is the list response of API v2: items under data, metadata alongside
+type ListEnvelope struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+	Pagination
+	Meta gin.H `json:"meta,omitempty"`
+}
+
+// ListResponse sends a paginated list. v2 clients get a ListEnvelope; v1 clients keep
+// the shape they were built against, with items under legacyKey inside data and any
+// meta values next to the pagination fields.
+func ListResponse(c *gin.Context, message, legacyKey string, items interface{}, pagination Pagination, meta gin.H) {
+	if APIVersion(c) != DefaultAPIVersion {
+		c.JSON(http.StatusOK, ListEnvelope{
+			Success:    true,
+			Message:    Translate(c, message),
+			Data:       items,
+			Pagination: pagination,
+			Meta:       meta,
+		})
+		return
+	}
+
+	data := gin.H{
+		legacyKey:     items,
+		"page":        pagination.Page,
+		"limit":       pagination.Limit,
+		"total":       pagination.Total,
+		"total_pages": pagination.TotalPages,
+	}
+	if pagination.NextCursor != "" {
+		data["next_cursor"] = pagination.NextCursor
+	}
+	for key, value := range meta {
+		data[key] = value
+	}
+	SuccessResponse(c, http.StatusOK, message, data)
+}
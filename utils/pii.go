@@ -0,0 +1,247 @@
+package utils
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// Encrypted PII values are stored as "pii1:<key id>:<wrapped data key>:<data>".
+// Every value gets its own random data key, and only that key is encrypted with
+// the master key (envelope encryption): rotating the master key rewraps the data
+// keys and leaves the data itself alone.
+const (
+	piiVersion = "pii1"
+	piiPrefix  = piiVersion + ":"
+)
+
+var errPIIMalformed = errors.New("pii: malformed encrypted value")
+
+// piiKeyring holds the master keys; the first is used to encrypt
+type piiKeyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+var piiKeys atomic.Pointer[piiKeyring]
+
+func init() {
+	schema.RegisterSerializer("pii", PIISerializer{})
+}
+
+// ParsePIIKeys parses master keys given as "id:base64 of 32 random bytes". The
+// first key encrypts new values; the others are only kept to read older ones.
+func ParsePIIKeys(specs []string) (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(specs))
+	for _, spec := range specs {
+		id, encoded, ok := strings.Cut(spec, ":")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("%q: expected id:base64-key", spec)
+		}
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("key id %q is listed twice", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes, base64 encoded", id)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// InitPII enables encryption of the `serializer:pii` columns with the master keys
+// of PII_ENCRYPTION_KEYS. Without keys values are stored in plain text, and
+// values encrypted earlier can not be read.
+func InitPII(specs []string) error {
+	keys, err := ParsePIIKeys(specs)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		piiKeys.Store(nil)
+		return nil
+	}
+
+	ring := &piiKeyring{current: strings.SplitN(specs[0], ":", 2)[0], keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return err
+		}
+		ring.keys[id] = aead
+	}
+	piiKeys.Store(ring)
+	return nil
+}
+
+// EncryptPII encrypts value with a fresh data key wrapped by the current master
+// key. Empty values stay empty so "not set" checks keep working.
+func EncryptPII(value string) (string, error) {
+	ring := piiKeys.Load()
+	if ring == nil || value == "" {
+		return value, nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		piiVersion,
+		ring.current,
+		seal(ring.keys[ring.current], dataKey),
+		seal(data, []byte(value)),
+	}, ":"), nil
+}
+
+// DecryptPII returns the plain text of an EncryptPII value. Values that are not
+// encrypted (written before a key was configured) are returned as they are.
+func DecryptPII(value string) (string, error) {
+	if !strings.HasPrefix(value, piiPrefix) {
+		return value, nil
+	}
+
+	_, dataKey, sealed, err := unwrapPII(value)
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(data, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// RewrapPII re-encrypts a stored value for the current master key: plain text is
+// encrypted, and the data key of a value of an older master key is rewrapped.
+// changed is false when the value is already current (or empty).
+func RewrapPII(value string) (rewrapped string, changed bool, err error) {
+	ring := piiKeys.Load()
+	if ring == nil {
+		return "", false, errors.New("pii: no encryption key configured")
+	}
+	if value == "" {
+		return value, false, nil
+	}
+	if !strings.HasPrefix(value, piiPrefix) {
+		rewrapped, err = EncryptPII(value)
+		return rewrapped, err == nil, err
+	}
+
+	keyID, dataKey, _, err := unwrapPII(value)
+	if err != nil || keyID == ring.current {
+		return value, false, err
+	}
+	parts := strings.Split(value, ":")
+	parts[1] = ring.current
+	parts[2] = seal(ring.keys[ring.current], dataKey)
+	return strings.Join(parts, ":"), true, nil
+}
+
+// unwrapPII splits an encrypted value and decrypts its data key
+func unwrapPII(value string) (keyID string, dataKey []byte, sealed string, err error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 {
+		return "", nil, "", errPIIMalformed
+	}
+	ring := piiKeys.Load()
+	if ring == nil {
+		return "", nil, "", errors.New("pii: value is encrypted but no encryption key is configured")
+	}
+	master, ok := ring.keys[parts[1]]
+	if !ok {
+		return "", nil, "", fmt.Errorf("pii: value is encrypted with unknown key %q", parts[1])
+	}
+	dataKey, err = open(master, parts[2])
+	if err != nil {
+		return "", nil, "", err
+	}
+	return parts[1], dataKey, parts[3], nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plain under a random nonce and returns nonce+ciphertext, base64 encoded
+func seal(aead cipher.AEAD, plain []byte) string {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err) // crypto/rand does not fail on supported platforms
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, nil))
+}
+
+func open(aead cipher.AEAD, encoded string) ([]byte, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, errPIIMalformed
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errPIIMalformed
+	}
+	return plain, nil
+}
+
+// PIISerializer encrypts a string field tagged `gorm:"serializer:pii"` on the way
+// into the database and decrypts it when loaded, so models only see plain text.
+// Column sizes must allow for the encrypted form (about 130 bytes more).
+type PIISerializer struct{}
+
+func (PIISerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	case nil:
+	default:
+		return fmt.Errorf("pii: unsupported column value %T", dbValue)
+	}
+
+	plain, err := DecryptPII(stored)
+	if err != nil {
+		return err
+	}
+	return field.Set(ctx, dst, plain)
+}
+
+func (PIISerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, _ := fieldValue.(string)
+	return EncryptPII(value)
+}
+
+// PII wraps a value written to a `serializer:pii` column through a map update
+// (Updates(map...) and Update(column, value) skip serializers), e.g.
+// Updates(map[string]interface{}{"pin_hash": utils.PII(hash)})
+type PII string
+
+func (p PII) Value() (driver.Value, error) {
+	return EncryptPII(string(p))
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxPageLimit caps the limit query parameter of every list endpoint
+const MaxPageLimit = 100
+
+// QueryInt reads an optional whole-number query parameter, giving def when it is
+// missing. A value that is not a whole number or lies outside [min, max] is answered
+// with a 400 validation error and ok is false; the handler should just return.
+func QueryInt(c *gin.Context, name string, def, min, max int) (value int, ok bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return def, true
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		queryError(c, name, "type", formatRule(c, "must be {param}", Translate(c, "a whole number")))
+		return 0, false
+	}
+	if value < min {
+		queryError(c, name, "min", formatRule(c, "must be at least {param}", strconv.Itoa(min)))
+		return 0, false
+	}
+	if value > max {
+		queryError(c, name, "max", formatRule(c, "must be at most {param}", strconv.Itoa(max)))
+		return 0, false
+	}
+	return value, true
+}
+
+// PageQuery reads the page and limit query parameters of a list endpoint. page
+// defaults to 1 and limit to defaultLimit, which may not exceed MaxPageLimit.
+func PageQuery(c *gin.Context, defaultLimit int) (page, limit int, ok bool) {
+	page, ok = QueryInt(c, "page", 1, 1, math.MaxInt32)
+	if !ok {
+		return 0, 0, false
+	}
+	limit, ok = LimitQuery(c, defaultLimit)
+	return page, limit, ok
+}
+
+// LimitQuery reads the limit query parameter of a list endpoint without pages
+func LimitQuery(c *gin.Context, defaultLimit int) (int, bool) {
+	return QueryInt(c, "limit", defaultLimit, 1, MaxPageLimit)
+}
+
+// QueryEnum reads an optional query parameter restricted to the values of an
+// enum validator tag, e.g. QueryEnum(c, "status", "user_status")
+func QueryEnum(c *gin.Context, name, tag string) (string, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return "", true
+	}
+
+	for _, allowed := range enums[tag] {
+		if value == allowed {
+			return value, true
+		}
+	}
+	queryError(c, name, tag, formatRule(c, "must be one of: {param}", strings.Join(enums[tag], ", ")))
+	return "", false
+}
+
+func queryError(c *gin.Context, field, rule, message string) {
+	ValidationErrorResponse(c, []FieldError{{Field: field, Rule: rule, Message: message}})
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ReplicaResolver names the read-replica pool registered by config.ConnectDB
+const ReplicaResolver = "replica"
+
+// ReadReplica returns a handle whose queries read from a replica when DB_REPLICA_HOSTS
+// is set and from the primary otherwise. Writes and transactions always use the primary.
+// Only use it for reports and listings that tolerate a few seconds of replication lag.
+func ReadReplica(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Use(ReplicaResolver)).Session(&gorm.Session{})
+}
@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"context"
+	"errors"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
+	Code    string      `json:"code,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Errors  interface{} `json:"errors,omitempty"`
 }
@@ -15,25 +19,56 @@ type Response struct {
 func SuccessResponse(c *gin.Context, statusCode int, message string, data interface{}) {
 	c.JSON(statusCode, Response{
 		Success: true,
-		Message: message,
+		Message: Translate(c, message),
 		Data:    data,
 	})
 }
 
-// ErrorResponse sends an error response
+// ErrorResponse sends an error response with the generic code of the status
 func ErrorResponse(c *gin.Context, statusCode int, message string, errors interface{}) {
+	if statusCode >= 500 && timedOut(c) {
+		ServiceErrorResponse(c, statusCode, ErrRequestTimeout)
+		return
+	}
 	c.JSON(statusCode, Response{
 		Success: false,
-		Message: message,
+		Message: Translate(c, message),
+		Code:    CodeForStatus(statusCode),
 		Errors:  errors,
 	})
 }
 
+// ServiceErrorResponse sends err with the code and status of the AppError it wraps,
+// otherwise as a plain error with fallbackStatus
+func ServiceErrorResponse(c *gin.Context, fallbackStatus int, err error) {
+	if errors.Is(err, context.DeadlineExceeded) || (fallbackStatus >= 500 && timedOut(c)) {
+		err = ErrRequestTimeout
+	}
+	appErr, ok := AsAppError(err)
+	if !ok {
+		ErrorResponse(c, fallbackStatus, err.Error(), nil)
+		return
+	}
+
+	c.JSON(appErr.Status, Response{
+		Success: false,
+		Message: translateError(c, appErr, err.Error()),
+		Code:    appErr.Code,
+	})
+}
+
 // ValidationErrorResponse sends a validation error response
 func ValidationErrorResponse(c *gin.Context, errors interface{}) {
 	c.JSON(400, Response{
 		Success: false,
-		Message: "Validation error",
+		Message: Translate(c, "Validation error"),
+		Code:    CodeValidationFailed,
 		Errors:  errors,
 	})
 }
+
+// timedOut reports whether the request deadline set by the Timeout middleware passed,
+// which makes a failing database call surface as a 504 instead of a 500
+func timedOut(c *gin.Context) bool {
+	return c.Request != nil && errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+}
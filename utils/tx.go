@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	// TxMaxAttempts bounds how often WithTx runs a transaction that keeps deadlocking
+	TxMaxAttempts = 3
+	// TxTimeout caps a transaction whose context has no deadline of its own
+	TxTimeout = 15 * time.Second
+
+	txRetryBaseDelay = 20 * time.Millisecond
+)
+
+// MySQL errors after which the whole transaction was rolled back and can simply be run again
+const (
+	mysqlLockWaitTimeout = 1205 // ER_LOCK_WAIT_TIMEOUT
+	mysqlDeadlock        = 1213 // ER_LOCK_DEADLOCK
+)
+
+// WithTx runs fn in a transaction, running it again from the start when MySQL aborts
+// it for a deadlock or lock wait timeout. fn must therefore not have side effects
+// outside tx (HTTP calls, cache invalidations, notifications) - do those after WithTx.
+// Retries stop once ctx is done, and ctx's error is returned in that case.
+func WithTx(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, TxTimeout)
+		defer cancel()
+	}
+
+	var err error
+	for attempt := 1; attempt <= TxMaxAttempts; attempt++ {
+		err = db.WithContext(ctx).Transaction(fn)
+		if err == nil || !IsRetryableTxError(err) || attempt == TxMaxAttempts {
+			break
+		}
+
+		delay := txRetryBaseDelay<<(attempt-1) + time.Duration(rand.Int63n(int64(txRetryBaseDelay)))
+		slog.WarnContext(ctx, "transaction aborted, retrying", "attempt", attempt, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if err != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+		// A statement cut off by the deadline surfaces as a driver error; report the timeout instead
+		return errors.Join(ctx.Err(), err)
+	}
+	return err
+}
+
+// IsRetryableTxError reports whether err means MySQL rolled the transaction back for a lock conflict
+func IsRetryableTxError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDeadlock || mysqlErr.Number == mysqlLockWaitTimeout
+	}
+	return false
+}
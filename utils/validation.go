@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxPointsPerOperation bounds a single amount of points in a request
+const MaxPointsPerOperation = 10000000
+
+// Enum validators shared by the request models, e.g. binding:"required,user_role".
+// Add the matching enums:"..." tag so the values also show up in the API docs.
+var enums = map[string][]string{
+	"user_role":      {"superadmin", "admin", "dosen", "mahasiswa"},
+	"user_status":    {"active", "inactive", "suspended"},
+	"product_status": {"active", "inactive"},
+	"product_type":   {"physical", "print_quota", "ticket"},
+	"mission_status": {"active", "inactive", "expired"},
+	"review_status":  {"approved", "rejected"},
+	"direction":      {"credit", "debit"},
+	"tenant_type":    {"campus", "faculty"},
+	"promotion_type": {"buy_x_get_y", "category_percent", "first_purchase"},
+
+	// List filters, read with QueryEnum
+	"transaction_status":      {"success", "failed", "pending"},
+	"submission_status":       {"pending", "approved", "rejected"},
+	"stock_alert_status":      {"open", "acknowledged", "snoozed", "resolved"},
+	"job_status":              {"pending", "running", "succeeded", "dead"},
+	"outbox_status":           {"pending", "sending", "sent", "dead"},
+	"scheduler_run_status":    {"running", "succeeded", "failed"},
+	"webhook_delivery_status": {"pending", "succeeded", "failed"},
+	"topup_status":            {"pending", "paid", "failed", "expired"},
+	"topup_request_status":    {"pending", "approved", "rejected"},
+	"lms_event_status":        {"credited", "skipped"},
+	"library_fine_status":     {"unpaid", "paid"},
+	"pos_charge_status":       {"completed", "voided"},
+	"print_quota_status":      {"pending", "completed", "refunded"},
+	"catalog_conflict_status": {"open", "resolved"},
+	"point_gift_box":          {"received", "sent"},
+	"point_gift_status":       {"pending", "claimed", "expired"},
+	"fulfillment_status":      {"pending", "claimed"},
+	"anomaly_status":          {"open", "dismissed", "confirmed"},
+	"anomaly_rule":            {"spend_spike", "night_new_ip"},
+	"admin_watch_rule":        {"price_changes", "manual_credits", "wallet_resets"},
+	"product_question_status": {"pending", "published", "rejected"},
+	"order_status":            {"pending", "paid", "fulfilled", "cancelled"},
+	"ticket_status":           {"valid", "used", "cancelled"},
+	"saga_status":             {"running", "completed", "compensating", "compensated"},
+}
+
+// nimPattern matches a student NIM or staff NIP: digits only (NIP has 18)
+var nimPattern = regexp.MustCompile(`^[0-9]{8,20}$`)
+
+// FieldError is one problem with a request field, sent in the errors of a validation response
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// RegisterValidators adds the custom validation tags to gin's binder and reports
+// fields by their JSON (or form) name. Call it once at startup.
+func RegisterValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("gin binding validator is not go-playground/validator")
+	}
+
+	v.RegisterTagNameFunc(fieldName)
+
+	if err := v.RegisterValidation("nim", func(fl validator.FieldLevel) bool {
+		return nimPattern.MatchString(fl.Field().String())
+	}); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("points", func(fl validator.FieldLevel) bool {
+		points := fl.Field().Int()
+		return points > 0 && points <= MaxPointsPerOperation
+	}); err != nil {
+		return err
+	}
+	for tag, values := range enums {
+		if err := v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			for _, value := range values {
+				if fl.Field().String() == value {
+					return true
+				}
+			}
+			return false
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// BindingErrorResponse sends the error of ShouldBind* as a validation error listing
+// every invalid field with a message in the request language
+func BindingErrorResponse(c *gin.Context, err error) {
+	ValidationErrorResponse(c, TranslateBindingError(c, err))
+}
+
+// TranslateBindingError turns a binder error into per-field errors
+func TranslateBindingError(c *gin.Context, err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: formatRule(c, ruleMessage(fe), ruleParam(fe)),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: formatRule(c, "must be {param}", Translate(c, jsonTypeName(typeErr.Type))),
+		}}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return []FieldError{{Rule: "json", Message: Translate(c, "request body is not valid JSON")}}
+	}
+	if errors.Is(err, io.EOF) {
+		return []FieldError{{Rule: "required", Message: Translate(c, "request body is empty")}}
+	}
+
+	return []FieldError{{Rule: "invalid", Message: err.Error()}}
+}
+
+// fieldPath drops the request struct name from the namespace: "filters[0].op"
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	kind := fe.Kind()
+	isText := kind == reflect.String
+	isList := kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "numeric":
+		return "must contain only digits"
+	case "e164":
+		return "must be a phone number like +6281234567890"
+	case "nim":
+		return "must be a NIM/NIP of 8 to 20 digits"
+	case "points":
+		return "must be between 1 and {param} points"
+	case "oneof":
+		return "must be one of: {param}"
+	case "len":
+		if isText {
+			return "must be exactly {param} characters"
+		}
+		if isList {
+			return "must have exactly {param} items"
+		}
+		return "must be {param}"
+	case "min", "gte":
+		if isText {
+			return "must be at least {param} characters"
+		}
+		if isList {
+			return "must have at least {param} items"
+		}
+		return "must be at least {param}"
+	case "max", "lte":
+		if isText {
+			return "must be at most {param} characters"
+		}
+		if isList {
+			return "must have at most {param} items"
+		}
+		return "must be at most {param}"
+	case "gt":
+		return "must be greater than {param}"
+	case "lt":
+		return "must be less than {param}"
+	}
+	if _, ok := enums[fe.Tag()]; ok {
+		return "must be one of: {param}"
+	}
+	return "is invalid"
+}
+
+func ruleParam(fe validator.FieldError) string {
+	if values, ok := enums[fe.Tag()]; ok {
+		return strings.Join(values, ", ")
+	}
+	switch fe.Tag() {
+	case "oneof":
+		return strings.Join(strings.Fields(fe.Param()), ", ")
+	case "points":
+		return fmt.Sprint(MaxPointsPerOperation)
+	}
+	return fe.Param()
+}
+
+// formatRule translates a rule message and fills in its parameter
+func formatRule(c *gin.Context, message, param string) string {
+	return strings.ReplaceAll(Translate(c, message), "{param}", param)
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "text"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	default:
+		return "an object"
+	}
+}
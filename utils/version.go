@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const DefaultAPIVersion = "v1"
+
+type apiVersionKey struct{}
+
+// WithAPIVersion records the API version a request was made against
+func WithAPIVersion(r *http.Request, version string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version))
+}
+
+// RequestedAPIVersion returns the version recorded by WithAPIVersion, or "" if none
+func RequestedAPIVersion(r *http.Request) string {
+	version, _ := r.Context().Value(apiVersionKey{}).(string)
+	return version
+}
+
+// APIVersion returns the API version the client called, so shared handlers can
+// shape their response per version
+func APIVersion(c *gin.Context) string {
+	if version := RequestedAPIVersion(c.Request); version != "" {
+		return version
+	}
+	return DefaultAPIVersion
+}